@@ -0,0 +1,127 @@
+// Package ask finds the files of the repository relevant to a question about it, like
+// "what does pkg/util do?" or "why does this diff touch the scheduler?": the files and
+// the directories named by the question first, then the files with its keywords in
+// their path or their content.
+package ask
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxKeywords is the maximum number of keywords of a question searched in the files.
+const maxKeywords = 8
+
+// minKeyword is the minimum length of a keyword.
+const minKeyword = 3
+
+// stopWords are the common words of the questions, they don't tell which files matter.
+var stopWords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`about above after again all also and any are because been
+		before being both but can cannot code could did does doing done each else every file
+		files for from get gets got had has have having here how into its just like made make
+		makes many more most much not now off once only other our out over same should since
+		some such than that the their them then there these they this those through too under
+		until use used uses using very was way were what when where which while who whom whose
+		why will with within without would you your change changes changed diff repo repository
+		project work works tell explain mean means happen happens need needs part parts`) {
+		stopWords[w] = true
+	}
+}
+
+// pathChars are the characters around the paths of a question, like the quotes.
+const pathChars = "\"'`()[]{}<>,;:?!"
+
+// Mentioned returns the files named by the question, by their path or their base name,
+// and the files of the directories it names, in the order of the files.
+func Mentioned(question string, files []string) []string {
+	var names, dirs []string
+	for _, word := range strings.Fields(question) {
+		word = strings.TrimRight(strings.Trim(word, pathChars), ".")
+		word = strings.TrimPrefix(word, "./")
+		if !strings.ContainsAny(word, "/.") {
+			continue
+		}
+		if strings.HasSuffix(word, "/") {
+			dirs = append(dirs, strings.TrimSuffix(word, "/"))
+			continue
+		}
+		names = append(names, word)
+		if strings.Contains(word, "/") {
+			dirs = append(dirs, word)
+		}
+	}
+
+	var mentioned []string
+	for _, f := range files {
+		if named(f, names, dirs) {
+			mentioned = append(mentioned, f)
+		}
+	}
+	return mentioned
+}
+
+// named reports whether the file is one of the names, a path or a base name, or in one
+// of the directories.
+func named(file string, names, dirs []string) bool {
+	for _, name := range names {
+		if file == name || (!strings.Contains(name, "/") && path.Base(file) == name) {
+			return true
+		}
+	}
+	for _, dir := range dirs {
+		if strings.HasPrefix(file, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// identifier matches the words and the identifiers of the code.
+var identifier = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// Keywords returns the words of the question worth searching in the files, the common
+// words and the short ones left out, in the order of the question.
+func Keywords(question string) []string {
+	var keywords []string
+	seen := map[string]bool{}
+	for _, word := range identifier.FindAllString(question, -1) {
+		lower := strings.ToLower(word)
+		if len(word) < minKeyword || stopWords[lower] || seen[lower] {
+			continue
+		}
+		seen[lower] = true
+		keywords = append(keywords, word)
+		if len(keywords) == maxKeywords {
+			break
+		}
+	}
+	return keywords
+}
+
+// Rank returns the files with a keyword in their path or their content, the most relevant
+// first. hits is the number of keywords found in the content of every file, a keyword in
+// the path counts twice. The ties keep the order of the files.
+func Rank(files, keywords []string, hits map[string]int) []string {
+	scores := map[string]int{}
+	var ranked []string
+	for _, f := range files {
+		score := hits[f]
+		lower := strings.ToLower(f)
+		for _, k := range keywords {
+			if strings.Contains(lower, strings.ToLower(k)) {
+				score += 2
+			}
+		}
+		if score > 0 {
+			scores[f] = score
+			ranked = append(ranked, f)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return scores[ranked[i]] > scores[ranked[j]] })
+	return ranked
+}
@@ -0,0 +1,62 @@
+package ask
+
+import (
+	"reflect"
+	"testing"
+)
+
+var files = []string{
+	"README.md",
+	"cmd/commit.go",
+	"pkg/scheduler/queue.go",
+	"pkg/util/strings.go",
+	"pkg/util/strings_test.go",
+	"pkg/utility/time.go",
+	"util/strings.go",
+}
+
+func TestMentioned(t *testing.T) {
+	tests := []struct {
+		question string
+		want     []string
+	}{
+		{
+			question: "what does pkg/util do?",
+			want:     []string{"pkg/util/strings.go", "pkg/util/strings_test.go"},
+		},
+		{
+			question: "Why is `cmd/commit.go` so long?",
+			want:     []string{"cmd/commit.go"},
+		},
+		{
+			question: "what's in strings.go and ./README.md.",
+			want:     []string{"README.md", "pkg/util/strings.go", "util/strings.go"},
+		},
+		{
+			question: "why does this diff touch the scheduler?",
+			want:     nil,
+		},
+	}
+	for _, tt := range tests {
+		if got := Mentioned(tt.question, files); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Mentioned(%q) = %v, want %v", tt.question, got, tt.want)
+		}
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	got := Keywords("Why does this diff touch the scheduler and the NewQueue function? What's the scheduler for?")
+	want := []string{"touch", "scheduler", "NewQueue", "function"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Keywords() = %v, want %v", got, want)
+	}
+}
+
+func TestRank(t *testing.T) {
+	hits := map[string]int{"cmd/commit.go": 1, "pkg/util/strings.go": 1}
+	got := Rank(files, []string{"scheduler", "Queue"}, hits)
+	want := []string{"pkg/scheduler/queue.go", "cmd/commit.go", "pkg/util/strings.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Rank() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,229 @@
+// Package audit keeps an append-only log of the requests sent to the providers, one
+// JSON record per line, so the organizations can audit which code context left the
+// machine. The records are encrypted with AES-GCM when the log has a key, derived from
+// the passphrase with Argon2id and the random salt stored next to the log.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// saltSize is the size of the random salt of the key derivation.
+const saltSize = 16
+
+// Record is a request sent to a provider and its response.
+type Record struct {
+	Time     time.Time `json:"time"`
+	User     string    `json:"user,omitempty"`
+	Repo     string    `json:"repo,omitempty"`
+	Command  string    `json:"command,omitempty"`
+	Provider string    `json:"provider,omitempty"`
+	Model    string    `json:"model"`
+	// PromptHash is the SHA256 of the prompt once redacted, see Hash
+	PromptHash       string `json:"prompt_hash"`
+	PromptTokens     int    `json:"prompt_tokens,omitempty"`
+	CompletionTokens int    `json:"completion_tokens,omitempty"`
+	Response         string `json:"response"`
+}
+
+// ErrNoKey is returned when the log has encrypted records and no key to read them.
+var ErrNoKey = errors.New("the audit log is encrypted, set its key to read it")
+
+// Log is a JSONL file of records, only ever appended to.
+type Log struct {
+	path string
+	aead cipher.AEAD
+}
+
+// NewLog returns the log stored in the given file.
+func NewLog(path string, opts ...Option) (*Log, error) {
+	cfg := &config{}
+	for _, o := range opts {
+		o.apply(cfg)
+	}
+
+	l := &Log{path: path}
+	if cfg.key == "" {
+		return l, nil
+	}
+
+	salt, err := loadSalt(path + ".salt")
+	if err != nil {
+		return nil, err
+	}
+	// the passphrase is stretched to an AES-256 key
+	if l.aead, err = newAEAD(argon2.IDKey([]byte(cfg.key), salt, 1, 64*1024, 4, 32)); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// newAEAD returns the AES-GCM cipher of the key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// loadSalt returns the salt of the key derivation stored in the file, a new random one
+// is written the first time.
+func loadSalt(name string) ([]byte, error) {
+	salt, err := os.ReadFile(name)
+	if err == nil {
+		if len(salt) != saltSize {
+			return nil, fmt.Errorf("invalid salt in %s", name)
+		}
+		return salt, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	salt = make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if errors.Is(err, os.ErrExist) {
+		// written by another process at the same time
+		return loadSalt(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.Write(salt); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return salt, f.Close()
+}
+
+// Hash returns the SHA256 of the prompt, the same prompt always has the same hash.
+func Hash(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// Append adds the record at the end of the log, the existing records are never
+// rewritten.
+func (l *Log) Append(r Record) error {
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if l.aead != nil {
+		line, err = l.seal(line)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	// a single write per record so the concurrent appends never interleave
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Records returns all the records of the log, none if the file does not exist.
+func (l *Log) Records() ([]Record, error) {
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return l.read(f)
+}
+
+func (l *Log) read(r io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	// the responses can be long
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if line[0] != '{' {
+			var err error
+			line, err = l.open(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d of %s: %w", n, l.path, err)
+			}
+		}
+		var rec Record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, fmt.Errorf("line %d of %s: %w", n, l.path, err)
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
+
+// seal encrypts the line, the result is the base64 of the nonce and the ciphertext.
+func (l *Log) seal(line []byte) ([]byte, error) {
+	nonce := make([]byte, l.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := l.aead.Seal(nonce, nonce, line, nil)
+	return []byte(base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// open decrypts the line written by seal.
+func (l *Log) open(line []byte) ([]byte, error) {
+	if l.aead == nil {
+		return nil, ErrNoKey
+	}
+	sealed := make([]byte, base64.StdEncoding.DecodedLen(len(line)))
+	n, err := base64.StdEncoding.Decode(sealed, line)
+	if err != nil {
+		return nil, err
+	}
+	sealed = sealed[:n]
+	size := l.aead.NonceSize()
+	if len(sealed) < size {
+		return nil, errors.New("invalid encrypted record")
+	}
+	out, err := l.aead.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		return nil, errors.New("can't decrypt the record, wrong key")
+	}
+	return out, nil
+}
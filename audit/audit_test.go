@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "codegpt", "audit.jsonl")
+	l, err := NewLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := l.Records()
+	if err != nil || len(records) != 0 {
+		t.Fatalf("Records() of a missing log = %v, %v", records, err)
+	}
+
+	for _, model := range []string{"gpt-4o", "gpt-4o-mini"} {
+		if err := l.Append(Record{Model: model, PromptHash: Hash("diff"), Response: "feat: add audit log"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err = l.Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[1].Model != "gpt-4o-mini" || records[0].Time.IsZero() {
+		t.Fatalf("Records() = %+v", records)
+	}
+	data, _ := os.ReadFile(path)
+	if n := bytes.Count(data, []byte("\n")); n != 2 {
+		t.Errorf("the log has %d lines, want 2", n)
+	}
+}
+
+func TestEncryptedLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l, err := NewLog(path, WithKey("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Append(Record{Model: "gpt-4o", PromptHash: Hash("diff"), Response: "fix: handle nil"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	if bytes.Contains(data, []byte("gpt-4o")) || bytes.Contains(data, []byte("handle nil")) {
+		t.Fatalf("the record is stored in clear: %s", data)
+	}
+
+	records, err := l.Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Response != "fix: handle nil" {
+		t.Errorf("Records() = %+v", records)
+	}
+
+	plain, _ := NewLog(path)
+	if _, err := plain.Records(); !errors.Is(err, ErrNoKey) {
+		t.Errorf("Records() without key returned %v, want ErrNoKey", err)
+	}
+	wrong, _ := NewLog(path, WithKey("other"))
+	if _, err := wrong.Records(); err == nil {
+		t.Error("Records() with the wrong key returned no error")
+	}
+}
+
+func TestEncryptedLogSalt(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.jsonl"), filepath.Join(dir, "b.jsonl")
+	for _, path := range []string{a, b} {
+		l, err := NewLog(path, WithKey("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := l.Append(Record{Model: "gpt-4o"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	saltA, _ := os.ReadFile(a + ".salt")
+	saltB, _ := os.ReadFile(b + ".salt")
+	if len(saltA) != saltSize || bytes.Equal(saltA, saltB) {
+		t.Errorf("the salts of the logs are %x and %x, want two random ones", saltA, saltB)
+	}
+
+	// the record of the first log can't be read with the salt of the second one
+	data, _ := os.ReadFile(a)
+	if err := os.WriteFile(b, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	l, _ := NewLog(b, WithKey("secret"))
+	if _, err := l.Records(); err == nil {
+		t.Error("Records() decrypted a record with another salt")
+	}
+}
+
+func TestHash(t *testing.T) {
+	if Hash("a") != Hash("a") || Hash("a") == Hash("b") {
+		t.Error("Hash() must be stable and differ between the prompts")
+	}
+}
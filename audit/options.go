@@ -0,0 +1,31 @@
+package audit
+
+// Option is an interface that specifies audit log configuration options.
+type Option interface {
+	apply(*config)
+}
+
+// optionFunc is a type of function that can be used to implement the Option interface.
+// It takes a pointer to a config struct and modifies it.
+type optionFunc func(*config)
+
+// Ensure that optionFunc satisfies the Option interface.
+var _ Option = (*optionFunc)(nil)
+
+// The apply method of optionFunc type is implemented here to modify the config struct based on the function passed.
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithKey returns an Option that encrypts the records with the passphrase.
+// An empty passphrase writes the records in plain JSON.
+func WithKey(val string) Option {
+	return optionFunc(func(c *config) {
+		c.key = val
+	})
+}
+
+// config is a struct that stores configuration options for the audit log.
+type config struct {
+	key string
+}
@@ -0,0 +1,74 @@
+// Package batch runs a command of CodeGPT in a list of repositories, a few at a time.
+package batch
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DefaultConcurrency is the number of repositories processed at the same time.
+const DefaultConcurrency = 4
+
+// ParseRepos reads the repositories of the list, one folder per line. The empty lines
+// and the lines starting with # are skipped, the relative folders are relative to base,
+// the folder of the list, and the repeated folders are only kept once.
+func ParseRepos(r io.Reader, base string) ([]string, error) {
+	var repos []string
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(base, line)
+		}
+		line = filepath.Clean(line)
+		if seen[line] {
+			continue
+		}
+		seen[line] = true
+		repos = append(repos, line)
+	}
+	return repos, scanner.Err()
+}
+
+// Run calls fn for every repository, at most concurrency at the same time, and returns
+// the results in the order of the repositories. The repositories not started when the
+// context is canceled get the error of the context.
+func Run[T any](ctx context.Context, repos []string, concurrency int, fn func(ctx context.Context, repo string) (T, error)) ([]T, []error) {
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+	results := make([]T, len(repos))
+	errs := make([]error, len(repos))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		if err := ctx.Err(); err != nil {
+			errs[i] = err
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i], errs[i] = fn(ctx, repo)
+		}()
+	}
+	wg.Wait()
+	return results, errs
+}
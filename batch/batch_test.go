@@ -0,0 +1,80 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRepos(t *testing.T) {
+	base := filepath.FromSlash("/src")
+	list := `# the services
+api
+ ./web
+
+/opt/tools
+api/
+`
+	got, err := ParseRepos(strings.NewReader(list), base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(base, "api"),
+		filepath.Join(base, "web"),
+		filepath.Clean("/opt/tools"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseRepos() = %v, want %v", got, want)
+	}
+}
+
+func TestRun(t *testing.T) {
+	repos := []string{"a", "b", "c", "d", "e"}
+	var running, most int32
+	results, errs := Run(context.Background(), repos, 2, func(ctx context.Context, repo string) (string, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&most)
+			if n <= m || atomic.CompareAndSwapInt32(&most, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if repo == "c" {
+			return "", errors.New("failed")
+		}
+		return strings.ToUpper(repo), nil
+	})
+
+	if want := []string{"A", "B", "", "D", "E"}; !reflect.DeepEqual(results, want) {
+		t.Errorf("Run() results = %v, want %v", results, want)
+	}
+	for i, err := range errs {
+		if (err != nil) != (repos[i] == "c") {
+			t.Errorf("Run() error of %s = %v", repos[i], err)
+		}
+	}
+	if most > 2 {
+		t.Errorf("Run() ran %d repositories at the same time, want at most 2", most)
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, errs := Run(ctx, []string{"a", "b"}, 1, func(ctx context.Context, repo string) (int, error) {
+		return 1, nil
+	})
+	for _, err := range errs {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Run() error = %v, want context.Canceled", err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// version is part of every key, bump it to invalidate the cached responses.
+const version = "v1"
+
+// Entry is a cached response.
+type Entry struct {
+	Created time.Time `json:"created"`
+	Content string    `json:"content"`
+}
+
+// Cache stores the provider responses on disk, one file per key.
+type Cache struct {
+	dir string
+	ttl time.Duration
+	now func() time.Time
+}
+
+// New returns a cache with the given options, stored in the codegpt folder of Dir by default.
+func New(opts ...Option) (*Cache, error) {
+	cfg := &config{ttl: defaultTTL}
+	for _, o := range opts {
+		o.apply(cfg)
+	}
+
+	if cfg.dir == "" {
+		dir, err := Dir()
+		if err != nil {
+			return nil, err
+		}
+		cfg.dir = dir
+	}
+
+	return &Cache{
+		dir: cfg.dir,
+		ttl: cfg.ttl,
+		now: time.Now,
+	}, nil
+}
+
+// Dir returns the default cache folder: $XDG_CACHE_HOME/codegpt when it's set, the cache
+// folder of the platform otherwise.
+func Dir() (string, error) {
+	if dir := os.Getenv("XDG_CACHE_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "codegpt"), nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "codegpt"), nil
+}
+
+// Key returns the SHA256 of the given parts, e.g. the model and the prompt.
+func Key(parts ...string) string {
+	h := sha256.New()
+	h.Write([]byte(version))
+	for _, p := range parts {
+		// separate the parts so that ("ab", "c") and ("a", "bc") differ
+		h.Write([]byte{0})
+		h.Write([]byte(p))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// path returns the file of the key.
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// Get returns the cached content of the key, if it exists and is not expired.
+func (c *Cache) Get(key string) (string, bool) {
+	data, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return "", false
+	}
+
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return "", false
+	}
+	if c.now().Sub(e.Created) > c.ttl {
+		_ = os.Remove(c.path(key))
+		return "", false
+	}
+	return e.Content, true
+}
+
+// Set stores the content of the key.
+func (c *Cache) Set(key, content string) error {
+	data, err := json.Marshal(Entry{
+		Created: c.now(),
+		Content: content,
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path(key), data, 0o600)
+}
+
+// Clear removes all the cached responses.
+func (c *Cache) Clear() error {
+	err := os.RemoveAll(c.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
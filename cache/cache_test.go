@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKey(t *testing.T) {
+	if Key("gpt-4", "diff") != Key("gpt-4", "diff") {
+		t.Error("Key() is not stable")
+	}
+	if Key("gpt-4", "diff") == Key("gpt-3.5-turbo", "diff") {
+		t.Error("Key() ignores the model")
+	}
+	if Key("ab", "c") == Key("a", "bc") {
+		t.Error("Key() doesn't separate the parts")
+	}
+}
+
+func TestCache(t *testing.T) {
+	c, err := New(WithDir(t.TempDir()), WithTTL(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := Key("gpt-4", "diff")
+	if _, ok := c.Get(key); ok {
+		t.Fatal("Get() found a missing key")
+	}
+
+	if err := c.Set(key, "feat: add cache"); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := c.Get(key); !ok || got != "feat: add cache" {
+		t.Errorf("Get() = %q, %v", got, ok)
+	}
+
+	// expire the entry
+	now := time.Now()
+	c.now = func() time.Time { return now.Add(2 * time.Hour) }
+	if _, ok := c.Get(key); ok {
+		t.Error("Get() returned an expired entry")
+	}
+
+	c.now = time.Now
+	if err := c.Set(key, "fix: cache"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get(key); ok {
+		t.Error("Get() found a key after Clear()")
+	}
+}
+
+func TestDir(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", base)
+	dir, err := Dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(base, "codegpt"); dir != want {
+		t.Errorf("Dir() = %q, want %q", dir, want)
+	}
+
+	// a relative path is ignored, like the XDG spec says
+	t.Setenv("XDG_CACHE_HOME", "cache")
+	if dir, err := Dir(); err == nil && dir == filepath.Join("cache", "codegpt") {
+		t.Errorf("Dir() = %q, a relative XDG_CACHE_HOME should be ignored", dir)
+	}
+}
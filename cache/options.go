@@ -0,0 +1,46 @@
+package cache
+
+import "time"
+
+// defaultTTL is how long a cached response is used by default.
+const defaultTTL = 7 * 24 * time.Hour
+
+// Option is an interface that specifies cache configuration options.
+type Option interface {
+	apply(*config)
+}
+
+// optionFunc is a type of function that can be used to implement the Option interface.
+// It takes a pointer to a config struct and modifies it.
+type optionFunc func(*config)
+
+// Ensure that optionFunc satisfies the Option interface.
+var _ Option = (*optionFunc)(nil)
+
+// The apply method of optionFunc type is implemented here to modify the config struct based on the function passed.
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithDir returns an Option that sets the directory of the cached responses.
+func WithDir(val string) Option {
+	return optionFunc(func(c *config) {
+		c.dir = val
+	})
+}
+
+// WithTTL returns an Option that sets how long a cached response is used.
+// Zero or negative values keep the default.
+func WithTTL(val time.Duration) Option {
+	return optionFunc(func(c *config) {
+		if val > 0 {
+			c.ttl = val
+		}
+	})
+}
+
+// config is a struct that stores configuration options for the cache.
+type config struct {
+	dir string
+	ttl time.Duration
+}
@@ -0,0 +1,201 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Modes of the recorder.
+const (
+	ModeRecord = "record"
+	ModeReplay = "replay"
+)
+
+// Request is a recorded HTTP request, without its headers so no credential is stored.
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body"`
+}
+
+// Interaction is a request with its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette is a list of interactions stored in a JSON file.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+
+	path string
+	used []bool
+	mu   sync.Mutex
+}
+
+// Load reads the cassette from the file, it's empty if the file does not exist.
+func Load(path string) (*Cassette, error) {
+	c := &Cassette{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+	}
+	c.used = make([]bool, len(c.Interactions))
+	return c, nil
+}
+
+// Save writes the cassette to its file.
+func (c *Cassette) Save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0o600)
+}
+
+// add appends the interaction and saves the cassette.
+func (c *Cassette) add(i Interaction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Interactions = append(c.Interactions, i)
+	c.used = append(c.used, true)
+	return c.Save()
+}
+
+// find returns the first unused interaction matching the request.
+func (c *Cassette) find(r Request) (Interaction, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, v := range c.Interactions {
+		if !c.used[i] && v.Request == r {
+			c.used[i] = true
+			return v, true
+		}
+	}
+	return Interaction{}, false
+}
+
+// Transport is an http.RoundTripper that records the interactions to
+// the cassette or replays them from it without any network access.
+type Transport struct {
+	Origin   http.RoundTripper
+	Cassette *Cassette
+	Mode     string
+}
+
+// NewTransport returns a transport that records or replays the cassette in the file.
+func NewTransport(origin http.RoundTripper, mode, path string) (*Transport, error) {
+	if mode != ModeRecord && mode != ModeReplay {
+		return nil, fmt.Errorf("unknown cassette mode: %s, must be record or replay", mode)
+	}
+	c, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	// a new recording replaces the previous one
+	if mode == ModeRecord {
+		c.Interactions = nil
+		c.used = nil
+	}
+	return &Transport{Origin: origin, Cassette: c, Mode: mode}, nil
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r, err := newRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Mode == ModeReplay {
+		i, ok := t.Cassette.find(r)
+		if !ok {
+			return nil, fmt.Errorf("no recorded interaction for %s %s in %s", r.Method, r.URL, t.Cassette.path)
+		}
+		return i.Response.http(req), nil
+	}
+
+	resp, err := t.Origin.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if err := t.Cassette.add(Interaction{
+		Request: r,
+		Response: Response{
+			StatusCode:  resp.StatusCode,
+			ContentType: resp.Header.Get("Content-Type"),
+			Body:        string(body),
+		},
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// newRequest returns the recorded form of the request, restoring its body.
+func newRequest(req *http.Request) (Request, error) {
+	r := Request{
+		Method: req.Method,
+		URL:    req.URL.String(),
+	}
+	if req.Body == nil {
+		return r, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return r, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	r.Body = string(body)
+	return r, nil
+}
+
+// http returns the recorded response as an HTTP response to the request.
+func (r Response) http(req *http.Request) *http.Response {
+	header := make(http.Header)
+	if r.ContentType != "" {
+		header.Set("Content-Type", r.ContentType)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.StatusCode, http.StatusText(r.StatusCode)),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewBufferString(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
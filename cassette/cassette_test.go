@@ -0,0 +1,80 @@
+package cassette
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordReplay(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"echo":` + string(body) + `}`))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "commit.json")
+	post := func(tr http.RoundTripper, body string) string {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/chat/completions", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer sk-secret")
+		resp, err := (&http.Client{Transport: tr}).Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		out, _ := io.ReadAll(resp.Body)
+		return string(out)
+	}
+
+	rec, err := NewTransport(http.DefaultTransport, ModeRecord, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := post(rec, `"a"`); got != `{"echo":"a"}` {
+		t.Errorf("recorded response = %s", got)
+	}
+	post(rec, `"b"`)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "sk-secret") {
+		t.Error("the cassette contains the API key")
+	}
+
+	// replaying doesn't hit the server
+	srv.Close()
+	rep, err := NewTransport(nil, ModeReplay, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := post(rep, `"b"`); got != `{"echo":"b"}` {
+		t.Errorf("replayed response = %s", got)
+	}
+	if got := post(rep, `"a"`); got != `{"echo":"a"}` {
+		t.Errorf("replayed response = %s", got)
+	}
+	if calls != 2 {
+		t.Errorf("server called %d times, want 2", calls)
+	}
+
+	// every interaction is replayed once
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/chat/completions", strings.NewReader(`"a"`))
+	if _, err := (&http.Client{Transport: rep}).Do(req); err == nil {
+		t.Error("replayed an interaction twice")
+	}
+}
+
+func TestNewTransportMode(t *testing.T) {
+	if _, err := NewTransport(nil, "play", "x.json"); err == nil {
+		t.Error("NewTransport() accepted an unknown mode")
+	}
+}
@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/ask"
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/openai"
+	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/util"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// maxAskFiles is the maximum number of files given to the model with a question.
+const maxAskFiles = 10
+
+// maxAskOthers is the maximum number of the other related files listed by name.
+const maxAskOthers = 20
+
+// defaultAskBudget is the number of tokens of the context of a question when the
+// context window of the model is unknown.
+const defaultAskBudget = 8000
+
+var askNoDiff bool
+
+func init() {
+	askCmd.Flags().BoolVar(&askNoDiff, "no_diff", false, "don't give the current changes of the repository to the model")
+	askCmd.Flags().IntVar(&diffUnified, "diff_unified", 3, "generate diffs with <n> lines of context, default is 3")
+	askCmd.Flags().StringSliceVar(&excludeList, "exclude_list", []string{}, "exclude file from git diff command")
+	askCmd.Flags().StringVar(&commitModel, "model", "gpt-3.5-turbo", "select openai model")
+	askCmd.Flags().StringVar(&commitLang, "lang", "en", "summarizing language uses English by default")
+	askCmd.Flags().IntVar(&maxTokens, "max_tokens", 1000, "the maximum number of tokens to generate in the chat completion.")
+	askCmd.Flags().DurationVarP(&timeout, "timeout", "t", 0, "timeout of every request to the provider, default is openai.timeout")
+	askCmd.Flags().DurationVar(&connectTimeout, "connect_timeout", 0, "timeout of the connection to the provider, default is openai.connect_timeout")
+}
+
+// askFile is a file of the repository given to the model with a question.
+type askFile struct {
+	Path    string
+	Content string
+}
+
+var askCmd = &cobra.Command{
+	Use:   "ask <question>",
+	Short: "Answer a question about the repository and its current changes",
+	Long: `Answer a question about the repository and its current changes, like "why does this
+diff touch the scheduler?" or "what does pkg/util do?". The files and the directories named
+by the question, and the files with its keywords in their path or their content, are given
+to the model with the staged changes, or the working tree changes when nothing is staged.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := check(); err != nil {
+			return err
+		}
+		question := strings.TrimSpace(strings.Join(args, " "))
+		if question == "" {
+			return errors.New("the question is empty")
+		}
+
+		opts := []git.Option{
+			git.WithDiffUnified(viper.GetInt("git.diff_unified")),
+			git.WithExcludeList(viper.GetStringSlice("git.exclude_list")),
+			git.WithMaxFileSize(viper.GetInt64("git.max_file_size")),
+		}
+		g := git.New(opts...)
+
+		var (
+			diff    string
+			changed []string
+			err     error
+		)
+		if !askNoDiff {
+			diff, changed, err = stagedDiff(g)
+			if err == nil && strings.TrimSpace(diff) == "" {
+				diff, changed, err = stagedDiff(git.New(append(opts, git.WithWorkingTree(true))...))
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if diff != "" {
+			diff, err = redactDiff(diff)
+			if err != nil {
+				return err
+			}
+			diff, err = fitDiff(cmd.Context(), diff)
+			if err != nil {
+				return err
+			}
+		}
+
+		budget := diffBudget()
+		switch {
+		case budget == 0:
+			budget = defaultAskBudget
+		case budget < 0:
+			budget = math.MaxInt
+		}
+		files, others, err := askFiles(g, question, budget-openai.EstimateTokens(diff))
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 && diff == "" {
+			if len(others) > 0 {
+				return errors.New("the files related to the question don't fit the context window of the model: " + strings.Join(others, ", "))
+			}
+			return errors.New("there are no files or changes of the repository related to the question")
+		}
+
+		client, err := newClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+		out, err := util.GetTemplateByString(
+			prompt.AskQuestionTemplate,
+			withVars(promptVars(g, changed), util.Data{
+				"question":    question,
+				"files":       files,
+				"other_files": strings.Join(others, ", "),
+				"file_diffs":  diff,
+			}),
+		)
+		if err != nil {
+			return err
+		}
+
+		logger.Info("We are trying to answer the question with " + strconv.Itoa(len(files)) + " file(s) of the repository")
+		// the answer is written while it's generated, the machine output waits for it
+		delta := func(string) {}
+		if !isMachineOutput() {
+			delta = func(s string) { fmt.Fprint(os.Stdout, s) }
+		}
+		resp, err := streamCompletion(cmd.Context(), client, out, delta)
+		if err != nil {
+			return err
+		}
+		if !isMachineOutput() {
+			fmt.Fprintln(os.Stdout)
+		}
+		printUsage(cmd.Context(), resp.Usage)
+		result.Message = strings.TrimSpace(resp.Content)
+		return nil
+	},
+}
+
+// askFiles returns the files related to the question which fit the budget of tokens, the
+// ones it names first, and the names of the other related files. The binary, generated
+// and too large files are skipped.
+func askFiles(g *git.Command, question string, budget int) ([]askFile, []string, error) {
+	tracked, err := g.TrackedFiles()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mentioned := ask.Mentioned(question, tracked)
+	keywords := ask.Keywords(question)
+	hits := map[string]int{}
+	for _, k := range keywords {
+		matches, err := g.GrepFiles(k)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, f := range matches {
+			hits[f]++
+		}
+	}
+	picked := mentioned
+	for _, f := range ask.Rank(tracked, keywords, hits) {
+		if !slices.Contains(mentioned, f) {
+			picked = append(picked, f)
+		}
+	}
+	logger.Debug("keywords of the question: " + strings.Join(keywords, ", "))
+
+	root, err := g.TopLevel()
+	if err != nil {
+		return nil, nil, err
+	}
+	maxSize := viper.GetInt64("git.max_file_size")
+	var (
+		files  []askFile
+		others []string
+	)
+	for _, name := range picked {
+		if len(files) == maxAskFiles || budget <= 0 {
+			if len(others) < maxAskOthers {
+				others = append(others, name)
+			}
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(root, name))
+		if err != nil || bytes.IndexByte(data, 0) >= 0 || git.IsGenerated(name, data) ||
+			(maxSize > 0 && int64(len(data)) > maxSize) {
+			continue
+		}
+		content, err := redactText(name, string(data))
+		if err != nil {
+			return nil, nil, err
+		}
+		cost := openai.EstimateTokens(name + "\n" + content)
+		if cost > budget {
+			if len(others) < maxAskOthers {
+				others = append(others, name)
+			}
+			continue
+		}
+		budget -= cost
+		files = append(files, askFile{Path: name, Content: strings.TrimRight(content, "\n")})
+		logger.Debug("Add " + name + " to the context of the question")
+	}
+	return files, others, nil
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/appleboy/CodeGPT/audit"
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/openai"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var auditDays int
+
+func init() {
+	auditCmd.Flags().IntVar(&auditDays, "days", 0, "only show the records of the last <n> days, default is all")
+}
+
+// auditLog returns the audit log from the audit.file config, in the config folder by
+// default, encrypted with audit.key when it's set, also from the OS keyring.
+func auditLog() (*audit.Log, error) {
+	file := viper.GetString("audit.file")
+	if file == "" {
+		file = filepath.Join(configFolder, "audit.jsonl")
+	}
+	return audit.NewLog(file, audit.WithKey(secret("audit.key")))
+}
+
+// recordAudit appends the prompt sent to the provider and its response to the audit
+// log when audit.enable is set. Only the hash of the prompt is kept, the diff in it
+// is already redacted.
+func recordAudit(ctx context.Context, content string, resp *openai.Response) {
+	r := resultOf(ctx)
+	if !viper.GetBool("audit.enable") || r.Provider == openai.MOCK {
+		return
+	}
+
+	l, err := auditLog()
+	if err != nil {
+		logger.Warn("can't open the audit log: " + err.Error())
+		return
+	}
+
+	// the repo is optional, the command may run outside of a git repository
+	repo, _ := git.New().TopLevel()
+	if err := l.Append(audit.Record{
+		Time:             time.Now(),
+		User:             auditUser(repo),
+		Repo:             repo,
+		Command:          r.Command,
+		Provider:         r.Provider,
+		Model:            r.Model,
+		PromptHash:       audit.Hash(content),
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+		Response:         resp.Content,
+	}); err != nil {
+		logger.Warn("can't write the audit log: " + err.Error())
+	}
+}
+
+// auditUser returns the git user.email of the repository, the login of the system user
+// otherwise.
+func auditUser(repo string) string {
+	if repo != "" {
+		if email := git.New().UserEmail(repo); email != "" {
+			return email
+		}
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show the audit log of the prompts sent to the providers and their responses",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		l, err := auditLog()
+		if err != nil {
+			return err
+		}
+		records, err := l.Records()
+		if err != nil {
+			return err
+		}
+		if auditDays > 0 {
+			since := time.Now().AddDate(0, 0, -auditDays)
+			kept := records[:0]
+			for _, r := range records {
+				if !r.Time.Before(since) {
+					kept = append(kept, r)
+				}
+			}
+			records = kept
+		}
+
+		result.Audit = records
+		if isMachineOutput() {
+			return nil
+		}
+
+		if len(records) == 0 {
+			logger.Info("No request audited yet, set audit.enable to record them")
+			return nil
+		}
+		// the decrypted records, one JSON per line
+		enc := json.NewEncoder(os.Stdout)
+		for _, r := range records {
+			if err := enc.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/appleboy/CodeGPT/batch"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/openai"
+	"github.com/appleboy/CodeGPT/review"
+	"github.com/appleboy/CodeGPT/usage"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	batchRepos       string
+	batchConcurrency int
+)
+
+func init() {
+	batchCmd.Flags().StringVar(&batchRepos, "repos", "", "file with the folders of the repositories, one per line, - to read them from stdin")
+	batchCmd.Flags().IntVar(&batchConcurrency, "concurrency", batch.DefaultConcurrency, "number of repositories processed at the same time")
+	_ = batchCmd.MarkFlagRequired("repos")
+}
+
+// RepoResult is the result of the command in a repository of the batch.
+type RepoResult struct {
+	Repo     string           `json:"repo"`
+	Message  string           `json:"message,omitempty"`
+	Findings []review.Finding `json:"findings,omitempty"`
+	Usage    openai.Usage     `json:"usage"`
+	Error    string           `json:"error,omitempty"`
+}
+
+var batchCmd = &cobra.Command{
+	Use:   "batch --repos <file> commit|review [-- <flags of the command>]",
+	Short: "Run the commit or review command in a list of repositories",
+	Long: `Run the commit or review command in every repository of a list, a few repositories at the
+same time, and print the results of all of them. The flags after -- are given to the command,
+like: codegpt batch --repos repos.txt review -- --profile security
+The command fails when it fails in one of the repositories.`,
+	Args:      cobra.MinimumNArgs(1),
+	ValidArgs: []string{"commit", "review"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if name != "commit" && name != "review" {
+			return errors.New("the command of the batch must be commit or review")
+		}
+		if outputFormat != outputText && outputFormat != outputJSON {
+			return errors.New("the batch command only supports the text and json output")
+		}
+		if dash := cmd.ArgsLenAtDash(); dash > 1 || (dash < 0 && len(args) > 1) {
+			return errors.New("the flags of the command must be given after --, like: batch --repos repos.txt review -- --fail_on HIGH")
+		}
+
+		repos, err := readRepos(batchRepos)
+		if err != nil {
+			return err
+		}
+		if len(repos) == 0 {
+			return errors.New("there are no repositories in " + batchRepos)
+		}
+		if err := checkBudget(); err != nil {
+			return err
+		}
+
+		exe, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		childArgs := append(batchArgs(cmd, name), args[1:]...)
+
+		logger.Info(fmt.Sprintf("Run %s in %d repositories, %d at a time", name, len(repos), batchConcurrency))
+		var mu sync.Mutex
+		results, errs := batch.Run(cmd.Context(), repos, batchConcurrency, func(ctx context.Context, repo string) (RepoResult, error) {
+			r, child, err := runInRepo(ctx, exe, repo, childArgs)
+			mu.Lock()
+			defer mu.Unlock()
+			// the usage is recorded here, the ledger isn't shared by the processes
+			recordRepoUsage(repo, child)
+			if err != nil {
+				logger.Error(repo + ": " + err.Error())
+			} else {
+				logger.Info(repo + ": done")
+			}
+			return r, err
+		})
+
+		failed := 0
+		for i, r := range results {
+			r.Repo = repos[i]
+			if errs[i] != nil {
+				r.Error = errs[i].Error()
+				failed++
+			}
+			result.Repos = append(result.Repos, r)
+		}
+		if !isMachineOutput() {
+			printRepoResults(name, result.Repos)
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%s failed in %d of the %d repositories", name, failed, len(repos))
+		}
+		logger.Info(fmt.Sprintf("Ran %s in the %d repositories", name, len(repos)))
+		return nil
+	},
+}
+
+// readRepos reads the repositories of the file, or of stdin for -.
+func readRepos(name string) ([]string, error) {
+	if name == "-" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		return batch.ParseRepos(os.Stdin, wd)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+	return batch.ParseRepos(f, filepath.Dir(abs))
+}
+
+// batchArgs returns the arguments of the command run in the repositories, with the
+// global flags given to the batch command.
+func batchArgs(cmd *cobra.Command, name string) []string {
+	args := []string{name, "--output", outputJSON}
+	for _, flag := range []string{"config", "config_profile", "log_level", "no_cache"} {
+		if f := cmd.Flags().Lookup(flag); f != nil && f.Changed {
+			args = append(args, "--"+flag+"="+f.Value.String())
+		}
+	}
+	return args
+}
+
+// runInRepo runs the command in the repository and returns its result. The result of
+// the process is also returned when the command fails, with the usage of the requests.
+func runInRepo(ctx context.Context, exe, repo string, args []string) (RepoResult, *Result, error) {
+	if info, err := os.Stat(repo); err != nil || !info.IsDir() {
+		return RepoResult{}, nil, errors.New("the repository folder doesn't exist")
+	}
+
+	c := exec.CommandContext(ctx, exe, args...)
+	c.Dir = repo
+	c.Env = append(os.Environ(), envName("usage.enable")+"=false")
+	var stdout, stderr bytes.Buffer
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	runErr := c.Run()
+
+	var out Result
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		if runErr == nil {
+			runErr = err
+		}
+		return RepoResult{}, nil, fmt.Errorf("%w: %s", runErr, lastLine(stderr.String()))
+	}
+	r := RepoResult{
+		Message:  out.Message,
+		Findings: out.Findings,
+		Usage:    out.Usage,
+	}
+	if out.Error != "" {
+		return r, &out, errors.New(out.Error)
+	}
+	return r, &out, runErr
+}
+
+// recordRepoUsage records the usage of the command run in the repository.
+func recordRepoUsage(repo string, child *Result) {
+	if child == nil || !viper.GetBool("usage.enable") || child.Usage.TotalTokens == 0 || child.Provider == openai.MOCK {
+		return
+	}
+	l, err := ledger()
+	if err != nil {
+		logger.Debug("can't open the usage ledger: " + err.Error())
+		return
+	}
+	if err := l.Add(usage.Record{
+		Time:             startTime,
+		Command:          child.Command,
+		Provider:         child.Provider,
+		Model:            child.Model,
+		Repo:             repo,
+		PromptTokens:     child.Usage.PromptTokens,
+		CompletionTokens: child.Usage.CompletionTokens,
+		TotalTokens:      child.Usage.TotalTokens,
+	}); err != nil {
+		logger.Debug("can't record the usage: " + err.Error())
+	}
+}
+
+// printRepoResults prints the commit messages or the review findings of every repository.
+func printRepoResults(name string, results []RepoResult) {
+	for _, r := range results {
+		if r.Error != "" {
+			color.Red("✘ " + r.Repo + ": " + r.Error)
+			continue
+		}
+		color.Green("✔ " + r.Repo)
+		switch name {
+		case "commit":
+			fmt.Fprintln(os.Stdout, indent(strings.TrimSpace(r.Message)))
+		case "review":
+			_ = review.Render(os.Stdout, review.FormatTable, r.Findings)
+		}
+	}
+}
+
+// lastLine returns the last line of the output, the error of a command.
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	return lines[len(lines)-1]
+}
+
+// indent indents the lines of the text by two spaces.
+func indent(text string) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		if line != "" {
+			sb.WriteString("  " + line)
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/openai"
+	"github.com/appleboy/CodeGPT/platform"
+	"github.com/appleboy/CodeGPT/review"
+	"github.com/appleboy/CodeGPT/util"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// botMarker marks the summary comment of the bot, updated on every review of the pull request.
+const botMarker = "<!-- codegpt-review -->"
+
+// maxPayloadSize is the maximum size of the webhook payloads of GitHub.
+const maxPayloadSize = 25 << 20
+
+var botAddr string
+
+func init() {
+	botCmd.Flags().StringVar(&botAddr, "addr", "", "receive the pull_request webhooks on this address, like :8080, instead of reviewing one pull request")
+	botCmd.Flags().StringVar(&githubRepo, "github_repo", "", "GitHub repository in the owner/name format, default is $GITHUB_REPOSITORY")
+	botCmd.Flags().BoolVar(&dryRun, "dry_run", false, "print the request that would be sent to the provider without sending it")
+	botCmd.Flags().BoolVar(&noPost, "no_post", false, "print the summary and the review instead of posting them")
+	botCmd.Flags().StringVar(&reviewProfile, "profile", review.ProfileGeneral, "review profile, general or security")
+	botCmd.Flags().StringVar(&commitModel, "model", "gpt-3.5-turbo", "select openai model")
+	botCmd.Flags().StringVar(&commitLang, "lang", "en", "summarizing language uses English by default")
+	botCmd.Flags().IntVar(&maxTokens, "max_tokens", 300, "the maximum number of tokens to generate in the chat completion.")
+	botCmd.Flags().DurationVarP(&timeout, "timeout", "t", 0, "timeout of every request to the provider, default is openai.timeout")
+	botCmd.Flags().DurationVar(&connectTimeout, "connect_timeout", 0, "timeout of the connection to the provider, default is openai.connect_timeout")
+}
+
+var botCmd = &cobra.Command{
+	Use:   "bot [<pull request number>]",
+	Short: "Review the GitHub pull requests in GitHub Actions or as a webhook receiver",
+	Long: `Review a GitHub pull request from its diff fetched with the GitHub API, without checkout,
+and post a summary comment with the inline comments of the findings. The summary comment is
+updated on the next runs and the inline comments already posted aren't repeated.
+
+In GitHub Actions, the pull request is the argument or the one of the event of the workflow.
+With --addr, the bot receives the pull_request webhooks of a repository or of a GitHub App,
+and reviews the pull requests when they are opened or get new commits.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := check(); err != nil {
+			return err
+		}
+		if reviewProfile != review.ProfileGeneral && reviewProfile != review.ProfileSecurity {
+			return errors.New("profile must be general or security")
+		}
+
+		client, err := newClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+		b := &bot{client: client}
+		if botAddr != "" {
+			return b.serve(cmd.Context(), botAddr)
+		}
+
+		number, err := botPullRequest(args)
+		if err != nil {
+			return err
+		}
+		gh, err := newGitHub()
+		if err != nil {
+			return err
+		}
+		return b.review(cmd.Context(), gh, currentGitHubRepo(), number)
+	},
+}
+
+// botPullRequest returns the pull request number of the argument, or the one of the
+// event of the GitHub Actions workflow.
+func botPullRequest(args []string) (int, error) {
+	if len(args) > 0 {
+		number, err := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+		if err != nil || number <= 0 {
+			return 0, errors.New("invalid pull request number: " + args[0])
+		}
+		return number, nil
+	}
+
+	name := os.Getenv("GITHUB_EVENT_PATH")
+	if name == "" {
+		return 0, errors.New("the pull request number is required outside of GitHub Actions")
+	}
+	payload, err := os.ReadFile(name)
+	if err != nil {
+		return 0, err
+	}
+	event, err := platform.ParsePullRequestEvent(payload)
+	if err != nil {
+		return 0, err
+	}
+	if event.Number == 0 {
+		return 0, errors.New("the workflow isn't triggered by a pull request, run it on the pull_request event")
+	}
+	return event.Number, nil
+}
+
+// bot reviews the pull requests with a shared client.
+type bot struct {
+	client *openai.Client
+
+	// the reviews share the result of the process, they run one at a time
+	mu sync.Mutex
+	wg sync.WaitGroup
+}
+
+// review reviews the diff of the pull request, updates the summary comment and posts
+// the inline comments not posted by the previous reviews.
+func (b *bot) review(ctx context.Context, gh *platform.GitHub, repo string, number int) error {
+	pr, err := gh.PullRequest(ctx, number)
+	if err != nil {
+		return err
+	}
+	diff, err := gh.PullRequestDiff(ctx, number)
+	if err != nil {
+		return err
+	}
+	diff, err = redactDiff(diff)
+	if err != nil {
+		return err
+	}
+	diff, err = fitDiff(ctx, diff)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(diff) == "" {
+		logger.Info("There are no changes to review in pull request #" + strconv.Itoa(number))
+		return nil
+	}
+
+	var files []string
+	for _, f := range git.ParsePatch(diff) {
+		files = append(files, f.Name())
+	}
+	logger.Info(fmt.Sprintf("Review %s pull request #%d at %s", repo, number, shortSHA(pr.Head.SHA)))
+	findings, err := reviewDiff(ctx, b.client, pullRequestVars(pr, repo, files), diff, reviewProfile)
+	if err != nil || dryRun {
+		return err
+	}
+	result.Findings = findings
+
+	r := platform.NewReview(findings)
+	summary := botMarker + "\n" + r.Body + "\n\n_Reviewed commit " + pr.Head.SHA + "._"
+	posted, err := gh.ReviewComments(ctx, number)
+	if err != nil {
+		return err
+	}
+	r = platform.Dedupe(r, posted)
+	r.CommitID = pr.Head.SHA
+	r.Body = fmt.Sprintf("CodeGPT found %d new issue(s) in commit %s.", len(r.Comments), shortSHA(pr.Head.SHA))
+
+	if noPost {
+		logger.Info("The following summary and review would be posted to pull request #" + strconv.Itoa(number))
+		out, err := json.MarshalIndent(map[string]interface{}{"summary": summary, "review": r}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(color.Output, string(out))
+		return nil
+	}
+
+	if err := gh.UpsertComment(ctx, number, botMarker, summary); err != nil {
+		return err
+	}
+	if len(r.Comments) == 0 {
+		logger.Info("There are no new inline comments for pull request #" + strconv.Itoa(number))
+		return nil
+	}
+	logger.Info(fmt.Sprintf("Post %d inline comments to pull request #%d", len(r.Comments), number))
+	return gh.PostReview(ctx, number, r)
+}
+
+// pullRequestVars returns the prompt variables of the pull request of the repository. It
+// isn't checked out, the git metadata of the server isn't the one of the pull request,
+// the branch and the repository name come from the payload.
+func pullRequestVars(pr *platform.PullRequest, repo string, files []string) util.Data {
+	ticket := git.TicketID(pr.Head.Ref)
+	vars := withVars(settingVars(files), util.Data{
+		"branch_name": pr.Head.Ref,
+		"ticket_id":   ticket,
+		"repo_name":   path.Base(repo),
+	})
+	return withVars(vars, ticketVars(ticket))
+}
+
+// serve receives the pull_request webhooks on the address until the context is canceled.
+func (b *bot) serve(ctx context.Context, addr string) error {
+	webhookSecret := secret("github.webhook_secret")
+	if webhookSecret == "" {
+		return errors.New("github.webhook_secret must be set to verify the webhooks")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/webhook", b.webhook(webhookSecret))
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+	logger.Info("Receive the GitHub webhooks on http://" + addr + "/webhook using " + result.Model + " model")
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	logger.Info("Shut down the webhook server, wait for the reviews in progress")
+	shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := srv.Shutdown(shutdown)
+	b.wg.Wait()
+	return err
+}
+
+// webhook returns the handler of the webhooks signed with the secret. The pull requests
+// are reviewed in the background, GitHub doesn't wait more than 10 seconds for a response.
+func (b *bot) webhook(webhookSecret string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "only support POST method", http.StatusMethodNotAllowed)
+			return
+		}
+		payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadSize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if !platform.VerifySignature(webhookSecret, payload, r.Header.Get("X-Hub-Signature-256")) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		kind := r.Header.Get("X-GitHub-Event")
+		if kind != "pull_request" {
+			// like the ping event sent when the webhook is created
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		event, err := platform.ParsePullRequestEvent(payload)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !event.Reviewable() {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		ctx := context.WithoutCancel(r.Context())
+		b.wg.Add(1)
+		go func() {
+			defer b.wg.Done()
+			if err := b.handle(ctx, event); err != nil {
+				logger.Error(fmt.Sprintf("can't review %s pull request #%d: %s", event.Repository.FullName, event.Number, err))
+			}
+		}()
+	}
+}
+
+// handle reviews the pull request of the event with the token of the GitHub App
+// installation, or github.token when it isn't a GitHub App.
+func (b *bot) handle(ctx context.Context, event *platform.PullRequestEvent) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	provider, model := result.Provider, result.Model
+	defer func() {
+		// the usage is recorded once per review
+		recordUsage(result, startTime)
+		result = &Result{Command: "bot", Provider: provider, Model: model}
+	}()
+	if err := checkBudget(); err != nil {
+		return err
+	}
+
+	token, err := installationToken(ctx, event.Installation.ID)
+	if err != nil {
+		return err
+	}
+	gh, err := platform.NewGitHub(
+		platform.WithToken(token),
+		platform.WithBaseURL(viper.GetString("github.base_url")),
+		platform.WithRepo(event.Repository.FullName),
+	)
+	if err != nil {
+		return err
+	}
+	return b.review(ctx, gh, event.Repository.FullName, event.Number)
+}
+
+// installationToken returns the token of the GitHub App installation when github.app_id
+// and github.app_private_key are set, github.token otherwise.
+func installationToken(ctx context.Context, installation int64) (string, error) {
+	id := viper.GetInt64("github.app_id")
+	if id == 0 || installation == 0 {
+		return secret("github.token"), nil
+	}
+	key, err := os.ReadFile(viper.GetString("github.app_private_key"))
+	if err != nil {
+		return "", err
+	}
+	app, err := platform.NewApp(id, key, platform.WithBaseURL(viper.GetString("github.base_url")))
+	if err != nil {
+		return "", err
+	}
+	return app.InstallationToken(ctx, installation)
+}
+
+// shortSHA returns the abbreviated commit hash.
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
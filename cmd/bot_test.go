@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/appleboy/CodeGPT/platform"
+)
+
+func TestPullRequestVars(t *testing.T) {
+	// the server runs in a repository of another branch and author
+	gitRepo(t)
+
+	pr := &platform.PullRequest{}
+	pr.Head.Ref = "feature/ABC-123-login"
+	vars := pullRequestVars(pr, "appleboy/CodeGPT", []string{"main.go"})
+
+	for k, want := range map[string]interface{}{
+		"branch_name":   "feature/ABC-123-login",
+		"ticket_id":     "ABC-123",
+		"repo_name":     "CodeGPT",
+		"changed_files": []string{"main.go"},
+	} {
+		if got := vars[k]; !reflect.DeepEqual(got, want) {
+			t.Errorf("vars[%q] = %v, want %v", k, got, want)
+		}
+	}
+	for _, k := range []string{"author_name", "author_email"} {
+		if v, ok := vars[k]; ok {
+			t.Errorf("vars[%q] = %v, want the git metadata of the server left out", k, v)
+		}
+	}
+}
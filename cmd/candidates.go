@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// selectCandidate asks which candidate commit message to use and returns the
+// indexes of the chosen ones. Several numbers separated by commas merge the
+// candidates, an empty answer or no input picks the first one.
+func selectCandidate(messages []string, in io.Reader) ([]int, error) {
+	for i, message := range messages {
+		color.New(color.Bold).Printf("[%d] ", i+1)
+		color.Yellow(message + "\n")
+	}
+
+	reader := bufio.NewReader(in)
+	for {
+		fmt.Fprintf(color.Output, "Choose a commit message [1-%d], or several like 1,3 to merge them (default 1): ", len(messages))
+		answer, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			if err == io.EOF {
+				fmt.Fprintln(color.Output)
+			}
+			return []int{0}, nil
+		}
+
+		picked, ok := parseChoices(answer, len(messages))
+		if ok {
+			return picked, nil
+		}
+		color.Red("Invalid choice: " + answer)
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+	}
+}
+
+// parseChoices parses the comma separated numbers of the answer into distinct indexes.
+func parseChoices(answer string, max int) ([]int, bool) {
+	var picked []int
+	seen := map[int]bool{}
+	for _, field := range strings.Split(answer, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n < 1 || n > max {
+			return nil, false
+		}
+		if !seen[n-1] {
+			seen[n-1] = true
+			picked = append(picked, n-1)
+		}
+	}
+	return picked, true
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// ciMode is set by the --ci flag.
+var ciMode bool
+
+// ciCancel ends the deadline of the command in the CI mode.
+var ciCancel context.CancelFunc = func() {}
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&ciMode, "ci", false, "non-interactive mode of the pipelines: no prompt nor color, JSON output, ci.timeout and stable exit codes")
+}
+
+// setupCI turns off the prompts and the colors, writes the JSON output unless another
+// format is asked, and bounds the command with ci.timeout and every request with
+// openai.timeout.
+func setupCI(cmd *cobra.Command) {
+	if !ciMode {
+		return
+	}
+	color.NoColor = true
+	if !cmd.Flags().Changed("output") {
+		outputFormat = outputJSON
+	}
+	// no request waits forever, even without timeout in the config
+	if viper.GetDuration("openai.timeout") <= 0 {
+		viper.Set("openai.timeout", "10s")
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), viper.GetDuration("ci.timeout"))
+	ciCancel = cancel
+	cmd.SetContext(ctx)
+}
+
+// requireInteractive returns a validation error in the CI mode, nothing can be asked.
+func requireInteractive(what string) error {
+	if !ciMode {
+		return nil
+	}
+	return invalid(errors.New(what + " needs an interactive terminal, it can't be used with --ci"))
+}
@@ -5,23 +5,41 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"path"
+	"path/filepath"
 	"strings"
 
+	"github.com/appleboy/CodeGPT/format"
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/lint"
+	"github.com/appleboy/CodeGPT/openai"
+	"github.com/appleboy/CodeGPT/update"
+
 	"github.com/appleboy/com/file"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var rootCmd = &cobra.Command{
-	Short:        "A git prepare-commit-msg hook using ChatGPT",
-	SilenceUsage: true,
-	Args:         cobra.MaximumNArgs(1),
+	Use:               "codegpt",
+	Short:             "A git prepare-commit-msg hook using ChatGPT",
+	SilenceUsage:      true,
+	Args:              cobra.MaximumNArgs(1),
+	PersistentPreRunE: setup,
 }
 
 // Used for flags.
 var (
-	cfgFile  string
+	cfgFile string
+	// configFolder is the config folder of codegpt, found by initConfig.
+	configFolder string
+	logLevel     string
+	quiet        bool
+	noCache      bool
+	profile      string
+
+	recordFile string
+	replayFile string
+
 	replacer = strings.NewReplacer("-", "_", ".", "_")
 )
 
@@ -33,40 +51,146 @@ const (
 func init() {
 	cobra.OnInitialize(initConfig)
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.config/codegpt/.codegpt.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file in YAML, TOML or JSON, default is $CODEGPT_CONFIG or .codegpt.yaml in the config folder")
+	rootCmd.PersistentFlags().StringVar(&profile, "config_profile", "", "config profile to use, default is $CODEGPT_PROFILE")
+	rootCmd.PersistentFlags().StringVar(&outputFormat, "output", outputText, "output format, text or json (review also supports table, markdown and sarif, review and lint support github-actions)")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log_level", "info", "log level, debug, info, warn or error")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no_cache", false, "always send a new request instead of using the cached response")
+	rootCmd.PersistentFlags().StringVar(&recordFile, "record", "", "record the provider HTTP interactions to a cassette file")
+	rootCmd.PersistentFlags().StringVar(&replayFile, "replay", "", "replay the provider HTTP interactions from a cassette file, without network access")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "only print errors, same as --log_level error")
+	rootCmd.PersistentFlags().String("system", "", "system prompt sent before every prompt, like the team style guide")
+	rootCmd.PersistentFlags().Float32("top_p", 1, "nucleus sampling between 0 and 1, lower values consider fewer tokens")
+	rootCmd.PersistentFlags().Float32("frequency_penalty", 0, "penalty between -2 and 2 of the tokens repeated in the response")
+	rootCmd.PersistentFlags().Float32("presence_penalty", 0, "penalty between -2 and 2 of the tokens already in the response, higher values talk about new topics")
+	rootCmd.PersistentFlags().Int("seed", 0, "seed of the chat completions for reproducible responses")
+	_ = viper.BindPFlag("prompt.system", rootCmd.PersistentFlags().Lookup("system"))
+	_ = viper.BindPFlag("openai.top_p", rootCmd.PersistentFlags().Lookup("top_p"))
+	_ = viper.BindPFlag("openai.frequency_penalty", rootCmd.PersistentFlags().Lookup("frequency_penalty"))
+	_ = viper.BindPFlag("openai.presence_penalty", rootCmd.PersistentFlags().Lookup("presence_penalty"))
+	_ = viper.BindPFlag("openai.seed", rootCmd.PersistentFlags().Lookup("seed"))
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(commitCmd)
 	rootCmd.AddCommand(hookCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(lintCommitsCmd)
 	rootCmd.AddCommand(reviewCmd)
+	rootCmd.AddCommand(splitCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(auditCmd)
+	rootCmd.AddCommand(updateCmd)
+	rootCmd.AddCommand(styleCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(translateCmd)
+	rootCmd.AddCommand(promptCmd)
+	rootCmd.AddCommand(modelsCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(uiCmd)
+	rootCmd.AddCommand(semverCmd)
+	rootCmd.AddCommand(releaseNotesCmd)
+	rootCmd.AddCommand(squashCmd)
+	rootCmd.AddCommand(explainCmd)
+	rootCmd.AddCommand(docsCmd)
+	rootCmd.AddCommand(testCmd)
+	rootCmd.AddCommand(askCmd)
+	rootCmd.AddCommand(rewordCmd)
+	rootCmd.AddCommand(botCmd)
+	rootCmd.AddCommand(standupCmd)
+	rootCmd.AddCommand(batchCmd)
 	rootCmd.AddCommand(CompletionCmd)
 
+	// the invalid flags are validation failures
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return invalid(err)
+	})
+
 	// hide completion command
 	rootCmd.CompletionOptions.HiddenDefaultCmd = true
+
+	// redact secrets from the git diff by default
+	viper.SetDefault("redact.enable", true)
+
+	// record the token usage in the local ledger by default
+	viper.SetDefault("usage.enable", true)
+	viper.SetDefault("usage.budget_action", budgetBlock)
+
+	// reuse the responses to unchanged prompts for a week by default
+	viper.SetDefault("cache.enable", true)
+	viper.SetDefault("cache.ttl", "168h")
+
+	// give up on the requests and the connections hanging on the network
+	viper.SetDefault("openai.timeout", "10s")
+	viper.SetDefault("openai.connect_timeout", "5s")
+
+	// the commands of the CI mode are stopped after five minutes
+	viper.SetDefault("ci.timeout", "5m")
+
+	// the updates install the stable releases only
+	viper.SetDefault("update.channel", update.Stable)
+
+	// the style is learned from the last hundred commits
+	viper.SetDefault("style.commits", 100)
+
+	// the prepare-commit-msg hook keeps the existing messages
+	viper.SetDefault("hook.skip", git.DefaultHookSkip)
+
+	// the commit-msg hook rejects the messages breaking Conventional Commits
+	viper.SetDefault("hook.commit_msg", lintReject)
+	viper.SetDefault("lint.types", lint.DefaultTypes)
+	viper.SetDefault("lint.header_max_length", lint.DefaultHeaderMaxLength)
+
+	// the generated messages follow the git conventions whatever the model answers
+	viper.SetDefault("format.strip_period", true)
+	viper.SetDefault("format.body_width", format.DefaultBodyWidth)
+
+	// the HTTP API only listens to the local processes by default
+	viper.SetDefault("serve.addr", "127.0.0.1:8089")
+
+	// check the model against the model list of the provider
+	viper.SetDefault("openai.validate_model", true)
+
+	// block the prompts flagged by the moderation when it's enabled
+	viper.SetDefault("openai.moderation_action", openai.ModerationBlock)
+
+	// describe the removed or changed exported identifiers and config keys in a footer
+	viper.SetDefault("prompt.breaking_change", true)
+
+	// name the functions, methods and types changed by the diff in the prompts
+	viper.SetDefault("prompt.symbols", true)
+	viper.SetDefault("prompt.dependencies", true)
+
+	// the past commits compared to the changes for the similar examples
+	viper.SetDefault("prompt.similar_history", 200)
+
+	// scope the commit messages to the workspace of the monorepo with the changes
+	viper.SetDefault("git.workspaces", true)
+
+	// stream the completions of text-generation-inference token by token
+	viper.SetDefault("huggingface.stream", true)
 }
 
 func initConfig() {
+	// The config folder keeps the usage ledger and the templates, also with another
+	// config file. The legacy ~/.config/codegpt is moved to it once.
+	dir, err := configDir()
+	cobra.CheckErr(err)
+	configFolder = migrateConfigDir(dir)
+
+	if cfgFile == "" {
+		cfgFile = os.Getenv(configEnv)
+	}
 	if cfgFile != "" {
-		// Use config file from the flag.
+		// Use config file from the flag, the format is found from its extension.
 		viper.SetConfigFile(cfgFile)
-		if !file.IsFile(cfgFile) {
-			// Config file not found; ignore error if desired
-			_, err := os.Create(cfgFile)
-			if err != nil {
-				log.Fatal(err)
-			}
-		}
 	} else {
-		// Find home directory.
-		home, err := os.UserHomeDir()
-		cobra.CheckErr(err)
-
-		// Search config in home directory with name ".cobra" (without extension).
-		configFolder := path.Join(home, ".config", "codegpt")
-		viper.AddConfigPath(configFolder)
-		viper.SetConfigType("yaml")
-		viper.SetConfigName(".codegpt")
-		cfgFile = path.Join(configFolder, ".codegpt.yaml")
+		// Search the config in YAML, TOML or JSON in the config folder, YAML by default.
+		cfgFile = findConfigFile(configFolder)
+		if cfgFile == "" {
+			cfgFile = filepath.Join(configFolder, configName+".yaml")
+		}
+		viper.SetConfigFile(cfgFile)
 
 		if !file.IsDir(configFolder) {
 			if err := os.MkdirAll(configFolder, os.ModePerm); err != nil {
@@ -77,6 +201,7 @@ func initConfig() {
 
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(replacer)
+	bindEnv()
 
 	// Support multiple platforms for CI/CD
 	// GitHub Actions need to use `INPUT_` prefix
@@ -88,22 +213,57 @@ func initConfig() {
 		viper.SetEnvPrefix("drone")
 	}
 
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			// Config file not found; ignore error if desired
-			_, err := os.Create(cfgFile)
-			if err != nil {
-				log.Fatal(err)
-			}
-		} else {
-			// Config file was found but another error was produced
-			fmt.Fprintln(os.Stderr, err)
+	if !file.IsFile(cfgFile) {
+		// Config file not found; ignore error if desired
+		if err := createConfigFile(cfgFile); err != nil {
+			log.Fatal(err)
 		}
 	}
+	if err := viper.ReadInConfig(); err != nil {
+		// Config file was found but another error was produced
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// setup prepares the CI mode, the output, the config profile, the repository config, the managed
+// policy, the privacy policy, the tracing and the custom prompt templates before running
+// any command.
+func setup(cmd *cobra.Command, args []string) error {
+	setupCI(cmd)
+	if err := setupOutput(cmd, args); err != nil {
+		return err
+	}
+	// policy > flag > env > repository config > profile > global config
+	if err := applyProfile(cmd); err != nil {
+		return err
+	}
+	if err := applyRepoConfig(cmd); err != nil {
+		return err
+	}
+	if err := applyPolicy(); err != nil {
+		return err
+	}
+	if err := setupPrivacy(); err != nil {
+		return err
+	}
+	setupTracing(cmd)
+	return loadTemplateOverrides()
 }
 
 func Execute(ctx context.Context) {
-	if _, err := rootCmd.ExecuteContextC(ctx); err != nil {
-		os.Exit(1)
+	registerCompletions(rootCmd)
+	cmd, err := rootCmd.ExecuteContextC(ctx)
+	if cmd != nil {
+		result.Command = cmd.Name()
+	}
+	ciCancel()
+	recordUsage(result, startTime)
+	endTracing(err)
+	result.ExitCode = exitCode(ctx, err)
+	if outputFormat == outputJSON {
+		writeResult(cmd, err)
+	}
+	if result.ExitCode != exitOK {
+		os.Exit(result.ExitCode)
 	}
 }
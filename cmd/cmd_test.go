@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// gitRepo changes to a new git repository with a commit of main.go and a staged
+// change of it.
+func gitRepo(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "demo")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(content string) {
+		if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	run("config", "user.name", "CodeGPT")
+	run("config", "user.email", "codegpt@example.com")
+	run("config", "commit.gpgsign", "false")
+	write("package main\n\nfunc main() {\n\tprintln(\"hello\")\n}\n")
+	run("add", "main.go")
+	run("commit", "-q", "-m", "feat: initial commit")
+	write("package main\n\nimport \"os\"\n\nfunc main() {\n\tprintln(os.Args[1])\n}\n")
+	run("add", "main.go")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	return dir
+}
+
+// replay runs the command in the repository of gitRepo, the provider HTTP interactions
+// are replayed from the cassette of testdata without network access. The cassettes
+// were recorded with --record, their URLs are the ones of the OpenAI API.
+func replay(t *testing.T, cassette string, args ...string) (string, error) {
+	t.Helper()
+	cassette, err := filepath.Abs(filepath.Join("testdata", cassette))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := gitRepo(t)
+
+	// an empty config folder, nothing of the user config is used
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+	t.Setenv(configEnv, filepath.Join(home, configName+".yaml"))
+	for k, v := range map[string]interface{}{
+		"openai.provider":       "openai",
+		"openai.api_key":        "sk-test",
+		"openai.base_url":       "",
+		"openai.validate_model": false,
+	} {
+		viper.Set(k, v)
+		// back to the value of the config or the default
+		t.Cleanup(func() { viper.Set(k, nil) })
+	}
+	t.Cleanup(func() {
+		cfgFile = ""
+		replayFile = ""
+		result = &Result{}
+	})
+
+	rootCmd.SetArgs(append(args, "--replay", cassette))
+	return dir, rootCmd.ExecuteContext(context.Background())
+}
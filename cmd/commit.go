@@ -1,16 +1,22 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"html"
+	"io"
 	"os"
-	"path"
-	"strconv"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/appleboy/CodeGPT/format"
 	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
 	"github.com/appleboy/CodeGPT/openai"
 	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/tracing"
 	"github.com/appleboy/CodeGPT/util"
 
 	"github.com/fatih/color"
@@ -32,15 +38,34 @@ var (
 	templateString string
 	commitAmend    bool
 	timeout        time.Duration
+	connectTimeout time.Duration
 	promptOnly     bool
+	showRedactions bool
+
+	commitAll        bool
+	includeUntracked bool
+	autoStage        bool
+	patchMode        bool
 
 	templateVars     []string
 	templateVarsFile string
+
+	commitCandidates int
+
+	hookSource string
+
+	commitStdin  bool
+	commitEdit   bool
+	commitRefine bool
+
+	noBody       bool
+	detailedBody bool
 )
 
 func init() {
 	commitCmd.PersistentFlags().StringP("file", "f", "", "commit message file")
 	commitCmd.PersistentFlags().BoolVar(&preview, "preview", false, "preview commit message")
+	commitCmd.PersistentFlags().StringVar(&hookSource, "hook_source", "", "source of the commit message given by git to the prepare-commit-msg hook")
 	commitCmd.PersistentFlags().IntVar(&diffUnified, "diff_unified", 3, "generate diffs with <n> lines of context, default is 3")
 	commitCmd.PersistentFlags().StringVar(&commitModel, "model", "gpt-3.5-turbo", "select openai model")
 	commitCmd.PersistentFlags().StringVar(&commitLang, "lang", "en", "summarizing language uses English by default")
@@ -52,60 +77,173 @@ func init() {
 	commitCmd.PersistentFlags().StringSliceVar(&templateVars, "template_vars", []string{}, "template variables")
 	commitCmd.PersistentFlags().StringVar(&templateVarsFile, "template_vars_file", "", "template variables file")
 	commitCmd.PersistentFlags().BoolVar(&commitAmend, "amend", false, "replace the tip of the current branch by creating a new commit.")
-	commitCmd.PersistentFlags().DurationVarP(&timeout, "timeout", "t", 10*time.Second, "http timeout")
+	commitCmd.PersistentFlags().DurationVarP(&timeout, "timeout", "t", 0, "timeout of every request to the provider, default is openai.timeout")
+	commitCmd.PersistentFlags().DurationVar(&connectTimeout, "connect_timeout", 0, "timeout of the connection to the provider, default is openai.connect_timeout")
 	commitCmd.PersistentFlags().BoolVar(&promptOnly, "prompt_only", false, "show prompt only, don't send request to openai")
+	commitCmd.PersistentFlags().BoolVar(&dryRun, "dry_run", false, "print the request that would be sent to the provider without sending it")
+	commitCmd.PersistentFlags().BoolVar(&showRedactions, "show_redactions", false, "show the secrets redacted from the git diff")
+	commitCmd.PersistentFlags().BoolVarP(&commitAll, "all", "a", false, "generate the commit message from the working tree changes instead of the staged changes")
+	commitCmd.PersistentFlags().BoolVar(&includeUntracked, "include_untracked", false, "also include the untracked files, implies --all")
+	commitCmd.PersistentFlags().BoolVar(&autoStage, "auto_stage", false, "stage the changes of the tracked files (git add --update) before committing, also the untracked files (git add --all) with --include_untracked")
+	commitCmd.PersistentFlags().BoolVarP(&patchMode, "patch", "p", false, "interactively choose the hunks the commit message is generated from")
+	commitCmd.PersistentFlags().BoolVar(&commitStdin, "stdin", false, "read the diff from stdin and only write the commit message to stdout")
+	commitCmd.PersistentFlags().BoolVarP(&commitEdit, "edit", "e", false, "write the message as a commented suggestion and open the git editor to finalize it")
+	commitCmd.PersistentFlags().BoolVar(&commitRefine, "refine", false, "ask for feedback on the commit message, like \"shorter\", and revise it until it's accepted")
+	commitCmd.PersistentFlags().IntVar(&commitCandidates, "candidates", 1, "generate <n> candidate titles and choose one of them or merge several")
+	commitCmd.PersistentFlags().Bool("structured", false, "get the type, scope, title and body of the commit as one JSON object")
+	commitCmd.PersistentFlags().Int("examples", 0, "use the <n> recent commit titles of the repository as style examples")
+	commitCmd.PersistentFlags().Int("similar", 0, "use the <n> commit titles the closest to the changes as style examples, with the embeddings API")
+	commitCmd.PersistentFlags().BoolVar(&noBody, "no_body", false, "generate the subject of the commit message only, without body")
+	commitCmd.PersistentFlags().BoolVar(&detailedBody, "detailed", false, "generate a body with a bullet point and the rationale of every change")
+	commitCmd.PersistentFlags().String("tone", "", "tone of the commit message: concise, detailed or formal")
+	commitCmd.PersistentFlags().Int("max_subject_length", 0, "maximum length of the commit subject, longer subjects are shortened, default is 50 in the prompt")
 	_ = viper.BindPFlag("output.file", commitCmd.PersistentFlags().Lookup("file"))
+	_ = viper.BindPFlag("prompt.examples", commitCmd.PersistentFlags().Lookup("examples"))
+	_ = viper.BindPFlag("prompt.similar", commitCmd.PersistentFlags().Lookup("similar"))
+	_ = viper.BindPFlag("prompt.structured", commitCmd.PersistentFlags().Lookup("structured"))
+	_ = viper.BindPFlag("prompt.tone", commitCmd.PersistentFlags().Lookup("tone"))
+	_ = viper.BindPFlag("prompt.max_subject_length", commitCmd.PersistentFlags().Lookup("max_subject_length"))
 }
 
 var commitCmd = &cobra.Command{
 	Use:   "commit",
 	Short: "Auto generate commit message",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkCIFlags(); err != nil {
+			return err
+		}
 		if err := check(); err != nil {
 			return err
 		}
 
+		// the hook keeps the messages of the merges, amends, rebases and the like
+		if cmd.Flags().Changed("hook_source") {
+			if reason := skipHook(); reason != "" {
+				logger.Info("Keep the existing commit message, skipped for " + reason)
+				return nil
+			}
+		}
+
+		// the filter mode only writes the message, the progress messages are dropped
+		if commitStdin {
+			if err := checkStdinFlags(); err != nil {
+				return err
+			}
+			color.Output = io.Discard
+		}
+
 		g := git.New(
 			git.WithDiffUnified(viper.GetInt("git.diff_unified")),
 			git.WithExcludeList(viper.GetStringSlice("git.exclude_list")),
 			git.WithEnableAmend(commitAmend),
+			git.WithMaxFileSize(viper.GetInt64("git.max_file_size")),
+			git.WithWorkingTree(commitAll),
+			git.WithIncludeUntracked(includeUntracked),
 		)
-		diff, err := g.DiffFiles()
+		defer g.Cleanup()
+		learnStyle(g)
+
+		_, diffSpan := tracing.Start(cmd.Context(), "diff")
+		var (
+			diff         string
+			changedFiles []string
+			err          error
+		)
+		if commitStdin {
+			diff, changedFiles, err = readDiff(os.Stdin)
+		} else {
+			diff, changedFiles, err = stagedDiff(g)
+		}
 		if err != nil {
 			return err
 		}
 
-		// Update the OpenAI client request timeout if the timeout value is greater than the default openai.timeout
-		if timeout > viper.GetDuration("openai.timeout") {
-			viper.Set("openai.timeout", timeout)
-		}
-
-		currentModel := viper.GetString("openai.model")
-		if viper.GetString("openai.provider") == openai.AZURE {
-			currentModel = viper.GetString("openai.model_name")
-		}
-
-		color.Green("Summarize the commit message use " + currentModel + " model")
-		client, err := openai.New(
-			openai.WithToken(viper.GetString("openai.api_key")),
-			openai.WithModel(viper.GetString("openai.model")),
-			openai.WithOrgID(viper.GetString("openai.org_id")),
-			openai.WithProxyURL(viper.GetString("openai.proxy")),
-			openai.WithSocksURL(viper.GetString("openai.socks")),
-			openai.WithBaseURL(viper.GetString("openai.base_url")),
-			openai.WithTimeout(viper.GetDuration("openai.timeout")),
-			openai.WithMaxTokens(viper.GetInt("openai.max_tokens")),
-			openai.WithTemperature(float32(viper.GetFloat64("openai.temperature"))),
-			openai.WithProvider(viper.GetString("openai.provider")),
-			openai.WithModelName(viper.GetString("openai.model_name")),
-			openai.WithSkipVerify(viper.GetBool("openai.skip_verify")),
-			openai.WithHeaders(viper.GetStringSlice("openai.headers")),
-			openai.WithApiVersion(viper.GetString("openai.api_version")),
+		// unselected hunks are removed from the index before committing
+		var unselected string
+		if patchMode {
+			if commitAmend {
+				return errors.New("the --patch flag can't be used with --amend")
+			}
+			raw, err := g.PatchDiff()
+			if err != nil {
+				return err
+			}
+			files := git.ParsePatch(raw)
+			if err := selectHunks(files, os.Stdin); err != nil {
+				return err
+			}
+			diff = git.FormatPatch(files, true)
+			if strings.TrimSpace(diff) == "" {
+				return errors.New("no hunk selected")
+			}
+			unselected = git.FormatPatch(files, false)
+
+			changedFiles = changedFiles[:0]
+			for _, f := range files {
+				for _, h := range f.Hunks {
+					if h.Selected {
+						changedFiles = append(changedFiles, f.Name())
+						break
+					}
+				}
+			}
+		}
+
+		// scope the prompts to the workspace of the monorepo with the changes
+		ws, shared := changedWorkspace(changedFiles)
+		if ws != nil {
+			logger.Info("The changes belong to the " + ws.Name + " workspace in " + ws.Dir)
+			if len(shared) > 0 && !commitStdin && !patchMode {
+				logger.Debug("Leave the shared files of the workspaces out of the diff: " + strings.Join(shared, ", "))
+				for _, f := range shared {
+					g.Exclude("/" + f)
+				}
+				diff, changedFiles, err = stagedDiff(g)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		// the dependency changes are a table in the prompts instead of the diff of the manifests
+		var dependencies string
+		if !commitStdin && !patchMode {
+			var manifests []string
+			dependencies, manifests = dependencyChanges(g, changedFiles)
+			// a diff of the manifests only is kept
+			if len(manifests) > 0 && len(manifests) < len(changedFiles) {
+				logger.Debug("Leave the manifests out of the diff, only their dependencies changed: " + strings.Join(manifests, ", "))
+				for _, f := range manifests {
+					g.Exclude("/" + f)
+				}
+				diff, changedFiles, err = stagedDiff(g)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		diff, err = redactDiff(diff)
+		if err != nil {
+			return err
+		}
+		diff, err = fitDiff(cmd.Context(), diff)
+		if err != nil {
+			return err
+		}
+		diffSpan.SetAttributes(
+			tracing.Int("codegpt.files", len(changedFiles)),
+			tracing.Int("codegpt.diff_tokens", openai.EstimateTokens(diff)),
 		)
+		diffSpan.End()
+
+		logger.Info("Summarize the commit message use " + currentModel() + " model")
+		client, err := newClient(cmd.Context())
 		if err != nil && !promptOnly {
 			return err
 		}
 
+		promptCtx, promptSpan := tracing.Start(cmd.Context(), "prompt")
 		data := util.Data{}
 		// add template vars
 		if vars := util.ConvertToMap(templateVars); len(vars) > 0 {
@@ -125,182 +263,122 @@ var commitCmd = &cobra.Command{
 			}
 		}
 
-		// Get code review message from diff datas
-		if _, ok := data[prompt.SummarizeMessageKey]; !ok {
-			out, err := util.GetTemplateByString(
-				prompt.SummarizeFileDiffTemplate,
-				util.Data{
-					"file_diffs": diff,
-				},
-			)
-			if err != nil {
-				return err
-			}
-
-			// determine if the user wants to use the prompt only
-			if promptOnly {
-				color.Yellow("====================Prompt========================")
-				color.Yellow("\n" + strings.TrimSpace(out) + "\n\n")
-				color.Yellow("==================================================")
-				return nil
+		// the prompts can use the git context and the template vars
+		vars := withVars(promptVars(g, changedFiles), data)
+		if ws != nil {
+			vars["workspace"] = ws.Name
+			vars["workspace_dir"] = ws.Dir
+		}
+		// the patch mode selects hunks, the symbols are found from the whole files
+		if !commitStdin && !patchMode {
+			if list := changedSymbols(g, changedFiles); len(list) > 0 {
+				vars["changed_symbols"] = list
 			}
+		}
+		if dependencies != "" {
+			vars["dependency_changes"] = dependencies
+		}
 
-			// Get summarize comment from diff datas
-			color.Cyan("We are trying to summarize a git diff")
-			resp, err := client.Completion(cmd.Context(), out)
+		// follow the style of the recent commit titles of the repository
+		if n := viper.GetInt("prompt.examples"); n > 0 {
+			subjects, err := g.RecentSubjects(n * 10)
 			if err != nil {
-				return err
+				logger.Debug("no commit history for the examples: " + err.Error())
+			} else {
+				vars["commit_examples"] = git.Examples(subjects, n)
 			}
-			data[prompt.SummarizeMessageKey] = strings.TrimSpace(resp.Content)
-			color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
-				", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
-				", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
-			)
 		}
 
-		// Get summarize title from diff datas
-		if _, ok := data[prompt.SummarizeTitleKey]; !ok {
-			out, err := util.GetTemplateByString(
-				prompt.SummarizeTitleTemplate,
-				util.Data{
-					"summary_points": data[prompt.SummarizeMessageKey],
-				},
-			)
+		// and of the past commits the closest to the changes
+		if n := viper.GetInt("prompt.similar"); n > 0 && client != nil && !dryRun {
+			similar, err := similarExamples(promptCtx, client, g, diff, changedFiles, n)
 			if err != nil {
-				return err
+				logger.Warn("can't find the similar commits: " + err.Error())
+			} else if len(similar) > 0 {
+				recent, _ := vars["commit_examples"].([]string)
+				vars["commit_examples"] = mergeExamples(similar, recent)
 			}
+		}
 
-			// Get summarize title from diff datas
-			color.Cyan("We are trying to summarize a title for pull request")
-			resp, err := client.Completion(cmd.Context(), out)
-			if err != nil {
-				return err
-			}
-			summarizeTitle := resp.Content
-			color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
-				", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
-				", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
-			)
+		promptSpan.End()
 
-			// lowercase the first character of first word of the commit message and remove last period
-			summarizeTitle = strings.TrimRight(strings.ToLower(string(summarizeTitle[0]))+summarizeTitle[1:], ".")
-			data[prompt.SummarizeTitleKey] = strings.TrimSpace(summarizeTitle)
+		opts := messageOptions{data: data, candidates: commitCandidates}
+		// stdin is the diff in the filter mode, the first candidate is used
+		if outputFormat != outputJSON && !commitStdin && !ciMode {
+			opts.pick = func(messages []string) ([]int, error) {
+				return selectCandidate(messages, os.Stdin)
+			}
 		}
-
-		if _, ok := data[prompt.SummarizePrefixKey]; !ok {
-			out, err := util.GetTemplateByString(
-				prompt.ConventionalCommitTemplate,
-				util.Data{
-					"summary_points": data[prompt.SummarizeMessageKey],
-				},
-			)
-			if err != nil {
-				return err
+		switch {
+		case promptOnly:
+			opts.preview = func(out string) {
+				color.Yellow("====================Prompt========================")
+				color.Yellow("\n" + strings.TrimSpace(out) + "\n\n")
+				color.Yellow("==================================================")
 			}
-			color.Cyan("We are trying to get conventional commit prefix")
-			summaryPrix := ""
-			if client.AllowFuncCall() {
-				resp, err := client.CreateFunctionCall(cmd.Context(), out, openai.SummaryPrefixFunc)
-				if err != nil {
-					return err
-				}
-				if len(resp.Choices) > 0 {
-					args := openai.GetSummaryPrefixArgs(resp.Choices[0].Message.FunctionCall.Arguments)
-					summaryPrix = args.Prefix
-				}
-				color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
-					", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
-					", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
-				)
-			} else {
-				resp, err := client.Completion(cmd.Context(), out)
-				if err != nil {
-					return err
-				}
-				summaryPrix = strings.TrimSpace(resp.Content)
-				color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
-					", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
-					", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
-				)
+		case dryRun:
+			// print what leaves the machine without sending it
+			opts.preview = func(out string) {
+				printDryRun(client, out)
 			}
-			data[prompt.SummarizePrefixKey] = summaryPrix
 		}
 
-		var commitMessage string
-		if viper.GetString("git.template_file") != "" {
-			format, err := os.ReadFile(viper.GetString("git.template_file"))
-			if err != nil {
-				return err
-			}
-			commitMessage, err = util.NewTemplateByString(
-				string(format),
-				data,
-			)
-			if err != nil {
-				return err
-			}
-		} else if viper.GetString("git.template_string") != "" {
-			commitMessage, err = util.NewTemplateByString(
-				viper.GetString("git.template_string"),
-				data,
-			)
-			if err != nil {
-				return err
-			}
-		} else {
-			commitMessage, err = util.GetTemplateByString(
-				git.CommitMessageTemplate,
-				data,
-			)
-			if err != nil {
-				return err
-			}
+		// the files are compared only when the diff is the one of the changes
+		var versions *git.Command
+		if !commitStdin && !patchMode {
+			versions = g
+		}
+		commitMessage, err := generateMessage(cmd.Context(), client, versions, vars, diff, changedFiles, opts)
+		if err != nil {
+			return err
+		}
+		if opts.preview != nil {
+			return nil
 		}
 
-		if prompt.GetLanguage(viper.GetString("output.lang")) != prompt.DefaultLanguage {
-			out, err := util.GetTemplateByString(
-				prompt.TranslationTemplate,
-				util.Data{
-					"output_language": prompt.GetLanguage(viper.GetString("output.lang")),
-					"output_message":  commitMessage,
-				},
-			)
-			if err != nil {
-				return err
-			}
+		result.Message = strings.TrimSpace(commitMessage)
 
-			// translate a git commit message
-			color.Cyan("We are trying to translate a git commit message to " + prompt.GetLanguage(viper.GetString("output.lang")) + " language")
-			resp, err := client.Completion(cmd.Context(), out)
-			if err != nil {
-				return err
-			}
-			color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
-				", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
-				", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
-			)
-			commitMessage = resp.Content
+		if commitStdin {
+			_, err := fmt.Fprintln(os.Stdout, result.Message)
+			return err
 		}
 
-		// unescape html entities in commit message
-		commitMessage = html.UnescapeString(commitMessage)
-
 		// Output commit summary data from AI
 		color.Yellow("================Commit Summary====================")
 		color.Yellow("\n" + strings.TrimSpace(commitMessage) + "\n\n")
 		color.Yellow("==================================================")
 
+		// revise the message with the feedback of the user, in one conversation
+		if commitRefine && outputFormat != outputJSON {
+			commitMessage, err = refineMessage(cmd.Context(), client, vars, data[prompt.SummarizeMessageKey], strings.TrimSpace(commitMessage), os.Stdin)
+			if err != nil {
+				return err
+			}
+			result.Message = commitMessage
+		}
+
 		outputFile := viper.GetString("output.file")
 		if outputFile == "" {
 			out, err := g.GitDir()
 			if err != nil {
 				return err
 			}
-			outputFile = path.Join(strings.TrimSpace(out), "COMMIT_EDITMSG")
+			outputFile = filepath.Join(strings.TrimSpace(out), "COMMIT_EDITMSG")
+		}
+		content := commitMessage
+		if commitEdit {
+			// the commented suggestion is toggled on in the editor
+			suggestion := strings.TrimSpace(commitMessage)
+			if signOff := g.SignOff(); signOff != "" && !preview {
+				suggestion += "\n\n" + signOff
+			}
+			content = git.Suggestion(suggestion, g.CommentChar())
+			logger.Info("Write the commit message as a commented suggestion to " + outputFile + " file")
+		} else {
+			logger.Info("Write the commit message to " + outputFile + " file")
 		}
-		color.Cyan("Write the commit message to " + outputFile + " file")
 		// write commit message to git staging file
-		err = os.WriteFile(outputFile, []byte(commitMessage), 0o644)
+		err = os.WriteFile(outputFile, []byte(content), 0o644)
 		if err != nil {
 			return err
 		}
@@ -309,8 +387,36 @@ var commitCmd = &cobra.Command{
 			return nil
 		}
 
+		// the working tree changes must be staged before committing
+		if commitAll || includeUntracked {
+			if !autoStage {
+				logger.Warn("The message was generated from the working tree, stage your changes or use the --auto_stage flag to commit them")
+				return nil
+			}
+			logger.Info("Stage the changes in the working tree")
+			if err := g.StageAll(); err != nil {
+				return err
+			}
+		}
+
+		if unselected != "" {
+			logger.Info("Unstage the hunks that were not selected")
+			if err := g.UnstagePatch(unselected); err != nil {
+				return err
+			}
+		}
+
+		// finalize the message in the editor of git
+		if commitEdit {
+			logger.Info("Open the git editor, uncomment the suggestion to use it")
+			return g.CommitEdit(outputFile)
+		}
+
 		// git commit automatically
-		color.Cyan("Git record changes to the repository")
+		logger.Info("Git record changes to the repository")
+		if format := g.SigningFormat(); format != "" {
+			logger.Info("Sign the commit using " + format + " key, enter the passphrase if prompted")
+		}
 		output, err := g.Commit(commitMessage)
 		if err != nil {
 			return err
@@ -319,3 +425,559 @@ var commitCmd = &cobra.Command{
 		return nil
 	},
 }
+
+// stagedDiff returns the diff of the changes to commit and the names of the changed files.
+func stagedDiff(g *git.Command) (string, []string, error) {
+	diff, err := g.DiffFiles()
+	if err != nil {
+		return "", nil, err
+	}
+	files, err := g.DiffNames()
+	if err != nil {
+		return "", nil, err
+	}
+	return diff, files, nil
+}
+
+// readDiff reads the unified diff of the filter mode and returns it with the names of
+// the changed files.
+func readDiff(r io.Reader) (string, []string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxDiffSize+1))
+	if err != nil {
+		return "", nil, err
+	}
+	if len(data) > maxDiffSize {
+		return "", nil, errors.New("the diff read from stdin is larger than 10 MiB")
+	}
+	diff := string(data)
+	if strings.TrimSpace(diff) == "" {
+		return "", nil, errors.New("no diff read from stdin, pipe a unified diff like: git diff --staged | codegpt commit --stdin")
+	}
+	var files []string
+	for _, f := range git.ParsePatch(diff) {
+		files = append(files, f.Name())
+	}
+	return diff, files, nil
+}
+
+// checkStdinFlags returns an error for the flags needing the repository or printing
+// something else than the message, which can't be used in the filter mode.
+func checkStdinFlags() error {
+	switch {
+	case outputFormat != outputText:
+		return errors.New("the --stdin flag only writes the commit message, it can't be used with --output " + outputFormat)
+	case patchMode, commitAmend, commitAll, includeUntracked, autoStage:
+		return errors.New("the --stdin flag can't be used with --patch, --amend, --all, --include_untracked or --auto_stage")
+	case promptOnly, dryRun, showRedactions, commitEdit, commitRefine:
+		return errors.New("the --stdin flag can't be used with --prompt_only, --dry_run, --show_redactions, --edit or --refine")
+	}
+	return nil
+}
+
+// checkCIFlags returns an error for the flags asking the user, which can't be used in
+// the CI mode.
+func checkCIFlags() error {
+	if patchMode || commitEdit || commitRefine {
+		return requireInteractive("the --patch, --edit or --refine flag")
+	}
+	return nil
+}
+
+// messageOptions are the options of the commit message generation.
+type messageOptions struct {
+	// data has the values given by the user, like the summary, the title or the prefix,
+	// their prompts are skipped. It's filled with the generated values.
+	data util.Data
+	// candidates is the number of candidate titles to generate, pick returns the indexes
+	// of the candidate messages to use, the first one is used if it's nil
+	candidates int
+	pick       func(messages []string) ([]int, error)
+	// preview prints the prompt of the summary instead of sending it
+	preview func(prompt string)
+	// step is called at the start of every step, delta with the text of the summary and
+	// the title while they're generated
+	step  func(name string) error
+	delta func(string)
+}
+
+// generateMessage runs the prompts of the commit message of the diff: the summary, the
+// structured message, the title, the prefix, the breaking change footer and the translation.
+// The breaking changes are detected from the files of g, from the diff only if it's nil.
+func generateMessage(
+	ctx context.Context,
+	client *openai.Client,
+	g *git.Command,
+	vars util.Data,
+	diff string,
+	files []string,
+	opts messageOptions,
+) (string, error) {
+	// every step is a span, with the prompt build and the provider calls of the step
+	parent := ctx
+	var stepSpan *tracing.Span
+	step := func(name string) error {
+		stepSpan.End()
+		ctx, stepSpan = tracing.Start(parent, name)
+		if opts.step == nil {
+			return nil
+		}
+		return opts.step(name)
+	}
+	defer func() { stepSpan.End() }()
+
+	send := func(content string) (*openai.Response, error) {
+		if opts.delta == nil {
+			return completion(ctx, client, content)
+		}
+		return streamCompletion(ctx, client, content, opts.delta)
+	}
+
+	data := opts.data
+	if data == nil {
+		data = util.Data{}
+	}
+	_, userSummary := data[prompt.SummarizeMessageKey]
+	if !userSummary {
+		if err := step("summary"); err != nil {
+			return "", err
+		}
+		out, err := util.GetTemplateByString(
+			prompt.SummarizeFileDiffTemplate,
+			withVars(vars, util.Data{
+				"file_diffs": diff,
+			}),
+		)
+		if err != nil {
+			return "", err
+		}
+		if opts.preview != nil {
+			opts.preview(out)
+			return "", nil
+		}
+		logger.Info("We are trying to summarize a git diff")
+		resp, err := send(out)
+		if err != nil {
+			return "", err
+		}
+		printUsage(ctx, resp.Usage)
+		data[prompt.SummarizeMessageKey] = strings.TrimSpace(resp.Content)
+	} else if opts.preview != nil {
+		logger.Info("The summary is given by the template vars, there is no prompt of the diff to print")
+		return "", nil
+	}
+
+	// get the type, scope, title and body of the commit as one JSON object
+	if viper.GetBool("prompt.structured") {
+		if err := step("structured message"); err != nil {
+			return "", err
+		}
+		msg, err := structuredMessage(ctx, client, withVars(vars, util.Data{
+			"summary_points": data[prompt.SummarizeMessageKey],
+		}))
+		if err != nil {
+			return "", err
+		}
+
+		prefix := msg.Type
+		if msg.Scope == "" {
+			msg.Scope = mapScope(files)
+		}
+		if msg.Scope != "" {
+			prefix += "(" + msg.Scope + ")"
+		}
+		if msg.Breaking {
+			prefix += "!"
+		}
+		if _, ok := data[prompt.SummarizePrefixKey]; !ok {
+			data[prompt.SummarizePrefixKey] = prefix
+		}
+		if _, ok := data[prompt.SummarizeTitleKey]; !ok {
+			data[prompt.SummarizeTitleKey] = titleOf(msg.Subject)
+		}
+		if body := strings.TrimSpace(msg.Body); body != "" && !userSummary {
+			data[prompt.SummarizeMessageKey] = body
+		}
+		data["breaking_change"] = msg.Breaking
+	}
+
+	var titles []string
+	if _, ok := data[prompt.SummarizeTitleKey]; !ok {
+		if err := step("title"); err != nil {
+			return "", err
+		}
+		out, err := util.GetTemplateByString(
+			prompt.SummarizeTitleTemplate,
+			withVars(vars, util.Data{
+				"summary_points": data[prompt.SummarizeMessageKey],
+			}),
+		)
+		if err != nil {
+			return "", err
+		}
+		logger.Info("We are trying to summarize a title for pull request")
+		var resp *openai.Response
+		if opts.candidates > 1 {
+			resp, err = candidates(ctx, client, out, opts.candidates)
+		} else {
+			resp, err = send(out)
+		}
+		if err != nil {
+			return "", err
+		}
+		printUsage(ctx, resp.Usage)
+		for _, c := range resp.Choices {
+			if title := titleOf(c); title != "" && !contains(titles, title) {
+				titles = append(titles, title)
+			}
+		}
+		data[prompt.SummarizeTitleKey] = titleOf(resp.Content)
+	}
+
+	if _, ok := data[prompt.SummarizePrefixKey]; !ok {
+		if err := step("prefix"); err != nil {
+			return "", err
+		}
+		prefix, err := commitPrefix(ctx, client, vars, data[prompt.SummarizeMessageKey], files)
+		if err != nil {
+			return "", err
+		}
+		data[prompt.SummarizePrefixKey] = prefix
+	}
+
+	footer := ""
+	if viper.GetBool("prompt.breaking_change") && len(breakingChanges(g, diff)) > 0 {
+		if err := step("breaking change"); err != nil {
+			return "", err
+		}
+		var err error
+		footer, err = breakingFooter(ctx, client, g, vars, data[prompt.SummarizeMessageKey], diff)
+		if err != nil {
+			return "", err
+		}
+		data[prompt.SummarizePrefixKey] = breakingPrefix(data[prompt.SummarizePrefixKey])
+		data["breaking_change"] = true
+	}
+
+	stepSpan.End()
+	ctx, stepSpan = tracing.Start(parent, "format")
+	// let the user pick one of the candidate titles or merge several of them
+	if len(titles) > 1 {
+		title, err := pickTitle(ctx, client, vars, data, titles, opts.pick)
+		if err != nil {
+			return "", err
+		}
+		data[prompt.SummarizeTitleKey] = title
+	}
+	message, err := renderMessage(vars, data)
+	if err != nil {
+		return "", err
+	}
+	message = withFooter(message, footer)
+	if prompt.GetLanguage(viper.GetString("output.lang")) != prompt.DefaultLanguage {
+		if err := step("translation"); err != nil {
+			return "", err
+		}
+	}
+	message, err = translateMessage(ctx, client, vars, message)
+	if err != nil {
+		return "", err
+	}
+	return newFormatter().Format(html.UnescapeString(message)), nil
+}
+
+// pickTitle returns the candidate title picked by the pick function, or the merge of the
+// picked titles, from the candidate messages rendered with every title.
+func pickTitle(
+	ctx context.Context,
+	client *openai.Client,
+	vars, data util.Data,
+	titles []string,
+	pick func(messages []string) ([]int, error),
+) (string, error) {
+	var messages []string
+	for _, title := range titles {
+		message, err := renderMessage(vars, withVars(data, util.Data{prompt.SummarizeTitleKey: title}))
+		if err != nil {
+			return "", err
+		}
+		messages = append(messages, newFormatter().Format(html.UnescapeString(message)))
+	}
+	resultOf(ctx).Candidates = messages
+
+	picked := []int{0}
+	if pick != nil {
+		var err error
+		picked, err = pick(messages)
+		if err != nil {
+			return "", err
+		}
+	}
+	if len(picked) == 1 {
+		return titles[picked[0]], nil
+	}
+
+	var merged []string
+	for _, i := range picked {
+		merged = append(merged, titles[i])
+	}
+	out, err := util.GetTemplateByString(
+		prompt.MergeTitlesTemplate,
+		withVars(vars, util.Data{
+			"titles": merged,
+		}),
+	)
+	if err != nil {
+		return "", err
+	}
+	logger.Info("We are trying to merge the selected titles")
+	resp, err := completion(ctx, client, out)
+	if err != nil {
+		return "", err
+	}
+	printUsage(ctx, resp.Usage)
+	return titleOf(resp.Content), nil
+}
+
+// breakingChanges returns the breaking changes of the diff, the Go package APIs are
+// compared from the files of g before and after the changes, skipped when g is nil.
+func breakingChanges(g *git.Command, diff string) []git.BreakingChange {
+	var versions func(string) ([]byte, []byte)
+	if g != nil {
+		versions = g.FileVersions
+	}
+	return git.DetectBreaking(diff, versions)
+}
+
+// breakingFooter asks the model for the BREAKING CHANGE footer with the migration notes
+// when the diff removes or changes the identifiers of the Go package APIs or config keys.
+// It's empty when no breaking change is detected or prompt.breaking_change is disabled.
+func breakingFooter(ctx context.Context, client *openai.Client, g *git.Command, vars util.Data, summary any, diff string) (string, error) {
+	if !viper.GetBool("prompt.breaking_change") {
+		return "", nil
+	}
+	changes := breakingChanges(g, diff)
+	if len(changes) == 0 {
+		return "", nil
+	}
+	var list []string
+	for _, c := range changes {
+		list = append(list, c.String())
+	}
+	out, err := util.GetTemplateByString(
+		prompt.BreakingChangeTemplate,
+		withVars(vars, util.Data{
+			"breaking_changes": list,
+			"summary_points":   summary,
+		}),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	logger.Info("We are trying to describe the breaking changes: " + strings.Join(list, "; "))
+	resp, err := completion(ctx, client, out)
+	if err != nil {
+		return "", err
+	}
+	printUsage(ctx, resp.Usage)
+
+	footer := strings.TrimSpace(resp.Content)
+	footer = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(footer, "```"), "```"))
+	if footer == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(footer, "BREAKING CHANGE:") {
+		footer = "BREAKING CHANGE: " + footer
+	}
+	return footer, nil
+}
+
+// breakingPrefix marks the conventional commit prefix as a breaking change, like feat(api)!.
+func breakingPrefix(prefix any) string {
+	s, _ := prefix.(string)
+	if s == "" || strings.HasSuffix(s, "!") {
+		return s
+	}
+	return s + "!"
+}
+
+// withFooter adds the footer at the end of the message.
+func withFooter(message, footer string) string {
+	if footer == "" {
+		return message
+	}
+	return strings.TrimSpace(message) + "\n\n" + footer
+}
+
+// newFormatter returns the formatter of the prompt.max_subject_length and format config.
+func newFormatter() *format.Formatter {
+	return format.New(
+		format.WithSubjectMaxLength(viper.GetInt("prompt.max_subject_length")),
+		format.WithStripPeriod(viper.GetBool("format.strip_period")),
+		format.WithStripScope(viper.GetBool("format.strip_scope")),
+		format.WithCase(viper.GetString("format.case")),
+		format.WithBodyWidth(viper.GetInt("format.body_width")),
+	)
+}
+
+// commitPrefix asks the model for the conventional commit prefix of the summary,
+// with the scope of the changed files, like feat(cli).
+func commitPrefix(ctx context.Context, client *openai.Client, vars util.Data, summary any, changedFiles []string) (string, error) {
+	out, err := util.GetTemplateByString(
+		prompt.ConventionalCommitTemplate,
+		withVars(vars, util.Data{
+			"summary_points": summary,
+		}),
+	)
+	if err != nil {
+		return "", err
+	}
+	logger.Info("We are trying to get conventional commit prefix")
+	summaryPrix := ""
+	if client.AllowFuncCall() {
+		resp, err := cached(ctx, out+openai.SummaryPrefixFunc.Name, func(ctx context.Context) (*openai.Response, error) {
+			resp, err := client.CreateFunctionCall(ctx, out, openai.SummaryPrefixFunc)
+			if err != nil {
+				return nil, err
+			}
+			r := &openai.Response{Usage: resp.Usage}
+			if args := openai.ToolCalls(resp, openai.SummaryPrefixFunc.Name); len(args) > 0 {
+				prefix, err := openai.GetSummaryPrefixArgs(args[0])
+				if err != nil {
+					return nil, err
+				}
+				r.Content = prefix.Prefix
+			}
+			return r, nil
+		})
+		if err != nil {
+			return "", err
+		}
+		summaryPrix = resp.Content
+		printUsage(ctx, resp.Usage)
+	} else {
+		resp, err := completion(ctx, client, out)
+		if err != nil {
+			return "", err
+		}
+		summaryPrix = strings.TrimSpace(resp.Content)
+		printUsage(ctx, resp.Usage)
+	}
+	// add the scope of the changed files, like feat(cli)
+	if scope := mapScope(changedFiles); scope != "" && !strings.Contains(summaryPrix, "(") {
+		summaryPrix += "(" + scope + ")"
+	}
+	return summaryPrix, nil
+}
+
+// translateMessage translates the commit message to the output.lang language,
+// it's returned as is in the default language.
+func translateMessage(ctx context.Context, client *openai.Client, vars util.Data, message string) (string, error) {
+	lang := prompt.GetLanguage(viper.GetString("output.lang"))
+	if lang == prompt.DefaultLanguage {
+		return message, nil
+	}
+	out, err := util.GetTemplateByString(
+		prompt.TranslationTemplate,
+		withVars(vars, util.Data{
+			"output_message": message,
+		}),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	// translate a git commit message
+	logger.Info("We are trying to translate a git commit message to " + lang + " language")
+	resp, err := completion(ctx, client, out)
+	if err != nil {
+		return "", err
+	}
+	printUsage(ctx, resp.Usage)
+	return resp.Content, nil
+}
+
+// structuredMessage asks the model for the commit message as a JSON object.
+func structuredMessage(ctx context.Context, client *openai.Client, vars util.Data) (openai.CommitMessageParams, error) {
+	out, err := util.GetTemplateByString(prompt.StructuredCommitTemplate, vars)
+	if err != nil {
+		return openai.CommitMessageParams{}, err
+	}
+
+	logger.Info("We are trying to get the structured commit message")
+	resp, err := cached(ctx, out+openai.CommitMessageFunc.Name, func(ctx context.Context) (*openai.Response, error) {
+		return client.Structured(ctx, out, openai.CommitMessageFunc)
+	})
+	if err != nil {
+		return openai.CommitMessageParams{}, err
+	}
+	printUsage(ctx, resp.Usage)
+
+	return openai.GetCommitMessageArgs(resp.Content)
+}
+
+// mapScope returns the scope of the changed files from the git.scope_map setting,
+// or the name of the workspace of the monorepo with the changes.
+func mapScope(files []string) string {
+	if scopes := viper.GetStringMapString("git.scope_map"); len(scopes) > 0 {
+		if scope := git.ScopeOf(files, scopes); scope != "" {
+			return scope
+		}
+	}
+	if ws, _ := changedWorkspace(files); ws != nil {
+		return ws.Name
+	}
+	return ""
+}
+
+// renderMessage renders the commit message from the template file or string of the
+// config, or from the default template.
+func renderMessage(vars, data util.Data) (string, error) {
+	// the summary is still asked for the title, it's left out of the message
+	if viper.GetString("prompt.body") == prompt.BodyNone {
+		data = withVars(data, util.Data{prompt.SummarizeMessageKey: ""})
+	}
+
+	if viper.GetString("git.template_file") != "" {
+		format, err := os.ReadFile(viper.GetString("git.template_file"))
+		if err != nil {
+			return "", err
+		}
+		return util.NewTemplateByString(
+			string(format),
+			withVars(vars, data),
+		)
+	}
+
+	if viper.GetString("git.template_string") != "" {
+		return util.NewTemplateByString(
+			viper.GetString("git.template_string"),
+			withVars(vars, data),
+		)
+	}
+
+	return util.GetTemplateByString(
+		git.CommitMessageTemplate,
+		data,
+	)
+}
+
+// titleOf lowercases the first character of the title and removes the last period.
+func titleOf(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return s
+	}
+	return strings.TrimSpace(strings.TrimRight(strings.ToLower(s[:1])+s[1:], "."))
+}
+
+// skipHook returns why the prepare-commit-msg hook keeps the existing message of the
+// output file, from the hook.skip config, or an empty string to generate a new one.
+func skipHook() string {
+	var message string
+	if file := viper.GetString("output.file"); file != "" {
+		data, _ := os.ReadFile(file)
+		message = util.NormalizeNewlines(string(data))
+	}
+	return git.SkipHook(hookSource, message, git.New().InProgress(), viper.GetStringSlice("hook.skip"))
+}
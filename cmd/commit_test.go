@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCommitReplay(t *testing.T) {
+	dir, err := replay(t, "commit.json", "commit", "--preview")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "feat: print the first command line argument\n\n- Print the first command line argument instead of a fixed greeting"
+	data, err := os.ReadFile(filepath.Join(dir, ".git", "COMMIT_EDITMSG"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != want && got != want+"\n" {
+		t.Errorf("COMMIT_EDITMSG = %q, want %q", got, want)
+	}
+	if result.Usage.TotalTokens != 615 {
+		t.Errorf("total tokens = %d, want the 615 of the cassette", result.Usage.TotalTokens)
+	}
+}
@@ -1,28 +1,189 @@
 package cmd
 
 import (
+	"context"
+	"io"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/appleboy/CodeGPT/format"
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/openai"
+	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/review"
+	"github.com/appleboy/CodeGPT/style"
+	"github.com/appleboy/CodeGPT/update"
+
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
 var CompletionCmd = &cobra.Command{
-	Use:                   "completion [bash|zsh|fish|powershell]",
-	Short:                 "Generate completion script",
-	Long:                  "To load completions",
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate completion script",
+	Long: `Generate the completion script of the shell, covering the commands, the flags,
+the config keys and the model names of the provider.
+
+To load completions:
+
+Bash:
+  $ source <(codegpt completion bash)
+  # for every new session, on Linux:
+  $ codegpt completion bash > /etc/bash_completion.d/codegpt
+  # on macOS:
+  $ codegpt completion bash > $(brew --prefix)/etc/bash_completion.d/codegpt
+
+Zsh:
+  # enable the completion if it isn't already:
+  $ echo "autoload -U compinit; compinit" >> ~/.zshrc
+  $ codegpt completion zsh > "${fpath[1]}/_codegpt"
+
+Fish:
+  $ codegpt completion fish > ~/.config/fish/completions/codegpt.fish
+
+PowerShell:
+  PS> codegpt completion powershell | Out-String | Invoke-Expression
+  # for every new session, add the output to your profile:
+  PS> codegpt completion powershell >> $PROFILE
+`,
 	DisableFlagsInUseLine: true,
 	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
 	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		switch args[0] {
 		case "bash":
-			_ = cmd.Root().GenBashCompletion(os.Stdout)
+			return cmd.Root().GenBashCompletionV2(os.Stdout, true)
 		case "zsh":
-			_ = cmd.Root().GenZshCompletion(os.Stdout)
+			return cmd.Root().GenZshCompletion(os.Stdout)
 		case "fish":
-			_ = cmd.Root().GenFishCompletion(os.Stdout, true)
-		case "powershell":
-			_ = cmd.Root().GenPowerShellCompletionWithDesc(os.Stdout)
+			return cmd.Root().GenFishCompletion(os.Stdout, true)
+		default:
+			return cmd.Root().GenPowerShellCompletionWithDesc(os.Stdout)
 		}
 	},
 }
+
+// modelsCompletionTimeout bounds the request of the model list, the shell waits for it.
+const modelsCompletionTimeout = 3 * time.Second
+
+// configValues are the values of the config keys completed after the key.
+var configValues = map[string][]string{
+	"openai.provider": {
+		openai.OPENAI, openai.AZURE, openai.BEDROCK, openai.MISTRAL,
+		openai.HUGGINGFACE, openai.COMPATIBLE, openai.MOCK,
+	},
+	"prompt.tone":            prompt.Tones,
+	"prompt.body":            prompt.Bodies,
+	"prompt.breaking_change": {"true", "false"},
+	"format.strip_period":    {"true", "false"},
+	"format.case":            format.Cases,
+	"hook.commit_msg":        {lintReject, lintFix},
+	"usage.budget_action":    {budgetBlock, budgetWarn},
+	"update.channel":         {update.Stable, update.Prerelease},
+	"prompt.tense":           style.Tenses,
+}
+
+// completionFunc completes the value of a flag or an argument.
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// registerCompletions adds the dynamic completions of the flags and the arguments, once all
+// the commands and their flags are defined.
+func registerCompletions(root *cobra.Command) {
+	flags := map[string]completionFunc{
+		"model":          completeModels,
+		"lang":           fixedCompletion(prompt.LanguageCodes()...),
+		"output":         fixedCompletion(outputText, outputJSON, review.FormatTable, review.FormatMarkdown, review.FormatSARIF, review.FormatGitHubActions),
+		"config_profile": completeProfiles,
+		"provider":       fixedCompletion(configValues["openai.provider"]...),
+		"profile":        fixedCompletion(review.ProfileGeneral, review.ProfileSecurity),
+		"fail_on":        fixedCompletion("LOW", "MEDIUM", "HIGH", "CRITICAL"),
+		"framework":      fixedCompletion(git.FrameworkHusky, git.FrameworkPreCommit, git.FrameworkLefthook),
+		"by":             fixedCompletion("model", "day", "repo"),
+		"tone":           fixedCompletion(prompt.Tones...),
+		"channel":        fixedCompletion(update.Stable, update.Prerelease),
+	}
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		for name, fn := range flags {
+			// the persistent flags are registered once, on the command defining them
+			if c.LocalNonPersistentFlags().Lookup(name) != nil || c.PersistentFlags().Lookup(name) != nil {
+				_ = c.RegisterFlagCompletionFunc(name, fn)
+			}
+		}
+		for _, sub := range c.Commands() {
+			walk(sub)
+		}
+	}
+	walk(root)
+
+	configCmd.ValidArgsFunction = completeConfig
+	hookCmd.ValidArgsFunction = completeHook
+}
+
+// fixedCompletion completes the given values.
+func fixedCompletion(values ...string) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeModels completes the model names of the provider, from the cached model list
+// or the API. The supported models are completed when they can't be listed.
+func completeModels(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// stdout is the completion, the warnings of the client are dropped
+	color.Output = io.Discard
+
+	ctx, cancel := context.WithTimeout(context.Background(), modelsCompletionTimeout)
+	defer cancel()
+	return modelChoices(ctx), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfiles completes the names of the config profiles.
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names := profileNames()
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeConfig completes the actions of the config command, then the keys
+// and the values of config set, the keys of config get and unset, or the formats
+// of config convert.
+func completeConfig(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch {
+	case len(args) == 0:
+		return []string{"list", "get", "set", "unset", "validate", "convert"}, cobra.ShellCompDirectiveNoFileComp
+	case args[0] == "convert" && len(args) == 1:
+		return configExts, cobra.ShellCompDirectiveNoFileComp
+	case (args[0] == "get" || args[0] == "unset") && len(args) == 1:
+		return availableKeys, cobra.ShellCompDirectiveNoFileComp
+	case args[0] != "set":
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	case len(args) == 1:
+		return availableKeys, cobra.ShellCompDirectiveNoFileComp
+	case len(args) == 2:
+		key := args[1]
+		switch {
+		case key == "openai.model":
+			return completeModels(cmd, args, toComplete)
+		case key == "output.lang":
+			return prompt.LanguageCodes(), cobra.ShellCompDirectiveNoFileComp
+		case strings.HasSuffix(key, "_file") || strings.HasSuffix(key, "_dir") || strings.HasSuffix(key, ".dir"):
+			return nil, cobra.ShellCompDirectiveDefault
+		}
+		return configValues[key], cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeHook completes the actions of the hook command, then the names of the hooks.
+func completeHook(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return []string{"install", "uninstall"}, cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		return []string{git.HookPrepareCommitMessageTemplate, git.HookCommitMessageTemplate}, cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
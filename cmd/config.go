@@ -2,35 +2,158 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
+	"os"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/appleboy/CodeGPT/logger"
+
 	"github.com/appleboy/com/array"
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+var (
+	useKeyring   bool
+	configLocal  bool
+	configGlobal bool
+)
+
 var availableKeys = []string{
 	"git.diff_unified",
 	"git.exclude_list",
 	"git.template_file",
 	"git.template_string",
+	"git.max_file_size",
+	"git.scope_map",
+	"git.workspaces",
 	"openai.socks",
 	"openai.api_key",
+	"openai.api_key_cmd",
 	"openai.model",
 	"openai.org_id",
 	"openai.proxy",
+	"openai.proxy_username",
+	"openai.proxy_password",
+	"openai.proxy_password_cmd",
 	"output.lang",
 	"openai.base_url",
 	"openai.timeout",
+	"openai.requests_per_minute",
+	"openai.tokens_per_minute",
+	"openai.connect_timeout",
 	"openai.max_tokens",
 	"openai.temperature",
+	"openai.top_p",
+	"openai.frequency_penalty",
+	"openai.presence_penalty",
+	"openai.seed",
+	"openai.stop",
+	"openai.embedding_model",
+	"openai.moderation",
+	"openai.moderation_action",
+	"prompt.similar",
+	"prompt.similar_history",
+	"openai.validate_model",
 	"openai.provider",
 	"openai.model_name",
+	"openai.azure_deployments",
 	"openai.skip_verify",
+	"openai.ca_file",
+	"openai.ca_dir",
+	"openai.client_cert",
+	"openai.client_key",
 	"openai.headers",
 	"openai.api_version",
+	"openai.azure_auth",
+	"openai.azure_tenant_id",
+	"openai.azure_client_id",
+	"openai.azure_client_secret",
+	"openai.azure_client_secret_cmd",
+	"huggingface.stream",
+	"bedrock.region",
+	"bedrock.profile",
+	"github.token",
+	"github.token_cmd",
+	"github.repo",
+	"github.base_url",
+	"github.webhook_secret",
+	"github.webhook_secret_cmd",
+	"github.app_id",
+	"github.app_private_key",
+	"gitea.token",
+	"gitea.token_cmd",
+	"gitea.repo",
+	"gitea.base_url",
+	"bitbucket.username",
+	"bitbucket.token",
+	"bitbucket.token_cmd",
+	"bitbucket.repo",
+	"bitbucket.base_url",
+	"jira.base_url",
+	"jira.email",
+	"jira.token",
+	"jira.token_cmd",
+	"notify.slack_webhook",
+	"notify.slack_webhook_cmd",
+	"notify.teams_webhook",
+	"notify.teams_webhook_cmd",
+	"standup.repos",
+	"redact.enable",
+	"redact.patterns",
+	"redact.entropy",
+	"usage.enable",
+	"usage.file",
+	"usage.budget_tokens",
+	"usage.budget_cost",
+	"usage.budget_action",
+	"cache.enable",
+	"cache.ttl",
+	"cache.dir",
+	"cassette.mode",
+	"cassette.file",
+	"mock.response",
+	"prompt.system",
+	"prompt.examples",
+	"prompt.structured",
+	"prompt.tone",
+	"prompt.tense",
+	"prompt.emoji",
+	"prompt.body",
+	"prompt.breaking_change",
+	"prompt.symbols",
+	"prompt.dependencies",
+	"prompt.max_diff_tokens",
+	"prompt.priority_rules",
+	"prompt.compression",
+	"prompt.max_subject_length",
+	"hook.skip",
+	"hook.commit_msg",
+	"lint.types",
+	"lint.scopes",
+	"lint.header_max_length",
+	"format.strip_period",
+	"format.strip_scope",
+	"format.case",
+	"format.body_width",
+	"serve.addr",
+	"serve.token",
+	"serve.token_cmd",
+	"otel.endpoint",
+	"otel.headers",
+	"otel.service_name",
+	"audit.enable",
+	"audit.file",
+	"audit.key",
+	"audit.key_cmd",
+	"privacy.local_only",
+	"privacy.allowed_hosts",
+	"ci.timeout",
+	"update.channel",
+	"style.learn",
+	"style.commits",
 }
 
 func init() {
@@ -49,11 +172,15 @@ func init() {
 	configCmd.PersistentFlags().Float32P("temperature", "", 0.7, "What sampling temperature to use, between 0 and 2. Higher values like 0.8 will make the output more random, while lower values like 0.2 will make it more focused and deterministic.")
 	configCmd.PersistentFlags().StringP("exclude_list", "", "", "exclude file from `git diff` command")
 
-	configCmd.PersistentFlags().StringP("provider", "", "openai", "service provider, only support 'openai' or 'azure'")
+	configCmd.PersistentFlags().StringP("provider", "", "openai", "service provider, only support 'openai', 'azure', 'bedrock', 'mistral', 'huggingface', 'openai_compatible' or 'mock'")
 	configCmd.PersistentFlags().StringP("model_name", "", "", "model deployment name for Azure cognitive service")
 	configCmd.PersistentFlags().BoolP("skip_verify", "", false, "skip verify TLS certificate")
 	configCmd.PersistentFlags().StringP("headers", "", "", "custom headers for openai request")
 	configCmd.PersistentFlags().StringP("api_version", "", "", "openai api version")
+	configCmd.Flags().BoolVar(&useKeyring, "keyring", false, "store the secret in the OS keyring instead of the config file")
+	configCmd.Flags().BoolVar(&configLocal, "local", false, "use the .codegpt.yaml config of the repository")
+	configCmd.Flags().BoolVar(&configGlobal, "global", false, "use the global config file, the default of set and unset")
+	configCmd.MarkFlagsMutuallyExclusive("local", "global")
 
 	_ = viper.BindPFlag("openai.base_url", configCmd.PersistentFlags().Lookup("base_url"))
 	_ = viper.BindPFlag("openai.org_id", configCmd.PersistentFlags().Lookup("org_id"))
@@ -79,33 +206,248 @@ func init() {
 
 var configCmd = &cobra.Command{
 	Use:   "config",
-	Short: "Add openai config (openai.api_key, openai.model ...)",
-	Args:  cobra.MinimumNArgs(3),
+	Short: "Manage the config (list, get, set, unset, validate, convert)",
+	Long: `Manage the config: list the values, get, set or unset a key, validate the config or
+convert the config file to another format. set and unset write the global config file, or
+the .codegpt.yaml file of the repository with --local. list and get show the effective
+config without --local or --global, the secrets are masked by list.`,
+	Args: cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		// Check if command is 'set'
-		if args[0] != "set" {
-			return errors.New("config set key value. ex: config set openai.api_key sk-...")
+		switch args[0] {
+		case "validate":
+			// Check the syntax and the values of the config
+			return runChecks(cmd.Context(), []checkFunc{
+				checkConfigFile,
+				checkConfigValues,
+				checkModel,
+			})
+		case "convert":
+			// Write the config file in another format, YAML, TOML or JSON
+			if len(args) != 2 {
+				return errors.New("config convert format, the format is " + strings.Join(configExts, ", ") + ". ex: config convert toml")
+			}
+			name, err := configTarget(configLocal)
+			if err != nil {
+				return err
+			}
+			target, err := convertConfig(name, args[1])
+			if err != nil {
+				return err
+			}
+			logger.Info("you can see the config file: " + target)
+			return nil
+		case "list":
+			return listConfig()
+		case "get":
+			if len(args) != 2 {
+				return errors.New("config get key. ex: config get openai.model")
+			}
+			return getConfig(args[1])
+		case "set":
+			// the value is prompted when stored in the keyring
+			if len(args) < 2 || (len(args) < 3 && !useKeyring) {
+				return errors.New("config set key value. ex: config set openai.api_key sk-...")
+			}
+			return setConfig(args[1], args[2:])
+		case "unset":
+			if len(args) != 2 {
+				return errors.New("config unset key. ex: config unset openai.proxy")
+			}
+			return unsetConfig(args[1])
 		}
+		return errors.New("config list, config get key, config set key value, config unset key, config validate or config convert format. ex: config set openai.api_key sk-...")
+	},
+}
 
-		// Check if key is available
-		if !array.InSlice(args[1], availableKeys) {
-			return errors.New("available key list: " + strings.Join(availableKeys, ", "))
+// checkConfigKey returns an error when the key isn't a config key, or can't be set in
+// the repository config with --local.
+func checkConfigKey(key string) error {
+	if !array.InSlice(key, availableKeys) {
+		return errors.New("available key list: " + strings.Join(availableKeys, ", "))
+	}
+	if configLocal && !array.InSlice(key, repoConfigKeys) {
+		return errors.New(key + " can only be set in the global config, the repository config supports " + strings.Join(repoConfigKeys, ", "))
+	}
+	return nil
+}
+
+// targetKey returns the key written in the target file, in the active profile of the
+// global config if any.
+func targetKey(key string) string {
+	if configLocal {
+		return key
+	}
+	return profileKey(key)
+}
+
+// listConfig prints the values of the config file with --local or --global, the effective
+// config otherwise, one key=value per line with the secrets masked.
+func listConfig() error {
+	values := map[string]interface{}{}
+	var keys []string
+	if configLocal || configGlobal {
+		name, err := configTarget(configLocal)
+		if err != nil {
+			return err
 		}
+		c, err := loadEditConfig(name)
+		if err != nil {
+			return err
+		}
+		keys = c.Keys()
+		for _, key := range keys {
+			values[key], _ = c.Get(key)
+		}
+	} else {
+		for _, key := range availableKeys {
+			if val := viper.Get(key); formatValue(val) != "" {
+				keys = append(keys, key)
+				values[key] = val
+			}
+		}
+		sort.Strings(keys)
+	}
 
-		// Set config value in viper
-		if args[1] == "git.exclude_list" {
-			viper.Set(args[1], strings.Split(args[2], ","))
-		} else {
-			viper.Set(args[1], args[2])
+	result.Config = map[string]string{}
+	for _, key := range keys {
+		val := formatValue(values[key])
+		if isMaskedKey(key) {
+			val = maskSecret(val)
 		}
+		result.Config[key] = val
+		if !isMachineOutput() {
+			fmt.Println(key + "=" + val)
+		}
+	}
+	return nil
+}
+
+// getConfig prints the value of the key in the config file with --local or --global, its
+// effective value otherwise, with the secrets of the commands and the OS keyring.
+func getConfig(key string) error {
+	if !array.InSlice(key, availableKeys) {
+		return errors.New("available key list: " + strings.Join(availableKeys, ", "))
+	}
 
-		// Write config to file
-		if err := viper.WriteConfig(); err != nil {
+	var val string
+	if configLocal || configGlobal {
+		name, err := configTarget(configLocal)
+		if err != nil {
+			return err
+		}
+		c, err := loadEditConfig(name)
+		if err != nil {
 			return err
 		}
+		v, ok := c.Get(targetKey(key))
+		if !ok {
+			return errors.New(key + " isn't set in " + name)
+		}
+		val = formatValue(v)
+	} else if isSecretKey(key) {
+		val = secret(key)
+	} else {
+		val = formatValue(viper.Get(key))
+	}
 
-		// Print success message with config file location
-		color.Green("you can see the config file: %s", viper.ConfigFileUsed())
-		return nil
-	},
+	result.Config = map[string]string{key: val}
+	if !isMachineOutput() {
+		fmt.Println(val)
+	}
+	return nil
+}
+
+// setConfig writes the value of the key to the target config file, checked against the
+// type of the key. The secrets stored in the OS keyring are removed from the file.
+func setConfig(key string, args []string) error {
+	if err := checkConfigKey(key); err != nil {
+		return err
+	}
+	if useKeyring && configLocal {
+		return errors.New("the secrets of the OS keyring can't be set in the repository config")
+	}
+	name, err := configTarget(configLocal)
+	if err != nil {
+		return err
+	}
+	c, err := loadEditConfig(name)
+	if err != nil {
+		return err
+	}
+
+	// Store secrets in the OS keyring instead of the config file
+	if useKeyring {
+		val := ""
+		if len(args) > 0 {
+			val = args[0]
+		} else {
+			if err := requireInteractive("reading the secret from the prompt"); err != nil {
+				return err
+			}
+			v, err := readSecret(os.Stdin, key)
+			if err != nil {
+				return err
+			}
+			val = v
+		}
+		if err := storeSecret(key, val); err != nil {
+			return err
+		}
+		logger.Info(key + " is stored in the OS keyring")
+		c.Unset(targetKey(key))
+	} else {
+		val, err := configValue(key, args[0])
+		if err != nil {
+			return err
+		}
+		// Set config value, in the active profile if any
+		c.Set(targetKey(key), val)
+	}
+
+	// Write config to file
+	if err := c.Write(); err != nil {
+		return err
+	}
+
+	// Print success message with config file location
+	logger.Info("you can see the config file: " + name)
+	return nil
+}
+
+// unsetConfig removes the key from the target config file, and the secret from the OS
+// keyring for the global config.
+func unsetConfig(key string) error {
+	if err := checkConfigKey(key); err != nil {
+		return err
+	}
+	name, err := configTarget(configLocal)
+	if err != nil {
+		return err
+	}
+	c, err := loadEditConfig(name)
+	if err != nil {
+		return err
+	}
+
+	found := c.Unset(targetKey(key))
+	if isSecretKey(key) && !configLocal {
+		// the OS keyring may not be available, like in CI
+		deleted, err := deleteSecret(key)
+		if err != nil {
+			logger.Debug(err.Error())
+		}
+		if deleted {
+			logger.Info(key + " is removed from the OS keyring")
+		}
+		found = found || deleted
+	}
+	if !found {
+		return errors.New(key + " isn't set in " + name)
+	}
+
+	if err := c.Write(); err != nil {
+		return err
+	}
+	logger.Info("you can see the config file: " + name)
+	return nil
 }
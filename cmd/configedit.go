@@ -0,0 +1,323 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/appleboy/CodeGPT/git"
+
+	"github.com/appleboy/com/file"
+	"github.com/spf13/viper"
+)
+
+// intKeys are the config keys with an integer value.
+var intKeys = []string{
+	"git.diff_unified",
+	"git.max_file_size",
+	"github.app_id",
+	"openai.max_tokens",
+	"openai.seed",
+	"openai.requests_per_minute",
+	"openai.tokens_per_minute",
+	"prompt.compression",
+	"prompt.examples",
+	"prompt.max_diff_tokens",
+	"prompt.max_subject_length",
+	"prompt.similar",
+	"prompt.similar_history",
+	"usage.budget_tokens",
+	"lint.header_max_length",
+	"format.body_width",
+	"style.commits",
+}
+
+// floatKeys are the config keys with a number value.
+var floatKeys = []string{
+	"openai.temperature",
+	"openai.top_p",
+	"openai.frequency_penalty",
+	"openai.presence_penalty",
+	"redact.entropy",
+	"usage.budget_cost",
+}
+
+// boolKeys are the config keys with a true or false value.
+var boolKeys = []string{
+	"git.workspaces",
+	"openai.moderation",
+	"openai.skip_verify",
+	"openai.validate_model",
+	"huggingface.stream",
+	"redact.enable",
+	"usage.enable",
+	"cache.enable",
+	"audit.enable",
+	"privacy.local_only",
+	"prompt.structured",
+	"prompt.breaking_change",
+	"prompt.symbols",
+	"prompt.dependencies",
+	"format.strip_period",
+	"format.strip_scope",
+	"prompt.emoji",
+	"style.learn",
+}
+
+// durationKeys are the config keys with a duration value, like 30s or 1h.
+var durationKeys = []string{
+	"openai.timeout",
+	"openai.connect_timeout",
+	"cache.ttl",
+	"ci.timeout",
+}
+
+// valueRanges are the bounds of the number values.
+var valueRanges = map[string][2]float64{
+	"openai.temperature":       {0, 2},
+	"openai.top_p":             {0, 1},
+	"openai.frequency_penalty": {-2, 2},
+	"openai.presence_penalty":  {-2, 2},
+	"prompt.compression":       {0, 100},
+}
+
+// configValue converts the raw value of the config key to its type, an error when the
+// value isn't valid for the key, like a temperature which isn't a number.
+func configValue(key, raw string) (interface{}, error) {
+	var val interface{}
+	switch {
+	case slices.Contains(intKeys, key):
+		n, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an integer, got %q", key, raw)
+		}
+		if err := checkRange(key, float64(n)); err != nil {
+			return nil, err
+		}
+		val = int(n)
+	case slices.Contains(floatKeys, key):
+		f, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number, got %q", key, raw)
+		}
+		if err := checkRange(key, f); err != nil {
+			return nil, err
+		}
+		val = f
+	case slices.Contains(boolKeys, key):
+		b, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false, got %q", key, raw)
+		}
+		val = b
+	case slices.Contains(durationKeys, key):
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%s must be a positive duration like 30s or 1h, got %q", key, raw)
+		}
+		val = d.String()
+	default:
+		val = parseValue(key, raw)
+	}
+
+	if values := configValues[key]; len(values) > 0 && raw != "" && !slices.Contains(values, raw) {
+		if _, ok := val.(bool); !ok {
+			return nil, fmt.Errorf("%s must be one of %s, got %q", key, strings.Join(values, ", "), raw)
+		}
+	}
+	return val, nil
+}
+
+// checkRange returns an error when the number value of the key is out of its bounds.
+func checkRange(key string, val float64) error {
+	r, ok := valueRanges[key]
+	if !ok || (val >= r[0] && val <= r[1]) {
+		return nil
+	}
+	return fmt.Errorf("%s must be between %s and %s, got %s", key,
+		strconv.FormatFloat(r[0], 'f', -1, 64), strconv.FormatFloat(r[1], 'f', -1, 64),
+		strconv.FormatFloat(val, 'f', -1, 64))
+}
+
+// configTarget returns the config file edited by the config command: the .codegpt.yaml
+// file of the repository root with local, which may not exist yet, the global one otherwise.
+func configTarget(local bool) (string, error) {
+	if !local {
+		return viper.ConfigFileUsed(), nil
+	}
+	root, err := git.New().TopLevel()
+	if err != nil {
+		return "", errors.New("--local needs a git repository")
+	}
+	if name := findConfigFile(root); name != "" {
+		return name, nil
+	}
+	return filepath.Join(root, configName+".yaml"), nil
+}
+
+// editConfig is a config file edited by the config command. Only the settings of the
+// file are written back, not the defaults, the environment or the other config files.
+type editConfig struct {
+	name     string
+	settings map[string]interface{}
+}
+
+// loadEditConfig reads the config file, empty when it doesn't exist yet.
+func loadEditConfig(name string) (*editConfig, error) {
+	c := &editConfig{name: name, settings: map[string]interface{}{}}
+	if !file.IsFile(name) {
+		return c, nil
+	}
+	v := viper.New()
+	v.SetConfigFile(name)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	c.settings = v.AllSettings()
+	return c, nil
+}
+
+// Get returns the value of the dotted key in the file.
+func (c *editConfig) Get(key string) (interface{}, bool) {
+	var val interface{} = c.settings
+	for _, p := range strings.Split(key, ".") {
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if val, ok = m[p]; !ok {
+			return nil, false
+		}
+	}
+	return val, true
+}
+
+// Set sets the value of the dotted key.
+func (c *editConfig) Set(key string, val interface{}) {
+	setNested(c.settings, key, val)
+}
+
+// Unset removes the dotted key and its parents left empty, it reports whether the key
+// was in the file.
+func (c *editConfig) Unset(key string) bool {
+	return unsetNested(c.settings, strings.Split(key, "."))
+}
+
+func unsetNested(m map[string]interface{}, parts []string) bool {
+	if len(parts) == 1 {
+		_, ok := m[parts[0]]
+		delete(m, parts[0])
+		return ok
+	}
+	child, ok := m[parts[0]].(map[string]interface{})
+	if !ok || !unsetNested(child, parts[1:]) {
+		return false
+	}
+	if len(child) == 0 {
+		delete(m, parts[0])
+	}
+	return true
+}
+
+// Keys returns the dotted keys of the values of the file, sorted.
+func (c *editConfig) Keys() []string {
+	var keys []string
+	var walk func(prefix string, m map[string]interface{})
+	walk = func(prefix string, m map[string]interface{}) {
+		for k, v := range m {
+			// a map value like git.scope_map is listed as a whole
+			if child, ok := v.(map[string]interface{}); ok && !slices.Contains(availableKeys, baseKey(prefix+k)) {
+				walk(prefix+k+".", child)
+				continue
+			}
+			keys = append(keys, prefix+k)
+		}
+	}
+	walk("", c.settings)
+	sort.Strings(keys)
+	return keys
+}
+
+// Write writes the settings back to the file, readable only by the user.
+func (c *editConfig) Write() error {
+	v := viper.New()
+	if err := v.MergeConfigMap(c.settings); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.name), os.ModePerm); err != nil {
+		return err
+	}
+	if err := v.WriteConfigAs(c.name); err != nil {
+		return err
+	}
+	return os.Chmod(c.name, 0o600)
+}
+
+// baseKey returns the config key without the profiles.<name>. prefix of a profile.
+func baseKey(key string) string {
+	if strings.HasPrefix(key, "profiles.") {
+		if parts := strings.SplitN(key, ".", 3); len(parts) == 3 {
+			return parts[2]
+		}
+	}
+	return key
+}
+
+// isMaskedKey reports whether the value of the key is a secret hidden by config list,
+// also in a profile.
+func isMaskedKey(key string) bool {
+	return isSecretKey(baseKey(key))
+}
+
+// maskSecret hides the secret value but its last 4 characters, the short ones entirely.
+func maskSecret(val string) string {
+	if val == "" {
+		return ""
+	}
+	if len(val) < 12 {
+		return "********"
+	}
+	return "********" + val[len(val)-4:]
+}
+
+// formatValue returns the value of a key as written on the command line: the lists and
+// the maps comma-separated, like parseValue reads them.
+func formatValue(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ",")
+	case []string:
+		return strings.Join(v, ",")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		items := make([]string, 0, len(v))
+		for _, k := range keys {
+			items = append(items, k+"="+fmt.Sprint(v[k]))
+		}
+		return strings.Join(items, ",")
+	case map[string]string:
+		m := make(map[string]interface{}, len(v))
+		for k, s := range v {
+			m[k] = s
+		}
+		return formatValue(m)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfigValue(t *testing.T) {
+	tests := []struct {
+		key     string
+		raw     string
+		want    interface{}
+		wantErr bool
+	}{
+		{"openai.max_tokens", " 500 ", 500, false},
+		{"openai.max_tokens", "five", nil, true},
+		{"openai.max_tokens", "1.5", nil, true},
+		{"openai.temperature", "0.2", 0.2, false},
+		{"openai.temperature", "2", 2.0, false},
+		{"openai.temperature", "2.5", nil, true},
+		{"openai.temperature", "hot", nil, true},
+		{"openai.top_p", "-0.1", nil, true},
+		{"openai.frequency_penalty", "-2", -2.0, false},
+		{"prompt.compression", "101", nil, true},
+		{"openai.validate_model", "false", false, false},
+		{"openai.validate_model", "no", nil, true},
+		{"openai.timeout", "30s", "30s", false},
+		{"cache.ttl", "1h", "1h0m0s", false},
+		{"openai.timeout", "-1s", nil, true},
+		{"openai.timeout", "30", nil, true},
+		{"openai.provider", "mock", "mock", false},
+		{"openai.provider", "claude", nil, true},
+		{"prompt.breaking_change", "true", true, false},
+		{"git.exclude_list", "*.lock,dist/", []string{"*.lock", "dist/"}, false},
+		{"git.scope_map", "cmd/=cli", map[string]interface{}{"cmd/": "cli"}, false},
+		{"openai.model", "gpt-4o", "gpt-4o", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key+"="+tt.raw, func(t *testing.T) {
+			got, err := configValue(tt.key, tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("configValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("configValue() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckRange(t *testing.T) {
+	tests := []struct {
+		key     string
+		val     float64
+		wantErr bool
+	}{
+		{"openai.temperature", 0, false},
+		{"openai.temperature", 2, false},
+		{"openai.temperature", 2.01, true},
+		{"openai.presence_penalty", -2.5, true},
+		{"prompt.compression", 50, false},
+		// the keys without bounds accept any value
+		{"openai.max_tokens", 1e9, false},
+	}
+
+	for _, tt := range tests {
+		if err := checkRange(tt.key, tt.val); (err != nil) != tt.wantErr {
+			t.Errorf("checkRange(%s, %v) error = %v, wantErr %v", tt.key, tt.val, err, tt.wantErr)
+		}
+	}
+}
+
+func TestEditConfigUnset(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		ok   bool
+		want map[string]interface{}
+	}{
+		{
+			name: "leaf",
+			key:  "openai.model",
+			ok:   true,
+			want: map[string]interface{}{
+				"openai":   map[string]interface{}{"max_tokens": 300},
+				"profiles": map[string]interface{}{"work": map[string]interface{}{"openai": map[string]interface{}{"model": "gpt-4o"}}},
+			},
+		},
+		{
+			name: "empty parents",
+			key:  "profiles.work.openai.model",
+			ok:   true,
+			want: map[string]interface{}{
+				"openai": map[string]interface{}{"model": "gpt-4o-mini", "max_tokens": 300},
+			},
+		},
+		{
+			name: "missing",
+			key:  "profiles.home.openai.model",
+			ok:   false,
+			want: map[string]interface{}{
+				"openai":   map[string]interface{}{"model": "gpt-4o-mini", "max_tokens": 300},
+				"profiles": map[string]interface{}{"work": map[string]interface{}{"openai": map[string]interface{}{"model": "gpt-4o"}}},
+			},
+		},
+		{
+			name: "through a value",
+			key:  "openai.model.name",
+			ok:   false,
+			want: map[string]interface{}{
+				"openai":   map[string]interface{}{"model": "gpt-4o-mini", "max_tokens": 300},
+				"profiles": map[string]interface{}{"work": map[string]interface{}{"openai": map[string]interface{}{"model": "gpt-4o"}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &editConfig{settings: map[string]interface{}{}}
+			c.Set("openai.model", "gpt-4o-mini")
+			c.Set("openai.max_tokens", 300)
+			c.Set("profiles.work.openai.model", "gpt-4o")
+
+			if ok := c.Unset(tt.key); ok != tt.ok {
+				t.Errorf("Unset(%s) = %v, want %v", tt.key, ok, tt.ok)
+			}
+			if !reflect.DeepEqual(c.settings, tt.want) {
+				t.Errorf("settings = %v, want %v", c.settings, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditConfigWrite(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "codegpt", "config.yaml")
+	c, err := loadEditConfig(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Set("openai.api_key", "sk-test")
+	c.Set("profiles.work.openai.model", "gpt-4o")
+	if err := c.Write(); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("mode = %v, want 0600", perm)
+	}
+	c, err = loadEditConfig(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := c.Keys(); !reflect.DeepEqual(keys, []string{"openai.api_key", "profiles.work.openai.model"}) {
+		t.Errorf("Keys() = %q", keys)
+	}
+}
+
+func TestMaskSecret(t *testing.T) {
+	tests := []struct {
+		val  string
+		want string
+	}{
+		{"", ""},
+		{"short", "********"},
+		{"12345678901", "********"},
+		{"sk-abcdefghijklmnop", "********mnop"},
+	}
+
+	for _, tt := range tests {
+		if got := maskSecret(tt.val); got != tt.want {
+			t.Errorf("maskSecret(%q) = %q, want %q", tt.val, got, tt.want)
+		}
+	}
+}
+
+func TestIsMaskedKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"openai.api_key", true},
+		{"profiles.work.openai.api_key", true},
+		{"openai.model", false},
+		{"profiles.work.openai.model", false},
+	}
+
+	for _, tt := range tests {
+		if got := isMaskedKey(tt.key); got != tt.want {
+			t.Errorf("isMaskedKey(%s) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
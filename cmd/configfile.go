@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/logger"
+
+	"github.com/appleboy/com/file"
+	"github.com/spf13/viper"
+)
+
+// configEnv is the environment variable of the config file, overridden by --config.
+const configEnv = "CODEGPT_CONFIG"
+
+// configDir returns the config folder of codegpt: $XDG_CONFIG_HOME/codegpt when it's set,
+// the config folder of the platform otherwise, ~/.config on Linux, ~/Library/Application
+// Support on macOS and %AppData% on Windows.
+func configDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, "codegpt"), nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "codegpt"), nil
+}
+
+// legacyConfigDir is the config folder of the older versions, ~/.config/codegpt on every
+// platform.
+func legacyConfigDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "codegpt"), nil
+}
+
+// migrateConfigDir moves the legacy config folder, with the config file, the usage ledger
+// and the templates, to the config folder when it doesn't exist yet. The legacy folder is
+// kept on error, it's returned to be used instead.
+func migrateConfigDir(dir string) string {
+	legacy, err := legacyConfigDir()
+	if err != nil || legacy == dir || !file.IsDir(legacy) || file.IsDir(dir) {
+		return dir
+	}
+	if err := os.MkdirAll(filepath.Dir(dir), os.ModePerm); err != nil {
+		logger.Warn("can't migrate the config folder " + legacy + ": " + err.Error())
+		return legacy
+	}
+	if err := os.Rename(legacy, dir); err != nil {
+		logger.Warn("can't migrate the config folder " + legacy + ": " + err.Error())
+		return legacy
+	}
+	logger.Info("Move the config folder " + legacy + " to " + dir)
+	return dir
+}
+
+// configName is the name of the config files without extension.
+const configName = ".codegpt"
+
+// configExts are the extensions of the supported config formats, the first file found
+// in this order is read.
+var configExts = []string{"yaml", "yml", "toml", "json"}
+
+// findConfigFile returns the config file of the directory in any supported format,
+// empty if there's none.
+func findConfigFile(dir string) string {
+	for _, ext := range configExts {
+		name := filepath.Join(dir, configName+"."+ext)
+		if file.IsFile(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// configFormat returns the format of the config file from its extension, an error for
+// the unsupported ones.
+func configFormat(name string) (string, error) {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(name)), ".")
+	for _, e := range configExts {
+		if ext == e {
+			return ext, nil
+		}
+	}
+	return "", errors.New("unsupported config format " + filepath.Ext(name) + " of " + name +
+		", the extension must be ." + strings.Join(configExts, ", ."))
+}
+
+// createConfigFile creates an empty config file, an empty object in JSON.
+func createConfigFile(name string) error {
+	var content []byte
+	if ext, _ := configFormat(name); ext == "json" {
+		content = []byte("{}\n")
+	}
+	return os.WriteFile(name, content, 0o600)
+}
+
+// convertConfig writes the config file in the format, with the same name and the new
+// extension. The old file is renamed with the .bak suffix, so it isn't read anymore.
+func convertConfig(name, format string) (string, error) {
+	format = strings.TrimPrefix(strings.ToLower(format), ".")
+	if _, err := configFormat("." + format); err != nil {
+		return "", errors.New("the format must be one of " + strings.Join(configExts, ", "))
+	}
+	if name == "" || !file.IsFile(name) {
+		return "", errors.New("there is no config file to convert")
+	}
+	target := strings.TrimSuffix(name, filepath.Ext(name)) + "." + format
+	if target == name {
+		return "", errors.New(name + " is already in the " + format + " format")
+	}
+	if file.IsFile(target) {
+		return "", errors.New(target + " already exists, remove it first")
+	}
+
+	v := viper.New()
+	v.SetConfigFile(name)
+	if err := v.ReadInConfig(); err != nil {
+		return "", err
+	}
+	if err := v.WriteConfigAs(target); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(target, 0o600); err != nil {
+		return "", err
+	}
+	if err := os.Rename(name, name+".bak"); err != nil {
+		return "", err
+	}
+	logger.Info("Rename the old config file to " + name + ".bak")
+	return target, nil
+}
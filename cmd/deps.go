@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"github.com/appleboy/CodeGPT/deps"
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+
+	"github.com/spf13/viper"
+)
+
+// maxDependencies is the maximum number of dependency changes listed in the prompts.
+const maxDependencies = 30
+
+// dependencyChanges returns the table of the dependencies changed in the manifests and
+// the lock files, even the ones excluded from the diff, and the changed files which are
+// manifests with only changes of the dependencies. It's empty when prompt.dependencies
+// is disabled, the files which can't be parsed are skipped.
+func dependencyChanges(g *git.Command, changed []string) (string, []string) {
+	if !viper.GetBool("prompt.dependencies") {
+		return "", nil
+	}
+	files, err := g.AllDiffNames()
+	if err != nil {
+		logger.Debug("can't list the changed files: " + err.Error())
+		return "", nil
+	}
+
+	inDiff := map[string]bool{}
+	for _, f := range changed {
+		inDiff[f] = true
+	}
+
+	var (
+		changes   []deps.Change
+		manifests []string
+	)
+	for _, f := range files {
+		if !deps.Supported(f) {
+			continue
+		}
+		before, after := g.FileVersions(f)
+		c, ok, err := deps.Diff(f, before, after)
+		if err != nil {
+			logger.Debug("can't parse the dependencies of " + f + ": " + err.Error())
+			continue
+		}
+		changes = append(changes, c...)
+		if ok && inDiff[f] {
+			manifests = append(manifests, f)
+		}
+	}
+	return deps.Table(changes, maxDependencies), manifests
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/docgen"
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/util"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var docsWrite bool
+
+func init() {
+	docsCmd.Flags().BoolVarP(&docsWrite, "write", "w", false, "write the comments to the files instead of printing the patch")
+	docsCmd.Flags().StringVar(&commitModel, "model", "gpt-3.5-turbo", "select openai model")
+	docsCmd.Flags().StringVar(&commitLang, "lang", "en", "summarizing language uses English by default")
+	docsCmd.Flags().IntVar(&maxTokens, "max_tokens", 1000, "the maximum number of tokens to generate in the chat completion.")
+	docsCmd.Flags().DurationVarP(&timeout, "timeout", "t", 0, "timeout of every request to the provider, default is openai.timeout")
+	docsCmd.Flags().DurationVar(&connectTimeout, "connect_timeout", 0, "timeout of the connection to the provider, default is openai.connect_timeout")
+}
+
+var docsCmd = &cobra.Command{
+	Use:   "docs <file or package>...",
+	Short: "Generate the doc comments of the exported symbols without documentation",
+	Long: `Generate the doc comments of the exported symbols without documentation: the Go doc
+comments, the Python docstrings and the JSDoc of JavaScript and TypeScript. The argument is
+a file, a directory for its files, or a directory followed by /... for its files and
+subdirectories, like ./... for the whole repository. The comments are printed as a patch
+to review and apply with git apply, or written to the files with --write.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := check(); err != nil {
+			return err
+		}
+
+		files, err := docsFiles(args)
+		if err != nil {
+			return err
+		}
+
+		g := git.New(git.WithExcludeList(viper.GetStringSlice("git.exclude_list")))
+		root, _ := g.TopLevel()
+		client, err := newClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		var patches []string
+		documented := 0
+		for _, file := range files {
+			if g.Excluded(patchName(root, file)) {
+				logger.Warn("Skip the excluded file " + file)
+				continue
+			}
+			info, err := os.Stat(file)
+			if err != nil {
+				return err
+			}
+			if limit := viper.GetInt64("git.max_file_size"); limit > 0 && info.Size() > limit {
+				logger.Warn("Skip the large file " + file)
+				continue
+			}
+			src, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			symbols, err := docgen.Find(file, src)
+			if err != nil {
+				return err
+			}
+			if len(symbols) == 0 {
+				continue
+			}
+			source, err := redactText(file, string(src))
+			if err != nil {
+				return err
+			}
+
+			out, err := util.GetTemplateByString(
+				prompt.DocCommentsTemplate,
+				withVars(promptVars(g, nil), util.Data{
+					"language":  docgen.Language(file),
+					"file_name": file,
+					"source":    source,
+					"symbols":   symbols,
+				}),
+			)
+			if err != nil {
+				return err
+			}
+			logger.Info(fmt.Sprintf("We are trying to document %d symbols of %s", len(symbols), file))
+			resp, err := completion(cmd.Context(), client, out)
+			if err != nil {
+				return err
+			}
+			printUsage(cmd.Context(), resp.Usage)
+			comments, err := docgen.ParseComments(resp.Content)
+			if err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+
+			updated := docgen.Apply(file, src, symbols, comments)
+			for _, s := range symbols {
+				if comments[s.Name] != "" {
+					documented++
+				}
+			}
+			if docsWrite {
+				if string(updated) != string(src) {
+					if err := os.WriteFile(file, updated, info.Mode().Perm()); err != nil {
+						return err
+					}
+					logger.Info("Write the comments to " + file)
+				}
+				continue
+			}
+			if patch := docgen.Patch(patchName(root, file), src, updated); patch != "" {
+				patches = append(patches, patch)
+			}
+		}
+
+		logger.Info(fmt.Sprintf("Documented %d symbols", documented))
+		if docsWrite {
+			return nil
+		}
+		result.Message = strings.Join(patches, "")
+		if !isMachineOutput() {
+			fmt.Fprint(os.Stdout, result.Message)
+		}
+		return nil
+	},
+}
+
+// docsFiles returns the source files of the arguments of the docs command: the files,
+// the files of the directories and, for the directories ending with /..., the files of
+// their subdirectories too. The hidden, vendor, node_modules and testdata directories are skipped.
+func docsFiles(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		dir, recursive := strings.CutSuffix(arg, "/...")
+		if dir == "" {
+			dir = "."
+		}
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, arg)
+			continue
+		}
+		err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path == dir {
+					return nil
+				}
+				if !recursive || strings.HasPrefix(d.Name(), ".") ||
+					d.Name() == "vendor" || d.Name() == "node_modules" || d.Name() == "testdata" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if docgen.Language(path) != "" {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// patchName returns the path of the file relative to the root of the repository, used in
+// the patch so it applies with git apply from the root.
+func patchName(root, file string) string {
+	if abs, err := filepath.Abs(file); err == nil && root != "" {
+		if rel, err := filepath.Rel(root, abs); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(file))
+}
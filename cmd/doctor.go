@@ -0,0 +1,432 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/appleboy/CodeGPT/format"
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/openai"
+	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/style"
+	"github.com/appleboy/CodeGPT/util"
+	"github.com/appleboy/com/file"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Status of a diagnostic check.
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// Check is the result of a diagnostic check, with an actionable fix if it's not ok.
+type Check struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+// checkFunc runs a diagnostic check.
+type checkFunc func(ctx context.Context) Check
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the git, config, API key, proxy and hook setup",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runChecks(cmd.Context(), []checkFunc{
+			checkGit,
+			checkConfigFile,
+			checkConfigValues,
+			checkModel,
+			checkProxy,
+			checkAPIKey,
+			checkHook,
+		})
+	},
+}
+
+// runChecks runs the checks, prints their results and fails if any of them failed.
+func runChecks(ctx context.Context, checks []checkFunc) error {
+	failed := 0
+	for _, run := range checks {
+		c := run(ctx)
+		result.Checks = append(result.Checks, c)
+		if c.Status == checkFail {
+			failed++
+		}
+		printCheck(c)
+	}
+
+	if failed > 0 {
+		return invalid(errors.New(strconv.Itoa(failed) + " check(s) failed"))
+	}
+	return nil
+}
+
+// printCheck prints the result of the check with its fix.
+func printCheck(c Check) {
+	line := c.Name
+	if c.Detail != "" {
+		line += ": " + c.Detail
+	}
+	switch c.Status {
+	case checkOK:
+		color.Green("✔ " + line)
+	case checkWarn:
+		color.Yellow("! " + line)
+	default:
+		color.Red("✘ " + line)
+	}
+	if c.Fix != "" {
+		fmt.Fprintln(color.Output, "  → "+c.Fix)
+	}
+}
+
+// checkGit checks that git is installed.
+func checkGit(ctx context.Context) Check {
+	c := Check{Name: "git"}
+	if !util.IsCommandAvailable("git") {
+		c.Status = checkFail
+		c.Detail = "git command not found"
+		c.Fix = "install git and add it to your PATH"
+		return c
+	}
+	out, err := exec.CommandContext(ctx, "git", "--version").Output()
+	if err != nil {
+		c.Status = checkFail
+		c.Detail = err.Error()
+		return c
+	}
+	c.Status = checkOK
+	c.Detail = strings.TrimSpace(string(out))
+	return c
+}
+
+// checkConfigFile checks the syntax and the keys of the config file.
+func checkConfigFile(ctx context.Context) Check {
+	c := Check{Name: "config file"}
+	name := viper.ConfigFileUsed()
+	if name == "" || !file.IsFile(name) {
+		c.Status = checkWarn
+		c.Detail = "no config file"
+		c.Fix = "run codegpt config set openai.api_key sk-..."
+		return c
+	}
+
+	v := viper.New()
+	v.SetConfigFile(name)
+	if err := v.ReadInConfig(); err != nil {
+		c.Status = checkFail
+		c.Detail = err.Error()
+		c.Fix = "fix the syntax of " + name
+		return c
+	}
+
+	var unknown []string
+	for _, key := range v.AllKeys() {
+		if !isKnownKey(key) {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		c.Status = checkWarn
+		c.Detail = "unknown keys in " + name + ": " + strings.Join(unknown, ", ")
+		c.Fix = "remove them or check for typos, available keys: " + strings.Join(availableKeys, ", ")
+		return c
+	}
+
+	c.Status = checkOK
+	c.Detail = name
+	return c
+}
+
+// isKnownKey reports whether the key of the config file is used.
+func isKnownKey(key string) bool {
+	// profiles contain the same keys as the config file
+	if strings.HasPrefix(key, "profiles.") {
+		parts := strings.SplitN(key, ".", 3)
+		if len(parts) < 3 {
+			return false
+		}
+		key = parts[2]
+	}
+	for _, k := range availableKeys {
+		if key == k || strings.HasPrefix(key, k+".") {
+			return true
+		}
+	}
+	// set by the config command flags or the CI platforms
+	switch key {
+	case "output.file", "platform", "git.template_vars", "git.template_vars_file":
+		return true
+	}
+	return false
+}
+
+// checkConfigValues checks the values of the config keys.
+func checkConfigValues(ctx context.Context) Check {
+	c := Check{Name: "config values", Status: checkFail}
+
+	switch provider := viper.GetString("openai.provider"); provider {
+	case "", openai.OPENAI, openai.AZURE, openai.MOCK, openai.BEDROCK, openai.COMPATIBLE, openai.MISTRAL, openai.HUGGINGFACE:
+	default:
+		c.Detail = "unknown provider " + provider
+		c.Fix = "codegpt config set openai.provider openai"
+		return c
+	}
+	if viper.GetString("openai.provider") == openai.AZURE && currentModel() == "" {
+		c.Detail = "the Azure deployment name of " + viper.GetString("openai.model") + " is missing"
+		c.Fix = "codegpt config set openai.model_name <deployment> or set openai.azure_deployments"
+		return c
+	}
+	if p := viper.GetString("openai.provider"); (p == openai.COMPATIBLE || p == openai.HUGGINGFACE) && viper.GetString("openai.base_url") == "" {
+		c.Detail = "the base URL of the " + p + " endpoint is missing"
+		c.Fix = "codegpt config set openai.base_url https://openrouter.ai/api/v1"
+		return c
+	}
+	if auth := viper.GetString("openai.azure_auth"); auth != "" && !openai.IsAzureAuth(auth) {
+		c.Detail = "unknown Azure authentication " + auth
+		c.Fix = "codegpt config set openai.azure_auth api_key"
+		return c
+	}
+	if viper.GetBool("openai.moderation") {
+		if action := viper.GetString("openai.moderation_action"); !openai.IsModerationAction(action) {
+			c.Detail = "unknown moderation action " + action
+			c.Fix = "codegpt config set openai.moderation_action block"
+			return c
+		}
+		if p := viper.GetString("openai.provider"); p != "" && p != openai.OPENAI && p != openai.MOCK {
+			c.Detail = "the moderation is only available with the openai provider"
+			c.Fix = "codegpt config set openai.moderation false"
+			return c
+		}
+	}
+	if t := viper.GetFloat64("openai.temperature"); t < 0 || t > 2 {
+		c.Detail = "temperature " + strconv.FormatFloat(t, 'f', -1, 64) + " is out of range"
+		c.Fix = "codegpt config set openai.temperature 0.7"
+		return c
+	}
+	if p := viper.GetFloat64("openai.top_p"); p < 0 || p > 1 {
+		c.Detail = "top_p " + strconv.FormatFloat(p, 'f', -1, 64) + " is out of range"
+		c.Fix = "codegpt config set openai.top_p 1"
+		return c
+	}
+	for _, key := range []string{"openai.frequency_penalty", "openai.presence_penalty"} {
+		if p := viper.GetFloat64(key); p < -2 || p > 2 {
+			c.Detail = strings.TrimPrefix(key, "openai.") + " " + strconv.FormatFloat(p, 'f', -1, 64) + " is out of range"
+			c.Fix = "codegpt config set " + key + " 0"
+			return c
+		}
+	}
+	if viper.IsSet("openai.timeout") && viper.GetDuration("openai.timeout") <= 0 {
+		c.Detail = "invalid timeout " + viper.GetString("openai.timeout")
+		c.Fix = "codegpt config set openai.timeout 30s"
+		return c
+	}
+	if a := viper.GetString("usage.budget_action"); a != budgetBlock && a != budgetWarn {
+		c.Detail = "unknown budget action " + a
+		c.Fix = "codegpt config set usage.budget_action block"
+		return c
+	}
+	if body := viper.GetString("prompt.body"); body != "" && !prompt.IsBody(body) {
+		c.Detail = "unknown body mode " + body
+		c.Fix = "codegpt config set prompt.body detailed"
+		return c
+	}
+	if tone := viper.GetString("prompt.tone"); tone != "" && !prompt.IsTone(tone) {
+		c.Detail = "unknown tone " + tone
+		c.Fix = "codegpt config set prompt.tone concise"
+		return c
+	}
+	if tense := viper.GetString("prompt.tense"); tense != "" && !slices.Contains(style.Tenses, tense) {
+		c.Detail = "unknown tense " + tense
+		c.Fix = "codegpt config set prompt.tense imperative"
+		return c
+	}
+	if sc := viper.GetString("format.case"); sc != format.CaseKeep && !format.IsCase(sc) {
+		c.Detail = "unknown subject case " + sc
+		c.Fix = "codegpt config set format.case lower"
+		return c
+	}
+	if m := viper.GetString("hook.commit_msg"); m != lintReject && m != lintFix {
+		c.Detail = "unknown commit-msg hook mode " + m
+		c.Fix = "codegpt config set hook.commit_msg reject"
+		return c
+	}
+	if viper.GetDuration("openai.connect_timeout") <= 0 {
+		c.Detail = "invalid connect timeout " + viper.GetString("openai.connect_timeout")
+		c.Fix = "codegpt config set openai.connect_timeout 5s"
+		return c
+	}
+	if f := viper.GetString("git.template_file"); f != "" && !file.IsFile(f) {
+		c.Detail = "template file not found: " + f
+		c.Fix = "codegpt config set git.template_file <path>"
+		return c
+	}
+	if lang := viper.GetString("output.lang"); lang != "" && lang != "en" && prompt.GetLanguage(lang) == prompt.DefaultLanguage {
+		c.Status = checkWarn
+		c.Detail = "unknown output language " + lang + ", English is used"
+		c.Fix = "codegpt config set output.lang en"
+		return c
+	}
+
+	c.Status = checkOK
+	return c
+}
+
+// checkModel checks that the model is supported and its context window fits the max tokens.
+func checkModel(ctx context.Context) Check {
+	c := Check{Name: "model", Status: checkFail}
+	model := viper.GetString("openai.model")
+	if model == "" {
+		model = openai.DefaultModel
+	}
+	c.Detail = model
+
+	// the model list of the provider is the reference, the known models otherwise
+	if client, err := openClient(); err == nil && knownModels() {
+		if ids, err := availableModels(ctx, client, false); err == nil {
+			if err := modelError(model, ids); err != nil {
+				c.Detail = err.Error()
+				c.Fix = "codegpt config set openai.model " + openai.DefaultModel
+				if suggestions := openai.Suggest(model, ids, 1); len(suggestions) > 0 {
+					c.Fix = "codegpt config set openai.model " + suggestions[0]
+				}
+				return c
+			}
+		}
+	}
+	if !openai.IsSupportedModel(model) && knownModels() {
+		c.Status = checkWarn
+		c.Detail = "unknown model " + model + ", it's sent as is and assumed to be a chat model"
+		c.Fix = "check the model name with codegpt models"
+		return c
+	}
+
+	maxTokens := viper.GetInt("openai.max_tokens")
+	if window := openai.ContextWindow(model); window > 0 {
+		if maxTokens >= window {
+			c.Detail = "max_tokens " + strconv.Itoa(maxTokens) + " exceeds the " + strconv.Itoa(window) + " tokens context window of " + model
+			c.Fix = "codegpt config set openai.max_tokens 300"
+			return c
+		}
+		// the completion leaves less than a quarter of the context to the diff
+		if maxTokens > window*3/4 {
+			c.Status = checkWarn
+			c.Detail = "max_tokens " + strconv.Itoa(maxTokens) + " leaves little room for the diff in the " + strconv.Itoa(window) + " tokens context window"
+			c.Fix = "lower openai.max_tokens or use a model with a larger context window"
+			return c
+		}
+		c.Detail += ", " + strconv.Itoa(window) + " tokens context window"
+	}
+
+	c.Status = checkOK
+	return c
+}
+
+// checkProxy checks that the HTTP or SOCKS proxy is reachable.
+func checkProxy(ctx context.Context) Check {
+	c := Check{Name: "proxy", Status: checkOK, Detail: "not configured"}
+	addr := viper.GetString("openai.proxy")
+	if addr == "" {
+		addr = viper.GetString("openai.socks")
+	}
+	if addr == "" {
+		return c
+	}
+	c.Detail = addr
+
+	host := addr
+	if u, err := url.Parse(addr); err == nil && u.Host != "" {
+		host = u.Host
+		if u.Port() == "" {
+			port := "80"
+			if u.Scheme == "https" {
+				port = "443"
+			}
+			host = net.JoinHostPort(u.Hostname(), port)
+		}
+	}
+
+	conn, err := (&net.Dialer{Timeout: 5 * time.Second}).DialContext(ctx, "tcp", host)
+	if err != nil {
+		c.Status = checkFail
+		c.Detail = "can't reach the proxy " + addr + ": " + err.Error()
+		c.Fix = "check openai.proxy and openai.socks"
+		return c
+	}
+	conn.Close()
+	return c
+}
+
+// checkAPIKey checks the API key with the lightweight models list call.
+func checkAPIKey(ctx context.Context) Check {
+	c := Check{Name: "API key", Status: checkFail}
+	if !keyless() && apiKey() == "" {
+		c.Detail = "no API key"
+		c.Fix = "export OPENAI_API_KEY=sk-... or codegpt config set openai.api_key --keyring"
+		return c
+	}
+
+	client, err := openClient()
+	if err != nil {
+		c.Detail = err.Error()
+		return c
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	models, err := client.Models(ctx)
+	if err != nil {
+		c.Detail = err.Error()
+		c.Fix = "check the API key, openai.base_url and your network"
+		return c
+	}
+
+	c.Status = checkOK
+	c.Detail = strconv.Itoa(len(models)) + " models available"
+	return c
+}
+
+// hookOutdated reports whether the installed hook differs from the current one.
+func hookOutdated() bool {
+	outdated, err := git.New().HookOutdated(git.HookPrepareCommitMessageTemplate)
+	return err == nil && outdated
+}
+
+// checkHook checks whether the git hook is installed in the current repository.
+func checkHook(ctx context.Context) Check {
+	c := Check{Name: "git hook"}
+	installed, err := git.New().HookInstalled(git.HookPrepareCommitMessageTemplate)
+	switch {
+	case err != nil:
+		c.Status = checkOK
+		c.Detail = "not in a git repository"
+	case installed && hookOutdated():
+		c.Status = checkWarn
+		c.Detail = "prepare-commit-msg is outdated, it replaces the existing messages of merges and amends"
+		c.Fix = "codegpt hook uninstall && codegpt hook install"
+	case installed:
+		c.Status = checkOK
+		c.Detail = "prepare-commit-msg installed"
+	default:
+		c.Status = checkWarn
+		c.Detail = "prepare-commit-msg not installed"
+		c.Fix = "codegpt hook install"
+	}
+	return c
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/util"
+
+	"github.com/spf13/viper"
+)
+
+// envPrefix is the prefix of the environment variables of the config keys.
+const envPrefix = "CODEGPT_"
+
+// envName returns the environment variable of the config key, like CODEGPT_OPENAI_MODEL.
+func envName(key string) string {
+	return envPrefix + strings.ToUpper(replacer.Replace(key))
+}
+
+// parseValue converts the raw value of a config key from the command line
+// or an environment variable: comma-separated lists and key=value maps.
+func parseValue(key, raw string) interface{} {
+	switch key {
+	case "git.exclude_list", "redact.patterns", "openai.stop", "hook.skip", "lint.types", "lint.scopes", "standup.repos", "prompt.priority_rules", "otel.headers", "privacy.allowed_hosts":
+		return strings.Split(raw, ",")
+	case "git.scope_map", "openai.azure_deployments":
+		return map[string]interface{}(util.ConvertToMap(strings.Split(raw, ",")))
+	default:
+		return raw
+	}
+}
+
+// bindEnv binds every config key to its CODEGPT_ environment variable. The variables
+// without prefix, like OPENAI_API_KEY, are still read for backward compatibility.
+func bindEnv() {
+	for _, key := range availableKeys {
+		name := envName(key)
+		_ = viper.BindEnv(key, name)
+
+		// the lists and maps can't be decoded from a plain string
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		if val := parseValue(key, raw); !isString(val) {
+			viper.Set(key, val)
+		}
+	}
+}
+
+// isString reports whether the value is a plain string.
+func isString(val interface{}) bool {
+	_, ok := val.(string)
+	return ok
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+
+	"github.com/appleboy/CodeGPT/openai"
+	"github.com/appleboy/CodeGPT/policy"
+	"github.com/appleboy/CodeGPT/privacy"
+	"github.com/appleboy/CodeGPT/usage"
+)
+
+// The exit codes of the commands, a stable contract for the scripts of the pipelines.
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitValidation  = 2
+	exitProvider    = 3
+	exitBudget      = 4
+	exitInterrupted = 130
+)
+
+// validationError is an input failing a check, like a commit message breaking the
+// Conventional Commits, a review finding over --fail_on or an invalid flag.
+type validationError struct {
+	err error
+}
+
+func (e *validationError) Error() string { return e.err.Error() }
+func (e *validationError) Unwrap() error { return e.err }
+
+// invalid returns the error as a validation failure.
+func invalid(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &validationError{err: err}
+}
+
+// providerError is a request to the provider which failed or timed out.
+type providerError struct {
+	err error
+}
+
+func (e *providerError) Error() string { return e.err.Error() }
+func (e *providerError) Unwrap() error { return e.err }
+
+// exitCode returns the exit code of the error of the command.
+func exitCode(ctx context.Context, err error) int {
+	var (
+		validation *validationError
+		flagged    *openai.FlaggedError
+		budget     *usage.ExceededError
+		provider   *providerError
+	)
+	switch {
+	case err == nil:
+		return exitOK
+	case ctx.Err() != nil:
+		// interrupted by SIGINT or SIGTERM, the in-flight request is canceled
+		return exitInterrupted
+	case errors.As(err, &budget):
+		return exitBudget
+	case errors.As(err, &validation), errors.As(err, &flagged),
+		errors.Is(err, policy.ErrNotAllowed), errors.Is(err, privacy.ErrNotAllowed):
+		return exitValidation
+	case errors.As(err, &provider), errors.Is(err, context.DeadlineExceeded):
+		return exitProvider
+	}
+	return exitError
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/util"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// maxExplainCommits is the number of the newest commit messages of a range given to the model.
+const maxExplainCommits = 50
+
+func init() {
+	explainCmd.Flags().IntVar(&diffUnified, "diff_unified", 3, "generate diffs with <n> lines of context, default is 3")
+	explainCmd.Flags().StringSliceVar(&excludeList, "exclude_list", []string{}, "exclude file from git diff command")
+	explainCmd.Flags().StringVar(&commitModel, "model", "gpt-3.5-turbo", "select openai model")
+	explainCmd.Flags().StringVar(&commitLang, "lang", "en", "summarizing language uses English by default")
+	explainCmd.Flags().IntVar(&maxTokens, "max_tokens", 1000, "the maximum number of tokens to generate in the chat completion.")
+	explainCmd.Flags().DurationVarP(&timeout, "timeout", "t", 0, "timeout of every request to the provider, default is openai.timeout")
+	explainCmd.Flags().DurationVar(&connectTimeout, "connect_timeout", 0, "timeout of the connection to the provider, default is openai.connect_timeout")
+}
+
+var explainCmd = &cobra.Command{
+	Use:   "explain <commit>|<from>..<to>|<from>...<to>|<file>",
+	Short: "Explain in plain language what a commit or a diff does",
+	Long: `Explain in plain language what a commit or a diff does and why it was probably made,
+from its diff and its commit messages. The argument is a commit, a revision range, a patch
+file, - to read the diff from stdin, or a file of the repository to explain its last change.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := check(); err != nil {
+			return err
+		}
+
+		opts := []git.Option{
+			git.WithDiffUnified(viper.GetInt("git.diff_unified")),
+			git.WithExcludeList(viper.GetStringSlice("git.exclude_list")),
+			git.WithMaxFileSize(viper.GetInt64("git.max_file_size")),
+		}
+		g := git.New(opts...)
+
+		target := args[0]
+		var (
+			diff, patch, rev string
+			files, messages  []string
+			err              error
+		)
+		if target != "-" {
+			patch, rev, err = explainFile(g, target)
+			if err != nil {
+				return err
+			}
+		}
+		switch {
+		case target == "-":
+			diff, files, err = readDiff(os.Stdin)
+		case patch != "":
+			diff = patch
+			for _, f := range git.ParsePatch(patch) {
+				files = append(files, f.Name())
+			}
+		default:
+			if rev != "" {
+				target = rev
+			}
+			from, to, mergeBase := git.ParseRange(target)
+			g = git.New(append(opts, git.WithRange(from, to, mergeBase))...)
+			diff, files, err = stagedDiff(g)
+			if err == nil {
+				revisions := from + ".." + to
+				if from == git.EmptyTree {
+					// the root commit has no parent to start the range from
+					revisions = to
+				}
+				messages, err = rangeMessages(g, revisions)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(diff) == "" {
+			return errors.New("there are no changes to explain in " + args[0])
+		}
+		diff, err = redactDiff(diff)
+		if err != nil {
+			return err
+		}
+		diff, err = fitDiff(cmd.Context(), diff)
+		if err != nil {
+			return err
+		}
+
+		client, err := newClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+		out, err := util.GetTemplateByString(
+			prompt.ExplainChangesTemplate,
+			withVars(promptVars(g, files), util.Data{
+				"commit_messages": messages,
+				"file_diffs":      diff,
+			}),
+		)
+		if err != nil {
+			return err
+		}
+
+		logger.Info("We are trying to explain the changes of " + args[0])
+		// the explanation is written while it's generated, the machine output waits for it
+		delta := func(string) {}
+		if !isMachineOutput() {
+			delta = func(s string) { fmt.Fprint(os.Stdout, s) }
+		}
+		resp, err := streamCompletion(cmd.Context(), client, out, delta)
+		if err != nil {
+			return err
+		}
+		if !isMachineOutput() {
+			fmt.Fprintln(os.Stdout)
+		}
+		printUsage(cmd.Context(), resp.Usage)
+		result.Message = strings.TrimSpace(resp.Content)
+		return nil
+	},
+}
+
+// explainFile returns the content of the patch file, or the last commit changing the
+// file of the repository. Both are empty when the argument isn't a file.
+func explainFile(g *git.Command, name string) (string, string, error) {
+	info, err := os.Stat(name)
+	if err != nil || info.IsDir() {
+		return "", "", nil
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", "", err
+	}
+	if len(git.ParsePatch(string(data))) > 0 {
+		return string(data), "", nil
+	}
+
+	rev, err := g.LastCommit(name)
+	if err != nil {
+		return "", "", err
+	}
+	if rev == "" {
+		return "", "", fmt.Errorf("the file %s isn't a patch and has no commit", name)
+	}
+	logger.Info("Explain the last commit changing " + name + ": " + rev[:7])
+	return "", rev, nil
+}
+
+// rangeMessages returns the messages of the newest commits of the revision range, oldest first.
+func rangeMessages(g *git.Command, revisions string) ([]string, error) {
+	entries, err := g.Log(revisions)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) > maxExplainCommits {
+		entries = entries[:maxExplainCommits]
+	}
+	messages := make([]string, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		messages = append(messages, entries[i].Message)
+	}
+	return messages, nil
+}
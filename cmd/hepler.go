@@ -1,14 +1,26 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/appleboy/CodeGPT/cache"
+	"github.com/appleboy/CodeGPT/cassette"
+	"github.com/appleboy/CodeGPT/format"
+	"github.com/appleboy/CodeGPT/logger"
 	"github.com/appleboy/CodeGPT/openai"
 	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/redact"
+	"github.com/appleboy/CodeGPT/tracing"
 	"github.com/appleboy/CodeGPT/util"
 	"github.com/appleboy/com/file"
 
+	"github.com/fatih/color"
 	"github.com/spf13/viper"
 )
 
@@ -31,7 +43,7 @@ func check() error {
 		viper.Set("output.lang", commitLang)
 	}
 
-	if openai.GetModel(commitModel) != openai.DefaultModel {
+	if commitModel != "" && commitModel != openai.DefaultModel {
 		viper.Set("openai.model", commitModel)
 	}
 
@@ -46,6 +58,16 @@ func check() error {
 	if maxTokens != 300 {
 		viper.Set("openai.max_tokens", maxTokens)
 	}
+	// the managed policy still bounds the max tokens of the flag
+	clampTokens()
+
+	if timeout > 0 {
+		viper.Set("openai.timeout", timeout)
+	}
+
+	if connectTimeout > 0 {
+		viper.Set("openai.connect_timeout", connectTimeout)
+	}
 
 	if templateFile != "" {
 		viper.Set("git.template_file", templateFile)
@@ -65,5 +87,391 @@ func check() error {
 		return fmt.Errorf("template variables file not found: %s", templateVarsFile)
 	}
 
+	if noBody && detailedBody {
+		return errors.New("--no_body and --detailed can't be used together")
+	}
+	if noBody {
+		viper.Set("prompt.body", prompt.BodyNone)
+	}
+	if detailedBody {
+		viper.Set("prompt.body", prompt.BodyDetailed)
+	}
+	if body := viper.GetString("prompt.body"); body != "" && !prompt.IsBody(body) {
+		return fmt.Errorf("unknown body mode %s, use one of %s", body, strings.Join(prompt.Bodies, ", "))
+	}
+
+	if tone := viper.GetString("prompt.tone"); tone != "" && !prompt.IsTone(tone) {
+		return fmt.Errorf("unknown tone %s, use one of %s", tone, strings.Join(prompt.Tones, ", "))
+	}
+
+	if c := viper.GetString("format.case"); c != format.CaseKeep && !format.IsCase(c) {
+		return fmt.Errorf("unknown subject case %s, use one of %s", c, strings.Join(format.Cases, ", "))
+	}
+
 	return nil
 }
+
+// newClient creates a new OpenAI client from the current configuration, checks that
+// the monthly budget isn't spent and that the provider has the configured model.
+func newClient(ctx context.Context) (*openai.Client, error) {
+	client, err := openClient()
+	if err != nil {
+		return nil, err
+	}
+	if !dryRun && !promptOnly {
+		if err := checkBudget(); err != nil {
+			return nil, err
+		}
+	}
+	if err := validateModel(ctx, client); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// openClient creates a new OpenAI client from the current configuration.
+func openClient() (*openai.Client, error) {
+	result.Provider = viper.GetString("openai.provider")
+	if result.Provider == "" {
+		result.Provider = openai.OPENAI
+	}
+	result.Model = currentModel()
+	if err := checkPolicy(result.Provider); err != nil {
+		return nil, err
+	}
+
+	opts := []openai.Option{
+		openai.WithToken(apiKey()),
+		openai.WithModel(viper.GetString("openai.model")),
+		openai.WithOrgID(viper.GetString("openai.org_id")),
+		openai.WithProxyURL(viper.GetString("openai.proxy")),
+		openai.WithSocksURL(viper.GetString("openai.socks")),
+		openai.WithProxyAuth(viper.GetString("openai.proxy_username"), secret("openai.proxy_password")),
+		openai.WithBaseURL(viper.GetString("openai.base_url")),
+		openai.WithTimeout(viper.GetDuration("openai.timeout")),
+		openai.WithConnectTimeout(viper.GetDuration("openai.connect_timeout")),
+		openai.WithMaxTokens(viper.GetInt("openai.max_tokens")),
+		openai.WithTemperature(float32(viper.GetFloat64("openai.temperature"))),
+		openai.WithProvider(viper.GetString("openai.provider")),
+		openai.WithModelName(viper.GetString("openai.model_name")),
+		openai.WithAzureDeployments(viper.GetStringMapString("openai.azure_deployments")),
+		openai.WithSkipVerify(viper.GetBool("openai.skip_verify")),
+		openai.WithCA(viper.GetString("openai.ca_file"), viper.GetString("openai.ca_dir")),
+		openai.WithClientCert(viper.GetString("openai.client_cert"), viper.GetString("openai.client_key")),
+		openai.WithHeaders(viper.GetStringSlice("openai.headers")),
+		openai.WithApiVersion(viper.GetString("openai.api_version")),
+		openai.WithAWSRegion(viper.GetString("bedrock.region")),
+		openai.WithAWSProfile(viper.GetString("bedrock.profile")),
+		openai.WithAzureAuth(viper.GetString("openai.azure_auth")),
+		openai.WithAzureCredentials(
+			viper.GetString("openai.azure_tenant_id"),
+			viper.GetString("openai.azure_client_id"),
+			azureClientSecret(),
+		),
+		openai.WithCassette(cassetteConfig()),
+		openai.WithMockResponse(viper.GetString("mock.response")),
+		openai.WithSystemPrompt(viper.GetString("prompt.system")),
+		openai.WithTopP(float32(viper.GetFloat64("openai.top_p"))),
+		openai.WithFrequencyPenalty(float32(viper.GetFloat64("openai.frequency_penalty"))),
+		openai.WithPresencePenalty(float32(viper.GetFloat64("openai.presence_penalty"))),
+		openai.WithStop(viper.GetStringSlice("openai.stop")),
+		openai.WithStream(viper.GetBool("huggingface.stream")),
+		openai.WithEmbeddingModel(viper.GetString("openai.embedding_model")),
+		openai.WithRateLimit(viper.GetInt("openai.requests_per_minute"), viper.GetInt("openai.tokens_per_minute")),
+		// the processes of the same user share the window, like the commands of a batch
+		openai.WithRateLimitFile(filepath.Join(filepath.Dir(ledgerFile()), "ratelimit.json")),
+	}
+	if viper.IsSet("openai.seed") {
+		opts = append(opts, openai.WithSeed(viper.GetInt("openai.seed")))
+	}
+	if viper.GetBool("openai.moderation") {
+		opts = append(opts, openai.WithModeration(viper.GetString("openai.moderation_action")))
+	}
+	policy, err := privacyPolicy()
+	if err != nil {
+		return nil, err
+	}
+	if policy != nil {
+		opts = append(opts, openai.WithPrivacy(policy))
+	}
+
+	return openai.New(opts...)
+}
+
+// currentModel returns the configured model, or its deployment name on Azure:
+// the one of openai.azure_deployments or openai.model_name.
+func currentModel() string {
+	model := viper.GetString("openai.model")
+	if viper.GetString("openai.provider") != openai.AZURE {
+		return model
+	}
+	if name := viper.GetStringMapString("openai.azure_deployments")[strings.ToLower(model)]; name != "" {
+		return name
+	}
+	return viper.GetString("openai.model_name")
+}
+
+// azureClientSecret returns the client secret of the Azure service principal,
+// it's only read with the client_secret authentication to skip the keyring otherwise.
+func azureClientSecret() string {
+	if viper.GetString("openai.provider") != openai.AZURE ||
+		viper.GetString("openai.azure_auth") != openai.AzureAuthClientSecret {
+		return ""
+	}
+	return secret("openai.azure_client_secret")
+}
+
+// azureAD reports whether the Azure provider authenticates with Microsoft Entra ID
+// instead of the API key.
+func azureAD() bool {
+	auth := viper.GetString("openai.azure_auth")
+	return viper.GetString("openai.provider") == openai.AZURE && auth != "" && auth != openai.AzureAuthKey
+}
+
+// apiKey returns the API key of the provider, the Mistral one can also be set
+// with the MISTRAL_API_KEY environment variable.
+func apiKey() string {
+	if key := secret("openai.api_key"); key != "" {
+		return key
+	}
+	if viper.GetString("openai.provider") == openai.MISTRAL {
+		return os.Getenv("MISTRAL_API_KEY")
+	}
+	return ""
+}
+
+// knownModels reports whether the models of the provider are known and validated.
+func knownModels() bool {
+	switch viper.GetString("openai.provider") {
+	case "", openai.OPENAI, openai.MISTRAL:
+		return true
+	}
+	return false
+}
+
+// keyless reports whether the provider works without the API key,
+// the self-hosted servers have none.
+func keyless() bool {
+	switch viper.GetString("openai.provider") {
+	case openai.MOCK, openai.BEDROCK, openai.COMPATIBLE, openai.HUGGINGFACE:
+		return true
+	}
+	return azureAD()
+}
+
+// cassetteConfig returns the mode and file of the cassette from the --record
+// and --replay flags or the cassette config.
+func cassetteConfig() (string, string) {
+	switch {
+	case recordFile != "":
+		return cassette.ModeRecord, recordFile
+	case replayFile != "":
+		return cassette.ModeReplay, replayFile
+	default:
+		return viper.GetString("cassette.mode"), viper.GetString("cassette.file")
+	}
+}
+
+// completion sends the prompt to the provider, the response comes from the cache
+// if the same prompt was already sent to the same model.
+func completion(ctx context.Context, client *openai.Client, content string) (*openai.Response, error) {
+	return cached(ctx, content, func(ctx context.Context) (*openai.Response, error) {
+		return client.Completion(ctx, content)
+	})
+}
+
+// streamCompletion sends the prompt like completion, the content is given to the delta
+// function while it's generated. The cached response is given at once.
+func streamCompletion(ctx context.Context, client *openai.Client, content string, delta func(string)) (*openai.Response, error) {
+	streamed := false
+	resp, err := cached(ctx, content, func(ctx context.Context) (*openai.Response, error) {
+		streamed = true
+		return client.Stream(ctx, content, delta)
+	})
+	if err == nil && !streamed {
+		delta(resp.Content)
+	}
+	return resp, err
+}
+
+// candidates returns n completions of the prompt, a single completion goes through the cache.
+func candidates(ctx context.Context, client *openai.Client, content string, n int) (*openai.Response, error) {
+	if n <= 1 {
+		return completion(ctx, client, content)
+	}
+	resp, err := observeProvider(ctx, func(ctx context.Context) (*openai.Response, error) {
+		return client.Candidates(ctx, content, n)
+	})
+	if err == nil {
+		recordAudit(ctx, content, resp)
+	}
+	return resp, err
+}
+
+// cached returns the cached response of the prompt or calls fn and caches its response.
+// Only the requests really sent to the provider are audited.
+func cached(ctx context.Context, content string, call func(ctx context.Context) (*openai.Response, error)) (*openai.Response, error) {
+	ctx, span := tracing.Start(ctx, "completion", tracing.Int("codegpt.prompt_length", len(content)))
+	defer span.End()
+	fn := func() (*openai.Response, error) {
+		resp, err := observeProvider(ctx, call)
+		span.SetError(err)
+		if err == nil {
+			recordAudit(ctx, content, resp)
+		}
+		return resp, err
+	}
+
+	// the requests must reach the cassette, and the mock responses are free
+	if _, file := cassetteConfig(); file != "" || result.Provider == openai.MOCK {
+		return fn()
+	}
+	if noCache || !viper.GetBool("cache.enable") {
+		return fn()
+	}
+
+	c, err := cache.New(
+		cache.WithDir(viper.GetString("cache.dir")),
+		cache.WithTTL(viper.GetDuration("cache.ttl")),
+	)
+	if err != nil {
+		return fn()
+	}
+
+	key := cacheKey(content)
+	if out, ok := c.Get(key); ok {
+		cacheRequestsTotal.Inc("hit")
+		span.SetAttributes(tracing.Bool("codegpt.cache_hit", true))
+		logger.Info("Use the cached response, run with --no_cache to send a new request")
+		return &openai.Response{Content: out, Choices: []string{out}}, nil
+	}
+	cacheRequestsTotal.Inc("miss")
+	span.SetAttributes(tracing.Bool("codegpt.cache_hit", false))
+
+	resp, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Set(key, resp.Content); err != nil {
+		logger.Debug("can't cache the response: " + err.Error())
+	}
+	return resp, nil
+}
+
+// cacheKey returns the cache key of the prompt, from everything that changes the response:
+// the endpoint, the model, the sampling parameters and the system prompt.
+func cacheKey(content string) string {
+	return cache.Key(
+		result.Provider,
+		viper.GetString("openai.base_url"),
+		result.Model,
+		strconv.Itoa(viper.GetInt("openai.max_tokens")),
+		strconv.FormatFloat(viper.GetFloat64("openai.temperature"), 'f', -1, 64),
+		samplingParams(),
+		strings.TrimSpace(viper.GetString("prompt.system")),
+		content,
+	)
+}
+
+// printDryRun prints the request that would be sent to the provider.
+func printDryRun(client *openai.Client, content string) {
+	color.Yellow("====================Dry Run=======================")
+	color.Yellow("Endpoint: " + client.Endpoint())
+	color.Yellow("Model: " + result.Model +
+		", MaxTokens: " + strconv.Itoa(viper.GetInt("openai.max_tokens")) +
+		", Temperature: " + strconv.FormatFloat(viper.GetFloat64("openai.temperature"), 'f', -1, 64))
+	color.Yellow(samplingParams())
+	color.Yellow("Estimated PromptTokens: " + strconv.Itoa(openai.EstimateTokens(client.SystemPrompt()+content)))
+	system := client.SystemPrompt()
+	if system == "" {
+		system = "(none)"
+	}
+	color.Yellow("System prompt: " + system)
+	color.Yellow("User prompt:\n\n" + strings.TrimSpace(content) + "\n")
+	color.Yellow("==================================================")
+}
+
+// samplingParams describes the sampling parameters sent with the requests.
+func samplingParams() string {
+	topP := viper.GetFloat64("openai.top_p")
+	if topP <= 0 || topP > 1 {
+		topP = 1
+	}
+	out := "TopP: " + strconv.FormatFloat(topP, 'f', -1, 64) +
+		", FrequencyPenalty: " + strconv.FormatFloat(viper.GetFloat64("openai.frequency_penalty"), 'f', -1, 64) +
+		", PresencePenalty: " + strconv.FormatFloat(viper.GetFloat64("openai.presence_penalty"), 'f', -1, 64)
+	if viper.IsSet("openai.seed") {
+		out += ", Seed: " + strconv.Itoa(viper.GetInt("openai.seed"))
+	}
+	if stop := viper.GetStringSlice("openai.stop"); len(stop) > 0 {
+		out += ", Stop: " + fmt.Sprintf("%q", stop)
+	}
+	return out
+}
+
+// printUsage prints the token usage of the request and adds it to the command result.
+func printUsage(ctx context.Context, usage openai.Usage) {
+	addUsage(ctx, usage)
+	// cached responses are not billed
+	if usage.TotalTokens == 0 {
+		return
+	}
+	logger.Info("PromptTokens: " + strconv.Itoa(usage.PromptTokens) +
+		", CompletionTokens: " + strconv.Itoa(usage.CompletionTokens) +
+		", TotalTokens: " + strconv.Itoa(usage.TotalTokens),
+	)
+}
+
+// redactDiff replaces secrets in the git diff with placeholders before sending it to the provider.
+func redactDiff(diff string) (string, error) {
+	return redactContent(diff, "the git diff", (*redact.Redactor).Redact)
+}
+
+// redactText replaces secrets in a text that isn't a diff, like the content of a file,
+// with placeholders before sending it to the provider. Every line is checked.
+func redactText(name, text string) (string, error) {
+	return redactContent(text, name, func(r *redact.Redactor, text string) (string, []redact.Finding) {
+		out, findings := r.RedactText(text)
+		for i := range findings {
+			findings[i].File = name
+		}
+		return out, findings
+	})
+}
+
+func redactContent(
+	content, source string,
+	fn func(*redact.Redactor, string) (string, []redact.Finding),
+) (string, error) {
+	if !viper.GetBool("redact.enable") {
+		return content, nil
+	}
+
+	r, err := redact.New(
+		redact.WithPatterns(viper.GetStringSlice("redact.patterns")),
+		redact.WithEntropyThreshold(viper.GetFloat64("redact.entropy")),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	out, findings := fn(r, content)
+	if len(findings) == 0 {
+		return out, nil
+	}
+
+	logger.Warn("Redacted " + strconv.Itoa(len(findings)) + " secret(s) from " + source)
+	if showRedactions {
+		color.Yellow("==================Redactions=======================")
+		for _, f := range findings {
+			location := "line " + strconv.Itoa(f.Line)
+			if f.File != "" {
+				location = f.File + ":" + strconv.Itoa(f.Line)
+			}
+			color.Yellow(location + ": " + f.Rule + " -> " + f.Placeholder)
+		}
+		color.Yellow("==================================================")
+	}
+
+	return out, nil
+}
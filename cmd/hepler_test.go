@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestCacheKey(t *testing.T) {
+	t.Cleanup(func() {
+		viper.Set("openai.temperature", nil)
+		viper.Set("openai.base_url", nil)
+	})
+	viper.Set("openai.temperature", 0.7)
+	viper.Set("openai.base_url", "https://api.openai.com/v1")
+	key := cacheKey("prompt")
+
+	tests := []struct {
+		name  string
+		key   string
+		value any
+	}{
+		{"temperature", "openai.temperature", 0.2},
+		{"base url", "openai.base_url", "http://localhost:11434/v1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := viper.Get(tt.key)
+			t.Cleanup(func() { viper.Set(tt.key, old) })
+
+			viper.Set(tt.key, tt.value)
+			if cacheKey("prompt") == key {
+				t.Errorf("cacheKey() is the same after changing %s", tt.key)
+			}
+		})
+	}
+
+	if cacheKey("prompt") != key {
+		t.Error("cacheKey() changed without a change of the settings")
+	}
+}
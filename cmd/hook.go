@@ -4,36 +4,92 @@ import (
 	"errors"
 
 	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
 
-	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
+var hookFramework string
+
+func init() {
+	hookCmd.Flags().StringVar(&hookFramework, "framework", "", "add the hook to the config of the hook manager: husky, pre-commit or lefthook")
+}
+
 var hookCmd = &cobra.Command{
-	Use:   "hook",
-	Short: "install/uninstall git prepare-commit-msg hook",
-	Args:  cobra.MinimumNArgs(1),
+	Use:   "hook install|uninstall [prepare-commit-msg|commit-msg] [--framework husky|pre-commit|lefthook]",
+	Short: "install/uninstall git prepare-commit-msg or commit-msg hook",
+	Args:  cobra.RangeArgs(1, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if args[0] != "install" && args[0] != "uninstall" {
 			return errors.New("only support install or uninstall command")
 		}
 
+		// the prepare-commit-msg hook writes the messages, the commit-msg one
+		// checks the written messages against Conventional Commits
+		name := git.HookPrepareCommitMessageTemplate
+		if len(args) > 1 {
+			name = args[1]
+		}
+		if name != git.HookPrepareCommitMessageTemplate && name != git.HookCommitMessageTemplate {
+			return errors.New("only support prepare-commit-msg or commit-msg hook")
+		}
+
 		g := git.New()
 
+		if hookFramework != "" {
+			return frameworkHook(g, args[0], name)
+		}
+
 		switch args[0] {
 		case "install":
-			if err := g.InstallHook(); err != nil {
+			if err := g.InstallHook(name); err != nil {
 				return err
 			}
-			color.Green("Install git hook: prepare-commit-msg successfully")
-			color.Green("You can see the hook file: .git/hooks/prepare-commit-msg")
+			logger.Info("Install git hook: " + name + " successfully")
+			logger.Info("You can see the hook file: .git/hooks/" + name)
 		case "uninstall":
-			if err := g.UninstallHook(); err != nil {
+			if err := g.UninstallHook(name); err != nil {
 				return err
 			}
-			color.Green("Remove git hook: prepare-commit-msg successfully")
+			logger.Info("Remove git hook: " + name + " successfully")
 		}
 
 		return nil
 	},
 }
+
+// frameworkHook adds the hook to the config of the hook manager, or removes it,
+// instead of the files of .git/hooks the hook managers overwrite.
+func frameworkHook(g *git.Command, action, name string) error {
+	if !git.IsFramework(hookFramework) {
+		return errors.New("only support husky, pre-commit or lefthook framework")
+	}
+	root, err := g.TopLevel()
+	if err != nil {
+		return err
+	}
+
+	if action == "uninstall" {
+		target, err := git.UninstallFramework(root, hookFramework, name)
+		if err != nil {
+			return err
+		}
+		logger.Info("Remove the " + name + " hook from " + target + " successfully")
+		return nil
+	}
+
+	target, err := git.InstallFramework(root, hookFramework, name)
+	if err != nil {
+		return err
+	}
+	logger.Info("Add the " + name + " hook to " + target + " successfully")
+	switch hookFramework {
+	case git.FrameworkHusky:
+		logger.Info("Set up husky with npx husky init if it isn't yet")
+	case git.FrameworkPreCommit:
+		logger.Info("Enable the hook with: pre-commit install --hook-type " + name)
+	case git.FrameworkLefthook:
+		logger.Info("Enable the hook with: lefthook install")
+	}
+	return nil
+}
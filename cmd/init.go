@@ -0,0 +1,417 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/openai"
+	"github.com/appleboy/CodeGPT/prompt"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set up the provider, API key, model, language and git hook interactively",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireInteractive("the init wizard"); err != nil {
+			return err
+		}
+		w := newWizard(os.Stdin)
+
+		provider, err := w.choose("Provider", []string{openai.OPENAI, openai.AZURE, openai.BEDROCK, openai.MISTRAL, openai.HUGGINGFACE, openai.COMPATIBLE, openai.MOCK}, openai.OPENAI)
+		if err != nil {
+			return err
+		}
+		viper.Set(profileKey("openai.provider"), provider)
+
+		azureAuth := openai.AzureAuthKey
+
+		if provider == openai.AZURE {
+			baseURL, err := w.ask("Azure endpoint, like https://xxx.openai.azure.com/", viper.GetString("openai.base_url"))
+			if err != nil {
+				return err
+			}
+			viper.Set(profileKey("openai.base_url"), baseURL)
+
+			modelName, err := w.ask("Azure deployment name", viper.GetString("openai.model_name"))
+			if err != nil {
+				return err
+			}
+			viper.Set(profileKey("openai.model_name"), modelName)
+
+			if azureAuth, err = w.azureAuth(); err != nil {
+				return err
+			}
+		}
+
+		if provider == openai.BEDROCK {
+			region, err := w.ask("AWS region, like us-east-1", viper.GetString("bedrock.region"))
+			if err != nil {
+				return err
+			}
+			viper.Set(profileKey("bedrock.region"), region)
+		}
+
+		if provider == openai.COMPATIBLE || provider == openai.HUGGINGFACE {
+			question := "Base URL, like https://openrouter.ai/api/v1 or http://localhost:1234/v1"
+			if provider == openai.HUGGINGFACE {
+				question = "Endpoint URL, like https://xxx.endpoints.huggingface.cloud or http://localhost:8080"
+			}
+			baseURL, err := w.ask(question, viper.GetString("openai.base_url"))
+			if err != nil {
+				return err
+			}
+			viper.Set(profileKey("openai.base_url"), baseURL)
+		}
+
+		if (provider == openai.OPENAI || provider == openai.AZURE || provider == openai.MISTRAL) && azureAuth == openai.AzureAuthKey {
+			if err := w.apiKey(true); err != nil {
+				return err
+			}
+		}
+		// the self-hosted servers have no API key
+		if provider == openai.COMPATIBLE || provider == openai.HUGGINGFACE {
+			if err := w.apiKey(false); err != nil {
+				return err
+			}
+		}
+
+		var model string
+		switch provider {
+		case openai.BEDROCK:
+			model, err = w.choose("Model", bedrockModels, bedrockModels[0])
+		case openai.COMPATIBLE, openai.HUGGINGFACE:
+			model, err = w.endpointModel(cmd.Context())
+		case openai.MISTRAL:
+			model, err = w.choose("Model", w.models(cmd.Context(), mistralModels), mistralModels[0])
+		default:
+			model, err = w.choose("Model", w.models(cmd.Context(), openaiModels), openai.DefaultModel)
+		}
+		if err != nil {
+			return err
+		}
+		viper.Set(profileKey("openai.model"), model)
+
+		lang, err := w.choose("Commit message language", prompt.LanguageCodes(), "en")
+		if err != nil {
+			return err
+		}
+		viper.Set(profileKey("output.lang"), lang)
+
+		// validate before writing anything
+		if err := runChecks(cmd.Context(), []checkFunc{checkConfigValues, checkModel}); err != nil {
+			return err
+		}
+		if err := viper.WriteConfig(); err != nil {
+			return err
+		}
+		logger.Info("you can see the config file: " + viper.ConfigFileUsed())
+
+		g := git.New()
+		if _, err := g.GitDir(); err != nil {
+			return nil
+		}
+		if installed, err := g.HookInstalled(git.HookPrepareCommitMessageTemplate); err != nil || installed {
+			return nil
+		}
+		ok, err := w.confirm("Install the prepare-commit-msg git hook in this repository?", true)
+		if err != nil || !ok {
+			return err
+		}
+		if err := g.InstallHook(git.HookPrepareCommitMessageTemplate); err != nil {
+			return err
+		}
+		logger.Info("Install git hook: prepare-commit-msg successfully")
+		return nil
+	},
+}
+
+// wizard asks the questions of the init command.
+type wizard struct {
+	r *bufio.Reader
+	// tty is the terminal of the answers, nil when they are piped
+	tty *os.File
+}
+
+// newWizard returns a wizard reading the answers from r.
+func newWizard(r io.Reader) *wizard {
+	w := &wizard{r: bufio.NewReader(r)}
+	if f, ok := r.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		w.tty = f
+	}
+	return w
+}
+
+// ask asks a question and returns the answer, or the default value if it's empty.
+func (w *wizard) ask(question, def string) (string, error) {
+	if def != "" {
+		question += " [" + def + "]"
+	}
+	fmt.Fprint(color.Output, color.CyanString("? ")+question+": ")
+
+	line, err := w.r.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if line = strings.TrimSpace(line); line != "" {
+		return line, nil
+	}
+	if errors.Is(err, io.EOF) {
+		fmt.Fprintln(color.Output)
+		if def == "" {
+			return "", io.ErrUnexpectedEOF
+		}
+	}
+	return def, nil
+}
+
+// askSecret asks for a secret like ask, without echoing the answer on a terminal.
+func (w *wizard) askSecret(question, def string) (string, error) {
+	// the answers already read ahead are used first
+	if w.tty == nil || w.r.Buffered() > 0 {
+		return w.ask(question, def)
+	}
+	if def != "" {
+		question += " [" + def + "]"
+	}
+	fmt.Fprint(color.Output, color.CyanString("? ")+question+": ")
+
+	data, err := term.ReadPassword(int(w.tty.Fd()))
+	fmt.Fprintln(color.Output)
+	if err != nil {
+		return "", err
+	}
+	if line := strings.TrimSpace(string(data)); line != "" {
+		return line, nil
+	}
+	return def, nil
+}
+
+// choose asks to pick one of the options by number or name.
+func (w *wizard) choose(question string, options []string, def string) (string, error) {
+	if !contains(options, def) {
+		def = options[0]
+	}
+	for i, o := range options {
+		fmt.Fprintf(color.Output, "  %d) %s\n", i+1, o)
+	}
+	for {
+		answer, err := w.ask(question, def)
+		if err != nil {
+			return "", err
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+			return options[n-1], nil
+		}
+		if contains(options, answer) {
+			return answer, nil
+		}
+		logger.Warn("please choose one of the options")
+	}
+}
+
+// confirm asks a yes or no question.
+func (w *wizard) confirm(question string, def bool) (bool, error) {
+	d := "y/N"
+	if def {
+		d = "Y/n"
+	}
+	answer, err := w.ask(question, d)
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return true, nil
+	case "n", "no":
+		return false, nil
+	default:
+		return def, nil
+	}
+}
+
+// apiKey asks for the API key and stores it in the config file or the OS keyring.
+// An empty key is kept empty when it isn't required.
+func (w *wizard) apiKey(required bool) error {
+	current := secret("openai.api_key")
+	def := ""
+	if current != "" {
+		def = "keep " + logger.MaskKey(current)
+	}
+	key, err := w.askSecret("API key", def)
+	if err != nil {
+		return err
+	}
+	if key == def {
+		return nil
+	}
+	if key == "" && !required {
+		return nil
+	}
+	if key == "" {
+		return errors.New("the API key is required")
+	}
+
+	keyring, err := w.confirm("Store the API key in the OS keyring instead of the config file?", false)
+	if err != nil {
+		return err
+	}
+	if keyring {
+		return storeSecret("openai.api_key", key)
+	}
+	viper.Set(profileKey("openai.api_key"), key)
+	return nil
+}
+
+// azureAuth asks for the authentication method of Azure and the credentials of
+// the Microsoft Entra ID service principal or the user-assigned managed identity.
+// It returns the chosen method.
+func (w *wizard) azureAuth() (string, error) {
+	auth, err := w.choose("Azure authentication", []string{
+		openai.AzureAuthKey, openai.AzureAuthClientSecret, openai.AzureAuthManagedIdentity, openai.AzureAuthDefault,
+	}, viper.GetString("openai.azure_auth"))
+	if err != nil {
+		return "", err
+	}
+	viper.Set(profileKey("openai.azure_auth"), auth)
+
+	switch auth {
+	case openai.AzureAuthClientSecret:
+		for _, q := range []struct{ question, key string }{
+			{"Tenant ID", "openai.azure_tenant_id"},
+			{"Client ID", "openai.azure_client_id"},
+		} {
+			val, err := w.ask(q.question, viper.GetString(q.key))
+			if err != nil {
+				return "", err
+			}
+			viper.Set(profileKey(q.key), val)
+		}
+		current := secret("openai.azure_client_secret")
+		def := ""
+		if current != "" {
+			def = "keep " + logger.MaskKey(current)
+		}
+		val, err := w.askSecret("Client secret", def)
+		if err != nil {
+			return "", err
+		}
+		if val == def {
+			break
+		}
+		keyring, err := w.confirm("Store the client secret in the OS keyring instead of the config file?", false)
+		if err != nil {
+			return "", err
+		}
+		if keyring {
+			return auth, storeSecret("openai.azure_client_secret", val)
+		}
+		viper.Set(profileKey("openai.azure_client_secret"), val)
+	case openai.AzureAuthManagedIdentity:
+		// empty for the system-assigned identity
+		val, err := w.ask("Client ID of the user-assigned identity, empty for the system-assigned one", viper.GetString("openai.azure_client_id"))
+		if err != nil {
+			return "", err
+		}
+		viper.Set(profileKey("openai.azure_client_id"), val)
+	}
+	return auth, nil
+}
+
+// bedrockModels lists the text models of AWS Bedrock to choose from,
+// they must be enabled in the Bedrock console first.
+var bedrockModels = []string{
+	"anthropic.claude-3-haiku-20240307-v1:0",
+	"anthropic.claude-3-5-sonnet-20240620-v1:0",
+	"anthropic.claude-3-opus-20240229-v1:0",
+	"amazon.titan-text-express-v1",
+	"amazon.titan-text-lite-v1",
+}
+
+// endpointModel asks for any model of the OpenAI-compatible or text-generation-inference
+// endpoint, the available ones are listed if the endpoint supports it.
+func (w *wizard) endpointModel(ctx context.Context) (string, error) {
+	if client, err := openClient(); err == nil {
+		ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+		if ids, err := client.Models(ctx); err == nil && len(ids) > 0 {
+			sort.Strings(ids)
+			return w.choose("Model", ids, viper.GetString("openai.model"))
+		}
+	}
+	return w.ask("Model, like meta-llama/llama-3.1-8b-instruct", viper.GetString("openai.model"))
+}
+
+// openaiModels lists the models of OpenAI to choose from if they can't be listed.
+var openaiModels = []string{
+	"gpt-3.5-turbo", "gpt-3.5-turbo-16k", "gpt-3.5-turbo-instruct",
+	"gpt-4", "gpt-4-32k", "gpt-4-turbo",
+	"gpt-4o", "gpt-4o-mini", "o1-mini",
+}
+
+// mistralModels lists the models of Mistral AI to choose from if they can't be listed.
+var mistralModels = []string{
+	"mistral-small-latest", "mistral-medium-latest", "mistral-large-latest",
+	"codestral-latest", "open-mistral-nemo",
+}
+
+// models returns the supported models available with the API key,
+// or the supported ones of the given models if they can't be listed.
+func (w *wizard) models(ctx context.Context, models []string) []string {
+	var supported []string
+	for _, m := range models {
+		if openai.IsSupportedModel(m) {
+			supported = append(supported, m)
+		}
+	}
+
+	client, err := openClient()
+	if err != nil {
+		return supported
+	}
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	logger.Info("Fetch the available models from the provider")
+	ids, err := client.Models(ctx)
+	if err != nil {
+		logger.Warn("can't list the models: " + err.Error())
+		return supported
+	}
+
+	var available []string
+	for _, id := range ids {
+		if openai.IsSupportedModel(id) {
+			available = append(available, id)
+		}
+	}
+	if len(available) == 0 {
+		return supported
+	}
+	sort.Strings(available)
+	return available
+}
+
+// contains reports whether the value is one of the options.
+func contains(options []string, val string) bool {
+	for _, o := range options {
+		if o == val {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/jira"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/util"
+
+	"github.com/spf13/viper"
+)
+
+// maxTicketDescription is the maximum length of the ticket description added to the prompts.
+const maxTicketDescription = 2000
+
+var (
+	// jiraIssues caches the issues read by the process, by key
+	jiraIssues   = map[string]*jira.Issue{}
+	jiraIssuesMu sync.Mutex
+)
+
+// newJira creates the Jira client of the jira config.
+func newJira() (*jira.Client, error) {
+	return jira.New(
+		jira.WithBaseURL(viper.GetString("jira.base_url")),
+		jira.WithEmail(viper.GetString("jira.email")),
+		jira.WithToken(secret("jira.token")),
+	)
+}
+
+// ticketVars returns the title and the description of the Jira issue of the ticket ID as
+// the ticket_title and ticket_description prompt variables. It's empty when jira.base_url
+// isn't set, the ticket isn't a Jira issue key or the issue can't be read.
+func ticketVars(ticket string) util.Data {
+	if viper.GetString("jira.base_url") == "" || !jira.IsKey(ticket) {
+		return nil
+	}
+
+	jiraIssuesMu.Lock()
+	defer jiraIssuesMu.Unlock()
+	issue, ok := jiraIssues[ticket]
+	if !ok {
+		client, err := newJira()
+		if err == nil {
+			logger.Debug("Read the Jira issue " + ticket)
+			issue, err = client.Issue(context.Background(), ticket)
+		}
+		if err != nil {
+			logger.Warn("can't read the Jira issue " + ticket + ": " + err.Error())
+		}
+		jiraIssues[ticket] = issue
+	}
+	if issue == nil {
+		return nil
+	}
+
+	description := []rune(issue.Description)
+	if len(description) > maxTicketDescription {
+		description = append(description[:maxTicketDescription], []rune("...")...)
+	}
+	return util.Data{
+		"ticket_title":       issue.Summary,
+		"ticket_description": string(description),
+	}
+}
+
+// postJiraComment posts the summary of the branch as a comment of its Jira issue.
+func postJiraComment(ctx context.Context, branch, summary string) error {
+	ticket := git.TicketID(branch)
+	if !jira.IsKey(ticket) {
+		return errors.New("the branch " + branch + " has no Jira issue key, like feature/ABC-123-login")
+	}
+	client, err := newJira()
+	if err != nil {
+		return err
+	}
+	logger.Info("Post the summary of the branch to the Jira issue " + ticket)
+	return client.AddComment(ctx, ticket, "Summary of the branch "+branch+":\n\n"+summary)
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/appleboy/CodeGPT/logger"
+
+	"github.com/fatih/color"
+	"github.com/spf13/viper"
+	"github.com/zalando/go-keyring"
+	"golang.org/x/term"
+)
+
+// keyringService is the service name of the secrets in the OS keyring.
+const keyringService = "codegpt"
+
+// secretKeys lists the config keys that can be stored in the OS keyring.
+var secretKeys = []string{
+	"openai.api_key",
+	"openai.azure_client_secret",
+	"openai.proxy_password",
+	"github.token",
+	"github.webhook_secret",
+	"gitea.token",
+	"bitbucket.token",
+	"jira.token",
+	"notify.slack_webhook",
+	"notify.teams_webhook",
+	"serve.token",
+	"audit.key",
+}
+
+// isSecretKey reports whether the config key can be stored in the OS keyring.
+func isSecretKey(key string) bool {
+	for _, k := range secretKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// secret returns the value of the config key. If it's not set in the config file,
+// the environment or the flags, it's read from the output of the <key>_cmd command
+// or from the OS keyring.
+func secret(key string) string {
+	if val := viper.GetString(key); val != "" {
+		return val
+	}
+
+	// fetch the secret from a password manager like `op read` or `pass show`
+	if command := viper.GetString(key + "_cmd"); command != "" {
+		val, err := secretCommand(command)
+		if err != nil {
+			logger.Error("can't run " + key + "_cmd: " + err.Error())
+			return ""
+		}
+		return val
+	}
+
+	val, err := keyring.Get(keyringService, profileKey(key))
+	if err != nil {
+		if !errors.Is(err, keyring.ErrNotFound) {
+			logger.Debug("can't read " + key + " from the OS keyring: " + err.Error())
+		}
+		return ""
+	}
+	return val
+}
+
+// storeSecret saves the value of the config key in the OS keyring and removes
+// the plaintext value from the config file.
+func storeSecret(key, val string) error {
+	if !isSecretKey(key) {
+		return fmt.Errorf("only %s can be stored in the OS keyring", strings.Join(secretKeys, ", "))
+	}
+	if err := keyring.Set(keyringService, profileKey(key), val); err != nil {
+		return fmt.Errorf("can't store %s in the OS keyring: %w", key, err)
+	}
+	viper.Set(profileKey(key), "")
+	return nil
+}
+
+// deleteSecret removes the value of the config key from the OS keyring, it reports
+// whether the keyring had it.
+func deleteSecret(key string) (bool, error) {
+	if err := keyring.Delete(keyringService, profileKey(key)); err != nil {
+		if errors.Is(err, keyring.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("can't remove %s from the OS keyring: %w", key, err)
+	}
+	return true, nil
+}
+
+// readSecret reads the value of the config key from the input, so it doesn't
+// end up in the shell history. The value isn't echoed when the input is a terminal.
+func readSecret(r io.Reader, key string) (string, error) {
+	fmt.Fprint(color.Output, "Enter the value of "+key+": ")
+	var line string
+	if f, ok := r.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		data, err := term.ReadPassword(int(f.Fd()))
+		// the newline typed by the user isn't echoed either
+		fmt.Fprintln(color.Output)
+		if err != nil {
+			return "", err
+		}
+		line = string(data)
+	} else {
+		var err error
+		line, err = bufio.NewReader(r).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+	}
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", errors.New("empty value for " + key)
+	}
+	return line, nil
+}
+
+// secretOutputs caches the outputs of the secret commands, a command may prompt for
+// the passphrase of the password manager, it runs once per process.
+var (
+	secretOutputs   = map[string]secretOutput{}
+	secretOutputsMu sync.Mutex
+)
+
+type secretOutput struct {
+	val string
+	err error
+}
+
+// secretCommand returns the output of the secret command, it's run the first time only.
+func secretCommand(command string) (string, error) {
+	secretOutputsMu.Lock()
+	defer secretOutputsMu.Unlock()
+	out, ok := secretOutputs[command]
+	if !ok {
+		out.val, out.err = runSecretCommand(command)
+		secretOutputs[command] = out
+	}
+	return out.val, out.err
+}
+
+// runSecretCommand runs the command with the shell and returns its trimmed output.
+func runSecretCommand(command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	// password managers may prompt for a passphrase
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+
+	val := strings.TrimSpace(string(out))
+	if val == "" {
+		return "", errors.New("the command printed nothing")
+	}
+	return val, nil
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/zalando/go-keyring"
+)
+
+func TestSecretCommandRunsOnce(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the command uses sh")
+	}
+	runs := filepath.Join(t.TempDir(), "runs")
+	viper.Set("jira.token_cmd", "echo run >> "+runs+" && echo s3cret")
+	t.Cleanup(func() { viper.Set("jira.token_cmd", nil) })
+
+	for i := 0; i < 3; i++ {
+		if got := secret("jira.token"); got != "s3cret" {
+			t.Fatalf("secret() = %q, want s3cret", got)
+		}
+	}
+	data, err := os.ReadFile(runs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "run"); n != 1 {
+		t.Errorf("the secret command ran %d times, want once", n)
+	}
+}
+
+func TestRunSecretCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the command uses sh")
+	}
+	tests := []struct {
+		command string
+		want    string
+		err     string
+	}{
+		{"printf '  s3cret \\n\\n'", "s3cret", ""},
+		{"echo 'vault is sealed' >&2; exit 2", "", "vault is sealed"},
+		{"exit 1", "", "exit status 1"},
+		{"true", "", "the command printed nothing"},
+	}
+	for _, tt := range tests {
+		got, err := runSecretCommand(tt.command)
+		if got != tt.want {
+			t.Errorf("runSecretCommand(%q) = %q, want %q", tt.command, got, tt.want)
+		}
+		if tt.err == "" && err != nil {
+			t.Errorf("runSecretCommand(%q) error = %v", tt.command, err)
+		}
+		if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("runSecretCommand(%q) error = %v, want %q", tt.command, err, tt.err)
+		}
+	}
+}
+
+func TestSecretCommandFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the command uses sh")
+	}
+	runs := filepath.Join(t.TempDir(), "runs")
+	command := "echo run >> " + runs + " && echo 'vault is sealed' >&2 && exit 1"
+	viper.Set("jira.token_cmd", command)
+	t.Cleanup(func() { viper.Set("jira.token_cmd", nil) })
+
+	// the failure is logged by every call, the command isn't run again to prompt for the passphrase
+	for i := 0; i < 2; i++ {
+		if got := secret("jira.token"); got != "" {
+			t.Fatalf("secret() = %q, want empty", got)
+		}
+	}
+	if _, err := secretCommand(command); err == nil || !strings.Contains(err.Error(), "vault is sealed") {
+		t.Errorf("secretCommand() error = %v, want the stderr of the command", err)
+	}
+	data, err := os.ReadFile(runs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "run"); n != 1 {
+		t.Errorf("the secret command ran %d times, want once", n)
+	}
+}
+
+func TestSecretConfigWins(t *testing.T) {
+	viper.Set("jira.token", "from-config")
+	viper.Set("jira.token_cmd", "echo from-command")
+	t.Cleanup(func() {
+		viper.Set("jira.token", nil)
+		viper.Set("jira.token_cmd", nil)
+	})
+	if got := secret("jira.token"); got != "from-config" {
+		t.Errorf("secret() = %q, want the value of the config", got)
+	}
+}
+
+func TestKeyring(t *testing.T) {
+	keyring.MockInit()
+	t.Cleanup(func() { viper.Set("jira.token", nil) })
+
+	if err := storeSecret("jira.token", "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	if v := viper.GetString("jira.token"); v != "" {
+		t.Errorf("jira.token = %q, want the plaintext value removed", v)
+	}
+	if got := secret("jira.token"); got != "s3cret" {
+		t.Errorf("secret() = %q, want the value of the keyring", got)
+	}
+	for _, want := range []bool{true, false} {
+		if deleted, err := deleteSecret("jira.token"); err != nil || deleted != want {
+			t.Errorf("deleteSecret() = %v, %v; want %v", deleted, err, want)
+		}
+	}
+	if got := secret("jira.token"); got != "" {
+		t.Errorf("secret() = %q after the delete, want empty", got)
+	}
+}
+
+func TestKeyringUnavailable(t *testing.T) {
+	keyring.MockInitWithError(errors.New("no secret service"))
+	t.Cleanup(keyring.MockInit)
+
+	if err := storeSecret("jira.token", "s3cret"); err == nil || !strings.Contains(err.Error(), "no secret service") {
+		t.Errorf("storeSecret() error = %v, want the keyring error", err)
+	}
+	// the secrets of the config are still read, the other ones are empty
+	if got := secret("jira.token"); got != "" {
+		t.Errorf("secret() = %q, want empty", got)
+	}
+	viper.Set("jira.token", "from-config")
+	t.Cleanup(func() { viper.Set("jira.token", nil) })
+	if got := secret("jira.token"); got != "from-config" {
+		t.Errorf("secret() = %q, want the value of the config", got)
+	}
+
+	// the value of the config file is removed without the keyring, like in CI
+	name := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(name, []byte("jira:\n  token: from-config\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	used := viper.ConfigFileUsed()
+	viper.SetConfigFile(name)
+	t.Cleanup(func() { viper.SetConfigFile(used) })
+	if err := unsetConfig("jira.token"); err != nil {
+		t.Fatalf("unsetConfig() = %v, want the fallback to the config file", err)
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "from-config") {
+		t.Errorf("config file = %q, want jira.token removed", data)
+	}
+}
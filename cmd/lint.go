@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/lint"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/review"
+	"github.com/appleboy/CodeGPT/util"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// The modes of the commit-msg hook when the message breaks the rules.
+const (
+	lintReject = "reject"
+	lintFix    = "fix"
+)
+
+var (
+	lintFile    string
+	lintFixFlag bool
+	lintHook    bool
+)
+
+func init() {
+	lintCmd.Flags().StringVar(&lintFile, "file", "", "commit message file, like the one given to the commit-msg hook")
+	lintCmd.Flags().BoolVar(&lintFixFlag, "fix", false, "ask the model to reformat the message breaking the rules")
+	lintCmd.Flags().BoolVar(&lintHook, "hook", false, "run as the commit-msg hook, the mode is hook.commit_msg")
+	lintCmd.Flags().StringVar(&commitModel, "model", "gpt-3.5-turbo", "select openai model")
+	lintCmd.Flags().IntVar(&maxTokens, "max_tokens", 300, "the maximum number of tokens to generate in the chat completion.")
+	lintCmd.Flags().DurationVarP(&timeout, "timeout", "t", 0, "timeout of every request to the provider, default is openai.timeout")
+	lintCmd.Flags().DurationVar(&connectTimeout, "connect_timeout", 0, "timeout of the connection to the provider, default is openai.connect_timeout")
+}
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [<commit>|-]",
+	Short: "Check a commit message against Conventional Commits, from a file, a commit or stdin",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := check(); err != nil {
+			return err
+		}
+
+		g := git.New()
+		var (
+			message string
+			err     error
+		)
+		switch {
+		case lintFile != "":
+			data, err := os.ReadFile(lintFile)
+			if err != nil {
+				return err
+			}
+			message = string(data)
+		case len(args) == 0:
+			dir, err := g.GitDir()
+			if err != nil {
+				return err
+			}
+			lintFile = filepath.Join(strings.TrimSpace(dir), "COMMIT_EDITMSG")
+			data, err := os.ReadFile(lintFile)
+			if err != nil {
+				return err
+			}
+			message = string(data)
+		case args[0] == "-":
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return err
+			}
+			message = string(data)
+		default:
+			message, err = g.CommitMessage(args[0])
+			if err != nil {
+				return err
+			}
+		}
+
+		// the editors on Windows may save the message with CRLF line endings
+		message = util.NormalizeNewlines(message)
+		linter := newLinter()
+		problems := linter.Check(message)
+		result.Problems = problems
+		if len(problems) == 0 {
+			if !lintHook {
+				logger.Info("The commit message follows the Conventional Commits")
+			}
+			return nil
+		}
+
+		fix := lintFixFlag
+		if lintHook {
+			fix = fix || viper.GetString("hook.commit_msg") == lintFix
+		}
+		if !fix {
+			printProblems(problems)
+			return invalid(errors.New("the commit message doesn't follow the Conventional Commits"))
+		}
+
+		logger.Info("The commit message doesn't follow the Conventional Commits, ask the model to reformat it")
+		fixed, err := fixMessage(cmd, g, message, problems)
+		if err != nil {
+			return err
+		}
+		if again := linter.Check(fixed); len(again) > 0 {
+			printProblems(again)
+			return invalid(errors.New("the reformatted commit message still doesn't follow the Conventional Commits"))
+		}
+		result.Problems = nil
+		result.Message = fixed
+
+		if lintFile != "" {
+			logger.Info("Write the reformatted message to " + lintFile + " file")
+			return os.WriteFile(lintFile, []byte(fixed+"\n"), 0o644)
+		}
+		if !isMachineOutput() {
+			color.Yellow("==================Commit message==================")
+			color.Yellow("\n" + fixed + "\n\n")
+			color.Yellow("==================================================")
+		}
+		return nil
+	},
+}
+
+// newLinter returns the linter of the lint.types, lint.scopes and lint.header_max_length config.
+func newLinter() *lint.Linter {
+	return lint.New(
+		lint.WithTypes(viper.GetStringSlice("lint.types")...),
+		lint.WithScopes(viper.GetStringSlice("lint.scopes")...),
+		lint.WithHeaderMaxLength(viper.GetInt("lint.header_max_length")),
+	)
+}
+
+// printProblems prints the broken rules with the hints to fix the message.
+func printProblems(problems []lint.Problem) {
+	if outputFormat == review.FormatGitHubActions {
+		for _, p := range problems {
+			fmt.Fprintln(os.Stdout, review.Annotation{
+				Level:   review.LevelError,
+				File:    lintFile,
+				Title:   "Conventional Commits",
+				Message: p.String(),
+			}.String())
+		}
+		return
+	}
+	if isMachineOutput() {
+		return
+	}
+	for _, p := range problems {
+		color.Red("✖ " + p.String())
+	}
+	color.Yellow("Write the message like: feat(cli): add the lint command")
+	color.Yellow("Reformat it with: codegpt lint --fix, or set hook.commit_msg to fix")
+	color.Yellow("Skip the check with: git commit --no-verify")
+}
+
+// fixMessage asks the model to rewrite the message following the broken rules.
+func fixMessage(cmd *cobra.Command, g *git.Command, message string, problems []lint.Problem) (string, error) {
+	var hints []string
+	for _, p := range problems {
+		hints = append(hints, "- "+p.String())
+	}
+	types := viper.GetStringSlice("lint.types")
+	if len(types) == 0 {
+		types = lint.DefaultTypes
+	}
+	maxLength := viper.GetInt("lint.header_max_length")
+	if maxLength <= 0 {
+		maxLength = lint.DefaultHeaderMaxLength
+	}
+
+	out, err := util.GetTemplateByString(
+		prompt.ConventionalFixTemplate,
+		withVars(promptVars(g, nil), util.Data{
+			"output_message":    lint.Clean(message),
+			"output_problems":   strings.Join(hints, "\n"),
+			"output_types":      strings.Join(types, ", "),
+			"output_scopes":     strings.Join(viper.GetStringSlice("lint.scopes"), ", "),
+			"output_max_length": strconv.Itoa(maxLength),
+		}),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	client, err := newClient(cmd.Context())
+	if err != nil {
+		return "", err
+	}
+	resp, err := completion(cmd.Context(), client, out)
+	if err != nil {
+		return "", err
+	}
+	printUsage(cmd.Context(), resp.Usage)
+
+	fixed := strings.TrimSpace(html.UnescapeString(resp.Content))
+	fixed = strings.TrimSuffix(strings.TrimPrefix(fixed, "```"), "```")
+	return lint.Clean(fixed), nil
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/lint"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/review"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var lintNoSuggest bool
+
+func init() {
+	lintCommitsCmd.Flags().BoolVar(&lintNoSuggest, "no_suggest", false, "don't ask the model for the rewrites of the messages breaking the rules")
+	lintCommitsCmd.Flags().StringVar(&commitModel, "model", "gpt-3.5-turbo", "select openai model")
+	lintCommitsCmd.Flags().IntVar(&maxTokens, "max_tokens", 300, "the maximum number of tokens to generate in the chat completion.")
+	lintCommitsCmd.Flags().DurationVarP(&timeout, "timeout", "t", 0, "timeout of every request to the provider, default is openai.timeout")
+	lintCommitsCmd.Flags().DurationVar(&connectTimeout, "connect_timeout", 0, "timeout of the connection to the provider, default is openai.connect_timeout")
+}
+
+// Violation is a commit of the range with a message breaking the rules.
+type Violation struct {
+	Hash       string         `json:"hash"`
+	Header     string         `json:"header"`
+	Problems   []lint.Problem `json:"problems"`
+	Suggestion string         `json:"suggestion,omitempty"`
+}
+
+var lintCommitsCmd = &cobra.Command{
+	Use:   "lint-commits <range>",
+	Short: "Check the commit messages of a range against Conventional Commits",
+	Long: `Check the messages of the commits of a range, like origin/main..HEAD, against Conventional
+Commits and the lint rules of the config, and suggest a rewrite of the messages breaking them.
+A single revision, like origin/main, checks the commits since it. The command fails when a
+message breaks the rules, to use it as a CI check of the pull requests.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := check(); err != nil {
+			return err
+		}
+
+		revisions := args[0]
+		if !strings.Contains(revisions, "..") {
+			revisions += "..HEAD"
+		}
+		g := git.New()
+		entries, err := g.Log(revisions)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			logger.Info("There are no commits in " + revisions)
+			return nil
+		}
+		// report the commits in the order they were made
+		slices.Reverse(entries)
+
+		linter := newLinter()
+		for _, e := range entries {
+			problems := linter.Check(e.Message)
+			if len(problems) == 0 {
+				continue
+			}
+			v := Violation{
+				Hash:     e.Hash,
+				Header:   strings.SplitN(lint.Clean(e.Message), "\n", 2)[0],
+				Problems: problems,
+			}
+			if !lintNoSuggest {
+				v.Suggestion, err = fixMessage(cmd, g, e.Message, problems)
+				if err != nil {
+					return err
+				}
+			}
+			result.Violations = append(result.Violations, v)
+			printViolation(v)
+		}
+
+		if n := len(result.Violations); n > 0 {
+			return invalid(fmt.Errorf("%d of the %d commits in %s don't follow the Conventional Commits", n, len(entries), revisions))
+		}
+		logger.Info("The " + strconv.Itoa(len(entries)) + " commits in " + revisions + " follow the Conventional Commits")
+		return nil
+	},
+}
+
+// printViolation prints the commit with the broken rules and the suggested rewrite.
+func printViolation(v Violation) {
+	if outputFormat == review.FormatGitHubActions {
+		lines := []string{v.Header, ""}
+		for _, p := range v.Problems {
+			lines = append(lines, "- "+p.String())
+		}
+		if v.Suggestion != "" {
+			lines = append(lines, "", "Suggested message:", "", v.Suggestion)
+		}
+		fmt.Fprintln(os.Stdout, review.Annotation{
+			Level:   review.LevelError,
+			Title:   "Commit " + v.Hash[:7] + " doesn't follow the Conventional Commits",
+			Message: strings.Join(lines, "\n"),
+		}.String())
+		return
+	}
+	if isMachineOutput() {
+		return
+	}
+	color.Red("✖ " + v.Hash[:7] + " " + v.Header)
+	for _, p := range v.Problems {
+		color.Red("    " + p.String())
+	}
+	if v.Suggestion != "" {
+		color.Yellow("  Suggested message:")
+		for _, line := range strings.Split(v.Suggestion, "\n") {
+			if line != "" {
+				line = "    " + line
+			}
+			color.Green(line)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/appleboy/CodeGPT/metrics"
+	"github.com/appleboy/CodeGPT/openai"
+	"github.com/appleboy/CodeGPT/tracing"
+	"github.com/appleboy/CodeGPT/usage"
+)
+
+// The metrics of the server, exposed on /metrics for Prometheus.
+var (
+	requestsTotal = metrics.NewCounter("codegpt_requests_total",
+		"Requests handled by the server, by command and HTTP status.", "command", "status")
+	requestDuration = metrics.NewHistogram("codegpt_request_duration_seconds",
+		"Latency of the requests handled by the server.", metrics.DefaultBuckets, "command")
+	providerRequestsTotal = metrics.NewCounter("codegpt_provider_requests_total",
+		"Completion requests sent to the provider, by result ok or error.", "provider", "model", "result")
+	providerDuration = metrics.NewHistogram("codegpt_provider_request_duration_seconds",
+		"Latency of the completion requests sent to the provider.", metrics.DefaultBuckets, "provider", "model")
+	tokensTotal = metrics.NewCounter("codegpt_tokens_total",
+		"Tokens used by the completions, by type prompt or completion.", "provider", "model", "type")
+	costTotal = metrics.NewCounter("codegpt_cost_dollars_total",
+		"Estimated cost in USD of the completions.", "provider", "model")
+	cacheRequestsTotal = metrics.NewCounter("codegpt_cache_requests_total",
+		"Lookups of the response cache, by result hit or miss.", "result")
+)
+
+// metricsRegistry writes the metrics of the server.
+var metricsRegistry = metrics.NewRegistry(
+	requestsTotal,
+	requestDuration,
+	providerRequestsTotal,
+	providerDuration,
+	tokensTotal,
+	costTotal,
+	cacheRequestsTotal,
+)
+
+// observeProvider calls the provider with fn and records its latency, its result and
+// the tokens and the estimated cost of its response, in the metrics and a span.
+func observeProvider(ctx context.Context, fn func(ctx context.Context) (*openai.Response, error)) (*openai.Response, error) {
+	provider, model := result.Provider, result.Model
+	ctx, span := tracing.StartKind(ctx, "provider", tracing.KindClient,
+		tracing.String("gen_ai.system", provider),
+		tracing.String("gen_ai.request.model", model),
+	)
+	defer span.End()
+
+	start := time.Now()
+	resp, err := fn(ctx)
+	providerDuration.Observe(time.Since(start).Seconds(), provider, model)
+	if err != nil {
+		providerRequestsTotal.Inc(provider, model, "error")
+		span.SetError(err)
+		return resp, &providerError{err: err}
+	}
+	providerRequestsTotal.Inc(provider, model, "ok")
+	tokensTotal.Add(float64(resp.Usage.PromptTokens), provider, model, "prompt")
+	tokensTotal.Add(float64(resp.Usage.CompletionTokens), provider, model, "completion")
+	costTotal.Add(usage.Cost(model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens), provider, model)
+	span.SetAttributes(
+		tracing.Int("gen_ai.usage.input_tokens", resp.Usage.PromptTokens),
+		tracing.Int("gen_ai.usage.output_tokens", resp.Usage.CompletionTokens),
+	)
+	return resp, nil
+}
+
+// metricsHandler serves the metrics, with the bearer token of serve.token if it's set.
+func (s *server) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		s.fail(w, http.StatusUnauthorized, errors.New("invalid or missing bearer token"))
+		return
+	}
+	metricsRegistry.ServeHTTP(w, r)
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/appleboy/CodeGPT/cache"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/openai"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// modelsTTL is how long the model list of the provider is cached.
+const modelsTTL = 24 * time.Hour
+
+var modelsRefresh bool
+
+func init() {
+	modelsCmd.Flags().BoolVar(&modelsRefresh, "refresh", false, "fetch the model list from the provider instead of the cache")
+}
+
+// availableModels returns the sorted model IDs of the provider, cached for a day unless
+// the cache is disabled. The list depends on the account, the cache is kept per provider,
+// endpoint, region and API key, only the hash of the key is used.
+func availableModels(ctx context.Context, client *openai.Client, refresh bool) ([]string, error) {
+	key := cache.Key("models",
+		viper.GetString("openai.provider"),
+		viper.GetString("openai.base_url"),
+		viper.GetString("bedrock.region"),
+		cache.Key(secret("openai.api_key")),
+	)
+	var c *cache.Cache
+	if !noCache && viper.GetBool("cache.enable") {
+		var err error
+		c, err = cache.New(
+			cache.WithDir(viper.GetString("cache.dir")),
+			cache.WithTTL(modelsTTL),
+		)
+		if err != nil {
+			logger.Debug("can't open the cache: " + err.Error())
+			c = nil
+		}
+	}
+	if c != nil && !refresh {
+		if out, ok := c.Get(key); ok {
+			return strings.Split(out, "\n"), nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	ids, err := client.Models(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(ids)
+
+	if c != nil && len(ids) > 0 {
+		if err := c.Set(key, strings.Join(ids, "\n")); err != nil {
+			logger.Debug("can't cache the model list: " + err.Error())
+		}
+	}
+	return ids, nil
+}
+
+// modelChoices returns the models of the provider to choose from, the supported ones
+// when they can't be listed. The configured model comes first if it's not listed.
+func modelChoices(ctx context.Context) []string {
+	var ids []string
+	if client, err := openClient(); err == nil && knownModels() {
+		ids, _ = availableModels(ctx, client, false)
+	}
+	if len(ids) == 0 {
+		switch viper.GetString("openai.provider") {
+		case "", openai.OPENAI:
+			ids = openaiModels
+		case openai.MISTRAL:
+			ids = mistralModels
+		case openai.BEDROCK:
+			ids = bedrockModels
+		}
+	}
+	if model := viper.GetString("openai.model"); model != "" && !contains(ids, model) {
+		ids = append([]string{model}, ids...)
+	}
+	return ids
+}
+
+// validateModel checks that the provider has the configured model and suggests
+// the close names if it doesn't. It's skipped if the models can't be listed.
+func validateModel(ctx context.Context, client *openai.Client) error {
+	if !viper.GetBool("openai.validate_model") || dryRun || promptOnly {
+		return nil
+	}
+	if !knownModels() {
+		return nil
+	}
+	if _, file := cassetteConfig(); file != "" {
+		return nil
+	}
+
+	ids, err := availableModels(ctx, client, false)
+	if err != nil {
+		logger.Debug("can't validate the model: " + err.Error())
+		return nil
+	}
+	return modelError(viper.GetString("openai.model"), ids)
+}
+
+// modelError returns an error with the suggestions if the model is not one of the ids.
+func modelError(model string, ids []string) error {
+	if contains(ids, model) {
+		return nil
+	}
+	msg := "model " + model + " is not available with your API key."
+	if suggestions := openai.Suggest(model, ids, 3); len(suggestions) > 0 {
+		msg += " Did you mean " + strings.Join(suggestions, ", ") + "?"
+	}
+	return invalid(errors.New(msg + " Run `codegpt models` to list the models."))
+}
+
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "List the models of the provider",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := openClient()
+		if err != nil {
+			return err
+		}
+
+		ids, err := availableModels(cmd.Context(), client, modelsRefresh)
+		if err != nil {
+			return err
+		}
+		result.Models = ids
+
+		if outputFormat == outputJSON {
+			return nil
+		}
+		for _, id := range ids {
+			if window := openai.ContextWindow(id); window > 0 {
+				fmt.Println(id + "\t" + strconv.Itoa(window) + " tokens")
+				continue
+			}
+			fmt.Println(id)
+		}
+		return nil
+	},
+}
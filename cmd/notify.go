@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"path"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/notify"
+)
+
+// notifyFlag posts the result of the command to the Slack and Teams webhooks.
+var notifyFlag bool
+
+// sendNotification posts the message to the Slack and Teams webhooks of the
+// notify.slack_webhook and notify.teams_webhook config.
+func sendNotification(ctx context.Context, title, text string) error {
+	n, err := notify.New(
+		notify.WithSlack(secret("notify.slack_webhook")),
+		notify.WithTeams(secret("notify.teams_webhook")),
+	)
+	if err != nil {
+		return err
+	}
+	logger.Info("Send the notification: " + title)
+	return n.Send(ctx, notify.Message{Title: title, Text: text})
+}
+
+// repoName returns the name of the repository folder, empty outside of a repository.
+func repoName(g *git.Command) string {
+	root, err := g.TopLevel()
+	if err != nil {
+		return ""
+	}
+	return path.Base(root)
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/appleboy/CodeGPT/audit"
+	"github.com/appleboy/CodeGPT/compress"
+	"github.com/appleboy/CodeGPT/lint"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/openai"
+	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/review"
+	"github.com/appleboy/CodeGPT/truncate"
+	"github.com/appleboy/CodeGPT/usage"
+	"github.com/appleboy/CodeGPT/util"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-colorable"
+	"github.com/spf13/cobra"
+)
+
+// Output formats of the commands.
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+// outputFormat is the output format selected with the global --output flag.
+var outputFormat string
+
+// Result is the machine-readable result of a command, printed with --output json.
+type Result struct {
+	Command     string                     `json:"command"`
+	Version     string                     `json:"version"`
+	Model       string                     `json:"model,omitempty"`
+	Provider    string                     `json:"provider,omitempty"`
+	Message     string                     `json:"message,omitempty"`
+	Candidates  []string                   `json:"candidates,omitempty"`
+	Findings    []review.Finding           `json:"findings,omitempty"`
+	Commits     []prompt.CommitGroup       `json:"commits,omitempty"`
+	Stats       map[string][]usage.Summary `json:"stats,omitempty"`
+	Checks      []Check                    `json:"checks,omitempty"`
+	Problems    []lint.Problem             `json:"problems,omitempty"`
+	Violations  []Violation                `json:"violations,omitempty"`
+	Models      []string                   `json:"models,omitempty"`
+	Config      map[string]string          `json:"config,omitempty"`
+	Bump        *Bump                      `json:"bump,omitempty"`
+	Repos       []RepoResult               `json:"repos,omitempty"`
+	Omitted     []truncate.Omitted         `json:"omitted,omitempty"`
+	Compression *compress.Report           `json:"compression,omitempty"`
+	Audit       []audit.Record             `json:"audit,omitempty"`
+	Update      *Update                    `json:"update,omitempty"`
+	Style       *StyleResult               `json:"style,omitempty"`
+	Usage       openai.Usage               `json:"usage"`
+	DurationMs  int64                      `json:"duration_ms"`
+	Error       string                     `json:"error,omitempty"`
+	ExitCode    int                        `json:"exit_code,omitempty"`
+}
+
+// result collects the output of the running command.
+var result = &Result{}
+
+// startTime is the time the command started.
+var startTime = time.Now()
+
+// validOutputFormats lists the global output formats and the review specific ones.
+var validOutputFormats = map[string]bool{
+	outputText:            true,
+	outputJSON:            true,
+	review.FormatTable:    true,
+	review.FormatMarkdown: true,
+	review.FormatSARIF:    true,
+	// the annotations of the review and lint commands
+	review.FormatGitHubActions: true,
+}
+
+// commandOutputFormats lists the output formats of the commands other than text and json.
+var commandOutputFormats = map[string][]string{
+	"review":       {review.FormatTable, review.FormatMarkdown, review.FormatSARIF, review.FormatGitHubActions},
+	"lint":         {review.FormatGitHubActions},
+	"lint-commits": {review.FormatGitHubActions},
+}
+
+// isMachineOutput reports whether the output must be machine-readable only.
+func isMachineOutput() bool {
+	return outputFormat == outputJSON || outputFormat == review.FormatSARIF || outputFormat == review.FormatGitHubActions
+}
+
+// setupOutput validates the output format and moves the progress messages to stderr
+// for the machine-readable formats, so stdout only contains the result.
+func setupOutput(cmd *cobra.Command, args []string) error {
+	if !validOutputFormats[outputFormat] {
+		return errors.New("output must be one of text, json, table, markdown, sarif or github-actions")
+	}
+	// the format is checked before any request is sent to the provider
+	if outputFormat != outputText && outputFormat != outputJSON && !contains(commandOutputFormats[cmd.Name()], outputFormat) {
+		return invalid(fmt.Errorf("the %s command doesn't support --output %s", cmd.Name(), outputFormat))
+	}
+	if isMachineOutput() {
+		color.Output = colorable.NewColorableStderr()
+	}
+
+	level, err := logger.ParseLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	if quiet {
+		level = logger.ErrorLevel
+	}
+	logger.SetLevel(level)
+
+	// the colors of the legacy Windows consoles are still written by go-colorable
+	if err := util.EnableVirtualTerminal(); err != nil {
+		logger.Debug("can't enable the ANSI escape sequences of the console: " + err.Error())
+	}
+	return nil
+}
+
+// addUsage adds the token usage of a request to the result.
+func addUsage(ctx context.Context, usage openai.Usage) {
+	r := resultOf(ctx)
+	r.Usage.PromptTokens += usage.PromptTokens
+	r.Usage.CompletionTokens += usage.CompletionTokens
+	r.Usage.TotalTokens += usage.TotalTokens
+}
+
+// resultKey is the context key of the result of a request of the server.
+type resultKey struct{}
+
+// withResult returns a copy of the context with the result of a request, the requests
+// served at the same time don't share the result of the process.
+func withResult(ctx context.Context, r *Result) context.Context {
+	return context.WithValue(ctx, resultKey{}, r)
+}
+
+// resultOf returns the result of the request of the context, the result of the command
+// otherwise.
+func resultOf(ctx context.Context) *Result {
+	if r, ok := ctx.Value(resultKey{}).(*Result); ok {
+		return r
+	}
+	return result
+}
+
+// writeResult prints the result of the command as JSON to stdout.
+func writeResult(cmd *cobra.Command, err error) {
+	if cmd != nil {
+		result.Command = cmd.Name()
+	}
+	result.Version = Version
+	result.DurationMs = time.Since(startTime).Milliseconds()
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(result)
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/git"
+
+	"github.com/fatih/color"
+)
+
+const hunkHelp = `y - use this hunk
+n - do not use this hunk
+a - use this hunk and all later hunks in the file
+d - do not use this hunk or any of the later hunks in the file
+q - quit; do not use this hunk or any of the remaining ones
+? - print help`
+
+// printHunk prints the hunk with the added lines in green and the deleted lines in red.
+func printHunk(h *git.Hunk) {
+	color.Cyan(h.Header)
+	for _, line := range h.Lines {
+		switch {
+		case strings.HasPrefix(line, "+"):
+			color.Green(line)
+		case strings.HasPrefix(line, "-"):
+			color.Red(line)
+		default:
+			fmt.Fprintln(color.Output, line)
+		}
+	}
+}
+
+// selectHunks asks which hunks the commit message should be generated from, like `git add --patch`.
+func selectHunks(files []*git.FilePatch, in io.Reader) error {
+	reader := bufio.NewReader(in)
+
+	// deselect marks the hunks from the given index onwards as not selected.
+	deselect := func(hunks []*git.Hunk) {
+		for _, h := range hunks {
+			h.Selected = false
+		}
+	}
+
+	for n, f := range files {
+		for i := 0; i < len(f.Hunks); i++ {
+			h := f.Hunks[i]
+			color.New(color.Bold).Println("--- " + f.Name())
+			printHunk(h)
+
+			fmt.Fprintf(color.Output, "(%d/%d) Use this hunk [y,n,a,d,q,?]? ", i+1, len(f.Hunks))
+			answer, err := reader.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return err
+			}
+
+			switch strings.TrimSpace(answer) {
+			case "y":
+				h.Selected = true
+			case "n":
+				h.Selected = false
+			case "a":
+				i = len(f.Hunks)
+			case "d":
+				deselect(f.Hunks[i:])
+				i = len(f.Hunks)
+			case "q":
+				deselect(f.Hunks[i:])
+				for _, rest := range files[n+1:] {
+					deselect(rest.Hunks)
+				}
+				return nil
+			default:
+				color.Red(hunkHelp)
+				i--
+			}
+
+			if err == io.EOF {
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/openai"
+	"github.com/appleboy/CodeGPT/policy"
+
+	"github.com/spf13/viper"
+)
+
+// orgPolicy is the managed policy of the organization, nil without policy file.
+var orgPolicy *policy.Policy
+
+// applyPolicy reads the managed policy file and forces its settings over the user and
+// repository configs, the flags and the environment variables. A policy which can't be
+// read stops the commands, so a broken file never lifts the rules.
+func applyPolicy() error {
+	p, err := policy.Load(policy.Path())
+	if err != nil {
+		return err
+	}
+	orgPolicy = p
+	if p == nil {
+		return nil
+	}
+	logger.Debug("use the managed policy " + p.File)
+
+	clampTokens()
+	if p.ForceRedact() {
+		viper.Set("redact.enable", true)
+		viper.Set("redact.patterns", p.RedactPatterns(viper.GetStringSlice("redact.patterns")))
+	}
+	if p.Privacy.LocalOnly {
+		viper.Set("privacy.local_only", true)
+		viper.Set("privacy.allowed_hosts", p.Privacy.AllowedHosts)
+	}
+	return nil
+}
+
+// clampTokens bounds the max tokens to the ones of the managed policy, again after the
+// flags of the command are applied.
+func clampTokens() {
+	if n := viper.GetInt("openai.max_tokens"); orgPolicy.Tokens(n) != n {
+		viper.Set("openai.max_tokens", orgPolicy.Tokens(n))
+	}
+}
+
+// checkPolicy returns an error when the provider or the model isn't allowed by the
+// managed policy. The mock provider sends nothing, it's always allowed.
+func checkPolicy(provider string) error {
+	if provider == openai.MOCK {
+		return nil
+	}
+	return orgPolicy.Check(provider, viper.GetString("openai.model"))
+}
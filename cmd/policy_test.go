@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/appleboy/CodeGPT/policy"
+
+	"github.com/spf13/viper"
+)
+
+func TestCheckClampsMaxTokensFlag(t *testing.T) {
+	orgPolicy = &policy.Policy{MaxTokens: 1000}
+	t.Cleanup(func() {
+		orgPolicy = nil
+		viper.Set("openai.max_tokens", nil)
+		_ = reviewCmd.Flags().Set("max_tokens", "300")
+	})
+	viper.Set("openai.max_tokens", 300)
+	if err := reviewCmd.Flags().Set("max_tokens", "4000"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := check(); err != nil {
+		t.Fatal(err)
+	}
+	if n := viper.GetInt("openai.max_tokens"); n != 1000 {
+		t.Errorf("openai.max_tokens = %d, want the 1000 of the policy", n)
+	}
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/appleboy/CodeGPT/privacy"
+
+	"github.com/spf13/viper"
+)
+
+// defaultTransport is the default HTTP transport without the privacy policy.
+var defaultTransport = http.DefaultTransport
+
+// privacyPolicy returns the policy of privacy.allowed_hosts when privacy.local_only is
+// set, nil otherwise. An invalid host is an error, so the requests are never sent.
+func privacyPolicy() (*privacy.Policy, error) {
+	if !viper.GetBool("privacy.local_only") {
+		return nil, nil
+	}
+	return privacy.New(viper.GetStringSlice("privacy.allowed_hosts"))
+}
+
+// setupPrivacy refuses the requests of the default HTTP transport to the hosts outside
+// of the privacy policy, like the review comments, the notifications, the Jira issues
+// and the traces. The provider client checks its own transport.
+// The transport of the previous policy is replaced, when the server reloads the config.
+func setupPrivacy() error {
+	policy, err := privacyPolicy()
+	if err != nil {
+		return err
+	}
+	if policy == nil {
+		http.DefaultTransport = defaultTransport
+		return nil
+	}
+	origin := defaultTransport
+	if tr, ok := origin.(*http.Transport); ok {
+		// the dialed addresses are checked, a name may resolve to others after the check
+		tr = tr.Clone()
+		tr.DialContext = policy.Dialer(&net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second})
+		origin = tr
+	}
+	http.DefaultTransport = &privacy.Transport{Origin: origin, Policy: policy}
+	return nil
+}
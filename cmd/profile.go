@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/logger"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// profileEnv is the environment variable that selects the config profile.
+const profileEnv = "CODEGPT_PROFILE"
+
+// profileName returns the config profile selected with --config_profile or CODEGPT_PROFILE.
+func profileName() string {
+	if profile != "" {
+		return profile
+	}
+	return os.Getenv(profileEnv)
+}
+
+// profileKey returns the config key of the active profile, the key itself without profile.
+func profileKey(key string) string {
+	if name := profileName(); name != "" {
+		return "profiles." + name + "." + key
+	}
+	return key
+}
+
+// profileNames returns the names of the profiles in the config file.
+func profileNames() []string {
+	var names []string
+	for name := range viper.GetStringMap("profiles") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyProfile merges the settings of the active profile into the config,
+// the flags and the environment variables still take precedence.
+func applyProfile(cmd *cobra.Command) error {
+	name := profileName()
+	// the config command writes the settings to the profile itself
+	if name == "" || cmd == configCmd {
+		return nil
+	}
+
+	if !viper.IsSet("profiles." + name) {
+		return fmt.Errorf("profile %s not found in %s, available profiles: %s",
+			name, viper.ConfigFileUsed(), strings.Join(profileNames(), ", "))
+	}
+
+	logger.Debug("use the " + name + " profile")
+	return viper.MergeConfigMap(viper.GetStringMap("profiles." + name))
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/util"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	promptDir   string
+	promptForce bool
+)
+
+func init() {
+	promptCmd.Flags().StringVar(&promptDir, "dir", "", "directory of the exported templates, default is the templates folder of the config folder")
+	promptCmd.Flags().BoolVar(&promptForce, "force", false, "overwrite the existing templates")
+}
+
+// templateDirs returns the directories of the custom prompt templates: the global
+// one in the config folder and the .codegpt/templates folder of the repository.
+func templateDirs() []string {
+	var dirs []string
+	if configFolder != "" {
+		dirs = append(dirs, filepath.Join(configFolder, "templates"))
+	}
+	if root, err := git.New().TopLevel(); err == nil {
+		dirs = append(dirs, filepath.Join(root, ".codegpt", "templates"))
+	}
+	return dirs
+}
+
+// loadTemplateOverrides overrides the embedded prompts with the custom templates,
+// the repository ones win over the global ones.
+func loadTemplateOverrides() error {
+	for _, dir := range templateDirs() {
+		names, err := util.LoadTemplatesFromDir(dir)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			logger.Debug("use the custom template " + filepath.Join(dir, name))
+		}
+	}
+	return nil
+}
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt",
+	Short: "Export the default prompt templates to customize them",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if args[0] != "export" {
+			return errors.New("only support export command")
+		}
+
+		dir := promptDir
+		if dir == "" {
+			dir = templateDirs()[0]
+		}
+
+		written, err := prompt.Export(dir, promptForce)
+		if err != nil {
+			return err
+		}
+		for _, f := range written {
+			logger.Info("Export the template " + f)
+		}
+		if len(written) == 0 {
+			logger.Warn("The templates already exist in " + dir + ", use --force to overwrite them")
+		}
+		return nil
+	},
+}
+
+// promptVars returns the variables available to every prompt template, like the
+// branch name, the ticket ID with its Jira issue and the changed files.
+func promptVars(g *git.Command, files []string) util.Data {
+	vars := settingVars(files)
+	branch := g.BranchName()
+	vars["branch_name"] = branch
+	vars["ticket_id"] = git.TicketID(branch)
+	vars["author_name"] = g.ConfigValue("user.name")
+	vars["author_email"] = g.ConfigValue("user.email")
+	if root, err := g.TopLevel(); err == nil {
+		vars["repo_name"] = filepath.Base(root)
+	}
+	for k, v := range ticketVars(git.TicketID(branch)) {
+		vars[k] = v
+	}
+	return vars
+}
+
+// settingVars returns the prompt variables of the changed files and of the settings,
+// without the git metadata, like for a diff sent to the server from another repository.
+func settingVars(files []string) util.Data {
+	vars := util.Data{
+		"changed_files":   files,
+		"output_language": prompt.GetLanguage(viper.GetString("output.lang")),
+	}
+	if tone := viper.GetString("prompt.tone"); tone != "" {
+		vars["tone"] = tone
+	}
+	if body := viper.GetString("prompt.body"); body != "" {
+		vars["body"] = body
+	}
+	if n := viper.GetInt("prompt.max_subject_length"); n > 0 {
+		vars["max_subject_length"] = n
+	}
+	if tense := viper.GetString("prompt.tense"); tense != "" {
+		vars["tense"] = tense
+	}
+	if viper.GetBool("prompt.emoji") {
+		vars["emoji"] = true
+	}
+	return vars
+}
+
+// withVars returns a copy of the prompt variables with the given data added.
+func withVars(vars, data util.Data) util.Data {
+	out := make(util.Data, len(vars)+len(data))
+	for k, v := range vars {
+		out[k] = v
+	}
+	for k, v := range data {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/openai"
+	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/util"
+
+	"github.com/fatih/color"
+)
+
+// refineMessage asks for feedback on the commit message and revises it until the answer
+// is empty. The feedback and the revisions are kept in one conversation with the model,
+// so every revision builds on the previous ones instead of starting over.
+func refineMessage(
+	ctx context.Context,
+	client *openai.Client,
+	vars util.Data,
+	summary any,
+	message string,
+	in io.Reader,
+) (string, error) {
+	seed, err := util.GetTemplateByString(
+		prompt.RefineCommitTemplate,
+		withVars(vars, util.Data{
+			"summary_points": summary,
+		}),
+	)
+	if err != nil {
+		return "", err
+	}
+	session := client.NewSession()
+	session.Add(seed, message)
+
+	reader := bufio.NewReader(in)
+	for {
+		fmt.Fprint(color.Output, "Press enter to use the message, or type feedback to refine it: ")
+		answer, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		feedback := strings.TrimSpace(answer)
+		if feedback == "" {
+			if err == io.EOF {
+				fmt.Fprintln(color.Output)
+			}
+			return message, nil
+		}
+
+		out, err := util.GetTemplateByString(
+			prompt.RefineFeedbackTemplate,
+			withVars(vars, util.Data{
+				"feedback": feedback,
+			}),
+		)
+		if err != nil {
+			return "", err
+		}
+		logger.Info("We are trying to refine the commit message")
+		resp, err := session.Send(ctx, out)
+		if err != nil {
+			return "", err
+		}
+		printUsage(ctx, resp.Usage)
+		recordAudit(ctx, out, resp)
+
+		revised := strings.TrimSpace(newFormatter().Format(html.UnescapeString(resp.Content)))
+		if revised != "" {
+			message = revised
+		}
+		color.Yellow("================Commit Summary====================")
+		color.Yellow("\n" + message + "\n\n")
+		color.Yellow("==================================================")
+	}
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/release"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	notesFrom         string
+	notesNoHighlights bool
+	notesPublish      bool
+	notesDraft        bool
+)
+
+func init() {
+	releaseNotesCmd.Flags().StringVar(&notesFrom, "from", "", "tag of the previous version, default is the tag before the version")
+	releaseNotesCmd.Flags().BoolVar(&notesNoHighlights, "no_highlights", false, "don't ask the model for the highlights paragraph")
+	releaseNotesCmd.Flags().BoolVar(&notesPublish, "publish", false, "publish the notes as a GitHub release of the version")
+	releaseNotesCmd.Flags().BoolVar(&notesDraft, "draft", false, "publish the GitHub release as a draft")
+	releaseNotesCmd.Flags().BoolVar(&notifyFlag, "notify", false, "post the notes to the Slack and Teams webhooks")
+	releaseNotesCmd.Flags().StringVar(&githubRepo, "github_repo", "", "GitHub repository in the owner/name format, default is $GITHUB_REPOSITORY")
+	releaseNotesCmd.Flags().StringVar(&commitModel, "model", "gpt-3.5-turbo", "select openai model")
+	releaseNotesCmd.Flags().StringVar(&commitLang, "lang", "en", "summarizing language uses English by default")
+	releaseNotesCmd.Flags().IntVar(&maxTokens, "max_tokens", 300, "the maximum number of tokens to generate in the chat completion.")
+	releaseNotesCmd.Flags().DurationVarP(&timeout, "timeout", "t", 0, "timeout of every request to the provider, default is openai.timeout")
+	releaseNotesCmd.Flags().DurationVar(&connectTimeout, "connect_timeout", 0, "timeout of the connection to the provider, default is openai.connect_timeout")
+}
+
+var releaseNotesCmd = &cobra.Command{
+	Use:   "release-notes <version>",
+	Short: "Write the markdown release notes of a version from its commits",
+	Long: `Write the markdown release notes of a version, with the commits since the previous tag
+grouped by type, their links, the contributors and a highlights paragraph written by the model.
+The commits up to the tag of the version are used, or up to HEAD if the version isn't tagged yet.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := check(); err != nil {
+			return err
+		}
+
+		g := git.New()
+		version := args[0]
+		to := "HEAD"
+		date := time.Now().Format(time.DateOnly)
+		if g.TagExists(version) {
+			to = version
+			d, err := g.CommitDate(version)
+			if err != nil {
+				return err
+			}
+			date = d
+		}
+
+		from := notesFrom
+		if from == "" {
+			rev := "HEAD"
+			if to != "HEAD" {
+				rev = to + "^"
+			}
+			// the first commit has no parent and no previous tag
+			from, _ = g.LatestTag(rev)
+		}
+		revisions := to
+		if from != "" {
+			revisions = from + ".." + to
+		}
+
+		commits, err := releaseCommits(g, revisions)
+		if err != nil {
+			return err
+		}
+		if len(commits) == 0 {
+			return fmt.Errorf("there are no commits in %s", revisions)
+		}
+
+		notes := release.Notes{
+			Version:  version,
+			Previous: from,
+			Date:     date,
+			Commits:  commits,
+			RepoURL:  release.RepoURL(g.RemoteURL("origin")),
+		}
+		if notes.RepoURL == "" && currentGitHubRepo() != "" {
+			notes.RepoURL = "https://github.com/" + currentGitHubRepo()
+		}
+		if !notesNoHighlights {
+			notes.Highlights, err = releaseSummary(cmd.Context(), g, version, commits)
+			if err != nil {
+				return err
+			}
+		}
+		result.Message = notes.Render()
+
+		if notesPublish {
+			gh, err := newGitHub()
+			if err != nil {
+				return err
+			}
+			logger.Info("Publish the release " + version + " to " + currentGitHubRepo())
+			url, err := gh.CreateRelease(cmd.Context(), version, result.Message, notesDraft)
+			if err != nil {
+				return err
+			}
+			logger.Info("Published the release: " + url)
+		}
+
+		if notifyFlag {
+			title := strings.TrimSpace(repoName(g) + " " + version)
+			if err := sendNotification(cmd.Context(), title, result.Message); err != nil {
+				return err
+			}
+		}
+
+		if !isMachineOutput() {
+			fmt.Fprint(os.Stdout, result.Message)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/openai"
+	"github.com/appleboy/CodeGPT/privacy"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// configPollInterval is the interval of the checks of the config files by the server.
+const configPollInterval = 2 * time.Second
+
+// configFiles returns the config files read by the commands: the global one and the
+// repository one, if any.
+func configFiles() []string {
+	files := []string{viper.ConfigFileUsed()}
+	if root, err := git.New().TopLevel(); err == nil {
+		if name := findConfigFile(root); name != "" {
+			files = append(files, name)
+		}
+	}
+	return files
+}
+
+// fileStamp is the modification time and the size of a file, zero when it doesn't exist.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func stampOf(name string) fileStamp {
+	info, err := os.Stat(name)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+// watchConfig calls fn when one of the config files is written, created or removed,
+// until the context is done. The files are polled, so the editors replacing the file
+// on save are caught too.
+func watchConfig(ctx context.Context, interval time.Duration, fn func()) {
+	files := configFiles()
+	stamps := make([]fileStamp, len(files))
+	for i, name := range files {
+		stamps[i] = stampOf(name)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		changed := false
+		for i, name := range files {
+			if s := stampOf(name); s != stamps[i] {
+				stamps[i] = s
+				changed = true
+			}
+		}
+		if changed {
+			fn()
+		}
+	}
+}
+
+// loadConfig replaces the config with the content of the global config file, then merges
+// the profile, the repository config and the managed policy, and applies the privacy
+// policy, like before running a command.
+func loadConfig(cmd *cobra.Command, data []byte) error {
+	if err := viper.ReadConfig(bytes.NewReader(data)); err != nil {
+		return err
+	}
+	if err := applyProfile(cmd); err != nil {
+		return err
+	}
+	if err := applyRepoConfig(cmd); err != nil {
+		return err
+	}
+	if err := applyPolicy(); err != nil {
+		return err
+	}
+	return setupPrivacy()
+}
+
+// checkConfig validates the content of the global config file in a fresh viper instance,
+// before it replaces the config of the server.
+func checkConfig(data []byte) error {
+	v := viper.New()
+	v.SetConfigFile(viper.ConfigFileUsed())
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		return err
+	}
+	if name := profileName(); name != "" && !v.IsSet("profiles."+name) {
+		return fmt.Errorf("profile %s not found in %s", name, viper.ConfigFileUsed())
+	}
+	if v.GetBool("privacy.local_only") {
+		if _, err := privacy.New(v.GetStringSlice("privacy.allowed_hosts")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reload reads the config files again and replaces the client of the server with one
+// of the new provider and model settings, and the prompt templates. The flags still
+// take precedence. The new config is validated first, the server keeps the previous
+// one when it's invalid.
+func (s *server) reload(ctx context.Context, cmd *cobra.Command) {
+	data, err := os.ReadFile(viper.ConfigFileUsed())
+	if err == nil {
+		err = checkConfig(data)
+	}
+	if err != nil {
+		logger.Warn("can't reload the config: " + err.Error())
+		return
+	}
+
+	// the requests in flight finish with the old settings
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err = loadConfig(cmd, data)
+	var client *openai.Client
+	if err == nil {
+		// the budget is checked by every request
+		client, err = openClient()
+	}
+	if err == nil {
+		err = validateModel(ctx, client)
+	}
+	if err != nil {
+		logger.Warn("can't reload the config, keep the " + s.model + " model: " + err.Error())
+		// back to the previous config, the repository config is read again
+		if err := loadConfig(cmd, s.config); err != nil {
+			logger.Warn("can't restore the previous config: " + err.Error())
+		}
+		return
+	}
+	if err := loadTemplateOverrides(); err != nil {
+		logger.Warn("can't reload the prompt templates: " + err.Error())
+	}
+
+	s.conf.Lock()
+	s.client = client
+	s.provider = result.Provider
+	s.model = result.Model
+	s.token = secret("serve.token")
+	s.conf.Unlock()
+	s.config = data
+	result = &Result{Provider: s.provider, Model: s.model}
+	logger.Info("Reload the config, using " + s.model + " model")
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+func TestServerReload(t *testing.T) {
+	const initial = "openai:\n  provider: mock\n  model: gpt-4o\n" +
+		"profiles:\n  work:\n    openai:\n      model: gpt-4o\n"
+
+	tests := []struct {
+		name   string
+		config string
+		model  string
+	}{
+		{"invalid yaml", "openai: [mock\n", "gpt-4o"},
+		{"missing profile", "openai:\n  provider: mock\n  model: gpt-4o-mini\n", "gpt-4o"},
+		{
+			"invalid allowed hosts",
+			"openai:\n  provider: mock\n  model: gpt-4o-mini\n" +
+				"profiles:\n  work:\n    openai:\n      model: gpt-4o-mini\n" +
+				"privacy:\n  local_only: true\n  allowed_hosts:\n    - http://localhost:11434\n",
+			"gpt-4o",
+		},
+		{
+			// the client can't be created without the API key, the previous config is restored
+			"invalid client",
+			"openai:\n  provider: openai\n  model: gpt-4o-mini\n" +
+				"profiles:\n  work:\n    openai:\n      model: gpt-4o-mini\n",
+			"gpt-4o",
+		},
+		{
+			"valid",
+			"openai:\n  provider: mock\n  model: gpt-4o-mini\n" +
+				"profiles:\n  work:\n    openai:\n      model: gpt-4o-mini\n",
+			"gpt-4o-mini",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitRepo(t)
+			t.Setenv("OPENAI_API_KEY", "")
+			file := filepath.Join(t.TempDir(), "config.yaml")
+			if err := os.WriteFile(file, []byte(initial), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			viper.SetConfigFile(file)
+			profile = "work"
+			t.Cleanup(func() {
+				profile = ""
+				_ = viper.ReadConfig(bytes.NewReader(nil))
+				_ = setupPrivacy()
+				result = &Result{}
+			})
+			if err := loadConfig(serveCmd, []byte(initial)); err != nil {
+				t.Fatal(err)
+			}
+			client, err := openClient()
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := &server{client: client, provider: result.Provider, model: result.Model, config: []byte(initial)}
+
+			if err := os.WriteFile(file, []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			s.reload(context.Background(), serveCmd)
+
+			if s.model != tt.model {
+				t.Errorf("model = %s, want %s", s.model, tt.model)
+			}
+			if swapped := s.client != client; swapped != (tt.model != "gpt-4o") {
+				t.Errorf("client swapped = %v, want %v", swapped, !swapped)
+			}
+			if got := viper.GetString(profileKey("openai.model")); got != tt.model {
+				t.Errorf("config model = %s, want %s", got, tt.model)
+			}
+			if tt.model == "gpt-4o" && viper.GetBool("privacy.local_only") {
+				t.Error("privacy.local_only is set by the rejected config")
+			}
+		})
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	gitRepo(t)
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte("openai:\n  model: gpt-4o\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	viper.SetConfigFile(file)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan struct{}, 1)
+	go watchConfig(ctx, 10*time.Millisecond, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case <-changed:
+		t.Fatal("watchConfig() called fn without a change")
+	default:
+	}
+
+	if err := os.WriteFile(file, []byte("openai:\n  model: gpt-4o-mini\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("watchConfig() didn't call fn after the change")
+	}
+}
@@ -0,0 +1,203 @@
+package cmd
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/notify"
+	"github.com/appleboy/CodeGPT/redact"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// repoConfigKeys lists the keys a repository config can override. The provider,
+// base URL, API key, proxies and headers are left out so that a cloned repository
+// can't send your code or credentials somewhere else.
+var repoConfigKeys = []string{
+	"openai.model",
+	"openai.max_tokens",
+	"openai.temperature",
+	"openai.top_p",
+	"openai.frequency_penalty",
+	"openai.presence_penalty",
+	"openai.seed",
+	"output.lang",
+	"prompt.system",
+	"prompt.examples",
+	"prompt.similar",
+	"prompt.structured",
+	"prompt.tone",
+	"prompt.tense",
+	"prompt.emoji",
+	"prompt.body",
+	"prompt.breaking_change",
+	"prompt.symbols",
+	"prompt.dependencies",
+	"prompt.max_diff_tokens",
+	"prompt.priority_rules",
+	"prompt.compression",
+	"prompt.max_subject_length",
+	"format.strip_period",
+	"format.strip_scope",
+	"format.case",
+	"format.body_width",
+	"style.learn",
+	"style.commits",
+	"git.diff_unified",
+	"git.exclude_list",
+	"git.max_file_size",
+	"git.template_file",
+	"git.template_string",
+	"git.scope_map",
+	"git.workspaces",
+	"lint.types",
+	"lint.scopes",
+	"lint.header_max_length",
+	"redact.enable",
+	"redact.patterns",
+	"redact.entropy",
+	// only the Slack and Teams webhooks, checked by applyRepoConfig
+	"notify.slack_webhook",
+	"notify.teams_webhook",
+}
+
+// applyRepoConfig merges the .codegpt.yaml file of the repository root into the config,
+// or its TOML or JSON version, the flags and the environment variables still take precedence.
+func applyRepoConfig(cmd *cobra.Command) error {
+	root, err := git.New().TopLevel()
+	if err != nil {
+		return nil
+	}
+	file := findConfigFile(root)
+	if file == "" {
+		return nil
+	}
+
+	// the repository root may be the home directory with the global config
+	if abs, err := filepath.Abs(viper.ConfigFileUsed()); err == nil && abs == filepath.Clean(file) {
+		return nil
+	}
+
+	repo := viper.New()
+	repo.SetConfigFile(file)
+	if err := repo.ReadInConfig(); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		// the config command can still fix the file
+		if cmd == configCmd {
+			logger.Warn("can't read " + file + ": " + err.Error())
+			return nil
+		}
+		return err
+	}
+
+	settings := map[string]interface{}{}
+	for _, key := range repoConfigKeys {
+		if !repo.IsSet(key) {
+			continue
+		}
+		val := repo.Get(key)
+		// a cloned repository can't send the notifications to any server
+		if strings.HasPrefix(key, "notify.") && !notify.IsWebhookURL(repo.GetString(key)) {
+			logger.Warn("ignore " + key + " in " + file + ", it must be a Slack or Teams webhook URL")
+			continue
+		}
+		// a cloned repository can only make the redaction stricter
+		switch key {
+		case "redact.enable":
+			if !repo.GetBool(key) {
+				logger.Warn("ignore " + key + " in " + file + ", the repository config can't turn off the redaction")
+				continue
+			}
+		case "redact.entropy":
+			if e := repo.GetFloat64(key); e <= 0 || e >= redactEntropy() {
+				logger.Warn("ignore " + key + " in " + file + ", the repository config can only lower the entropy threshold")
+				continue
+			}
+		case "redact.patterns":
+			// the patterns are added to the user ones
+			val = append(viper.GetStringSlice(key), repo.GetStringSlice(key)...)
+		}
+		// the template file is relative to the repository root and stays in it
+		if key == "git.template_file" {
+			f, err := repoFile(root, repo.GetString(key))
+			if err != nil {
+				logger.Warn("ignore " + key + " in " + file + ": " + err.Error())
+				continue
+			}
+			val = f
+		}
+		setNested(settings, key, val)
+	}
+	for _, key := range repo.AllKeys() {
+		if !inRepoConfigKeys(key) {
+			logger.Warn("ignore " + key + " in " + file + ", it can only be set in the global config")
+		}
+	}
+
+	logger.Debug("use the repository config " + file)
+	return viper.MergeConfigMap(settings)
+}
+
+// redactEntropy returns the entropy threshold of the redaction of the current config.
+func redactEntropy() float64 {
+	if e := viper.GetFloat64("redact.entropy"); e > 0 {
+		return e
+	}
+	return redact.DefaultEntropy
+}
+
+// repoFile returns the path of the file relative to the repository root, with the
+// symbolic links resolved, or an error when it's outside of the repository.
+func repoFile(root, name string) (string, error) {
+	if name == "" {
+		return "", nil
+	}
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(root, name)
+	}
+	root, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", err
+	}
+	path, err := filepath.EvalSymlinks(filepath.Clean(name))
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(root, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.New("the file must be in the repository")
+	}
+	return path, nil
+}
+
+// inRepoConfigKeys reports whether the key, or its parent for a map like git.scope_map,
+// can be set in a repository config.
+func inRepoConfigKeys(key string) bool {
+	for _, k := range repoConfigKeys {
+		if key == k || strings.HasPrefix(key, k+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// setNested sets the dotted key like git.exclude_list in the nested settings map.
+func setNested(settings map[string]interface{}, key string, val interface{}) {
+	parts := strings.Split(key, ".")
+	m := settings
+	for _, p := range parts[:len(parts)-1] {
+		child, ok := m[p].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			m[p] = child
+		}
+		m = child
+	}
+	m[parts[len(parts)-1]] = val
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestApplyRepoConfigRedaction(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		enable   bool
+		entropy  float64
+		patterns []string
+	}{
+		{
+			name:     "weaker",
+			config:   "redact:\n  enable: false\n  entropy: 9\n",
+			enable:   true,
+			entropy:  0,
+			patterns: []string{"internal-[a-z]+"},
+		},
+		{
+			name:     "stricter",
+			config:   "redact:\n  entropy: 3.5\n  patterns:\n    - ticket-[0-9]+\n",
+			enable:   true,
+			entropy:  3.5,
+			patterns: []string{"internal-[a-z]+", "ticket-[0-9]+"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := gitRepo(t)
+			if err := os.WriteFile(filepath.Join(dir, ".codegpt.yaml"), []byte(tt.config), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			// the patterns of the global config
+			if err := viper.MergeConfigMap(map[string]interface{}{
+				"redact": map[string]interface{}{"patterns": []string{"internal-[a-z]+"}},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() {
+				_ = viper.MergeConfigMap(map[string]interface{}{
+					"redact": map[string]interface{}{"entropy": nil, "patterns": nil},
+				})
+			})
+
+			if err := applyRepoConfig(reviewCmd); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := viper.GetBool("redact.enable"); got != tt.enable {
+				t.Errorf("redact.enable = %v, want %v", got, tt.enable)
+			}
+			if got := viper.GetFloat64("redact.entropy"); got != tt.entropy {
+				t.Errorf("redact.entropy = %v, want %v", got, tt.entropy)
+			}
+			if got := viper.GetStringSlice("redact.patterns"); !slices.Equal(got, tt.patterns) {
+				t.Errorf("redact.patterns = %q, want %q", got, tt.patterns)
+			}
+		})
+	}
+}
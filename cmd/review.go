@@ -1,12 +1,20 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
 	"strconv"
 	"strings"
 
 	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
 	"github.com/appleboy/CodeGPT/openai"
+	"github.com/appleboy/CodeGPT/platform"
 	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/review"
 	"github.com/appleboy/CodeGPT/util"
 
 	"github.com/fatih/color"
@@ -18,106 +26,288 @@ import (
 // The total length of input tokens and generated tokens is limited by the model's context length.
 var maxTokens int
 
+var (
+	reviewFailOn  string
+	reviewProfile string
+
+	githubPR    int
+	githubRepo  string
+	giteaPR     int
+	bitbucketPR int
+	dryRun      bool
+	noPost      bool
+
+	diffFrom string
+	diffTo   string
+)
+
 func init() {
 	reviewCmd.Flags().IntVar(&diffUnified, "diff_unified", 3, "generate diffs with <n> lines of context, default is 3")
 	reviewCmd.Flags().IntVar(&maxTokens, "max_tokens", 300, "the maximum number of tokens to generate in the chat completion.")
+	reviewCmd.Flags().DurationVarP(&timeout, "timeout", "t", 0, "timeout of every request to the provider, default is openai.timeout")
+	reviewCmd.Flags().DurationVar(&connectTimeout, "connect_timeout", 0, "timeout of the connection to the provider, default is openai.connect_timeout")
 	reviewCmd.Flags().StringVar(&commitModel, "model", "gpt-3.5-turbo", "select openai model")
 	reviewCmd.Flags().StringVar(&commitLang, "lang", "en", "summarizing language uses English by default")
 	reviewCmd.Flags().StringSliceVar(&excludeList, "exclude_list", []string{}, "exclude file from git diff command")
 	reviewCmd.Flags().BoolVar(&commitAmend, "amend", false, "replace the tip of the current branch by creating a new commit.")
+	reviewCmd.Flags().BoolVar(&showRedactions, "show_redactions", false, "show the secrets redacted from the git diff")
+	reviewCmd.Flags().StringVar(&diffFrom, "from", "", "review the changes since the merge base with this revision, like origin/main")
+	reviewCmd.Flags().StringVar(&diffTo, "to", "HEAD", "review the changes up to this revision, used with --from")
+	reviewCmd.Flags().IntVar(&githubPR, "github_pr", 0, "post the findings as a review of this GitHub pull request number")
+	reviewCmd.Flags().StringVar(&githubRepo, "github_repo", "", "GitHub repository in the owner/name format, default is $GITHUB_REPOSITORY")
+	reviewCmd.Flags().IntVar(&giteaPR, "gitea_pr", 0, "post the findings as a review of this Gitea pull request number")
+	reviewCmd.Flags().IntVar(&bitbucketPR, "bitbucket_pr", 0, "post the findings as comments of this Bitbucket pull request number")
+	reviewCmd.Flags().BoolVar(&dryRun, "dry_run", false, "print the request that would be sent to the provider without sending it")
+	reviewCmd.Flags().BoolVar(&noPost, "no_post", false, "print the pull request review instead of posting it")
+	reviewCmd.Flags().BoolVar(&notifyFlag, "notify", false, "post the summary of the review to the Slack and Teams webhooks")
+	reviewCmd.MarkFlagsMutuallyExclusive("github_pr", "gitea_pr", "bitbucket_pr")
+	reviewCmd.Flags().StringVar(&reviewProfile, "profile", review.ProfileGeneral, "review profile, general or security")
+	reviewCmd.Flags().StringVar(&reviewFailOn, "fail_on", "", "exit with an error if a finding has this severity or above (LOW, MEDIUM, HIGH, CRITICAL)")
 }
 
 var reviewCmd = &cobra.Command{
-	Use:   "review",
+	Use:   "review [<commit>|<from>..<to>|<from>...<to>]",
 	Short: "Auto review code changes",
+	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if err := check(); err != nil {
 			return err
 		}
 
+		if reviewFailOn != "" && !review.ValidSeverity(reviewFailOn) {
+			return invalid(errors.New("fail_on must be one of LOW, MEDIUM, HIGH or CRITICAL"))
+		}
+
+		// review the merge base diff with --from or the given revision range
+		from, to, mergeBase := diffFrom, diffTo, true
+		if len(args) > 0 {
+			from, to, mergeBase = git.ParseRange(args[0])
+		}
+
 		g := git.New(
 			git.WithDiffUnified(viper.GetInt("git.diff_unified")),
 			git.WithExcludeList(viper.GetStringSlice("git.exclude_list")),
 			git.WithEnableAmend(commitAmend),
+			git.WithMaxFileSize(viper.GetInt64("git.max_file_size")),
+			git.WithRange(from, to, mergeBase),
 		)
 		diff, err := g.DiffFiles()
 		if err != nil {
 			return err
 		}
 
-		color.Green("Code review your changes using " + viper.GetString("openai.model") + " model")
-		client, err := openai.New(
-			openai.WithToken(viper.GetString("openai.api_key")),
-			openai.WithModel(viper.GetString("openai.model")),
-			openai.WithOrgID(viper.GetString("openai.org_id")),
-			openai.WithProxyURL(viper.GetString("openai.proxy")),
-			openai.WithSocksURL(viper.GetString("openai.socks")),
-			openai.WithBaseURL(viper.GetString("openai.base_url")),
-			openai.WithTimeout(viper.GetDuration("openai.timeout")),
-			openai.WithMaxTokens(viper.GetInt("openai.max_tokens")),
-			openai.WithTemperature(float32(viper.GetFloat64("openai.temperature"))),
-			openai.WithProvider(viper.GetString("openai.provider")),
-			openai.WithModelName(viper.GetString("openai.model_name")),
-			openai.WithSkipVerify(viper.GetBool("openai.skip_verify")),
-			openai.WithHeaders(viper.GetStringSlice("openai.headers")),
-			openai.WithApiVersion(viper.GetString("openai.api_version")),
-		)
+		diff, err = redactDiff(diff)
+		if err != nil {
+			return err
+		}
+		diff, err = fitDiff(cmd.Context(), diff)
 		if err != nil {
 			return err
 		}
 
-		out, err := util.GetTemplateByString(
-			prompt.CodeReviewTemplate,
-			util.Data{
-				"file_diffs": diff,
-			},
-		)
+		files, err := g.DiffNames()
 		if err != nil {
 			return err
 		}
 
-		// Get summarize comment from diff datas
-		color.Cyan("We are trying to review code changes")
-		resp, err := client.Completion(cmd.Context(), out)
+		logger.Info("Code review your changes using " + viper.GetString("openai.model") + " model")
+		client, err := newClient(cmd.Context())
 		if err != nil {
 			return err
 		}
-		summarizeMessage := resp.Content
-		color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
-			", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
-			", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
-		)
 
-		if prompt.GetLanguage(viper.GetString("output.lang")) != prompt.DefaultLanguage {
-			out, err = util.GetTemplateByString(
-				prompt.TranslationTemplate,
-				util.Data{
-					"output_language": prompt.GetLanguage(viper.GetString("output.lang")),
-					"output_message":  summarizeMessage,
-				},
-			)
-			if err != nil {
+		findings, err := reviewDiff(cmd.Context(), client, promptVars(g, files), diff, reviewProfile)
+		if err != nil || dryRun {
+			return err
+		}
+
+		result.Findings = findings
+
+		// Output code review findings
+		switch outputFormat {
+		case outputJSON:
+		case review.FormatSARIF, review.FormatGitHubActions:
+			if err := review.Render(os.Stdout, outputFormat, findings); err != nil {
+				return err
+			}
+		default:
+			format := outputFormat
+			if format == outputText {
+				format = review.FormatTable
+			}
+			color.Yellow("================Review Summary====================")
+			if err := review.Render(os.Stdout, format, findings); err != nil {
 				return err
 			}
+			color.Yellow("==================================================")
+		}
 
-			// translate a git commit message
-			color.Cyan("We are trying to translate code review to " + prompt.GetLanguage(viper.GetString("output.lang")) + " language")
-			resp, err := client.Completion(cmd.Context(), out)
-			if err != nil {
+		if githubPR > 0 || giteaPR > 0 || bitbucketPR > 0 {
+			if err := postReview(cmd.Context(), findings); err != nil {
 				return err
 			}
-			color.Magenta("PromptTokens: " + strconv.Itoa(resp.Usage.PromptTokens) +
-				", CompletionTokens: " + strconv.Itoa(resp.Usage.CompletionTokens) +
-				", TotalTokens: " + strconv.Itoa(resp.Usage.TotalTokens),
-			)
-			summarizeMessage = resp.Content
 		}
 
-		// Output core review summary
-		color.Yellow("================Review Summary====================")
-		color.Yellow("\n" + strings.TrimSpace(summarizeMessage) + "\n\n")
-		color.Yellow("==================================================")
+		if notifyFlag {
+			title := strings.TrimSpace("Code review of " + repoName(g))
+			if from != "" {
+				title += " " + from + ".." + to
+			}
+			if err := sendNotification(cmd.Context(), title, review.Summary(findings)); err != nil {
+				return err
+			}
+		}
+
+		if reviewFailOn != "" {
+			if count := review.CountAtLeast(findings, reviewFailOn); count > 0 {
+				return invalid(fmt.Errorf("found %d finding(s) with severity %s or above", count, strings.ToUpper(reviewFailOn)))
+			}
+		}
 
 		return nil
 	},
 }
+
+// reviewDiff asks the model to review the diff with the profile, the findings are
+// mapped to the lines of the diff. With --dry_run, the request is printed instead
+// and there are no findings.
+func reviewDiff(ctx context.Context, client *openai.Client, vars util.Data, diff, profile string) ([]review.Finding, error) {
+	reviewTemplate := prompt.CodeReviewTemplate
+	switch profile {
+	case review.ProfileGeneral:
+	case review.ProfileSecurity:
+		reviewTemplate = prompt.CodeReviewSecurityTemplate
+	default:
+		return nil, errors.New("profile must be general or security")
+	}
+
+	out, err := util.GetTemplateByString(
+		reviewTemplate,
+		withVars(vars, util.Data{
+			"file_diffs": diff,
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		printDryRun(client, out)
+		return nil, nil
+	}
+
+	// Get review findings from diff datas
+	logger.Info("We are trying to review code changes with the " + profile + " profile")
+	resp, err := completion(ctx, client, out)
+	if err != nil {
+		return nil, err
+	}
+	printUsage(ctx, resp.Usage)
+
+	findings, err := review.ParseFindings(resp.Content)
+	if err != nil {
+		return nil, err
+	}
+	return review.MapToDiff(findings, diff), nil
+}
+
+// postReview posts the findings as a review with inline comments to the pull request
+// of the --github_pr, --gitea_pr or --bitbucket_pr flag.
+func postReview(ctx context.Context, findings []review.Finding) error {
+	name, repo, number := "GitHub", currentGitHubRepo(), githubPR
+	newPlatform := func() (platform.Platform, error) { return newGitHub() }
+	switch {
+	case giteaPR > 0:
+		name, repo, number = "Gitea", currentGiteaRepo(), giteaPR
+		newPlatform = func() (platform.Platform, error) { return newGitea() }
+	case bitbucketPR > 0:
+		name, repo, number = "Bitbucket", currentBitbucketRepo(), bitbucketPR
+		newPlatform = func() (platform.Platform, error) { return newBitbucket() }
+	}
+	r := platform.NewReview(findings)
+
+	if noPost {
+		logger.Info("The following review would be posted to " + name + " pull request #" + strconv.Itoa(number))
+		out, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(color.Output, string(out))
+		return nil
+	}
+
+	p, err := newPlatform()
+	if err != nil {
+		return err
+	}
+	// the local diff may have changes missing from the pull request
+	if d, ok := p.(platform.Differ); ok {
+		diff, err := d.PullRequestDiff(ctx, number)
+		if err != nil {
+			return err
+		}
+		r = platform.Anchor(r, diff)
+	}
+
+	logger.Info("Post the review to " + name + " " + repo + " pull request #" + strconv.Itoa(number))
+	return p.PostReview(ctx, number, r)
+}
+
+// currentGitHubRepo returns the GitHub repository of the --github_repo flag,
+// the github.repo config or the GITHUB_REPOSITORY environment variable.
+func currentGitHubRepo() string {
+	repo := githubRepo
+	if repo == "" {
+		repo = viper.GetString("github.repo")
+	}
+	if repo == "" {
+		repo = os.Getenv("GITHUB_REPOSITORY")
+	}
+	return repo
+}
+
+// newGitHub creates the GitHub client of the github config and the current repository.
+func newGitHub() (*platform.GitHub, error) {
+	return platform.NewGitHub(
+		platform.WithToken(secret("github.token")),
+		platform.WithBaseURL(viper.GetString("github.base_url")),
+		platform.WithRepo(currentGitHubRepo()),
+	)
+}
+
+// currentGiteaRepo returns the Gitea repository of the gitea.repo config, or the
+// GITHUB_REPOSITORY environment variable set by Gitea Actions.
+func currentGiteaRepo() string {
+	if repo := viper.GetString("gitea.repo"); repo != "" {
+		return repo
+	}
+	return os.Getenv("GITHUB_REPOSITORY")
+}
+
+// newGitea creates the Gitea client of the gitea config.
+func newGitea() (*platform.Gitea, error) {
+	return platform.NewGitea(
+		platform.WithToken(secret("gitea.token")),
+		platform.WithBaseURL(viper.GetString("gitea.base_url")),
+		platform.WithRepo(currentGiteaRepo()),
+	)
+}
+
+// currentBitbucketRepo returns the Bitbucket repository of the bitbucket.repo config, or
+// the BITBUCKET_REPO_FULL_NAME environment variable set by Bitbucket Pipelines.
+func currentBitbucketRepo() string {
+	if repo := viper.GetString("bitbucket.repo"); repo != "" {
+		return repo
+	}
+	return os.Getenv("BITBUCKET_REPO_FULL_NAME")
+}
+
+// newBitbucket creates the Bitbucket client of the bitbucket config.
+func newBitbucket() (*platform.Bitbucket, error) {
+	return platform.NewBitbucket(
+		platform.WithToken(secret("bitbucket.token")),
+		platform.WithUsername(viper.GetString("bitbucket.username")),
+		platform.WithBaseURL(viper.GetString("bitbucket.base_url")),
+		platform.WithRepo(currentBitbucketRepo()),
+	)
+}
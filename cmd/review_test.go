@@ -0,0 +1,16 @@
+package cmd
+
+import "testing"
+
+func TestReviewReplay(t *testing.T) {
+	if _, err := replay(t, "review.json", "review"); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Findings) != 1 {
+		t.Fatalf("got %d findings, want 1: %+v", len(result.Findings), result.Findings)
+	}
+	f := result.Findings[0]
+	if f.File != "main.go" || f.StartLine != 6 || f.Severity != "HIGH" {
+		t.Errorf("unexpected finding: %+v", f)
+	}
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/format"
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var rewordForce bool
+
+func init() {
+	rewordCmd.Flags().BoolVar(&preview, "preview", false, "only print the new commit message, don't rewrite the commit")
+	rewordCmd.Flags().BoolVar(&rewordForce, "force", false, "reword the commit even if it's already pushed to a remote branch")
+	rewordCmd.Flags().IntVar(&diffUnified, "diff_unified", 3, "generate diffs with <n> lines of context, default is 3")
+	rewordCmd.Flags().StringSliceVar(&excludeList, "exclude_list", []string{}, "exclude file from git diff command")
+	rewordCmd.Flags().StringVar(&commitModel, "model", "gpt-3.5-turbo", "select openai model")
+	rewordCmd.Flags().StringVar(&commitLang, "lang", "en", "summarizing language uses English by default")
+	rewordCmd.Flags().DurationVarP(&timeout, "timeout", "t", 0, "timeout of every request to the provider, default is openai.timeout")
+	rewordCmd.Flags().DurationVar(&connectTimeout, "connect_timeout", 0, "timeout of the connection to the provider, default is openai.connect_timeout")
+}
+
+var rewordCmd = &cobra.Command{
+	Use:   "reword <commit>",
+	Short: "Write a better message for a commit of the current branch",
+	Long: `Write a better message for a commit of the current branch from its diff, and replace
+its message: the HEAD commit is amended, an older commit is reworded by an interactive rebase.
+The trailers of the message, like Signed-off-by:, are kept. A commit already pushed to a
+remote branch isn't rewritten without --force, and the branches with merge commits after
+the commit can't be reworded.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := check(); err != nil {
+			return err
+		}
+
+		opts := []git.Option{
+			git.WithDiffUnified(viper.GetInt("git.diff_unified")),
+			git.WithExcludeList(viper.GetStringSlice("git.exclude_list")),
+			git.WithMaxFileSize(viper.GetInt64("git.max_file_size")),
+		}
+		g := git.New(opts...)
+
+		hash, err := g.ResolveCommit(args[0])
+		if err != nil {
+			return err
+		}
+		if err := checkReword(g, hash); err != nil {
+			return err
+		}
+
+		parents, err := g.Parents(hash)
+		if err != nil {
+			return err
+		}
+		if len(parents) > 1 {
+			return errors.New("the commit " + hash[:7] + " is a merge commit, its message can't be generated from a diff")
+		}
+		parent := git.EmptyTree
+		if len(parents) == 1 {
+			parent = parents[0]
+		}
+		g = git.New(append(opts, git.WithRange(parent, hash, false))...)
+		diff, files, err := stagedDiff(g)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(diff) == "" {
+			return errors.New("the commit " + hash[:7] + " has no changes to describe")
+		}
+		diff, err = redactDiff(diff)
+		if err != nil {
+			return err
+		}
+		diff, err = fitDiff(cmd.Context(), diff)
+		if err != nil {
+			return err
+		}
+		old, err := g.CommitMessage(hash)
+		if err != nil {
+			return err
+		}
+
+		client, err := newClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+		logger.Info("We are trying to write a new message for the commit " + hash[:7])
+		message, err := generateMessage(cmd.Context(), client, g, promptVars(g, files), diff, files, messageOptions{})
+		if err != nil {
+			return err
+		}
+		message = withTrailers(strings.TrimSpace(message), format.Trailers(old))
+		result.Message = message
+
+		if !isMachineOutput() {
+			color.Yellow("==================Old Message=====================")
+			color.Yellow("\n" + old + "\n\n")
+			color.Yellow("==================New Message=====================")
+			color.Yellow("\n" + message + "\n\n")
+			color.Yellow("==================================================")
+		}
+		if preview {
+			return nil
+		}
+
+		logger.Info("Reword the commit " + hash[:7])
+		if err := g.Reword(hash, message); err != nil {
+			if len(g.InProgress()) > 0 {
+				return fmt.Errorf("%w, restore the branch with: git rebase --abort", err)
+			}
+			return err
+		}
+		return nil
+	},
+}
+
+// checkReword returns an error when the commit can't be rewritten safely: it isn't in the
+// current branch, it's already pushed without --force, or a merge commit follows it.
+func checkReword(g *git.Command, hash string) error {
+	if ops := g.InProgress(); len(ops) > 0 {
+		return errors.New("a " + ops[0] + " is in progress, finish it before rewording a commit")
+	}
+	if !g.IsAncestor(hash, "HEAD") {
+		return errors.New("the commit " + hash[:7] + " isn't in the history of the current branch")
+	}
+	if branches := g.RemoteBranches(hash); len(branches) > 0 && !rewordForce && !preview {
+		return errors.New("the commit " + hash[:7] + " is already pushed to " + strings.Join(branches, ", ") +
+			", rewording it rewrites the published history, use --force to reword it anyway")
+	}
+	merges, err := g.Merges(hash + "..HEAD")
+	if err != nil {
+		return err
+	}
+	if len(merges) > 0 {
+		return errors.New("the merge commit " + merges[0][:7] + " follows the commit " + hash[:7] + ", it can't be reworded without losing the merge")
+	}
+	return nil
+}
+
+// withTrailers appends the trailers missing from the message, in a paragraph of their own.
+func withTrailers(message string, trailers []string) string {
+	var missing []string
+	for _, t := range trailers {
+		if !strings.Contains(message, t) {
+			missing = append(missing, t)
+		}
+	}
+	if len(missing) == 0 {
+		return message
+	}
+	if len(format.Trailers(message)) > 0 {
+		return message + "\n" + strings.Join(missing, "\n")
+	}
+	return message + "\n\n" + strings.Join(missing, "\n")
+}
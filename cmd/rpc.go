@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/openai"
+	codegptv1 "github.com/appleboy/CodeGPT/proto/codegpt/v1"
+	"github.com/appleboy/CodeGPT/proto/codegpt/v1/codegptv1connect"
+	"github.com/appleboy/CodeGPT/tracing"
+
+	"connectrpc.com/connect"
+)
+
+// rpcServer serves the commands over gRPC, gRPC-Web and Connect, on the same
+// address and with the same pipeline as the HTTP API.
+type rpcServer struct {
+	codegptv1connect.UnimplementedCodeGPTServiceHandler
+	*server
+}
+
+// Ensure that rpcServer implements the service.
+var _ codegptv1connect.CodeGPTServiceHandler = (*rpcServer)(nil)
+
+// GenerateCommitMessage streams the steps of the pipeline, then the commit message with the usage.
+func (s *rpcServer) GenerateCommitMessage(
+	ctx context.Context,
+	req *connect.Request[codegptv1.GenerateCommitMessageRequest],
+	stream *connect.ServerStream[codegptv1.GenerateCommitMessageResponse],
+) error {
+	if err := s.check(req.Header(), req.Msg.GetDiff()); err != nil {
+		return err
+	}
+
+	ctx = tracing.Extract(ctx, req.Header().Get("traceparent"))
+	out, status := s.exec(ctx, "commit", req.Msg.GetDiff(), func(ctx context.Context, diff string) error {
+		return s.commitMessage(ctx, diff, func(name string) error {
+			return stream.Send(&codegptv1.GenerateCommitMessageResponse{
+				Event: &codegptv1.GenerateCommitMessageResponse_Step{Step: name},
+			})
+		})
+	})
+	if status != http.StatusOK {
+		return rpcError(status, out.Error)
+	}
+	return stream.Send(&codegptv1.GenerateCommitMessageResponse{
+		Event: &codegptv1.GenerateCommitMessageResponse_Message{Message: out.Message},
+		Usage: rpcUsage(out.Usage),
+	})
+}
+
+// Review streams the findings of the review, then the usage.
+func (s *rpcServer) Review(
+	ctx context.Context,
+	req *connect.Request[codegptv1.ReviewRequest],
+	stream *connect.ServerStream[codegptv1.ReviewResponse],
+) error {
+	if err := s.check(req.Header(), req.Msg.GetDiff()); err != nil {
+		return err
+	}
+
+	ctx = tracing.Extract(ctx, req.Header().Get("traceparent"))
+	out, status := s.exec(ctx, "review", req.Msg.GetDiff(), func(ctx context.Context, diff string) error {
+		return s.review(ctx, diff, req.Msg.GetProfile())
+	})
+	if status != http.StatusOK {
+		return rpcError(status, out.Error)
+	}
+	for _, f := range out.Findings {
+		if err := stream.Send(&codegptv1.ReviewResponse{Finding: &codegptv1.Finding{
+			File:       f.File,
+			StartLine:  int32(f.StartLine),
+			EndLine:    int32(f.EndLine),
+			Severity:   f.Severity,
+			Category:   f.Category,
+			Owasp:      f.OWASP,
+			Message:    f.Message,
+			Suggestion: f.Suggestion,
+		}}); err != nil {
+			return err
+		}
+	}
+	return stream.Send(&codegptv1.ReviewResponse{Usage: rpcUsage(out.Usage)})
+}
+
+// check checks the bearer token of the request and its diff.
+func (s *rpcServer) check(header http.Header, diff string) error {
+	if !s.authorized(&http.Request{Header: header}) {
+		return connect.NewError(connect.CodeUnauthenticated, errors.New("invalid or missing bearer token"))
+	}
+	if strings.TrimSpace(diff) == "" {
+		return connect.NewError(connect.CodeInvalidArgument, errors.New("the diff of the changes is empty"))
+	}
+	return nil
+}
+
+// rpcError returns the error of the RPC from the HTTP status of the command.
+func rpcError(status int, message string) error {
+	code := connect.CodeInternal
+	switch status {
+	case http.StatusBadRequest:
+		code = connect.CodeInvalidArgument
+	case http.StatusTooManyRequests:
+		code = connect.CodeResourceExhausted
+	case http.StatusUnprocessableEntity:
+		code = connect.CodeFailedPrecondition
+	case 499:
+		code = connect.CodeCanceled
+	}
+	return connect.NewError(code, errors.New(message))
+}
+
+func rpcUsage(u openai.Usage) *codegptv1.Usage {
+	return &codegptv1.Usage{
+		PromptTokens:     int32(u.PromptTokens),
+		CompletionTokens: int32(u.CompletionTokens),
+		TotalTokens:      int32(u.TotalTokens),
+	}
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/appleboy/CodeGPT/openai"
+	codegptv1 "github.com/appleboy/CodeGPT/proto/codegpt/v1"
+	"github.com/appleboy/CodeGPT/proto/codegpt/v1/codegptv1connect"
+
+	"connectrpc.com/connect"
+)
+
+// rpcClient serves the RPCs of the server in process and returns a client of them.
+func rpcClient(t *testing.T, s *server) codegptv1connect.CodeGPTServiceClient {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.Handle(codegptv1connect.NewCodeGPTServiceHandler(&rpcServer{server: s}))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return codegptv1connect.NewCodeGPTServiceClient(srv.Client(), srv.URL)
+}
+
+func withToken[T any](msg *T, token string) *connect.Request[T] {
+	req := connect.NewRequest(msg)
+	if token != "" {
+		req.Header().Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestRPCGenerateCommitMessage(t *testing.T) {
+	client := rpcClient(t, testServer(t, mockClient(t)))
+
+	stream, err := client.GenerateCommitMessage(context.Background(),
+		withToken(&codegptv1.GenerateCommitMessageRequest{Diff: serveDiff}, "secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		steps   []string
+		message string
+		usage   *codegptv1.Usage
+	)
+	for stream.Receive() {
+		msg := stream.Msg()
+		if step := msg.GetStep(); step != "" {
+			steps = append(steps, step)
+		}
+		if m := msg.GetMessage(); m != "" {
+			message, usage = m, msg.GetUsage()
+		}
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"summary", "title", "prefix"}
+	if len(steps) != len(want) {
+		t.Fatalf("steps = %q, want %q", steps, want)
+	}
+	for i := range want {
+		if steps[i] != want[i] {
+			t.Errorf("steps = %q, want %q", steps, want)
+		}
+	}
+	if message != "feat: update the code\n\nupdate the code" {
+		t.Errorf("message = %q, want the mock commit message", message)
+	}
+	if usage.GetTotalTokens() == 0 {
+		t.Error("usage = 0, want the tokens of the mock requests")
+	}
+}
+
+func TestRPCReview(t *testing.T) {
+	client, err := openai.New(
+		openai.WithProvider(openai.MOCK),
+		openai.WithModel("gpt-4o"),
+		openai.WithMockResponse(`{{- if contains .Prompt "JSON" -}}
+[{"file": "main.go", "start_line": 2, "end_line": 2, "severity": "HIGH", "category": "bug", "message": "main does nothing"}]
+{{- else -}}update the code{{- end -}}`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpc := rpcClient(t, testServer(t, client))
+
+	stream, err := rpc.Review(context.Background(), withToken(&codegptv1.ReviewRequest{Diff: serveDiff}, "secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var findings []*codegptv1.Finding
+	for stream.Receive() {
+		if f := stream.Msg().GetFinding(); f != nil {
+			findings = append(findings, f)
+		}
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].GetFile() != "main.go" || findings[0].GetSeverity() != "HIGH" {
+		t.Errorf("findings = %v, want the finding of main.go", findings)
+	}
+}
+
+func TestRPCErrors(t *testing.T) {
+	client := rpcClient(t, testServer(t, mockClient(t)))
+
+	tests := []struct {
+		name  string
+		token string
+		req   *codegptv1.ReviewRequest
+		code  connect.Code
+	}{
+		{"missing token", "", &codegptv1.ReviewRequest{Diff: serveDiff}, connect.CodeUnauthenticated},
+		{"wrong token", "other", &codegptv1.ReviewRequest{Diff: serveDiff}, connect.CodeUnauthenticated},
+		{"empty diff", "secret", &codegptv1.ReviewRequest{Diff: "\n"}, connect.CodeInvalidArgument},
+		{"bad profile", "secret", &codegptv1.ReviewRequest{Diff: serveDiff, Profile: "style"}, connect.CodeInvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, err := client.Review(context.Background(), withToken(tt.req, tt.token))
+			if err == nil {
+				for stream.Receive() {
+				}
+				err = stream.Err()
+			}
+			var cerr *connect.Error
+			if !errors.As(err, &cerr) || cerr.Code() != tt.code {
+				t.Errorf("error = %v, want the %v code", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestRPCError(t *testing.T) {
+	tests := []struct {
+		status int
+		code   connect.Code
+	}{
+		{http.StatusBadRequest, connect.CodeInvalidArgument},
+		{http.StatusTooManyRequests, connect.CodeResourceExhausted},
+		{http.StatusUnprocessableEntity, connect.CodeFailedPrecondition},
+		{499, connect.CodeCanceled},
+		{http.StatusInternalServerError, connect.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		if code := connect.CodeOf(rpcError(tt.status, "failed")); code != tt.code {
+			t.Errorf("rpcError(%d) code = %v, want %v", tt.status, code, tt.code)
+		}
+	}
+}
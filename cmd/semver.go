@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/release"
+	"github.com/appleboy/CodeGPT/util"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// defaultVersion is the version before the first tag.
+const defaultVersion = "v0.0.0"
+
+var (
+	semverFrom string
+	semverTag  bool
+)
+
+func init() {
+	semverCmd.Flags().StringVar(&semverFrom, "from", "", "tag of the current version, default is the latest tag")
+	semverCmd.Flags().BoolVar(&semverTag, "tag", false, "create the annotated tag of the next version with a generated release summary")
+	semverCmd.Flags().StringVar(&commitModel, "model", "gpt-3.5-turbo", "select openai model")
+	semverCmd.Flags().StringVar(&commitLang, "lang", "en", "summarizing language uses English by default")
+	semverCmd.Flags().IntVar(&maxTokens, "max_tokens", 300, "the maximum number of tokens to generate in the chat completion.")
+	semverCmd.Flags().DurationVarP(&timeout, "timeout", "t", 0, "timeout of every request to the provider, default is openai.timeout")
+	semverCmd.Flags().DurationVar(&connectTimeout, "connect_timeout", 0, "timeout of the connection to the provider, default is openai.connect_timeout")
+}
+
+// Bump is the next version suggested by the semver command.
+type Bump struct {
+	Current string           `json:"current"`
+	Next    string           `json:"next"`
+	Level   string           `json:"level"`
+	Commits []release.Commit `json:"commits"`
+	Tagged  bool             `json:"tagged,omitempty"`
+}
+
+var semverCmd = &cobra.Command{
+	Use:   "semver",
+	Short: "Suggest the next semantic version from the commits since the last tag",
+	Long: `Suggest the next semantic version from the Conventional Commits since the last tag:
+a breaking change bumps the major version, a feature the minor version, and a fix,
+a performance improvement or a revert the patch version. Before 1.0.0, a breaking
+change bumps the minor version.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := check(); err != nil {
+			return err
+		}
+
+		g := git.New()
+		from := semverFrom
+		if from == "" {
+			tag, err := g.LatestTag("HEAD")
+			if err != nil {
+				return err
+			}
+			from = tag
+		}
+
+		current := defaultVersion
+		revisions := "HEAD"
+		if from != "" {
+			current = from
+			revisions = from + "..HEAD"
+		}
+		version, err := release.ParseVersion(current)
+		if err != nil {
+			return fmt.Errorf("the tag %s isn't a semantic version, use --from: %w", from, err)
+		}
+
+		commits, err := releaseCommits(g, revisions)
+		if err != nil {
+			return err
+		}
+
+		level := release.LevelOf(commits)
+		next := version.Bump(level)
+		reason := ""
+		if level == release.Major && version.Major == 0 {
+			level, reason = release.Minor, ", the breaking changes bump the minor version before 1.0.0"
+		}
+		result.Bump = &Bump{
+			Current: version.String(),
+			Next:    next.String(),
+			Level:   level.String(),
+			Commits: commits,
+		}
+
+		if !isMachineOutput() {
+			color.Cyan("Current version: " + version.String())
+			color.Cyan("Commits since " + version.String() + ": " + strconv.Itoa(len(commits)) + countTypes(commits))
+			color.Cyan("Suggested bump: " + level.String() + reason)
+		}
+		if level == release.None {
+			logger.Info("No release needed, there's no feature, fix or breaking change since " + version.String())
+			return nil
+		}
+		if !isMachineOutput() {
+			color.Green("Next version: " + next.String())
+		}
+
+		if !semverTag {
+			return nil
+		}
+
+		summary, err := releaseSummary(cmd.Context(), g, next.String(), commits)
+		if err != nil {
+			return err
+		}
+
+		message := next.String() + "\n\n" + summary + "\n\n" +
+			"- " + strings.Join(subjects(commits), "\n- ")
+		if err := g.CreateTag(next.String(), message); err != nil {
+			return err
+		}
+		result.Bump.Tagged = true
+		result.Message = message
+		logger.Info("Created the tag " + next.String() + ", push it with: git push origin " + next.String())
+		return nil
+	},
+}
+
+// releaseCommits returns the conventional commits of the revision range, newest first.
+func releaseCommits(g *git.Command, revisions string) ([]release.Commit, error) {
+	entries, err := g.Log(revisions)
+	if err != nil {
+		return nil, err
+	}
+	commits := make([]release.Commit, 0, len(entries))
+	for _, e := range entries {
+		c := release.ParseCommit(e.Hash, e.Message)
+		c.Author, c.Email = e.Author, e.Email
+		commits = append(commits, c)
+	}
+	return commits, nil
+}
+
+// releaseSummary asks the model for the paragraph with the highlights of the release.
+func releaseSummary(ctx context.Context, g *git.Command, version string, commits []release.Commit) (string, error) {
+	client, err := newClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	out, err := util.GetTemplateByString(
+		prompt.ReleaseSummaryTemplate,
+		withVars(promptVars(g, nil), util.Data{
+			"version": version,
+			"commits": subjects(commits),
+		}),
+	)
+	if err != nil {
+		return "", err
+	}
+	logger.Info("We are trying to summarize the release " + version)
+	resp, err := completion(ctx, client, out)
+	if err != nil {
+		return "", err
+	}
+	printUsage(ctx, resp.Usage)
+	return strings.TrimSpace(resp.Content), nil
+}
+
+// countTypes describes the number of breaking changes, features, fixes and other commits.
+func countTypes(commits []release.Commit) string {
+	var breaking, features, fixes, others int
+	for _, c := range commits {
+		switch {
+		case c.Breaking:
+			breaking++
+		case c.Type == "feat":
+			features++
+		case c.Level() == release.Patch:
+			fixes++
+		default:
+			others++
+		}
+	}
+	var parts []string
+	for _, p := range []struct {
+		n    int
+		name string
+	}{{breaking, "breaking"}, {features, "feat"}, {fixes, "fix"}, {others, "other"}} {
+		if p.n > 0 {
+			parts = append(parts, strconv.Itoa(p.n)+" "+p.name)
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// subjects returns the headers of the commits, like feat(cli): add the semver command.
+func subjects(commits []release.Commit) []string {
+	out := make([]string, 0, len(commits))
+	for _, c := range commits {
+		header := c.Subject
+		if c.Type != "" {
+			prefix := c.Type
+			if c.Scope != "" {
+				prefix += "(" + c.Scope + ")"
+			}
+			if c.Breaking {
+				prefix += "!"
+			}
+			header = prefix + ": " + header
+		}
+		out = append(out, header)
+	}
+	return out
+}
@@ -0,0 +1,324 @@
+package cmd
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/openai"
+	"github.com/appleboy/CodeGPT/proto/codegpt/v1/codegptv1connect"
+	"github.com/appleboy/CodeGPT/review"
+	"github.com/appleboy/CodeGPT/tracing"
+
+	"connectrpc.com/connect"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// maxDiffSize is the maximum size of the diff sent to the server.
+const maxDiffSize = 10 << 20
+
+var serveAddr string
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "", "address of the HTTP server, default is serve.addr")
+	serveCmd.Flags().StringVar(&commitModel, "model", "gpt-3.5-turbo", "select openai model")
+	serveCmd.Flags().StringVar(&commitLang, "lang", "en", "summarizing language uses English by default")
+	serveCmd.Flags().IntVar(&maxTokens, "max_tokens", 300, "the maximum number of tokens to generate in the chat completion.")
+	serveCmd.Flags().DurationVarP(&timeout, "timeout", "t", 0, "timeout of every request to the provider, default is openai.timeout")
+	serveCmd.Flags().DurationVar(&connectTimeout, "connect_timeout", 0, "timeout of the connection to the provider, default is openai.connect_timeout")
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the commit message and the code review over an HTTP and gRPC API",
+	Long: `Serve the commit message and the code review over an HTTP and gRPC API. The config
+files are watched, a change of the provider, the model, the prompt settings or the token
+is used by the next request without restarting the server.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := check(); err != nil {
+			return err
+		}
+
+		addr := serveAddr
+		if addr == "" {
+			addr = viper.GetString("serve.addr")
+		}
+
+		// one client for all the requests, they share its connections and the response cache
+		client, err := newClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+		s := &server{
+			client:   client,
+			provider: result.Provider,
+			model:    result.Model,
+			token:    secret("serve.token"),
+		}
+		// the config file may not exist, it's empty then
+		s.config, _ = os.ReadFile(viper.ConfigFileUsed())
+
+		mux := http.NewServeMux()
+		mux.HandleFunc("/v1/health", s.health)
+		mux.HandleFunc("/v1/commit-message", s.handle("commit"))
+		mux.HandleFunc("/v1/review", s.handle("review"))
+		mux.HandleFunc("/metrics", s.metricsHandler)
+		// the streaming RPCs of gRPC, gRPC-Web and Connect
+		mux.Handle(codegptv1connect.NewCodeGPTServiceHandler(
+			&rpcServer{server: s},
+			connect.WithReadMaxBytes(maxDiffSize),
+		))
+
+		srv := &http.Server{
+			Addr: addr,
+			// gRPC needs HTTP/2, without TLS on the local address
+			Handler:           h2c.NewHandler(mux, &http2.Server{}),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		errs := make(chan error, 1)
+		go func() {
+			errs <- srv.ListenAndServe()
+		}()
+		logger.Info("Serve the HTTP API on http://" + addr + " using " + s.model + " model")
+		if s.token == "" {
+			logger.Warn("serve.token isn't set, any local process can use the API key of the server")
+		}
+		// editor integrations pick up the new keys and models without a restart
+		go watchConfig(cmd.Context(), configPollInterval, func() {
+			s.reload(cmd.Context(), cmd)
+		})
+
+		select {
+		case err := <-errs:
+			return err
+		case <-cmd.Context().Done():
+		}
+		logger.Info("Shut down the HTTP server")
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(ctx)
+	},
+}
+
+// server serves the commands over HTTP with a shared client.
+type server struct {
+	client   *openai.Client
+	provider string
+	model    string
+	token    string
+	// config is the content of the global config file in use, restored when a reload fails
+	config []byte
+
+	// the requests run at the same time with a read lock, each with its own result, the
+	// config reload waits for them with the write lock
+	mu sync.RWMutex
+	// conf guards the settings above for the handlers running outside of the requests,
+	// the config reload changes them with both locks held
+	conf sync.RWMutex
+}
+
+// handle returns the handler of the POST requests of the command, the body is the unified diff.
+// The response is the JSON result of the command, like the --output json one.
+func (s *server) handle(command string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		if !s.authorized(r) {
+			s.fail(w, http.StatusUnauthorized, errors.New("invalid or missing bearer token"))
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			s.fail(w, http.StatusMethodNotAllowed, errors.New("only support POST method"))
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxDiffSize))
+		if err != nil {
+			s.fail(w, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+		diff := string(body)
+		if strings.TrimSpace(diff) == "" {
+			s.fail(w, http.StatusBadRequest, errors.New("the body must be the unified diff of the changes"))
+			return
+		}
+
+		var fn func(ctx context.Context, diff string) error
+		switch command {
+		case "commit":
+			fn = func(ctx context.Context, diff string) error {
+				return s.commitMessage(ctx, diff, nil)
+			}
+		default:
+			fn = func(ctx context.Context, diff string) error {
+				return s.review(ctx, diff, r.URL.Query().Get("profile"))
+			}
+		}
+		ctx := tracing.Extract(r.Context(), r.Header.Get("traceparent"))
+		out, status := s.exec(ctx, command, diff, fn)
+		s.write(w, status, out)
+		logger.Info(r.Method + " " + r.URL.Path + " " + strconv.Itoa(status) + " " + time.Since(start).Round(time.Millisecond).String())
+	}
+}
+
+// exec runs the command of the diff and returns its result with the HTTP status. The usage
+// is checked against the budget before, and recorded after every request. The request is
+// the root span of its trace, or the child of the traceparent of the caller.
+func (s *server) exec(ctx context.Context, command, diff string, fn func(ctx context.Context, diff string) error) (*Result, int) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ctx, span := tracing.StartKind(ctx, "codegpt "+command, tracing.KindServer)
+	defer span.End()
+
+	out := &Result{Command: command, Provider: s.provider, Model: s.model}
+	ctx = withResult(ctx, out)
+	start := time.Now()
+
+	status := http.StatusOK
+	if err := checkBudget(); err != nil {
+		status = http.StatusTooManyRequests
+		out.Error = err.Error()
+	} else if err := s.run(ctx, diff, fn); err != nil {
+		status = statusOf(err)
+		out.Error = err.Error()
+		span.SetError(err)
+	}
+	span.SetAttributes(
+		tracing.String("gen_ai.request.model", s.model),
+		tracing.Int("http.response.status_code", status),
+	)
+	recordUsage(out, start)
+	out.Version = Version
+	out.DurationMs = time.Since(start).Milliseconds()
+	requestsTotal.Inc(command, strconv.Itoa(status))
+	requestDuration.Observe(time.Since(start).Seconds(), command)
+	return out, status
+}
+
+// run redacts the secrets of the diff and runs the command.
+func (s *server) run(ctx context.Context, diff string, fn func(ctx context.Context, diff string) error) error {
+	_, span := tracing.Start(ctx, "diff")
+	diff, err := redactDiff(diff)
+	if err != nil {
+		span.SetError(err)
+		span.End()
+		return err
+	}
+	diff, err = fitDiff(ctx, diff)
+	span.SetError(err)
+	span.End()
+	if err != nil {
+		return err
+	}
+	return fn(ctx, diff)
+}
+
+// commitMessage writes the commit message of the diff to the result, the steps of
+// the pipeline are reported to the step function if it's set.
+func (s *server) commitMessage(ctx context.Context, diff string, step func(name string) error) error {
+	var files []string
+	for _, f := range git.ParsePatch(diff) {
+		files = append(files, f.Name())
+	}
+	// the diff may come from another repository than the one of the server
+	message, err := generateMessage(ctx, s.client, nil, settingVars(files), diff, files, messageOptions{step: step})
+	if err != nil {
+		return err
+	}
+	resultOf(ctx).Message = message
+	return nil
+}
+
+// review writes the findings of the diff to the result, the profile is general or security.
+func (s *server) review(ctx context.Context, diff, profile string) error {
+	switch profile {
+	case "":
+		profile = review.ProfileGeneral
+	case review.ProfileGeneral, review.ProfileSecurity:
+	default:
+		return &requestError{errors.New("profile must be general or security")}
+	}
+	var files []string
+	for _, f := range git.ParsePatch(diff) {
+		files = append(files, f.Name())
+	}
+	findings, err := reviewDiff(ctx, s.client, settingVars(files), diff, profile)
+	if err != nil {
+		return err
+	}
+	resultOf(ctx).Findings = findings
+	return nil
+}
+
+// health returns the provider and the model of the server.
+func (s *server) health(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		s.fail(w, http.StatusUnauthorized, errors.New("invalid or missing bearer token"))
+		return
+	}
+	s.conf.RLock()
+	out := &Result{Command: "health", Version: Version, Provider: s.provider, Model: s.model}
+	s.conf.RUnlock()
+	s.write(w, http.StatusOK, out)
+}
+
+// authorized reports whether the request has the bearer token of serve.token, if it's set.
+func (s *server) authorized(r *http.Request) bool {
+	s.conf.RLock()
+	want := s.token
+	s.conf.RUnlock()
+	if want == "" {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1
+}
+
+// fail writes the error as the JSON result.
+func (s *server) fail(w http.ResponseWriter, status int, err error) {
+	s.write(w, status, &Result{Version: Version, Error: err.Error()})
+}
+
+func (s *server) write(w http.ResponseWriter, status int, out *Result) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(out)
+}
+
+// requestError is an error of the parameters of the request.
+type requestError struct {
+	error
+}
+
+// statusOf returns the HTTP status of the error of a command.
+func statusOf(err error) int {
+	var (
+		flagged *openai.FlaggedError
+		invalid *requestError
+	)
+	switch {
+	case errors.As(err, &invalid):
+		return http.StatusBadRequest
+	case errors.As(err, &flagged):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, context.Canceled):
+		// the client closed the connection
+		return 499
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/appleboy/CodeGPT/openai"
+
+	"github.com/spf13/viper"
+)
+
+const serveDiff = "diff --git a/main.go b/main.go\n" +
+	"--- a/main.go\n" +
+	"+++ b/main.go\n" +
+	"@@ -1,3 +1,3 @@\n" +
+	" package main\n" +
+	"-func main() {}\n" +
+	"+func main() { println(\"hello\") }\n"
+
+// testServer returns a server of the client with the token, the usage and the cache
+// are kept out of the user folders.
+func testServer(t *testing.T, client *openai.Client) *server {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOME", home)
+	for k, v := range map[string]interface{}{
+		"usage.enable": false,
+		"cache.enable": false,
+	} {
+		viper.Set(k, v)
+		// back to the value of the config or the default
+		t.Cleanup(func() { viper.Set(k, nil) })
+	}
+	return &server{client: client, provider: openai.MOCK, model: "gpt-4o", token: "secret"}
+}
+
+func mockClient(t *testing.T) *openai.Client {
+	t.Helper()
+	client, err := openai.New(
+		openai.WithProvider(openai.MOCK),
+		openai.WithModel("gpt-4o"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestServerHandle(t *testing.T) {
+	// the moderation of the fake provider flags every prompt
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/moderations") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"modr-1","model":"omni-moderation-latest","results":[{"flagged":true,"categories":{"violence":true}}]}`))
+	}))
+	t.Cleanup(provider.Close)
+	flagging, err := openai.New(
+		openai.WithToken("sk-test"),
+		openai.WithProvider(openai.OPENAI),
+		openai.WithModel("gpt-4o"),
+		openai.WithBaseURL(provider.URL+"/v1"),
+		openai.WithModeration(openai.ModerationBlock),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		client  *openai.Client
+		method  string
+		path    string
+		token   string
+		body    string
+		status  int
+		message bool
+	}{
+		{"missing token", nil, http.MethodPost, "/v1/commit-message", "", serveDiff, http.StatusUnauthorized, false},
+		{"wrong token", nil, http.MethodPost, "/v1/commit-message", "other", serveDiff, http.StatusUnauthorized, false},
+		{"get", nil, http.MethodGet, "/v1/commit-message", "secret", "", http.StatusMethodNotAllowed, false},
+		{"empty body", nil, http.MethodPost, "/v1/commit-message", "secret", " \n", http.StatusBadRequest, false},
+		{"oversized body", nil, http.MethodPost, "/v1/commit-message", "secret", strings.Repeat("+", maxDiffSize+1), http.StatusRequestEntityTooLarge, false},
+		{"flagged", flagging, http.MethodPost, "/v1/commit-message", "secret", serveDiff, http.StatusUnprocessableEntity, false},
+		{"bad profile", nil, http.MethodPost, "/v1/review?profile=style", "secret", serveDiff, http.StatusBadRequest, false},
+		{"commit message", nil, http.MethodPost, "/v1/commit-message", "secret", serveDiff, http.StatusOK, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := tt.client
+			if client == nil {
+				client = mockClient(t)
+			}
+			s := testServer(t, client)
+			command := "commit"
+			if strings.HasPrefix(tt.path, "/v1/review") {
+				command = "review"
+			}
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			s.handle(command)(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			var out Result
+			if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+				t.Fatal(err)
+			}
+			if tt.message {
+				if out.Message == "" || out.Error != "" {
+					t.Errorf("result = %+v, want a commit message", out)
+				}
+			} else if out.Error == "" {
+				t.Errorf("result = %+v, want an error", out)
+			}
+			if tt.status == http.StatusMethodNotAllowed && rec.Header().Get("Allow") != http.MethodPost {
+				t.Errorf("Allow = %q, want POST", rec.Header().Get("Allow"))
+			}
+		})
+	}
+}
+
+func TestServerWithoutToken(t *testing.T) {
+	s := testServer(t, mockClient(t))
+	s.token = ""
+
+	rec := httptest.NewRecorder()
+	s.health(rec, httptest.NewRequest(http.MethodGet, "/v1/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200 without serve.token", rec.Code)
+	}
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/appleboy/CodeGPT/cache"
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/openai"
+
+	"github.com/spf13/viper"
+)
+
+// embeddingTTL is how long the embeddings of the commits are cached,
+// a commit never changes so they are only refreshed once in a while.
+const embeddingTTL = 30 * 24 * time.Hour
+
+// maxQueryLength is the number of characters of the diff embedded as the query.
+const maxQueryLength = 8000
+
+// similarExamples returns up to n titles of the past commits the closest to the
+// changes, from the embeddings of their subject and changed files.
+func similarExamples(ctx context.Context, client *openai.Client, g *git.Command, diff string, files []string, n int) ([]string, error) {
+	commits, err := g.History(viper.GetInt("prompt.similar_history"))
+	if err != nil {
+		return nil, err
+	}
+	// only the well-formed titles are good examples
+	var candidates []git.Commit
+	for _, c := range commits {
+		if git.WellFormed(c.Subject) {
+			candidates = append(candidates, c)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	vectors, err := commitEmbeddings(ctx, client, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	query := strings.Join(files, "\n") + "\n" + diff
+	if len(query) > maxQueryLength {
+		query = query[:maxQueryLength]
+	}
+	out, usage, err := client.Embeddings(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	// the embeddings are billed like the completions, they have no response text to audit
+	printUsage(ctx, usage)
+	recordAudit(ctx, query, &openai.Response{Usage: usage})
+
+	type scored struct {
+		subject string
+		score   float64
+	}
+	ranked := make([]scored, len(candidates))
+	for i, c := range candidates {
+		ranked[i] = scored{subject: c.Subject, score: openai.Cosine(out[0], vectors[i])}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].score > ranked[j].score
+	})
+
+	subjects := make([]string, len(ranked))
+	for i, r := range ranked {
+		subjects[i] = r.subject
+	}
+	return git.Examples(subjects, n), nil
+}
+
+// mergeExamples returns the similar titles followed by the recent ones, without duplicates.
+func mergeExamples(similar, recent []string) []string {
+	out := append([]string{}, similar...)
+	for _, r := range recent {
+		if !contains(out, r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// commitEmbeddings returns the embeddings of the commits, the ones not in the cache
+// are requested in one call and cached by the commit hash.
+func commitEmbeddings(ctx context.Context, client *openai.Client, commits []git.Commit) ([][]float32, error) {
+	var c *cache.Cache
+	if !noCache && viper.GetBool("cache.enable") {
+		c, _ = cache.New(
+			cache.WithDir(viper.GetString("cache.dir")),
+			cache.WithTTL(embeddingTTL),
+		)
+	}
+
+	vectors := make([][]float32, len(commits))
+	var (
+		missing []int
+		inputs  []string
+	)
+	for i, commit := range commits {
+		if c != nil {
+			if out, ok := c.Get(embeddingKey(client, commit)); ok &&
+				json.Unmarshal([]byte(out), &vectors[i]) == nil {
+				continue
+			}
+		}
+		missing = append(missing, i)
+		inputs = append(inputs, commit.Subject+"\n"+strings.Join(commit.Files, "\n"))
+	}
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+
+	logger.Debug("embed " + strconv.Itoa(len(missing)) + " commits of the history")
+	out, usage, err := client.Embeddings(ctx, inputs)
+	if err != nil {
+		return nil, err
+	}
+	printUsage(ctx, usage)
+	recordAudit(ctx, strings.Join(inputs, "\n"), &openai.Response{Usage: usage})
+
+	for j, i := range missing {
+		vectors[i] = out[j]
+		if c == nil {
+			continue
+		}
+		data, _ := json.Marshal(out[j])
+		if err := c.Set(embeddingKey(client, commits[i]), string(data)); err != nil {
+			logger.Debug("can't cache the embedding: " + err.Error())
+		}
+	}
+	return vectors, nil
+}
+
+// embeddingKey returns the cache key of the embedding of the commit.
+func embeddingKey(client *openai.Client, commit git.Commit) string {
+	return cache.Key("embedding", viper.GetString("openai.provider"), client.EmbeddingModel(), commit.Hash)
+}
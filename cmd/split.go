@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/util"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	splitCmd.Flags().IntVar(&diffUnified, "diff_unified", 3, "generate diffs with <n> lines of context, default is 3")
+	splitCmd.Flags().StringVar(&commitModel, "model", "gpt-3.5-turbo", "select openai model")
+	splitCmd.Flags().StringSliceVar(&excludeList, "exclude_list", []string{}, "exclude file from git diff command")
+	splitCmd.Flags().IntVar(&maxTokens, "max_tokens", 300, "the maximum number of tokens to generate in the chat completion.")
+	splitCmd.Flags().DurationVarP(&timeout, "timeout", "t", 0, "timeout of every request to the provider, default is openai.timeout")
+	splitCmd.Flags().DurationVar(&connectTimeout, "connect_timeout", 0, "timeout of the connection to the provider, default is openai.connect_timeout")
+	splitCmd.Flags().BoolVar(&preview, "preview", false, "preview the proposed commits without committing")
+	splitCmd.Flags().BoolVar(&dryRun, "dry_run", false, "print the request that would be sent to the provider without sending it")
+}
+
+var splitCmd = &cobra.Command{
+	Use:   "split",
+	Short: "Split the staged changes into several logical commits",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// every proposed commit is confirmed, only the preview runs unattended
+		if !preview {
+			if err := requireInteractive("the split command without --preview"); err != nil {
+				return err
+			}
+		}
+		if err := check(); err != nil {
+			return err
+		}
+
+		g := git.New(
+			git.WithDiffUnified(viper.GetInt("git.diff_unified")),
+			git.WithExcludeList(viper.GetStringSlice("git.exclude_list")),
+			git.WithMaxFileSize(viper.GetInt64("git.max_file_size")),
+		)
+		diff, err := g.DiffFiles()
+		if err != nil {
+			return err
+		}
+
+		diff, err = redactDiff(diff)
+		if err != nil {
+			return err
+		}
+
+		files, err := g.DiffNames()
+		if err != nil {
+			return err
+		}
+
+		client, err := newClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		out, err := util.GetTemplateByString(
+			prompt.SplitCommitsTemplate,
+			withVars(promptVars(g, files), util.Data{
+				"file_diffs": diff,
+			}),
+		)
+		if err != nil {
+			return err
+		}
+
+		if dryRun {
+			printDryRun(client, out)
+			return nil
+		}
+
+		logger.Info("We are trying to split the changes into logical commits")
+		resp, err := completion(cmd.Context(), client, out)
+		if err != nil {
+			return err
+		}
+		printUsage(cmd.Context(), resp.Usage)
+
+		groups, err := prompt.ParseCommitGroups(resp.Content)
+		if err != nil {
+			return err
+		}
+		if len(groups) == 0 {
+			return errors.New("the model didn't propose any commit")
+		}
+		result.Commits = groups
+
+		// only keep the staged files and commit every file once
+		remaining := map[string]bool{}
+		for _, f := range files {
+			remaining[f] = true
+		}
+
+		if preview {
+			for i, group := range groups {
+				printCommitGroup(i+1, len(groups), group)
+			}
+			return nil
+		}
+
+		// save the staged changes, they are restored when all groups are handled
+		tree, err := g.WriteTree()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := g.StageFromTree(tree, []string{":/"}); err != nil {
+				logger.Error("Failed to restore the staged changes: " + err.Error())
+			}
+		}()
+
+		reader := bufio.NewReader(os.Stdin)
+		for i, group := range groups {
+			var groupFiles []string
+			for _, f := range group.Files {
+				if remaining[f] {
+					groupFiles = append(groupFiles, f)
+				}
+			}
+			if len(groupFiles) == 0 {
+				continue
+			}
+			group.Files = groupFiles
+			printCommitGroup(i+1, len(groups), group)
+
+			fmt.Fprint(color.Output, "Commit this group [y,n,q]? ")
+			answer, err := reader.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return err
+			}
+
+			switch strings.TrimSpace(answer) {
+			case "y":
+			case "q":
+				return nil
+			default:
+				continue
+			}
+
+			if err := g.StageFromTree(tree, groupFiles); err != nil {
+				return err
+			}
+			output, err := g.Commit(group.Message)
+			if err != nil {
+				return err
+			}
+			color.Yellow(output)
+
+			for _, f := range groupFiles {
+				delete(remaining, f)
+			}
+		}
+
+		if len(remaining) > 0 {
+			logger.Info(strconv.Itoa(len(remaining)) + " file(s) are left staged")
+		}
+
+		return nil
+	},
+}
+
+// printCommitGroup prints the files and the message of a proposed commit.
+func printCommitGroup(n, total int, group prompt.CommitGroup) {
+	color.Yellow("==================Commit " + strconv.Itoa(n) + "/" + strconv.Itoa(total) + "======================")
+	color.Yellow(group.Message)
+	for _, f := range group.Files {
+		color.Cyan("  " + f)
+	}
+	color.Yellow("==================================================")
+}
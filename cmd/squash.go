@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/util"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	squashOnto        string
+	squashApply       bool
+	squashJiraComment bool
+)
+
+func init() {
+	squashCmd.Flags().StringVar(&squashOnto, "onto", "main", "branch the feature branch is squashed onto")
+	squashCmd.Flags().BoolVar(&squashApply, "apply", false, "squash the commits of the branch into one commit with the message")
+	squashCmd.Flags().BoolVar(&squashJiraComment, "jira_comment", false, "post the message as a comment of the Jira issue of the branch")
+	squashCmd.Flags().StringVar(&commitModel, "model", "gpt-3.5-turbo", "select openai model")
+	squashCmd.Flags().StringVar(&commitLang, "lang", "en", "summarizing language uses English by default")
+	squashCmd.Flags().IntVar(&maxTokens, "max_tokens", 300, "the maximum number of tokens to generate in the chat completion.")
+	squashCmd.Flags().DurationVarP(&timeout, "timeout", "t", 0, "timeout of every request to the provider, default is openai.timeout")
+	squashCmd.Flags().DurationVar(&connectTimeout, "connect_timeout", 0, "timeout of the connection to the provider, default is openai.connect_timeout")
+}
+
+var squashCmd = &cobra.Command{
+	Use:   "squash",
+	Short: "Write the squash commit message of the current branch",
+	Long: `Write one commit message for the commits of the current branch since it forked from
+the --onto branch, from their messages and the combined diff, ready for a squash merge
+or an interactive rebase. The authors of the branch are added as Co-authored-by trailers.
+With --apply, the commits of the branch are squashed into one commit with the message.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := check(); err != nil {
+			return err
+		}
+
+		g := git.New(
+			git.WithDiffUnified(viper.GetInt("git.diff_unified")),
+			git.WithExcludeList(viper.GetStringSlice("git.exclude_list")),
+			git.WithMaxFileSize(viper.GetInt64("git.max_file_size")),
+			git.WithRange(squashOnto, "HEAD", true),
+		)
+		if squashApply && g.HasStagedChanges() {
+			return errors.New("there are staged changes, commit or unstage them before squashing")
+		}
+
+		revisions := squashOnto + "..HEAD"
+		entries, err := g.Log(revisions)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return fmt.Errorf("there are no commits in %s", revisions)
+		}
+		// the log is newest first, the model reads the story of the branch in order
+		slices.Reverse(entries)
+		messages := make([]string, 0, len(entries))
+		for _, e := range entries {
+			messages = append(messages, e.Message)
+		}
+
+		diff, err := g.DiffFiles()
+		if err != nil {
+			return err
+		}
+		diff, err = redactDiff(diff)
+		if err != nil {
+			return err
+		}
+		diff, err = fitDiff(cmd.Context(), diff)
+		if err != nil {
+			return err
+		}
+		files, err := g.DiffNames()
+		if err != nil {
+			return err
+		}
+
+		client, err := newClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+		vars := promptVars(g, files)
+		out, err := util.GetTemplateByString(
+			prompt.SquashCommitTemplate,
+			withVars(vars, util.Data{
+				"commit_messages": messages,
+				"file_diffs":      diff,
+			}),
+		)
+		if err != nil {
+			return err
+		}
+
+		logger.Info(fmt.Sprintf("We are trying to write the squash commit message of %d commits", len(entries)))
+		resp, err := completion(cmd.Context(), client, out)
+		if err != nil {
+			return err
+		}
+		printUsage(cmd.Context(), resp.Usage)
+
+		message := strings.TrimSpace(resp.Content)
+		message = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(message, "```"), "```"))
+		header, body, _ := strings.Cut(message, "\n")
+		if viper.GetString("prompt.body") == prompt.BodyNone {
+			body = ""
+		}
+
+		footer, err := breakingFooter(cmd.Context(), client, g, vars, body, diff)
+		if err != nil {
+			return err
+		}
+		if footer != "" {
+			if prefix, subject, ok := strings.Cut(header, ": "); ok {
+				header = breakingPrefix(prefix) + ": " + subject
+			}
+		}
+		message = withFooter(strings.TrimSpace(header+"\n\n"+strings.TrimSpace(body)), footer)
+		message, err = translateMessage(cmd.Context(), client, vars, message)
+		if err != nil {
+			return err
+		}
+		// the trailers are added after the translation, they're kept as is
+		if trailers := git.CoAuthors(entries, g.ConfigValue("user.email")); len(trailers) > 0 {
+			message = strings.TrimSpace(message) + "\n\n" + strings.Join(trailers, "\n")
+		}
+		result.Message = strings.TrimSpace(newFormatter().Format(html.UnescapeString(message)))
+		if squashJiraComment {
+			if err := postJiraComment(cmd.Context(), g.BranchName(), result.Message); err != nil {
+				return err
+			}
+		}
+
+		if !squashApply {
+			if !isMachineOutput() {
+				fmt.Fprintln(os.Stdout, result.Message)
+			}
+			return nil
+		}
+
+		base, err := g.MergeBase(squashOnto, "HEAD")
+		if err != nil {
+			return err
+		}
+		if !isMachineOutput() {
+			color.Yellow("================Commit Summary====================")
+			color.Yellow("\n" + result.Message + "\n\n")
+			color.Yellow("==================================================")
+		}
+		logger.Info(fmt.Sprintf("Squash the %d commits of the branch onto %s", len(entries), base[:7]))
+		if err := g.SoftReset(base); err != nil {
+			return err
+		}
+		output, err := g.Commit(result.Message)
+		if err != nil {
+			return fmt.Errorf("%w, restore the branch with: git reset --soft ORIG_HEAD", err)
+		}
+		color.Yellow(output)
+		return nil
+	},
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/release"
+	"github.com/appleboy/CodeGPT/standup"
+	"github.com/appleboy/CodeGPT/util"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	standupSince     string
+	standupUntil     string
+	standupAuthor    string
+	standupRepos     []string
+	standupNoSummary bool
+)
+
+func init() {
+	standupCmd.Flags().StringVar(&standupSince, "since", standup.Yesterday, "start of the digest: today, yesterday (the previous working day), week, or a date of git like 2024-05-01")
+	standupCmd.Flags().StringVar(&standupUntil, "until", "", "end of the digest, a date of git, default is now")
+	standupCmd.Flags().StringVar(&standupAuthor, "author", "me", "author of the commits, me for the user.email of every repository")
+	standupCmd.Flags().StringSliceVar(&standupRepos, "repos", nil, "folders of the repositories, default is standup.repos or the current repository")
+	standupCmd.Flags().BoolVar(&standupNoSummary, "no_summary", false, "list the commits instead of asking the model for the digest")
+	standupCmd.Flags().BoolVar(&notifyFlag, "notify", false, "post the digest to the Slack and Teams webhooks")
+	standupCmd.Flags().StringVar(&commitModel, "model", "gpt-3.5-turbo", "select openai model")
+	standupCmd.Flags().StringVar(&commitLang, "lang", "en", "summarizing language uses English by default")
+	standupCmd.Flags().IntVar(&maxTokens, "max_tokens", 300, "the maximum number of tokens to generate in the chat completion.")
+	standupCmd.Flags().DurationVarP(&timeout, "timeout", "t", 0, "timeout of every request to the provider, default is openai.timeout")
+	standupCmd.Flags().DurationVar(&connectTimeout, "connect_timeout", 0, "timeout of the connection to the provider, default is openai.connect_timeout")
+}
+
+var standupCmd = &cobra.Command{
+	Use:   "standup",
+	Short: "Summarize your commits across repositories for the standup notes",
+	Long: `Summarize the commits of an author in all the branches of one or more repositories into a
+short digest for the notes of the daily or weekly standup meetings. By default, the digest
+covers your commits in the current repository since the start of the previous working day,
+so the digest of Monday covers the work of Friday. Use --since week for the weekly meetings.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := check(); err != nil {
+			return err
+		}
+
+		repos := standupRepos
+		if len(repos) == 0 {
+			repos = viper.GetStringSlice("standup.repos")
+		}
+		if len(repos) == 0 {
+			repos = []string{"."}
+		}
+		since := standup.Since(standupSince, time.Now())
+
+		g := git.New()
+		var digest []standup.Repo
+		for _, dir := range repos {
+			r, err := standupRepo(g, dir, since)
+			if err != nil {
+				return err
+			}
+			digest = append(digest, r)
+		}
+
+		n := standup.Count(digest)
+		if n == 0 {
+			logger.Info("There are no commits since " + standupSince)
+			return nil
+		}
+		logger.Info(fmt.Sprintf("Found %d commits since %s", n, standupSince))
+
+		result.Message = standup.Render(digest)
+		if !standupNoSummary {
+			summary, err := standupSummary(cmd.Context(), g, digest)
+			if err != nil {
+				return err
+			}
+			result.Message = summary + "\n"
+		}
+
+		if notifyFlag {
+			title := "Standup since " + standupSince
+			if standupAuthor != "me" {
+				title += " of " + standupAuthor
+			}
+			if err := sendNotification(cmd.Context(), title, result.Message); err != nil {
+				return err
+			}
+		}
+
+		if !isMachineOutput() {
+			fmt.Fprint(os.Stdout, result.Message)
+		}
+		return nil
+	},
+}
+
+// standupRepo returns the repository of the folder with the commits of the author since the date.
+func standupRepo(g *git.Command, dir, since string) (standup.Repo, error) {
+	author := standupAuthor
+	if author == "me" {
+		email := g.UserEmail(dir)
+		if email == "" {
+			return standup.Repo{}, errors.New("user.email isn't set in " + dir + ", set it or use --author")
+		}
+		// the author is a regular expression of git
+		author = regexp.QuoteMeta(email)
+	}
+	entries, err := g.AuthorLog(dir, author, since, standupUntil)
+	if err != nil {
+		return standup.Repo{}, err
+	}
+
+	name := dir
+	if abs, err := filepath.Abs(dir); err == nil {
+		name = filepath.Base(abs)
+	}
+	commits := make([]release.Commit, 0, len(entries))
+	for _, e := range entries {
+		commits = append(commits, release.ParseCommit(e.Hash, e.Message))
+	}
+	return standup.Repo{Name: name, Commits: standup.Dedupe(commits)}, nil
+}
+
+// standupSummary asks the model for the digest of the commits.
+func standupSummary(ctx context.Context, g *git.Command, digest []standup.Repo) (string, error) {
+	client, err := newClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	period := "since " + standupSince
+	if standupUntil != "" {
+		period += " until " + standupUntil
+	}
+	out, err := util.GetTemplateByString(
+		prompt.StandupDigestTemplate,
+		withVars(promptVars(g, nil), util.Data{
+			"period":  period,
+			"commits": standup.Render(digest),
+		}),
+	)
+	if err != nil {
+		return "", err
+	}
+	logger.Info("We are trying to write the standup digest")
+	resp, err := completion(ctx, client, out)
+	if err != nil {
+		return "", err
+	}
+	printUsage(ctx, resp.Usage)
+	return strings.TrimSpace(resp.Content), nil
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/openai"
+	"github.com/appleboy/CodeGPT/usage"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// The actions once the monthly budget is spent.
+const (
+	budgetBlock = "block"
+	budgetWarn  = "warn"
+)
+
+var (
+	statsBy   string
+	statsDays int
+)
+
+func init() {
+	statsCmd.Flags().StringVar(&statsBy, "by", "", "group the usage by model, day or repo, default shows all of them")
+	statsCmd.Flags().IntVar(&statsDays, "days", 0, "only show the usage of the last <n> days, default is all")
+}
+
+// ledger returns the usage ledger from the usage.file config, in the config folder by default.
+func ledger() (*usage.Ledger, error) {
+	return usage.NewLedger(ledgerFile()), nil
+}
+
+// ledgerFile returns the file of the usage ledger.
+func ledgerFile() string {
+	if file := viper.GetString("usage.file"); file != "" {
+		return file
+	}
+	return filepath.Join(configFolder, "usage.json")
+}
+
+// recordUsage adds the token usage of the result of the command, started at the given
+// time, to the usage ledger.
+func recordUsage(r *Result, start time.Time) {
+	if !viper.GetBool("usage.enable") || r.Usage.TotalTokens == 0 || r.Provider == openai.MOCK {
+		return
+	}
+
+	l, err := ledger()
+	if err != nil {
+		logger.Debug("can't open the usage ledger: " + err.Error())
+		return
+	}
+
+	// the repo is optional, the command may run outside of a git repository
+	repo, _ := git.New().TopLevel()
+	if err := l.Add(usage.Record{
+		Time:             start,
+		Command:          r.Command,
+		Provider:         r.Provider,
+		Model:            r.Model,
+		Repo:             repo,
+		PromptTokens:     r.Usage.PromptTokens,
+		CompletionTokens: r.Usage.CompletionTokens,
+		TotalTokens:      r.Usage.TotalTokens,
+	}); err != nil {
+		logger.Debug("can't record the usage: " + err.Error())
+	}
+}
+
+// budget returns the monthly budget of the config.
+func budget() usage.Budget {
+	return usage.Budget{
+		Tokens: viper.GetInt("usage.budget_tokens"),
+		Cost:   viper.GetFloat64("usage.budget_cost"),
+	}
+}
+
+// checkBudget warns or refuses to send the requests once the monthly budget is spent,
+// from the usage of the ledger, so a loop in CI can't run up the bill.
+func checkBudget() error {
+	b := budget()
+	if b == (usage.Budget{}) || !viper.GetBool("usage.enable") || result.Provider == openai.MOCK {
+		return nil
+	}
+
+	l, err := ledger()
+	if err != nil {
+		return nil
+	}
+	records, err := l.Records()
+	if err != nil {
+		logger.Debug("can't read the usage ledger: " + err.Error())
+		return nil
+	}
+
+	err = b.Exceeded(usage.Month(records, time.Now()))
+	if err != nil && viper.GetString("usage.budget_action") == budgetWarn {
+		logger.Warn(err.Error())
+		return nil
+	}
+	return err
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show the token usage and estimated cost per model, day and repo",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		groups := []string{usage.ByModel, usage.ByDay, usage.ByRepo}
+		if statsBy != "" {
+			if statsBy != usage.ByModel && statsBy != usage.ByDay && statsBy != usage.ByRepo {
+				return errors.New("by must be one of model, day or repo")
+			}
+			groups = []string{statsBy}
+		}
+
+		l, err := ledger()
+		if err != nil {
+			return err
+		}
+		records, err := l.Records()
+		if err != nil {
+			return err
+		}
+		month := usage.Month(records, time.Now())
+		if statsDays > 0 {
+			records = usage.Since(records, time.Now().AddDate(0, 0, -statsDays))
+		}
+
+		result.Stats = map[string][]usage.Summary{}
+		for _, by := range groups {
+			result.Stats[by] = usage.Summarize(records, by)
+		}
+		if isMachineOutput() {
+			return nil
+		}
+
+		if len(records) == 0 {
+			logger.Info("No usage recorded yet")
+			return nil
+		}
+
+		for i, by := range groups {
+			if i > 0 {
+				fmt.Println()
+			}
+			color.Cyan("Usage per " + by + " (" + period() + ")")
+			if err := usage.Render(os.Stdout, by, result.Stats[by]); err != nil {
+				return err
+			}
+		}
+
+		if b := budget(); b != (usage.Budget{}) {
+			fmt.Println()
+			color.Cyan("Budget of " + month.Key)
+			if b.Tokens > 0 {
+				fmt.Printf("%d of %d tokens\n", month.TotalTokens, b.Tokens)
+			}
+			if b.Cost > 0 {
+				fmt.Printf("$%.2f of $%.2f\n", month.Cost, b.Cost)
+			}
+		}
+		return nil
+	},
+}
+
+// period describes the time range of the stats.
+func period() string {
+	if statsDays > 0 {
+		return "last " + strconv.Itoa(statsDays) + " days"
+	}
+	return "all time"
+}
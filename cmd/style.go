@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/style"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	styleCommits int
+	styleSave    bool
+)
+
+func init() {
+	styleCmd.Flags().IntVar(&styleCommits, "commits", 0, "number of the last commits analyzed, default is style.commits")
+	styleCmd.Flags().BoolVar(&styleSave, "save", false, "write the settings matching the style to the .codegpt.yaml file of the repository")
+}
+
+// StyleResult is the style of the commits found by the style command and the settings
+// matching it.
+type StyleResult struct {
+	style.Report
+	Settings map[string]interface{} `json:"settings"`
+}
+
+// styleSettings returns the generation settings matching the style of the commits.
+// A convention is followed when most of the commits use it.
+func styleSettings(r style.Report) map[string]interface{} {
+	settings := map[string]interface{}{
+		"prompt.emoji":        r.Emoji >= 0.5,
+		"format.strip_period": r.Period < 0.5,
+		// the scopes are dropped only when the conventional commits almost never use them
+		"format.strip_scope": r.Conventional >= 0.5 && r.Scoped < 0.1,
+	}
+	if r.Tense != "" {
+		settings["prompt.tense"] = r.Tense
+	}
+	if r.Case != "" {
+		settings["format.case"] = r.Case
+	}
+	if r.Language != "" {
+		settings["output.lang"] = r.Language
+	}
+	return settings
+}
+
+// analyzeStyle returns the style of the last n commits of the repository.
+func analyzeStyle(g *git.Command, n int) (style.Report, error) {
+	subjects, err := g.RecentSubjects(n)
+	if err != nil {
+		return style.Report{}, err
+	}
+	return style.Analyze(subjects), nil
+}
+
+// learnStyle uses the style of the last style.commits commits of the repository as
+// the defaults of the generation settings when style.learn is set, the settings of the
+// configs, the environment and the flags still win.
+func learnStyle(g *git.Command) {
+	if !viper.GetBool("style.learn") {
+		return
+	}
+	r, err := analyzeStyle(g, viper.GetInt("style.commits"))
+	if err != nil {
+		logger.Debug("no commit history to learn the style: " + err.Error())
+		return
+	}
+	if r.Commits == 0 {
+		return
+	}
+	for key, val := range styleSettings(r) {
+		viper.SetDefault(key, val)
+	}
+	logger.Debug("learn the style of the last " + strconv.Itoa(r.Commits) + " commits")
+}
+
+var styleCmd = &cobra.Command{
+	Use:   "style analyze [--commits <n>] [--save]",
+	Short: "Analyze the conventions of the commit messages of the repository",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if args[0] != "analyze" {
+			return invalid(errors.New("only support the analyze command"))
+		}
+
+		n := viper.GetInt("style.commits")
+		if cmd.Flags().Changed("commits") {
+			n = styleCommits
+		}
+		if n <= 0 {
+			return invalid(errors.New("the number of commits must be positive"))
+		}
+		r, err := analyzeStyle(git.New(), n)
+		if err != nil {
+			return err
+		}
+		if r.Commits == 0 {
+			return errors.New("no commit to analyze")
+		}
+		settings := styleSettings(r)
+		result.Style = &StyleResult{Report: r, Settings: settings}
+
+		keys := make([]string, 0, len(settings))
+		for k := range settings {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		if !isMachineOutput() {
+			printStyle(r)
+			color.Cyan("\nSettings matching the style:")
+			for _, k := range keys {
+				fmt.Printf("  %s: %v\n", k, settings[k])
+			}
+		}
+
+		if !styleSave {
+			return nil
+		}
+		target, err := configTarget(true)
+		if err != nil {
+			return err
+		}
+		c, err := loadEditConfig(target)
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			c.Set(k, settings[k])
+		}
+		if err := c.Write(); err != nil {
+			return err
+		}
+		logger.Info("Write the settings of the style to " + target)
+		return nil
+	},
+}
+
+// printStyle prints the conventions of the report.
+func printStyle(r style.Report) {
+	percent := func(f float64) string {
+		return strconv.Itoa(int(f*100+0.5)) + "%"
+	}
+	color.Cyan("Style of the last %d commits:", r.Commits)
+	fmt.Println("  conventional commits:", percent(r.Conventional))
+	scopes := percent(r.Scoped) + " of the conventional commits"
+	if len(r.Scopes) > 0 {
+		scopes += ", like " + strings.Join(r.Scopes, ", ")
+	}
+	fmt.Println("  scopes:", scopes)
+	fmt.Println("  emojis:", percent(r.Emoji))
+	fmt.Println("  trailing period:", percent(r.Period))
+	if r.Tense != "" {
+		fmt.Println("  tense:", r.Tense)
+	}
+	if r.Case != "" {
+		fmt.Println("  first letter:", r.Case)
+	}
+	fmt.Println("  language:", prompt.GetLanguage(r.Language))
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/symbols"
+
+	"github.com/spf13/viper"
+)
+
+// maxSymbols is the maximum number of changed symbols listed in the prompts.
+const maxSymbols = 30
+
+// changedSymbols returns the functions, methods and types changed in the files, parsed
+// from their content before and after the changes. It's empty when prompt.symbols is
+// disabled, the files which can't be parsed are skipped.
+func changedSymbols(g *git.Command, files []string) []string {
+	if !viper.GetBool("prompt.symbols") {
+		return nil
+	}
+	var changes []symbols.Change
+	for _, f := range files {
+		if !symbols.Supported(f) {
+			continue
+		}
+		before, after := g.FileVersions(f)
+		c, err := symbols.Diff(f, before, after)
+		if err != nil {
+			logger.Debug("can't parse the symbols of " + f + ": " + err.Error())
+			continue
+		}
+		changes = append(changes, c...)
+	}
+	return symbols.Lines(changes, maxSymbols)
+}
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/redact"
+	"github.com/appleboy/CodeGPT/testgen"
+	"github.com/appleboy/CodeGPT/util"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var testWrite bool
+
+func init() {
+	testCmd.Flags().BoolVarP(&testWrite, "write", "w", false, "write the tests to the _test.go files instead of printing them")
+	testCmd.Flags().IntVar(&diffUnified, "diff_unified", 3, "generate diffs with <n> lines of context, default is 3")
+	testCmd.Flags().StringSliceVar(&excludeList, "exclude_list", []string{}, "exclude file from git diff command")
+	testCmd.Flags().StringVar(&commitModel, "model", "gpt-3.5-turbo", "select openai model")
+	testCmd.Flags().StringVar(&commitLang, "lang", "en", "summarizing language uses English by default")
+	testCmd.Flags().IntVar(&maxTokens, "max_tokens", 2000, "the maximum number of tokens to generate in the chat completion.")
+	testCmd.Flags().DurationVarP(&timeout, "timeout", "t", 0, "timeout of every request to the provider, default is openai.timeout")
+	testCmd.Flags().DurationVar(&connectTimeout, "connect_timeout", 0, "timeout of the connection to the provider, default is openai.connect_timeout")
+}
+
+// testTarget is a Go file with the lines changed by the diff, nil to test all its functions.
+type testTarget struct {
+	name  string
+	lines []int
+	diff  string
+}
+
+var testCmd = &cobra.Command{
+	Use:   "test [<file>|<diff>|-]",
+	Short: "Suggest table-driven Go tests for the changed functions",
+	Long: `Suggest table-driven Go tests for the functions changed by the staged diff, targeting
+the new and modified logic. The argument is a Go file to only test its changes, or all its
+functions when it has no staged change, a patch file, or - to read the diff from stdin.
+The tests are printed, or added to the _test.go files with --write.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := check(); err != nil {
+			return err
+		}
+
+		g := git.New(
+			git.WithDiffUnified(viper.GetInt("git.diff_unified")),
+			git.WithExcludeList(viper.GetStringSlice("git.exclude_list")),
+			git.WithMaxFileSize(viper.GetInt64("git.max_file_size")),
+		)
+		root, _ := g.TopLevel()
+		readFile := func(name string) ([]byte, error) {
+			return os.ReadFile(filepath.Join(root, name))
+		}
+		// the staged diff matches the content of the index, not of the working tree
+		read := func(name string) ([]byte, error) {
+			return g.ShowFile("", name)
+		}
+
+		var (
+			diff string
+			only string
+			err  error
+		)
+		switch {
+		case len(args) == 0:
+			diff, _, err = stagedDiff(g)
+		case args[0] == "-":
+			diff, _, err = readDiff(os.Stdin)
+			read = readFile
+		case strings.HasSuffix(args[0], ".go"):
+			only = patchName(root, args[0])
+			// without staged changes, all the functions of the file are tested
+			diff, _, _ = stagedDiff(g)
+		default:
+			var data []byte
+			data, err = os.ReadFile(args[0])
+			diff = string(data)
+			read = readFile
+		}
+		if err != nil {
+			return err
+		}
+
+		targets := testTargets(diff, only)
+		if only != "" && len(targets) == 0 {
+			targets = []testTarget{{name: only}}
+			read = readFile
+		}
+		if len(targets) == 0 {
+			return errors.New("there are no changed Go files to test")
+		}
+
+		client, err := newClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		var outputs []string
+		tested := 0
+		for _, t := range targets {
+			src, err := read(t.name)
+			if err != nil {
+				return err
+			}
+			funcs, err := testgen.ChangedFuncs(t.name, src, t.lines)
+			if err != nil {
+				return err
+			}
+			if len(funcs) == 0 {
+				continue
+			}
+			tested++
+			pkg, err := testgen.Package(src)
+			if err != nil {
+				return err
+			}
+			testFile := testgen.TestFile(t.name)
+			existing, _ := readFile(testFile)
+			funcs, err = redactFuncs(t.name, funcs)
+			if err != nil {
+				return err
+			}
+			fileDiff, err := redactDiff(t.diff)
+			if err != nil {
+				return err
+			}
+			testSource, err := redactText(testFile, string(existing))
+			if err != nil {
+				return err
+			}
+
+			out, err := util.GetTemplateByString(
+				prompt.UnitTestsTemplate,
+				withVars(promptVars(g, []string{t.name}), util.Data{
+					"package_name": pkg,
+					"file_name":    t.name,
+					"functions":    funcs,
+					"file_diffs":   fileDiff,
+					"test_file":    testSource,
+				}),
+			)
+			if err != nil {
+				return err
+			}
+			logger.Info(fmt.Sprintf("We are trying to write the tests of %d functions of %s", len(funcs), t.name))
+			resp, err := completion(cmd.Context(), client, out)
+			if err != nil {
+				return err
+			}
+			printUsage(cmd.Context(), resp.Usage)
+
+			code := []byte(testgen.Clean(resp.Content))
+			merged, skipped, err := testgen.Merge(existing, code)
+			if err != nil {
+				return fmt.Errorf("the tests of %s aren't valid Go code: %w", t.name, err)
+			}
+			if len(skipped) > 0 {
+				logger.Warn("Skip the tests already in " + testFile + ": " + strings.Join(skipped, ", "))
+			}
+			if !testWrite {
+				formatted, _, _ := testgen.Merge(nil, code)
+				outputs = append(outputs, "// "+testFile+"\n"+string(formatted))
+				continue
+			}
+			if string(merged) == string(existing) {
+				continue
+			}
+			if err := os.WriteFile(filepath.Join(root, testFile), merged, 0o644); err != nil {
+				return err
+			}
+			logger.Info("Write the tests to " + testFile)
+		}
+
+		if tested == 0 {
+			return errors.New("there are no changed Go functions to test")
+		}
+		if testWrite {
+			return nil
+		}
+		result.Message = strings.Join(outputs, "\n")
+		if !isMachineOutput() {
+			fmt.Fprint(os.Stdout, result.Message)
+		}
+		return nil
+	},
+}
+
+// redactFuncs replaces the secrets in the code of the functions with placeholders, the
+// redactions are reported at their line of the file.
+func redactFuncs(name string, funcs []testgen.Func) ([]testgen.Func, error) {
+	out := make([]testgen.Func, 0, len(funcs))
+	for _, f := range funcs {
+		code, err := redactContent(f.Code, name, func(r *redact.Redactor, code string) (string, []redact.Finding) {
+			code, findings := r.RedactText(code)
+			for i := range findings {
+				findings[i].File = name
+				findings[i].Line += f.Line - 1
+			}
+			return code, findings
+		})
+		if err != nil {
+			return nil, err
+		}
+		f.Code = code
+		out = append(out, f)
+	}
+	return out, nil
+}
+
+// testTargets returns the Go files changed by the diff with their changed lines, the
+// test files and the deleted files excluded. Only the file named only is kept, if set.
+func testTargets(diff, only string) []testTarget {
+	var targets []testTarget
+	for _, f := range git.ParsePatch(diff) {
+		name := f.Name()
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") ||
+			(only != "" && name != only) || slices.Contains(f.Header, "+++ /dev/null") {
+			continue
+		}
+		var lines []int
+		for _, h := range f.Hunks {
+			lines = append(lines, h.ChangedLines()...)
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		targets = append(targets, testTarget{
+			name:  name,
+			lines: lines,
+			diff:  git.FormatPatch([]*git.FilePatch{f}, true),
+		})
+	}
+	return targets
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/appleboy/CodeGPT/testgen"
+
+	"github.com/spf13/viper"
+)
+
+func TestRedactFuncs(t *testing.T) {
+	viper.Set("redact.enable", true)
+	t.Cleanup(func() { viper.Set("redact.enable", nil) })
+
+	src := []byte("package config\n\n" +
+		"func Token() string {\n" +
+		"\treturn \"q8Vz3Kp1Lx9Rt7Yw2Nm5Bc4Hd6Jf0Gs\"\n" +
+		"}\n\n" +
+		"func Region() string {\n" +
+		"\treturn \"us-east-1\"\n" +
+		"}\n")
+	funcs, err := testgen.ChangedFuncs("config.go", src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := redactFuncs("config.go", funcs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 2 {
+		t.Fatalf("redactFuncs() = %+v, want 2 functions", out)
+	}
+	if strings.Contains(out[0].Code, "q8Vz3Kp1Lx9Rt7Yw2Nm5Bc4Hd6Jf0Gs") ||
+		!strings.Contains(out[0].Code, "REDACTED_HIGH_ENTROPY_1") {
+		t.Errorf("redactFuncs() Token code = %q, want the secret redacted", out[0].Code)
+	}
+	if out[1].Code != funcs[1].Code {
+		t.Errorf("redactFuncs() Region code = %q, want %q", out[1].Code, funcs[1].Code)
+	}
+	if !strings.Contains(funcs[0].Code, "q8Vz3Kp1Lx9Rt7Yw2Nm5Bc4Hd6Jf0Gs") {
+		t.Error("redactFuncs() changed the functions of the caller")
+	}
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/tracing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// tracingShutdownTimeout bounds the export of the spans left when the command ends.
+const tracingShutdownTimeout = 5 * time.Second
+
+// rootSpan is the span of the running command.
+var rootSpan *tracing.Span
+
+// setupTracing enables the export of the spans when otel.endpoint, or the standard
+// OTEL_EXPORTER_OTLP_ENDPOINT variable, is set, and starts the span of the command.
+// The server starts a span per request instead.
+func setupTracing(cmd *cobra.Command) {
+	endpoint := viper.GetString("otel.endpoint")
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	}
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+	if endpoint == "" {
+		return
+	}
+
+	headers := map[string]string{}
+	list := viper.GetStringSlice("otel.headers")
+	if len(list) == 0 && os.Getenv("OTEL_EXPORTER_OTLP_HEADERS") != "" {
+		list = strings.Split(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"), ",")
+	}
+	for _, h := range list {
+		if k, v, ok := strings.Cut(h, "="); ok {
+			headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	service := viper.GetString("otel.service_name")
+	if service == "" {
+		service = os.Getenv("OTEL_SERVICE_NAME")
+	}
+
+	exporter, err := tracing.NewOTLPExporter(cmd.Context(), endpoint, tracing.WithHeaders(headers))
+	if err != nil {
+		logger.Warn("can't export the traces: " + err.Error())
+		return
+	}
+	tracing.Init(exporter, tracing.WithService(service, Version))
+	logger.Debug("export the traces to " + exporter.Endpoint())
+
+	if cmd == serveCmd {
+		return
+	}
+	ctx, span := tracing.Start(cmd.Context(), "codegpt "+cmd.Name())
+	rootSpan = span
+	cmd.SetContext(ctx)
+}
+
+// endTracing ends the span of the command with its error and exports the spans left.
+func endTracing(err error) {
+	rootSpan.SetError(err)
+	rootSpan.End()
+	ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+	defer cancel()
+	if err := tracing.Shutdown(ctx); err != nil {
+		logger.Warn("can't export the traces: " + err.Error())
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"errors"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/lint"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/util"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var translateWrite bool
+
+func init() {
+	translateCmd.Flags().StringVar(&commitModel, "model", "gpt-3.5-turbo", "select openai model")
+	translateCmd.Flags().StringVar(&commitLang, "lang", "en", "target language of the translation")
+	translateCmd.Flags().IntVar(&maxTokens, "max_tokens", 300, "the maximum number of tokens to generate in the chat completion.")
+	translateCmd.Flags().DurationVarP(&timeout, "timeout", "t", 0, "timeout of every request to the provider, default is openai.timeout")
+	translateCmd.Flags().DurationVar(&connectTimeout, "connect_timeout", 0, "timeout of the connection to the provider, default is openai.connect_timeout")
+	translateCmd.Flags().BoolVar(&translateWrite, "write", false, "write the translation back to .git/COMMIT_EDITMSG")
+}
+
+var translateCmd = &cobra.Command{
+	Use:   "translate [<commit>|-]",
+	Short: "Translate a commit message from COMMIT_EDITMSG, a commit or stdin",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := check(); err != nil {
+			return err
+		}
+
+		g := git.New()
+		var (
+			message    string
+			editMsg    string
+			err        error
+			fromCommit bool
+		)
+		switch {
+		case len(args) == 0:
+			dir, err := g.GitDir()
+			if err != nil {
+				return err
+			}
+			editMsg = filepath.Join(strings.TrimSpace(dir), "COMMIT_EDITMSG")
+			data, err := os.ReadFile(editMsg)
+			if err != nil {
+				return err
+			}
+			// the comments of git and the diff of commit --verbose aren't translated
+			message = lint.Clean(string(data))
+		case args[0] == "-":
+			data, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return err
+			}
+			message = string(data)
+		default:
+			fromCommit = true
+			message, err = g.CommitMessage(args[0])
+			if err != nil {
+				return err
+			}
+		}
+
+		message = strings.TrimSpace(util.NormalizeNewlines(message))
+		if message == "" {
+			return errors.New("the commit message is empty")
+		}
+		if translateWrite && editMsg == "" {
+			return errors.New("the --write flag only works with .git/COMMIT_EDITMSG")
+		}
+
+		lang := prompt.GetLanguage(viper.GetString("output.lang"))
+		out, err := util.GetTemplateByString(
+			prompt.TranslationTemplate,
+			withVars(promptVars(g, nil), util.Data{
+				"output_message": message,
+			}),
+		)
+		if err != nil {
+			return err
+		}
+
+		client, err := newClient(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		logger.Info("We are trying to translate a git commit message to " + lang + " language")
+		resp, err := completion(cmd.Context(), client, out)
+		if err != nil {
+			return err
+		}
+		printUsage(cmd.Context(), resp.Usage)
+
+		translation := strings.TrimSpace(html.UnescapeString(resp.Content))
+		result.Message = translation
+
+		if translateWrite {
+			logger.Info("Write the translation to " + editMsg + " file")
+			return os.WriteFile(editMsg, []byte(translation+"\n"), 0o644)
+		}
+		if fromCommit {
+			logger.Info("Use git commit --amend to replace the message of the last commit")
+		}
+
+		if !isMachineOutput() {
+			color.Yellow("==================Translation=====================")
+			color.Yellow("\n" + translation + "\n\n")
+			color.Yellow("==================================================")
+		}
+		return nil
+	},
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/appleboy/CodeGPT/compress"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/openai"
+	"github.com/appleboy/CodeGPT/truncate"
+
+	"github.com/spf13/viper"
+)
+
+// promptReserve is the number of tokens of the context window kept for the prompt
+// around the diff, like the instructions, the examples and the changed symbols.
+const promptReserve = 1024
+
+// diffBudget returns the maximum number of tokens of the diff in the prompts:
+// prompt.max_diff_tokens, or the context window of the model without the completion
+// and the rest of the prompt. It's zero when the context window of the model is
+// unknown, or negative when prompt.max_diff_tokens disables the truncation.
+func diffBudget() int {
+	if budget := viper.GetInt("prompt.max_diff_tokens"); budget != 0 {
+		return budget
+	}
+	window := openai.ContextWindow(currentModel())
+	if window == 0 {
+		return 0
+	}
+	return max(window-viper.GetInt("openai.max_tokens")-promptReserve, promptReserve)
+}
+
+// fitDiff compresses the diff by the prompt.compression percentage, then drops the
+// lowest-priority hunks when it doesn't fit the diff budget, instead of sending a diff
+// the model can't read. The savings and the omitted hunks are reported in the result
+// of the command, the omitted hunks are also listed at the end of the diff.
+func fitDiff(ctx context.Context, diff string) (string, error) {
+	if target := viper.GetInt("prompt.compression"); target > 0 {
+		out, report := compress.New(compress.WithCounter(openai.EstimateTokens)).Compress(diff, target)
+		if len(report.Passes) > 0 {
+			logger.Info("Compress the diff from " + report.String())
+			resultOf(ctx).Compression = &report
+			diff = out
+		}
+	}
+
+	budget := diffBudget()
+	if budget <= 0 {
+		return diff, nil
+	}
+	rules, err := truncate.ParseRules(viper.GetStringSlice("prompt.priority_rules"))
+	if err != nil {
+		return "", err
+	}
+
+	out, omitted := truncate.New(
+		truncate.WithRules(rules),
+		truncate.WithCounter(openai.EstimateTokens),
+	).Fit(diff, budget)
+	if len(omitted) == 0 {
+		return diff, nil
+	}
+
+	for _, o := range omitted {
+		logger.Debug("Omit " + o.String())
+	}
+	logger.Warn("The diff exceeds the " + strconv.Itoa(budget) + " tokens budget, omit the lowest-priority hunks of " +
+		strconv.Itoa(len(omitted)) + " file(s)")
+	r := resultOf(ctx)
+	r.Omitted = append(r.Omitted, omitted...)
+	return out, nil
+}
@@ -0,0 +1,459 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/prompt"
+	"github.com/appleboy/CodeGPT/tui"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// uiTones are the tones of the commit message the ui cycles through, the default tone first.
+var uiTones = append([]string{""}, prompt.Tones...)
+
+// uiModelsTimeout bounds the request of the model list of the model picker.
+const uiModelsTimeout = 10 * time.Second
+
+func init() {
+	uiCmd.Flags().StringVar(&commitModel, "model", "gpt-3.5-turbo", "select openai model")
+	uiCmd.Flags().StringVar(&commitLang, "lang", "en", "summarizing language uses English by default")
+	uiCmd.Flags().IntVar(&diffUnified, "diff_unified", 3, "generate diffs with <n> lines of context, default is 3")
+	uiCmd.Flags().StringSliceVar(&excludeList, "exclude_list", []string{}, "exclude file from git diff command")
+	uiCmd.Flags().IntVar(&maxTokens, "max_tokens", 300, "the maximum number of tokens to generate in the chat completion.")
+	uiCmd.Flags().DurationVarP(&timeout, "timeout", "t", 0, "timeout of every request to the provider, default is openai.timeout")
+	uiCmd.Flags().DurationVar(&connectTimeout, "connect_timeout", 0, "timeout of the connection to the provider, default is openai.connect_timeout")
+}
+
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Generate the commit message of the staged changes in a terminal user interface",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := requireInteractive("the ui command"); err != nil {
+			return err
+		}
+		if err := check(); err != nil {
+			return err
+		}
+		if isMachineOutput() {
+			return errors.New("the ui command can't be used with --output " + outputFormat)
+		}
+
+		g := git.New(
+			git.WithDiffUnified(viper.GetInt("git.diff_unified")),
+			git.WithExcludeList(viper.GetStringSlice("git.exclude_list")),
+			git.WithMaxFileSize(viper.GetInt64("git.max_file_size")),
+		)
+		defer g.Cleanup()
+		learnStyle(g)
+
+		diff, files, err := stagedDiff(g)
+		if err != nil {
+			return err
+		}
+		diff, err = redactDiff(diff)
+		if err != nil {
+			return err
+		}
+		diff, err = fitDiff(cmd.Context(), diff)
+		if err != nil {
+			return err
+		}
+		if _, err := newClient(cmd.Context()); err != nil {
+			return err
+		}
+
+		m := &uiModel{
+			ctx:   cmd.Context(),
+			g:     g,
+			diff:  diff,
+			lines: strings.Split(strings.TrimRight(diff, "\n"), "\n"),
+			files: files,
+		}
+		for i, tone := range uiTones {
+			if tone == viper.GetString("prompt.tone") {
+				m.tone = i
+			}
+		}
+		p := tui.NewProgram(m)
+		m.send = p.Send
+
+		// the progress messages would be drawn over the screen
+		out := color.Output
+		color.Output = io.Discard
+		final, err := p.Run(cmd.Context())
+		color.Output = out
+		if err != nil {
+			return err
+		}
+
+		m = final.(*uiModel)
+		if !m.commit {
+			logger.Info("Quit without committing")
+			return nil
+		}
+		result.Message = m.message
+
+		logger.Info("Git record changes to the repository")
+		if format := g.SigningFormat(); format != "" {
+			logger.Info("Sign the commit using " + format + " key, enter the passphrase if prompted")
+		}
+		output, err := g.Commit(m.message)
+		if err != nil {
+			return err
+		}
+		color.Yellow(output)
+		return nil
+	},
+}
+
+// The messages of the ui commands, gen is the generation they belong to.
+type (
+	uiStepMsg struct {
+		gen  int
+		step string
+	}
+	uiDeltaMsg struct {
+		gen  int
+		text string
+	}
+	uiDoneMsg struct {
+		gen      int
+		message  string
+		tokens   int
+		duration time.Duration
+		err      error
+	}
+	uiModelsMsg struct {
+		models []string
+	}
+)
+
+// uiModel is the state of the ui: the staged diff on one pane, the generated
+// message on the other, and the model picker.
+type uiModel struct {
+	ctx   context.Context
+	g     *git.Command
+	diff  string
+	lines []string
+	files []string
+	send  func(tui.Msg)
+
+	width  int
+	height int
+	scroll int
+
+	// the generation, the prompts read the config so it's only changed when idle
+	gen     int
+	busy    bool
+	cancel  context.CancelFunc
+	step    string
+	live    string
+	message string
+	status  string
+	failed  bool
+
+	tone   int
+	models []string
+	picker bool
+	cursor int
+
+	// commit the message once the terminal is restored
+	commit bool
+}
+
+func (m *uiModel) Init() tui.Cmd {
+	return m.generate(false)
+}
+
+// generate runs the prompts of the commit message in the background, the steps and
+// the generated text are sent to the ui while they come.
+func (m *uiModel) generate(refresh bool) tui.Cmd {
+	m.gen++
+	gen := m.gen
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.busy, m.cancel = true, cancel
+	m.step, m.live, m.failed = "", "", false
+	m.status = "Generate the commit message with " + viper.GetString("openai.model") + " model, press esc to cancel"
+	noCache = refresh
+
+	return func() tui.Msg {
+		defer cancel()
+		start := time.Now()
+		tokens := result.Usage.TotalTokens
+
+		client, err := newClient(ctx)
+		if err != nil {
+			return uiDoneMsg{gen: gen, err: err}
+		}
+		message, err := generateMessage(ctx, client, m.g, promptVars(m.g, m.files), m.diff, m.files, messageOptions{
+			step: func(step string) error {
+				m.send(uiStepMsg{gen: gen, step: step})
+				return nil
+			},
+			delta: func(text string) {
+				m.send(uiDeltaMsg{gen: gen, text: text})
+			},
+		})
+		return uiDoneMsg{
+			gen:      gen,
+			message:  message,
+			tokens:   result.Usage.TotalTokens - tokens,
+			duration: time.Since(start),
+			err:      err,
+		}
+	}
+}
+
+// loadModels lists the models of the provider for the picker.
+func (m *uiModel) loadModels() tui.Cmd {
+	m.busy = true
+	m.status = "List the models of the provider"
+	return func() tui.Msg {
+		ctx, cancel := context.WithTimeout(m.ctx, uiModelsTimeout)
+		defer cancel()
+		return uiModelsMsg{models: modelChoices(ctx)}
+	}
+}
+
+func (m *uiModel) Update(msg tui.Msg) (tui.Model, tui.Cmd) {
+	switch msg := msg.(type) {
+	case tui.ResizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case uiStepMsg:
+		if msg.gen == m.gen {
+			m.step, m.live = msg.step, ""
+		}
+	case uiDeltaMsg:
+		if msg.gen == m.gen {
+			m.live += msg.text
+		}
+	case uiDoneMsg:
+		m.busy = false
+		switch {
+		case errors.Is(msg.err, context.Canceled):
+			m.status = "Canceled, press r to regenerate"
+		case msg.err != nil:
+			m.status, m.failed = msg.err.Error(), true
+		default:
+			m.message = msg.message
+			m.status = "Generated in " + msg.duration.Round(100*time.Millisecond).String() +
+				" with " + strconv.Itoa(msg.tokens) + " tokens, press c to commit"
+		}
+	case uiModelsMsg:
+		m.busy = false
+		m.models, m.picker, m.cursor = msg.models, true, 0
+		for i, id := range msg.models {
+			if id == viper.GetString("openai.model") {
+				m.cursor = i
+			}
+		}
+		m.status = "Choose the model, press enter to select or esc to cancel"
+	case tui.KeyMsg:
+		if m.picker {
+			return m.pick(msg)
+		}
+		return m.key(msg)
+	}
+	return m, nil
+}
+
+// key handles the key bindings of the panes.
+func (m *uiModel) key(k tui.KeyMsg) (tui.Model, tui.Cmd) {
+	switch k.String() {
+	case "q", "ctrl+c":
+		if m.cancel != nil {
+			m.cancel()
+		}
+		return m, tui.Quit
+	case "esc":
+		if m.busy && m.cancel != nil {
+			m.cancel()
+		}
+	case "up", "k":
+		m.scroll--
+	case "down", "j":
+		m.scroll++
+	case "pgup":
+		m.scroll -= m.paneHeight()
+	case "pgdown", " ":
+		m.scroll += m.paneHeight()
+	case "home":
+		m.scroll = 0
+	case "end":
+		m.scroll = len(m.lines)
+	}
+	m.scroll = max(0, min(m.scroll, len(m.lines)-m.paneHeight()))
+
+	// the other bindings change the config or use the message, they wait for the generation
+	if m.busy {
+		return m, nil
+	}
+	switch k.String() {
+	case "r":
+		return m, m.generate(true)
+	case "t":
+		m.tone = (m.tone + 1) % len(uiTones)
+		viper.Set("prompt.tone", uiTones[m.tone])
+		return m, m.generate(false)
+	case "m":
+		return m, m.loadModels()
+	case "c":
+		if m.message != "" {
+			m.commit = true
+			return m, tui.Quit
+		}
+	}
+	return m, nil
+}
+
+// pick handles the key bindings of the model picker.
+func (m *uiModel) pick(k tui.KeyMsg) (tui.Model, tui.Cmd) {
+	switch k.String() {
+	case "q", "ctrl+c":
+		return m, tui.Quit
+	case "esc":
+		m.picker = false
+		m.status = "Keep the " + viper.GetString("openai.model") + " model"
+	case "up", "k":
+		m.cursor = max(0, m.cursor-1)
+	case "down", "j":
+		m.cursor = min(len(m.models)-1, m.cursor+1)
+	case "enter":
+		m.picker = false
+		if len(m.models) == 0 {
+			return m, nil
+		}
+		viper.Set("openai.model", m.models[m.cursor])
+		return m, m.generate(false)
+	}
+	return m, nil
+}
+
+// paneHeight is the number of lines of the panes, between the header and the status lines.
+func (m *uiModel) paneHeight() int {
+	if m.width < 100 {
+		return max(1, (m.height-4)/2)
+	}
+	return max(1, m.height-3)
+}
+
+func (m *uiModel) View(width, height int) string {
+	if width < 20 || height < 8 {
+		return "The terminal is too small"
+	}
+
+	header := " codegpt ui · " + viper.GetString("openai.model") + " · tone " + toneName(uiTones[m.tone]) +
+		" · " + strconv.Itoa(len(m.files)) + " files"
+	rows := []string{tui.Style(tui.Fit(header, width), tui.Reverse)}
+
+	h := m.paneHeight()
+	if width < 100 {
+		rows = append(rows, m.diffPane(width, h)...)
+		rows = append(rows, tui.Style(strings.Repeat("─", width), tui.Faint))
+		rows = append(rows, m.messagePane(width, height-3-h-1)...)
+	} else {
+		left := (width - 1) / 2
+		diff := m.diffPane(left, h)
+		msg := m.messagePane(width-left-1, h)
+		for i := 0; i < h; i++ {
+			rows = append(rows, diff[i]+tui.Style("│", tui.Faint)+msg[i])
+		}
+	}
+
+	status := tui.Fit(" "+m.status, width)
+	if m.failed {
+		status = tui.Style(status, tui.Red)
+	}
+	help := " r regenerate · t tone · m model · c commit · ↑/↓ scroll · esc cancel · q quit"
+	rows = append(rows, status, tui.Style(tui.Fit(help, width), tui.Faint))
+	return strings.Join(rows, "\n")
+}
+
+// diffPane renders the lines of the diff from the scroll position, colored like git.
+func (m *uiModel) diffPane(width, height int) []string {
+	rows := make([]string, 0, height)
+	for i := m.scroll; i < len(m.lines) && len(rows) < height; i++ {
+		line := m.lines[i]
+		cell := tui.Fit(line, width)
+		switch {
+		case strings.HasPrefix(line, "diff --git"):
+			cell = tui.Style(cell, tui.Bold)
+		case strings.HasPrefix(line, "@@"):
+			cell = tui.Style(cell, tui.Cyan)
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			cell = tui.Style(cell, tui.Bold)
+		case strings.HasPrefix(line, "+"):
+			cell = tui.Style(cell, tui.Green)
+		case strings.HasPrefix(line, "-"):
+			cell = tui.Style(cell, tui.Red)
+		}
+		rows = append(rows, cell)
+	}
+	for len(rows) < height {
+		rows = append(rows, tui.Fit("", width))
+	}
+	return rows
+}
+
+// messagePane renders the model picker, the text streamed by the current step or the message.
+func (m *uiModel) messagePane(width, height int) []string {
+	var rows []string
+	add := func(line string, attrs ...int) {
+		rows = append(rows, tui.Style(tui.Fit(" "+line, width), attrs...))
+	}
+
+	switch {
+	case m.picker:
+		add("Models", tui.Bold)
+		// keep the cursor in sight
+		first := max(0, m.cursor-(height-2))
+		for i := first; i < len(m.models) && len(rows) < height; i++ {
+			if i == m.cursor {
+				add("› "+m.models[i], tui.Reverse)
+				continue
+			}
+			add("  " + m.models[i])
+		}
+	case m.busy && m.step != "":
+		add("Generating the "+m.step+"…", tui.Bold, tui.Yellow)
+		lines := tui.Wrap(m.live, width-1)
+		// the last lines follow the streamed text
+		if len(lines) > height-1 {
+			lines = lines[len(lines)-(height-1):]
+		}
+		for _, line := range lines {
+			add(line)
+		}
+	default:
+		add("Commit message", tui.Bold)
+		for _, line := range tui.Wrap(m.message, width-1) {
+			add(line)
+		}
+	}
+
+	if len(rows) > height {
+		rows = rows[:height]
+	}
+	for len(rows) < height {
+		rows = append(rows, tui.Fit("", width))
+	}
+	return rows
+}
+
+// toneName returns the name of the tone shown in the header.
+func toneName(tone string) string {
+	if tone == "" {
+		return "default"
+	}
+	return tone
+}
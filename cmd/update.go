@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/release"
+	"github.com/appleboy/CodeGPT/update"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	updateChannel string
+	updateCheck   bool
+	updateForce   bool
+)
+
+func init() {
+	updateCmd.Flags().StringVar(&updateChannel, "channel", "", "release channel, stable or prerelease, default is update.channel")
+	updateCmd.Flags().BoolVar(&updateCheck, "check", false, "only check if a newer release is available")
+	updateCmd.Flags().BoolVar(&updateForce, "force", false, "install the latest release even if it isn't newer, like for a development build")
+}
+
+// Update is the release checked or installed by the update command.
+type Update struct {
+	Channel string `json:"channel"`
+	Current string `json:"current"`
+	Latest  string `json:"latest"`
+	URL     string `json:"url,omitempty"`
+	Updated bool   `json:"updated,omitempty"`
+}
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Update codegpt to the latest release, with its checksum verified",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		channel := viper.GetString("update.channel")
+		if cmd.Flags().Changed("channel") {
+			channel = updateChannel
+		}
+		if channel != update.Stable && channel != update.Prerelease {
+			return invalid(fmt.Errorf("invalid channel %q, use %s or %s", channel, update.Stable, update.Prerelease))
+		}
+
+		client, err := update.New(update.WithPublicKey(PublicKey))
+		if errors.Is(err, update.ErrNoPublicKey) {
+			return errors.New("this build has no release public key to verify the update, download the release from https://github.com/appleboy/CodeGPT/releases")
+		}
+		if err != nil {
+			return err
+		}
+		latest, err := client.Latest(cmd.Context(), channel)
+		if err != nil {
+			return err
+		}
+		result.Update = &Update{Channel: channel, Current: Version, Latest: latest.Tag, URL: latest.URL}
+
+		next, err := release.ParseVersion(latest.Tag)
+		if err != nil {
+			return fmt.Errorf("invalid version of the latest release: %w", err)
+		}
+		current, err := release.ParseVersion(Version)
+		switch {
+		case err != nil && !updateForce:
+			return errors.New("the version of the development build can't be compared, use --force to install " + latest.Tag)
+		case err == nil && next.Compare(current) <= 0 && !updateForce:
+			logger.Info("codegpt " + Version + " is up to date on the " + channel + " channel")
+			return nil
+		}
+
+		if updateCheck {
+			logger.Info("codegpt " + latest.Tag + " is available: " + latest.URL)
+			return nil
+		}
+
+		exe, err := os.Executable()
+		if err != nil {
+			return err
+		}
+		if exe, err = filepath.EvalSymlinks(exe); err != nil {
+			return err
+		}
+		// the package managers track their files, they update them on their own
+		if strings.Contains(filepath.ToSlash(exe), "/Cellar/") {
+			return errors.New("codegpt is installed by Homebrew, run `brew upgrade codegpt` instead")
+		}
+
+		name := update.AssetName(latest.Tag)
+		logger.Info("Download " + name + " of " + latest.Tag)
+		data, err := client.Download(cmd.Context(), latest, name)
+		if err != nil {
+			return err
+		}
+		if err := update.Replace(exe, data); err != nil {
+			return err
+		}
+		result.Update.Updated = true
+		logger.Info("codegpt is updated to " + latest.Tag + ": " + exe)
+		return nil
+	},
+}
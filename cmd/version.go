@@ -9,12 +9,19 @@ import (
 var (
 	Version string = ""
 	Commit  string = ""
+	// PublicKey is the base64 Ed25519 public key verifying the releases installed by
+	// the update command, set at build time.
+	PublicKey string = ""
 )
 
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version number",
 	Run: func(cmd *cobra.Command, args []string) {
+		// the version is part of every JSON result
+		if outputFormat == outputJSON {
+			return
+		}
 		fmt.Println("version:", Version, "commit:", Commit)
 	},
 }
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"sync"
+
+	"github.com/appleboy/CodeGPT/git"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/workspace"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	// workspaces caches the workspaces of the repository, detected once per process
+	workspaces     []workspace.Workspace
+	workspacesOnce sync.Once
+)
+
+// changedWorkspace returns the workspace of the monorepo of the changed files and the
+// shared files of the repository root changed with them, like the lock files. It's nil
+// when git.workspaces is disabled or the files don't belong to one workspace.
+func changedWorkspace(files []string) (*workspace.Workspace, []string) {
+	if !viper.GetBool("git.workspaces") || len(files) == 0 {
+		return nil, nil
+	}
+	workspacesOnce.Do(func() {
+		root, err := git.New().TopLevel()
+		if err != nil {
+			return
+		}
+		workspaces, err = workspace.Detect(root)
+		if err != nil {
+			logger.Warn("can't detect the workspaces of the repository: " + err.Error())
+		}
+	})
+	return workspace.Of(files, workspaces)
+}
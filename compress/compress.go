@@ -0,0 +1,286 @@
+// Package compress reduces the number of tokens of a git diff before it's sent to the
+// model. The passes lose a little more information each: the hunks changing only the
+// whitespace are collapsed, the long literals like the base64 blobs are abbreviated,
+// and the unchanged context lines are stripped. They run in this order until the diff
+// is smaller by the target percentage.
+package compress
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/appleboy/CodeGPT/git"
+)
+
+// defaultLiteralLength is the default minimum length of the abbreviated literals.
+const defaultLiteralLength = 120
+
+// literalPrefix is the number of characters kept at the beginning of a literal.
+const literalPrefix = 32
+
+// The names of the passes in the report.
+const (
+	PassWhitespace = "whitespace-only hunks"
+	PassLiterals   = "long literals"
+	PassContext    = "context lines"
+)
+
+// Report is the result of the compression.
+type Report struct {
+	// Before and After are the number of tokens of the diff
+	Before int      `json:"before"`
+	After  int      `json:"after"`
+	Passes []string `json:"passes"`
+}
+
+// Saved returns the percentage of tokens saved.
+func (r Report) Saved() int {
+	if r.Before == 0 {
+		return 0
+	}
+	return (r.Before - r.After) * 100 / r.Before
+}
+
+// String describes the savings, like: 5200 -> 3400 tokens (-34%) with whitespace-only hunks, long literals
+func (r Report) String() string {
+	s := strconv.Itoa(r.Before) + " -> " + strconv.Itoa(r.After) + " tokens (-" + strconv.Itoa(r.Saved()) + "%)"
+	if len(r.Passes) > 0 {
+		s += " with " + strings.Join(r.Passes, ", ")
+	}
+	return s
+}
+
+// Compressor runs the compression passes.
+type Compressor struct {
+	cfg      *config
+	literals *regexp.Regexp
+}
+
+// New returns a compressor with the given options.
+func New(opts ...Option) *Compressor {
+	cfg := &config{
+		counter:       func(s string) int { return (len(s) + 3) / 4 },
+		literalLength: defaultLiteralLength,
+	}
+	for _, o := range opts {
+		o.apply(cfg)
+	}
+
+	n := strconv.Itoa(cfg.literalLength)
+	return &Compressor{
+		cfg: cfg,
+		// the long words, like the base64, hex or minified blobs, and the long quoted strings
+		literals: regexp.MustCompile(`[^\s"'` + "`" + `]{` + n + `,}|"[^"]{` + n + `,}"|'[^']{` + n + `,}'`),
+	}
+}
+
+// Compress runs the passes on the diff until it's smaller by the target percentage,
+// or all of them. The diff is returned as is when the target isn't positive.
+func (c *Compressor) Compress(diff string, target int) (string, Report) {
+	count := c.cfg.counter
+	report := Report{Before: count(diff), After: count(diff)}
+	if target <= 0 || report.Before == 0 {
+		return diff, report
+	}
+
+	patch, notes := git.SplitNotes(diff)
+	files := git.ParsePatch(patch)
+	passes := []struct {
+		name string
+		run  func(*git.FilePatch) bool
+	}{
+		{PassWhitespace, collapseWhitespace},
+		{PassLiterals, c.abbreviateLiterals},
+		// one line of context first, none after
+		{PassContext, func(f *git.FilePatch) bool { return stripContext(f, 1) }},
+		{PassContext, func(f *git.FilePatch) bool { return stripContext(f, 0) }},
+	}
+
+	out := diff
+	for _, p := range passes {
+		if report.Saved() >= target {
+			break
+		}
+		changed := false
+		for _, f := range files {
+			if p.run(f) {
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		out = git.FormatPatch(files, true) + notes
+		report.After = count(out)
+		if n := len(report.Passes); n == 0 || report.Passes[n-1] != p.name {
+			report.Passes = append(report.Passes, p.name)
+		}
+	}
+	return out, report
+}
+
+// collapseWhitespace replaces the lines of the hunks which only change the whitespace
+// with a note, it reports whether a hunk is collapsed.
+func collapseWhitespace(f *git.FilePatch) bool {
+	collapsed := false
+	for _, h := range f.Hunks {
+		var removed, added strings.Builder
+		changes := 0
+		for _, line := range h.Lines {
+			switch {
+			case strings.HasPrefix(line, "-"):
+				removed.WriteString(strings.Join(strings.Fields(line[1:]), ""))
+				changes++
+			case strings.HasPrefix(line, "+"):
+				added.WriteString(strings.Join(strings.Fields(line[1:]), ""))
+				changes++
+			}
+		}
+		if changes == 0 || removed.String() != added.String() {
+			continue
+		}
+		h.Lines = []string{`\ ` + strconv.Itoa(changes) + " lines changed, only the whitespace"}
+		collapsed = true
+	}
+	return collapsed
+}
+
+// abbreviateLiterals shortens the long literals of the lines of the hunks to their
+// beginning and their length, it reports whether a literal is abbreviated.
+func (c *Compressor) abbreviateLiterals(f *git.FilePatch) bool {
+	abbreviated := false
+	for _, h := range f.Hunks {
+		for i, line := range h.Lines {
+			if len(line) <= c.cfg.literalLength {
+				continue
+			}
+			short := c.literals.ReplaceAllStringFunc(line, func(s string) string {
+				// cut at the start of a character
+				end := literalPrefix
+				for end > 0 && !utf8.RuneStart(s[end]) {
+					end--
+				}
+				return s[:end] + "…(" + strconv.Itoa(len(s)) + " chars)"
+			})
+			if short != line {
+				h.Lines[i] = short
+				abbreviated = true
+			}
+		}
+	}
+	return abbreviated
+}
+
+// hunkHeader matches the ranges of a hunk header, like @@ -1,3 +4,5 @@ func main() {
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,\d+)? \+(\d+)(?:,\d+)? @@(.*)$`)
+
+// stripContext splits the hunks of the file into hunks with the given number of
+// context lines around the changes, with the line numbers of their headers updated.
+// It reports whether a context line is stripped.
+func stripContext(f *git.FilePatch, context int) bool {
+	stripped := false
+	var hunks []*git.Hunk
+	for _, h := range f.Hunks {
+		split, ok := splitHunk(h, context)
+		hunks = append(hunks, split...)
+		stripped = stripped || ok
+	}
+	f.Hunks = hunks
+	return stripped
+}
+
+// splitHunk returns the hunks of the changes of the hunk with the given number of
+// context lines, and whether a context line is stripped. The hunk is returned as is
+// when its header can't be parsed.
+func splitHunk(h *git.Hunk, context int) ([]*git.Hunk, bool) {
+	m := hunkHeader.FindStringSubmatch(h.Header)
+	if m == nil {
+		return []*git.Hunk{h}, false
+	}
+	oldLine, _ := strconv.Atoi(m[1])
+	newLine, _ := strconv.Atoi(m[2])
+	section := m[3]
+
+	// the line numbers before every line of the hunk
+	n := len(h.Lines)
+	olds, news := make([]int, n), make([]int, n)
+	var changes []int
+	for i, line := range h.Lines {
+		olds[i], news[i] = oldLine, newLine
+		switch {
+		case strings.HasPrefix(line, "-"):
+			oldLine++
+			changes = append(changes, i)
+		case strings.HasPrefix(line, "+"):
+			newLine++
+			changes = append(changes, i)
+		case strings.HasPrefix(line, `\`):
+		default:
+			oldLine++
+			newLine++
+		}
+	}
+	if len(changes) == 0 {
+		return []*git.Hunk{h}, false
+	}
+
+	// the ranges of the lines kept, the changes close to each other share their context
+	type span struct{ start, end int }
+	var spans []span
+	for _, i := range changes {
+		start, end := max(i-context, 0), min(i+context, n-1)
+		// the "\ No newline at end of file" lines go with the line before them
+		for end+1 < n && strings.HasPrefix(h.Lines[end+1], `\`) {
+			end++
+		}
+		if k := len(spans) - 1; k >= 0 && start <= spans[k].end+1 {
+			spans[k].end = max(spans[k].end, end)
+			continue
+		}
+		spans = append(spans, span{start, end})
+	}
+
+	kept := 0
+	hunks := make([]*git.Hunk, 0, len(spans))
+	for _, s := range spans {
+		lines := h.Lines[s.start : s.end+1]
+		kept += len(lines)
+		oldCount, newCount := 0, 0
+		for _, line := range lines {
+			switch {
+			case strings.HasPrefix(line, "-"):
+				oldCount++
+			case strings.HasPrefix(line, "+"):
+				newCount++
+			case strings.HasPrefix(line, `\`):
+			default:
+				oldCount++
+				newCount++
+			}
+		}
+		// an empty range starts at the line before it, like git diff -U0
+		oldStart, newStart := olds[s.start], news[s.start]
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		hunks = append(hunks, &git.Hunk{
+			Header:   "@@ -" + hunkRange(oldStart, oldCount) + " +" + hunkRange(newStart, newCount) + " @@" + section,
+			Lines:    lines,
+			Selected: h.Selected,
+		})
+	}
+	return hunks, kept < n
+}
+
+// hunkRange formats the range of a hunk header, the count is omitted when it's one.
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return strconv.Itoa(start)
+	}
+	return strconv.Itoa(start) + "," + strconv.Itoa(count)
+}
@@ -0,0 +1,146 @@
+package compress
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/appleboy/CodeGPT/git"
+)
+
+func TestSplitHunk(t *testing.T) {
+	h := &git.Hunk{
+		Header: "@@ -10,9 +10,9 @@ func main() {",
+		Lines: []string{
+			" a",
+			" b",
+			"-c",
+			"+C",
+			" d",
+			" e",
+			" f",
+			" g",
+			"+h",
+			" i",
+		},
+		Selected: true,
+	}
+
+	got, stripped := splitHunk(h, 1)
+	if !stripped {
+		t.Error("splitHunk() stripped = false")
+	}
+	want := []*git.Hunk{
+		{Header: "@@ -11,3 +11,3 @@ func main() {", Lines: []string{" b", "-c", "+C", " d"}, Selected: true},
+		{Header: "@@ -16,2 +16,3 @@ func main() {", Lines: []string{" g", "+h", " i"}, Selected: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitHunk(1) =\n%+v\nwant\n%+v", got, want)
+	}
+
+	got, _ = splitHunk(h, 0)
+	want = []*git.Hunk{
+		{Header: "@@ -12 +12 @@ func main() {", Lines: []string{"-c", "+C"}, Selected: true},
+		{Header: "@@ -16,0 +17 @@ func main() {", Lines: []string{"+h"}, Selected: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitHunk(0) =\n%+v\nwant\n%+v", got, want)
+	}
+	// the line numbers of the changes don't move
+	if lines := got[1].ChangedLines(); !reflect.DeepEqual(lines, []int{17}) {
+		t.Errorf("ChangedLines() = %v, want [17]", lines)
+	}
+
+	if got, stripped := splitHunk(h, 5); stripped || len(got) != 1 || len(got[0].Lines) != len(h.Lines) {
+		t.Errorf("splitHunk(5) = %+v, %v, want the hunk as is", got, stripped)
+	}
+}
+
+func TestSplitHunkNoNewline(t *testing.T) {
+	h := &git.Hunk{Header: "@@ -1,2 +1,2 @@", Lines: []string{" a", "-b", `\ No newline at end of file`, "+b"}}
+	got, _ := splitHunk(h, 0)
+	want := []*git.Hunk{{Header: "@@ -2 +2 @@", Lines: []string{"-b", `\ No newline at end of file`, "+b"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitHunk() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestCollapseWhitespace(t *testing.T) {
+	f := &git.FilePatch{Hunks: []*git.Hunk{
+		{Header: "@@ -1,2 +1,2 @@", Lines: []string{"-if (a){", "-  b()", "+if (a) {", "+\tb()"}},
+		{Header: "@@ -9 +9 @@", Lines: []string{"-a", "+b"}},
+	}}
+	if !collapseWhitespace(f) {
+		t.Error("collapseWhitespace() = false")
+	}
+	if got := f.Hunks[0].Lines; !reflect.DeepEqual(got, []string{`\ 4 lines changed, only the whitespace`}) {
+		t.Errorf("collapseWhitespace() lines = %q", got)
+	}
+	if got := f.Hunks[1].Lines; len(got) != 2 {
+		t.Errorf("collapseWhitespace() collapsed a real change: %q", got)
+	}
+}
+
+func TestAbbreviateLiterals(t *testing.T) {
+	blob := strings.Repeat("QUJD", 50)
+	f := &git.FilePatch{Hunks: []*git.Hunk{{Lines: []string{
+		`+const logo = "data:image/png;base64,` + blob + `"`,
+		"+short line",
+	}}}}
+	if !New().abbreviateLiterals(f) {
+		t.Fatal("abbreviateLiterals() = false")
+	}
+	want := `+const logo = "data:image/png;base64,QUJDQUJDQ…(224 chars)`
+	if got := f.Hunks[0].Lines[0]; got != want {
+		t.Errorf("abbreviateLiterals() = %q, want %q", got, want)
+	}
+	if got := f.Hunks[0].Lines[1]; got != "+short line" {
+		t.Errorf("abbreviateLiterals() = %q", got)
+	}
+}
+
+// diff returns a diff with a change in the middle of many context lines.
+func diff() string {
+	var b strings.Builder
+	b.WriteString("diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1,41 +1,41 @@\n")
+	for i := 0; i < 20; i++ {
+		b.WriteString(" context line of the file\n")
+	}
+	b.WriteString("-old\n+new\n")
+	for i := 0; i < 20; i++ {
+		b.WriteString(" context line of the file\n")
+	}
+	b.WriteString("\nBinary or large files (content omitted):\n- added image logo.png, +12KB\n")
+	return b.String()
+}
+
+func TestCompress(t *testing.T) {
+	c := New()
+	got, report := c.Compress(diff(), 50)
+	if report.Saved() < 50 || !reflect.DeepEqual(report.Passes, []string{PassContext}) {
+		t.Errorf("Compress() report = %+v, want the context lines stripped", report)
+	}
+	if !strings.Contains(got, "@@ -20,3 +20,3 @@\n context line of the file\n-old\n+new\n context line of the file\n") {
+		t.Errorf("Compress() = %s\nwant one line of context", got)
+	}
+	if !strings.HasSuffix(got, "\nBinary or large files (content omitted):\n- added image logo.png, +12KB\n") {
+		t.Errorf("Compress() = %s\nwant the notes kept", got)
+	}
+	if !strings.HasSuffix(report.String(), "%) with context lines") {
+		t.Errorf("String() = %q", report.String())
+	}
+
+	if got, report := c.Compress(diff(), 0); got != diff() || report.Before != report.After || report.Passes != nil {
+		t.Errorf("Compress() without target = %+v, want the diff as is", report)
+	}
+}
+
+func TestCompressFullTarget(t *testing.T) {
+	got, report := New().Compress(diff(), 100)
+	if !strings.Contains(got, "@@ -21 +21 @@\n-old\n+new\n") {
+		t.Errorf("Compress() = %s\nwant no context", got)
+	}
+	if report.After >= report.Before {
+		t.Errorf("Compress() report = %+v", report)
+	}
+}
@@ -0,0 +1,46 @@
+package compress
+
+// Option is an interface that specifies compression configuration options.
+type Option interface {
+	apply(*config)
+}
+
+// optionFunc is a type of function that can be used to implement the Option interface.
+// It takes a pointer to a config struct and modifies it.
+type optionFunc func(*config)
+
+// Ensure that optionFunc satisfies the Option interface.
+var _ Option = (*optionFunc)(nil)
+
+// The apply method of optionFunc type is implemented here to modify the config struct based on the function passed.
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithCounter returns an Option that sets the function counting the tokens of the text,
+// a character count divided by four by default.
+func WithCounter(val func(string) int) Option {
+	return optionFunc(func(c *config) {
+		if val == nil {
+			return
+		}
+		c.counter = val
+	})
+}
+
+// WithLiteralLength returns an Option that sets the minimum length of the literals
+// abbreviated by the compression. Zero or negative values keep the default.
+func WithLiteralLength(val int) Option {
+	return optionFunc(func(c *config) {
+		if val <= 0 {
+			return
+		}
+		c.literalLength = val
+	})
+}
+
+// config is a struct that stores configuration options for the compression.
+type config struct {
+	counter       func(string) int
+	literalLength int
+}
@@ -0,0 +1,182 @@
+// Package deps finds the dependencies added, removed and updated by the changes of the
+// manifests and the lock files of the package managers, like go.mod, package-lock.json
+// or requirements.txt, from their content before and after the changes. The prompts get
+// a compact table of the versions instead of the noise of the lock files.
+package deps
+
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The actions of the changes.
+const (
+	Added   = "added"
+	Removed = "removed"
+	Updated = "updated"
+)
+
+// Change is a dependency added, removed or updated in a manifest or a lock file.
+type Change struct {
+	File   string `json:"file"`
+	Action string `json:"action"`
+	Name   string `json:"name"`
+	// Old and New are the versions, or the version constraints of the manifests,
+	// empty on the side where the dependency doesn't exist
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+// Version describes the versions of the change, like v1.6.0 → v1.7.0.
+func (c Change) Version() string {
+	switch c.Action {
+	case Added:
+		return c.New
+	case Removed:
+		return c.Old
+	}
+	return c.Old + " → " + c.New
+}
+
+// format is a file format of a package manager.
+type format struct {
+	parse func(content []byte) (map[string]string, error)
+	// lock is set for the lock files, their changes come after the ones of the manifests
+	lock bool
+	// other returns the content of the manifest without its dependencies, to find
+	// whether the changes of the file are only changes of the dependencies
+	other func(content []byte) (string, error)
+}
+
+// formatOf returns the format of the file, nil if it isn't supported.
+func formatOf(name string) *format {
+	base := path.Base(name)
+	switch base {
+	case "go.mod":
+		return &format{parse: parseGoMod, other: goModOther}
+	case "go.sum":
+		return &format{parse: parseGoSum, lock: true}
+	case "package.json":
+		return &format{parse: jsonSections(npmSections...), other: jsonOther(npmSections...)}
+	case "package-lock.json", "npm-shrinkwrap.json":
+		return &format{parse: parseNpmLock, lock: true}
+	case "yarn.lock":
+		return &format{parse: parseYarnLock, lock: true}
+	case "pnpm-lock.yaml":
+		return &format{parse: parsePnpmLock, lock: true}
+	case "composer.json":
+		return &format{parse: jsonSections(composerSections...), other: jsonOther(composerSections...)}
+	case "composer.lock":
+		return &format{parse: parseComposerLock, lock: true}
+	case "Pipfile.lock":
+		return &format{parse: parsePipfileLock, lock: true}
+	case "poetry.lock", "uv.lock", "Cargo.lock":
+		return &format{parse: parsePackageTables, lock: true}
+	case "Gemfile.lock":
+		return &format{parse: parseGemfileLock, lock: true}
+	}
+	if strings.HasPrefix(base, "requirements") && strings.HasSuffix(base, ".txt") {
+		// the requirements files only list dependencies
+		return &format{parse: parseRequirements, other: func([]byte) (string, error) { return "", nil }}
+	}
+	return nil
+}
+
+// isLock reports whether the file is a lock file.
+func isLock(name string) bool {
+	f := formatOf(name)
+	return f != nil && f.lock
+}
+
+// Supported reports whether the dependencies of the file can be found.
+func Supported(name string) bool {
+	return formatOf(name) != nil
+}
+
+// Diff returns the dependencies changed in the file from its content before and after
+// the changes, nil when it doesn't exist. only reports whether the changes of the file
+// are only changes of the dependencies, always for the lock files, so its diff can be
+// left out of the prompts.
+func Diff(name string, before, after []byte) (changes []Change, only bool, err error) {
+	f := formatOf(name)
+	if f == nil {
+		return nil, false, nil
+	}
+	old, err := f.parse(before)
+	if err != nil {
+		return nil, false, err
+	}
+	cur, err := f.parse(after)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for dep, version := range cur {
+		prev, ok := old[dep]
+		switch {
+		case !ok:
+			changes = append(changes, Change{File: name, Action: Added, Name: dep, New: version})
+		case prev != version:
+			changes = append(changes, Change{File: name, Action: Updated, Name: dep, Old: prev, New: version})
+		}
+	}
+	for dep, version := range old {
+		if _, ok := cur[dep]; !ok {
+			changes = append(changes, Change{File: name, Action: Removed, Name: dep, Old: version})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+
+	only = f.lock
+	if f.other != nil && before != nil && after != nil {
+		a, err := f.other(before)
+		if err != nil {
+			return nil, false, err
+		}
+		b, err := f.other(after)
+		if err != nil {
+			return nil, false, err
+		}
+		only = a == b
+	}
+	return changes, only, nil
+}
+
+// Table returns the markdown table of at most limit changes, the ones of the manifests
+// first. The changes of a lock file already listed for the manifest of its directory
+// are skipped, they only repeat the resolved versions. It's empty without changes.
+func Table(changes []Change, limit int) string {
+	sorted := make([]Change, len(changes))
+	copy(sorted, changes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return !isLock(sorted[i].File) && isLock(sorted[j].File)
+	})
+
+	listed := map[string]bool{}
+	var rows []Change
+	for _, c := range sorted {
+		key := path.Dir(c.File) + "\x00" + c.Name
+		if isLock(c.File) && listed[key] {
+			continue
+		}
+		listed[key] = true
+		rows = append(rows, c)
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("| File | Dependency | Change | Version |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	for i, c := range rows {
+		if limit > 0 && i == limit {
+			b.WriteString("| | and " + strconv.Itoa(len(rows)-limit) + " more | | |\n")
+			break
+		}
+		b.WriteString("| " + c.File + " | " + c.Name + " | " + c.Action + " | " + c.Version() + " |\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,269 @@
+package deps
+
+import (
+	"reflect"
+	"testing"
+)
+
+const oldGoMod = `module example.com/app
+
+go 1.22
+
+require (
+	github.com/spf13/cobra v1.6.1
+	github.com/old/lib v0.1.0 // indirect
+)
+
+require golang.org/x/net v0.20.0
+`
+
+const newGoMod = `module example.com/app
+
+go 1.22
+
+require (
+	github.com/spf13/cobra v1.7.0
+	github.com/new/lib v1.0.0
+)
+
+require golang.org/x/net v0.20.0
+`
+
+func TestDiffGoMod(t *testing.T) {
+	got, only, err := Diff("go.mod", []byte(oldGoMod), []byte(newGoMod))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Change{
+		{File: "go.mod", Action: Added, Name: "github.com/new/lib", New: "v1.0.0"},
+		{File: "go.mod", Action: Removed, Name: "github.com/old/lib", Old: "v0.1.0"},
+		{File: "go.mod", Action: Updated, Name: "github.com/spf13/cobra", Old: "v1.6.1", New: "v1.7.0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() =\n%+v\nwant\n%+v", got, want)
+	}
+	if !only {
+		t.Error("Diff() only = false, want true for the changes of the require directives")
+	}
+
+	_, only, err = Diff("go.mod", []byte(oldGoMod), []byte(oldGoMod+"\ntoolchain go1.23.1\n"))
+	if err != nil || only {
+		t.Errorf("Diff() only = %v, %v, want false for a new toolchain directive", only, err)
+	}
+}
+
+func TestDiffPackageJSON(t *testing.T) {
+	before := `{"name": "app", "scripts": {"test": "jest"}, "dependencies": {"react": "^18.2.0", "left-pad": "1.3.0"}}`
+	after := `{
+  "name": "app",
+  "scripts": {"test": "jest"},
+  "dependencies": {"react": "^18.3.1"},
+  "devDependencies": {"vitest": "^1.0.0"}
+}`
+	got, only, err := Diff("web/package.json", []byte(before), []byte(after))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Change{
+		{File: "web/package.json", Action: Removed, Name: "left-pad", Old: "1.3.0"},
+		{File: "web/package.json", Action: Updated, Name: "react", Old: "^18.2.0", New: "^18.3.1"},
+		{File: "web/package.json", Action: Added, Name: "vitest", New: "^1.0.0"},
+	}
+	if !reflect.DeepEqual(got, want) || !only {
+		t.Errorf("Diff() =\n%+v, %v\nwant\n%+v, true", got, only, want)
+	}
+
+	scripts := `{"name": "app", "scripts": {"test": "vitest"}, "dependencies": {"react": "^18.2.0", "left-pad": "1.3.0"}}`
+	if _, only, _ := Diff("package.json", []byte(before), []byte(scripts)); only {
+		t.Error("Diff() only = true, want false for a change of the scripts")
+	}
+	if _, _, err := Diff("package.json", []byte(before), []byte("{")); err == nil {
+		t.Error("Diff() error = nil, want the JSON error")
+	}
+}
+
+func TestParseLockFiles(t *testing.T) {
+	tests := []struct {
+		file    string
+		content string
+		want    map[string]string
+	}{
+		{
+			file:    "go.sum",
+			content: "github.com/a/b v1.0.0 h1:x=\ngithub.com/a/b v1.0.0/go.mod h1:y=\ngithub.com/c/d v0.9.0/go.mod h1:z=\n",
+			want:    map[string]string{"github.com/a/b": "v1.0.0"},
+		},
+		{
+			file: "package-lock.json",
+			content: `{"lockfileVersion": 3, "packages": {
+				"": {"name": "app"},
+				"node_modules/react": {"version": "18.3.1"},
+				"node_modules/@babel/core": {"version": "7.24.0"},
+				"node_modules/a/node_modules/b": {"version": "1.0.0"}}}`,
+			want: map[string]string{"react": "18.3.1", "@babel/core": "7.24.0"},
+		},
+		{
+			file:    "package-lock.json",
+			content: `{"lockfileVersion": 1, "dependencies": {"react": {"version": "16.0.0"}}}`,
+			want:    map[string]string{"react": "16.0.0"},
+		},
+		{
+			file: "yarn.lock",
+			content: `# yarn lockfile v1
+
+"@babel/core@^7.0.0", "@babel/core@^7.1.0":
+  version "7.24.0"
+  dependencies:
+    debug "^4.1.0"
+
+debug@^4.1.0:
+  version "4.3.4"
+`,
+			want: map[string]string{"@babel/core": "7.24.0", "debug": "4.3.4"},
+		},
+		{
+			file: "yarn.lock",
+			content: `__metadata:
+  version: 8
+
+"react@npm:^18.2.0":
+  version: 18.3.1
+  resolution: "react@npm:18.3.1"
+`,
+			want: map[string]string{"react": "18.3.1"},
+		},
+		{
+			file: "pnpm-lock.yaml",
+			content: `lockfileVersion: '9.0'
+packages:
+  react@18.3.1:
+    resolution: {integrity: sha512-x}
+  '@types/react@18.3.3':
+    resolution: {integrity: sha512-y}
+  react-dom@18.3.1(react@18.3.1):
+    resolution: {integrity: sha512-z}
+  /lodash/4.17.21:
+    resolution: {integrity: sha512-w}
+`,
+			want: map[string]string{"react": "18.3.1", "@types/react": "18.3.3", "react-dom": "18.3.1", "lodash": "4.17.21"},
+		},
+		{
+			file: "Cargo.lock",
+			content: `version = 3
+
+[[package]]
+name = "serde"
+version = "1.0.200"
+dependencies = [
+ "serde_derive",
+]
+
+[[package]]
+name = "syn"
+version = "1.0.109"
+
+[[package]]
+name = "syn"
+version = "2.0.60"
+
+[metadata]
+name = "other"
+`,
+			want: map[string]string{"serde": "1.0.200", "syn": "1.0.109, 2.0.60"},
+		},
+		{
+			file: "Gemfile.lock",
+			content: `GEM
+  remote: https://rubygems.org/
+  specs:
+    rails (7.1.3)
+      actionpack (= 7.1.3)
+    rack (3.0.9)
+
+PLATFORMS
+  ruby
+`,
+			want: map[string]string{"rails": "7.1.3", "rack": "3.0.9"},
+		},
+		{
+			file:    "composer.lock",
+			content: `{"packages": [{"name": "monolog/monolog", "version": "3.5.0"}], "packages-dev": [{"name": "phpunit/phpunit", "version": "10.5.0"}]}`,
+			want:    map[string]string{"monolog/monolog": "3.5.0", "phpunit/phpunit": "10.5.0"},
+		},
+		{
+			file:    "Pipfile.lock",
+			content: `{"default": {"requests": {"version": "==2.31.0"}}, "develop": {"pytest": {"version": "==8.0.0"}}}`,
+			want:    map[string]string{"requests": "2.31.0", "pytest": "8.0.0"},
+		},
+		{
+			file: "requirements-dev.txt",
+			content: `# tools
+-r requirements.txt
+--index-url https://example.com/simple
+Django==4.2.11
+requests[socks] >= 2.31, < 3  # http
+python_dateutil==2.9.0; python_version >= "3.8"
+black
+`,
+			want: map[string]string{"django": "4.2.11", "requests": ">= 2.31, < 3", "python-dateutil": "2.9.0", "black": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			got, err := formatOf(tt.file).parse([]byte(tt.content))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLockFile(t *testing.T) {
+	got, only, err := Diff("go.sum", nil, []byte("github.com/a/b v1.0.0 h1:x=\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Change{{File: "go.sum", Action: Added, Name: "github.com/a/b", New: "v1.0.0"}}
+	if !reflect.DeepEqual(got, want) || !only {
+		t.Errorf("Diff() = %+v, %v, want %+v, true", got, only, want)
+	}
+
+	got, only, err = Diff("main.go", nil, []byte("package main"))
+	if err != nil || got != nil || only {
+		t.Errorf("Diff() of an unsupported file = %+v, %v, %v", got, only, err)
+	}
+}
+
+func TestTable(t *testing.T) {
+	changes := []Change{
+		{File: "package-lock.json", Action: Updated, Name: "react", Old: "18.2.0", New: "18.3.1"},
+		{File: "package-lock.json", Action: Updated, Name: "scheduler", Old: "0.23.0", New: "0.23.2"},
+		{File: "package.json", Action: Updated, Name: "react", Old: "^18.2.0", New: "^18.3.1"},
+		{File: "go.mod", Action: Added, Name: "github.com/a/b", New: "v1.0.0"},
+		{File: "go.mod", Action: Removed, Name: "github.com/c/d", Old: "v0.1.0"},
+	}
+	want := `| File | Dependency | Change | Version |
+| --- | --- | --- | --- |
+| package.json | react | updated | ^18.2.0 → ^18.3.1 |
+| go.mod | github.com/a/b | added | v1.0.0 |
+| go.mod | github.com/c/d | removed | v0.1.0 |
+| package-lock.json | scheduler | updated | 0.23.0 → 0.23.2 |`
+	if got := Table(changes, 0); got != want {
+		t.Errorf("Table() =\n%s\nwant\n%s", got, want)
+	}
+
+	limited := `| File | Dependency | Change | Version |
+| --- | --- | --- | --- |
+| package.json | react | updated | ^18.2.0 → ^18.3.1 |
+| go.mod | github.com/a/b | added | v1.0.0 |
+| | and 2 more | | |`
+	if got := Table(changes, 2); got != limited {
+		t.Errorf("Table() =\n%s\nwant\n%s", got, limited)
+	}
+	if got := Table(nil, 10); got != "" {
+		t.Errorf("Table() = %q, want empty", got)
+	}
+}
@@ -0,0 +1,368 @@
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// versions collects the versions of the dependencies, a lock file can resolve several
+// versions of the same package.
+type versions map[string]map[string]bool
+
+func (v versions) add(name, version string) {
+	if name == "" {
+		return
+	}
+	if v[name] == nil {
+		v[name] = map[string]bool{}
+	}
+	v[name][version] = true
+}
+
+// result returns the versions of every dependency, joined by commas.
+func (v versions) result() map[string]string {
+	out := make(map[string]string, len(v))
+	for name, set := range v {
+		list := make([]string, 0, len(set))
+		for version := range set {
+			list = append(list, version)
+		}
+		sort.Strings(list)
+		out[name] = strings.Join(list, ", ")
+	}
+	return out
+}
+
+// lines calls fn with every line of the content.
+func lines(content []byte, fn func(line string)) {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fn(scanner.Text())
+	}
+}
+
+// goModRequire calls fn with the fields of every require directive of the go.mod file,
+// and other with the other lines.
+func goModRequire(content []byte, fn func(fields []string), other func(line string)) {
+	inBlock := false
+	lines(content, func(line string) {
+		code, _, _ := strings.Cut(line, "//")
+		fields := strings.Fields(code)
+		switch {
+		case inBlock && len(fields) == 1 && fields[0] == ")":
+			inBlock = false
+		case inBlock:
+			fn(fields)
+		case len(fields) == 2 && fields[0] == "require" && fields[1] == "(":
+			inBlock = true
+		case len(fields) > 0 && fields[0] == "require":
+			fn(fields[1:])
+		default:
+			other(strings.TrimSpace(line))
+		}
+	})
+}
+
+// parseGoMod returns the modules of the require directives of a go.mod file.
+func parseGoMod(content []byte) (map[string]string, error) {
+	out := map[string]string{}
+	goModRequire(content, func(fields []string) {
+		if len(fields) >= 2 {
+			out[fields[0]] = fields[1]
+		}
+	}, func(string) {})
+	return out, nil
+}
+
+// goModOther returns the go.mod file without its require directives.
+func goModOther(content []byte) (string, error) {
+	var b strings.Builder
+	goModRequire(content, func([]string) {}, func(line string) {
+		if line != "" {
+			b.WriteString(line + "\n")
+		}
+	})
+	return b.String(), nil
+}
+
+// parseGoSum returns the module versions of a go.sum file, without the go.mod only ones.
+func parseGoSum(content []byte) (map[string]string, error) {
+	v := versions{}
+	lines(content, func(line string) {
+		fields := strings.Fields(line)
+		if len(fields) == 3 && !strings.HasSuffix(fields[1], "/go.mod") {
+			v.add(fields[0], fields[1])
+		}
+	})
+	return v.result(), nil
+}
+
+// The sections of the dependencies of the JSON manifests.
+var (
+	npmSections      = []string{"dependencies", "devDependencies", "peerDependencies", "optionalDependencies"}
+	composerSections = []string{"require", "require-dev"}
+)
+
+// jsonSections returns the parser of the dependencies in the sections of a JSON manifest,
+// the first section wins for a package listed twice.
+func jsonSections(sections ...string) func([]byte) (map[string]string, error) {
+	return func(content []byte) (map[string]string, error) {
+		out := map[string]string{}
+		if len(bytes.TrimSpace(content)) == 0 {
+			return out, nil
+		}
+		var doc map[string]json.RawMessage
+		if err := json.Unmarshal(content, &doc); err != nil {
+			return nil, err
+		}
+		for _, s := range sections {
+			var list map[string]string
+			if raw, ok := doc[s]; !ok || json.Unmarshal(raw, &list) != nil {
+				continue
+			}
+			for name, version := range list {
+				if _, ok := out[name]; !ok {
+					out[name] = version
+				}
+			}
+		}
+		return out, nil
+	}
+}
+
+// jsonOther returns the function returning a JSON manifest without its sections of
+// dependencies, in a canonical form.
+func jsonOther(sections ...string) func([]byte) (string, error) {
+	return func(content []byte) (string, error) {
+		var doc map[string]interface{}
+		if err := json.Unmarshal(content, &doc); err != nil {
+			return "", err
+		}
+		for _, s := range sections {
+			delete(doc, s)
+		}
+		out, err := json.Marshal(doc)
+		return string(out), err
+	}
+}
+
+// parseNpmLock returns the packages installed at the top of node_modules by a
+// package-lock.json file, or the dependencies of the version 1 of the format.
+func parseNpmLock(content []byte) (map[string]string, error) {
+	out := map[string]string{}
+	if len(bytes.TrimSpace(content)) == 0 {
+		return out, nil
+	}
+	var lock struct {
+		Packages map[string]struct {
+			Version string `json:"version"`
+		} `json:"packages"`
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+	if len(lock.Packages) > 0 {
+		for key, p := range lock.Packages {
+			name, ok := strings.CutPrefix(key, "node_modules/")
+			if !ok || strings.Contains(name, "/node_modules/") {
+				continue
+			}
+			out[name] = p.Version
+		}
+		return out, nil
+	}
+	for name, p := range lock.Dependencies {
+		out[name] = p.Version
+	}
+	return out, nil
+}
+
+// parseYarnLock returns the resolved packages of a yarn.lock file, of the classic
+// format or of the YAML one of Yarn 2 and later.
+func parseYarnLock(content []byte) (map[string]string, error) {
+	v := versions{}
+	name := ""
+	lines(content, func(line string) {
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case !strings.HasPrefix(line, " "):
+			// "@babel/core@^7.0.0", "@babel/core@^7.1.0":
+			spec, _, _ := strings.Cut(strings.TrimSuffix(line, ":"), ",")
+			spec = strings.Trim(strings.TrimSpace(spec), `"`)
+			name = ""
+			if i := strings.Index(spec[min(1, len(spec)):], "@"); i >= 0 {
+				name = spec[:i+1]
+			}
+		case name != "":
+			field := strings.TrimSpace(line)
+			if version, ok := strings.CutPrefix(field, "version"); ok {
+				version = strings.Trim(strings.TrimSpace(strings.TrimPrefix(version, ":")), `"`)
+				v.add(name, version)
+				name = ""
+			}
+		}
+	})
+	return v.result(), nil
+}
+
+// parsePnpmLock returns the packages of a pnpm-lock.yaml file, their keys are like
+// /name/1.0.0 in the version 5 of the format, /name@1.0.0 in the version 6 and
+// name@1.0.0(peer@2.0.0) in the version 9.
+func parsePnpmLock(content []byte) (map[string]string, error) {
+	v := versions{}
+	var lock struct {
+		Packages map[string]interface{} `yaml:"packages"`
+	}
+	if err := yaml.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+	for key := range lock.Packages {
+		key, _, _ = strings.Cut(strings.TrimPrefix(key, "/"), "(")
+		if i := strings.Index(key[min(1, len(key)):], "@"); i >= 0 {
+			v.add(key[:i+1], key[i+2:])
+		} else if i := strings.LastIndex(key, "/"); i > 0 {
+			v.add(key[:i], key[i+1:])
+		}
+	}
+	return v.result(), nil
+}
+
+// parseComposerLock returns the packages of a composer.lock file.
+func parseComposerLock(content []byte) (map[string]string, error) {
+	out := map[string]string{}
+	if len(bytes.TrimSpace(content)) == 0 {
+		return out, nil
+	}
+	type pkg struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	var lock struct {
+		Packages    []pkg `json:"packages"`
+		PackagesDev []pkg `json:"packages-dev"`
+	}
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+	for _, p := range append(lock.Packages, lock.PackagesDev...) {
+		out[p.Name] = p.Version
+	}
+	return out, nil
+}
+
+// parsePipfileLock returns the packages of a Pipfile.lock file.
+func parsePipfileLock(content []byte) (map[string]string, error) {
+	out := map[string]string{}
+	if len(bytes.TrimSpace(content)) == 0 {
+		return out, nil
+	}
+	type pkg struct {
+		Version string `json:"version"`
+	}
+	var lock struct {
+		Default map[string]pkg `json:"default"`
+		Develop map[string]pkg `json:"develop"`
+	}
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, err
+	}
+	for _, section := range []map[string]pkg{lock.Default, lock.Develop} {
+		for name, p := range section {
+			if _, ok := out[name]; !ok {
+				out[name] = strings.TrimPrefix(p.Version, "==")
+			}
+		}
+	}
+	return out, nil
+}
+
+// tomlString matches a string field of a TOML table, like name = "serde".
+var tomlString = regexp.MustCompile(`^(name|version)\s*=\s*"([^"]*)"`)
+
+// parsePackageTables returns the packages of the [[package]] tables of the poetry.lock,
+// uv.lock and Cargo.lock files.
+func parsePackageTables(content []byte) (map[string]string, error) {
+	v := versions{}
+	inPackage := false
+	name, version := "", ""
+	flush := func() {
+		if inPackage {
+			v.add(name, version)
+		}
+		name, version = "", ""
+	}
+	lines(content, func(line string) {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			flush()
+			inPackage = line == "[[package]]"
+			return
+		}
+		if m := tomlString.FindStringSubmatch(line); inPackage && m != nil {
+			if m[1] == "name" {
+				name = m[2]
+			} else {
+				version = m[2]
+			}
+		}
+	})
+	flush()
+	return v.result(), nil
+}
+
+// gemSpec matches a gem of the specs of a Gemfile.lock file, the dependencies of the
+// gems are indented further.
+var gemSpec = regexp.MustCompile(`^    ([^\s(]+) \(([^)]+)\)$`)
+
+// parseGemfileLock returns the gems of a Gemfile.lock file.
+func parseGemfileLock(content []byte) (map[string]string, error) {
+	v := versions{}
+	lines(content, func(line string) {
+		if m := gemSpec.FindStringSubmatch(line); m != nil {
+			v.add(m[1], m[2])
+		}
+	})
+	return v.result(), nil
+}
+
+// requirement matches a requirement of a pip requirements file: the name, the extras
+// and the version specifier.
+var requirement = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(\[[^\]]*\])?\s*(.*)$`)
+
+// parseRequirements returns the packages of a pip requirements file with their version
+// specifiers, the pinned versions without ==. The options and the comments are skipped.
+func parseRequirements(content []byte) (map[string]string, error) {
+	out := map[string]string{}
+	lines(content, func(line string) {
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			return
+		}
+		m := requirement.FindStringSubmatch(line)
+		if m == nil {
+			return
+		}
+		// the names are case insensitive, and the dots and underscores are dashes
+		name := strings.NewReplacer("_", "-", ".", "-").Replace(strings.ToLower(m[1]))
+		spec, _, _ := strings.Cut(m[3], ";")
+		spec = strings.TrimSpace(spec)
+		if version, ok := strings.CutPrefix(spec, "=="); ok && !strings.ContainsAny(version, ",*") {
+			spec = strings.TrimSpace(version)
+		}
+		out[name] = spec
+	})
+	return out, nil
+}
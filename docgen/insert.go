@@ -0,0 +1,169 @@
+package docgen
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/format"
+)
+
+// commentWidth is the maximum width of the comment lines, without the indentation.
+const commentWidth = 80
+
+// ParseComments parses the JSON object of the comments by symbol name from the model
+// response. Markdown code fences and text around the object are ignored.
+func ParseComments(content string) (map[string]string, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, errors.New("no comments found in the response")
+	}
+
+	var comments map[string]string
+	if err := json.Unmarshal([]byte(content[start:end+1]), &comments); err != nil {
+		return nil, err
+	}
+	for name, text := range comments {
+		comments[name] = strings.TrimSpace(text)
+	}
+	return comments, nil
+}
+
+// Apply returns the source with the comments of the symbols inserted, in the comment
+// syntax of the language. The symbols without comment are left undocumented.
+func Apply(name string, src []byte, symbols []Symbol, comments map[string]string) []byte {
+	lang := Language(name)
+	inserts := map[int][]string{}
+	for _, s := range symbols {
+		text := comments[s.Name]
+		if text == "" {
+			continue
+		}
+		inserts[s.at] = append(inserts[s.at], render(lang, text, s.indent)...)
+	}
+	if len(inserts) == 0 {
+		return src
+	}
+
+	lines := strings.Split(string(src), "\n")
+	out := make([]string, 0, len(lines)+len(inserts)*3)
+	for i, line := range lines {
+		out = append(out, inserts[i]...)
+		out = append(out, line)
+	}
+	return []byte(strings.Join(out, "\n"))
+}
+
+// render returns the lines of the comment in the syntax of the language.
+func render(lang, text, indent string) []string {
+	body := strings.Split(format.Wrap(text, commentWidth-len(indent)-4), "\n")
+	var lines []string
+	switch lang {
+	case LangGo:
+		for _, l := range body {
+			lines = append(lines, strings.TrimRight(indent+"// "+l, " "))
+		}
+	case LangPython:
+		text = strings.ReplaceAll(strings.Join(body, "\n"), `"""`, `\"\"\"`)
+		body = strings.Split(text, "\n")
+		if len(body) == 1 {
+			return []string{indent + `"""` + body[0] + `"""`}
+		}
+		lines = append(lines, indent+`"""`+body[0])
+		for _, l := range body[1:] {
+			lines = append(lines, strings.TrimRight(indent+l, " \t"))
+		}
+		lines = append(lines, indent+`"""`)
+	default:
+		lines = append(lines, indent+"/**")
+		for _, l := range body {
+			lines = append(lines, strings.TrimRight(indent+" * "+strings.ReplaceAll(l, "*/", "*\\/"), " "))
+		}
+		lines = append(lines, indent+" */")
+	}
+	return lines
+}
+
+// contextLines is the number of unchanged lines around the changes of the patch.
+const contextLines = 3
+
+// Patch returns the unified diff of the lines inserted into the old content of the file,
+// empty when there's no change. The new content must contain all the old lines in order.
+func Patch(name string, old, new []byte) string {
+	if string(old) == string(new) {
+		return ""
+	}
+	// the last line without newline is marked like git does
+	noEOL := !strings.HasSuffix(string(old), "\n")
+	a := strings.Split(strings.TrimSuffix(string(old), "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(string(new), "\n"), "\n")
+
+	// added[j] reports whether the line j of the new content was inserted
+	added := make([]bool, len(b))
+	i := 0
+	for j := range b {
+		if i < len(a) && a[i] == b[j] {
+			i++
+			continue
+		}
+		added[j] = true
+	}
+
+	// oldLine[j] is the line of the old content before the new line j, starting at 0
+	oldLine := make([]int, len(b)+1)
+	for j, n := 0, 0; j < len(b); j++ {
+		oldLine[j] = n
+		if !added[j] {
+			n++
+		}
+	}
+
+	var hunks []string
+	for j := 0; j < len(b); {
+		if !added[j] {
+			j++
+			continue
+		}
+		start := max(j-contextLines, 0)
+		end := j
+		// extend the hunk while the next insertion is within the context lines
+		for k := j; k < len(b); k++ {
+			if added[k] {
+				end = k
+			} else if k-end > 2*contextLines {
+				break
+			}
+		}
+		stop := min(end+contextLines+1, len(b))
+
+		var body strings.Builder
+		oldCount, newCount := 0, 0
+		for k := start; k < stop; k++ {
+			if added[k] {
+				body.WriteString("+" + b[k] + "\n")
+			} else {
+				body.WriteString(" " + b[k] + "\n")
+				if k == len(b)-1 && noEOL {
+					body.WriteString("\\ No newline at end of file\n")
+				}
+				oldCount++
+			}
+			newCount++
+		}
+		hunks = append(hunks, fmt.Sprintf("@@ -%s +%s @@\n%s",
+			hunkRange(oldLine[start]+1, oldCount), hunkRange(start+1, newCount), body.String()))
+		j = stop
+	}
+
+	return "diff --git a/" + name + " b/" + name + "\n" +
+		"--- a/" + name + "\n" +
+		"+++ b/" + name + "\n" +
+		strings.Join(hunks, "")
+}
+
+// hunkRange formats the start and the number of lines of a hunk, like 10,4.
+func hunkRange(start, count int) string {
+	return fmt.Sprintf("%d,%d", start, count)
+}
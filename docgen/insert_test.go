@@ -0,0 +1,103 @@
+package docgen
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseComments(t *testing.T) {
+	got, err := ParseComments("```json\n{\"Run\": \" Run runs the task. \"}\n```")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]string{"Run": "Run runs the task."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseComments() = %q, want %q", got, want)
+	}
+	if _, err := ParseComments("no comment"); err == nil {
+		t.Error("ParseComments() expected an error")
+	}
+}
+
+func TestApplyAndPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      string
+		comments map[string]string
+		want     string
+	}{
+		{
+			name: "a/a.go",
+			src: `package a
+
+const (
+	A = 1
+)
+
+func Run() {}`,
+			comments: map[string]string{"A": "A is the first letter.", "Run": "Run runs the task."},
+			want: `diff --git a/a/a.go b/a/a.go
+--- a/a/a.go
++++ b/a/a.go
+@@ -1,7 +1,9 @@
+ package a
+ 
+ const (
++	// A is the first letter.
+ 	A = 1
+ )
+ 
++// Run runs the task.
+ func Run() {}
+\ No newline at end of file
+`,
+		},
+		{
+			name: "a.py",
+			src:  "def run(task):\n    return task\n",
+			comments: map[string]string{
+				"run": "Run the task and return it, the task is returned as is without being changed in any way.",
+			},
+			want: `diff --git a/a.py b/a.py
+--- a/a.py
++++ b/a.py
+@@ -1,2 +1,5 @@
+ def run(task):
++    """Run the task and return it, the task is returned as is without being
++    changed in any way.
++    """
+     return task
+`,
+		},
+		{
+			name:     "a.js",
+			src:      "export function run() {}\n",
+			comments: map[string]string{"run": "Run the task."},
+			want: `diff --git a/a.js b/a.js
+--- a/a.js
++++ b/a.js
+@@ -1,1 +1,4 @@
++/**
++ * Run the task.
++ */
+ export function run() {}
+`,
+		},
+		{
+			name:     "b.js",
+			src:      "export function run() {}\n",
+			comments: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			symbols, err := Find(tt.name, []byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := Apply(tt.name, []byte(tt.src), symbols, tt.comments)
+			if got := Patch(tt.name, []byte(tt.src), out); got != tt.want {
+				t.Errorf("Patch() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
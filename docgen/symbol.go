@@ -0,0 +1,309 @@
+package docgen
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// The languages of the source files.
+const (
+	LangGo         = "Go"
+	LangPython     = "Python"
+	LangJavaScript = "JavaScript"
+	LangTypeScript = "TypeScript"
+)
+
+// Language returns the language of the source file from its extension, empty if it isn't supported.
+func Language(name string) string {
+	switch filepath.Ext(name) {
+	case ".go":
+		return LangGo
+	case ".py":
+		return LangPython
+	case ".js", ".jsx", ".mjs", ".cjs":
+		return LangJavaScript
+	case ".ts", ".tsx", ".mts", ".cts":
+		return LangTypeScript
+	}
+	return ""
+}
+
+// Symbol is an exported symbol of a source file without documentation.
+type Symbol struct {
+	// Name is the name of the symbol, Type.Method for the methods
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	// Line is the line of the declaration, starting at 1
+	Line int `json:"line"`
+	// Code is the declaration, without the body of the functions
+	Code string `json:"code"`
+
+	// the comment is inserted before the line at, starting at 0, with the indentation
+	at     int
+	indent string
+}
+
+// generated matches the comment of the generated Go files.
+var generated = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+
+// Find returns the exported symbols of the source file without documentation, in order.
+// The Go test files and generated files are skipped.
+func Find(name string, src []byte) ([]Symbol, error) {
+	switch Language(name) {
+	case LangGo:
+		if strings.HasSuffix(name, "_test.go") || generated.Match(src) {
+			return nil, nil
+		}
+		return findGo(name, src)
+	case LangPython:
+		return findPython(src), nil
+	case LangJavaScript, LangTypeScript:
+		return findJS(src), nil
+	}
+	return nil, nil
+}
+
+func findGo(name string, src []byte) ([]Symbol, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(src), "\n")
+	symbol := func(name, kind string, pos, end token.Pos) Symbol {
+		line := fset.Position(pos).Line
+		code := strings.TrimSpace(string(src[fset.Position(pos).Offset:fset.Position(end).Offset]))
+		return Symbol{
+			Name:   name,
+			Kind:   kind,
+			Line:   line,
+			Code:   code,
+			at:     line - 1,
+			indent: indentOf(lines[line-1]),
+		}
+	}
+
+	var symbols []Symbol
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Doc != nil || !d.Name.IsExported() {
+				continue
+			}
+			end := d.End()
+			if d.Body != nil {
+				end = d.Body.Lbrace
+			}
+			if d.Recv == nil {
+				symbols = append(symbols, symbol(d.Name.Name, "func", d.Pos(), end))
+				continue
+			}
+			recv := receiverName(d.Recv)
+			if !ast.IsExported(recv) {
+				continue
+			}
+			symbols = append(symbols, symbol(recv+"."+d.Name.Name, "method", d.Pos(), end))
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT {
+				continue
+			}
+			grouped := d.Lparen.IsValid()
+			// the comment of a group documents its constants and variables
+			if d.Doc != nil && (!grouped || d.Tok != token.TYPE) {
+				continue
+			}
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if s.Doc != nil || s.Comment != nil || !s.Name.IsExported() {
+						continue
+					}
+					if grouped {
+						symbols = append(symbols, symbol(s.Name.Name, "type", s.Pos(), s.End()))
+					} else {
+						symbols = append(symbols, symbol(s.Name.Name, "type", d.Pos(), d.End()))
+					}
+				case *ast.ValueSpec:
+					if s.Doc != nil || s.Comment != nil || !s.Names[0].IsExported() {
+						continue
+					}
+					if grouped {
+						symbols = append(symbols, symbol(s.Names[0].Name, d.Tok.String(), s.Pos(), s.End()))
+					} else {
+						symbols = append(symbols, symbol(s.Names[0].Name, d.Tok.String(), d.Pos(), d.End()))
+					}
+				}
+			}
+		}
+	}
+	return symbols, nil
+}
+
+// receiverName returns the type name of the receiver of a method, like Command for (c *Command).
+func receiverName(recv *ast.FieldList) string {
+	if recv == nil || len(recv.List) == 0 {
+		return ""
+	}
+	t := recv.List[0].Type
+	for {
+		switch x := t.(type) {
+		case *ast.StarExpr:
+			t = x.X
+		case *ast.IndexExpr:
+			t = x.X
+		case *ast.IndexListExpr:
+			t = x.X
+		case *ast.Ident:
+			return x.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// pythonDef matches the definitions of the Python functions and classes.
+var pythonDef = regexp.MustCompile(`^(\s*)(?:async\s+)?(def|class)\s+([A-Za-z]\w*)`)
+
+// findPython returns the public functions and classes and the public methods of the
+// top-level classes without docstring. The nested functions are skipped.
+func findPython(src []byte) []Symbol {
+	lines := strings.Split(string(src), "\n")
+	var symbols []Symbol
+	inClass := false
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		indent := indentOf(line)
+		if indent == "" {
+			inClass = strings.HasPrefix(line, "class ")
+		}
+		m := pythonDef.FindStringSubmatch(line)
+		if m == nil || (indent != "" && !inClass) {
+			continue
+		}
+		// the methods of a class are one level deep only
+		if indent != "" && strings.Count(indent, " ")+strings.Count(indent, "\t")*4 > 4 {
+			continue
+		}
+
+		// the signature ends with the colon of the line closing the parentheses
+		end := i
+		for depth := 0; end < len(lines); end++ {
+			depth += strings.Count(lines[end], "(") - strings.Count(lines[end], ")")
+			if depth <= 0 {
+				break
+			}
+		}
+		// the one-line definitions, like def f(): pass, have no room for a docstring
+		if end == len(lines) || !strings.HasSuffix(strings.TrimSpace(stripComment(lines[end])), ":") {
+			i = end
+			continue
+		}
+
+		name := m[3]
+		if strings.HasPrefix(name, "_") {
+			i = end
+			continue
+		}
+		body := ""
+		bodyIndent := indent + "    "
+		for j := end + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) != "" {
+				body = strings.TrimSpace(lines[j])
+				if len(indentOf(lines[j])) > len(indent) {
+					bodyIndent = indentOf(lines[j])
+				}
+				break
+			}
+		}
+		if isDocstring(body) {
+			i = end
+			continue
+		}
+
+		kind := "function"
+		switch {
+		case m[2] == "class":
+			kind = "class"
+		case indent != "":
+			kind = "method"
+		}
+		symbols = append(symbols, Symbol{
+			Name:   name,
+			Kind:   kind,
+			Line:   i + 1,
+			Code:   strings.TrimSpace(strings.Join(lines[i:end+1], "\n")),
+			at:     end + 1,
+			indent: bodyIndent,
+		})
+		i = end
+	}
+	return symbols
+}
+
+// isDocstring reports whether the first statement of a Python body is a string literal.
+func isDocstring(line string) bool {
+	line = strings.TrimLeft(line, "rRuUbB")
+	return strings.HasPrefix(line, `"""`) || strings.HasPrefix(line, `'''`) ||
+		strings.HasPrefix(line, `"`) || strings.HasPrefix(line, `'`)
+}
+
+// stripComment removes the comment at the end of a Python line.
+func stripComment(line string) string {
+	if i := strings.Index(line, " #"); i >= 0 {
+		return line[:i]
+	}
+	return line
+}
+
+// jsExport matches the exported declarations of JavaScript and TypeScript.
+var jsExport = regexp.MustCompile(`^(\s*)export\s+(?:default\s+)?(?:declare\s+)?(?:async\s+)?(function\*?|abstract\s+class|class|const|let|var|interface|type|enum)\s+([A-Za-z_$][\w$]*)`)
+
+// findJS returns the exported declarations of a JavaScript or TypeScript file without JSDoc.
+func findJS(src []byte) []Symbol {
+	lines := strings.Split(string(src), "\n")
+	var symbols []Symbol
+	for i, line := range lines {
+		m := jsExport.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		// the decorators of a class come between the JSDoc and the declaration
+		at := i
+		for at > 0 && strings.HasPrefix(strings.TrimSpace(lines[at-1]), "@") {
+			at--
+		}
+		documented := false
+		for j := at - 1; j >= 0; j-- {
+			if prev := strings.TrimSpace(lines[j]); prev != "" {
+				documented = strings.HasSuffix(prev, "*/") || strings.HasPrefix(prev, "//")
+				break
+			}
+		}
+		if documented {
+			continue
+		}
+		kind := strings.Fields(m[2])[len(strings.Fields(m[2]))-1]
+		kind = strings.TrimSuffix(kind, "*")
+		symbols = append(symbols, Symbol{
+			Name:   m[3],
+			Kind:   kind,
+			Line:   i + 1,
+			Code:   strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), "{")),
+			at:     at,
+			indent: m[1],
+		})
+	}
+	return symbols
+}
+
+// indentOf returns the leading spaces and tabs of the line.
+func indentOf(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
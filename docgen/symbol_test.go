@@ -0,0 +1,101 @@
+package docgen
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			name: "a.go",
+			src: `package a
+
+type T struct{}
+
+// Documented is documented.
+func Documented() {}
+
+func (t *T) Run(x int) error {
+	return nil
+}
+
+func (h *hidden) Run() {}
+
+const (
+	A = 1
+	b = 2
+	C = 3 // documented
+)
+
+// Group documents its variables.
+var (
+	D = 4
+)
+`,
+			want: []string{"type T:3", "method T.Run:8", "const A:15"},
+		},
+		{
+			name: "a_test.go",
+			src:  "package a\n\nfunc TestRun(t *testing.T) {}\n",
+		},
+		{
+			name: "a.py",
+			src: `class Foo:
+    def bar(self, x,
+            y):
+        return x
+
+    def _private(self):
+        pass
+
+    def documented(self):
+        """Yes."""
+
+
+def top():
+    def nested():
+        pass
+
+def one(): pass
+`,
+			want: []string{"class Foo:1", "method bar:2", "function top:13"},
+		},
+		{
+			name: "a.ts",
+			src: `/** Documented. */
+export function a() {}
+
+@Component()
+export class B {}
+export const c = 1;
+const d = 2;
+`,
+			want: []string{"class B:5", "const c:6"},
+		},
+		{
+			name: "README.md",
+			src:  "export function a() {}\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			symbols, err := Find(tt.name, []byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, s := range symbols {
+				got = append(got, s.Kind+" "+s.Name+":"+strconv.Itoa(s.Line))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Find() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package format
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/appleboy/CodeGPT/prompt"
+)
+
+// DefaultBodyWidth is the width of the body lines recommended by git.
+const DefaultBodyWidth = 72
+
+// The cases of the first letter of the subject.
+const (
+	// CaseKeep leaves the subject as the model wrote it.
+	CaseKeep = ""
+	// CaseUpper capitalizes the first letter, like Add the format config.
+	CaseUpper = "upper"
+	// CaseLower lowers the first letter, like add the format config.
+	CaseLower = "lower"
+)
+
+// Cases lists the cases of the first letter of the subject.
+var Cases = []string{CaseUpper, CaseLower}
+
+// IsCase returns true if the case of the subject is supported.
+func IsCase(val string) bool {
+	for _, c := range Cases {
+		if c == val {
+			return true
+		}
+	}
+	return false
+}
+
+// prefix matches the conventional commit prefix of the header: type(scope)!:
+var prefix = regexp.MustCompile(`^\w+(?:\([^()]*\))?!?: `)
+
+// scope matches the scope of the conventional commit prefix, like (cli) of feat(cli):
+var scope = regexp.MustCompile(`\([^()]*\)`)
+
+// Formatter rewrites the commit messages returned by the model, so that they follow the
+// configured style whatever the model answers.
+type Formatter struct {
+	subjectMaxLength int
+	stripPeriod      bool
+	stripScope       bool
+	subjectCase      string
+	bodyWidth        int
+}
+
+// New returns a formatter with the given options.
+func New(opts ...Option) *Formatter {
+	f := &Formatter{
+		stripPeriod: true,
+		bodyWidth:   DefaultBodyWidth,
+	}
+	for _, opt := range opts {
+		opt.apply(f)
+	}
+	return f
+}
+
+// Format returns the message with the subject shortened, without trailing period, without
+// scope if configured and with its first letter in the configured case, and the body
+// lines wrapped.
+func (f *Formatter) Format(message string) string {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return message
+	}
+	subject, body, hasBody := strings.Cut(message, "\n")
+	subject = f.subject(strings.TrimSpace(subject))
+	if !hasBody {
+		return subject
+	}
+	return subject + "\n" + Wrap(body, f.bodyWidth)
+}
+
+// subject formats the first line of the message.
+func (f *Formatter) subject(subject string) string {
+	// the prefix like feat(cli): is left untouched, but its scope
+	head := prefix.FindString(subject)
+	title := strings.TrimPrefix(subject, head)
+	if f.stripScope {
+		head = scope.ReplaceAllString(head, "")
+	}
+
+	if f.stripPeriod {
+		title = strings.TrimRight(title, ".")
+	}
+	title = setCase(title, f.subjectCase)
+	return prompt.LimitSubject(head+title, f.subjectMaxLength)
+}
+
+// setCase changes the case of the first letter of the title. The title is kept
+// as is when lowering an acronym, like API or CLI.
+func setCase(title, c string) string {
+	r, size := utf8.DecodeRuneInString(title)
+	if r == utf8.RuneError {
+		return title
+	}
+	switch c {
+	case CaseUpper:
+		return string(unicode.ToUpper(r)) + title[size:]
+	case CaseLower:
+		if next, _ := utf8.DecodeRuneInString(title[size:]); unicode.IsUpper(next) {
+			return title
+		}
+		return string(unicode.ToLower(r)) + title[size:]
+	}
+	return title
+}
+
+// Wrap hard-wraps the lines of the body longer than width characters at the last space
+// that fits. The continuation lines of a list item are indented under its text. The code
+// blocks, the trailers and the words longer than the width, like the URLs, aren't wrapped.
+// The body is unchanged if width isn't positive.
+func Wrap(body string, width int) string {
+	if width <= 0 {
+		return body
+	}
+	var out []string
+	fenced := false
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			fenced = !fenced
+		}
+		if fenced || strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t") || isTrailer(line) {
+			out = append(out, line)
+			continue
+		}
+		out = append(out, wrapLine(line, width)...)
+	}
+	return strings.Join(out, "\n")
+}
+
+// listItem matches the marker of a list item, like "- ", "* " or "1. ".
+var listItem = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)]) `)
+
+// trailer matches the git trailers, like Signed-off-by: or Co-authored-by:.
+var trailer = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*: \S`)
+
+// isTrailer reports whether the line is a git trailer with a dash in its key,
+// the other keys are usually the start of a sentence.
+func isTrailer(line string) bool {
+	key, _, _ := strings.Cut(line, ":")
+	return trailer.MatchString(line) && strings.Contains(key, "-")
+}
+
+// Trailers returns the trailers of the message, like Signed-off-by: or Co-authored-by:,
+// the lines of its last paragraph when all of them are trailers.
+func Trailers(message string) []string {
+	paragraphs := strings.Split(strings.TrimSpace(strings.ReplaceAll(message, "\r\n", "\n")), "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+	lines := strings.Split(paragraphs[len(paragraphs)-1], "\n")
+	for _, line := range lines {
+		if !isTrailer(line) {
+			return nil
+		}
+	}
+	return lines
+}
+
+// wrapLine splits the line at the spaces so that every part fits the width.
+func wrapLine(line string, width int) []string {
+	if utf8.RuneCountInString(line) <= width {
+		return []string{line}
+	}
+	// the first line keeps the list marker, the next ones are aligned with its text
+	lead := listItem.FindString(line)
+	indent := strings.Repeat(" ", utf8.RuneCountInString(lead))
+	if lead == "" {
+		lead = line[:len(line)-len(strings.TrimLeft(line, " "))]
+		indent = lead
+	}
+
+	words := strings.Fields(line[len(lead):])
+	var lines []string
+	current := lead + words[0]
+	for _, word := range words[1:] {
+		if utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) <= width {
+			current += " " + word
+			continue
+		}
+		lines = append(lines, current)
+		current = indent + word
+	}
+	return append(lines, current)
+}
@@ -0,0 +1,121 @@
+package format
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		opts    []Option
+		want    string
+	}{
+		{name: "unchanged", message: "feat(cli): add the format config", want: "feat(cli): add the format config"},
+		{name: "trim", message: "\n fix: handle the empty diff \n\n", want: "fix: handle the empty diff"},
+		{name: "period", message: "fix: handle the empty diff.", want: "fix: handle the empty diff"},
+		{name: "periods", message: "Handle the empty diff...\n\nKeep the body.", want: "Handle the empty diff\n\nKeep the body."},
+		{
+			name:    "keep period",
+			message: "fix: handle the empty diff.",
+			opts:    []Option{WithStripPeriod(false)},
+			want:    "fix: handle the empty diff.",
+		},
+		{name: "upper", message: "fix!: handle the empty diff", opts: []Option{WithCase(CaseUpper)}, want: "fix!: Handle the empty diff"},
+		{name: "upper without prefix", message: "émettre le message", opts: []Option{WithCase(CaseUpper)}, want: "Émettre le message"},
+		{name: "lower", message: "fix(git): Handle the empty diff", opts: []Option{WithCase(CaseLower)}, want: "fix(git): handle the empty diff"},
+		{name: "strip scope", message: "feat(cli)!: add (the) format", opts: []Option{WithStripScope(true)}, want: "feat!: add (the) format"},
+		{name: "lower acronym", message: "docs: API usage", opts: []Option{WithCase(CaseLower)}, want: "docs: API usage"},
+		{
+			name:    "subject length",
+			message: "feat(cli): add the format config of the messages.\n\n- wrap the body",
+			opts:    []Option{WithSubjectMaxLength(30)},
+			want:    "feat(cli): add the format\n\n- wrap the body",
+		},
+		{
+			name: "wrap",
+			message: "feat: add the format config\n\n" +
+				"- hard-wrap the lines of the body at 72 characters, whatever the model returns in its answer\n" +
+				"Keep the short lines.",
+			want: "feat: add the format config\n\n" +
+				"- hard-wrap the lines of the body at 72 characters, whatever the model\n" +
+				"  returns in its answer\n" +
+				"Keep the short lines.",
+		},
+		{
+			name:    "no wrap",
+			message: "feat: add the format config\n\n- hard-wrap the lines of the body at 72 characters, whatever the model returns",
+			opts:    []Option{WithBodyWidth(0)},
+			want:    "feat: add the format config\n\n- hard-wrap the lines of the body at 72 characters, whatever the model returns",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := New(tt.opts...).Format(tt.message); got != tt.want {
+				t.Errorf("Format() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		width int
+		want  string
+	}{
+		{name: "short", body: "one two three", width: 20, want: "one two three"},
+		{name: "paragraph", body: "one two three four five", width: 10, want: "one two\nthree four\nfive"},
+		{name: "indent", body: "  one two three four", width: 10, want: "  one two\n  three\n  four"},
+		{name: "numbered", body: "1. one two three four", width: 13, want: "1. one two\n   three four"},
+		{name: "nested", body: "  * one two three", width: 10, want: "  * one\n    two\n    three"},
+		{name: "long word", body: "see https://example.com/a/very/long/path", width: 10, want: "see\nhttps://example.com/a/very/long/path"},
+		{name: "code", body: "    one two three four five", width: 10, want: "    one two three four five"},
+		{
+			name:  "fence",
+			body:  "```\none two three four five\n```\none two three",
+			width: 10,
+			want:  "```\none two three four five\n```\none two\nthree",
+		},
+		{
+			name:  "trailer",
+			body:  "Signed-off-by: A Very Long Name <a.very.long.name@example.com>",
+			width: 20,
+			want:  "Signed-off-by: A Very Long Name <a.very.long.name@example.com>",
+		},
+		{name: "sentence", body: "Note: one two three", width: 10, want: "Note: one\ntwo three"},
+		{name: "trailing spaces", body: "one  \n\ntwo", width: 10, want: "one\n\ntwo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Wrap(tt.body, tt.width); got != tt.want {
+				t.Errorf("Wrap() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrailers(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    []string
+	}{
+		{
+			name:    "trailers",
+			message: "fix: handle the empty diff\n\n- return early\n\nSigned-off-by: Dev <dev@example.com>\nCo-authored-by: Ops <ops@example.com>\n",
+			want:    []string{"Signed-off-by: Dev <dev@example.com>", "Co-authored-by: Ops <ops@example.com>"},
+		},
+		{name: "body", message: "fix: handle the empty diff\n\nNote: one two", want: nil},
+		{name: "subject", message: "Signed-off-by: Dev <dev@example.com>", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Trailers(tt.message); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Trailers() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package format
+
+// Option is an interface that specifies the options of the formatter.
+type Option interface {
+	apply(*Formatter)
+}
+
+// optionFunc is a type of function that can be used to implement the Option interface.
+type optionFunc func(*Formatter)
+
+// Ensure that optionFunc satisfies the Option interface.
+var _ Option = (*optionFunc)(nil)
+
+func (o optionFunc) apply(f *Formatter) {
+	o(f)
+}
+
+// WithSubjectMaxLength sets the maximum length of the subject, 0 disables the limit.
+func WithSubjectMaxLength(n int) Option {
+	return optionFunc(func(f *Formatter) {
+		f.subjectMaxLength = n
+	})
+}
+
+// WithStripPeriod sets whether the trailing periods of the subject are removed.
+func WithStripPeriod(strip bool) Option {
+	return optionFunc(func(f *Formatter) {
+		f.stripPeriod = strip
+	})
+}
+
+// WithStripScope sets whether the scope of the conventional commit prefix is removed,
+// like feat: for feat(cli):.
+func WithStripScope(strip bool) Option {
+	return optionFunc(func(f *Formatter) {
+		f.stripScope = strip
+	})
+}
+
+// WithCase sets the case of the first letter of the subject, CaseUpper or CaseLower.
+func WithCase(c string) Option {
+	return optionFunc(func(f *Formatter) {
+		f.subjectCase = c
+	})
+}
+
+// WithBodyWidth sets the width the body lines are wrapped at, 0 disables the wrapping.
+func WithBodyWidth(n int) Option {
+	return optionFunc(func(f *Formatter) {
+		f.bodyWidth = n
+	})
+}
@@ -0,0 +1,62 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+func (c *Command) authorLog(dir, author, since, until string) *exec.Cmd {
+	args := []string{
+		"-C",
+		dir,
+		"log",
+		// the commits of the feature branches too
+		"--all",
+		"--no-merges",
+		"--author=" + author,
+		"--since=" + since,
+		"--format=%x1e%H%x1f%an%x1f%ae%x1f%B",
+	}
+	if until != "" {
+		args = append(args, "--until="+until)
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// AuthorLog returns the commits of the author in all the branches of the repository of
+// the folder, made since the date and before the until date when it isn't empty, newest first.
+func (c *Command) AuthorLog(dir, author, since, until string) ([]LogEntry, error) {
+	output, err := c.authorLog(dir, author, since, until).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return parseLog(string(output)), nil
+}
+
+func (c *Command) userEmail(dir string) *exec.Cmd {
+	args := []string{
+		"-C",
+		dir,
+		"config",
+		"user.email",
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// UserEmail returns the user.email of the repository of the folder, empty if it isn't set.
+func (c *Command) UserEmail(dir string) string {
+	output, err := c.userEmail(dir).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,56 @@
+package git
+
+import (
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// ticketPattern matches issue tracker keys like ABC-123 in branch names.
+var ticketPattern = regexp.MustCompile(`(?i)(?:^|[^a-z0-9])([a-z][a-z0-9]+-[0-9]+)(?:[^0-9]|$)`)
+
+// issuePattern matches issue numbers like 123 in branch names like fix/123-crash or issue-123.
+var issuePattern = regexp.MustCompile(`(?:^|/)(?:issue-|gh-|#)?([0-9]+)(?:[-_]|$)`)
+
+// TicketID returns the ticket ID of the branch name, like ABC-123 for
+// feature/ABC-123-login or #42 for fix/42-crash, or an empty string.
+func TicketID(branch string) string {
+	if m := ticketPattern.FindStringSubmatch(branch); m != nil && !isIssuePrefix(m[1]) {
+		return strings.ToUpper(m[1])
+	}
+	if m := issuePattern.FindStringSubmatch(branch); m != nil {
+		return "#" + m[1]
+	}
+	return ""
+}
+
+// isIssuePrefix reports whether the match is a generic issue prefix like issue-123.
+func isIssuePrefix(s string) bool {
+	s = strings.ToLower(s)
+	return strings.HasPrefix(s, "issue-") || strings.HasPrefix(s, "gh-")
+}
+
+func (c *Command) branchName() *exec.Cmd {
+	args := []string{
+		"symbolic-ref",
+		"--short",
+		"-q",
+		"HEAD",
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// BranchName returns the name of the current branch, or an empty string
+// if HEAD is detached.
+func (c *Command) BranchName() string {
+	output, err := c.branchName().Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(output))
+}
@@ -0,0 +1,23 @@
+package git
+
+import "testing"
+
+func TestTicketID(t *testing.T) {
+	tests := []struct {
+		branch string
+		want   string
+	}{
+		{"feature/ABC-123-login", "ABC-123"},
+		{"proj-42_fix", "PROJ-42"},
+		{"fix/42-crash", "#42"},
+		{"issue-7", "#7"},
+		{"gh-15-docs", "#15"},
+		{"main", ""},
+		{"release/v1.2", ""},
+	}
+	for _, tt := range tests {
+		if got := TicketID(tt.branch); got != tt.want {
+			t.Errorf("TicketID(%q) = %q, want %q", tt.branch, got, tt.want)
+		}
+	}
+}
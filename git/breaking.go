@@ -0,0 +1,300 @@
+package git
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// BreakingChange is a change of the git diff which may break the users of the code,
+// like a removed exported identifier, a changed function signature or a deleted config key.
+type BreakingChange struct {
+	// Kind is either "removed" or "changed"
+	Kind string
+	// What is the kind of the identifier: func, method, type, var, const, member of a
+	// struct or an interface, or config key
+	What string
+	Name string
+	File string
+	// Signature is the new declaration of a changed identifier
+	Signature string
+}
+
+// String returns a one-line description like "removed func Parse in parser/parse.go".
+func (b BreakingChange) String() string {
+	s := b.Kind + " " + b.What + " " + b.Name + " in " + b.File
+	if b.Signature != "" {
+		s += ", now " + b.Signature
+	}
+	return s
+}
+
+// configKey matches the keys of the YAML, JSON, TOML, INI and .env files: key: value
+var configKey = regexp.MustCompile(`^(\s*)"?([A-Za-z_][\w.-]*)"?\s*[:=]`)
+
+// configExts are the extensions of the config files the deleted keys are looked for in.
+var configExts = map[string]bool{
+	".yaml": true, ".yml": true, ".json": true, ".toml": true, ".ini": true,
+	".env": true, ".properties": true, ".cfg": true, ".conf": true,
+}
+
+// declaration is an identifier of the package API or a config key.
+type declaration struct {
+	what      string
+	name      string
+	file      string
+	signature string
+}
+
+// DetectBreaking looks for the breaking changes of a unified diff: the identifiers of
+// the Go package APIs removed or with a changed declaration, and the keys deleted from
+// the config files. The Go files are parsed from their content before and after the
+// changes given by versions, they're skipped when it's nil. An identifier moved to
+// another file of the same package isn't reported. The tests, the internal and the main
+// packages are skipped.
+func DetectBreaking(diff string, versions func(file string) (before, after []byte)) []BreakingChange {
+	removed := map[string]declaration{}
+	var order []string
+	added := map[string]declaration{}
+	add := func(decls map[string]declaration, d declaration, old bool) {
+		key := d.key()
+		if _, found := decls[key]; found {
+			return
+		}
+		decls[key] = d
+		if old {
+			order = append(order, key)
+		}
+	}
+
+	file := ""
+	inHunk := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			file = (&FilePatch{Header: []string{line}}).Name()
+			inHunk = false
+			if path.Ext(file) == ".go" && versions != nil && trackedFile(file) {
+				before, after := versions(file)
+				oldAPI, err := goAPI(file, before)
+				if err != nil {
+					continue
+				}
+				newAPI, err := goAPI(file, after)
+				if err != nil {
+					continue
+				}
+				for _, d := range oldAPI {
+					add(removed, d, true)
+				}
+				for _, d := range newAPI {
+					add(added, d, false)
+				}
+			}
+			continue
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+			continue
+		case line == "":
+			// the diff is over, like the lists of the renamed or omitted files
+			inHunk = false
+			continue
+		case !inHunk || !trackedFile(file) || path.Ext(file) == ".go":
+			continue
+		}
+
+		if line[0] != '-' && line[0] != '+' {
+			continue
+		}
+		if d, ok := declareKey(file, line[1:]); ok {
+			if line[0] == '-' {
+				add(removed, d, true)
+			} else {
+				add(added, d, false)
+			}
+		}
+	}
+
+	var changes []BreakingChange
+	for _, key := range order {
+		old := removed[key]
+		d, ok := added[key]
+		switch {
+		case !ok:
+			// the members of a removed type are removed with it
+			if parent, _, found := strings.Cut(old.name, "."); found && old.what == "member" {
+				if _, kept := added[path.Dir(old.file)+" type "+parent]; !kept {
+					continue
+				}
+			}
+			changes = append(changes, BreakingChange{Kind: "removed", What: old.what, Name: old.name, File: old.file})
+		case d.signature != old.signature && old.what != "config key":
+			changes = append(changes, BreakingChange{
+				Kind: "changed", What: old.what, Name: old.name, File: d.file, Signature: d.signature,
+			})
+		}
+	}
+	return changes
+}
+
+// trackedFile reports whether the breaking changes of the file are looked for.
+func trackedFile(file string) bool {
+	if strings.HasSuffix(file, "_test.go") || strings.HasPrefix(file, "internal/") || strings.Contains(file, "/internal/") {
+		return false
+	}
+	return path.Ext(file) == ".go" || configExts[path.Ext(file)] || path.Base(file) == ".env"
+}
+
+// key identifies the declaration, the Go identifiers by package and the config keys by file.
+func (d declaration) key() string {
+	if d.what == "config key" {
+		return d.file + " " + d.name
+	}
+	return path.Dir(d.file) + " " + d.what + " " + d.name
+}
+
+// declareKey returns the top-level config key declared in the line of the config file.
+func declareKey(file, line string) (declaration, bool) {
+	m := configKey.FindStringSubmatch(line)
+	// the nested keys are too ambiguous without their parents
+	if m == nil || len(m[1]) > 2 {
+		return declaration{}, false
+	}
+	return declaration{what: "config key", name: m[2], file: file}, true
+}
+
+// goAPI returns the identifiers of the package API declared in the Go source: the
+// exported functions, types, variables and constants, the exported methods of the
+// exported types and the exported fields and methods of the exported structs and
+// interfaces. A missing file declares nothing, like the main packages.
+func goAPI(file string, src []byte) ([]declaration, error) {
+	if src == nil {
+		return nil, nil
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	if f.Name.Name == "main" {
+		return nil, nil
+	}
+	format := func(node any) string {
+		var buf bytes.Buffer
+		_ = printer.Fprint(&buf, fset, node)
+		return strings.Join(strings.Fields(buf.String()), " ")
+	}
+
+	var out []declaration
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			signature := format(&ast.FuncDecl{Recv: d.Recv, Name: d.Name, Type: d.Type})
+			if d.Recv == nil || len(d.Recv.List) == 0 {
+				out = append(out, declaration{what: "func", name: d.Name.Name, file: file, signature: signature})
+				continue
+			}
+			recv := receiverType(d.Recv.List[0].Type)
+			// the methods of the unexported types aren't part of the API
+			if !ast.IsExported(recv) {
+				continue
+			}
+			out = append(out, declaration{what: "method", name: recv + "." + d.Name.Name, file: file, signature: signature})
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if !s.Name.IsExported() {
+						continue
+					}
+					out = append(out, declaration{what: "type", name: s.Name.Name, file: file, signature: "type " + typeHeader(s, format)})
+					out = append(out, members(file, s, format)...)
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						if !name.IsExported() {
+							continue
+						}
+						// the value doesn't change the declaration, only the explicit type
+						v := declaration{what: d.Tok.String(), name: name.Name, file: file}
+						if s.Type != nil {
+							v.signature = format(s.Type)
+						}
+						out = append(out, v)
+					}
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+// members returns the exported fields of the struct and the methods of the interface,
+// named like Type.Field.
+func members(file string, s *ast.TypeSpec, format func(any) string) []declaration {
+	var list *ast.FieldList
+	switch t := s.Type.(type) {
+	case *ast.StructType:
+		list = t.Fields
+	case *ast.InterfaceType:
+		list = t.Methods
+	default:
+		return nil
+	}
+	var out []declaration
+	for _, field := range list.List {
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			signature := name.Name + " " + format(field.Type)
+			if _, ok := field.Type.(*ast.FuncType); ok {
+				signature = name.Name + strings.TrimPrefix(format(field.Type), "func")
+			}
+			out = append(out, declaration{what: "member", name: s.Name.Name + "." + name.Name, file: file, signature: signature})
+		}
+	}
+	return out
+}
+
+// typeHeader returns the type declaration without the fields of the structs and the
+// methods of the interfaces, like Config struct or ID = string.
+func typeHeader(t *ast.TypeSpec, format func(any) string) string {
+	header := t.Name.Name
+	if t.TypeParams != nil {
+		header = format(&ast.TypeSpec{Name: t.Name, TypeParams: t.TypeParams, Type: &ast.Ident{Name: "_"}})
+		header = strings.TrimSuffix(header, " _")
+	}
+	if t.Assign.IsValid() {
+		header += " ="
+	}
+	switch t.Type.(type) {
+	case *ast.StructType:
+		return header + " struct"
+	case *ast.InterfaceType:
+		return header + " interface"
+	}
+	return header + " " + format(t.Type)
+}
+
+// receiverType returns the type name of the method receiver, like T for *T[K].
+func receiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.IndexListExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
@@ -0,0 +1,218 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+// goDiff returns the header of the diff of the Go files, their content is given by the
+// versions of the test.
+func goDiff(files ...string) string {
+	diff := ""
+	for _, f := range files {
+		diff += "diff --git a/" + f + " b/" + f + "\n--- a/" + f + "\n+++ b/" + f + "\n@@ -1 +1 @@\n"
+	}
+	return diff
+}
+
+func TestDetectBreaking(t *testing.T) {
+	tests := []struct {
+		name   string
+		diff   string
+		before map[string]string
+		after  map[string]string
+		want   []string
+	}{
+		{
+			name: "removed func",
+			diff: goDiff("parser/parse.go"),
+			before: map[string]string{"parser/parse.go": `package parser
+
+// Parse parses the input.
+func Parse(s string) error {
+	return nil
+}
+
+func parse(s string) error { return nil }
+`},
+			after: map[string]string{"parser/parse.go": `package parser
+
+func parse(s string) error { return nil }
+`},
+			want: []string{"removed func Parse in parser/parse.go"},
+		},
+		{
+			name: "changed signature",
+			diff: goDiff("git/git.go"),
+			before: map[string]string{"git/git.go": `package git
+
+type Command struct{}
+
+func (c *Command) Commit(val string) error {
+	return nil
+}
+`},
+			after: map[string]string{"git/git.go": `package git
+
+import "context"
+
+type Command struct{}
+
+func (c *Command) Commit(ctx context.Context, val string) error {
+	return nil
+}
+`},
+			want: []string{"changed method Command.Commit in git/git.go, now func (c *Command) Commit(ctx context.Context, val string) error"},
+		},
+		{
+			name: "unexported receiver",
+			diff: goDiff("api/client.go"),
+			before: map[string]string{"api/client.go": `package api
+
+type client struct{}
+
+func (c *client) Do(url string) error { return nil }
+`},
+			after: map[string]string{"api/client.go": `package api
+
+type client struct{}
+
+func (c *client) Do(url string, retries int) error { return nil }
+`},
+		},
+		{
+			name: "renamed type and changed field",
+			diff: goDiff("api/api.go"),
+			before: map[string]string{"api/api.go": `package api
+
+type Request struct {
+	Token string
+	URL   string
+}
+
+type Options struct {
+	Retries int
+	Debug   bool
+	secret  string
+}
+`},
+			after: map[string]string{"api/api.go": `package api
+
+type Req struct {
+	Token []byte // the raw token
+	URL   string
+}
+
+type Options struct {
+	Retries uint
+	secret  []byte
+}
+`},
+			want: []string{
+				"removed type Request in api/api.go",
+				"changed member Options.Retries in api/api.go, now Retries uint",
+				"removed member Options.Debug in api/api.go",
+			},
+		},
+		{
+			name: "moved to another file",
+			diff: goDiff("util/a.go", "util/b.go"),
+			before: map[string]string{"util/a.go": `package util
+
+func Join(a, b string) string {
+	return a + b
+}
+`, "util/b.go": "package util\n"},
+			after: map[string]string{"util/a.go": "package util\n", "util/b.go": `package util
+
+func Join(a, b string) string {
+	return a + b
+}
+`},
+		},
+		{
+			name: "function body and constant value",
+			diff: goDiff("openai/models.go"),
+			before: map[string]string{"openai/models.go": `package openai
+
+const (
+	DefaultModel = "gpt-3.5-turbo"
+)
+
+func Register() {
+	Default(DefaultModel)
+}
+`},
+			after: map[string]string{"openai/models.go": `package openai
+
+const (
+	DefaultModel = "gpt-4o-mini"
+)
+
+func Register() {
+	default_(DefaultModel)
+}
+`},
+		},
+		{
+			name: "skipped files",
+			diff: goDiff("git/git_test.go", "internal/x/x.go", "main.go"),
+			before: map[string]string{
+				"git/git_test.go": "package git\n\nfunc TestCommit(t *testing.T) {}\n",
+				"internal/x/x.go": "package x\n\nfunc Helper() {}\n",
+				"main.go":         "package main\n\nfunc Run() {}\n",
+			},
+			after: map[string]string{},
+		},
+		{
+			name: "config keys",
+			diff: `diff --git a/config.yaml b/config.yaml
+--- a/config.yaml
++++ b/config.yaml
+@@ -1,6 +1,5 @@
+ server:
+-  port: 8080
+-  host: localhost
++  host: 0.0.0.0
+     nested:
+-      deep: true
+diff --git a/.env b/.env
+--- a/.env
++++ b/.env
+@@ -1,2 +1 @@
+-API_URL=http://localhost
+ DEBUG=true
+
+Binary or large files (content omitted):
+- logo.png: 12 KB
+`,
+			want: []string{"removed config key port in config.yaml", "removed config key API_URL in .env"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			versions := func(file string) ([]byte, []byte) {
+				var before, after []byte
+				if s, ok := tt.before[file]; ok {
+					before = []byte(s)
+				}
+				if s, ok := tt.after[file]; ok {
+					after = []byte(s)
+				}
+				return before, after
+			}
+			var got []string
+			for _, c := range DetectBreaking(tt.diff, versions) {
+				got = append(got, c.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DetectBreaking() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// without the versions of the files, only the config keys are looked for
+	if got := DetectBreaking(goDiff("parser/parse.go"), nil); len(got) != 0 {
+		t.Errorf("DetectBreaking() without versions = %v", got)
+	}
+}
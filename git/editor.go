@@ -0,0 +1,105 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// DefaultCommentChar is the character of the comment lines git strips from the messages.
+const DefaultCommentChar = "#"
+
+// commentChar returns the configured comment string, core.commentString of the recent
+// versions of git or core.commentChar.
+func (c *Command) commentChar() string {
+	if char := c.ConfigValue("core.commentString"); char != "" {
+		return char
+	}
+	return c.ConfigValue("core.commentChar")
+}
+
+// autoCommentChar reports whether git chooses the comment character of every message,
+// the first one that doesn't start any of its lines.
+func (c *Command) autoCommentChar() bool {
+	return strings.EqualFold(c.commentChar(), "auto")
+}
+
+// CommentChar returns the character of the comment lines of core.commentChar, the
+// default one when it's unset or auto. With auto, git picks # for the empty messages
+// of the prepare-commit-msg hook, and CommitEdit makes git use it.
+func (c *Command) CommentChar() string {
+	char := c.commentChar()
+	if char == "" || strings.EqualFold(char, "auto") {
+		return DefaultCommentChar
+	}
+	return char
+}
+
+// Suggestion returns the message commented out with the comment character, under a
+// header telling to uncomment it. Git strips the comment lines, so the commit is
+// aborted if the user doesn't toggle the suggestion on in the editor.
+func Suggestion(message, char string) string {
+	var b strings.Builder
+	b.WriteString(char + " Suggested commit message, uncomment the lines to use it:\n")
+	b.WriteString(char + "\n")
+	for _, line := range strings.Split(strings.TrimSpace(message), "\n") {
+		if line = strings.TrimRight(line, " \t"); line == "" {
+			b.WriteString(char + "\n")
+			continue
+		}
+		b.WriteString(char + " " + line + "\n")
+	}
+	return b.String()
+}
+
+func (c *Command) commitEdit(file string) *exec.Cmd {
+	var args []string
+	// with auto, git would pick another character than the # of the suggestion lines
+	// and keep them in the message
+	if c.autoCommentChar() {
+		args = append(args, "-c", "core.commentChar="+DefaultCommentChar)
+	}
+	args = append(args,
+		"commit",
+		"--no-verify",
+		"--edit",
+		"--file="+file,
+	)
+
+	if c.isAmend {
+		args = append(args, "--amend")
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// SignOff returns the Signed-off-by trailer of the committer, empty if git doesn't know it.
+func (c *Command) SignOff() string {
+	output, err := exec.Command("git", "var", "GIT_COMMITTER_IDENT").Output()
+	if err != nil {
+		return ""
+	}
+	// the ident is followed by the timestamp and the timezone
+	ident := strings.TrimSpace(string(output))
+	i := strings.LastIndex(ident, ">")
+	if i < 0 {
+		return ""
+	}
+	return "Signed-off-by: " + ident[:i+1]
+}
+
+// CommitEdit records the changes with the message of the file after opening it in the
+// editor of git: GIT_EDITOR, core.editor, VISUAL or EDITOR. The editor is attached to
+// the terminal, the commit is aborted if the edited message is empty. Unlike Commit, git
+// doesn't sign off the message: its trailer would be inserted into the commented
+// suggestion, SignOff is added to the suggestion instead.
+func (c *Command) CommitEdit(file string) error {
+	cmd := c.commitEdit(file)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
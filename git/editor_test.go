@@ -0,0 +1,33 @@
+package git
+
+import "testing"
+
+func TestSuggestion(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		char    string
+		want    string
+	}{
+		{
+			name:    "title and body",
+			message: "feat(cli): add the edit flag\n\nOpen the editor of git.\n",
+			char:    "#",
+			want: "# Suggested commit message, uncomment the lines to use it:\n#\n" +
+				"# feat(cli): add the edit flag\n#\n# Open the editor of git.\n",
+		},
+		{
+			name:    "core.commentChar",
+			message: "fix: handle the empty diff",
+			char:    ";",
+			want:    "; Suggested commit message, uncomment the lines to use it:\n;\n; fix: handle the empty diff\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Suggestion(tt.message, tt.char); got != tt.want {
+				t.Errorf("Suggestion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package git
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+func (c *Command) lsFiles() *exec.Cmd {
+	args := []string{
+		"ls-files",
+		"--full-name",
+		"--",
+		":(top)",
+	}
+	args = append(args, c.excludeFiles()...)
+
+	cmd := exec.Command(
+		"git",
+		args...,
+	)
+	cmd.Env = c.env()
+	return cmd
+}
+
+// TrackedFiles returns the paths of the files tracked by the repository from its root,
+// without the excluded files.
+func (c *Command) TrackedFiles() ([]string, error) {
+	output, err := c.lsFiles().Output()
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(string(output)), nil
+}
+
+func (c *Command) grepFiles(word string) *exec.Cmd {
+	args := []string{
+		"grep",
+		"--full-name",
+		"--files-with-matches",
+		"-I",
+		"--ignore-case",
+		"--word-regexp",
+		"--fixed-strings",
+		"-e",
+		word,
+		"--",
+		":(top)",
+	}
+	args = append(args, c.excludeFiles()...)
+
+	cmd := exec.Command(
+		"git",
+		args...,
+	)
+	cmd.Env = c.env()
+	return cmd
+}
+
+// GrepFiles returns the paths of the tracked text files containing the word, ignoring
+// the case, from the root of the repository.
+func (c *Command) GrepFiles(word string) ([]string, error) {
+	output, err := c.grepFiles(word).Output()
+	// git grep exits with 1 when nothing matches
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(string(output)), nil
+}
+
+// splitLines returns the non-empty lines of the output.
+func splitLines(output string) []string {
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,371 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/util"
+	"github.com/appleboy/com/file"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The hook managers overwriting the hooks of .git/hooks, their config gets the hooks instead.
+const (
+	FrameworkHusky     = "husky"
+	FrameworkPreCommit = "pre-commit"
+	FrameworkLefthook  = "lefthook"
+)
+
+var (
+	errorsUnknownFramework = errors.New("only support husky, pre-commit or lefthook framework")
+	errorsHookExist        = errors.New("the codegpt hook is already in the config")
+	errorsHookNotExist     = errors.New("the codegpt hook is not in the config")
+)
+
+// hookID is the name of the codegpt hooks in the configs of the hook managers.
+const hookID = "codegpt"
+
+// lefthookFiles are the config files of lefthook, in the order it reads them.
+var lefthookFiles = []string{"lefthook.yml", ".lefthook.yml", "lefthook.yaml", ".lefthook.yaml"}
+
+// IsFramework returns true if the hook manager is supported.
+func IsFramework(val string) bool {
+	return val == FrameworkHusky || val == FrameworkPreCommit || val == FrameworkLefthook
+}
+
+// FrameworkFile returns the file of the hook manager getting the hook of the name,
+// relative to the root of the repository.
+func FrameworkFile(root, framework, name string) string {
+	switch framework {
+	case FrameworkHusky:
+		return filepath.Join(".husky", name)
+	case FrameworkPreCommit:
+		return ".pre-commit-config.yaml"
+	}
+	for _, f := range lefthookFiles {
+		if file.IsFile(filepath.Join(root, f)) {
+			return f
+		}
+	}
+	return lefthookFiles[0]
+}
+
+// frameworkCommand returns the command of the hook of the name for the hook manager,
+// with the arguments of git in its syntax.
+func frameworkCommand(framework, name string) (string, error) {
+	switch framework {
+	case FrameworkHusky:
+		// the same command as the hook of .git/hooks
+		content, err := util.GetTemplateByBytes(name, nil)
+		if err != nil {
+			return "", err
+		}
+		lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+		return lines[len(lines)-1], nil
+	case FrameworkPreCommit:
+		// the message file is the last argument, the source is in the environment
+		if name == HookCommitMessageTemplate {
+			return "codegpt lint --hook --file", nil
+		}
+		return `sh -c 'codegpt commit --preview --hook_source "$PRE_COMMIT_COMMIT_MSG_SOURCE" --file "$1"' --`, nil
+	case FrameworkLefthook:
+		if name == HookCommitMessageTemplate {
+			return "codegpt lint --hook --file {1}", nil
+		}
+		return "codegpt commit --file {1} --preview --hook_source {2}", nil
+	}
+	return "", errorsUnknownFramework
+}
+
+// InstallFramework adds the hook of the name to the config of the hook manager in the
+// root of the repository, and returns the changed file.
+func InstallFramework(root, framework, name string) (string, error) {
+	if !IsFramework(framework) {
+		return "", errorsUnknownFramework
+	}
+	command, err := frameworkCommand(framework, name)
+	if err != nil {
+		return "", err
+	}
+	target := FrameworkFile(root, framework, name)
+	full := filepath.Join(root, target)
+
+	if framework == FrameworkHusky {
+		return target, installHusky(full, command)
+	}
+	return target, editYAML(full, func(doc *yaml.Node) error {
+		if framework == FrameworkPreCommit {
+			return addPreCommit(doc, name, command)
+		}
+		return addLefthook(doc, name, command)
+	})
+}
+
+// UninstallFramework removes the hook of the name from the config of the hook manager
+// in the root of the repository, and returns the changed file.
+func UninstallFramework(root, framework, name string) (string, error) {
+	if !IsFramework(framework) {
+		return "", errorsUnknownFramework
+	}
+	target := FrameworkFile(root, framework, name)
+	full := filepath.Join(root, target)
+	if !file.IsFile(full) {
+		return target, errorsHookNotExist
+	}
+
+	if framework == FrameworkHusky {
+		return target, uninstallHusky(full)
+	}
+	return target, editYAML(full, func(doc *yaml.Node) error {
+		if framework == FrameworkPreCommit {
+			return removePreCommit(doc, name)
+		}
+		return removeLefthook(doc, name)
+	})
+}
+
+// installHusky adds the command to the husky hook, after the existing commands.
+func installHusky(target, command string) error {
+	data, err := os.ReadFile(target)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if bytes.Contains(data, []byte("codegpt ")) {
+		return errorsHookExist
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if len(data) > 0 && !bytes.HasSuffix(data, []byte("\n")) {
+		data = append(data, '\n')
+	}
+	data = append(data, command+"\n"...)
+	return os.WriteFile(target, data, 0o755)
+}
+
+// uninstallHusky removes the codegpt commands of the husky hook, and the hook without other command.
+func uninstallHusky(target string) error {
+	data, err := os.ReadFile(target)
+	if err != nil {
+		return err
+	}
+	var (
+		kept  []string
+		found bool
+		other bool
+	)
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "codegpt ") {
+			found = true
+			continue
+		}
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			other = true
+		}
+		kept = append(kept, line)
+	}
+	if !found {
+		return errorsHookNotExist
+	}
+	if !other {
+		return os.Remove(target)
+	}
+	return os.WriteFile(target, []byte(strings.Join(kept, "\n")+"\n"), 0o755)
+}
+
+// editYAML changes the YAML file with the function, the comments and the other entries are kept.
+// The file left empty is removed.
+func editYAML(target string, edit func(doc *yaml.Node) error) error {
+	data, err := os.ReadFile(target)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if err := edit(doc.Content[0]); err != nil {
+		return err
+	}
+	// the config only holding the removed hook
+	if len(doc.Content[0].Content) == 0 {
+		return os.Remove(target)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(target, buf.Bytes(), 0o644)
+}
+
+// addPreCommit adds the hook to the local repository of the pre-commit config:
+//
+//	repos:
+//	  - repo: local
+//	    hooks:
+//	      - id: codegpt-prepare-commit-msg
+func addPreCommit(root *yaml.Node, name, command string) error {
+	repos := mapValue(root, "repos", yaml.SequenceNode, true)
+	id := hookID + "-" + name
+
+	var local *yaml.Node
+	for _, repo := range repos.Content {
+		if v := mapValue(repo, "repo", yaml.ScalarNode, false); v == nil || v.Value != "local" {
+			continue
+		}
+		hooks := mapValue(repo, "hooks", yaml.SequenceNode, true)
+		for _, h := range hooks.Content {
+			if v := mapValue(h, "id", yaml.ScalarNode, false); v != nil && v.Value == id {
+				return errorsHookExist
+			}
+		}
+		if local == nil {
+			local = hooks
+		}
+	}
+	if local == nil {
+		repo := &yaml.Node{Kind: yaml.MappingNode}
+		setScalar(repo, "repo", "local")
+		local = mapValue(repo, "hooks", yaml.SequenceNode, true)
+		repos.Content = append(repos.Content, repo)
+	}
+
+	hook := &yaml.Node{Kind: yaml.MappingNode}
+	setScalar(hook, "id", id)
+	setScalar(hook, "name", "codegpt "+name)
+	setScalar(hook, "entry", command)
+	setScalar(hook, "language", "system")
+	stages := mapValue(hook, "stages", yaml.SequenceNode, true)
+	stages.Style = yaml.FlowStyle
+	stages.Content = append(stages.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name})
+	local.Content = append(local.Content, hook)
+	return nil
+}
+
+// removePreCommit removes the hook from the local repositories, and the repositories without hook.
+func removePreCommit(root *yaml.Node, name string) error {
+	repos := mapValue(root, "repos", yaml.SequenceNode, false)
+	if repos == nil {
+		return errorsHookNotExist
+	}
+	id := hookID + "-" + name
+
+	found := false
+	keptRepos := repos.Content[:0]
+	for _, repo := range repos.Content {
+		hooks := mapValue(repo, "hooks", yaml.SequenceNode, false)
+		if v := mapValue(repo, "repo", yaml.ScalarNode, false); v == nil || v.Value != "local" || hooks == nil {
+			keptRepos = append(keptRepos, repo)
+			continue
+		}
+		kept := hooks.Content[:0]
+		for _, h := range hooks.Content {
+			if v := mapValue(h, "id", yaml.ScalarNode, false); v != nil && v.Value == id {
+				found = true
+				continue
+			}
+			kept = append(kept, h)
+		}
+		hooks.Content = kept
+		if len(kept) > 0 {
+			keptRepos = append(keptRepos, repo)
+		}
+	}
+	repos.Content = keptRepos
+	if !found {
+		return errorsHookNotExist
+	}
+	if len(keptRepos) == 0 {
+		deleteKey(root, "repos")
+	}
+	return nil
+}
+
+// addLefthook adds the command of the hook to the lefthook config:
+//
+//	prepare-commit-msg:
+//	  commands:
+//	    codegpt:
+//	      run: codegpt commit ...
+func addLefthook(root *yaml.Node, name, command string) error {
+	commands := mapValue(mapValue(root, name, yaml.MappingNode, true), "commands", yaml.MappingNode, true)
+	if mapValue(commands, hookID, 0, false) != nil {
+		return errorsHookExist
+	}
+	setScalar(mapValue(commands, hookID, yaml.MappingNode, true), "run", command)
+	return nil
+}
+
+// removeLefthook removes the command of the hook, and the hook without command.
+func removeLefthook(root *yaml.Node, name string) error {
+	hook := mapValue(root, name, yaml.MappingNode, false)
+	commands := mapValue(hook, "commands", yaml.MappingNode, false)
+	if !deleteKey(commands, hookID) {
+		return errorsHookNotExist
+	}
+	if len(commands.Content) == 0 {
+		deleteKey(hook, "commands")
+	}
+	if len(hook.Content) == 0 {
+		deleteKey(root, name)
+	}
+	return nil
+}
+
+// mapValue returns the value of the key of the mapping, added with the kind if it's missing
+// and create is true. It's nil if the node isn't a mapping.
+func mapValue(m *yaml.Node, key string, kind yaml.Kind, create bool) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			v := m.Content[i+1]
+			// an empty value, like "hooks:" without item
+			if create && v.Kind == yaml.ScalarNode && v.Tag == "!!null" {
+				v.Kind, v.Tag, v.Value = kind, "", ""
+			}
+			return v
+		}
+	}
+	if !create {
+		return nil
+	}
+	v := &yaml.Node{Kind: kind}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, v)
+	return v
+}
+
+// setScalar sets the key of the mapping to the value.
+func setScalar(m *yaml.Node, key, value string) {
+	v := mapValue(m, key, yaml.ScalarNode, true)
+	v.Kind, v.Value = yaml.ScalarNode, value
+}
+
+// deleteKey removes the key of the mapping and returns true if it was there.
+func deleteKey(m *yaml.Node, key string) bool {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFrameworkHusky(t *testing.T) {
+	root := t.TempDir()
+	target := filepath.Join(root, ".husky", HookCommitMessageTemplate)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("npx --no -- commitlint --edit $1"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := InstallFramework(root, FrameworkHusky, HookCommitMessageTemplate); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(target)
+	if want := "npx --no -- commitlint --edit $1\ncodegpt lint --file \"$1\" --hook\n"; string(data) != want {
+		t.Errorf("hook = %q, want %q", data, want)
+	}
+	if _, err := InstallFramework(root, FrameworkHusky, HookCommitMessageTemplate); !errors.Is(err, errorsHookExist) {
+		t.Errorf("second install error = %v, want %v", err, errorsHookExist)
+	}
+
+	if _, err := UninstallFramework(root, FrameworkHusky, HookCommitMessageTemplate); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(target)
+	if want := "npx --no -- commitlint --edit $1\n"; string(data) != want {
+		t.Errorf("hook = %q, want %q", data, want)
+	}
+
+	// the hook only running codegpt is removed
+	if _, err := InstallFramework(root, FrameworkHusky, HookPrepareCommitMessageTemplate); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UninstallFramework(root, FrameworkHusky, HookPrepareCommitMessageTemplate); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".husky", HookPrepareCommitMessageTemplate)); !os.IsNotExist(err) {
+		t.Errorf("hook not removed: %v", err)
+	}
+}
+
+func TestFrameworkPreCommit(t *testing.T) {
+	root := t.TempDir()
+	target := filepath.Join(root, ".pre-commit-config.yaml")
+	existing := `# the hooks of the repository
+repos:
+  - repo: https://github.com/pre-commit/pre-commit-hooks
+    rev: v4.6.0
+    hooks:
+      - id: trailing-whitespace
+`
+	if err := os.WriteFile(target, []byte(existing), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{HookPrepareCommitMessageTemplate, HookCommitMessageTemplate} {
+		if _, err := InstallFramework(root, FrameworkPreCommit, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, _ := os.ReadFile(target)
+	want := existing + `  - repo: local
+    hooks:
+      - id: codegpt-prepare-commit-msg
+        name: codegpt prepare-commit-msg
+        entry: sh -c 'codegpt commit --preview --hook_source "$PRE_COMMIT_COMMIT_MSG_SOURCE" --file "$1"' --
+        language: system
+        stages: [prepare-commit-msg]
+      - id: codegpt-commit-msg
+        name: codegpt commit-msg
+        entry: codegpt lint --hook --file
+        language: system
+        stages: [commit-msg]
+`
+	if string(data) != want {
+		t.Errorf("config =\n%s\nwant\n%s", data, want)
+	}
+	if _, err := InstallFramework(root, FrameworkPreCommit, HookCommitMessageTemplate); !errors.Is(err, errorsHookExist) {
+		t.Errorf("second install error = %v, want %v", err, errorsHookExist)
+	}
+
+	for _, name := range []string{HookPrepareCommitMessageTemplate, HookCommitMessageTemplate} {
+		if _, err := UninstallFramework(root, FrameworkPreCommit, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, _ = os.ReadFile(target)
+	if string(data) != existing {
+		t.Errorf("config =\n%s\nwant\n%s", data, existing)
+	}
+	if _, err := UninstallFramework(root, FrameworkPreCommit, HookCommitMessageTemplate); !errors.Is(err, errorsHookNotExist) {
+		t.Errorf("uninstall error = %v, want %v", err, errorsHookNotExist)
+	}
+}
+
+func TestFrameworkLefthook(t *testing.T) {
+	root := t.TempDir()
+	if got := FrameworkFile(root, FrameworkLefthook, HookCommitMessageTemplate); got != "lefthook.yml" {
+		t.Errorf("FrameworkFile() = %q, want lefthook.yml", got)
+	}
+	existing := "pre-commit:\n  commands:\n    lint:\n      run: make lint\n"
+	target := filepath.Join(root, ".lefthook.yml")
+	if err := os.WriteFile(target, []byte(existing), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := InstallFramework(root, FrameworkLefthook, HookPrepareCommitMessageTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed != ".lefthook.yml" {
+		t.Errorf("changed file = %q, want .lefthook.yml", changed)
+	}
+	data, _ := os.ReadFile(target)
+	if !strings.HasSuffix(string(data), "prepare-commit-msg:\n  commands:\n    codegpt:\n      run: codegpt commit --file {1} --preview --hook_source {2}\n") {
+		t.Errorf("config =\n%s", data)
+	}
+
+	if _, err := UninstallFramework(root, FrameworkLefthook, HookPrepareCommitMessageTemplate); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(target)
+	if string(data) != existing {
+		t.Errorf("config =\n%s\nwant\n%s", data, existing)
+	}
+}
+
+func TestFrameworkNewConfig(t *testing.T) {
+	root := t.TempDir()
+	if _, err := InstallFramework(root, FrameworkPreCommit, HookCommitMessageTemplate); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UninstallFramework(root, FrameworkPreCommit, HookCommitMessageTemplate); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".pre-commit-config.yaml")); !os.IsNotExist(err) {
+		t.Errorf("config not removed: %v", err)
+	}
+}
+
+func TestFrameworkUnknown(t *testing.T) {
+	if _, err := InstallFramework(t.TempDir(), "overcommit", HookCommitMessageTemplate); !errors.Is(err, errorsUnknownFramework) {
+		t.Errorf("error = %v, want %v", err, errorsUnknownFramework)
+	}
+}
@@ -0,0 +1,195 @@
+package git
+
+import (
+	"bufio"
+	"io"
+	"os/exec"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// generatedHeadSize is the size in bytes of the beginning of a file searched for the
+// comments of the code generators.
+const generatedHeadSize = 1024
+
+// generatedNames are the patterns of the names of the generated files.
+var generatedNames = []string{
+	// protocol buffers and gRPC
+	"*.pb.go",
+	"*.pb.gw.go",
+	"*_pb2.py",
+	"*_pb2_grpc.py",
+	"*_pb2.pyi",
+	"*_pb.js",
+	"*_pb.d.ts",
+	"*_grpc_pb.js",
+	"*.pb.cc",
+	"*.pb.h",
+	// mocks
+	"mock_*.go",
+	"*_mock.go",
+	"*_mocks.go",
+	"*.mock.ts",
+	"*.mock.js",
+	// snapshots
+	"*.snap",
+	// code generators
+	"*.gen.go",
+	"*_gen.go",
+	"*.generated.*",
+	"*.g.dart",
+	"*.freezed.dart",
+	"*.designer.cs",
+}
+
+// generatedDirs are the directories of the generated files.
+var generatedDirs = []string{
+	"__snapshots__",
+	"mocks",
+	"__generated__",
+}
+
+// generatedHeader matches the comments written by the code generators at the beginning
+// of the files, like "Code generated by protoc-gen-go. DO NOT EDIT."
+var generatedHeader = regexp.MustCompile(`(?i)(code generated .*do not edit|@generated\b|<auto-generated|auto-generated by|autogenerated by|generated by the protocol buffer compiler|this (file|code) (is|was) (automatically |auto-)?generated)`)
+
+// IsGenerated reports whether the file is generated by a tool, from its name, its
+// directory or the beginning of its content, nil when it isn't known.
+func IsGenerated(name string, head []byte) bool {
+	base := path.Base(name)
+	for _, pattern := range generatedNames {
+		if ok, _ := path.Match(pattern, base); ok {
+			return true
+		}
+	}
+	for _, dir := range strings.Split(path.Dir(name), "/") {
+		for _, d := range generatedDirs {
+			if dir == d {
+				return true
+			}
+		}
+	}
+	if len(head) > generatedHeadSize {
+		head = head[:generatedHeadSize]
+	}
+	return generatedHeader.Match(head)
+}
+
+// summarizeGenerated returns a one-line summary of a generated file change,
+// for example: "modified generated file api/api.pb.go, +120 -30 lines".
+// A negative size means the file does not exist on that side of the diff.
+func summarizeGenerated(stat FileStat, oldSize, newSize int64) string {
+	action := "modified"
+	switch {
+	case oldSize < 0:
+		action = "added"
+	case newSize < 0:
+		action = "deleted"
+	}
+
+	return action + " generated file " + stat.Path + ", +" + strconv.Itoa(stat.Added) + " -" + strconv.Itoa(stat.Deleted) + " lines"
+}
+
+func (c *Command) checkGeneratedAttr(files []string) *exec.Cmd {
+	args := []string{
+		"check-attr",
+		"linguist-generated",
+		"--",
+	}
+	args = append(args, files...)
+
+	cmd := exec.Command(
+		"git",
+		args...,
+	)
+	cmd.Env = c.env()
+	return cmd
+}
+
+// generatedAttrs returns the linguist-generated attributes of the files set in the
+// .gitattributes files, true or false, the files without the attribute are missing.
+func (c *Command) generatedAttrs(files []string) map[string]bool {
+	attrs := map[string]bool{}
+	if len(files) == 0 {
+		return attrs
+	}
+	output, err := c.checkGeneratedAttr(files).Output()
+	if err != nil {
+		return attrs
+	}
+
+	// path/to/file: linguist-generated: true
+	for _, line := range strings.Split(string(output), "\n") {
+		name, value, ok := strings.Cut(line, ": linguist-generated: ")
+		if !ok {
+			continue
+		}
+		switch value {
+		case "true", "set":
+			attrs[name] = true
+		case "false", "unset":
+			attrs[name] = false
+		}
+	}
+	return attrs
+}
+
+func (c *Command) batchContent() *exec.Cmd {
+	args := []string{
+		"cat-file",
+		"--batch",
+	}
+
+	cmd := exec.Command(
+		"git",
+		args...,
+	)
+	cmd.Env = c.env()
+	return cmd
+}
+
+// blobHeads returns the beginning of the content of the given objects, nil for a
+// missing object.
+func (c *Command) blobHeads(objects []string) ([][]byte, error) {
+	heads := make([][]byte, len(objects))
+	if len(objects) == 0 {
+		return heads, nil
+	}
+
+	cmd := c.batchContent()
+	cmd.Stdin = strings.NewReader(strings.Join(objects, "\n") + "\n")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	// every object is "<oid> <type> <size>\n<content>\n", or "<object> missing\n"
+	r := bufio.NewReader(stdout)
+	for i := range objects {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			break
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			break
+		}
+		content := make([]byte, size+1)
+		if _, err := io.ReadFull(r, content); err != nil {
+			break
+		}
+		heads[i] = content[:min(size, generatedHeadSize)]
+	}
+	_, _ = io.Copy(io.Discard, stdout)
+
+	return heads, cmd.Wait()
+}
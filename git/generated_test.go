@@ -0,0 +1,61 @@
+package git
+
+import "testing"
+
+func TestIsGenerated(t *testing.T) {
+	tests := []struct {
+		name string
+		head string
+		want bool
+	}{
+		{name: "api/v1/api.pb.go", want: true},
+		{name: "proto/service_pb2.py", want: true},
+		{name: "internal/store/mock_store.go", want: true},
+		{name: "internal/mocks/store.go", want: true},
+		{name: "web/src/__snapshots__/App.test.tsx.snap", want: true},
+		{name: "ui/Button.snap", want: true},
+		{name: "graph/schema.generated.ts", want: true},
+		{name: "types_string.go", head: "// Code generated by \"stringer -type=Kind\"; DO NOT EDIT.\n\npackage types\n", want: true},
+		{name: "schema.sql", head: "-- @generated by the migration tool\n", want: true},
+		{name: "Form.cs", head: "//------\n// <auto-generated>\n//     This code was generated by a tool.\n", want: true},
+		{name: "main.go", head: "package main\n\nfunc main() {}\n", want: false},
+		{name: "mockery.go", head: "package mockery\n", want: false},
+		{name: "docs/generated-code.md", head: "# How the code is generated\n", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := IsGenerated(tt.name, []byte(tt.head)); got != tt.want {
+			t.Errorf("IsGenerated(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsGeneratedHeadOnly(t *testing.T) {
+	head := make([]byte, generatedHeadSize)
+	for i := range head {
+		head[i] = ' '
+	}
+	head = append(head, "// Code generated by tool. DO NOT EDIT."...)
+	if IsGenerated("late.go", head) {
+		t.Error("IsGenerated() = true, want only the beginning of the content searched")
+	}
+}
+
+func TestSummarizeGenerated(t *testing.T) {
+	tests := []struct {
+		stat    FileStat
+		oldSize int64
+		newSize int64
+		want    string
+	}{
+		{FileStat{Path: "api/api.pb.go", Added: 120, Deleted: 30}, 2048, 4096, "modified generated file api/api.pb.go, +120 -30 lines"},
+		{FileStat{Path: "mocks/store.go", Added: 80}, -1, 1024, "added generated file mocks/store.go, +80 -0 lines"},
+		{FileStat{Path: "a.snap", Deleted: 12}, 512, -1, "deleted generated file a.snap, +0 -12 lines"},
+	}
+
+	for _, tt := range tests {
+		if got := summarizeGenerated(tt.stat, tt.oldSize, tt.newSize); got != tt.want {
+			t.Errorf("summarizeGenerated(%q) = %q, want %q", tt.stat.Path, got, tt.want)
+		}
+	}
+}
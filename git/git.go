@@ -1,11 +1,13 @@
 package git
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
-	"path"
+	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 
@@ -19,6 +21,9 @@ var excludeFromDiff = []string{
 	// yarn.lock, Cargo.lock, Gemfile.lock, Pipfile.lock, etc.
 	"*.lock",
 	"go.sum",
+	// minified assets
+	"*.min.js",
+	"*.min.css",
 }
 
 type Command struct {
@@ -26,60 +31,542 @@ type Command struct {
 	diffUnified int
 	excludeList []string
 	isAmend     bool
+	// Files over this size in bytes are summarized instead of diffed
+	maxFileSize int64
+	// Diff the working tree instead of the staged changes
+	workingTree      bool
+	includeUntracked bool
+	// Temporary index file used to collect the working tree changes
+	indexFile string
+	// Diff a range of revisions instead of the staged changes
+	fromRev   string
+	toRev     string
+	mergeBase bool
+}
+
+// env returns the environment of the git commands,
+// nil means the environment of the current process.
+func (c *Command) env() []string {
+	if c.indexFile == "" {
+		return nil
+	}
+	return append(os.Environ(), "GIT_INDEX_FILE="+c.indexFile)
+}
+
+func (c *Command) indexPath() *exec.Cmd {
+	args := []string{
+		"rev-parse",
+		"--git-path",
+		"index",
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+func (c *Command) addAll() *exec.Cmd {
+	args := []string{
+		"add",
+	}
+
+	if c.includeUntracked {
+		args = append(args, "--all")
+	} else {
+		args = append(args, "--update")
+	}
+
+	cmd := exec.Command(
+		"git",
+		args...,
+	)
+	cmd.Env = c.env()
+	return cmd
+}
+
+// prepareWorkingTree copies the index to a temporary file and stages the
+// working tree changes into it, so the real index is left untouched.
+func (c *Command) prepareWorkingTree() error {
+	output, err := c.indexPath().Output()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp("", "codegpt-index-*")
+	if err != nil {
+		return err
+	}
+	defer tmp.Close()
+	c.indexFile = tmp.Name()
+
+	// a new repository doesn't have an index file yet
+	index, err := os.ReadFile(strings.TrimSpace(string(output)))
+	if err == nil {
+		if _, err := tmp.Write(index); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	return c.addAll().Run()
+}
+
+// Cleanup removes the temporary files created by the command.
+func (c *Command) Cleanup() {
+	if c.indexFile != "" {
+		_ = os.Remove(c.indexFile)
+		c.indexFile = ""
+	}
+}
+
+// StageAll adds the working tree changes of the tracked files to the index like
+// `git add --update`, also the untracked files like `git add --all` when they are included.
+func (c *Command) StageAll() error {
+	index := c.indexFile
+	c.indexFile = ""
+	defer func() { c.indexFile = index }()
+
+	output, err := c.addAll().CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
 }
 
 func (c *Command) excludeFiles() []string {
 	var excludedFiles []string
 	for _, f := range c.excludeList {
-		excludedFiles = append(excludedFiles, ":(exclude)"+f)
+		excludedFiles = append(excludedFiles, excludePathspec(f))
 	}
 	return excludedFiles
 }
 
+// Exclude leaves the files matching the patterns out of the next diffs.
+func (c *Command) Exclude(patterns ...string) {
+	c.excludeList = append(c.excludeList, patterns...)
+}
+
+func (c *Command) topLevel() *exec.Cmd {
+	args := []string{
+		"rev-parse",
+		"--show-toplevel",
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// TopLevel returns the absolute path of the top-level directory of the working tree.
+func (c *Command) TopLevel() (string, error) {
+	output, err := c.topLevel().Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// diffRange returns the revisions to compare, the staged changes by default
+// or the last commit if amend is enabled.
+func (c *Command) diffRange() []string {
+	if c.fromRev != "" {
+		if c.mergeBase {
+			return []string{c.fromRev + "..." + c.toRev}
+		}
+		return []string{c.fromRev, c.toRev}
+	}
+	if c.isAmend {
+		return []string{"HEAD^", "HEAD"}
+	}
+	return []string{"--staged"}
+}
+
+func (c *Command) mergeBaseOf(from, to string) *exec.Cmd {
+	args := []string{
+		"merge-base",
+		from,
+		to,
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// revisions returns the object name prefixes of the old and new side of the diff.
+func (c *Command) revisions() (string, string) {
+	if c.fromRev != "" {
+		from := c.fromRev
+		if c.mergeBase {
+			if output, err := c.mergeBaseOf(c.fromRev, c.toRev).Output(); err == nil {
+				from = strings.TrimSpace(string(output))
+			}
+		}
+		return from + ":", c.toRev + ":"
+	}
+	if c.isAmend {
+		return "HEAD^:", "HEAD:"
+	}
+	// the empty revision refers to the index (staged content)
+	return "HEAD:", ":"
+}
+
 func (c *Command) diffNames() *exec.Cmd {
 	args := []string{
 		"diff",
 		"--name-only",
 	}
 
-	if c.isAmend {
-		args = append(args, "HEAD^", "HEAD")
-	} else {
-		args = append(args, "--staged")
+	args = append(args, c.diffRange()...)
+
+	excludedFiles := c.excludeFiles()
+	args = append(args, excludedFiles...)
+
+	cmd := exec.Command(
+		"git",
+		args...,
+	)
+	cmd.Env = c.env()
+	return cmd
+}
+
+func (c *Command) diffStat() *exec.Cmd {
+	args := []string{
+		"diff",
+		"--numstat",
+		"--no-renames",
 	}
 
+	args = append(args, c.diffRange()...)
+
 	excludedFiles := c.excludeFiles()
 	args = append(args, excludedFiles...)
 
-	return exec.Command(
+	cmd := exec.Command(
 		"git",
 		args...,
 	)
+	cmd.Env = c.env()
+	return cmd
 }
 
-func (c *Command) diffFiles() *exec.Cmd {
+func (c *Command) diffFiles(skipFiles ...string) *exec.Cmd {
 	args := []string{
 		"diff",
 		"--ignore-all-space",
 		"--diff-algorithm=minimal",
 		"--unified=" + strconv.Itoa(c.diffUnified),
+		// detect renames and copies
+		"-M",
+		"-C",
 	}
 
-	if c.isAmend {
-		args = append(args, "HEAD^", "HEAD")
-	} else {
-		args = append(args, "--staged")
+	args = append(args, c.diffRange()...)
+
+	excludedFiles := c.excludeFiles()
+	args = append(args, excludedFiles...)
+
+	for _, f := range skipFiles {
+		args = append(args, ":(exclude,literal)"+f)
 	}
 
+	cmd := exec.Command(
+		"git",
+		args...,
+	)
+	cmd.Env = c.env()
+	return cmd
+}
+
+func (c *Command) patchDiff() *exec.Cmd {
+	args := []string{
+		"diff",
+		"--no-color",
+		"--no-renames",
+		"--no-ext-diff",
+		"--unified=" + strconv.Itoa(c.diffUnified),
+	}
+
+	args = append(args, c.diffRange()...)
+
 	excludedFiles := c.excludeFiles()
 	args = append(args, excludedFiles...)
 
+	cmd := exec.Command(
+		"git",
+		args...,
+	)
+	cmd.Env = c.env()
+	return cmd
+}
+
+// PatchDiff returns the diff of the changes in a format that can be applied with `git apply`.
+func (c *Command) PatchDiff() (string, error) {
+	output, err := c.patchDiff().Output()
+	if err != nil {
+		return "", err
+	}
+
+	return string(output), nil
+}
+
+func (c *Command) applyReverse() *exec.Cmd {
+	args := []string{
+		"apply",
+		"--cached",
+		"--reverse",
+		"--recount",
+		"-",
+	}
+
 	return exec.Command(
 		"git",
 		args...,
 	)
 }
 
+// UnstagePatch removes the changes of the given patch from the index,
+// the working tree is left untouched.
+func (c *Command) UnstagePatch(patch string) error {
+	cmd := c.applyReverse()
+	cmd.Stdin = strings.NewReader(patch)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (c *Command) writeTree() *exec.Cmd {
+	args := []string{
+		"write-tree",
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// WriteTree creates a tree object from the current index and returns its name.
+func (c *Command) WriteTree() (string, error) {
+	output, err := c.writeTree().Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (c *Command) resetIndex() *exec.Cmd {
+	args := []string{
+		"reset",
+		"--quiet",
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+func (c *Command) restoreStaged(tree string, files []string) *exec.Cmd {
+	args := []string{
+		"restore",
+		"--staged",
+		"--source=" + tree,
+		"--",
+	}
+
+	args = append(args, files...)
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// StageFromTree resets the index to HEAD and stages the given files as they are in the tree,
+// the working tree is left untouched.
+func (c *Command) StageFromTree(tree string, files []string) error {
+	if output, err := c.resetIndex().CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	if len(files) == 0 {
+		return nil
+	}
+
+	if output, err := c.restoreStaged(tree, files).CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// DiffNames returns the names of the changed files.
+func (c *Command) DiffNames() ([]string, error) {
+	output, err := c.diffNames().Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, name := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func (c *Command) allDiffNames() *exec.Cmd {
+	args := []string{
+		"diff",
+		"--name-only",
+	}
+
+	args = append(args, c.diffRange()...)
+
+	cmd := exec.Command(
+		"git",
+		args...,
+	)
+	cmd.Env = c.env()
+	return cmd
+}
+
+// AllDiffNames returns the names of the changed files, with the excluded ones like the lock files.
+func (c *Command) AllDiffNames() ([]string, error) {
+	output, err := c.allDiffNames().Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, name := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+func (c *Command) batchCheck() *exec.Cmd {
+	args := []string{
+		"cat-file",
+		"--batch-check=%(objectsize)",
+	}
+
+	cmd := exec.Command(
+		"git",
+		args...,
+	)
+	cmd.Env = c.env()
+	return cmd
+}
+
+// blobSizes returns the size of the given objects, -1 for a missing object.
+func (c *Command) blobSizes(objects []string) ([]int64, error) {
+	cmd := c.batchCheck()
+	cmd.Stdin = strings.NewReader(strings.Join(objects, "\n") + "\n")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	sizes := make([]int64, len(objects))
+	for i := range objects {
+		sizes[i] = -1
+		if i < len(lines) {
+			if size, err := strconv.ParseInt(strings.TrimSpace(lines[i]), 10, 64); err == nil {
+				sizes[i] = size
+			}
+		}
+	}
+	return sizes, nil
+}
+
+// omittedFiles returns the binary files, the files over the maximum size and the
+// generated files with a one-line summary for each of them.
+func (c *Command) omittedFiles() ([]string, []string, []string, error) {
+	output, err := c.diffStat().Output()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	stats := ParseNumstat(string(output))
+	if len(stats) == 0 {
+		return nil, nil, nil, nil
+	}
+
+	oldRev, newRev := c.revisions()
+	objects := make([]string, 0, len(stats)*2)
+	paths := make([]string, 0, len(stats))
+	for _, stat := range stats {
+		objects = append(objects, oldRev+stat.Path, newRev+stat.Path)
+		paths = append(paths, stat.Path)
+	}
+
+	sizes, err := c.blobSizes(objects)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// the generated files are found from their name, the linguist-generated attribute
+	// of .gitattributes, or the comment at the beginning of their content
+	attrs := c.generatedAttrs(paths)
+	isGenerated := make([]bool, len(stats))
+	var (
+		files, summaries, generated []string
+		candidates                  []int
+		candidateObjects            []string
+	)
+	for i, stat := range stats {
+		oldSize, newSize := sizes[i*2], sizes[i*2+1]
+		if stat.Binary || oldSize > c.maxFileSize || newSize > c.maxFileSize {
+			files = append(files, stat.Path)
+			summaries = append(summaries, summarizeFile(stat.Path, stat.Binary, oldSize, newSize))
+			continue
+		}
+		if attr, ok := attrs[stat.Path]; ok {
+			isGenerated[i] = attr
+			continue
+		}
+		if IsGenerated(stat.Path, nil) {
+			isGenerated[i] = true
+			continue
+		}
+		candidates = append(candidates, i)
+		if newSize >= 0 {
+			candidateObjects = append(candidateObjects, newRev+stat.Path)
+		} else {
+			candidateObjects = append(candidateObjects, oldRev+stat.Path)
+		}
+	}
+
+	contents, err := c.blobHeads(candidateObjects)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for n, i := range candidates {
+		isGenerated[i] = IsGenerated(stats[i].Path, contents[n])
+	}
+	for i, stat := range stats {
+		if isGenerated[i] {
+			files = append(files, stat.Path)
+			generated = append(generated, summarizeGenerated(stat, sizes[i*2], sizes[i*2+1]))
+		}
+	}
+
+	return files, summaries, generated, nil
+}
+
 func (c *Command) hookPath() *exec.Cmd {
 	args := []string{
 		"rev-parse",
@@ -123,9 +610,199 @@ func (c *Command) commit(val string) *exec.Cmd {
 	)
 }
 
+func (c *Command) commitMessage(rev string) *exec.Cmd {
+	args := []string{
+		"log",
+		"-1",
+		"--format=%B",
+		rev,
+		"--",
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// CommitMessage returns the message of the given commit.
+func (c *Command) CommitMessage(rev string) (string, error) {
+	output, err := c.commitMessage(rev).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (c *Command) showFile(rev, file string) *exec.Cmd {
+	args := []string{
+		"show",
+		rev + ":" + file,
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// ShowFile returns the content of the file, relative to the root of the repository, at
+// the revision, or in the index when the revision is empty.
+func (c *Command) ShowFile(rev, file string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := c.showFile(rev, file)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+func (c *Command) showObject(object string) *exec.Cmd {
+	args := []string{
+		"show",
+		object,
+	}
+
+	cmd := exec.Command(
+		"git",
+		args...,
+	)
+	cmd.Env = c.env()
+	return cmd
+}
+
+// FileVersions returns the content of the changed file, relative to the root of the
+// repository, before and after the changes to diff, nil on the side where it doesn't exist.
+func (c *Command) FileVersions(file string) ([]byte, []byte) {
+	oldRev, newRev := c.revisions()
+	before, err := c.showObject(oldRev + file).Output()
+	if err != nil {
+		before = nil
+	}
+	after, err := c.showObject(newRev + file).Output()
+	if err != nil {
+		after = nil
+	}
+	return before, after
+}
+
+func (c *Command) configValue(key string) *exec.Cmd {
+	args := []string{
+		"config",
+		"--get",
+		key,
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// ConfigValue returns the value of the given git config key.
+// It returns an empty string if the key is not set.
+func (c *Command) ConfigValue(key string) string {
+	output, err := c.configValue(key).Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(output))
+}
+
+func (c *Command) boolConfigValue(key string) *exec.Cmd {
+	args := []string{
+		"config",
+		"--bool",
+		"--get",
+		key,
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// BoolConfigValue returns the value of the given boolean git config key, like git reads
+// it: yes, on and 1 are true too. It returns false if the key is not set or invalid.
+func (c *Command) BoolConfigValue(key string) bool {
+	output, err := c.boolConfigValue(key).Output()
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(output)) == "true"
+}
+
+// SigningFormat returns the signing format (openpgp, x509 or ssh) if the repository
+// requires signed commits via commit.gpgsign, otherwise it returns an empty string.
+func (c *Command) SigningFormat() string {
+	if !c.BoolConfigValue("commit.gpgsign") {
+		return ""
+	}
+
+	format := c.ConfigValue("gpg.format")
+	if format == "" {
+		format = "openpgp"
+	}
+	return format
+}
+
+// ttyPath returns the path of the controlling terminal for the current platform.
+func ttyPath() string {
+	if runtime.GOOS == "windows" {
+		return "CONIN$"
+	}
+	return "/dev/tty"
+}
+
+// ttyName returns the path of the terminal of the process, like /dev/pts/3, from the tty
+// command on its stdin, stderr or stdout. GPG_TTY must be this path, /dev/tty is the
+// terminal of the process opening it, not the one of gpg. It's empty if none is a terminal.
+func ttyName() string {
+	if runtime.GOOS == "windows" {
+		return ""
+	}
+	for _, f := range []*os.File{os.Stdin, os.Stderr, os.Stdout} {
+		cmd := exec.Command("tty")
+		cmd.Stdin = f
+		if output, err := cmd.Output(); err == nil {
+			return strings.TrimSpace(string(output))
+		}
+	}
+	return ""
+}
+
+// Commit records changes to the repository with the given message.
+// If commit signing is enabled, the signing program (gpg or ssh-keygen) is attached
+// to the terminal so it can prompt for the key passphrase, even when stdin is not a terminal
+// like in the prepare-commit-msg hook.
 func (c *Command) Commit(val string) (string, error) {
-	output, err := c.commit(val).Output()
+	cmd := c.commit(val)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if c.SigningFormat() != "" {
+		tty, err := os.OpenFile(ttyPath(), os.O_RDWR, 0)
+		if err == nil {
+			defer tty.Close()
+			cmd.Stdin = tty
+			cmd.Stderr = tty
+			if name := ttyName(); name != "" {
+				cmd.Env = append(os.Environ(), "GPG_TTY="+name)
+			}
+		}
+	}
+
+	output, err := cmd.Output()
 	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
 		return "", err
 	}
 
@@ -146,34 +823,74 @@ func (c *Command) GitDir() (string, error) {
 // It returns a string representing the differences and an error.
 // If there are no differences, it returns an empty string and an error.
 func (c *Command) DiffFiles() (string, error) {
+	if c.workingTree && c.indexFile == "" {
+		if err := c.prepareWorkingTree(); err != nil {
+			return "", err
+		}
+	}
+
 	output, err := c.diffNames().Output()
 	if err != nil {
 		return "", err
 	}
 	if string(output) == "" {
-		return "", errors.New("please add your staged changes using git add <files...>")
+		if c.fromRev != "" {
+			return "", fmt.Errorf("there are no changes between %s and %s", c.fromRev, c.toRev)
+		}
+		if c.workingTree {
+			return "", errors.New("there are no changes in the working tree")
+		}
+		return "", errors.New("please add your staged changes using git add <files...> or use the --all flag")
 	}
 
-	output, err = c.diffFiles().Output()
+	files, summaries, generated, err := c.omittedFiles()
 	if err != nil {
 		return "", err
 	}
 
-	return string(output), nil
+	output, err = c.diffFiles(files...).Output()
+	if err != nil {
+		return "", err
+	}
+
+	diff := string(output)
+
+	// list the renamed and copied files as explicit events
+	if events := ParseRenames(diff); len(events) > 0 {
+		diff += "\nRenamed or copied files:\n"
+		for _, e := range events {
+			diff += "- " + e.String() + "\n"
+		}
+	}
+
+	// list the omitted files instead of their content
+	if len(summaries) > 0 {
+		diff += "\nBinary or large files (content omitted):\n- " +
+			strings.Join(summaries, "\n- ") + "\n"
+	}
+
+	// and the generated files, they would drown out the hand-written changes
+	if len(generated) > 0 {
+		diff += "\nGenerated files (content omitted):\n- " +
+			strings.Join(generated, "\n- ") + "\n"
+	}
+
+	return diff, nil
 }
 
-func (c *Command) InstallHook() error {
+// InstallHook installs the hook of the name, prepare-commit-msg or commit-msg.
+func (c *Command) InstallHook(name string) error {
 	hookPath, err := c.hookPath().Output()
 	if err != nil {
 		return err
 	}
 
-	target := path.Join(strings.TrimSpace(string(hookPath)), HookPrepareCommitMessageTemplate)
+	target := filepath.Join(strings.TrimSpace(string(hookPath)), name)
 	if file.IsFile(target) {
-		return errors.New("hook file prepare-commit-msg exist.")
+		return errors.New("hook file " + name + " exist.")
 	}
 
-	content, err := util.GetTemplateByBytes(HookPrepareCommitMessageTemplate, nil)
+	content, err := util.GetTemplateByBytes(name, nil)
 	if err != nil {
 		return err
 	}
@@ -181,22 +898,54 @@ func (c *Command) InstallHook() error {
 	return os.WriteFile(target, content, 0o755)
 }
 
-func (c *Command) UninstallHook() error {
+// HookInstalled returns true if the hook of the name is installed.
+func (c *Command) HookInstalled(name string) (bool, error) {
+	hookPath, err := c.hookPath().Output()
+	if err != nil {
+		return false, err
+	}
+
+	return file.IsFile(filepath.Join(strings.TrimSpace(string(hookPath)), name)), nil
+}
+
+// HookOutdated returns true if the installed hook of the name differs from
+// the current one, like the hooks installed before the skip conditions.
+func (c *Command) HookOutdated(name string) (bool, error) {
+	hookPath, err := c.hookPath().Output()
+	if err != nil {
+		return false, err
+	}
+
+	installed, err := os.ReadFile(filepath.Join(strings.TrimSpace(string(hookPath)), name))
+	if err != nil {
+		return false, err
+	}
+	content, err := util.GetTemplateByBytes(name, nil)
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(installed, content), nil
+}
+
+// UninstallHook removes the hook of the name.
+func (c *Command) UninstallHook(name string) error {
 	hookPath, err := c.hookPath().Output()
 	if err != nil {
 		return err
 	}
 
-	target := path.Join(strings.TrimSpace(string(hookPath)), HookPrepareCommitMessageTemplate)
+	target := filepath.Join(strings.TrimSpace(string(hookPath)), name)
 	if !file.IsFile(target) {
-		return errors.New("hook file prepare-commit-msg is not exist.")
+		return errors.New("hook file " + name + " is not exist.")
 	}
 	return os.Remove(target)
 }
 
 func New(opts ...Option) *Command {
 	// Instantiate a new config object with default values
-	cfg := &config{}
+	cfg := &config{
+		maxFileSize: defaultMaxFileSize,
+	}
 
 	// Loop through each option passed as argument and apply it to the config object
 	for _, o := range opts {
@@ -207,8 +956,22 @@ func New(opts ...Option) *Command {
 	cmd := &Command{
 		diffUnified: cfg.diffUnified,
 		// Append the user-defined excludeList to the default excludeFromDiff
-		excludeList: append(excludeFromDiff, cfg.excludeList...),
+		excludeList: append(append([]string{}, excludeFromDiff...), cfg.excludeList...),
 		isAmend:     cfg.isAmend,
+		maxFileSize: cfg.maxFileSize,
+		// Including the untracked files implies diffing the working tree
+		workingTree:      cfg.workingTree || cfg.includeUntracked,
+		includeUntracked: cfg.includeUntracked,
+		fromRev:          cfg.fromRev,
+		toRev:            cfg.toRev,
+		mergeBase:        cfg.mergeBase,
+	}
+
+	// Append the patterns from the .codegptignore file in the repository root
+	if root, err := cmd.TopLevel(); err == nil {
+		if patterns, err := ReadIgnoreFile(filepath.Join(root, IgnoreFile)); err == nil {
+			cmd.excludeList = append(cmd.excludeList, patterns...)
+		}
 	}
 
 	return cmd
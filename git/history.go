@@ -0,0 +1,160 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// conventionalPrefix matches the conventional commit prefix of a subject, like feat(cli)!:
+var conventionalPrefix = regexp.MustCompile(`^[a-z]+(\([^)]*\))?!?:\s*`)
+
+// skipPrefixes are the subjects of work in progress and generated commits.
+var skipPrefixes = []string{"wip", "fixup!", "squash!", "amend!", "merge ", "revert \"", "initial commit"}
+
+func (c *Command) recentSubjects(limit int) *exec.Cmd {
+	args := []string{
+		"log",
+		"--no-merges",
+		"-n",
+		strconv.Itoa(limit),
+		"--format=%s",
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// RecentSubjects returns the subjects of the last commits, newest first.
+func (c *Command) RecentSubjects(limit int) ([]string, error) {
+	output, err := c.recentSubjects(limit).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+
+	return strings.Split(strings.TrimSpace(string(output)), "\n"), nil
+}
+
+// Commit is a commit of the history with the files it changed.
+type Commit struct {
+	Hash    string
+	Subject string
+	Files   []string
+}
+
+func (c *Command) history(limit int) *exec.Cmd {
+	args := []string{
+		"log",
+		"--no-merges",
+		"-n",
+		strconv.Itoa(limit),
+		"--name-only",
+		"--format=%x1e%H%x1f%s",
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// History returns the last commits with their changed files, newest first.
+func (c *Command) History(limit int) ([]Commit, error) {
+	output, err := c.history(limit).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return parseHistory(string(output)), nil
+}
+
+// parseHistory parses the records of the history: the hash and the subject
+// separated by \x1f, then the changed files, one per line.
+func parseHistory(output string) []Commit {
+	var commits []Commit
+	for _, record := range strings.Split(output, "\x1e") {
+		lines := strings.Split(strings.TrimSpace(record), "\n")
+		hash, subject, ok := strings.Cut(lines[0], "\x1f")
+		if !ok {
+			continue
+		}
+		commit := Commit{Hash: hash, Subject: subject}
+		for _, f := range lines[1:] {
+			if f = strings.TrimSpace(f); f != "" {
+				commit.Files = append(commit.Files, f)
+			}
+		}
+		commits = append(commits, commit)
+	}
+	return commits
+}
+
+// WellFormed reports whether the commit subject is a good example of the
+// project style: a few words, at most 72 characters and not a work in progress.
+func WellFormed(subject string) bool {
+	subject = strings.TrimSpace(subject)
+	if len(subject) < 10 || len(subject) > 72 || strings.HasSuffix(subject, ".") {
+		return false
+	}
+	lower := strings.ToLower(subject)
+	for _, p := range skipPrefixes {
+		if strings.HasPrefix(lower, p) {
+			return false
+		}
+	}
+	return len(strings.Fields(TrimPrefix(subject))) >= 2
+}
+
+// TrimPrefix removes the conventional commit prefix of the subject.
+func TrimPrefix(subject string) string {
+	return conventionalPrefix.ReplaceAllString(subject, "")
+}
+
+// Examples returns up to n distinct well-formed subjects without their
+// conventional commit prefix, in the given order.
+func Examples(subjects []string, n int) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, s := range subjects {
+		if len(out) >= n {
+			break
+		}
+		if !WellFormed(s) {
+			continue
+		}
+		s = TrimPrefix(strings.TrimSpace(s))
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		out = append(out, s)
+	}
+	return out
+}
+
+func (c *Command) lastCommit(file string) *exec.Cmd {
+	args := []string{
+		"log",
+		"-1",
+		"--format=%H",
+		"--",
+		file,
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// LastCommit returns the hash of the last commit changing the file, empty if there's none.
+func (c *Command) LastCommit(file string) (string, error) {
+	output, err := c.lastCommit(file).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,50 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWellFormed(t *testing.T) {
+	tests := map[string]bool{
+		"Add the scope map to the config":      true,
+		"feat(cli): add the translate command": true,
+		"WIP login":                            false,
+		"fixup! add the scope map":             false,
+		"Merge branch 'main' into feature":     false,
+		"Fix the tests.":                       false,
+		"update":                               false,
+		"feat: foo":                            false,
+	}
+	for subject, want := range tests {
+		if got := WellFormed(subject); got != want {
+			t.Errorf("WellFormed(%q) = %v, want %v", subject, got, want)
+		}
+	}
+}
+
+func TestExamples(t *testing.T) {
+	subjects := []string{
+		"feat(cli): add the translate command",
+		"wip",
+		"fix: handle the empty diff",
+		"Handle the empty diff",
+		"Raise the amount of returned recordings",
+	}
+	want := []string{"add the translate command", "handle the empty diff", "Handle the empty diff"}
+	if got := Examples(subjects, 3); !reflect.DeepEqual(got, want) {
+		t.Errorf("Examples() = %q, want %q", got, want)
+	}
+}
+
+func TestParseHistory(t *testing.T) {
+	output := "\x1eabc123\x1ffeat: add the models command\n\ncmd/models.go\nREADME.md\n" +
+		"\x1edef456\x1fdocs: empty commit\n"
+	want := []Commit{
+		{Hash: "abc123", Subject: "feat: add the models command", Files: []string{"cmd/models.go", "README.md"}},
+		{Hash: "def456", Subject: "docs: empty commit"},
+	}
+	if got := parseHistory(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseHistory() = %+v, want %+v", got, want)
+	}
+}
@@ -3,6 +3,9 @@ package git
 import (
 	"embed"
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/appleboy/CodeGPT/util"
 )
@@ -12,7 +15,9 @@ var files embed.FS
 
 const (
 	HookPrepareCommitMessageTemplate = "prepare-commit-msg"
-	CommitMessageTemplate            = "commit-msg.tmpl"
+	// HookCommitMessageTemplate validates the written messages against Conventional Commits.
+	HookCommitMessageTemplate = "commit-msg"
+	CommitMessageTemplate     = "commit-msg.tmpl"
 )
 
 func init() {
@@ -20,3 +25,106 @@ func init() {
 		log.Fatal(err)
 	}
 }
+
+// The reasons to skip the commit message generation of the prepare-commit-msg hook:
+// the sources of the message given by git to the hook and the operations in progress.
+const (
+	// HookMessage is a message given with -m or -F, or the --no-edit one of cherry-pick.
+	HookMessage = "message"
+	// HookTemplate is the message of commit.template or -t.
+	HookTemplate = "template"
+	// HookMerge is the message of a merge commit or of .git/MERGE_MSG.
+	HookMerge = "merge"
+	// HookSquash is the message of .git/SQUASH_MSG after git merge --squash.
+	HookSquash = "squash"
+	// HookCommit is the message of an existing commit, with --amend, -c or -C.
+	HookCommit = "commit"
+	// HookFixup is a fixup!, squash! or amend! message for git rebase --autosquash.
+	HookFixup = "fixup"
+	// HookRebase is a commit made while a rebase is in progress.
+	HookRebase = "rebase"
+	// HookCherryPick is a commit made while a cherry-pick is in progress.
+	HookCherryPick = "cherry-pick"
+	// HookRevert is a commit made while a revert is in progress.
+	HookRevert = "revert"
+)
+
+// DefaultHookSkip lists the cases where the hook keeps the existing message,
+// only the new commits without message or with the template get one.
+var DefaultHookSkip = []string{
+	HookMessage, HookMerge, HookSquash, HookCommit,
+	HookFixup, HookRebase, HookCherryPick, HookRevert,
+}
+
+// fixupPrefixes are the prefixes of the messages of git commit --fixup and --squash.
+var fixupPrefixes = []string{"fixup! ", "squash! ", "amend! "}
+
+// SkipHook returns why the hook keeps the existing message instead of generating one,
+// from the source of the message, the message itself and the operations in progress.
+// Only the reasons of the skip list count, it returns an empty string otherwise.
+func SkipHook(source, message string, inProgress, skip []string) string {
+	var reasons []string
+	if source != "" {
+		reasons = append(reasons, source)
+	}
+	if isFixup(message) {
+		reasons = append(reasons, HookFixup)
+	}
+	reasons = append(reasons, inProgress...)
+
+	for _, r := range reasons {
+		for _, s := range skip {
+			if strings.EqualFold(strings.TrimSpace(s), r) {
+				return r
+			}
+		}
+	}
+	return ""
+}
+
+// isFixup reports whether the first line of the message, comments aside,
+// is the one of a fixup, squash or amend commit.
+func isFixup(message string) bool {
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+		for _, p := range fixupPrefixes {
+			if strings.HasPrefix(line, p) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// InProgress returns the operations in progress in the repository: rebase,
+// cherry-pick, revert or merge, from the state files of the git directory.
+func (c *Command) InProgress() []string {
+	dir, err := c.GitDir()
+	if err != nil {
+		return nil
+	}
+	dir = strings.TrimSpace(dir)
+
+	var out []string
+	states := []struct {
+		name  string
+		files []string
+	}{
+		{name: HookRebase, files: []string{"rebase-merge", "rebase-apply"}},
+		{name: HookCherryPick, files: []string{"CHERRY_PICK_HEAD"}},
+		{name: HookRevert, files: []string{"REVERT_HEAD"}},
+		{name: HookMerge, files: []string{"MERGE_HEAD"}},
+	}
+	for _, s := range states {
+		for _, f := range s.files {
+			if _, err := os.Stat(filepath.Join(dir, f)); err == nil {
+				out = append(out, s.name)
+				break
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,38 @@
+package git
+
+import "testing"
+
+func TestSkipHook(t *testing.T) {
+	tests := []struct {
+		name       string
+		source     string
+		message    string
+		inProgress []string
+		skip       []string
+		want       string
+	}{
+		{name: "new commit", want: ""},
+		{name: "template", source: HookTemplate, message: "# Describe the change\n", want: ""},
+		{name: "message", source: HookMessage, message: "Fix the login\n", want: HookMessage},
+		{name: "merge", source: HookMerge, message: "Merge branch 'main'\n", want: HookMerge},
+		{name: "squash", source: HookSquash, want: HookSquash},
+		{name: "amend", source: HookCommit, want: HookCommit},
+		{name: "fixup", message: "# comment\nfixup! Fix the login\n", skip: []string{HookFixup}, want: HookFixup},
+		{name: "rebase in progress", inProgress: []string{HookRebase}, want: HookRebase},
+		{name: "cherry-pick in progress", source: HookMessage, inProgress: []string{HookCherryPick}, want: HookMessage},
+		{name: "messages kept by config", source: HookMessage, skip: []string{"MERGE", " message"}, want: HookMessage},
+		{name: "messages replaced by config", source: HookMessage, skip: []string{HookMerge}, want: ""},
+		{name: "nothing skipped", source: HookMerge, skip: []string{""}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			skip := tt.skip
+			if skip == nil {
+				skip = DefaultHookSkip
+			}
+			if got := SkipHook(tt.source, tt.message, tt.inProgress, skip); got != tt.want {
+				t.Errorf("SkipHook() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
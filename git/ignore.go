@@ -0,0 +1,100 @@
+package git
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// IgnoreFile is the name of the file in the repository root that lists
+// gitignore-style patterns of files to strip from the git diff.
+const IgnoreFile = ".codegptignore"
+
+// ReadIgnoreFile reads gitignore-style patterns from the given file.
+// Blank lines and lines starting with # are skipped.
+func ReadIgnoreFile(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+
+	return patterns, scanner.Err()
+}
+
+// excludePathspec converts a gitignore-style pattern to a git exclude pathspec.
+//
+//   - a pattern without a slash matches at any level (`*.lock` -> `**/*.lock`)
+//   - a leading slash anchors the pattern to the repository root
+//   - a trailing slash matches everything inside the directory
+func excludePathspec(pattern string) string {
+	pattern = strings.TrimSpace(pattern)
+
+	dir := strings.HasSuffix(pattern, "/")
+	pattern = strings.TrimSuffix(pattern, "/")
+
+	switch {
+	case strings.HasPrefix(pattern, "/"):
+		pattern = strings.TrimPrefix(pattern, "/")
+	case !strings.Contains(pattern, "/") && !strings.HasPrefix(pattern, "**"):
+		pattern = "**/" + pattern
+	}
+
+	if dir {
+		pattern += "/**"
+	}
+
+	return ":(exclude,glob)" + pattern
+}
+
+// globPattern converts the glob of a git pathspec to a regular expression: ** matches
+// across the directories, * and ? only inside a path segment.
+func globPattern(glob string) *regexp.Regexp {
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			sb.WriteString("[^/]*")
+		case glob[i] == '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.MustCompile(sb.String())
+}
+
+// Excluded reports whether the file, a path from the repository root, matches the
+// exclude list like the diffs do: the pattern matches the file or one of its directories.
+func (c *Command) Excluded(file string) bool {
+	file = path.Clean(strings.TrimPrefix(file, "./"))
+	for _, p := range c.excludeList {
+		re := globPattern(strings.TrimPrefix(excludePathspec(p), ":(exclude,glob)"))
+		for name := file; name != "." && name != "/"; name = path.Dir(name) {
+			if re.MatchString(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package git
+
+import "testing"
+
+func TestExcludePathspec(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    string
+	}{
+		{"*.lock", ":(exclude,glob)**/*.lock"},
+		{"go.sum", ":(exclude,glob)**/go.sum"},
+		{"/go.sum", ":(exclude,glob)go.sum"},
+		{"vendor/", ":(exclude,glob)**/vendor/**"},
+		{"/web/dist/", ":(exclude,glob)web/dist/**"},
+		{"web/*.min.js", ":(exclude,glob)web/*.min.js"},
+		{"**/generated/*.go", ":(exclude,glob)**/generated/*.go"},
+	}
+
+	for _, tt := range tests {
+		if got := excludePathspec(tt.pattern); got != tt.want {
+			t.Errorf("excludePathspec(%q) = %q, want %q", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestExcluded(t *testing.T) {
+	c := &Command{excludeList: []string{"*.lock", "/go.sum", "vendor/", "web/*.min.js", "secrets"}}
+
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{"yarn.lock", true},
+		{"web/yarn.lock", true},
+		{"go.sum", true},
+		{"tools/go.sum", false},
+		{"vendor/github.com/pkg/errors/errors.go", true},
+		{"web/app.min.js", true},
+		{"web/lib/app.min.js", false},
+		{"config/secrets/token.go", true},
+		{"main.go", false},
+	}
+
+	for _, tt := range tests {
+		if got := c.Excluded(tt.file); got != tt.want {
+			t.Errorf("Excluded(%q) = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+}
@@ -1,5 +1,8 @@
 package git
 
+// defaultMaxFileSize is the default maximum size in bytes of a file to include in the git diff.
+const defaultMaxFileSize = 100 * 1024
+
 // Option is an interface that specifies instrumentation configuration options.
 type Option interface {
 	apply(*config)
@@ -42,9 +45,61 @@ func WithEnableAmend(val bool) Option {
 	})
 }
 
+// WithMaxFileSize returns an Option that sets the maximum size in bytes of a file to include in the git diff.
+// Larger files are summarized in one line instead.
+func WithMaxFileSize(val int64) Option {
+	return optionFunc(func(c *config) {
+		// If the given value is not positive, keep the default.
+		if val <= 0 {
+			return
+		}
+		c.maxFileSize = val
+	})
+}
+
+// WithWorkingTree returns an Option that diffs the tracked files of the working tree
+// instead of the staged changes.
+func WithWorkingTree(val bool) Option {
+	return optionFunc(func(c *config) {
+		c.workingTree = val
+	})
+}
+
+// WithIncludeUntracked returns an Option that also diffs the untracked files of the working tree.
+func WithIncludeUntracked(val bool) Option {
+	return optionFunc(func(c *config) {
+		c.includeUntracked = val
+	})
+}
+
+// WithRange returns an Option that diffs a range of revisions instead of the staged changes.
+// If mergeBase is true, the diff starts from the merge base of both revisions like `git diff from...to`.
+// An empty to revision means HEAD.
+func WithRange(from, to string, mergeBase bool) Option {
+	return optionFunc(func(c *config) {
+		if from == "" {
+			return
+		}
+		if to == "" {
+			to = "HEAD"
+		}
+		c.fromRev = from
+		c.toRev = to
+		c.mergeBase = mergeBase
+	})
+}
+
 // config is a struct that stores configuration options for the instrumentation.
 type config struct {
 	diffUnified int
 	excludeList []string
 	isAmend     bool
+	maxFileSize int64
+
+	workingTree      bool
+	includeUntracked bool
+
+	fromRev   string
+	toRev     string
+	mergeBase bool
 }
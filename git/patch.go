@@ -0,0 +1,131 @@
+package git
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Hunk is a single hunk of a file patch, starting with the `@@` header line.
+type Hunk struct {
+	Header   string
+	Lines    []string
+	Selected bool
+}
+
+// FilePatch is the patch of a single file, the header contains the lines
+// before the first hunk (diff --git, index, ---, +++).
+type FilePatch struct {
+	Header []string
+	Hunks  []*Hunk
+}
+
+// Name returns the path of the file from the diff --git header line.
+func (f *FilePatch) Name() string {
+	if len(f.Header) == 0 {
+		return ""
+	}
+	line := f.Header[0]
+	if i := strings.LastIndex(line, " b/"); i >= 0 {
+		return line[i+3:]
+	}
+	return strings.TrimPrefix(line, "diff --git ")
+}
+
+// ParsePatch splits a unified diff into file patches and hunks.
+// Every hunk is selected by default.
+func ParsePatch(diff string) []*FilePatch {
+	var files []*FilePatch
+	var file *FilePatch
+	var hunk *Hunk
+
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			file = &FilePatch{Header: []string{line}}
+			files = append(files, file)
+			hunk = nil
+		case file == nil:
+			continue
+		case strings.HasPrefix(line, "@@"):
+			hunk = &Hunk{Header: line, Selected: true}
+			file.Hunks = append(file.Hunks, hunk)
+		case hunk != nil:
+			hunk.Lines = append(hunk.Lines, line)
+		default:
+			file.Header = append(file.Header, line)
+		}
+	}
+
+	return files
+}
+
+// SplitNotes splits the diff into the patch and the lists of the renamed, binary and
+// generated files appended after it by DiffFiles. git never writes empty lines in a
+// patch, the notes start at the first one.
+func SplitNotes(diff string) (string, string) {
+	if i := strings.Index(diff, "\n\n"); i >= 0 {
+		return diff[:i+1], diff[i+1:]
+	}
+	return diff, ""
+}
+
+// FormatPatch joins the hunks with the given selection state back into a unified diff.
+// Files without hunks, like binary or mode changes, are only kept in the selected patch.
+func FormatPatch(files []*FilePatch, selected bool) string {
+	var sb strings.Builder
+	for _, f := range files {
+		var hunks []*Hunk
+		for _, h := range f.Hunks {
+			if h.Selected == selected {
+				hunks = append(hunks, h)
+			}
+		}
+
+		if len(hunks) == 0 && (len(f.Hunks) > 0 || !selected) {
+			continue
+		}
+
+		for _, line := range f.Header {
+			sb.WriteString(line + "\n")
+		}
+		for _, h := range hunks {
+			sb.WriteString(h.Header + "\n")
+			for _, line := range h.Lines {
+				sb.WriteString(line + "\n")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// hunkStart matches the start of the new file range of a hunk header like @@ -1,3 +4,5 @@.
+var hunkStart = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)`)
+
+// ChangedLines returns the lines of the new file changed by the hunk, starting at 1:
+// the added lines and the line following the deleted lines.
+func (h *Hunk) ChangedLines() []int {
+	m := hunkStart.FindStringSubmatch(h.Header)
+	if m == nil {
+		return nil
+	}
+	line, _ := strconv.Atoi(m[1])
+	var lines []int
+	for _, l := range h.Lines {
+		switch {
+		case strings.HasPrefix(l, "+"):
+			lines = append(lines, line)
+			line++
+		case strings.HasPrefix(l, "-"):
+			if len(lines) == 0 || lines[len(lines)-1] != line {
+				lines = append(lines, line)
+			}
+		case strings.HasPrefix(l, `\`):
+			// \ No newline at end of file
+		default:
+			line++
+		}
+	}
+	return lines
+}
@@ -0,0 +1,90 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+const testPatch = `diff --git a/main.go b/main.go
+index aadf691..bfef603 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,3 @@
+ package main
+-// foo
++// bar
+ 
+@@ -10,2 +10,3 @@ func main() {
+ 	run()
++	exit()
+ }
+diff --git a/logo.png b/logo.png
+index aadf691..bfef603 100644
+Binary files a/logo.png and b/logo.png differ
+`
+
+func TestParsePatch(t *testing.T) {
+	files := ParsePatch(testPatch)
+	if len(files) != 2 {
+		t.Fatalf("ParsePatch() returned %d files, want 2", len(files))
+	}
+
+	if name := files[0].Name(); name != "main.go" {
+		t.Errorf("Name() = %q, want main.go", name)
+	}
+
+	if len(files[0].Hunks) != 2 || len(files[1].Hunks) != 0 {
+		t.Errorf("ParsePatch() hunks = %d, %d; want 2, 0", len(files[0].Hunks), len(files[1].Hunks))
+	}
+
+	if got := FormatPatch(files, true); got != testPatch {
+		t.Errorf("FormatPatch() = %q, want %q", got, testPatch)
+	}
+}
+
+func TestFormatPatchSelection(t *testing.T) {
+	files := ParsePatch(testPatch)
+	files[0].Hunks[1].Selected = false
+
+	want := `diff --git a/main.go b/main.go
+index aadf691..bfef603 100644
+--- a/main.go
++++ b/main.go
+@@ -10,2 +10,3 @@ func main() {
+ 	run()
++	exit()
+ }
+`
+	if got := FormatPatch(files, false); got != want {
+		t.Errorf("FormatPatch() = %q, want %q", got, want)
+	}
+}
+
+func TestChangedLines(t *testing.T) {
+	h := &Hunk{
+		Header: "@@ -10,6 +10,6 @@ func main() {",
+		Lines: []string{
+			" 	run()",
+			"-	exit()",
+			"-	stop()",
+			" 	wait()",
+			"+	done()",
+			"+	close()",
+			" }",
+		},
+	}
+	if got, want := h.ChangedLines(), []int{11, 12, 13}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedLines() = %v, want %v", got, want)
+	}
+}
+
+func TestSplitNotes(t *testing.T) {
+	diff := "diff --git a/a.go b/a.go\n@@ -1 +1 @@\n-a\n+b\n \n\nBinary or large files (content omitted):\n- added image logo.png, +12KB\n"
+	patch, notes := SplitNotes(diff)
+	if patch != "diff --git a/a.go b/a.go\n@@ -1 +1 @@\n-a\n+b\n \n" || notes != "\nBinary or large files (content omitted):\n- added image logo.png, +12KB\n" {
+		t.Errorf("SplitNotes() = %q, %q", patch, notes)
+	}
+	if patch, notes := SplitNotes("diff --git a/a.go b/a.go\n"); patch != "diff --git a/a.go b/a.go\n" || notes != "" {
+		t.Errorf("SplitNotes() without notes = %q, %q", patch, notes)
+	}
+}
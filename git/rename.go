@@ -0,0 +1,53 @@
+package git
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RenameEvent describes a file that was renamed or copied in the git diff.
+type RenameEvent struct {
+	// Kind is either "renamed" or "copied"
+	Kind       string
+	From       string
+	To         string
+	Similarity int
+}
+
+// String returns a one-line description like "renamed pkg/foo.go → pkg/bar/foo.go, 92% similar".
+func (e RenameEvent) String() string {
+	return e.Kind + " " + e.From + " → " + e.To + ", " + strconv.Itoa(e.Similarity) + "% similar"
+}
+
+// ParseRenames extracts the renamed and copied files from the output of
+// the `git diff -M -C` command.
+func ParseRenames(diff string) []RenameEvent {
+	var events []RenameEvent
+	var current *RenameEvent
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			current = nil
+		case strings.HasPrefix(line, "similarity index "):
+			val := strings.TrimSuffix(strings.TrimPrefix(line, "similarity index "), "%")
+			similarity, _ := strconv.Atoi(val)
+			events = append(events, RenameEvent{Similarity: similarity})
+			current = &events[len(events)-1]
+		case current == nil:
+			continue
+		case strings.HasPrefix(line, "rename from "):
+			current.Kind = "renamed"
+			current.From = strings.TrimPrefix(line, "rename from ")
+		case strings.HasPrefix(line, "rename to "):
+			current.To = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "copy from "):
+			current.Kind = "copied"
+			current.From = strings.TrimPrefix(line, "copy from ")
+		case strings.HasPrefix(line, "copy to "):
+			current.To = strings.TrimPrefix(line, "copy to ")
+		}
+	}
+
+	return events
+}
@@ -0,0 +1,40 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRenames(t *testing.T) {
+	diff := `diff --git a/pkg/foo.go b/pkg/bar/foo.go
+similarity index 92%
+rename from pkg/foo.go
+rename to pkg/bar/foo.go
+index aadf691..bfef603 100644
+--- a/pkg/foo.go
++++ b/pkg/bar/foo.go
+@@ -1 +1 @@
+-package pkg
++package bar
+diff --git a/main.go b/cmd/main.go
+similarity index 100%
+copy from main.go
+copy to cmd/main.go
+diff --git a/README.md b/README.md
+index aadf691..bfef603 100644
+`
+
+	want := []RenameEvent{
+		{Kind: "renamed", From: "pkg/foo.go", To: "pkg/bar/foo.go", Similarity: 92},
+		{Kind: "copied", From: "main.go", To: "cmd/main.go", Similarity: 100},
+	}
+
+	got := ParseRenames(diff)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseRenames() = %v, want %v", got, want)
+	}
+
+	if s := got[0].String(); s != "renamed pkg/foo.go → pkg/bar/foo.go, 92% similar" {
+		t.Errorf("RenameEvent.String() = %q", s)
+	}
+}
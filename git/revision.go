@@ -0,0 +1,31 @@
+package git
+
+import "strings"
+
+// ParseRange parses a revision range like `main...feature`, `v1.0..v1.1` or a single commit.
+// A three-dot range diffs from the merge base of both revisions, a single commit is
+// compared with its parent, or with the empty tree for a root commit, like git show.
+// An empty side of the range means HEAD.
+func ParseRange(val string) (from, to string, mergeBase bool) {
+	val = strings.TrimSpace(val)
+
+	if i := strings.Index(val, "..."); i >= 0 {
+		from, to, mergeBase = val[:i], val[i+3:], true
+	} else if i := strings.Index(val, ".."); i >= 0 {
+		from, to = val[:i], val[i+2:]
+	} else {
+		// an unknown revision keeps its parent, git reports it with the diff
+		if parents, err := New().Parents(val); err == nil && len(parents) == 0 {
+			return EmptyTree, val, false
+		}
+		return val + "^", val, false
+	}
+
+	if from == "" {
+		from = "HEAD"
+	}
+	if to == "" {
+		to = "HEAD"
+	}
+	return from, to, mergeBase
+}
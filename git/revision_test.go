@@ -0,0 +1,77 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		val       string
+		from      string
+		to        string
+		mergeBase bool
+	}{
+		{"origin/main...HEAD", "origin/main", "HEAD", true},
+		{"v1.0..v1.1", "v1.0", "v1.1", false},
+		{"main..", "main", "HEAD", false},
+		{"abc123", "abc123^", "abc123", false},
+	}
+
+	for _, tt := range tests {
+		from, to, mergeBase := ParseRange(tt.val)
+		if from != tt.from || to != tt.to || mergeBase != tt.mergeBase {
+			t.Errorf("ParseRange(%q) = %q, %q, %v; want %q, %q, %v",
+				tt.val, from, to, mergeBase, tt.from, tt.to, tt.mergeBase)
+		}
+	}
+}
+
+func TestParseRangeRootCommit(t *testing.T) {
+	dir := t.TempDir()
+	run := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	run("init", "-q")
+	run("config", "user.name", "CodeGPT")
+	run("config", "user.email", "codegpt@example.com")
+	run("config", "commit.gpgsign", "false")
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	run("add", "main.go")
+	run("commit", "-q", "-m", "feat: initial commit")
+	root := run("rev-parse", "HEAD")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	for _, val := range []string{"HEAD", root} {
+		from, to, mergeBase := ParseRange(val)
+		if from != EmptyTree || to != val || mergeBase {
+			t.Fatalf("ParseRange(%q) = %q, %q, %v; want %q, %q, false", val, from, to, mergeBase, EmptyTree, val)
+		}
+		diff, err := New(WithRange(from, to, mergeBase)).DiffFiles()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(diff, "+package main") {
+			t.Errorf("DiffFiles() of the root commit = %q, want the added main.go", diff)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// EmptyTree is the hash of the empty tree, the changes of a root commit are diffed from it.
+const EmptyTree = "4b825dc642cb6eb9a060e54bf8d69288fbee4904"
+
+func (c *Command) resolveCommit(rev string) *exec.Cmd {
+	args := []string{
+		"rev-parse",
+		"--verify",
+		"--quiet",
+		rev + "^{commit}",
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// ResolveCommit returns the full hash of the commit of the revision.
+func (c *Command) ResolveCommit(rev string) (string, error) {
+	output, err := c.resolveCommit(rev).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s isn't a commit", rev)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (c *Command) isAncestor(rev, of string) *exec.Cmd {
+	args := []string{
+		"merge-base",
+		"--is-ancestor",
+		rev,
+		of,
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// IsAncestor reports whether the commit rev is in the history of the commit of.
+func (c *Command) IsAncestor(rev, of string) bool {
+	return c.isAncestor(rev, of).Run() == nil
+}
+
+func (c *Command) revList(args ...string) *exec.Cmd {
+	return exec.Command(
+		"git",
+		append([]string{"rev-list"}, args...)...,
+	)
+}
+
+// Parents returns the hashes of the parents of the commit.
+func (c *Command) Parents(hash string) ([]string, error) {
+	output, err := c.revList("--parents", "--max-count=1", hash).Output()
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%s isn't a commit", hash)
+	}
+	return fields[1:], nil
+}
+
+// Merges returns the hashes of the merge commits of the revision range.
+func (c *Command) Merges(revisions string) ([]string, error) {
+	output, err := c.revList("--merges", revisions).Output()
+	if err != nil {
+		return nil, err
+	}
+	return splitLines(string(output)), nil
+}
+
+func (c *Command) remoteBranches(hash string) *exec.Cmd {
+	args := []string{
+		"branch",
+		"--remotes",
+		"--contains",
+		hash,
+		"--format=%(refname:short)",
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// RemoteBranches returns the remote-tracking branches containing the commit, the ones
+// it's already pushed to.
+func (c *Command) RemoteBranches(hash string) []string {
+	output, err := c.remoteBranches(hash).Output()
+	if err != nil {
+		return nil
+	}
+	return splitLines(string(output))
+}
+
+func (c *Command) amendMessage(file string) *exec.Cmd {
+	args := []string{
+		"commit",
+		"--amend",
+		"--only",
+		"--no-verify",
+		"--allow-empty",
+		"--file=" + file,
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+func (c *Command) rebaseReword(parent string) *exec.Cmd {
+	args := []string{
+		"rebase",
+		"--interactive",
+		"--autostash",
+	}
+	if parent == "" {
+		args = append(args, "--root")
+	} else {
+		args = append(args, parent)
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// Reword replaces the message of the commit of the current branch. The HEAD commit is
+// amended, the older ones are reworded by an interactive rebase from their parent, with
+// the todo list and the message written by the sequence editor and the editor of git.
+// The merge commits after the commit aren't kept, Reword must not be used with them.
+func (c *Command) Reword(hash, message string) error {
+	dir, err := os.MkdirTemp("", "codegpt-reword-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	messageFile := filepath.Join(dir, "COMMIT_EDITMSG")
+	if err := os.WriteFile(messageFile, []byte(message+"\n"), 0o600); err != nil {
+		return err
+	}
+
+	if head, err := c.ResolveCommit("HEAD"); err == nil && head == hash {
+		return run(c.amendMessage(messageFile))
+	}
+
+	parents, err := c.Parents(hash)
+	if err != nil {
+		return err
+	}
+	parent, revisions := "", "HEAD"
+	if len(parents) > 0 {
+		parent = parents[0]
+		revisions = parent + "..HEAD"
+	}
+	output, err := c.revList("--reverse", "--no-merges", revisions).Output()
+	if err != nil {
+		return err
+	}
+	var todo strings.Builder
+	for _, h := range splitLines(string(output)) {
+		action := "pick"
+		if h == hash {
+			action = "reword"
+		}
+		todo.WriteString(action + " " + h + "\n")
+	}
+	todoFile := filepath.Join(dir, "git-rebase-todo")
+	if err := os.WriteFile(todoFile, []byte(todo.String()), 0o600); err != nil {
+		return err
+	}
+
+	// git runs the editors with the path of the file to edit as last argument
+	cmd := c.rebaseReword(parent)
+	cmd.Env = append(os.Environ(),
+		"GIT_SEQUENCE_EDITOR=cp "+shellQuote(todoFile),
+		"GIT_EDITOR=cp "+shellQuote(messageFile),
+	)
+	return run(cmd)
+}
+
+// shellQuote quotes the path for the shell running the editors of git.
+func shellQuote(path string) string {
+	return "'" + strings.ReplaceAll(filepath.ToSlash(path), "'", `'\''`) + "'"
+}
+
+// run runs the command, its error includes the standard error output.
+func run(cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
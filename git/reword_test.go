@@ -0,0 +1,19 @@
+package git
+
+import "testing"
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/tmp/codegpt-reword-1/COMMIT_EDITMSG", want: "'/tmp/codegpt-reword-1/COMMIT_EDITMSG'"},
+		{path: "/home/o'neil/tmp/todo", want: `'/home/o'\''neil/tmp/todo'`},
+		{path: "/tmp/with space/todo", want: "'/tmp/with space/todo'"},
+	}
+	for _, tt := range tests {
+		if got := shellQuote(tt.path); got != tt.want {
+			t.Errorf("shellQuote(%q) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
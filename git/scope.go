@@ -0,0 +1,58 @@
+package git
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// MatchPattern reports whether the file matches the pattern, like the ones of git.scope_map.
+//
+//   - a trailing slash matches everything inside the directory (`cmd/`)
+//   - a pattern without a slash matches the file name at any level (`*.md`)
+//   - any other pattern matches the whole path (`docs/*.md`)
+func MatchPattern(pattern, file string) bool {
+	pattern = strings.TrimPrefix(strings.TrimSpace(pattern), "/")
+	if strings.HasSuffix(pattern, "/") {
+		return strings.HasPrefix(file, pattern)
+	}
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(file))
+		return ok
+	}
+	ok, _ := path.Match(pattern, file)
+	return ok
+}
+
+// ScopeOf returns the conventional commit scope of the files from the map of
+// patterns to scopes, or an empty string if the files don't share one scope.
+// The longest matching pattern wins for every file.
+func ScopeOf(files []string, scopes map[string]string) string {
+	patterns := make([]string, 0, len(scopes))
+	for p := range scopes {
+		patterns = append(patterns, p)
+	}
+	// longest first, so `cmd/internal/` wins over `cmd/`
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+
+	scope := ""
+	for _, f := range files {
+		matched := ""
+		for _, p := range patterns {
+			if MatchPattern(p, f) {
+				matched = scopes[p]
+				break
+			}
+		}
+		if matched == "" || (scope != "" && matched != scope) {
+			return ""
+		}
+		scope = matched
+	}
+	return scope
+}
@@ -0,0 +1,32 @@
+package git
+
+import "testing"
+
+func TestScopeOf(t *testing.T) {
+	scopes := map[string]string{
+		"cmd/":          "cli",
+		"cmd/internal/": "internal",
+		"*.md":          "docs",
+		"docs/*.png":    "docs",
+	}
+
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{"directory", []string{"cmd/commit.go", "cmd/review.go"}, "cli"},
+		{"longest pattern", []string{"cmd/internal/x.go"}, "internal"},
+		{"file name at any level", []string{"README.md", "git/README.md", "docs/logo.png"}, "docs"},
+		{"different scopes", []string{"cmd/commit.go", "README.md"}, ""},
+		{"unmapped file", []string{"cmd/commit.go", "go.mod"}, ""},
+		{"no files", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ScopeOf(tt.files, scopes); got != tt.want {
+				t.Errorf("ScopeOf(%v) = %q, want %q", tt.files, got, tt.want)
+			}
+		})
+	}
+}
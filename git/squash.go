@@ -0,0 +1,73 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// MergeBase returns the best common ancestor of the two revisions.
+func (c *Command) MergeBase(from, to string) (string, error) {
+	output, err := c.mergeBaseOf(from, to).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (c *Command) hasStagedChanges() *exec.Cmd {
+	args := []string{
+		"diff",
+		"--cached",
+		"--quiet",
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// HasStagedChanges reports whether the index differs from HEAD.
+func (c *Command) HasStagedChanges() bool {
+	return c.hasStagedChanges().Run() != nil
+}
+
+func (c *Command) softReset(rev string) *exec.Cmd {
+	args := []string{
+		"reset",
+		"--soft",
+		rev,
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// SoftReset moves the current branch to the revision, the changes of the commits
+// after it stay staged.
+func (c *Command) SoftReset(rev string) error {
+	output, err := c.softReset(rev).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// CoAuthors returns the Co-authored-by trailers of the distinct authors of the commits,
+// in order, except the author with the given email.
+func CoAuthors(entries []LogEntry, email string) []string {
+	var trailers []string
+	seen := map[string]bool{strings.ToLower(email): true}
+	for _, e := range entries {
+		key := strings.ToLower(e.Email)
+		if e.Email == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		trailers = append(trailers, "Co-authored-by: "+e.Author+" <"+e.Email+">")
+	}
+	return trailers
+}
@@ -0,0 +1,25 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCoAuthors(t *testing.T) {
+	entries := []LogEntry{
+		{Author: "Bo", Email: "bo@example.com"},
+		{Author: "Ann", Email: "ann@example.com"},
+		{Author: "Bo", Email: "BO@example.com"},
+		{Author: "Cy", Email: "cy@example.com"},
+	}
+	want := []string{
+		"Co-authored-by: Bo <bo@example.com>",
+		"Co-authored-by: Cy <cy@example.com>",
+	}
+	if got := CoAuthors(entries, "ann@example.com"); !reflect.DeepEqual(got, want) {
+		t.Errorf("CoAuthors() = %q, want %q", got, want)
+	}
+	if got := CoAuthors(entries[1:2], "ann@example.com"); got != nil {
+		t.Errorf("CoAuthors() = %q, want none", got)
+	}
+}
@@ -0,0 +1,114 @@
+package git
+
+import (
+	"path"
+	"strconv"
+	"strings"
+)
+
+// FileStat is the number of added and deleted lines of a file in the git diff.
+type FileStat struct {
+	Path    string
+	Added   int
+	Deleted int
+	Binary  bool
+}
+
+// ParseNumstat parses the output of the `git diff --numstat` command.
+// Binary files are reported by git with `-` instead of line counts.
+func ParseNumstat(output string) []FileStat {
+	var stats []FileStat
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		stat := FileStat{
+			Path: fields[2],
+		}
+		if fields[0] == "-" && fields[1] == "-" {
+			stat.Binary = true
+		} else {
+			stat.Added, _ = strconv.Atoi(fields[0])
+			stat.Deleted, _ = strconv.Atoi(fields[1])
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
+
+// fileKinds maps file extensions to a human-readable kind.
+var fileKinds = map[string]string{
+	".png":   "image",
+	".jpg":   "image",
+	".jpeg":  "image",
+	".gif":   "image",
+	".svg":   "image",
+	".ico":   "image",
+	".webp":  "image",
+	".bmp":   "image",
+	".ttf":   "font",
+	".otf":   "font",
+	".woff":  "font",
+	".woff2": "font",
+	".zip":   "archive",
+	".gz":    "archive",
+	".tar":   "archive",
+	".tgz":   "archive",
+	".jar":   "archive",
+	".pdf":   "document",
+	".mp3":   "audio",
+	".wav":   "audio",
+	".mp4":   "video",
+	".mov":   "video",
+	".exe":   "executable",
+	".dll":   "library",
+	".so":    "library",
+}
+
+// fileKind returns the kind of the file based on its extension.
+func fileKind(name string, binary bool) string {
+	if kind, ok := fileKinds[strings.ToLower(path.Ext(name))]; ok {
+		return kind
+	}
+	if binary {
+		return "binary"
+	}
+	return "large file"
+}
+
+// formatSize formats a size difference in bytes like +12KB or -3MB.
+func formatSize(delta int64) string {
+	sign := "+"
+	if delta < 0 {
+		sign = "-"
+		delta = -delta
+	}
+
+	switch {
+	case delta >= 1<<20:
+		return sign + strconv.FormatInt(delta>>20, 10) + "MB"
+	case delta >= 1<<10:
+		return sign + strconv.FormatInt(delta>>10, 10) + "KB"
+	default:
+		return sign + strconv.FormatInt(delta, 10) + "B"
+	}
+}
+
+// summarizeFile returns a one-line summary of a binary or large file change,
+// for example: "modified image assets/logo.png, +12KB".
+// A negative size means the file does not exist on that side of the diff.
+func summarizeFile(name string, binary bool, oldSize, newSize int64) string {
+	action := "modified"
+	switch {
+	case oldSize < 0:
+		action = "added"
+		oldSize = 0
+	case newSize < 0:
+		action = "deleted"
+		newSize = 0
+	}
+
+	return action + " " + fileKind(name, binary) + " " + name + ", " + formatSize(newSize-oldSize)
+}
@@ -0,0 +1,39 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNumstat(t *testing.T) {
+	output := "10\t2\tgit/git.go\n-\t-\tassets/logo.png\n"
+
+	want := []FileStat{
+		{Path: "git/git.go", Added: 10, Deleted: 2},
+		{Path: "assets/logo.png", Binary: true},
+	}
+
+	if got := ParseNumstat(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseNumstat() = %v, want %v", got, want)
+	}
+}
+
+func TestSummarizeFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		binary  bool
+		oldSize int64
+		newSize int64
+		want    string
+	}{
+		{"assets/logo.png", true, 1024, 13 * 1024, "modified image assets/logo.png, +12KB"},
+		{"bin/tool", true, -1, 3 << 20, "added binary bin/tool, +3MB"},
+		{"data/dump.sql", false, 2048, -1, "deleted large file data/dump.sql, -2KB"},
+	}
+
+	for _, tt := range tests {
+		if got := summarizeFile(tt.name, tt.binary, tt.oldSize, tt.newSize); got != tt.want {
+			t.Errorf("summarizeFile(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
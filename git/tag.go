@@ -0,0 +1,177 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+func (c *Command) latestTag(rev string) *exec.Cmd {
+	args := []string{
+		"describe",
+		"--tags",
+		"--abbrev=0",
+		rev,
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// LatestTag returns the closest tag reachable from the revision, empty if there's none.
+func (c *Command) LatestTag(rev string) (string, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+	output, err := c.latestTag(rev).CombinedOutput()
+	if err != nil {
+		// git describe fails when no tag can describe the revision
+		if strings.Contains(string(output), "No names found") || strings.Contains(string(output), "No tags can describe") {
+			return "", nil
+		}
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// LogEntry is a commit of the log with its author and its full message.
+type LogEntry struct {
+	Hash    string
+	Author  string
+	Email   string
+	Message string
+}
+
+func (c *Command) log(revisions string) *exec.Cmd {
+	args := []string{
+		"log",
+		"--no-merges",
+		"--format=%x1e%H%x1f%an%x1f%ae%x1f%B",
+		revisions,
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// Log returns the commits of the revision range, like v1.2.0..HEAD, newest first.
+func (c *Command) Log(revisions string) ([]LogEntry, error) {
+	output, err := c.log(revisions).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return parseLog(string(output)), nil
+}
+
+// parseLog parses the records of the log: the hash, the author name, the author
+// email and the message separated by \x1f.
+func parseLog(output string) []LogEntry {
+	var entries []LogEntry
+	for _, record := range strings.Split(output, "\x1e") {
+		fields := strings.SplitN(record, "\x1f", 4)
+		if len(fields) != 4 {
+			continue
+		}
+		entries = append(entries, LogEntry{
+			Hash:    strings.TrimSpace(fields[0]),
+			Author:  fields[1],
+			Email:   fields[2],
+			Message: strings.TrimSpace(fields[3]),
+		})
+	}
+	return entries
+}
+
+func (c *Command) createTag(name, message string) *exec.Cmd {
+	args := []string{
+		"tag",
+		"--annotate",
+		// keep the markdown headings of the message, they start with #
+		"--cleanup=verbatim",
+		"--message",
+		message,
+		name,
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// CreateTag creates the annotated tag of HEAD with the message.
+func (c *Command) CreateTag(name, message string) error {
+	output, err := c.createTag(name, message).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (c *Command) tagExists(name string) *exec.Cmd {
+	args := []string{
+		"rev-parse",
+		"--verify",
+		"--quiet",
+		"refs/tags/" + name,
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// TagExists reports whether the tag exists.
+func (c *Command) TagExists(name string) bool {
+	return c.tagExists(name).Run() == nil
+}
+
+func (c *Command) commitDate(rev string) *exec.Cmd {
+	args := []string{
+		"log",
+		"-1",
+		"--format=%cs",
+		rev,
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// CommitDate returns the committer date of the revision, like 2024-05-01.
+func (c *Command) CommitDate(rev string) (string, error) {
+	output, err := c.commitDate(rev).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (c *Command) remoteURL(name string) *exec.Cmd {
+	args := []string{
+		"remote",
+		"get-url",
+		name,
+	}
+
+	return exec.Command(
+		"git",
+		args...,
+	)
+}
+
+// RemoteURL returns the URL of the remote, empty if it doesn't exist.
+func (c *Command) RemoteURL(name string) string {
+	output, err := c.remoteURL(name).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
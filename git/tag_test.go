@@ -0,0 +1,21 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLog(t *testing.T) {
+	output := "\x1eabc123\x1fJane Doe\x1fjane@example.com\x1ffeat(cli): add the semver command\n\nSuggest the next version.\n\n" +
+		"\x1edef456\x1fJohn\x1fjohn@example.com\x1ffix: handle the empty log\n"
+	want := []LogEntry{
+		{Hash: "abc123", Author: "Jane Doe", Email: "jane@example.com", Message: "feat(cli): add the semver command\n\nSuggest the next version."},
+		{Hash: "def456", Author: "John", Email: "john@example.com", Message: "fix: handle the empty log"},
+	}
+	if got := parseLog(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseLog() = %+v, want %+v", got, want)
+	}
+	if got := parseLog(""); got != nil {
+		t.Errorf("parseLog() = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,122 @@
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	errorsMissingURL   = errors.New("missing Jira URL")
+	errorsMissingToken = errors.New("missing Jira API token")
+)
+
+// keyPattern matches the issue keys, like ABC-123.
+var keyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]+-[0-9]+$`)
+
+// IsKey reports whether the ticket ID is a Jira issue key, like ABC-123, not a GitHub issue like #42.
+func IsKey(ticket string) bool {
+	return keyPattern.MatchString(ticket)
+}
+
+// Issue is a Jira issue.
+type Issue struct {
+	Key         string `json:"key"`
+	Summary     string `json:"summary"`
+	Description string `json:"description"`
+}
+
+// Client reads the issues of a Jira site and comments them, with the REST API v2 of
+// Jira Cloud and Jira Data Center.
+type Client struct {
+	cfg *config
+}
+
+// New creates a new Jira client with the given options.
+func New(opts ...Option) (*Client, error) {
+	cfg := &config{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+	if cfg.baseURL == "" {
+		return nil, errorsMissingURL
+	}
+	if cfg.token == "" {
+		return nil, errorsMissingToken
+	}
+	return &Client{cfg: cfg}, nil
+}
+
+// Issue returns the summary and the description of the issue.
+// https://developer.atlassian.com/cloud/jira/platform/rest/v2/api-group-issues/#api-rest-api-2-issue-issueidorkey-get
+func (c *Client) Issue(ctx context.Context, key string) (*Issue, error) {
+	var out struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary     string `json:"summary"`
+			Description string `json:"description"`
+		} `json:"fields"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key)+"?fields=summary,description", nil, &out); err != nil {
+		return nil, err
+	}
+	return &Issue{Key: out.Key, Summary: out.Fields.Summary, Description: out.Fields.Description}, nil
+}
+
+// AddComment adds the comment to the issue, in the wiki markup of Jira.
+// https://developer.atlassian.com/cloud/jira/platform/rest/v2/api-group-issue-comments/#api-rest-api-2-issue-issueidorkey-comment-post
+func (c *Client) AddComment(ctx context.Context, key, body string) error {
+	return c.do(ctx, http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(key)+"/comment", map[string]string{"body": body}, nil)
+}
+
+// do sends a JSON request to the Jira API and decodes the JSON response into out.
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var reader io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(c.cfg.baseURL, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.cfg.email != "" {
+		req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(c.cfg.email+":"+c.cfg.token)))
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.token)
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.cfg.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("jira api error: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
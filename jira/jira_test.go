@@ -0,0 +1,90 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsKey(t *testing.T) {
+	tests := []struct {
+		ticket string
+		want   bool
+	}{
+		{"ABC-123", true},
+		{"AB2_X-1", true},
+		{"#42", false},
+		{"abc-123", false},
+		{"ABC-", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsKey(tt.ticket); got != tt.want {
+			t.Errorf("IsKey(%q) = %v, want %v", tt.ticket, got, tt.want)
+		}
+	}
+}
+
+func TestClientIssue(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/issue/ABC-123" || r.URL.Query().Get("fields") != "summary,description" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		// base64 of me@example.com:token
+		if r.Header.Get("Authorization") != "Basic bWVAZXhhbXBsZS5jb206dG9rZW4=" {
+			t.Errorf("unexpected authorization header: %s", r.Header.Get("Authorization"))
+		}
+		_, _ = w.Write([]byte(`{"key": "ABC-123", "fields": {"summary": "Login fails", "description": "The *login* page crashes"}}`))
+	}))
+	defer srv.Close()
+
+	if _, err := New(WithToken("token")); err != errorsMissingURL {
+		t.Errorf("New() error = %v, want %v", err, errorsMissingURL)
+	}
+
+	c, err := New(WithBaseURL(srv.URL+"/"), WithEmail("me@example.com"), WithToken("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	issue, err := c.Issue(context.Background(), "ABC-123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.Key != "ABC-123" || issue.Summary != "Login fails" || issue.Description != "The *login* page crashes" {
+		t.Errorf("Issue() = %+v", issue)
+	}
+}
+
+func TestClientAddComment(t *testing.T) {
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/rest/api/2/issue/ABC-123/comment" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("unexpected authorization header: %s", r.Header.Get("Authorization"))
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": "10000"}`))
+	}))
+	defer srv.Close()
+
+	c, err := New(WithBaseURL(srv.URL), WithToken("token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddComment(context.Background(), "ABC-123", "feat: add the login"); err != nil {
+		t.Fatal(err)
+	}
+	if got["body"] != "feat: add the login" {
+		t.Errorf("AddComment() sent %v", got)
+	}
+
+	srv.Close()
+	if err := c.AddComment(context.Background(), "ABC-123", "x"); err == nil {
+		t.Error("AddComment() expected error")
+	}
+}
@@ -0,0 +1,62 @@
+package jira
+
+import (
+	"net/http"
+)
+
+// Option is an interface that specifies Jira client configuration options.
+type Option interface {
+	apply(*config)
+}
+
+// optionFunc is a type of function that can be used to implement the Option interface.
+// It takes a pointer to a config struct and modifies it.
+type optionFunc func(*config)
+
+// Ensure that optionFunc satisfies the Option interface.
+var _ Option = (*optionFunc)(nil)
+
+// The apply method of optionFunc type is implemented here to modify the config struct based on the function passed.
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithBaseURL returns an Option that sets the URL of the Jira site, like https://example.atlassian.net.
+func WithBaseURL(val string) Option {
+	return optionFunc(func(c *config) {
+		c.baseURL = val
+	})
+}
+
+// WithEmail returns an Option that sets the email of the API token of Jira Cloud. Without
+// email, the token is sent as the personal access token of Jira Data Center.
+func WithEmail(val string) Option {
+	return optionFunc(func(c *config) {
+		c.email = val
+	})
+}
+
+// WithToken returns an Option that sets the API token.
+func WithToken(val string) Option {
+	return optionFunc(func(c *config) {
+		c.token = val
+	})
+}
+
+// WithHTTPClient returns an Option that sets the HTTP client.
+func WithHTTPClient(val *http.Client) Option {
+	return optionFunc(func(c *config) {
+		if val == nil {
+			return
+		}
+		c.httpClient = val
+	})
+}
+
+// config is a struct that stores configuration options for the Jira client.
+type config struct {
+	baseURL    string
+	email      string
+	token      string
+	httpClient *http.Client
+}
@@ -0,0 +1,150 @@
+package lint
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultTypes are the types of the commits of @commitlint/config-conventional.
+var DefaultTypes = []string{
+	"build", "chore", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test",
+}
+
+// DefaultHeaderMaxLength is the maximum length of the header of @commitlint/config-conventional.
+const DefaultHeaderMaxLength = 100
+
+// header matches the header of a conventional commit: type(scope)!: subject
+var header = regexp.MustCompile(`^(\w+)(?:\(([^()]*)\))?(!)?: ?(.*)$`)
+
+// ignored matches the messages generated by git, which follow their own format.
+var ignored = regexp.MustCompile(`^(Merge |Revert "|fixup! |squash! |amend! |Initial commit$)`)
+
+// scissors is the line of git commit --verbose, the diff below it isn't part of the message.
+const scissors = "# ------------------------ >8 ------------------------"
+
+// Problem is a rule of the Conventional Commits specification the message breaks.
+type Problem struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	return p.Message + " [" + p.Rule + "]"
+}
+
+// Linter checks the commit messages against the Conventional Commits specification.
+type Linter struct {
+	types           []string
+	scopes          []string
+	headerMaxLength int
+}
+
+// New returns a linter with the given options.
+func New(opts ...Option) *Linter {
+	l := &Linter{
+		types:           DefaultTypes,
+		headerMaxLength: DefaultHeaderMaxLength,
+	}
+	for _, opt := range opts {
+		opt.apply(l)
+	}
+	return l
+}
+
+// Clean removes the comment lines added by git and the diff below the scissors line.
+func Clean(message string) string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if line == scissors {
+			break
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t\r"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// Ignored reports whether the message is generated by git, like the merges, the
+// reverts and the fixups, and isn't checked.
+func Ignored(message string) bool {
+	return ignored.MatchString(Clean(message))
+}
+
+// Check returns the problems of the message, none if it follows the specification
+// or is ignored. The comments of git are removed first.
+func (l *Linter) Check(message string) []Problem {
+	message = Clean(message)
+	if message == "" {
+		return []Problem{{Rule: "header-empty", Message: "the message is empty"}}
+	}
+	if ignored.MatchString(message) {
+		return nil
+	}
+
+	lines := strings.Split(message, "\n")
+	first := lines[0]
+
+	var problems []Problem
+	if l.headerMaxLength > 0 && len(first) > l.headerMaxLength {
+		problems = append(problems, Problem{
+			Rule:    "header-max-length",
+			Message: "the header is " + strconv.Itoa(len(first)) + " characters long, the maximum is " + strconv.Itoa(l.headerMaxLength),
+		})
+	}
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		problems = append(problems, Problem{
+			Rule:    "body-leading-blank",
+			Message: "the body must be separated from the header by a blank line",
+		})
+	}
+
+	m := header.FindStringSubmatch(first)
+	if m == nil {
+		return append(problems, Problem{
+			Rule:    "header-format",
+			Message: "the header must look like type(scope): subject, e.g. feat(cli): add the lint command",
+		})
+	}
+	typ, scope, subject := m[1], m[2], m[4]
+
+	if typ != strings.ToLower(typ) {
+		problems = append(problems, Problem{Rule: "type-case", Message: "the type " + typ + " must be lower case"})
+	}
+	if !slices.Contains(l.types, strings.ToLower(typ)) {
+		problems = append(problems, Problem{
+			Rule:    "type-enum",
+			Message: "the type " + typ + " must be one of " + strings.Join(l.types, ", "),
+		})
+	}
+	if m[2] == "" && strings.Contains(first, "()") {
+		problems = append(problems, Problem{Rule: "scope-empty", Message: "the scope between the parentheses is empty"})
+	}
+	if scope != strings.ToLower(scope) {
+		problems = append(problems, Problem{Rule: "scope-case", Message: "the scope " + scope + " must be lower case"})
+	}
+	if len(l.scopes) > 0 && scope != "" {
+		// a commit can change several scopes, like feat(api,cli)
+		for _, s := range strings.Split(scope, ",") {
+			if s = strings.TrimSpace(s); !slices.Contains(l.scopes, strings.ToLower(s)) {
+				problems = append(problems, Problem{
+					Rule:    "scope-enum",
+					Message: "the scope " + s + " must be one of " + strings.Join(l.scopes, ", "),
+				})
+			}
+		}
+	}
+	subject = strings.TrimSpace(subject)
+	switch {
+	case subject == "":
+		problems = append(problems, Problem{Rule: "subject-empty", Message: "the subject is empty"})
+	case !strings.Contains(first, ": "):
+		problems = append(problems, Problem{Rule: "subject-separator", Message: "a space must follow the colon of the type"})
+	case strings.HasSuffix(subject, "."):
+		problems = append(problems, Problem{Rule: "subject-full-stop", Message: "the subject must not end with a period"})
+	}
+	return problems
+}
@@ -0,0 +1,71 @@
+package lint
+
+import (
+	"reflect"
+	"testing"
+)
+
+func rules(problems []Problem) []string {
+	var out []string
+	for _, p := range problems {
+		out = append(out, p.Rule)
+	}
+	return out
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		opts    []Option
+		want    []string
+	}{
+		{name: "valid", message: "feat(cli): add the lint command\n\nCheck the written messages.\n"},
+		{name: "breaking", message: "refactor!: drop the v1 config"},
+		{name: "comments", message: "fix: handle the empty diff\n# Please enter the commit message\n"},
+		{
+			name:    "scissors",
+			message: "docs: update the readme\n# ------------------------ >8 ------------------------\ndiff --git a/x b/x\n",
+		},
+		{name: "merge", message: "Merge branch 'main' into feature"},
+		{name: "fixup", message: "fixup! feat: add the lint command"},
+		{name: "empty", message: "# only comments\n\n", want: []string{"header-empty"}},
+		{name: "no type", message: "Add the lint command", want: []string{"header-format"}},
+		{name: "unknown type", message: "feature: add the lint command", want: []string{"type-enum"}},
+		{name: "upper case type", message: "Fix: handle the empty diff", want: []string{"type-case"}},
+		{name: "upper case scope", message: "fix(CLI): handle the empty diff", want: []string{"scope-case"}},
+		{name: "empty scope", message: "fix(): handle the empty diff", want: []string{"scope-empty"}},
+		{name: "no space", message: "fix:handle the empty diff", want: []string{"subject-separator"}},
+		{name: "no subject", message: "fix: ", want: []string{"subject-empty"}},
+		{name: "period", message: "fix: handle the empty diff.", want: []string{"subject-full-stop"}},
+		{name: "no blank line", message: "fix: handle the empty diff\nThe diff is empty.", want: []string{"body-leading-blank"}},
+		{
+			name:    "long header",
+			message: "fix: handle the empty diff",
+			opts:    []Option{WithHeaderMaxLength(10)},
+			want:    []string{"header-max-length"},
+		},
+		{name: "custom types", message: "wip: handle the empty diff", opts: []Option{WithTypes("wip")}},
+		{name: "allowed scopes", message: "fix(api, cli): handle the empty diff", opts: []Option{WithScopes("api", "cli")}},
+		{
+			name:    "unknown scope",
+			message: "fix(api,git): handle the empty diff",
+			opts:    []Option{WithScopes("api", "cli")},
+			want:    []string{"scope-enum"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules(New(tt.opts...).Check(tt.message)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClean(t *testing.T) {
+	message := "feat: add the lint command  \n\n# comment\nbody\n# ------------------------ >8 ------------------------\ndiff\n"
+	if got, want := Clean(message), "feat: add the lint command\n\nbody"; got != want {
+		t.Errorf("Clean() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,39 @@
+package lint
+
+// Option is an interface that specifies the options of the linter.
+type Option interface {
+	apply(*Linter)
+}
+
+// optionFunc is a type of function that can be used to implement the Option interface.
+type optionFunc func(*Linter)
+
+// Ensure that optionFunc satisfies the Option interface.
+var _ Option = (*optionFunc)(nil)
+
+func (o optionFunc) apply(l *Linter) {
+	o(l)
+}
+
+// WithTypes sets the allowed types of the commits, the default ones when empty.
+func WithTypes(types ...string) Option {
+	return optionFunc(func(l *Linter) {
+		if len(types) > 0 {
+			l.types = types
+		}
+	})
+}
+
+// WithScopes sets the allowed scopes of the commits, any scope when empty.
+func WithScopes(scopes ...string) Option {
+	return optionFunc(func(l *Linter) {
+		l.scopes = scopes
+	})
+}
+
+// WithHeaderMaxLength sets the maximum length of the header, 0 disables the rule.
+func WithHeaderMaxLength(n int) Option {
+	return optionFunc(func(l *Linter) {
+		l.headerMaxLength = n
+	})
+}
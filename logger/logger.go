@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Level is the severity of a log message.
+type Level int
+
+// Log levels, from the most to the least verbose.
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+var levelNames = map[string]Level{
+	"debug": DebugLevel,
+	"info":  InfoLevel,
+	"warn":  WarnLevel,
+	"error": ErrorLevel,
+}
+
+var (
+	level  = InfoLevel
+	output io.Writer
+)
+
+// ParseLevel returns the level with the given name: debug, info, warn or error.
+func ParseLevel(name string) (Level, error) {
+	l, ok := levelNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return InfoLevel, fmt.Errorf("unknown log level: %s, must be debug, info, warn or error", name)
+	}
+	return l, nil
+}
+
+// SetLevel sets the minimum level of the messages to print.
+func SetLevel(l Level) {
+	level = l
+}
+
+// Enabled reports whether the messages of the given level are printed.
+func Enabled(l Level) bool {
+	return l >= level
+}
+
+// SetOutput sets the writer of the log messages, nil means color.Output.
+func SetOutput(w io.Writer) {
+	output = w
+}
+
+// writer returns the writer of the log messages.
+// color.Output is resolved on every call since it is moved to stderr for the machine-readable output.
+func writer() io.Writer {
+	if output != nil {
+		return output
+	}
+	return color.Output
+}
+
+func print(l Level, c *color.Color, msg string) {
+	if !Enabled(l) {
+		return
+	}
+	_, _ = c.Fprintln(writer(), msg)
+}
+
+var (
+	debugColor = color.New(color.Faint)
+	infoColor  = color.New(color.FgCyan)
+	warnColor  = color.New(color.FgYellow)
+	errorColor = color.New(color.FgRed)
+)
+
+// Debug prints a debug message like the prompts and the HTTP requests.
+func Debug(msg string) {
+	print(DebugLevel, debugColor, "[debug] "+msg)
+}
+
+// Info prints a progress message.
+func Info(msg string) {
+	print(InfoLevel, infoColor, msg)
+}
+
+// Warn prints a warning message.
+func Warn(msg string) {
+	print(WarnLevel, warnColor, msg)
+}
+
+// Error prints an error message.
+func Error(msg string) {
+	print(ErrorLevel, errorColor, msg)
+}
+
+// MaskKey hides a secret like an API key, only keeping the first 3 and last 4 characters.
+func MaskKey(key string) string {
+	if len(key) <= 8 {
+		return strings.Repeat("*", len(key))
+	}
+	return key[:3] + strings.Repeat("*", len(key)-7) + key[len(key)-4:]
+}
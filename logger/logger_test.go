@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Level
+		wantErr bool
+	}{
+		{"debug", DebugLevel, false},
+		{"INFO", InfoLevel, false},
+		{"warn", WarnLevel, false},
+		{"error", ErrorLevel, false},
+		{"trace", InfoLevel, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.name)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestLevelFilter(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(nil)
+	defer SetLevel(InfoLevel)
+
+	SetLevel(WarnLevel)
+	Debug("debug")
+	Info("info")
+	Warn("warn")
+	Error("error")
+
+	if got := buf.String(); got != "warn\nerror\n" {
+		t.Errorf("log output = %q, want %q", got, "warn\nerror\n")
+	}
+}
+
+func TestMaskKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"sk-1234567890abcdef", "sk-************cdef"},
+		{"short", "*****"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := MaskKey(tt.key); got != tt.want {
+			t.Errorf("MaskKey(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Package metrics collects the counters and the histograms of the server with the
+// Prometheus client, and writes them in the text exposition format for the /metrics
+// endpoint.
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// DefaultBuckets are the upper bounds in seconds of the latency histograms, from
+// the cached responses to the slow completions.
+var DefaultBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Collector is a metric of the registry.
+type Collector interface {
+	collector() prometheus.Collector
+}
+
+// Counter is a value which only goes up, per label values.
+type Counter struct {
+	vec *prometheus.CounterVec
+}
+
+// NewCounter returns a counter with the label names.
+func NewCounter(name, help string, labels ...string) *Counter {
+	return &Counter{vec: prometheus.NewCounterVec(prometheus.CounterOpts{Name: name, Help: help}, labels)}
+}
+
+// Add adds the value, which must not be negative, to the counter of the label values.
+func (c *Counter) Add(v float64, values ...string) {
+	if v < 0 {
+		return
+	}
+	c.vec.WithLabelValues(values...).Add(v)
+}
+
+// Inc adds one to the counter of the label values.
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Value returns the counter of the label values.
+func (c *Counter) Value(values ...string) float64 {
+	var m dto.Metric
+	if err := c.vec.WithLabelValues(values...).Write(&m); err != nil {
+		return 0
+	}
+	return m.GetCounter().GetValue()
+}
+
+func (c *Counter) collector() prometheus.Collector { return c.vec }
+
+// Histogram counts the observed values in buckets, per label values.
+type Histogram struct {
+	vec *prometheus.HistogramVec
+}
+
+// NewHistogram returns a histogram with the upper bounds of the buckets, in any order,
+// and the label names.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Histogram{vec: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    name,
+		Help:    help,
+		Buckets: buckets,
+	}, labels)}
+}
+
+// Observe adds the value to the histogram of the label values.
+func (h *Histogram) Observe(v float64, values ...string) {
+	h.vec.WithLabelValues(values...).Observe(v)
+}
+
+func (h *Histogram) collector() prometheus.Collector { return h.vec }
+
+// Registry gathers its metrics, sorted by name.
+type Registry struct {
+	reg *prometheus.Registry
+}
+
+// NewRegistry returns a registry of the metrics.
+func NewRegistry(collectors ...Collector) *Registry {
+	reg := prometheus.NewRegistry()
+	for _, c := range collectors {
+		reg.MustRegister(c.collector())
+	}
+	return &Registry{reg: reg}
+}
+
+// Write writes the metrics in the Prometheus text format.
+func (r *Registry) Write(w io.Writer) error {
+	families, err := r.reg.Gather()
+	if err != nil {
+		return err
+	}
+	enc := expfmt.NewEncoder(w, expfmt.NewFormat(expfmt.TypeTextPlain))
+	for _, mf := range families {
+		if err := enc.Encode(mf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServeHTTP serves the metrics for the scrapes of Prometheus, in the format of the
+// Accept header.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	promhttp.HandlerFor(r.reg, promhttp.HandlerOpts{}).ServeHTTP(w, req)
+}
@@ -0,0 +1,118 @@
+package metrics
+
+import (
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// parse parses the metrics written by the registry, like the scrapes of Prometheus.
+func parse(t *testing.T, r *Registry) map[string]*dto.MetricFamily {
+	t.Helper()
+	var out strings.Builder
+	if err := r.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(strings.NewReader(out.String()))
+	if err != nil {
+		t.Fatalf("invalid text format %q: %v", out.String(), err)
+	}
+	return families
+}
+
+// labels returns the label pairs of the metric like command=commit,status=200.
+func labels(m *dto.Metric) string {
+	var pairs []string
+	for _, l := range m.GetLabel() {
+		pairs = append(pairs, l.GetName()+"="+l.GetValue())
+	}
+	return strings.Join(pairs, ",")
+}
+
+func TestCounter(t *testing.T) {
+	c := NewCounter("codegpt_requests_total", "Requests.", "command", "status")
+	c.Inc("commit", "200")
+	c.Inc("commit", "200")
+	c.Add(3, "review", "500")
+	c.Add(-1, "review", "500")
+
+	if v := c.Value("commit", "200"); v != 2 {
+		t.Errorf("Value() = %v, want 2", v)
+	}
+	mf := parse(t, NewRegistry(c))["codegpt_requests_total"]
+	if mf.GetType() != dto.MetricType_COUNTER || mf.GetHelp() != "Requests." {
+		t.Fatalf("family = %v, want the counter", mf)
+	}
+	got := map[string]float64{}
+	for _, m := range mf.GetMetric() {
+		got[labels(m)] = m.GetCounter().GetValue()
+	}
+	want := map[string]float64{"command=commit,status=200": 2, "command=review,status=500": 3}
+	if len(got) != len(want) {
+		t.Fatalf("counters = %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("counter %s = %v, want %v", k, got[k], v)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram("codegpt_request_duration_seconds", "Latency.", []float64{1, 0.5}, "command")
+	h.Observe(0.2, "commit")
+	h.Observe(0.7, "commit")
+	h.Observe(3, "commit")
+
+	mf := parse(t, NewRegistry(h))["codegpt_request_duration_seconds"]
+	if mf.GetType() != dto.MetricType_HISTOGRAM || len(mf.GetMetric()) != 1 {
+		t.Fatalf("family = %v, want one histogram", mf)
+	}
+	m := mf.GetMetric()[0]
+	if labels(m) != "command=commit" {
+		t.Errorf("labels = %s, want command=commit", labels(m))
+	}
+	hist := m.GetHistogram()
+	if hist.GetSampleCount() != 3 || hist.GetSampleSum() != 3.9 {
+		t.Errorf("count, sum = %d, %v, want 3, 3.9", hist.GetSampleCount(), hist.GetSampleSum())
+	}
+	want := []struct {
+		le    float64
+		count uint64
+	}{{0.5, 1}, {1, 2}, {math.Inf(1), 3}}
+	if len(hist.GetBucket()) != len(want) {
+		t.Fatalf("buckets = %v, want %v", hist.GetBucket(), want)
+	}
+	for i, b := range hist.GetBucket() {
+		if b.GetUpperBound() != want[i].le || b.GetCumulativeCount() != want[i].count {
+			t.Errorf("bucket %d = %v, want le=%v count=%d", i, b, want[i].le, want[i].count)
+		}
+	}
+}
+
+func TestEscape(t *testing.T) {
+	c := NewCounter("codegpt_errors_total", "Errors.", "model")
+	c.Inc("a\"b\\c\nd")
+	m := parse(t, NewRegistry(c))["codegpt_errors_total"].GetMetric()[0]
+	if got := m.GetLabel()[0].GetValue(); got != "a\"b\\c\nd" {
+		t.Errorf("label = %q, want the value back", got)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	c := NewCounter("codegpt_cache_requests_total", "Cache.", "result")
+	c.Inc("hit")
+	rec := httptest.NewRecorder()
+	NewRegistry(c).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q, want the text format", ct)
+	}
+	if !strings.Contains(rec.Body.String(), `codegpt_cache_requests_total{result="hit"} 1`) {
+		t.Errorf("body = %q", rec.Body.String())
+	}
+}
@@ -0,0 +1,161 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var errorsNoWebhook = errors.New("no Slack or Teams webhook is set")
+
+// maxSlackText is the maximum length of the text of a Slack message.
+const maxSlackText = 39000
+
+// Message is a notification, the text is in markdown.
+type Message struct {
+	Title string
+	Text  string
+}
+
+// Notifier posts the notifications to the Slack and Microsoft Teams incoming webhooks.
+type Notifier struct {
+	cfg *config
+}
+
+// New creates a new notifier with the given options, at least one webhook must be set.
+func New(opts ...Option) (*Notifier, error) {
+	cfg := &config{
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+	if cfg.slack == "" && cfg.teams == "" {
+		return nil, errorsNoWebhook
+	}
+	return &Notifier{cfg: cfg}, nil
+}
+
+// Send posts the message to every webhook, the errors of the webhooks are joined.
+func (n *Notifier) Send(ctx context.Context, m Message) error {
+	var errs []error
+	if n.cfg.slack != "" {
+		if err := n.post(ctx, "slack", n.cfg.slack, slackPayload(m)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if n.cfg.teams != "" {
+		if err := n.post(ctx, "teams", n.cfg.teams, teamsPayload(m)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// post sends the JSON payload to the webhook.
+func (n *Notifier) post(ctx context.Context, name, webhook string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.cfg.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s webhook: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s webhook error: %s: %s", name, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+var (
+	headingPattern = regexp.MustCompile(`(?m)^#{1,6}\s+(.+)$`)
+	boldPattern    = regexp.MustCompile(`\*\*(.+?)\*\*`)
+	linkPattern    = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	bulletPattern  = regexp.MustCompile(`(?m)^(\s*)[-*] `)
+)
+
+// SlackText converts the markdown to the mrkdwn format of Slack: the headings and the bold
+// text are bold, the links are <url|text> and the bullets are •.
+func SlackText(markdown string) string {
+	text := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(markdown)
+	text = bulletPattern.ReplaceAllString(text, "$1• ")
+	text = headingPattern.ReplaceAllString(text, "**$1**")
+	text = boldPattern.ReplaceAllString(text, "*$1*")
+	return linkPattern.ReplaceAllString(text, "<$2|$1>")
+}
+
+// slackPayload returns the payload of the Slack incoming webhooks.
+// https://api.slack.com/messaging/webhooks
+func slackPayload(m Message) map[string]interface{} {
+	text := SlackText(m.Text)
+	if m.Title != "" {
+		text = "*" + SlackText(m.Title) + "*\n" + text
+	}
+	if len(text) > maxSlackText {
+		text = text[:maxSlackText] + "…"
+	}
+	return map[string]interface{}{"text": text, "unfurl_links": false}
+}
+
+// teamsPayload returns the Adaptive Card message of the Teams workflow webhooks, also
+// accepted by the legacy incoming webhook connectors. The text blocks support markdown.
+// https://learn.microsoft.com/en-us/microsoftteams/platform/webhooks-and-connectors/how-to/connectors-using
+func teamsPayload(m Message) map[string]interface{} {
+	var body []map[string]interface{}
+	if m.Title != "" {
+		body = append(body, map[string]interface{}{
+			"type": "TextBlock", "text": m.Title, "weight": "Bolder", "size": "Medium", "wrap": true,
+		})
+	}
+	// the headings aren't supported, they're bold
+	text := headingPattern.ReplaceAllString(m.Text, "**$1**")
+	body = append(body, map[string]interface{}{"type": "TextBlock", "text": text, "wrap": true})
+
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]interface{}{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+}
+
+// trustedHosts are the hosts of the Slack and Teams webhooks, and their domain suffixes.
+var trustedHosts = []string{"hooks.slack.com", ".webhook.office.com", ".logic.azure.com"}
+
+// IsWebhookURL reports whether the URL is an HTTPS webhook of Slack or Microsoft Teams.
+func IsWebhookURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, h := range trustedHosts {
+		if host == h || (strings.HasPrefix(h, ".") && strings.HasSuffix(host, h)) {
+			return true
+		}
+	}
+	return false
+}
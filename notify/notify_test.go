@@ -0,0 +1,79 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSlackText(t *testing.T) {
+	got := SlackText("## v1.2.0\n\n- **cli:** add the [lint](https://example.com/a?b=1&c=2) command\n- fix <nil> values")
+	want := "*v1.2.0*\n\n• *cli:* add the <https://example.com/a?b=1&amp;c=2|lint> command\n• fix &lt;nil&gt; values"
+	if got != want {
+		t.Errorf("SlackText() = %q, want %q", got, want)
+	}
+}
+
+func TestNotifierSend(t *testing.T) {
+	var slack, teams map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slack":
+			_ = json.NewDecoder(r.Body).Decode(&slack)
+		case "/teams":
+			_ = json.NewDecoder(r.Body).Decode(&teams)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("no_service"))
+		}
+	}))
+	defer srv.Close()
+
+	if _, err := New(); err != errorsNoWebhook {
+		t.Errorf("New() error = %v, want %v", err, errorsNoWebhook)
+	}
+
+	n, err := New(WithSlack(srv.URL+"/slack"), WithTeams(srv.URL+"/teams"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Send(context.Background(), Message{Title: "Release v1.2.0", Text: "- **cli:** add lint"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if slack["text"] != "*Release v1.2.0*\n• *cli:* add lint" {
+		t.Errorf("Send() slack payload = %v", slack)
+	}
+	data, _ := json.Marshal(teams)
+	if !strings.Contains(string(data), `"type":"AdaptiveCard"`) || !strings.Contains(string(data), `"text":"- **cli:** add lint"`) {
+		t.Errorf("Send() teams payload = %s", data)
+	}
+
+	n, _ = New(WithSlack(srv.URL + "/missing"))
+	if err := n.Send(context.Background(), Message{Text: "x"}); err == nil || !strings.Contains(err.Error(), "no_service") {
+		t.Errorf("Send() error = %v, want the webhook error", err)
+	}
+}
+
+func TestIsWebhookURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://hooks.slack.com/services/T0/B0/x", true},
+		{"https://contoso.webhook.office.com/webhookb2/x", true},
+		{"https://prod-01.westus.logic.azure.com:443/workflows/x", true},
+		{"http://hooks.slack.com/services/T0/B0/x", false},
+		{"https://hooks.slack.com.example.com/x", false},
+		{"https://example.com/webhook.office.com", false},
+		{"not a url", false},
+	}
+	for _, tt := range tests {
+		if got := IsWebhookURL(tt.url); got != tt.want {
+			t.Errorf("IsWebhookURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"net/http"
+)
+
+// Option is an interface that specifies notifier configuration options.
+type Option interface {
+	apply(*config)
+}
+
+// optionFunc is a type of function that can be used to implement the Option interface.
+// It takes a pointer to a config struct and modifies it.
+type optionFunc func(*config)
+
+// Ensure that optionFunc satisfies the Option interface.
+var _ Option = (*optionFunc)(nil)
+
+// The apply method of optionFunc type is implemented here to modify the config struct based on the function passed.
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithSlack returns an Option that sets the URL of the Slack incoming webhook.
+func WithSlack(url string) Option {
+	return optionFunc(func(c *config) {
+		c.slack = url
+	})
+}
+
+// WithTeams returns an Option that sets the URL of the Microsoft Teams incoming webhook,
+// a workflow webhook or a legacy connector one.
+func WithTeams(url string) Option {
+	return optionFunc(func(c *config) {
+		c.teams = url
+	})
+}
+
+// WithHTTPClient returns an Option that sets the HTTP client.
+func WithHTTPClient(val *http.Client) Option {
+	return optionFunc(func(c *config) {
+		if val == nil {
+			return
+		}
+		c.httpClient = val
+	})
+}
+
+// config is a struct that stores configuration options for the notifier.
+type config struct {
+	slack      string
+	teams      string
+	httpClient *http.Client
+}
@@ -0,0 +1,109 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// The authentication methods of the Azure provider.
+const (
+	// AzureAuthKey sends the API key in the api-key header.
+	AzureAuthKey = "api_key"
+	// AzureAuthClientSecret gets the Microsoft Entra ID token of a service principal.
+	AzureAuthClientSecret = "client_secret"
+	// AzureAuthManagedIdentity gets the token of the managed identity of the Azure host.
+	AzureAuthManagedIdentity = "managed_identity"
+	// AzureAuthDefault tries the environment variables, the workload identity,
+	// the managed identity and the Azure CLI, in this order.
+	AzureAuthDefault = "default"
+)
+
+// azureScope is the scope of the Microsoft Entra ID tokens for Azure OpenAI.
+const azureScope = "https://cognitiveservices.azure.com/.default"
+
+// azureRefreshWindow is how long before the expiry the token is refreshed.
+const azureRefreshWindow = 5 * time.Minute
+
+var (
+	errorsInvalidAzureAuth   = errors.New("invalid Azure authentication, use api_key, client_secret, managed_identity or default")
+	errorsMissingAzureSecret = errors.New("the client_secret authentication needs the tenant ID, client ID and client secret")
+)
+
+// IsAzureAuth reports whether val is an authentication method of the Azure provider.
+func IsAzureAuth(val string) bool {
+	switch val {
+	case AzureAuthKey, AzureAuthClientSecret, AzureAuthManagedIdentity, AzureAuthDefault:
+		return true
+	}
+	return false
+}
+
+// azureCredential returns the Microsoft Entra ID credential of the authentication method.
+func azureCredential(cfg *config) (azcore.TokenCredential, error) {
+	switch cfg.azureAuth {
+	case AzureAuthClientSecret:
+		return azidentity.NewClientSecretCredential(cfg.azureTenantID, cfg.azureClientID, cfg.azureClientSecret, nil)
+	case AzureAuthManagedIdentity:
+		opts := &azidentity.ManagedIdentityCredentialOptions{}
+		// a user-assigned identity is selected by its client ID
+		if cfg.azureClientID != "" {
+			opts.ID = azidentity.ClientID(cfg.azureClientID)
+		}
+		return azidentity.NewManagedIdentityCredential(opts)
+	case AzureAuthDefault:
+		return azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{
+			TenantID: cfg.azureTenantID,
+		})
+	}
+	return nil, errorsInvalidAzureAuth
+}
+
+// AzureADTransport is an http.RoundTripper that authenticates the requests with
+// a Microsoft Entra ID token, which is refreshed before it expires.
+type AzureADTransport struct {
+	Origin     http.RoundTripper
+	Credential azcore.TokenCredential
+
+	mu    sync.Mutex
+	token azcore.AccessToken
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *AzureADTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.accessToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	// the round tripper must not modify the original request
+	req = req.Clone(req.Context())
+	req.Header.Del("api-key")
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.Origin.RoundTrip(req)
+}
+
+// accessToken returns the cached token, or a new one if it expires soon.
+func (t *AzureADTransport) accessToken(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token.Token != "" && time.Until(t.token.ExpiresOn) > azureRefreshWindow {
+		return t.token.Token, nil
+	}
+
+	token, err := t.Credential.GetToken(ctx, policy.TokenRequestOptions{
+		Scopes: []string{azureScope},
+	})
+	if err != nil {
+		return "", err
+	}
+	t.token = token
+	return token.Token, nil
+}
@@ -0,0 +1,94 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+type fakeCredential struct {
+	calls   int
+	expires time.Duration
+}
+
+func (f *fakeCredential) GetToken(_ context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	f.calls++
+	return azcore.AccessToken{
+		Token:     opts.Scopes[0] + "#" + string(rune('0'+f.calls)),
+		ExpiresOn: time.Now().Add(f.expires),
+	}, nil
+}
+
+func TestAzureADTransport(t *testing.T) {
+	var auth, key string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		key = r.Header.Get("api-key")
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name      string
+		expires   time.Duration
+		wantCalls int
+	}{
+		{name: "cached token", expires: time.Hour, wantCalls: 1},
+		{name: "refreshed token", expires: time.Minute, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cred := &fakeCredential{expires: tt.expires}
+			client := &http.Client{Transport: &AzureADTransport{
+				Origin:     http.DefaultTransport,
+				Credential: cred,
+			}}
+
+			for i := 0; i < 2; i++ {
+				req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+				req.Header.Set("api-key", "sk-test")
+				resp, err := client.Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+				if req.Header.Get("Authorization") != "" {
+					t.Error("the original request is modified")
+				}
+			}
+
+			if cred.calls != tt.wantCalls {
+				t.Errorf("GetToken() calls = %d, want %d", cred.calls, tt.wantCalls)
+			}
+			want := "Bearer " + azureScope + "#" + string(rune('0'+tt.wantCalls))
+			if auth != want {
+				t.Errorf("Authorization = %q, want %q", auth, want)
+			}
+			if key != "" {
+				t.Errorf("api-key = %q, want empty", key)
+			}
+		})
+	}
+}
+
+func TestAzureToolsAPI(t *testing.T) {
+	tests := map[string]bool{
+		"":                   false,
+		"2023-05-15":         false,
+		"2023-07-01-preview": false,
+		"2023-12-01-preview": true,
+		"2023-12-01":         true,
+		"2024-02-01":         true,
+		"2024-10-21":         true,
+		"latest":             false,
+	}
+	for version, want := range tests {
+		if got := azureToolsAPI(version); got != want {
+			t.Errorf("azureToolsAPI(%q) = %v, want %v", version, got, want)
+		}
+	}
+}
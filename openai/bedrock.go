@@ -0,0 +1,149 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+// converser is the Converse API of the Bedrock runtime client.
+type converser interface {
+	Converse(
+		ctx context.Context,
+		params *bedrockruntime.ConverseInput,
+		optFns ...func(*bedrockruntime.Options),
+	) (*bedrockruntime.ConverseOutput, error)
+}
+
+// bedrock is the AWS Bedrock provider, it sends the prompts to the Converse API
+// which works the same for the Claude, Titan and other text models.
+type bedrock struct {
+	client      converser
+	credentials aws.CredentialsProvider
+	region      string
+}
+
+// newBedrock creates the Bedrock runtime client with the default AWS credential chain:
+// the environment variables, the shared config profile, SSO and the instance role.
+// The requests are signed with SigV4 and sent with the given HTTP client.
+func newBedrock(cfg *config, httpClient *http.Client) (*bedrock, error) {
+	var opts []func(*awsconfig.LoadOptions) error
+	if cfg.awsRegion != "" {
+		opts = append(opts, awsconfig.WithRegion(cfg.awsRegion))
+	}
+	if cfg.awsProfile != "" {
+		opts = append(opts, awsconfig.WithSharedConfigProfile(cfg.awsProfile))
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(), opts...)
+	if err != nil {
+		return nil, err
+	}
+	if awsCfg.Region == "" {
+		return nil, errorsMissingRegion
+	}
+
+	client := bedrockruntime.NewFromConfig(awsCfg, func(o *bedrockruntime.Options) {
+		// only the runtime calls use the proxy, headers and cassette of the client,
+		// the credential providers keep the AWS one with its AWS_CA_BUNDLE
+		o.HTTPClient = httpClient
+		// a VPC endpoint keeps the traffic inside the AWS network
+		if cfg.baseURL != "" {
+			o.BaseEndpoint = aws.String(cfg.baseURL)
+		}
+	})
+	return &bedrock{
+		client:      client,
+		credentials: awsCfg.Credentials,
+		region:      awsCfg.Region,
+	}, nil
+}
+
+// endpoint returns the URL of the Converse API of the model.
+func (b *bedrock) endpoint(baseURL, model string) string {
+	if baseURL == "" {
+		baseURL = "https://bedrock-runtime." + b.region + ".amazonaws.com"
+	}
+	return strings.TrimRight(baseURL, "/") + "/model/" + model + "/converse"
+}
+
+// foldSystem reports whether the system message of the model leads the prompt instead,
+// the Titan text models don't support it.
+func foldSystem(model string) bool {
+	return strings.Contains(model, "amazon.titan")
+}
+
+// converseInput returns the Converse request of the prompt.
+func (c *Client) converseInput(content string) *bedrockruntime.ConverseInput {
+	input := &bedrockruntime.ConverseInput{
+		ModelId: aws.String(c.model),
+		InferenceConfig: &types.InferenceConfiguration{
+			MaxTokens:   aws.Int32(int32(c.maxTokens)),
+			Temperature: aws.Float32(c.temperature),
+			TopP:        aws.Float32(c.topP),
+		},
+	}
+	if len(c.stop) > 0 {
+		input.InferenceConfig.StopSequences = c.stop
+	}
+
+	if c.system != "" && foldSystem(c.model) {
+		content = c.system + "\n\n" + content
+	} else if c.system != "" {
+		input.System = []types.SystemContentBlock{
+			&types.SystemContentBlockMemberText{Value: c.system},
+		}
+	}
+	input.Messages = []types.Message{{
+		Role:    types.ConversationRoleUser,
+		Content: []types.ContentBlock{&types.ContentBlockMemberText{Value: content}},
+	}}
+	return input
+}
+
+// bedrockCompletion requests n completions of the prompt, one call each since
+// the Converse API returns a single message.
+func (c *Client) bedrockCompletion(ctx context.Context, content string, n int) (*Response, error) {
+	resp := &Response{}
+	for i := 0; i < max(n, 1); i++ {
+		out, err := c.bedrock.client.Converse(ctx, c.converseInput(content))
+		if err != nil {
+			return nil, err
+		}
+
+		msg, ok := out.Output.(*types.ConverseOutputMemberMessage)
+		if !ok {
+			return nil, errors.New("no completion returned")
+		}
+		var text strings.Builder
+		for _, block := range msg.Value.Content {
+			if t, ok := block.(*types.ContentBlockMemberText); ok {
+				text.WriteString(t.Value)
+			}
+		}
+		resp.Choices = append(resp.Choices, text.String())
+
+		if out.Usage != nil {
+			resp.Usage.PromptTokens += int(aws.ToInt32(out.Usage.InputTokens))
+			resp.Usage.CompletionTokens += int(aws.ToInt32(out.Usage.OutputTokens))
+			resp.Usage.TotalTokens += int(aws.ToInt32(out.Usage.TotalTokens))
+		}
+	}
+	resp.Content = resp.Choices[0]
+	return resp, nil
+}
+
+// bedrockModels checks the AWS credentials and returns the configured model,
+// listing the foundation models needs the Bedrock control plane permissions.
+func (c *Client) bedrockModels(ctx context.Context) ([]string, error) {
+	if _, err := c.bedrock.credentials.Retrieve(ctx); err != nil {
+		return nil, err
+	}
+	return []string{c.model}, nil
+}
@@ -0,0 +1,96 @@
+package openai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+)
+
+type fakeConverser struct {
+	inputs []*bedrockruntime.ConverseInput
+}
+
+func (f *fakeConverser) Converse(
+	_ context.Context,
+	params *bedrockruntime.ConverseInput,
+	_ ...func(*bedrockruntime.Options),
+) (*bedrockruntime.ConverseOutput, error) {
+	f.inputs = append(f.inputs, params)
+	return &bedrockruntime.ConverseOutput{
+		Output: &types.ConverseOutputMemberMessage{Value: types.Message{
+			Role: types.ConversationRoleAssistant,
+			Content: []types.ContentBlock{
+				&types.ContentBlockMemberText{Value: "feat: add "},
+				&types.ContentBlockMemberText{Value: "bedrock"},
+			},
+		}},
+		Usage: &types.TokenUsage{
+			InputTokens:  aws.Int32(10),
+			OutputTokens: aws.Int32(3),
+			TotalTokens:  aws.Int32(13),
+		},
+	}, nil
+}
+
+func TestConverseInput(t *testing.T) {
+	tests := []struct {
+		name       string
+		model      string
+		wantSystem bool
+		wantPrompt string
+	}{
+		{
+			name:       "system message",
+			model:      "anthropic.claude-3-haiku-20240307-v1:0",
+			wantSystem: true,
+			wantPrompt: "diff",
+		},
+		{
+			name:       "titan folds the system message",
+			model:      "amazon.titan-text-express-v1",
+			wantPrompt: "be brief\n\ndiff",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Client{model: tt.model, maxTokens: 300, temperature: 0.7, topP: 1, system: "be brief"}
+			input := c.converseInput("diff")
+			if got := len(input.System) == 1; got != tt.wantSystem {
+				t.Errorf("system = %v, want %v", got, tt.wantSystem)
+			}
+			text := input.Messages[0].Content[0].(*types.ContentBlockMemberText).Value
+			if text != tt.wantPrompt {
+				t.Errorf("prompt = %q, want %q", text, tt.wantPrompt)
+			}
+			if aws.ToString(input.ModelId) != tt.model || aws.ToInt32(input.InferenceConfig.MaxTokens) != 300 {
+				t.Errorf("unexpected model %s or max tokens", aws.ToString(input.ModelId))
+			}
+		})
+	}
+}
+
+func TestBedrockCompletion(t *testing.T) {
+	fake := &fakeConverser{}
+	c := &Client{model: "anthropic.claude-3-haiku-20240307-v1:0", bedrock: &bedrock{client: fake, region: "us-east-1"}}
+
+	resp, err := c.Candidates(context.Background(), "diff", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.inputs) != 2 || len(resp.Choices) != 2 {
+		t.Fatalf("got %d calls and %d choices, want 2", len(fake.inputs), len(resp.Choices))
+	}
+	if resp.Content != "feat: add bedrock" {
+		t.Errorf("Content = %q", resp.Content)
+	}
+	if resp.Usage.TotalTokens != 26 || resp.Usage.PromptTokens != 20 {
+		t.Errorf("Usage = %+v", resp.Usage)
+	}
+	want := "https://bedrock-runtime.us-east-1.amazonaws.com/model/anthropic.claude-3-haiku-20240307-v1:0/converse"
+	if got := c.Endpoint(); got != want {
+		t.Errorf("Endpoint() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,82 @@
+package openai
+
+import "strings"
+
+// Capability describes the features of a model.
+type Capability struct {
+	// Chat is true if the model uses the chat completions endpoint.
+	Chat bool
+	// Tools is true if the model supports the tools (function calling) API.
+	Tools bool
+	// JSONMode is true if the model supports the json_object response format.
+	JSONMode bool
+	// Reasoning is true for the o-series models: they have fixed sampling parameters,
+	// no system message and their reasoning tokens count against the completion tokens.
+	Reasoning bool
+	// ContextWindow is the maximum number of tokens of the prompt and the completion.
+	ContextWindow int
+}
+
+// capabilities maps model name prefixes to their capabilities,
+// the longest matching prefix wins.
+var capabilities = map[string]Capability{
+	"gpt-4o":                 {Chat: true, Tools: true, JSONMode: true, ContextWindow: 128000},
+	"chatgpt-4o-latest":      {Chat: true, JSONMode: true, ContextWindow: 128000},
+	"o1":                     {Chat: true, Reasoning: true, ContextWindow: 200000},
+	"o1-mini":                {Chat: true, Reasoning: true, ContextWindow: 128000},
+	"o1-preview":             {Chat: true, Reasoning: true, ContextWindow: 128000},
+	"o3-mini":                {Chat: true, Reasoning: true, ContextWindow: 200000},
+	"gpt-4-turbo":            {Chat: true, Tools: true, JSONMode: true, ContextWindow: 128000},
+	"gpt-4-0125-preview":     {Chat: true, Tools: true, JSONMode: true, ContextWindow: 128000},
+	"gpt-4-1106-preview":     {Chat: true, Tools: true, JSONMode: true, ContextWindow: 128000},
+	"gpt-4-32k":              {Chat: true, ContextWindow: 32768},
+	"gpt-4-32k-0613":         {Chat: true, Tools: true, ContextWindow: 32768},
+	"gpt-4":                  {Chat: true, ContextWindow: 8192},
+	"gpt-4-0613":             {Chat: true, Tools: true, ContextWindow: 8192},
+	"gpt-3.5-turbo":          {Chat: true, ContextWindow: 4096},
+	"gpt-3.5-turbo-0613":     {Chat: true, Tools: true, ContextWindow: 4096},
+	"gpt-3.5-turbo-16k":      {Chat: true, ContextWindow: 16384},
+	"gpt-3.5-turbo-16k-0613": {Chat: true, Tools: true, ContextWindow: 16384},
+	"gpt-3.5-turbo-0125":     {Chat: true, Tools: true, JSONMode: true, ContextWindow: 16385},
+	"gpt-3.5-turbo-1106":     {Chat: true, Tools: true, JSONMode: true, ContextWindow: 16385},
+	"gpt-3.5-turbo-instruct": {ContextWindow: 4096},
+	"davinci-002":            {ContextWindow: 16384},
+	"babbage-002":            {ContextWindow: 16384},
+
+	// the models of Mistral AI, the tool_choice can't force a function
+	"mistral-large":      {Chat: true, JSONMode: true, ContextWindow: 128000},
+	"mistral-medium":     {Chat: true, JSONMode: true, ContextWindow: 32000},
+	"mistral-small":      {Chat: true, JSONMode: true, ContextWindow: 32000},
+	"codestral":          {Chat: true, JSONMode: true, ContextWindow: 32000},
+	"open-mistral-nemo":  {Chat: true, JSONMode: true, ContextWindow: 128000},
+	"open-mistral-7b":    {Chat: true, JSONMode: true, ContextWindow: 32000},
+	"open-mixtral-8x7b":  {Chat: true, JSONMode: true, ContextWindow: 32000},
+	"open-mixtral-8x22b": {Chat: true, JSONMode: true, ContextWindow: 64000},
+}
+
+// lookup returns the capabilities of the model and whether it's known.
+// The longest matching model name prefix wins, so gpt-4-32k-0613 supports tools.
+func lookup(model string) (Capability, bool) {
+	var capability Capability
+	length := 0
+	for prefix, c := range capabilities {
+		if strings.HasPrefix(model, prefix) && len(prefix) > length {
+			capability, length = c, len(prefix)
+		}
+	}
+	return capability, length > 0
+}
+
+// CapabilityOf returns the capabilities of the model. The unknown models are
+// assumed to be chat models, like all the recent ones.
+func CapabilityOf(model string) Capability {
+	if capability, ok := lookup(model); ok {
+		return capability
+	}
+	return Capability{Chat: true}
+}
+
+// ContextWindow returns the context window of the model in tokens, or zero if it's unknown.
+func ContextWindow(model string) int {
+	return CapabilityOf(model).ContextWindow
+}
@@ -1,8 +1,13 @@
 package openai
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
+
+	"github.com/appleboy/CodeGPT/logger"
 )
 
 // DefaultHeaderTransport is an http.RoundTripper that adds the given headers to
@@ -34,3 +39,67 @@ func NewHeaders(headers []string) http.Header {
 	}
 	return h
 }
+
+// endpointHeaders are the extra headers of the known OpenAI-compatible endpoints,
+// keyed by the host of the base URL.
+var endpointHeaders = map[string][]string{
+	// https://openrouter.ai/docs/api-reference/overview#headers
+	"openrouter.ai": {"HTTP-Referer=https://github.com/appleboy/CodeGPT", "X-Title=CodeGPT"},
+}
+
+// EndpointHeaders returns the extra headers of the endpoint of the base URL merged with
+// the given headers, which replace the ones of the endpoint with the same key.
+func EndpointHeaders(baseURL string, headers []string) http.Header {
+	h := make(http.Header)
+	if u, err := url.Parse(baseURL); err == nil {
+		host := u.Hostname()
+		for domain, defaults := range endpointHeaders {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				for key, values := range NewHeaders(defaults) {
+					h[key] = values
+				}
+			}
+		}
+	}
+	for key, values := range NewHeaders(headers) {
+		h[key] = values
+	}
+	return h
+}
+
+// DebugTransport is an http.RoundTripper that logs every request and its response
+// status at debug level, with the API key masked.
+type DebugTransport struct {
+	Origin http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *DebugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !logger.Enabled(logger.DebugLevel) {
+		return t.Origin.RoundTrip(req)
+	}
+
+	logger.Debug(fmt.Sprintf("request: %s %s", req.Method, req.URL.Redacted()))
+	for _, key := range []string{"Authorization", "Api-Key"} {
+		if v := req.Header.Get(key); v != "" {
+			logger.Debug(fmt.Sprintf("header: %s: %s", key, maskAuth(v)))
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.Origin.RoundTrip(req)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("request failed after %s: %s", time.Since(start), err))
+		return nil, err
+	}
+	logger.Debug(fmt.Sprintf("response: %s in %s", resp.Status, time.Since(start)))
+	return resp, nil
+}
+
+// maskAuth masks the credential of an authorization header value.
+func maskAuth(v string) string {
+	if scheme, token, ok := strings.Cut(v, " "); ok {
+		return scheme + " " + logger.MaskKey(token)
+	}
+	return logger.MaskKey(v)
+}
@@ -0,0 +1,111 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// The default embedding models of the providers.
+const (
+	defaultEmbeddingModel = string(openai.SmallEmbedding3)
+	mistralEmbeddingModel = "mistral-embed"
+)
+
+// mockDimensions is the size of the embeddings of the mock provider.
+const mockDimensions = 256
+
+var errorsEmbeddingsUnsupported = errors.New("the provider doesn't support the embeddings API")
+
+// EmbeddingModel returns the model of the embeddings.
+func (c *Client) EmbeddingModel() string {
+	return c.embeddingModel
+}
+
+// Embeddings returns the embedding vectors of the inputs, in the same order.
+// On Azure the embedding model is mapped to its deployment like the other models.
+func (c *Client) Embeddings(ctx context.Context, inputs []string) ([][]float32, Usage, error) {
+	if len(inputs) == 0 {
+		return nil, Usage{}, nil
+	}
+
+	switch {
+	case c.mock != nil:
+		out := make([][]float32, len(inputs))
+		var usage Usage
+		for i, input := range inputs {
+			out[i] = mockEmbedding(input)
+			usage.PromptTokens += EstimateTokens(input)
+		}
+		usage.TotalTokens = usage.PromptTokens
+		return out, usage, nil
+	case c.bedrock != nil, c.tgi != nil:
+		return nil, Usage{}, errorsEmbeddingsUnsupported
+	}
+
+	tokens := 0
+	for _, input := range inputs {
+		tokens += EstimateTokens(input)
+	}
+	if err := c.limiter.wait(ctx, tokens); err != nil {
+		return nil, Usage{}, err
+	}
+
+	resp, err := c.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+		Input: inputs,
+		Model: openai.EmbeddingModel(c.embeddingModel),
+	})
+	if err != nil {
+		return nil, Usage{}, err
+	}
+	if len(resp.Data) != len(inputs) {
+		return nil, Usage{}, errors.New("the number of embeddings doesn't match the inputs")
+	}
+
+	out := make([][]float32, len(inputs))
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(out) {
+			return nil, Usage{}, errors.New("invalid embedding index")
+		}
+		out[d.Index] = d.Embedding
+	}
+	return out, resp.Usage, nil
+}
+
+// mockEmbedding returns a deterministic bag of words vector of the text,
+// so the texts sharing words are similar without any network access.
+func mockEmbedding(text string) []float32 {
+	vec := make([]float32, mockDimensions)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	for _, w := range words {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(w))
+		vec[h.Sum32()%mockDimensions]++
+	}
+	return vec
+}
+
+// Cosine returns the cosine similarity of the vectors, between -1 and 1,
+// or 0 if their sizes differ or one of them is zero.
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
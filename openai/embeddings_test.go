@@ -0,0 +1,53 @@
+package openai
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestCosine(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float64
+	}{
+		{name: "same direction", a: []float32{1, 2}, b: []float32{2, 4}, want: 1},
+		{name: "orthogonal", a: []float32{1, 0}, b: []float32{0, 1}, want: 0},
+		{name: "opposite", a: []float32{1, 1}, b: []float32{-1, -1}, want: -1},
+		{name: "zero vector", a: []float32{0, 0}, b: []float32{1, 1}, want: 0},
+		{name: "size mismatch", a: []float32{1}, b: []float32{1, 1}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Cosine(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Cosine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMockEmbeddings(t *testing.T) {
+	client, err := New(WithProvider(MOCK))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.EmbeddingModel() != defaultEmbeddingModel {
+		t.Errorf("EmbeddingModel() = %s", client.EmbeddingModel())
+	}
+
+	out, usage, err := client.Embeddings(context.Background(), []string{
+		"add the models command cmd/models.go",
+		"fix the README typo",
+		"cmd/models.go list the models",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 3 || usage.TotalTokens == 0 {
+		t.Fatalf("got %d embeddings and usage %+v", len(out), usage)
+	}
+	if Cosine(out[0], out[2]) <= Cosine(out[0], out[1]) {
+		t.Error("the texts sharing words should be closer")
+	}
+}
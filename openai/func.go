@@ -2,6 +2,9 @@ package openai
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/appleboy/com/bytesconv"
 	openai "github.com/sashabaranov/go-openai"
@@ -28,17 +31,97 @@ var SummaryPrefixFunc = openai.FunctionDefinition{
 	},
 }
 
+// ToolCalls returns the arguments of the calls of the function in the response, the model
+// can call it several times in parallel. The deprecated function_call of the older API
+// versions is supported too.
+func ToolCalls(resp openai.ChatCompletionResponse, name string) []string {
+	var args []string
+	for _, choice := range resp.Choices {
+		for _, call := range choice.Message.ToolCalls {
+			if call.Type == openai.ToolTypeFunction && call.Function.Name == name {
+				args = append(args, call.Function.Arguments)
+			}
+		}
+		if call := choice.Message.FunctionCall; call != nil && call.Name == name {
+			args = append(args, call.Arguments)
+		}
+	}
+	return args
+}
+
 // SummaryPrefixParams is a struct that stores configuration options for the get_summary_prefix function.
 type SummaryPrefixParams struct {
 	Prefix string `json:"prefix"`
 }
 
-// GetSummaryPrefixArgs returns the SummaryPrefixParams struct corresponding to the given JSON data.
-func GetSummaryPrefixArgs(data string) SummaryPrefixParams {
+// GetSummaryPrefixArgs returns the SummaryPrefixParams struct corresponding to the given JSON data,
+// an error when the arguments returned by the model aren't valid JSON.
+func GetSummaryPrefixArgs(data string) (SummaryPrefixParams, error) {
 	var prefix SummaryPrefixParams
-	err := json.Unmarshal(bytesconv.StrToBytes(data), &prefix)
-	if err != nil {
-		panic(err)
+	if err := json.Unmarshal(bytesconv.StrToBytes(data), &prefix); err != nil {
+		return prefix, fmt.Errorf("invalid arguments of %s: %w", SummaryPrefixFunc.Name, err)
+	}
+	return prefix, nil
+}
+
+// CommitMessageFunc is the openai function definition of a structured commit message.
+var CommitMessageFunc = openai.FunctionDefinition{
+	Name:        "write_commit_message",
+	Description: "Write a conventional commit message",
+	Parameters: jsonschema.Definition{
+		Type: jsonschema.Object,
+		Properties: map[string]jsonschema.Definition{
+			"type": {
+				Type: jsonschema.String,
+				Enum: []string{
+					"build", "chore", "ci",
+					"docs", "feat", "fix",
+					"perf", "refactor", "style",
+					"test",
+				},
+			},
+			"scope": {
+				Type:        jsonschema.String,
+				Description: "optional scope of the change, like a package or a component",
+			},
+			"subject": {
+				Type:        jsonschema.String,
+				Description: "title in the imperative tense, no more than 50 characters",
+			},
+			"body": {
+				Type:        jsonschema.String,
+				Description: "bullet points of the changes",
+			},
+			"breaking": {
+				Type:        jsonschema.Boolean,
+				Description: "whether the change breaks the backward compatibility",
+			},
+		},
+		Required: []string{"type", "subject", "body", "breaking"},
+	},
+}
+
+// CommitMessageParams is the structured commit message of the write_commit_message function.
+type CommitMessageParams struct {
+	Type     string `json:"type"`
+	Scope    string `json:"scope"`
+	Subject  string `json:"subject"`
+	Body     string `json:"body"`
+	Breaking bool   `json:"breaking"`
+}
+
+// GetCommitMessageArgs returns the CommitMessageParams struct corresponding to the given JSON data.
+// The Markdown code fence some models wrap the JSON object in is removed.
+func GetCommitMessageArgs(data string) (CommitMessageParams, error) {
+	var msg CommitMessageParams
+	data = strings.TrimSpace(data)
+	data = strings.TrimPrefix(data, "```json")
+	data = strings.Trim(data, "`\n ")
+	if err := json.Unmarshal(bytesconv.StrToBytes(data), &msg); err != nil {
+		return msg, fmt.Errorf("invalid structured commit message: %w", err)
+	}
+	if msg.Type == "" || msg.Subject == "" {
+		return msg, errors.New("the structured commit message misses the type or the subject")
 	}
-	return prefix
+	return msg, nil
 }
@@ -3,12 +3,17 @@ package openai
 import (
 	"reflect"
 	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
 )
 
 func TestGetSummaryPrefixArgs(t *testing.T) {
 	data := `{"prefix": "feat", "param2": "value2"}`
 
-	result := GetSummaryPrefixArgs(data)
+	result, err := GetSummaryPrefixArgs(data)
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	expected := SummaryPrefixParams{
 		Prefix: "feat",
@@ -18,3 +23,59 @@ func TestGetSummaryPrefixArgs(t *testing.T) {
 		t.Errorf("Expected %v, but got %v", expected, result)
 	}
 }
+
+func TestGetSummaryPrefixArgsInvalid(t *testing.T) {
+	if _, err := GetSummaryPrefixArgs(`{"prefix": "feat"`); err == nil {
+		t.Error("GetSummaryPrefixArgs() expected an error for invalid JSON")
+	}
+}
+
+func TestGetCommitMessageArgs(t *testing.T) {
+	data := "```json\n{\"type\": \"feat\", \"scope\": \"cli\", \"subject\": \"add the models command\", \"body\": \"- list models\", \"breaking\": true}\n```"
+
+	result, err := GetCommitMessageArgs(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := CommitMessageParams{
+		Type:     "feat",
+		Scope:    "cli",
+		Subject:  "add the models command",
+		Body:     "- list models",
+		Breaking: true,
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, but got %v", expected, result)
+	}
+
+	if _, err := GetCommitMessageArgs(`{"scope": "cli"}`); err == nil {
+		t.Error("expected an error without type and subject")
+	}
+}
+
+func TestToolCalls(t *testing.T) {
+	resp := openai.ChatCompletionResponse{
+		Choices: []openai.ChatCompletionChoice{
+			{
+				Message: openai.ChatCompletionMessage{
+					ToolCalls: []openai.ToolCall{
+						{Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "get_summary_prefix", Arguments: `{"prefix": "feat"}`}},
+						{Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "other", Arguments: `{}`}},
+						{Type: openai.ToolTypeFunction, Function: openai.FunctionCall{Name: "get_summary_prefix", Arguments: `{"prefix": "fix"}`}},
+					},
+				},
+			},
+			{
+				Message: openai.ChatCompletionMessage{
+					FunctionCall: &openai.FunctionCall{Name: "get_summary_prefix", Arguments: `{"prefix": "docs"}`},
+				},
+			},
+		},
+	}
+
+	expected := []string{`{"prefix": "feat"}`, `{"prefix": "fix"}`, `{"prefix": "docs"}`}
+	if got := ToolCalls(resp, SummaryPrefixFunc.Name); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, but got %v", expected, got)
+	}
+}
@@ -0,0 +1,56 @@
+package openai
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+)
+
+// defaultMockResponse answers the built-in prompts: the commit label,
+// the structured commit message, the JSON findings of a review and a summary
+// for everything else.
+const defaultMockResponse = `
+{{- if contains .Prompt "best label for the commit" -}}
+feat
+{{- else if contains .Prompt "conventional commit as a JSON object" -}}
+{"type": "feat", "subject": "update the code", "body": "- update the code", "breaking": false}
+{{- else if contains .Prompt "JSON" -}}
+[]
+{{- else -}}
+update the code
+{{- end -}}`
+
+// mockData is the data of the mock response template.
+type mockData struct {
+	Prompt string
+	Model  string
+}
+
+// newMockTemplate parses the mock response template, the default one if it's empty.
+func newMockTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		text = defaultMockResponse
+	}
+	return template.New("mock").Funcs(template.FuncMap{
+		"contains":  strings.Contains,
+		"hasPrefix": strings.HasPrefix,
+	}).Parse(text)
+}
+
+// mockCompletion returns the mock response to the prompt without any network access.
+// The token usage is estimated from the prompt and the response.
+func (c *Client) mockCompletion(content string) (*Response, error) {
+	var buf bytes.Buffer
+	if err := c.mock.Execute(&buf, mockData{
+		Prompt: content,
+		Model:  c.model,
+	}); err != nil {
+		return nil, err
+	}
+
+	resp := &Response{Content: buf.String(), Choices: []string{buf.String()}}
+	resp.Usage.PromptTokens = EstimateTokens(content)
+	resp.Usage.CompletionTokens = EstimateTokens(resp.Content)
+	resp.Usage.TotalTokens = resp.Usage.PromptTokens + resp.Usage.CompletionTokens
+	return resp, nil
+}
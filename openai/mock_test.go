@@ -0,0 +1,66 @@
+package openai
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMockCompletion(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		prompt   string
+		want     string
+	}{
+		{
+			name:   "default summary",
+			prompt: "summarize a git diff",
+			want:   "update the code",
+		},
+		{
+			name:   "default label",
+			prompt: "Determine the best label for the commit.",
+			want:   "feat",
+		},
+		{
+			name:   "default structured commit",
+			prompt: "Write the conventional commit as a JSON object with the following fields",
+			want:   `{"type": "feat", "subject": "update the code", "body": "- update the code", "breaking": false}`,
+		},
+		{
+			name:   "default findings",
+			prompt: "Respond with a JSON array of findings",
+			want:   "[]",
+		},
+		{
+			name:     "custom template",
+			response: `{{ if hasPrefix .Prompt "title" }}add mock provider{{ else }}{{ .Model }}{{ end }}`,
+			prompt:   "title of the pull request",
+			want:     "add mock provider",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := New(WithProvider(MOCK), WithMockResponse(tt.response))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Completion(context.Background(), tt.prompt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Content != tt.want {
+				t.Errorf("Completion() = %q, want %q", resp.Content, tt.want)
+			}
+			if resp.Usage.TotalTokens == 0 {
+				t.Error("Completion() returned no token usage")
+			}
+		})
+	}
+}
+
+func TestMockInvalidTemplate(t *testing.T) {
+	if _, err := New(WithProvider(MOCK), WithMockResponse("{{ .Prompt")); err == nil {
+		t.Error("New() accepted an invalid mock response template")
+	}
+}
@@ -0,0 +1,106 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/logger"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// The actions of the moderation when the prompt is flagged.
+const (
+	ModerationBlock = "block"
+	ModerationWarn  = "warn"
+)
+
+var (
+	errorsInvalidModeration     = errors.New("invalid moderation action, use block or warn")
+	errorsModerationUnsupported = errors.New("the moderation is only available with the openai provider")
+)
+
+// FlaggedError is returned when the moderation flags the prompt and blocks it.
+type FlaggedError struct {
+	Categories []string
+}
+
+func (e *FlaggedError) Error() string {
+	return "the prompt is flagged by the moderation: " + strings.Join(e.Categories, ", ")
+}
+
+// IsModerationAction returns true if the action of the moderation is known.
+func IsModerationAction(val string) bool {
+	return val == ModerationBlock || val == ModerationWarn
+}
+
+// moderate runs the prompt and the system message through the moderation endpoint
+// before they are sent. A flagged prompt returns a FlaggedError with the block action,
+// a warning with the warn one. The result of every prompt is checked only once.
+func (c *Client) moderate(ctx context.Context, content string) error {
+	if c.moderation == "" || c.mock != nil {
+		return nil
+	}
+
+	input := content
+	if c.system != "" {
+		input = c.system + "\n\n" + content
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	categories, ok := c.moderated[input]
+	if !ok {
+		resp, err := c.client.Moderations(ctx, openai.ModerationRequest{
+			Input: input,
+			Model: openai.ModerationOmniLatest,
+		})
+		if err != nil {
+			return err
+		}
+		categories = flaggedCategories(resp.Results)
+		c.moderated[input] = categories
+	}
+
+	if len(categories) == 0 {
+		return nil
+	}
+	flagged := &FlaggedError{Categories: categories}
+	if c.moderation == ModerationWarn {
+		logger.Warn(flagged.Error())
+		return nil
+	}
+	return flagged
+}
+
+// flaggedCategories returns the sorted names of the categories of the flagged results,
+// or "unknown" for a flagged result without category.
+func flaggedCategories(results []openai.Result) []string {
+	var (
+		out     []string
+		flagged bool
+	)
+	for _, r := range results {
+		if !r.Flagged {
+			continue
+		}
+		flagged = true
+		var categories map[string]bool
+		data, _ := json.Marshal(r.Categories)
+		_ = json.Unmarshal(data, &categories)
+		for name, ok := range categories {
+			if ok && !slices.Contains(out, name) {
+				out = append(out, name)
+			}
+		}
+	}
+	if flagged && len(out) == 0 {
+		return []string{"unknown"}
+	}
+	sort.Strings(out)
+	return out
+}
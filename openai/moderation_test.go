@@ -0,0 +1,97 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+func TestModeration(t *testing.T) {
+	var moderations, completions int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/moderations":
+			moderations++
+			var req openai.ModerationRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				t.Error(err)
+			}
+			flagged := strings.Contains(req.Input, "threat")
+			fmt.Fprintf(w, `{"id":"modr-1","model":"omni-moderation-latest","results":[{"flagged":%t,"categories":{"violence":%t,"harassment":%t}}]}`,
+				flagged, flagged, flagged)
+		case "/chat/completions":
+			completions++
+			fmt.Fprint(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"Add feature"}}]}`)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name            string
+		action          string
+		content         string
+		wantErr         []string
+		wantCompletions int
+	}{
+		{name: "clean prompt", action: ModerationBlock, content: "add the feature", wantCompletions: 1},
+		{name: "blocked prompt", action: ModerationBlock, content: "a threat", wantErr: []string{"harassment", "violence"}},
+		{name: "warned prompt", action: ModerationWarn, content: "a threat", wantCompletions: 1},
+		{name: "disabled", content: "a threat", wantCompletions: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			moderations, completions = 0, 0
+			client, err := New(
+				WithToken("sk-test"),
+				WithModel(openai.GPT4o),
+				WithBaseURL(srv.URL),
+				WithModeration(tt.action),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the second call reuses the result of the moderation
+			for i := 0; i < 2; i++ {
+				_, err = client.Completion(context.Background(), tt.content)
+			}
+			var flagged *FlaggedError
+			if tt.wantErr != nil {
+				if !errors.As(err, &flagged) || !reflect.DeepEqual(flagged.Categories, tt.wantErr) {
+					t.Fatalf("Completion() error = %v, want categories %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			wantModerations := 1
+			if tt.action == "" {
+				wantModerations = 0
+			}
+			if moderations != wantModerations || completions != 2*tt.wantCompletions {
+				t.Errorf("got %d moderations and %d completions", moderations, completions)
+			}
+		})
+	}
+}
+
+func TestFlaggedCategories(t *testing.T) {
+	results := []openai.Result{
+		{Flagged: false, Categories: openai.ResultCategories{Hate: true}},
+		{Flagged: true},
+	}
+	if got := flaggedCategories(results); !reflect.DeepEqual(got, []string{"unknown"}) {
+		t.Errorf("flaggedCategories() = %v", got)
+	}
+	if got := flaggedCategories(results[:1]); got != nil {
+		t.Errorf("flaggedCategories() = %v, want nil", got)
+	}
+}
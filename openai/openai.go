@@ -2,50 +2,36 @@ package openai
 
 import (
 	"context"
-	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
-	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/appleboy/CodeGPT/cassette"
+	"github.com/appleboy/CodeGPT/logger"
+	"github.com/appleboy/CodeGPT/privacy"
 
 	openai "github.com/sashabaranov/go-openai"
-	"golang.org/x/net/proxy"
 )
 
 // DefaultModel is the default OpenAI model to use if one is not provided.
 var DefaultModel = openai.GPT3Dot5Turbo
 
-// modelMaps maps model names to their corresponding model ID strings.
-var modelMaps = map[string]string{
-	"gpt-4-32k-0613":         openai.GPT432K0613,
-	"gpt-4-32k-0314":         openai.GPT432K0314,
-	"gpt-4-32k":              openai.GPT432K,
-	"gpt-4-0613":             openai.GPT40613,
-	"gpt-4-0314":             openai.GPT40314,
-	"gpt-4":                  openai.GPT4,
-	"gpt-3.5-turbo-0613":     openai.GPT3Dot5Turbo0613,
-	"gpt-3.5-turbo-0301":     openai.GPT3Dot5Turbo0301,
-	"gpt-3.5-turbo-16k":      openai.GPT3Dot5Turbo16K,
-	"gpt-3.5-turbo-16k-0613": openai.GPT3Dot5Turbo16K0613,
-	"gpt-3.5-turbo":          openai.GPT3Dot5Turbo,
-	"gpt-3.5-turbo-instruct": openai.GPT3Dot5TurboInstruct,
-	"davinci":                openai.GPT3Davinci,
-	"davinci-002":            openai.GPT3Davinci002,
-	"curie":                  openai.GPT3Curie,
-	"curie-002":              openai.GPT3Curie002,
-	"ada":                    openai.GPT3Ada,
-	"ada-002":                openai.GPT3Ada002,
-	"babbage":                openai.GPT3Babbage,
-	"babbage-002":            openai.GPT3Babbage002,
-}
+// privacyCheckTimeout bounds the resolution of the endpoint checked by the privacy policy.
+const privacyCheckTimeout = 5 * time.Second
 
-// GetModel returns the model ID corresponding to the given model name.
-// If the model name is not recognized, it returns the default model ID.
-func GetModel(model string) string {
-	v, ok := modelMaps[model]
-	if !ok {
-		return DefaultModel
-	}
-	return v
+// reasoningTokens is the budget of the reasoning tokens of the o-series models,
+// added to the max tokens of the completion.
+const reasoningTokens = 4096
+
+// IsSupportedModel returns true if the capabilities of the model are known.
+func IsSupportedModel(model string) bool {
+	_, ok := lookup(model)
+	return ok
 }
 
 // Client is a struct that represents an OpenAI client.
@@ -54,53 +40,192 @@ type Client struct {
 	model       string
 	maxTokens   int
 	temperature float32
+	topP        float32
 	isFuncCall  bool
+	isJSONMode  bool
+	reasoning   bool
+	mock        *template.Template
+	bedrock     *bedrock
+	tgi         *tgi
+	system      string
+	stop        []string
+
+	frequencyPenalty float32
+	presencePenalty  float32
+	seed             *int
+	embeddingModel   string
+
+	// the moderation action and the categories flagged for every checked prompt
+	moderation string
+	moderated  map[string][]string
+	mu         sync.Mutex
+	limiter    *limiter
+
+	// used to describe the endpoint in dry-run mode
+	provider   string
+	baseURL    string
+	modelName  string
+	apiVersion string
 }
 
+// Usage represents the total token usage per request.
+type Usage = openai.Usage
+
 type Response struct {
 	Content string
+	Choices []string
 	Usage   openai.Usage
 }
 
-// CreateChatCompletion is an API call to create a function call for a chat message.
+// messages returns the chat messages of the prompt, the system message goes first.
+// The reasoning models don't support the system message, it leads the prompt instead.
+func (c *Client) messages(content string) []openai.ChatCompletionMessage {
+	var msgs []openai.ChatCompletionMessage
+	if c.system != "" && c.reasoning {
+		content = c.system + "\n\n" + content
+	} else if c.system != "" {
+		msgs = append(msgs, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: c.system,
+		})
+	}
+	return append(msgs, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleUser,
+		Content: content,
+	})
+}
+
+// SystemPrompt returns the system message sent before every prompt.
+func (c *Client) SystemPrompt() string {
+	return c.system
+}
+
+// CreateFunctionCall is an API call to create a tool call of the functions for a chat message.
+// A single function is forced with the tool_choice, the model picks among several functions
+// and can call them in parallel. Use ToolCalls to read the arguments of the calls.
 func (c *Client) CreateFunctionCall(
 	ctx context.Context,
 	content string,
 	funcs ...openai.FunctionDefinition,
 ) (resp openai.ChatCompletionResponse, err error) {
-	req := openai.ChatCompletionRequest{
-		Model:       c.model,
-		MaxTokens:   c.maxTokens,
-		Temperature: c.temperature,
-		TopP:        1,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: content,
-			},
-		},
-		Functions:    funcs,
-		FunctionCall: "auto",
+	req := c.chatRequest(content)
+	for i := range funcs {
+		req.Tools = append(req.Tools, openai.Tool{
+			Type:     openai.ToolTypeFunction,
+			Function: &funcs[i],
+		})
+	}
+	req.ToolChoice = "auto"
+	if len(funcs) == 1 {
+		req.ToolChoice = openai.ToolChoice{
+			Type:     openai.ToolTypeFunction,
+			Function: openai.ToolFunction{Name: funcs[0].Name},
+		}
+	}
+	c.debugRequest(content)
+	if err := c.moderate(ctx, content); err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	if err := c.throttle(ctx, content, 1); err != nil {
+		return openai.ChatCompletionResponse{}, err
 	}
 	return c.client.CreateChatCompletion(ctx, req)
 }
 
+// Structured returns the arguments of the function as a JSON object in the Content of the response:
+// from the JSON mode when the model supports it, from a function call otherwise, and from the
+// plain completion as a last resort. The prompt must ask for a JSON object.
+func (c *Client) Structured(
+	ctx context.Context,
+	content string,
+	fn openai.FunctionDefinition,
+) (*Response, error) {
+	switch {
+	case c.mock != nil:
+		c.debugRequest(content)
+		return c.mockCompletion(content)
+	case c.isJSONMode:
+		c.debugRequest(content)
+		if err := c.moderate(ctx, content); err != nil {
+			return nil, err
+		}
+		if err := c.throttle(ctx, content, 1); err != nil {
+			return nil, err
+		}
+		req := c.chatRequest(content)
+		req.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		}
+		r, err := c.client.CreateChatCompletion(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if len(r.Choices) == 0 {
+			return nil, errors.New("no completion returned")
+		}
+		return &Response{Content: r.Choices[0].Message.Content, Usage: r.Usage}, nil
+	case c.isFuncCall:
+		r, err := c.CreateFunctionCall(ctx, content, fn)
+		if err != nil {
+			return nil, err
+		}
+		args := ToolCalls(r, fn.Name)
+		if len(args) == 0 {
+			return nil, errors.New("no tool call returned")
+		}
+		return &Response{Content: args[0], Usage: r.Usage}, nil
+	default:
+		return c.Completion(ctx, content)
+	}
+}
+
 // CreateChatCompletion is an API call to create a completion for a chat message.
 func (c *Client) CreateChatCompletion(
 	ctx context.Context,
 	content string,
 ) (resp openai.ChatCompletionResponse, err error) {
+	return c.createChatCompletion(ctx, content, 1)
+}
+
+// chatRequest returns the chat completion request of the prompt.
+func (c *Client) chatRequest(content string) openai.ChatCompletionRequest {
+	// the reasoning models have fixed sampling parameters
+	if c.reasoning {
+		return openai.ChatCompletionRequest{
+			Model:               c.model,
+			MaxCompletionTokens: c.maxTokens + reasoningTokens,
+			Seed:                c.seed,
+			Messages:            c.messages(content),
+		}
+	}
+
 	req := openai.ChatCompletionRequest{
-		Model:       c.model,
-		MaxTokens:   c.maxTokens,
-		Temperature: c.temperature,
-		TopP:        1,
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    openai.ChatMessageRoleUser,
-				Content: content,
-			},
-		},
+		Model:            c.model,
+		MaxTokens:        c.maxTokens,
+		Temperature:      c.temperature,
+		TopP:             c.topP,
+		FrequencyPenalty: c.frequencyPenalty,
+		PresencePenalty:  c.presencePenalty,
+		Seed:             c.seed,
+		Stop:             c.stop,
+		Messages:         c.messages(content),
+	}
+	// Mistral rejects the unknown fields, its seed is named random_seed
+	if c.provider == MISTRAL {
+		req.Seed = nil
+	}
+	return req
+}
+
+// createChatCompletion creates n completions for a chat message.
+func (c *Client) createChatCompletion(
+	ctx context.Context,
+	content string,
+	n int,
+) (resp openai.ChatCompletionResponse, err error) {
+	req := c.chatRequest(content)
+	if n > 1 {
+		req.N = n
 	}
 
 	return c.client.CreateChatCompletion(ctx, req)
@@ -115,54 +240,155 @@ func (c *Client) CreateChatCompletion(
 func (c *Client) CreateCompletion(
 	ctx context.Context,
 	content string,
+) (resp openai.CompletionResponse, err error) {
+	return c.createCompletion(ctx, content, 1)
+}
+
+// createCompletion creates n completions for the prompt.
+func (c *Client) createCompletion(
+	ctx context.Context,
+	content string,
+	n int,
 ) (resp openai.CompletionResponse, err error) {
 	req := openai.CompletionRequest{
-		Model:       c.model,
-		MaxTokens:   c.maxTokens,
-		Temperature: c.temperature,
-		TopP:        1,
-		Prompt:      content,
+		Model:            c.model,
+		MaxTokens:        c.maxTokens,
+		Temperature:      c.temperature,
+		TopP:             c.topP,
+		FrequencyPenalty: c.frequencyPenalty,
+		PresencePenalty:  c.presencePenalty,
+		Stop:             c.stop,
+		Prompt:           content,
+	}
+	// the completions endpoint has no roles, the system message leads the prompt
+	if c.system != "" {
+		req.Prompt = c.system + "\n\n" + content
+	}
+	if n > 1 {
+		req.N = n
 	}
 
 	return c.client.CreateCompletion(ctx, req)
 }
 
+// isChat returns true if the model uses the chat completions endpoint.
+func (c *Client) isChat() bool {
+	return c.capability().Chat
+}
+
+// capability returns the capabilities of the model. The model map is skipped with
+// the OpenAI-compatible endpoints, their models use the plain chat prompts.
+func (c *Client) capability() Capability {
+	if c.provider == COMPATIBLE {
+		return Capability{Chat: true}
+	}
+	return CapabilityOf(c.model)
+}
+
+// Endpoint returns the URL the prompts are sent to.
+func (c *Client) Endpoint() string {
+	if c.provider == MOCK {
+		return "mock provider, no request is sent"
+	}
+	if c.bedrock != nil {
+		return c.bedrock.endpoint(c.baseURL, c.model)
+	}
+	if c.tgi != nil {
+		return c.tgi.route()
+	}
+
+	suffix := "/completions"
+	if c.isChat() {
+		suffix = "/chat/completions"
+	}
+	if c.provider == AZURE {
+		return strings.TrimRight(c.baseURL, "/") + "/openai/deployments/" + c.modelName + suffix + "?api-version=" + c.apiVersion
+	}
+	return strings.TrimRight(c.baseURL, "/") + suffix
+}
+
 // Completion is a method on the Client struct that takes a context.Context and a string argument
 // and returns a string and an error.
 func (c *Client) Completion(
 	ctx context.Context,
 	content string,
 ) (*Response, error) {
+	return c.Candidates(ctx, content, 1)
+}
+
+// Candidates requests n completions of the prompt in one call, the Choices of the
+// response hold all of them and the Content the first one.
+func (c *Client) Candidates(
+	ctx context.Context,
+	content string,
+	n int,
+) (*Response, error) {
+	c.debugRequest(content)
+	if err := c.moderate(ctx, content); err != nil {
+		return nil, err
+	}
+
+	if c.mock != nil {
+		return c.mockCompletion(content)
+	}
+	if err := c.throttle(ctx, content, n); err != nil {
+		return nil, err
+	}
+
+	if c.bedrock != nil {
+		return c.bedrockCompletion(ctx, content, n)
+	}
+	if c.tgi != nil {
+		return c.tgiCompletion(ctx, content, n)
+	}
+
 	resp := &Response{}
-	switch c.model {
-	case openai.GPT3Dot5Turbo,
-		openai.GPT3Dot5Turbo0301,
-		openai.GPT3Dot5Turbo0613,
-		openai.GPT3Dot5Turbo16K,
-		openai.GPT3Dot5Turbo16K0613,
-		openai.GPT4,
-		openai.GPT40314,
-		openai.GPT40613,
-		openai.GPT432K,
-		openai.GPT432K0314,
-		openai.GPT432K0613:
-		r, err := c.CreateChatCompletion(ctx, content)
+	switch {
+	case c.isChat():
+		r, err := c.createChatCompletion(ctx, content, n)
 		if err != nil {
 			return nil, err
 		}
-		resp.Content = r.Choices[0].Message.Content
+		for _, choice := range r.Choices {
+			resp.Choices = append(resp.Choices, choice.Message.Content)
+		}
 		resp.Usage = r.Usage
 	default:
-		r, err := c.CreateCompletion(ctx, content)
+		r, err := c.createCompletion(ctx, content, n)
 		if err != nil {
 			return nil, err
 		}
-		resp.Content = r.Choices[0].Text
+		for _, choice := range r.Choices {
+			resp.Choices = append(resp.Choices, choice.Text)
+		}
 		resp.Usage = r.Usage
 	}
+	if len(resp.Choices) == 0 {
+		return nil, errors.New("no completion returned")
+	}
+	resp.Content = resp.Choices[0]
 	return resp, nil
 }
 
+// debugRequest logs the request parameters and the final prompt at debug level.
+func (c *Client) debugRequest(content string) {
+	if !logger.Enabled(logger.DebugLevel) {
+		return
+	}
+	logger.Debug(fmt.Sprintf("model: %s, max_tokens: %d, temperature: %g, top_p: %g, frequency_penalty: %g, presence_penalty: %g",
+		c.model, c.maxTokens, c.temperature, c.topP, c.frequencyPenalty, c.presencePenalty))
+	if c.seed != nil {
+		logger.Debug(fmt.Sprintf("seed: %d", *c.seed))
+	}
+	if len(c.stop) > 0 {
+		logger.Debug(fmt.Sprintf("stop: %q", c.stop))
+	}
+	if c.system != "" {
+		logger.Debug("system prompt:\n" + c.system)
+	}
+	logger.Debug("prompt:\n" + content)
+}
+
 // New creates a new OpenAI API client with the given options.
 func New(opts ...Option) (*Client, error) {
 	// Create a new config object with the given options.
@@ -175,9 +401,28 @@ func New(opts ...Option) (*Client, error) {
 
 	// Create a new client instance with the necessary fields.
 	engine := &Client{
-		model:       modelMaps[cfg.model],
+		model:       cfg.model,
 		maxTokens:   cfg.maxTokens,
 		temperature: cfg.temperature,
+		provider:    cfg.provider,
+		modelName:   cfg.deployment(cfg.model),
+		system:      strings.TrimSpace(cfg.systemPrompt),
+		topP:        cfg.topP,
+
+		frequencyPenalty: cfg.frequencyPenalty,
+		presencePenalty:  cfg.presencePenalty,
+		seed:             cfg.seed,
+		stop:             cfg.stop,
+		embeddingModel:   cfg.embeddingModel,
+		moderation:       cfg.moderation,
+		moderated:        map[string][]string{},
+		limiter:          newLimiter(cfg.requestsPerMinute, cfg.tokensPerMinute, cfg.rateLimitFile),
+	}
+	if engine.embeddingModel == "" {
+		engine.embeddingModel = defaultEmbeddingModel
+		if cfg.provider == MISTRAL {
+			engine.embeddingModel = mistralEmbeddingModel
+		}
 	}
 
 	// Create a new OpenAI config object with the given API token and other optional fields.
@@ -187,12 +432,25 @@ func New(opts ...Option) (*Client, error) {
 	}
 	if cfg.baseURL != "" {
 		c.BaseURL = cfg.baseURL
+	} else if cfg.provider == MISTRAL {
+		c.BaseURL = defaultMistralURL
 	}
 
-	// Create a new HTTP transport.
-	tr := &http.Transport{}
-	if cfg.skipVerify {
-		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	// Create a new HTTP transport with the custom CA certificates and client certificate, if any.
+	tlsCfg, err := tlsConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	dialer := &net.Dialer{Timeout: cfg.connectTimeout, KeepAlive: 30 * time.Second}
+	dial := dialer.DialContext
+	if cfg.privacy != nil {
+		// the dialed addresses are checked, a name may resolve to others after the check
+		dial = cfg.privacy.Dialer(dialer)
+	}
+	tr := &http.Transport{
+		TLSClientConfig:     tlsCfg,
+		DialContext:         dial,
+		TLSHandshakeTimeout: cfg.connectTimeout,
 	}
 
 	// Create a new HTTP client with the specified timeout and proxy, if any.
@@ -200,35 +458,69 @@ func New(opts ...Option) (*Client, error) {
 		Timeout: cfg.timeout,
 	}
 
-	if cfg.proxyURL != "" {
-		proxyURL, _ := url.Parse(cfg.proxyURL)
-		tr.Proxy = http.ProxyURL(proxyURL)
-	} else if cfg.socksURL != "" {
-		dialer, err := proxy.SOCKS5("tcp", cfg.socksURL, nil, proxy.Direct)
+	// The SOCKS5 proxy dials the connections, otherwise the HTTP proxy of the config
+	// or of the environment variables is used.
+	if cfg.socksURL != "" && cfg.proxyURL == "" {
+		dial, err := socksDialer(cfg, dialer)
+		if err != nil {
+			return nil, err
+		}
+		tr.DialContext = dial
+	} else {
+		tr.Proxy, err = proxyFunc(cfg)
 		if err != nil {
-			return nil, fmt.Errorf("can't connect to the proxy: %s", err)
+			return nil, err
 		}
-		tr.DialContext = dialer.(proxy.ContextDialer).DialContext
+	}
+
+	// Refuse the requests and the proxies outside of the privacy policy, if any.
+	var origin http.RoundTripper = tr
+	if cfg.privacy != nil {
+		if err := checkPrivacy(cfg, tr); err != nil {
+			return nil, err
+		}
+		origin = &privacy.Transport{Origin: tr, Policy: cfg.privacy}
+	}
+
+	// Record or replay the HTTP interactions with a cassette, if any.
+	if cfg.cassetteFile != "" {
+		rec, err := cassette.NewTransport(origin, cfg.cassetteMode, cfg.cassetteFile)
+		if err != nil {
+			return nil, err
+		}
+		origin = rec
 	}
 
 	// Set the HTTP client to use the default header transport with the specified headers.
 	httpClient.Transport = &DefaultHeaderTransport{
-		Origin: tr,
-		Header: NewHeaders(cfg.headers),
+		Origin: &DebugTransport{Origin: origin},
+		Header: EndpointHeaders(cfg.baseURL, cfg.headers),
 	}
 
 	// Set the OpenAI client to use the default configuration with Azure-specific options, if the provider is Azure.
 	if cfg.provider == AZURE {
 		defaultAzureConfig := openai.DefaultAzureConfig(cfg.token, cfg.baseURL)
-		defaultAzureConfig.AzureModelMapperFunc = func(model string) string {
-			return cfg.modelName
-		}
+		defaultAzureConfig.AzureModelMapperFunc = cfg.deployment
 		// Set the API version to the one with the specified options.
 		if cfg.apiVersion != "" {
 			defaultAzureConfig.APIVersion = cfg.apiVersion
 		}
+		// Authenticate with Microsoft Entra ID tokens instead of the API key.
+		if cfg.azureAD() {
+			credential, err := azureCredential(cfg)
+			if err != nil {
+				return nil, fmt.Errorf("can't create the Azure credential: %w", err)
+			}
+			defaultAzureConfig.APIType = openai.APITypeAzureAD
+			httpClient.Transport = &AzureADTransport{
+				Origin:     httpClient.Transport,
+				Credential: credential,
+			}
+		}
 		// Set the HTTP client to the one with the specified options.
 		defaultAzureConfig.HTTPClient = httpClient
+		engine.baseURL = defaultAzureConfig.BaseURL
+		engine.apiVersion = defaultAzureConfig.APIVersion
 		engine.client = openai.NewClientWithConfig(
 			defaultAzureConfig,
 		)
@@ -238,31 +530,128 @@ func New(opts ...Option) (*Client, error) {
 		if cfg.apiVersion != "" {
 			c.APIVersion = cfg.apiVersion
 		}
+		engine.baseURL = c.BaseURL
 		engine.client = openai.NewClientWithConfig(c)
 	}
 
 	engine.isFuncCall = engine.allowFuncCall(cfg)
+	engine.isJSONMode = engine.allowJSONMode(cfg)
+	engine.reasoning = engine.capability().Reasoning
+
+	// new models work without code changes, but their features are guessed
+	if (cfg.provider == OPENAI || cfg.provider == MISTRAL) && !IsSupportedModel(engine.model) {
+		logger.Warn("unknown model " + engine.model + ", it's sent as is and assumed to be a chat model")
+	}
+
+	// The Bedrock provider signs the requests with the AWS credentials.
+	if cfg.provider == BEDROCK {
+		b, err := newBedrock(cfg, httpClient)
+		if err != nil {
+			return nil, fmt.Errorf("can't load the AWS config: %w", err)
+		}
+		engine.bedrock = b
+		engine.baseURL = cfg.baseURL
+	}
+
+	// The Hugging Face provider sends the prompts to the generate routes of TGI.
+	if cfg.provider == HUGGINGFACE {
+		engine.tgi = &tgi{
+			httpClient: httpClient,
+			baseURL:    cfg.baseURL,
+			token:      cfg.token,
+			stream:     cfg.stream,
+		}
+		engine.baseURL = cfg.baseURL
+	}
+
+	// The mock provider answers every prompt with its response template.
+	if cfg.provider == MOCK {
+		tmpl, err := newMockTemplate(cfg.mockResponse)
+		if err != nil {
+			return nil, fmt.Errorf("invalid mock response template: %w", err)
+		}
+		engine.mock = tmpl
+	}
+
+	// Fail closed before sending anything when the endpoint is outside of the privacy policy.
+	if cfg.privacy != nil && cfg.provider != MOCK &&
+		!(cfg.cassetteFile != "" && cfg.cassetteMode == cassette.ModeReplay) {
+		ctx, cancel := context.WithTimeout(context.Background(), privacyCheckTimeout)
+		defer cancel()
+		if err := cfg.privacy.Check(ctx, engine.Endpoint()); err != nil {
+			return nil, fmt.Errorf("the endpoint of the %s provider is refused: %w", cfg.provider, err)
+		}
+	}
 
 	// Return the resulting client engine.
 	return engine, nil
 }
 
-// allowFuncCall returns true if the model supports function calls.
+// azureToolsVersion is the first Azure API version with the tools API, 2023-12-01-preview.
+var azureToolsVersion = time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)
+
+// allowFuncCall returns true if the model supports the tools API.
 // https://learn.microsoft.com/en-us/azure/ai-services/openai/how-to/function-calling
-// Function calling is available in the 2023-07-01-preview API version and works with version 0613 of
-// gpt-35-turbo, gpt-35-turbo-16k, gpt-4, and gpt-4-32k.
+// On Azure the deployment decides the model, the tools are available from the
+// 2023-12-01-preview API version.
 func (c *Client) allowFuncCall(cfg *config) bool {
-	if cfg.provider == AZURE && cfg.apiVersion == "2023-07-01-preview" {
-		return true
+	if cfg.provider == MOCK || cfg.provider == BEDROCK || cfg.provider == HUGGINGFACE {
+		return false
 	}
 
-	switch c.model {
-	case openai.GPT432K0613, openai.GPT40613,
-		openai.GPT3Dot5Turbo0613, openai.GPT3Dot5Turbo16K0613:
-		return true
-	default:
+	if cfg.provider == AZURE {
+		return azureToolsAPI(cfg.apiVersion)
+	}
+
+	return c.capability().Tools
+}
+
+// azureToolsAPI reports whether the Azure API version, like 2024-02-01 or 2024-05-01-preview,
+// has the tools API. The versions are compared as dates, as strings 2023-12-01 sorts
+// before 2023-12-01-preview.
+func azureToolsAPI(version string) bool {
+	if len(version) < len(time.DateOnly) {
+		return false
+	}
+	date, err := time.Parse(time.DateOnly, version[:len(time.DateOnly)])
+	if err != nil {
+		return false
+	}
+	return !date.Before(azureToolsVersion)
+}
+
+// allowJSONMode returns true if the model supports the json_object response format,
+// the providers with their own API don't.
+func (c *Client) allowJSONMode(cfg *config) bool {
+	switch cfg.provider {
+	case MOCK, BEDROCK, HUGGINGFACE:
 		return false
 	}
+	return c.capability().JSONMode
+}
+
+// Models returns the IDs of the models available with the API key.
+// It's a lightweight call to check the API key.
+func (c *Client) Models(ctx context.Context) ([]string, error) {
+	if c.mock != nil {
+		return []string{c.model}, nil
+	}
+	if c.bedrock != nil {
+		return c.bedrockModels(ctx)
+	}
+	if c.tgi != nil {
+		return c.tgiModels(ctx)
+	}
+
+	list, err := c.client.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(list.Models))
+	for _, m := range list.Models {
+		ids = append(ids, m.ID)
+	}
+	return ids, nil
 }
 
 // AllowFuncCall returns true if the model supports function calls.
@@ -0,0 +1,193 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContextWindow(t *testing.T) {
+	tests := map[string]int{
+		"gpt-3.5-turbo":          4096,
+		"gpt-3.5-turbo-16k-0613": 16384,
+		"gpt-4-0613":             8192,
+		"gpt-4-32k-0613":         32768,
+		"gpt-4o-mini":            128000,
+		"o1-mini-2024-09-12":     128000,
+		"curie":                  0,
+	}
+	for model, want := range tests {
+		if got := ContextWindow(model); got != want {
+			t.Errorf("ContextWindow(%q) = %d, want %d", model, got, want)
+		}
+	}
+}
+
+func TestMessages(t *testing.T) {
+	c := &Client{}
+	if msgs := c.messages("diff"); len(msgs) != 1 || msgs[0].Role != "user" {
+		t.Fatalf("messages without system prompt = %+v", msgs)
+	}
+
+	c.system = "be terse"
+	msgs := c.messages("diff")
+	if len(msgs) != 2 || msgs[0].Role != "system" || msgs[0].Content != "be terse" || msgs[1].Content != "diff" {
+		t.Fatalf("messages with system prompt = %+v", msgs)
+	}
+}
+
+func TestCapabilityOf(t *testing.T) {
+	if IsSupportedModel("gpt-5") {
+		t.Error("gpt-5 should be unknown")
+	}
+	if c := CapabilityOf("gpt-5"); !c.Chat || c.Tools {
+		t.Errorf("unknown models should be plain chat models, got %+v", c)
+	}
+	if c := CapabilityOf("gpt-3.5-turbo-instruct"); c.Chat {
+		t.Error("gpt-3.5-turbo-instruct should use the completions endpoint")
+	}
+}
+
+func TestReasoningRequest(t *testing.T) {
+	c := &Client{model: "o1-mini", maxTokens: 300, temperature: 0.7, topP: 1, system: "be terse", reasoning: true}
+	req := c.chatRequest("diff")
+	if req.MaxTokens != 0 || req.MaxCompletionTokens != 300+reasoningTokens {
+		t.Errorf("max tokens = %d, max completion tokens = %d", req.MaxTokens, req.MaxCompletionTokens)
+	}
+	if req.Temperature != 0 || req.TopP != 0 {
+		t.Errorf("the sampling parameters should be omitted, got %g and %g", req.Temperature, req.TopP)
+	}
+	if len(req.Messages) != 1 || req.Messages[0].Content != "be terse\n\ndiff" {
+		t.Errorf("the system prompt should lead the user message, got %+v", req.Messages)
+	}
+}
+
+func TestCompatibleCapability(t *testing.T) {
+	c := &Client{provider: COMPATIBLE, model: "gpt-3.5-turbo-instruct"}
+	if !c.isChat() {
+		t.Error("the OpenAI-compatible models should use the chat endpoint")
+	}
+	if got := c.capability(); got.Tools || got.JSONMode || got.Reasoning {
+		t.Errorf("capability() = %+v, want the plain chat prompts", got)
+	}
+}
+
+func TestEndpointHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		headers []string
+		want    map[string]string
+	}{
+		{
+			name:    "OpenRouter",
+			baseURL: "https://openrouter.ai/api/v1",
+			want:    map[string]string{"Http-Referer": "https://github.com/appleboy/CodeGPT", "X-Title": "CodeGPT"},
+		},
+		{
+			name:    "override",
+			baseURL: "https://openrouter.ai/api/v1",
+			headers: []string{"X-Title=MyApp"},
+			want:    map[string]string{"Http-Referer": "https://github.com/appleboy/CodeGPT", "X-Title": "MyApp"},
+		},
+		{
+			name:    "other endpoint",
+			baseURL: "https://api.groq.com/openai/v1",
+			headers: []string{"X-Team=infra"},
+			want:    map[string]string{"X-Team": "infra"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := EndpointHeaders(tt.baseURL, tt.headers)
+			if len(h) != len(tt.want) {
+				t.Fatalf("EndpointHeaders() = %v, want %v", h, tt.want)
+			}
+			for key, val := range tt.want {
+				if got := h.Get(key); got != val {
+					t.Errorf("%s = %q, want %q", key, got, val)
+				}
+			}
+		})
+	}
+}
+
+func TestMistralRequest(t *testing.T) {
+	seed := 42
+	c := &Client{provider: MISTRAL, model: "mistral-small-latest", maxTokens: 300, seed: &seed}
+	if got := CapabilityOf(c.model); !got.Chat || !got.JSONMode || got.Tools {
+		t.Errorf("CapabilityOf(%s) = %+v", c.model, got)
+	}
+	if req := c.chatRequest("diff"); req.Seed != nil || req.MaxTokens != 300 {
+		t.Errorf("the seed should not be sent to Mistral, got %+v", req)
+	}
+}
+
+func TestConnectTimeout(t *testing.T) {
+	// the listener accepts the connections but never answers the TLS handshake
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	client, err := New(
+		WithToken("sk-test"),
+		WithBaseURL("https://"+ln.Addr().String()+"/v1"),
+		WithConnectTimeout(100*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	_, err = client.Models(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "TLS handshake timeout") {
+		t.Fatalf("Models() error = %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("the connect timeout took %s", time.Since(start))
+	}
+}
+
+func TestCancelRequest(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the server notices the closed connection once the body is read
+		_, _ = io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+		close(done)
+	}))
+	defer srv.Close()
+
+	client, err := New(WithToken("sk-test"), WithModel("gpt-4o"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if _, err := client.Completion(ctx, "diff"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Completion() error = %v, want context canceled", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("the in-flight request was not canceled")
+	}
+}
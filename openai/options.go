@@ -2,8 +2,12 @@ package openai
 
 import (
 	"errors"
+	"strings"
 	"time"
 
+	"github.com/appleboy/CodeGPT/cassette"
+	"github.com/appleboy/CodeGPT/privacy"
+
 	"github.com/sashabaranov/go-openai"
 )
 
@@ -11,18 +15,33 @@ var (
 	errorsMissingToken      = errors.New("please set OPENAI_API_KEY environment variable")
 	errorsMissingModel      = errors.New("missing model")
 	errorsMissingAzureModel = errors.New("missing Azure deployments model name")
+	errorsInvalidPenalty    = errors.New("the frequency and presence penalties must be between -2 and 2")
+	errorsMissingRegion     = errors.New("missing AWS region, set bedrock.region or AWS_REGION")
+	errorsMissingBaseURL    = errors.New("missing base URL of the OpenAI-compatible endpoint")
 )
 
 const (
-	OPENAI = "openai"
-	AZURE  = "azure"
+	OPENAI  = "openai"
+	AZURE   = "azure"
+	MOCK    = "mock"
+	BEDROCK = "bedrock"
+	// COMPATIBLE is any endpoint of the OpenAI API, like OpenRouter, Groq,
+	// Together or LM Studio, set with the base URL.
+	COMPATIBLE = "openai_compatible"
+	// MISTRAL is the API of the Mistral AI platform.
+	MISTRAL = "mistral"
+	// HUGGINGFACE is a text-generation-inference server, like the Hugging Face
+	// Inference Endpoints, set with the base URL.
+	HUGGINGFACE = "huggingface"
 )
 
 const (
 	defaultMaxTokens   = 300
 	defaultModel       = openai.GPT3Dot5Turbo
 	defaultTemperature = 0.7
+	defaultTopP        = 1
 	defaultProvider    = OPENAI
+	defaultMistralURL  = "https://api.mistral.ai/v1"
 )
 
 // Option is an interface that specifies instrumentation configuration options.
@@ -77,6 +96,15 @@ func WithSocksURL(val string) Option {
 	})
 }
 
+// WithProxyAuth returns a new Option that sets the credentials of the HTTP or SOCKS5 proxy,
+// the ones of the proxy URL take precedence.
+func WithProxyAuth(username, password string) Option {
+	return optionFunc(func(c *config) {
+		c.proxyUsername = username
+		c.proxyPassword = password
+	})
+}
+
 // WithBaseURL returns a new Option that sets the base URL for the client configuration.
 // It takes a string value representing the base URL to use for requests.
 // It returns an optionFunc that sets the baseURL field of the configuration to the provided
@@ -95,6 +123,32 @@ func WithTimeout(val time.Duration) Option {
 	})
 }
 
+// WithConnectTimeout returns a new Option that sets the timeout of the TCP connection and
+// of the TLS handshake, the timeout of the whole request is set with WithTimeout.
+func WithConnectTimeout(val time.Duration) Option {
+	return optionFunc(func(c *config) {
+		c.connectTimeout = val
+	})
+}
+
+// WithRateLimit returns a new Option that limits the requests and the estimated tokens
+// sent per minute, the requests over the limits wait. Zero disables a limit.
+func WithRateLimit(requestsPerMinute, tokensPerMinute int) Option {
+	return optionFunc(func(c *config) {
+		c.requestsPerMinute = requestsPerMinute
+		c.tokensPerMinute = tokensPerMinute
+	})
+}
+
+// WithRateLimitFile returns a new Option that keeps the window of the rate limits in the
+// file, shared by the processes using it, like the commands run by a script or a batch.
+// Without file, the window is the one of the process.
+func WithRateLimitFile(val string) Option {
+	return optionFunc(func(c *config) {
+		c.rateLimitFile = val
+	})
+}
+
 // WithMaxTokens returns a new Option that sets the max tokens for the client configuration.
 // The maximum number of tokens to generate in the chat completion.
 // The total length of input tokens and generated tokens is limited by the model's context length.
@@ -120,13 +174,75 @@ func WithTemperature(val float32) Option {
 	})
 }
 
+// WithTopP returns a new Option that sets the nucleus sampling for the client configuration.
+// The model considers the tokens comprising the top_p probability mass, between 0 and 1,
+// so 0.1 means only the tokens comprising the top 10% probability mass are considered.
+func WithTopP(val float32) Option {
+	if val <= 0 || val > 1 {
+		val = defaultTopP
+	}
+	return optionFunc(func(c *config) {
+		c.topP = val
+	})
+}
+
+// WithFrequencyPenalty returns a new Option that sets the frequency penalty, between -2 and 2.
+// Positive values penalize the tokens based on their frequency in the text so far,
+// decreasing the likelihood to repeat the same line verbatim.
+func WithFrequencyPenalty(val float32) Option {
+	return optionFunc(func(c *config) {
+		c.frequencyPenalty = val
+	})
+}
+
+// WithPresencePenalty returns a new Option that sets the presence penalty, between -2 and 2.
+// Positive values penalize the tokens that already appear in the text so far,
+// increasing the likelihood to talk about new topics.
+func WithPresencePenalty(val float32) Option {
+	return optionFunc(func(c *config) {
+		c.presencePenalty = val
+	})
+}
+
+// WithSeed returns a new Option that sets the seed of the chat completions, the
+// repeated requests with the same seed and parameters should return the same result.
+func WithSeed(val int) Option {
+	return optionFunc(func(c *config) {
+		c.seed = &val
+	})
+}
+
+// WithStop returns a new Option that sets the sequences where the model stops generating,
+// they are not part of the completion.
+func WithStop(val []string) Option {
+	return optionFunc(func(c *config) {
+		c.stop = val
+	})
+}
+
+// WithStream returns a new Option that streams the completions of the text-generation-inference
+// provider token by token, instead of waiting for the whole text.
+func WithStream(val bool) Option {
+	return optionFunc(func(c *config) {
+		c.stream = val
+	})
+}
+
+// WithEmbeddingModel returns a new Option that sets the model of the embeddings,
+// text-embedding-3-small by default and mistral-embed with Mistral.
+func WithEmbeddingModel(val string) Option {
+	return optionFunc(func(c *config) {
+		c.embeddingModel = val
+	})
+}
+
 // WithProvider sets the `provider` variable based on the value of the `val` parameter.
-// If `val` is not set to `OPENAI` or `AZURE`, it will be set to the default value `defaultProvider`.
+// If `val` is not set to `OPENAI`, `AZURE`, `MOCK`, `BEDROCK`, `COMPATIBLE`, `MISTRAL` or `HUGGINGFACE`, it will be set to the default value `defaultProvider`.
 // This function returns an `Option` object.
 func WithProvider(val string) Option {
-	// Check if `val` is set to `OPENAI` or `AZURE`. If not, set it to the default value.
+	// Check if `val` is set to a known provider. If not, set it to the default value.
 	switch val {
-	case OPENAI, AZURE:
+	case OPENAI, AZURE, MOCK, BEDROCK, COMPATIBLE, MISTRAL, HUGGINGFACE:
 	default:
 		val = defaultProvider
 	}
@@ -146,6 +262,30 @@ func WithModelName(val string) Option {
 	})
 }
 
+// WithAzureDeployments returns a new Option that maps the models to the Azure deployment
+// names, like gpt-4 to my-gpt-4. The models without deployment use the model name.
+func WithAzureDeployments(val map[string]string) Option {
+	return optionFunc(func(c *config) {
+		c.azureDeployments = val
+	})
+}
+
+// WithAWSRegion returns a new Option that sets the AWS region of the Bedrock provider,
+// the region of the AWS config or the AWS_REGION environment variable is used otherwise.
+func WithAWSRegion(val string) Option {
+	return optionFunc(func(c *config) {
+		c.awsRegion = val
+	})
+}
+
+// WithAWSProfile returns a new Option that sets the profile of the shared AWS config
+// and credentials files used by the Bedrock provider.
+func WithAWSProfile(val string) Option {
+	return optionFunc(func(c *config) {
+		c.awsProfile = val
+	})
+}
+
 // WithSkipVerify returns a new Option that sets the skipVerify for the client configuration.
 func WithSkipVerify(val bool) Option {
 	return optionFunc(func(c *config) {
@@ -153,6 +293,24 @@ func WithSkipVerify(val bool) Option {
 	})
 }
 
+// WithCA returns a new Option that trusts the CA certificates of the PEM file and of the
+// .pem, .crt and .cer files of the directory, on top of the system ones.
+func WithCA(file, dir string) Option {
+	return optionFunc(func(c *config) {
+		c.caFile = file
+		c.caDir = dir
+	})
+}
+
+// WithClientCert returns a new Option that sets the PEM client certificate and its key
+// presented to the gateways requiring mTLS.
+func WithClientCert(certFile, keyFile string) Option {
+	return optionFunc(func(c *config) {
+		c.certFile = certFile
+		c.keyFile = keyFile
+	})
+}
+
 // WithHeaders returns a new Option that sets the headers for the http client configuration.
 func WithHeaders(headers []string) Option {
 	return optionFunc(func(c *config) {
@@ -167,6 +325,68 @@ func WithApiVersion(apiVersion string) Option {
 	})
 }
 
+// WithCassette returns a new Option that records the HTTP interactions to the cassette file
+// or replays them from it, depending on the mode: record or replay.
+func WithCassette(mode, path string) Option {
+	return optionFunc(func(c *config) {
+		c.cassetteMode = mode
+		c.cassetteFile = path
+	})
+}
+
+// WithPrivacy returns a new Option that refuses the requests to the hosts outside of the
+// policy, the proxies included. A nil policy allows every host.
+func WithPrivacy(val *privacy.Policy) Option {
+	return optionFunc(func(c *config) {
+		c.privacy = val
+	})
+}
+
+// WithMockResponse returns a new Option that sets the response template of the mock provider.
+// The template gets the .Prompt and .Model fields and the contains and hasPrefix functions.
+func WithMockResponse(val string) Option {
+	return optionFunc(func(c *config) {
+		c.mockResponse = val
+	})
+}
+
+// WithSystemPrompt returns a new Option that sets the system message sent before every prompt.
+func WithSystemPrompt(val string) Option {
+	return optionFunc(func(c *config) {
+		c.systemPrompt = val
+	})
+}
+
+// WithAzureAuth returns a new Option that sets the authentication method of the Azure provider:
+// api_key, client_secret, managed_identity or default.
+func WithAzureAuth(val string) Option {
+	if val == "" {
+		val = AzureAuthKey
+	}
+	return optionFunc(func(c *config) {
+		c.azureAuth = val
+	})
+}
+
+// WithModeration returns a new Option that runs every prompt through the moderation endpoint
+// before sending it, the flagged prompts are blocked or only warned about. Empty disables it.
+func WithModeration(action string) Option {
+	return optionFunc(func(c *config) {
+		c.moderation = action
+	})
+}
+
+// WithAzureCredentials returns a new Option that sets the Microsoft Entra ID tenant,
+// the client ID of the service principal or the user-assigned managed identity,
+// and the client secret of the service principal.
+func WithAzureCredentials(tenantID, clientID, clientSecret string) Option {
+	return optionFunc(func(c *config) {
+		c.azureTenantID = tenantID
+		c.azureClientID = clientID
+		c.azureClientSecret = clientSecret
+	})
+}
+
 // config is a struct that stores configuration options for the instrumentation.
 type config struct {
 	baseURL     string
@@ -179,35 +399,130 @@ type config struct {
 	maxTokens   int
 	temperature float32
 
+	topP             float32
+	frequencyPenalty float32
+	presencePenalty  float32
+	seed             *int
+	stop             []string
+	stream           bool
+	embeddingModel   string
+	moderation       string
+
 	provider   string
 	modelName  string
 	skipVerify bool
 	headers    []string
 	apiVersion string
+
+	caFile   string
+	caDir    string
+	certFile string
+	keyFile  string
+
+	azureDeployments  map[string]string
+	azureAuth         string
+	azureTenantID     string
+	azureClientID     string
+	azureClientSecret string
+
+	awsRegion  string
+	awsProfile string
+
+	proxyUsername  string
+	proxyPassword  string
+	connectTimeout time.Duration
+
+	requestsPerMinute int
+	tokensPerMinute   int
+	rateLimitFile     string
+
+	cassetteMode string
+	cassetteFile string
+	mockResponse string
+	systemPrompt string
+
+	privacy *privacy.Policy
 }
 
 // valid checks whether a config object is valid, returning an error if it is not.
 func (cfg *config) valid() error {
-	// Check that the token is not empty.
-	if cfg.token == "" {
+	// Check that the token is not empty, the mock, Bedrock, OpenAI-compatible and Hugging Face
+	// providers, replaying a cassette and the Microsoft Entra ID authentication of Azure don't need it.
+	// The self-hosted servers, like LM Studio or text-generation-inference, have no API key.
+	if cfg.token == "" && !cfg.keyless() &&
+		!(cfg.cassetteFile != "" && cfg.cassetteMode == cassette.ModeReplay) {
 		return errorsMissingToken
 	}
 
-	// Check that the model exists in the model maps.
-	modelExists := modelMaps[cfg.model] != ""
-	if !modelExists {
+	// Check that the model is set, the provider validates the name.
+	if cfg.model == "" {
 		return errorsMissingModel
 	}
 
+	// Check that the penalties are in the range of the API.
+	if cfg.frequencyPenalty < -2 || cfg.frequencyPenalty > 2 ||
+		cfg.presencePenalty < -2 || cfg.presencePenalty > 2 {
+		return errorsInvalidPenalty
+	}
+
+	// The OpenAI-compatible and Hugging Face providers have no default endpoint.
+	if (cfg.provider == COMPATIBLE || cfg.provider == HUGGINGFACE) && cfg.baseURL == "" {
+		return errorsMissingBaseURL
+	}
+
 	// If the provider is Azure, check that the model name is not empty.
-	if cfg.provider == AZURE && cfg.modelName == "" {
+	if cfg.provider == AZURE && cfg.deployment(cfg.model) == "" {
 		return errorsMissingAzureModel
 	}
 
+	// If the provider is Azure, check the authentication method and its credentials.
+	if cfg.provider == AZURE {
+		if !IsAzureAuth(cfg.azureAuth) {
+			return errorsInvalidAzureAuth
+		}
+		if cfg.azureAuth == AzureAuthClientSecret &&
+			(cfg.azureTenantID == "" || cfg.azureClientID == "" || cfg.azureClientSecret == "") {
+			return errorsMissingAzureSecret
+		}
+	}
+
+	// The moderation endpoint is only part of the OpenAI API.
+	if cfg.moderation != "" {
+		if !IsModerationAction(cfg.moderation) {
+			return errorsInvalidModeration
+		}
+		if cfg.provider != OPENAI && cfg.provider != MOCK {
+			return errorsModerationUnsupported
+		}
+	}
+
 	// If all checks pass, return nil (no error).
 	return nil
 }
 
+// deployment returns the Azure deployment name of the model, or the model name
+// if the model has no deployment.
+func (cfg *config) deployment(model string) string {
+	if name := cfg.azureDeployments[strings.ToLower(model)]; name != "" {
+		return name
+	}
+	return cfg.modelName
+}
+
+// keyless reports whether the provider works without the token.
+func (cfg *config) keyless() bool {
+	switch cfg.provider {
+	case MOCK, BEDROCK, COMPATIBLE, HUGGINGFACE:
+		return true
+	}
+	return cfg.azureAD()
+}
+
+// azureAD reports whether the Azure provider authenticates with Microsoft Entra ID tokens.
+func (cfg *config) azureAD() bool {
+	return cfg.provider == AZURE && cfg.azureAuth != AzureAuthKey
+}
+
 // newConfig creates a new config object with default values, and applies the given options.
 func newConfig(opts ...Option) *config {
 	// Create a new config object with default values.
@@ -215,7 +530,9 @@ func newConfig(opts ...Option) *config {
 		model:       defaultModel,
 		maxTokens:   defaultMaxTokens,
 		temperature: defaultTemperature,
+		topP:        defaultTopP,
 		provider:    defaultProvider,
+		azureAuth:   AzureAuthKey,
 	}
 
 	// Apply each of the given options to the config object.
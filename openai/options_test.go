@@ -30,7 +30,7 @@ func Test_config_valid(t *testing.T) {
 			name: "missing model",
 			cfg: newConfig(
 				WithToken("test"),
-				WithModel("test"),
+				WithModel(""),
 				WithProvider(OPENAI),
 			),
 			wantErr: errorsMissingModel,
@@ -44,6 +44,79 @@ func Test_config_valid(t *testing.T) {
 			),
 			wantErr: errorsMissingAzureModel,
 		},
+		{
+			name: "OpenAI-compatible endpoint without token",
+			cfg: newConfig(
+				WithModel("llama-3.1-8b-instant"),
+				WithProvider(COMPATIBLE),
+				WithBaseURL("http://localhost:1234/v1"),
+			),
+			wantErr: nil,
+		},
+		{
+			name: "missing OpenAI-compatible base URL",
+			cfg: newConfig(
+				WithToken("test"),
+				WithModel("llama-3.1-8b-instant"),
+				WithProvider(COMPATIBLE),
+			),
+			wantErr: errorsMissingBaseURL,
+		},
+		{
+			name: "Azure deployment of the model",
+			cfg: newConfig(
+				WithToken("test"),
+				WithModel(openai.GPT4),
+				WithProvider(AZURE),
+				WithAzureDeployments(map[string]string{"gpt-4": "my-gpt-4"}),
+			),
+			wantErr: nil,
+		},
+		{
+			name: "Azure managed identity without token",
+			cfg: newConfig(
+				WithModelName("gpt-35-turbo"),
+				WithProvider(AZURE),
+				WithAzureAuth(AzureAuthManagedIdentity),
+			),
+			wantErr: nil,
+		},
+		{
+			name: "invalid Azure authentication",
+			cfg: newConfig(
+				WithModelName("gpt-35-turbo"),
+				WithProvider(AZURE),
+				WithAzureAuth("password"),
+			),
+			wantErr: errorsInvalidAzureAuth,
+		},
+		{
+			name: "missing Azure client secret",
+			cfg: newConfig(
+				WithModelName("gpt-35-turbo"),
+				WithProvider(AZURE),
+				WithAzureAuth(AzureAuthClientSecret),
+				WithAzureCredentials("tenant", "client", ""),
+			),
+			wantErr: errorsMissingAzureSecret,
+		},
+		{
+			name: "invalid moderation action",
+			cfg: newConfig(
+				WithToken("test"),
+				WithModeration("drop"),
+			),
+			wantErr: errorsInvalidModeration,
+		},
+		{
+			name: "moderation without the OpenAI API",
+			cfg: newConfig(
+				WithBaseURL("http://localhost:8080"),
+				WithProvider(HUGGINGFACE),
+				WithModeration(ModerationBlock),
+			),
+			wantErr: errorsModerationUnsupported,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -54,3 +127,23 @@ func Test_config_valid(t *testing.T) {
 		})
 	}
 }
+
+func Test_config_deployment(t *testing.T) {
+	cfg := newConfig(
+		WithModelName("my-gpt-35"),
+		WithAzureDeployments(map[string]string{"gpt-4": "my-gpt-4"}),
+	)
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{model: "gpt-4", want: "my-gpt-4"},
+		{model: "GPT-4", want: "my-gpt-4"},
+		{model: "gpt-3.5-turbo", want: "my-gpt-35"},
+	}
+	for _, tt := range tests {
+		if got := cfg.deployment(tt.model); got != tt.want {
+			t.Errorf("deployment(%q) = %q, want %q", tt.model, got, tt.want)
+		}
+	}
+}
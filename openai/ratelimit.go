@@ -0,0 +1,194 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/appleboy/CodeGPT/logger"
+)
+
+const (
+	// rateWindow is the sliding window of the rate limits.
+	rateWindow = time.Minute
+	// lockTimeout is how long a process waits for the lock of the shared window.
+	lockTimeout = 5 * time.Second
+	// staleLock is the age of a lock left by a process which died holding it.
+	staleLock = 10 * time.Second
+)
+
+// limiter is a client-side rate limiter of the requests and the tokens per minute,
+// so the loops of requests stay under the rate limits of the organization.
+// The tokens of a request are estimated from its prompt and its max tokens. With a
+// file, the window is shared by all the processes using the file.
+type limiter struct {
+	requests int
+	tokens   int
+	file     string
+
+	mu     sync.Mutex
+	events []rateEvent
+
+	// replaced in the tests
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// rateEvent is a request sent in the window.
+type rateEvent struct {
+	At     time.Time `json:"at"`
+	Tokens int       `json:"tokens"`
+}
+
+// newLimiter returns the limiter of the requests and tokens per minute, nil without limit.
+// The window is kept in the file when it's set.
+func newLimiter(requests, tokens int, file string) *limiter {
+	if requests <= 0 && tokens <= 0 {
+		return nil
+	}
+	return &limiter{
+		requests: requests,
+		tokens:   tokens,
+		file:     file,
+		now:      time.Now,
+		sleep:    sleepContext,
+	}
+}
+
+// wait blocks until the request of the tokens fits in the limits, or the context is done.
+// A request over the tokens per minute waits for an empty window.
+func (l *limiter) wait(ctx context.Context, tokens int) error {
+	if l == nil {
+		return nil
+	}
+	for {
+		d := l.reserve(tokens)
+		if d <= 0 {
+			return nil
+		}
+		logger.Info("rate limit reached, wait " + d.Round(time.Second).String())
+		if err := l.sleep(ctx, d); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve records the request if it fits in the limits, or returns the time
+// until the oldest request of the window expires.
+func (l *limiter) reserve(tokens int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// the shared window is read and written under the lock of the file, the window
+	// of the process is used when the file can't be locked
+	if l.file != "" {
+		unlock, err := lockFile(l.file + ".lock")
+		if err != nil {
+			logger.Debug("can't lock the rate limit window: " + err.Error())
+		} else {
+			defer unlock()
+			l.events = readEvents(l.file)
+			defer func() {
+				if err := writeEvents(l.file, l.events); err != nil {
+					logger.Debug("can't write the rate limit window: " + err.Error())
+				}
+			}()
+		}
+	}
+
+	now := l.now()
+	used := 0
+	kept := l.events[:0]
+	for _, e := range l.events {
+		if now.Sub(e.At) < rateWindow {
+			kept = append(kept, e)
+			used += e.Tokens
+		}
+	}
+	l.events = kept
+
+	fits := len(l.events) == 0 ||
+		((l.requests <= 0 || len(l.events) < l.requests) &&
+			(l.tokens <= 0 || used+tokens <= l.tokens))
+	if fits {
+		l.events = append(l.events, rateEvent{At: now, Tokens: tokens})
+		return 0
+	}
+	return l.events[0].At.Add(rateWindow).Sub(now)
+}
+
+// lockFile creates the lock file, waiting for the other processes holding it. A lock
+// older than staleLock is removed. The returned function releases the lock.
+func lockFile(name string) (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err == nil {
+			f.Close()
+			return func() { _ = os.Remove(name) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if info, err := os.Stat(name); err == nil && time.Since(info.ModTime()) > staleLock {
+			_ = os.Remove(name)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.New("timeout waiting for " + name)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// readEvents returns the requests of the shared window, none when the file is missing
+// or broken.
+func readEvents(name string) []rateEvent {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil
+	}
+	var events []rateEvent
+	if err := json.Unmarshal(data, &events); err != nil {
+		return nil
+	}
+	return events
+}
+
+// writeEvents replaces the shared window with the requests.
+func writeEvents(name string, events []rateEvent) error {
+	data, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	tmp := name + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+// sleepContext waits for the duration or until the context is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// throttle waits for the rate limits before sending the n completions of the prompt.
+func (c *Client) throttle(ctx context.Context, content string, n int) error {
+	tokens := (EstimateTokens(c.system+content) + c.maxTokens) * max(n, 1)
+	return c.limiter.wait(ctx, tokens)
+}
@@ -0,0 +1,91 @@
+package openai
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeLimiter returns the limiter with a fake clock moved forward by its sleeps.
+func fakeLimiter(requests, tokens int) (*limiter, *time.Duration) {
+	l := newLimiter(requests, tokens, "")
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var waited time.Duration
+	l.now = func() time.Time { return start.Add(waited) }
+	l.sleep = func(_ context.Context, d time.Duration) error {
+		waited += d
+		return nil
+	}
+	return l, &waited
+}
+
+func TestLimiter(t *testing.T) {
+	tests := []struct {
+		name     string
+		requests int
+		tokens   int
+		calls    []int
+		want     time.Duration
+	}{
+		{name: "under the limits", requests: 3, tokens: 1000, calls: []int{100, 100, 100}},
+		{name: "requests per minute", requests: 2, calls: []int{1, 1, 1}, want: time.Minute},
+		{name: "tokens per minute", tokens: 1000, calls: []int{600, 300, 200}, want: time.Minute},
+		{name: "request over the tokens per minute", tokens: 1000, calls: []int{1500, 10}, want: time.Minute},
+		{name: "window slides", requests: 2, calls: []int{1, 1, 1, 1, 1}, want: 2 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, waited := fakeLimiter(tt.requests, tt.tokens)
+			for _, tokens := range tt.calls {
+				if err := l.wait(context.Background(), tokens); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if *waited != tt.want {
+				t.Errorf("waited %s, want %s", *waited, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimiterCanceled(t *testing.T) {
+	l := newLimiter(1, 0, "")
+	if err := l.wait(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.wait(ctx, 1); err != context.Canceled {
+		t.Errorf("wait() error = %v, want context canceled", err)
+	}
+	if newLimiter(0, 0, "") != nil {
+		t.Error("newLimiter() without limit should be nil")
+	}
+}
+
+func TestLimiterSharedFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "ratelimit.json")
+	// two processes with the same limit of two requests per minute
+	first, _ := fakeLimiter(2, 0)
+	first.file = file
+	second, waited := fakeLimiter(2, 0)
+	second.file = file
+
+	if d := first.reserve(1); d != 0 {
+		t.Fatalf("reserve() = %s, want 0", d)
+	}
+	if d := first.reserve(1); d != 0 {
+		t.Fatalf("reserve() = %s, want 0", d)
+	}
+	if err := second.wait(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if *waited != time.Minute {
+		t.Errorf("the other process waited %s, want %s", *waited, time.Minute)
+	}
+	if _, err := os.Stat(file + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("the lock is left: %v", err)
+	}
+}
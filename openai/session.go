@@ -0,0 +1,112 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// Session is a conversation with the model: the prompts and the replies of the previous
+// turns are sent with every new prompt, so the model refines its last reply instead of
+// starting over. The chat models get the messages of the turns, the other providers the
+// transcript of the conversation as one prompt.
+type Session struct {
+	client   *Client
+	messages []openai.ChatCompletionMessage
+}
+
+// NewSession returns an empty conversation with the model.
+func (c *Client) NewSession() *Session {
+	return &Session{client: c}
+}
+
+// Add appends a turn of the conversation without sending it, like the prompt and the
+// reply of a completion made before the session.
+func (s *Session) Add(prompt, reply string) {
+	s.add(openai.ChatMessageRoleUser, prompt)
+	s.add(openai.ChatMessageRoleAssistant, reply)
+}
+
+// add appends a message of the role to the conversation.
+func (s *Session) add(role, content string) {
+	s.messages = append(s.messages, openai.ChatCompletionMessage{
+		Role:    role,
+		Content: content,
+	})
+}
+
+// Len returns the number of messages of the conversation.
+func (s *Session) Len() int {
+	return len(s.messages)
+}
+
+// Send sends the prompt after the previous turns of the conversation, the prompt and the
+// reply are added to the session. The session is left as is on error.
+func (s *Session) Send(ctx context.Context, content string) (*Response, error) {
+	s.add(openai.ChatMessageRoleUser, content)
+	resp, err := s.send(ctx, content)
+	if err != nil {
+		s.messages = s.messages[:len(s.messages)-1]
+		return nil, err
+	}
+	s.add(openai.ChatMessageRoleAssistant, resp.Content)
+	return resp, nil
+}
+
+// send requests the completion of the conversation, the prompt is the last message.
+func (s *Session) send(ctx context.Context, content string) (*Response, error) {
+	c := s.client
+	if c.mock != nil || c.bedrock != nil || c.tgi != nil || !c.isChat() {
+		return c.Completion(ctx, s.Transcript())
+	}
+
+	transcript := s.Transcript()
+	c.debugRequest(transcript)
+	if err := c.moderate(ctx, content); err != nil {
+		return nil, err
+	}
+	if err := c.throttle(ctx, transcript, 1); err != nil {
+		return nil, err
+	}
+
+	// the system message goes before the first prompt
+	req := c.chatRequest(s.messages[0].Content)
+	req.Messages = append(req.Messages, s.messages[1:]...)
+	r, err := c.client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.Choices) == 0 {
+		return nil, errors.New("no completion returned")
+	}
+	return &Response{
+		Content: r.Choices[0].Message.Content,
+		Choices: []string{r.Choices[0].Message.Content},
+		Usage:   r.Usage,
+	}, nil
+}
+
+// Transcript returns the conversation as one prompt: the first prompt as is, and the
+// following turns with the role of their author.
+func (s *Session) Transcript() string {
+	var b strings.Builder
+	for i, m := range s.messages {
+		if i == 0 && m.Role == openai.ChatMessageRoleUser {
+			b.WriteString(m.Content)
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		switch m.Role {
+		case openai.ChatMessageRoleAssistant:
+			b.WriteString("ASSISTANT:\n")
+		default:
+			b.WriteString("USER:\n")
+		}
+		b.WriteString(m.Content)
+	}
+	return b.String()
+}
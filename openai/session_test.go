@@ -0,0 +1,81 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+func TestSessionSend(t *testing.T) {
+	var got []openai.ChatCompletionMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req openai.ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		got = req.Messages
+		_ = json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleAssistant,
+				Content: "feat: add the migration",
+			}}},
+		})
+	}))
+	defer srv.Close()
+
+	client, err := New(WithToken("sk-test"), WithModel("gpt-4o"), WithBaseURL(srv.URL), WithSystemPrompt("be brief"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := client.NewSession()
+	s.Add("write the commit message", "feat: update the code")
+	resp, err := s.Send(context.Background(), "mention the migration")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Content != "feat: add the migration" {
+		t.Errorf("Send() = %q", resp.Content)
+	}
+
+	roles := []string{
+		openai.ChatMessageRoleSystem,
+		openai.ChatMessageRoleUser,
+		openai.ChatMessageRoleAssistant,
+		openai.ChatMessageRoleUser,
+	}
+	if len(got) != len(roles) {
+		t.Fatalf("sent %d messages, want %d: %+v", len(got), len(roles), got)
+	}
+	for i, role := range roles {
+		if got[i].Role != role {
+			t.Errorf("message %d role = %q, want %q", i, got[i].Role, role)
+		}
+	}
+	if got[3].Content != "mention the migration" {
+		t.Errorf("last message = %q", got[3].Content)
+	}
+	if s.Len() != 4 {
+		t.Errorf("Len() = %d, want 4 with the reply", s.Len())
+	}
+}
+
+func TestSessionTranscript(t *testing.T) {
+	client, err := New(WithProvider(MOCK), WithMockResponse("{{ .Prompt }}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := client.NewSession()
+	s.Add("write the commit message", "feat: update the code")
+	resp, err := s.Send(context.Background(), "shorter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "write the commit message\n\nASSISTANT:\nfeat: update the code\n\nUSER:\nshorter"
+	if resp.Content != want {
+		t.Errorf("Send() prompt =\n%s\nwant\n%s", resp.Content, want)
+	}
+}
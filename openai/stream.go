@@ -0,0 +1,76 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Stream sends the prompt like Completion, the content is given to the delta function
+// piece by piece while the model generates it. The providers and the models without
+// the streaming chat completions give the whole content at once.
+func (c *Client) Stream(
+	ctx context.Context,
+	content string,
+	delta func(string),
+) (*Response, error) {
+	if c.mock != nil || c.bedrock != nil || c.tgi != nil || !c.isChat() {
+		resp, err := c.Completion(ctx, content)
+		if err != nil {
+			return nil, err
+		}
+		delta(resp.Content)
+		return resp, nil
+	}
+
+	c.debugRequest(content)
+	if err := c.moderate(ctx, content); err != nil {
+		return nil, err
+	}
+	if err := c.throttle(ctx, content, 1); err != nil {
+		return nil, err
+	}
+
+	req := c.chatRequest(content)
+	req.Stream = true
+	// the usage comes with the last chunk, Mistral rejects the unknown fields and sends it anyway
+	if c.provider != MISTRAL {
+		req.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+	}
+	stream, err := c.client.CreateChatCompletionStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	var (
+		b    strings.Builder
+		resp = &Response{}
+	)
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if chunk.Usage != nil {
+			resp.Usage = *chunk.Usage
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+		b.WriteString(chunk.Choices[0].Delta.Content)
+		delta(chunk.Choices[0].Delta.Content)
+	}
+	if b.Len() == 0 {
+		return nil, errors.New("no completion returned")
+	}
+	resp.Content = b.String()
+	resp.Choices = []string{resp.Content}
+	return resp, nil
+}
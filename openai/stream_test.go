@@ -0,0 +1,67 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req["stream"] != true {
+			t.Errorf("stream request = %v, %v", req, err)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, part := range []string{"feat: ", "add the ", "ui command"} {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":%q}}]}\n\n", part)
+		}
+		fmt.Fprint(w, "data: {\"choices\":[],\"usage\":{\"prompt_tokens\":10,\"completion_tokens\":5,\"total_tokens\":15}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer srv.Close()
+
+	client, err := New(
+		WithToken("sk-test"),
+		WithBaseURL(srv.URL+"/v1"),
+		WithModel("gpt-4o-mini"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var parts []string
+	resp, err := client.Stream(context.Background(), "diff", func(s string) {
+		parts = append(parts, s)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parts) != 3 || strings.Join(parts, "") != "feat: add the ui command" {
+		t.Errorf("deltas = %q", parts)
+	}
+	if resp.Content != "feat: add the ui command" || resp.Usage.TotalTokens != 15 {
+		t.Errorf("Stream() = %+v", resp)
+	}
+}
+
+func TestStreamMock(t *testing.T) {
+	client, err := New(WithProvider(MOCK), WithModel("gpt-4o-mini"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got string
+	resp, err := client.Stream(context.Background(), "diff", func(s string) {
+		got += s
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == "" || got != resp.Content {
+		t.Errorf("delta = %q, content = %q", got, resp.Content)
+	}
+}
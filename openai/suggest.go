@@ -0,0 +1,75 @@
+package openai
+
+import (
+	"sort"
+	"strings"
+)
+
+// Suggest returns up to n model names close to the given name, the closest first.
+// The models containing the name, like gpt-3.5-turbo for gpt-3.5, come before the
+// models a few edits away from it, like gpt-4-turbo for gpt-4-trubo.
+func Suggest(name string, models []string, n int) []string {
+	type match struct {
+		model    string
+		contains bool
+		distance int
+	}
+
+	name = strings.ToLower(name)
+	limit := len(name)/3 + 1
+	var matches []match
+	for _, m := range models {
+		d := distance(name, strings.ToLower(m))
+		contains := strings.Contains(strings.ToLower(m), name)
+		if d > limit && !contains {
+			continue
+		}
+		matches = append(matches, match{m, contains, d})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].contains != matches[j].contains {
+			return matches[i].contains
+		}
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].model < matches[j].model
+	})
+
+	var out []string
+	for i := 0; i < len(matches) && i < n; i++ {
+		out = append(out, matches[i].model)
+	}
+	return out
+}
+
+// distance returns the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
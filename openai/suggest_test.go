@@ -0,0 +1,25 @@
+package openai
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSuggest(t *testing.T) {
+	models := []string{"gpt-3.5-turbo", "gpt-3.5-turbo-0125", "gpt-4", "gpt-4-turbo", "dall-e-3"}
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"gpt-4-trubo", []string{"gpt-4-turbo"}},
+		{"gpt4", []string{"gpt-4"}},
+		{"gpt-3.5", []string{"gpt-3.5-turbo", "gpt-3.5-turbo-0125", "gpt-4"}},
+		{"whisper", nil},
+	}
+	for _, tt := range tests {
+		if got := Suggest(tt.name, models, 3); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Suggest(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// tgi is the text-generation-inference provider of Hugging Face, the Inference
+// Endpoints or a self-hosted server. The prompt is sent as is, without chat template.
+// https://huggingface.github.io/text-generation-inference/
+type tgi struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+	stream     bool
+}
+
+// tgiParameters are the generation parameters of the TGI request.
+type tgiParameters struct {
+	MaxNewTokens   int      `json:"max_new_tokens"`
+	Temperature    float32  `json:"temperature,omitempty"`
+	TopP           float32  `json:"top_p,omitempty"`
+	Stop           []string `json:"stop,omitempty"`
+	Seed           *int     `json:"seed,omitempty"`
+	ReturnFullText bool     `json:"return_full_text"`
+	Details        bool     `json:"details"`
+}
+
+// tgiRequest is the request of the generate and generate_stream routes.
+type tgiRequest struct {
+	Inputs     string        `json:"inputs"`
+	Parameters tgiParameters `json:"parameters"`
+}
+
+// tgiDetails are the details of the generation.
+type tgiDetails struct {
+	FinishReason    string `json:"finish_reason"`
+	GeneratedTokens int    `json:"generated_tokens"`
+}
+
+// tgiResponse is the response of the generate route, and the last event of the
+// generate_stream route which also holds the whole generated text.
+type tgiResponse struct {
+	GeneratedText *string     `json:"generated_text"`
+	Details       *tgiDetails `json:"details"`
+	Token         struct {
+		Text    string `json:"text"`
+		Special bool   `json:"special"`
+	} `json:"token"`
+	Error string `json:"error"`
+}
+
+// route returns the URL of the generate route, the streaming one if enabled.
+func (t *tgi) route() string {
+	route := "/generate"
+	if t.stream {
+		route = "/generate_stream"
+	}
+	return strings.TrimRight(t.baseURL, "/") + route
+}
+
+// generate sends the request and returns the generated text and the number of generated tokens.
+func (t *tgi) generate(ctx context.Context, body tgiRequest) (string, int, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.route(), bytes.NewReader(data))
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if t.token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var r tgiResponse
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if json.Unmarshal(msg, &r) == nil && r.Error != "" {
+			msg = []byte(r.Error)
+		}
+		return "", 0, fmt.Errorf("text-generation-inference error, status code: %d, message: %s", resp.StatusCode, msg)
+	}
+
+	if t.stream {
+		return readStream(resp.Body)
+	}
+
+	var r tgiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return "", 0, err
+	}
+	if r.GeneratedText == nil {
+		return "", 0, errors.New("no completion returned")
+	}
+	tokens := 0
+	if r.Details != nil {
+		tokens = r.Details.GeneratedTokens
+	}
+	return *r.GeneratedText, tokens, nil
+}
+
+// readStream reads the server-sent events of the generate_stream route. Every event
+// holds a token, the last one also the generated text and the details.
+func readStream(body io.Reader) (string, int, error) {
+	var (
+		text   strings.Builder
+		tokens int
+	)
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+
+		var event tgiResponse
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &event); err != nil {
+			return "", 0, err
+		}
+		if event.Error != "" {
+			return "", 0, errors.New("text-generation-inference error: " + event.Error)
+		}
+		if event.GeneratedText != nil {
+			if event.Details != nil {
+				tokens = event.Details.GeneratedTokens
+			}
+			return *event.GeneratedText, tokens, nil
+		}
+		if !event.Token.Special {
+			text.WriteString(event.Token.Text)
+		}
+		tokens++
+	}
+	if err := scanner.Err(); err != nil {
+		return "", 0, err
+	}
+	// the stream ended without the final event
+	return text.String(), tokens, nil
+}
+
+// tgiCompletion requests n completions of the prompt, one call each.
+// The prompt tokens are estimated, TGI only counts the generated ones.
+func (c *Client) tgiCompletion(ctx context.Context, content string, n int) (*Response, error) {
+	// no roles without chat template, the system message leads the prompt
+	if c.system != "" {
+		content = c.system + "\n\n" + content
+	}
+	body := tgiRequest{
+		Inputs: content,
+		Parameters: tgiParameters{
+			MaxNewTokens: c.maxTokens,
+			Temperature:  c.temperature,
+			TopP:         c.topP,
+			Stop:         c.stop,
+			Seed:         c.seed,
+			Details:      true,
+		},
+	}
+	// TGI requires the top_p in the open interval
+	if body.Parameters.TopP >= 1 {
+		body.Parameters.TopP = 0
+	}
+
+	resp := &Response{}
+	for i := 0; i < max(n, 1); i++ {
+		text, tokens, err := c.tgi.generate(ctx, body)
+		if err != nil {
+			return nil, err
+		}
+		resp.Choices = append(resp.Choices, trimStop(text, c.stop))
+		resp.Usage.PromptTokens += EstimateTokens(content)
+		resp.Usage.CompletionTokens += tokens
+	}
+	resp.Usage.TotalTokens = resp.Usage.PromptTokens + resp.Usage.CompletionTokens
+	resp.Content = resp.Choices[0]
+	return resp, nil
+}
+
+// trimStop removes the stop sequence ending the text, TGI keeps it.
+func trimStop(text string, stop []string) string {
+	for _, s := range stop {
+		if s != "" && strings.HasSuffix(text, s) {
+			return strings.TrimSuffix(text, s)
+		}
+	}
+	return text
+}
+
+// tgiModels returns the model served by the endpoint from its info route.
+func (c *Client) tgiModels(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(c.tgi.baseURL, "/")+"/info", nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.tgi.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.tgi.token)
+	}
+	resp, err := c.tgi.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("text-generation-inference error, status code: %d", resp.StatusCode)
+	}
+
+	var info struct {
+		ModelID string `json:"model_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, err
+	}
+	return []string{info.ModelID}, nil
+}
@@ -0,0 +1,105 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTGIServer(t *testing.T, requests *[]tgiRequest) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/info" {
+			fmt.Fprint(w, `{"model_id":"bigcode/starcoder2-15b"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer hf_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"Authorization header is invalid"}`)
+			return
+		}
+
+		var req tgiRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		*requests = append(*requests, req)
+
+		switch r.URL.Path {
+		case "/generate":
+			fmt.Fprint(w, `{"generated_text":"feat: add tgi\n###","details":{"finish_reason":"stop_sequence","generated_tokens":5}}`)
+		case "/generate_stream":
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data:{\"token\":{\"text\":\"feat\",\"special\":false}}\n\n")
+			fmt.Fprint(w, "data:{\"token\":{\"text\":\": add\",\"special\":false}}\n\n")
+			fmt.Fprint(w, "data:{\"token\":{\"text\":\"</s>\",\"special\":true},\"generated_text\":\"feat: add\",\"details\":{\"finish_reason\":\"eos_token\",\"generated_tokens\":3}}\n\n")
+		}
+	}))
+}
+
+func TestTGICompletion(t *testing.T) {
+	var requests []tgiRequest
+	srv := newTGIServer(t, &requests)
+	defer srv.Close()
+
+	tests := []struct {
+		name       string
+		stream     bool
+		want       string
+		wantTokens int
+	}{
+		{name: "generate", want: "feat: add tgi\n", wantTokens: 5},
+		{name: "generate_stream", stream: true, want: "feat: add", wantTokens: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := New(
+				WithProvider(HUGGINGFACE),
+				WithBaseURL(srv.URL),
+				WithToken("hf_test"),
+				WithStop([]string{"###"}),
+				WithStream(tt.stream),
+				WithSystemPrompt("be brief"),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Completion(context.Background(), "diff")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Content != tt.want {
+				t.Errorf("Content = %q, want %q", resp.Content, tt.want)
+			}
+			if resp.Usage.CompletionTokens != tt.wantTokens {
+				t.Errorf("CompletionTokens = %d, want %d", resp.Usage.CompletionTokens, tt.wantTokens)
+			}
+
+			req := requests[len(requests)-1]
+			if req.Inputs != "be brief\n\ndiff" || req.Parameters.Stop[0] != "###" || req.Parameters.TopP != 0 {
+				t.Errorf("unexpected request %+v", req)
+			}
+		})
+	}
+}
+
+func TestTGIError(t *testing.T) {
+	var requests []tgiRequest
+	srv := newTGIServer(t, &requests)
+	defer srv.Close()
+
+	client, err := New(WithProvider(HUGGINGFACE), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Completion(context.Background(), "diff"); err == nil {
+		t.Error("expected the authorization error")
+	}
+
+	models, err := client.Models(context.Background())
+	if err != nil || len(models) != 1 || models[0] != "bigcode/starcoder2-15b" {
+		t.Errorf("Models() = %v, %v", models, err)
+	}
+}
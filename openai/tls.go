@@ -0,0 +1,88 @@
+package openai
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	errorsMissingClientKey = errors.New("the client certificate and key must be set together")
+	errorsInvalidCA        = errors.New("no PEM certificate found")
+)
+
+// tlsConfig returns the TLS configuration of the connections: the extra CA certificates
+// trusted with the system ones, like the root of a TLS-intercepting proxy, and the client
+// certificate of the mTLS-protected gateways. It's nil without any of them.
+func tlsConfig(cfg *config) (*tls.Config, error) {
+	if !cfg.skipVerify && cfg.caFile == "" && cfg.caDir == "" && cfg.certFile == "" && cfg.keyFile == "" {
+		return nil, nil
+	}
+	out := &tls.Config{InsecureSkipVerify: cfg.skipVerify}
+
+	if cfg.caFile != "" || cfg.caDir != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if cfg.caFile != "" {
+			if err := appendCert(pool, cfg.caFile); err != nil {
+				return nil, err
+			}
+		}
+		if cfg.caDir != "" {
+			entries, err := os.ReadDir(cfg.caDir)
+			if err != nil {
+				return nil, fmt.Errorf("can't read the CA directory: %w", err)
+			}
+			for _, e := range entries {
+				if e.IsDir() || !isCertFile(e.Name()) {
+					continue
+				}
+				if err := appendCert(pool, filepath.Join(cfg.caDir, e.Name())); err != nil {
+					return nil, err
+				}
+			}
+		}
+		out.RootCAs = pool
+	}
+
+	if cfg.certFile != "" || cfg.keyFile != "" {
+		if cfg.certFile == "" || cfg.keyFile == "" {
+			return nil, errorsMissingClientKey
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.certFile, cfg.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't load the client certificate: %w", err)
+		}
+		out.Certificates = []tls.Certificate{cert}
+	}
+
+	return out, nil
+}
+
+// appendCert adds the PEM certificates of the file to the pool.
+func appendCert(pool *x509.CertPool, name string) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("can't read the CA certificate: %w", err)
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return fmt.Errorf("%s: %w", name, errorsInvalidCA)
+	}
+	return nil
+}
+
+// isCertFile reports whether the file of the CA directory holds certificates,
+// from its extension like the c_rehash tool.
+func isCertFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".pem", ".crt", ".cer":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package openai
+
+import (
+	"strings"
+	"unicode"
+)
+
+// EstimateTokens returns a rough estimate of the number of tokens of the text.
+// English text averages about four characters per token, while CJK characters
+// and punctuation usually count as one token each.
+func EstimateTokens(text string) int {
+	var other, wide int
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			wide++
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			wide++
+		default:
+			other++
+		}
+	}
+	if other == 0 && wide == 0 {
+		return 0
+	}
+
+	// never estimate less than the number of words
+	estimate := wide + (other+3)/4
+	if words := len(strings.Fields(text)); estimate < words {
+		estimate = words
+	}
+	return estimate
+}
@@ -0,0 +1,91 @@
+package platform
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errorsInvalidPrivateKey = errors.New("the private key of the GitHub App must be a PEM encoded RSA key")
+
+// App is a GitHub App, authenticated with its ID and private key, which creates the
+// tokens of its installations.
+type App struct {
+	id  int64
+	key *rsa.PrivateKey
+	cfg *config
+}
+
+// NewApp creates the GitHub App of the ID and the PEM encoded private key, downloaded
+// from the settings of the app. Only the base URL and the HTTP client options are used.
+func NewApp(id int64, privateKey []byte, opts ...Option) (*App, error) {
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return nil, errorsInvalidPrivateKey
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, errorsInvalidPrivateKey
+		}
+		var ok bool
+		if key, ok = parsed.(*rsa.PrivateKey); !ok {
+			return nil, errorsInvalidPrivateKey
+		}
+	}
+
+	return &App{id: id, key: key, cfg: newConfig(defaultGitHubURL, opts...)}, nil
+}
+
+// jwt returns the JSON Web Token authenticating the app, valid for 10 minutes.
+// https://docs.github.com/en/apps/creating-github-apps/authenticating-with-a-github-app/generating-a-json-web-token-jwt-for-a-github-app
+func (a *App) jwt(now time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]int64{
+		// the clock of GitHub may be behind
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": a.id,
+	})
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+
+	hash := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, a.key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + enc.EncodeToString(sig), nil
+}
+
+// InstallationToken creates a token of the installation of the app, valid for an hour.
+// https://docs.github.com/en/rest/apps/apps#create-an-installation-access-token-for-an-app
+func (a *App) InstallationToken(ctx context.Context, installation int64) (string, error) {
+	token, err := a.jwt(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	// the JWT is sent like the token of the API client
+	g := &GitHub{cfg: &config{token: token, baseURL: a.cfg.baseURL, httpClient: a.cfg.httpClient}}
+	var out struct {
+		Token string `json:"token"`
+	}
+	url := strings.TrimRight(a.cfg.baseURL, "/") + "/app/installations/" + strconv.FormatInt(installation, 10) + "/access_tokens"
+	if err := g.do(ctx, http.MethodPost, url, nil, &out); err != nil {
+		return "", err
+	}
+	return out.Token, nil
+}
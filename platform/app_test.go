@@ -0,0 +1,69 @@
+package platform
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppInstallationToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/app/installations/42/access_tokens" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+
+		jwt, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		parts := strings.Split(jwt, ".")
+		if len(parts) != 3 {
+			t.Fatalf("invalid JWT: %s", jwt)
+		}
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, hash[:], sig); err != nil {
+			t.Errorf("invalid JWT signature: %v", err)
+		}
+		var claims map[string]int64
+		data, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		_ = json.Unmarshal(data, &claims)
+		if claims["iss"] != 123 || claims["exp"] <= time.Now().Unix() || claims["iat"] > time.Now().Unix() {
+			t.Errorf("unexpected JWT claims: %v", claims)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"token": "ghs_installation"}`))
+	}))
+	defer srv.Close()
+
+	app, err := NewApp(123, privateKey, WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := app.InstallationToken(context.Background(), 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "ghs_installation" {
+		t.Errorf("InstallationToken() = %s, want ghs_installation", token)
+	}
+
+	if _, err := NewApp(123, []byte("not a key")); err != errorsInvalidPrivateKey {
+		t.Errorf("NewApp() error = %v, want %v", err, errorsInvalidPrivateKey)
+	}
+}
@@ -0,0 +1,84 @@
+package platform
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const defaultBitbucketURL = "https://api.bitbucket.org/2.0"
+
+// Bitbucket posts reviews to the pull requests of Bitbucket Cloud.
+type Bitbucket struct {
+	cfg *config
+}
+
+// NewBitbucket creates a new Bitbucket platform with the given options. The repository
+// is in the workspace/slug format. The token is an access token, or an app password
+// when the username is set.
+func NewBitbucket(opts ...Option) (*Bitbucket, error) {
+	cfg := newConfig(defaultBitbucketURL, opts...)
+	if cfg.token == "" {
+		return nil, errorsMissingToken
+	}
+	if !validRepo(cfg.repo) {
+		return nil, errorsInvalidRepo
+	}
+
+	return &Bitbucket{cfg: cfg}, nil
+}
+
+// bitbucketComment is the request body of the create comment API.
+type bitbucketComment struct {
+	Content struct {
+		Raw string `json:"raw"`
+	} `json:"content"`
+	Inline *bitbucketInline `json:"inline,omitempty"`
+}
+
+type bitbucketInline struct {
+	Path string `json:"path"`
+	// To is the line of the new file
+	To int `json:"to"`
+}
+
+// PostReview posts the summary and every inline comment, Bitbucket has no review API
+// to post them in a single request. The comments on several lines are on their last line.
+// https://developer.atlassian.com/cloud/bitbucket/rest/api-group-pullrequests/#api-repositories-workspace-repo-slug-pullrequests-pull-request-id-comments-post
+func (b *Bitbucket) PostReview(ctx context.Context, number int, r Review) error {
+	comments := make([]bitbucketComment, 0, len(r.Comments)+1)
+	summary := bitbucketComment{}
+	summary.Content.Raw = r.Body
+	comments = append(comments, summary)
+	for _, c := range r.Comments {
+		comment := bitbucketComment{Inline: &bitbucketInline{Path: c.Path, To: c.Line}}
+		comment.Content.Raw = c.Body
+		comments = append(comments, comment)
+	}
+
+	url := strings.TrimRight(b.cfg.baseURL, "/") + "/repositories/" + b.cfg.repo + "/pullrequests/" + strconv.Itoa(number) + "/comments"
+	for _, c := range comments {
+		resp, err := send(ctx, b.cfg.httpClient, "bitbucket", http.MethodPost, url, b.header(), c)
+		if err != nil {
+			return err
+		}
+		if err := decode(resp, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// header returns the headers of the API requests.
+func (b *Bitbucket) header() http.Header {
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	if b.cfg.username != "" {
+		header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(b.cfg.username+":"+b.cfg.token)))
+	} else {
+		header.Set("Authorization", "Bearer "+b.cfg.token)
+	}
+	return header
+}
@@ -0,0 +1,62 @@
+package platform
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBitbucketPostReview(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		auth     string
+	}{
+		{"access token", "", "Bearer secret"},
+		// base64 of appleboy:secret
+		{"app password", "appleboy", "Basic YXBwbGVib3k6c2VjcmV0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []bitbucketComment
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/repositories/workspace/repo/pullrequests/7/comments" {
+					t.Errorf("unexpected path: %s", r.URL.Path)
+				}
+				if r.Header.Get("Authorization") != tt.auth {
+					t.Errorf("unexpected authorization header: %s", r.Header.Get("Authorization"))
+				}
+				var c bitbucketComment
+				_ = json.NewDecoder(r.Body).Decode(&c)
+				got = append(got, c)
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer srv.Close()
+
+			b, err := NewBitbucket(WithToken("secret"), WithUsername(tt.username), WithRepo("workspace/repo"), WithBaseURL(srv.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = b.PostReview(context.Background(), 7, Review{
+				Body:     "summary",
+				Comments: []ReviewComment{{Path: "main.go", StartLine: 1, Line: 3, Body: "fix"}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(got) != 2 || got[0].Content.Raw != "summary" || got[0].Inline != nil {
+				t.Fatalf("PostReview() sent %+v", got)
+			}
+			if c := got[1]; c.Content.Raw != "fix" || c.Inline == nil || c.Inline.Path != "main.go" || c.Inline.To != 3 {
+				t.Errorf("PostReview() comment = %+v", c)
+			}
+		})
+	}
+
+	if _, err := NewBitbucket(WithToken("secret"), WithRepo("repo")); err != errorsInvalidRepo {
+		t.Errorf("NewBitbucket() error = %v, want %v", err, errorsInvalidRepo)
+	}
+}
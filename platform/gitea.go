@@ -0,0 +1,78 @@
+package platform
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const defaultGiteaURL = "https://gitea.com/api/v1"
+
+// Gitea posts reviews to the pull requests of Gitea and Forgejo.
+type Gitea struct {
+	cfg *config
+}
+
+// NewGitea creates a new Gitea platform with the given options. The base URL is the one
+// of the API of the instance, like https://git.example.com/api/v1.
+func NewGitea(opts ...Option) (*Gitea, error) {
+	cfg := newConfig(defaultGiteaURL, opts...)
+	if cfg.token == "" {
+		return nil, errorsMissingToken
+	}
+	if !validRepo(cfg.repo) {
+		return nil, errorsInvalidRepo
+	}
+
+	return &Gitea{cfg: cfg}, nil
+}
+
+// giteaReview is the request body of the create review API.
+type giteaReview struct {
+	CommitID string         `json:"commit_id,omitempty"`
+	Body     string         `json:"body"`
+	Event    string         `json:"event"`
+	Comments []giteaComment `json:"comments"`
+}
+
+type giteaComment struct {
+	Path string `json:"path"`
+	Body string `json:"body"`
+	// NewPosition is the line of the new file, the comments are on a single line
+	NewPosition int `json:"new_position"`
+}
+
+// PostReview creates a single pull request review with all inline comments. Gitea
+// comments a single line, the last line of the comments on several lines.
+// https://gitea.com/api/swagger#/repository/repoCreatePullReview
+func (g *Gitea) PostReview(ctx context.Context, number int, r Review) error {
+	body := giteaReview{
+		CommitID: r.CommitID,
+		Body:     r.Body,
+		Event:    "COMMENT",
+		Comments: make([]giteaComment, 0, len(r.Comments)),
+	}
+	for _, c := range r.Comments {
+		body.Comments = append(body.Comments, giteaComment{
+			Path:        c.Path,
+			Body:        c.Body,
+			NewPosition: c.Line,
+		})
+	}
+
+	url := strings.TrimRight(g.cfg.baseURL, "/") + "/repos/" + g.cfg.repo + "/pulls/" + strconv.Itoa(number) + "/reviews"
+	resp, err := send(ctx, g.cfg.httpClient, "gitea", http.MethodPost, url, g.header(), body)
+	if err != nil {
+		return err
+	}
+	return decode(resp, nil)
+}
+
+// header returns the headers of the API requests.
+func (g *Gitea) header() http.Header {
+	header := http.Header{}
+	header.Set("Accept", "application/json")
+	header.Set("Authorization", "token "+g.cfg.token)
+	return header
+}
@@ -0,0 +1,45 @@
+package platform
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGiteaPostReview(t *testing.T) {
+	var got giteaReview
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/appleboy/CodeGPT/pulls/7/reviews" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "token secret" {
+			t.Errorf("unexpected authorization header: %s", r.Header.Get("Authorization"))
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	if _, err := NewGitea(WithRepo("appleboy/CodeGPT")); err != errorsMissingToken {
+		t.Errorf("NewGitea() error = %v, want %v", err, errorsMissingToken)
+	}
+
+	var p Platform
+	p, err := NewGitea(WithToken("secret"), WithRepo("appleboy/CodeGPT"), WithBaseURL(srv.URL+"/api/v1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = p.PostReview(context.Background(), 7, Review{
+		Body:     "summary",
+		Comments: []ReviewComment{{Path: "main.go", StartLine: 1, Line: 3, Body: "fix"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Event != "COMMENT" || got.Body != "summary" || len(got.Comments) != 1 || got.Comments[0].NewPosition != 3 {
+		t.Errorf("PostReview() sent %+v", got)
+	}
+}
@@ -0,0 +1,207 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const defaultGitHubURL = "https://api.github.com"
+
+// perPage is the number of items of the pages of the list APIs, the maximum of GitHub.
+const perPage = 100
+
+var (
+	errorsMissingToken = errors.New("missing API token")
+	errorsInvalidRepo  = errors.New("repository must be in the owner/name format")
+)
+
+// GitHub posts reviews to GitHub pull requests.
+type GitHub struct {
+	cfg *config
+}
+
+// NewGitHub creates a new GitHub platform with the given options.
+func NewGitHub(opts ...Option) (*GitHub, error) {
+	cfg := newConfig(defaultGitHubURL, opts...)
+	if cfg.token == "" {
+		return nil, errorsMissingToken
+	}
+	if !validRepo(cfg.repo) {
+		return nil, errorsInvalidRepo
+	}
+
+	return &GitHub{cfg: cfg}, nil
+}
+
+// githubReview is the request body of the create review API.
+type githubReview struct {
+	CommitID string          `json:"commit_id,omitempty"`
+	Body     string          `json:"body"`
+	Event    string          `json:"event"`
+	Comments []githubComment `json:"comments"`
+}
+
+type githubComment struct {
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line,omitempty"`
+	StartSide string `json:"start_side,omitempty"`
+	Line      int    `json:"line"`
+	Side      string `json:"side"`
+	Body      string `json:"body"`
+}
+
+// PostReview creates a single pull request review with all inline comments.
+// https://docs.github.com/en/rest/pulls/reviews#create-a-review-for-a-pull-request
+func (g *GitHub) PostReview(ctx context.Context, number int, r Review) error {
+	body := githubReview{
+		CommitID: r.CommitID,
+		Body:     r.Body,
+		Event:    "COMMENT",
+		Comments: make([]githubComment, 0, len(r.Comments)),
+	}
+	for _, c := range r.Comments {
+		comment := githubComment{
+			Path: c.Path,
+			Line: c.Line,
+			Side: "RIGHT",
+			Body: c.Body,
+		}
+		if c.StartLine > 0 {
+			comment.StartLine = c.StartLine
+			comment.StartSide = "RIGHT"
+		}
+		body.Comments = append(body.Comments, comment)
+	}
+
+	return g.do(ctx, http.MethodPost, g.pullURL(number)+"/reviews", body, nil)
+}
+
+// PullRequest is a pull request of the repository.
+type PullRequest struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	State  string `json:"state"`
+	Draft  bool   `json:"draft"`
+	Head   struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+// PullRequest returns the pull request of the number.
+// https://docs.github.com/en/rest/pulls/pulls#get-a-pull-request
+func (g *GitHub) PullRequest(ctx context.Context, number int) (*PullRequest, error) {
+	var pr PullRequest
+	err := g.do(ctx, http.MethodGet, g.pullURL(number), nil, &pr)
+	return &pr, err
+}
+
+// PullRequestDiff returns the unified diff of the changes of the pull request.
+func (g *GitHub) PullRequestDiff(ctx context.Context, number int) (string, error) {
+	resp, err := g.send(ctx, http.MethodGet, g.pullURL(number), "application/vnd.github.diff", nil)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	return string(data), err
+}
+
+// ReviewComments returns the inline comments of the reviews of the pull request.
+// https://docs.github.com/en/rest/pulls/comments#list-review-comments-on-a-pull-request
+func (g *GitHub) ReviewComments(ctx context.Context, number int) ([]ReviewComment, error) {
+	var comments []ReviewComment
+	for page := 1; ; page++ {
+		var batch []ReviewComment
+		url := g.pullURL(number) + "/comments?per_page=" + strconv.Itoa(perPage) + "&page=" + strconv.Itoa(page)
+		if err := g.do(ctx, http.MethodGet, url, nil, &batch); err != nil {
+			return nil, err
+		}
+		comments = append(comments, batch...)
+		if len(batch) < perPage {
+			return comments, nil
+		}
+	}
+}
+
+// githubIssueComment is a comment of the conversation of a pull request.
+type githubIssueComment struct {
+	ID   int64  `json:"id,omitempty"`
+	Body string `json:"body"`
+}
+
+// UpsertComment updates the comment of the pull request conversation containing the
+// marker, like an HTML comment, with the body, or creates it when there's none. The
+// body must contain the marker to find the comment again.
+// https://docs.github.com/en/rest/issues/comments
+func (g *GitHub) UpsertComment(ctx context.Context, number int, marker, body string) error {
+	base := strings.TrimRight(g.cfg.baseURL, "/") + "/repos/" + g.cfg.repo + "/issues"
+	for page := 1; ; page++ {
+		var batch []githubIssueComment
+		url := base + "/" + strconv.Itoa(number) + "/comments?per_page=" + strconv.Itoa(perPage) + "&page=" + strconv.Itoa(page)
+		if err := g.do(ctx, http.MethodGet, url, nil, &batch); err != nil {
+			return err
+		}
+		for _, c := range batch {
+			if strings.Contains(c.Body, marker) {
+				url := base + "/comments/" + strconv.FormatInt(c.ID, 10)
+				return g.do(ctx, http.MethodPatch, url, githubIssueComment{Body: body}, nil)
+			}
+		}
+		if len(batch) < perPage {
+			break
+		}
+	}
+	return g.do(ctx, http.MethodPost, base+"/"+strconv.Itoa(number)+"/comments", githubIssueComment{Body: body}, nil)
+}
+
+// pullURL returns the API URL of the pull request.
+func (g *GitHub) pullURL(number int) string {
+	return strings.TrimRight(g.cfg.baseURL, "/") + "/repos/" + g.cfg.repo + "/pulls/" + strconv.Itoa(number)
+}
+
+// githubRelease is the request and the response body of the create release API.
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	Name    string `json:"name"`
+	Body    string `json:"body"`
+	Draft   bool   `json:"draft"`
+	HTMLURL string `json:"html_url,omitempty"`
+}
+
+// CreateRelease creates the release of the tag with the notes, and returns its URL.
+// GitHub creates the tag from the default branch if it doesn't exist.
+// https://docs.github.com/en/rest/releases/releases#create-a-release
+func (g *GitHub) CreateRelease(ctx context.Context, tag, body string, draft bool) (string, error) {
+	var out githubRelease
+	url := strings.TrimRight(g.cfg.baseURL, "/") + "/repos/" + g.cfg.repo + "/releases"
+	err := g.do(ctx, http.MethodPost, url, githubRelease{TagName: tag, Name: tag, Body: body, Draft: draft}, &out)
+	return out.HTMLURL, err
+}
+
+// do sends a JSON request to the GitHub API and decodes the JSON response into out.
+func (g *GitHub) do(ctx context.Context, method, url string, in, out interface{}) error {
+	resp, err := g.send(ctx, method, url, "application/vnd.github+json", in)
+	if err != nil {
+		return err
+	}
+	return decode(resp, out)
+}
+
+// send sends a request to the GitHub API with the JSON body in, and returns the response
+// in the media type of accept.
+func (g *GitHub) send(ctx context.Context, method, url, accept string, in interface{}) (*http.Response, error) {
+	header := http.Header{}
+	header.Set("Accept", accept)
+	header.Set("Authorization", "Bearer "+g.cfg.token)
+	header.Set("X-GitHub-Api-Version", "2022-11-28")
+	return send(ctx, g.cfg.httpClient, "github", method, url, header, in)
+}
@@ -0,0 +1,251 @@
+package platform
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/appleboy/CodeGPT/review"
+)
+
+func TestNewReview(t *testing.T) {
+	r := NewReview([]review.Finding{
+		{File: "main.go", StartLine: 10, EndLine: 12, Severity: review.HIGH, Category: "bug", Message: "nil pointer"},
+		{File: "main.go", StartLine: 20, EndLine: 20, Severity: review.LOW, Category: "style", Message: "typo"},
+		{Severity: review.MEDIUM, Category: "maintainability", Message: "missing tests"},
+	})
+
+	if len(r.Comments) != 2 {
+		t.Fatalf("NewReview() comments = %d, want 2", len(r.Comments))
+	}
+	if c := r.Comments[0]; c.StartLine != 10 || c.Line != 12 {
+		t.Errorf("NewReview() comment = %+v, want lines 10-12", c)
+	}
+	if c := r.Comments[1]; c.StartLine != 0 || c.Line != 20 {
+		t.Errorf("NewReview() comment = %+v, want line 20", c)
+	}
+}
+
+func TestGitHubPostReview(t *testing.T) {
+	var got githubReview
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/appleboy/CodeGPT/pulls/7/reviews" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("unexpected authorization header: %s", r.Header.Get("Authorization"))
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	if _, err := NewGitHub(WithToken("token"), WithRepo("CodeGPT")); err != errorsInvalidRepo {
+		t.Errorf("NewGitHub() error = %v, want %v", err, errorsInvalidRepo)
+	}
+
+	g, err := NewGitHub(WithToken("token"), WithRepo("appleboy/CodeGPT"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = g.PostReview(context.Background(), 7, Review{
+		Body:     "summary",
+		Comments: []ReviewComment{{Path: "main.go", StartLine: 1, Line: 3, Body: "fix"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Event != "COMMENT" || len(got.Comments) != 1 || got.Comments[0].Side != "RIGHT" {
+		t.Errorf("PostReview() sent %+v", got)
+	}
+}
+
+func TestGitHubCreateRelease(t *testing.T) {
+	var got githubRelease
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/appleboy/CodeGPT/releases" {
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"html_url": "https://github.com/appleboy/CodeGPT/releases/tag/v1.3.0"}`))
+	}))
+	defer srv.Close()
+
+	g, err := NewGitHub(WithToken("token"), WithRepo("appleboy/CodeGPT"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	url, err := g.CreateRelease(context.Background(), "v1.3.0", "## v1.3.0", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "https://github.com/appleboy/CodeGPT/releases/tag/v1.3.0" {
+		t.Errorf("CreateRelease() url = %s", url)
+	}
+	if got.TagName != "v1.3.0" || got.Name != "v1.3.0" || got.Body != "## v1.3.0" || !got.Draft {
+		t.Errorf("CreateRelease() request = %+v", got)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	r := Dedupe(Review{
+		Comments: []ReviewComment{
+			{Path: "main.go", Line: 12, Body: "**HIGH** (bug): nil pointer"},
+			{Path: "main.go", Line: 30, Body: "**LOW** (style): typo"},
+			{Path: "db.go", Line: 5, Body: "**LOW** (style): typo"},
+			{Path: "db.go", Line: 8, Body: "**LOW** (style): typo"},
+		},
+	}, []ReviewComment{
+		// the line moved with the new commits
+		{Path: "main.go", Line: 10, Body: "**HIGH** (bug): nil pointer\n"},
+	})
+
+	if len(r.Comments) != 2 {
+		t.Fatalf("Dedupe() comments = %+v, want 2", r.Comments)
+	}
+	if r.Comments[0].Path != "main.go" || r.Comments[1].Path != "db.go" || r.Comments[1].Line != 5 {
+		t.Errorf("Dedupe() comments = %+v", r.Comments)
+	}
+}
+
+func TestAnchor(t *testing.T) {
+	diff := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -8,3 +8,5 @@ func main() {\n a\n+b\n+c\n d\n e\n"
+	r := Anchor(Review{
+		Body: "CodeGPT found 3 issue(s) in this pull request.",
+		Comments: []ReviewComment{
+			{Path: "main.go", Line: 10, Body: "**HIGH** (bug): nil pointer"},
+			// only changed in the local working tree
+			{Path: "main.go", StartLine: 11, Line: 14, Body: "**LOW** (style): typo"},
+			{Path: "db.go", Line: 5, Body: "**MEDIUM** (bug): leak"},
+		},
+	}, diff)
+
+	if len(r.Comments) != 1 || r.Comments[0].Line != 10 {
+		t.Fatalf("Anchor() comments = %+v, want the one of line 10", r.Comments)
+	}
+	for _, want := range []string{"- `main.go:11` **LOW** (style): typo", "- `db.go:5` **MEDIUM** (bug): leak"} {
+		if !strings.Contains(r.Body, want) {
+			t.Errorf("Anchor() body = %q, want %q in it", r.Body, want)
+		}
+	}
+}
+
+func TestGitHubPullRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path != "/repos/appleboy/CodeGPT/pulls/7":
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		case r.Header.Get("Accept") == "application/vnd.github.diff":
+			_, _ = w.Write([]byte("diff --git a/main.go b/main.go\n"))
+		default:
+			_, _ = w.Write([]byte(`{"number": 7, "head": {"ref": "feature/GH-12", "sha": "abc123"}}`))
+		}
+	}))
+	defer srv.Close()
+
+	g, err := NewGitHub(WithToken("token"), WithRepo("appleboy/CodeGPT"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, err := g.PullRequest(context.Background(), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Number != 7 || pr.Head.Ref != "feature/GH-12" || pr.Head.SHA != "abc123" {
+		t.Errorf("PullRequest() = %+v", pr)
+	}
+
+	diff, err := g.PullRequestDiff(context.Background(), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != "diff --git a/main.go b/main.go\n" {
+		t.Errorf("PullRequestDiff() = %q", diff)
+	}
+}
+
+func TestGitHubReviewComments(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/appleboy/CodeGPT/pulls/7/comments" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		var comments []ReviewComment
+		if r.URL.Query().Get("page") == "1" {
+			for i := 0; i < perPage; i++ {
+				comments = append(comments, ReviewComment{Path: "main.go", Line: i + 1, Body: "fix"})
+			}
+		} else {
+			comments = append(comments, ReviewComment{Path: "db.go", Line: 1, Body: "fix"})
+		}
+		_ = json.NewEncoder(w).Encode(comments)
+	}))
+	defer srv.Close()
+
+	g, err := NewGitHub(WithToken("token"), WithRepo("appleboy/CodeGPT"), WithBaseURL(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	comments, err := g.ReviewComments(context.Background(), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != perPage+1 || comments[perPage].Path != "db.go" {
+		t.Errorf("ReviewComments() returned %d comments", len(comments))
+	}
+}
+
+func TestGitHubUpsertComment(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		method   string
+		path     string
+	}{
+		{
+			name:     "create",
+			existing: `[{"id": 1, "body": "LGTM"}]`,
+			method:   http.MethodPost,
+			path:     "/repos/appleboy/CodeGPT/issues/7/comments",
+		},
+		{
+			name:     "update",
+			existing: `[{"id": 1, "body": "LGTM"}, {"id": 2, "body": "<!-- marker -->\nold summary"}]`,
+			method:   http.MethodPatch,
+			path:     "/repos/appleboy/CodeGPT/issues/comments/2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				method, path string
+				got          githubIssueComment
+			)
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodGet {
+					_, _ = w.Write([]byte(tt.existing))
+					return
+				}
+				method, path = r.Method, r.URL.Path
+				_ = json.NewDecoder(r.Body).Decode(&got)
+			}))
+			defer srv.Close()
+
+			g, err := NewGitHub(WithToken("token"), WithRepo("appleboy/CodeGPT"), WithBaseURL(srv.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := g.UpsertComment(context.Background(), 7, "<!-- marker -->", "<!-- marker -->\nnew summary"); err != nil {
+				t.Fatal(err)
+			}
+			if method != tt.method || path != tt.path || got.Body != "<!-- marker -->\nnew summary" {
+				t.Errorf("UpsertComment() sent %s %s %+v", method, path, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package platform
+
+import (
+	"net/http"
+	"time"
+)
+
+// Option is an interface that specifies platform configuration options.
+type Option interface {
+	apply(*config)
+}
+
+// optionFunc is a type of function that can be used to implement the Option interface.
+// It takes a pointer to a config struct and modifies it.
+type optionFunc func(*config)
+
+// Ensure that optionFunc satisfies the Option interface.
+var _ Option = (*optionFunc)(nil)
+
+// The apply method of optionFunc type is implemented here to modify the config struct based on the function passed.
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithToken returns an Option that sets the API token.
+func WithToken(val string) Option {
+	return optionFunc(func(c *config) {
+		c.token = val
+	})
+}
+
+// WithUsername returns an Option that sets the username of the API token, sent with the
+// basic authentication, like the app passwords of Bitbucket.
+func WithUsername(val string) Option {
+	return optionFunc(func(c *config) {
+		c.username = val
+	})
+}
+
+// WithBaseURL returns an Option that sets the API base URL, for self-hosted instances.
+// An empty value keeps the default.
+func WithBaseURL(val string) Option {
+	return optionFunc(func(c *config) {
+		if val == "" {
+			return
+		}
+		c.baseURL = val
+	})
+}
+
+// WithRepo returns an Option that sets the repository in the owner/name format.
+func WithRepo(val string) Option {
+	return optionFunc(func(c *config) {
+		c.repo = val
+	})
+}
+
+// WithHTTPClient returns an Option that sets the HTTP client.
+func WithHTTPClient(val *http.Client) Option {
+	return optionFunc(func(c *config) {
+		if val == nil {
+			return
+		}
+		c.httpClient = val
+	})
+}
+
+// config is a struct that stores configuration options for the platform.
+type config struct {
+	token      string
+	username   string
+	baseURL    string
+	repo       string
+	httpClient *http.Client
+}
+
+// newConfig creates a new config object with default values, and applies the given options.
+func newConfig(baseURL string, opts ...Option) *config {
+	c := &config{
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+
+	for _, opt := range opts {
+		opt.apply(c)
+	}
+
+	return c
+}
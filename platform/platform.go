@@ -0,0 +1,121 @@
+package platform
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/review"
+)
+
+// ReviewComment is an inline comment anchored to a line of the pull request diff.
+type ReviewComment struct {
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line,omitempty"`
+	Line      int    `json:"line"`
+	Body      string `json:"body"`
+}
+
+// Review is a pull request review with a summary and inline comments,
+// posted in a single request.
+type Review struct {
+	// CommitID is the reviewed commit, the head of the pull request when it's empty
+	CommitID string          `json:"commit_id,omitempty"`
+	Body     string          `json:"body"`
+	Comments []ReviewComment `json:"comments"`
+}
+
+// Platform posts reviews to the pull requests of a code hosting service.
+type Platform interface {
+	PostReview(ctx context.Context, number int, r Review) error
+}
+
+// Differ is a platform returning the diff of the pull requests, it rejects the inline
+// comments on the lines outside of it.
+type Differ interface {
+	PullRequestDiff(ctx context.Context, number int) (string, error)
+}
+
+// NewReview converts the review findings to a pull request review.
+// Findings without a line are listed in the summary instead of inline.
+func NewReview(findings []review.Finding) Review {
+	r := Review{
+		Body:     "CodeGPT found " + strconv.Itoa(len(findings)) + " issue(s) in this pull request.",
+		Comments: []ReviewComment{},
+	}
+	if len(findings) == 0 {
+		r.Body = "CodeGPT didn't find any issue in this pull request."
+		return r
+	}
+
+	for _, f := range findings {
+		category := f.Category
+		if f.OWASP != "" {
+			category += ", " + f.OWASP
+		}
+		body := "**" + f.Severity + "** (" + category + "): " + f.Message
+		if f.Suggestion != "" {
+			body += "\n\n" + f.Suggestion
+		}
+
+		if f.File == "" || f.StartLine == 0 {
+			r.Body += "\n\n- " + body
+			continue
+		}
+
+		c := ReviewComment{
+			Path: f.File,
+			Line: f.EndLine,
+			Body: body,
+		}
+		if f.EndLine > f.StartLine {
+			c.StartLine = f.StartLine
+		} else {
+			c.Line = f.StartLine
+		}
+		r.Comments = append(r.Comments, c)
+	}
+
+	return r
+}
+
+// Dedupe removes the comments of the review already posted to the same file with the
+// same body, so running the review again on a pull request doesn't repeat them.
+func Dedupe(r Review, posted []ReviewComment) Review {
+	seen := make(map[string]bool, len(posted))
+	for _, c := range posted {
+		seen[c.Path+"\x00"+strings.TrimSpace(c.Body)] = true
+	}
+
+	comments := make([]ReviewComment, 0, len(r.Comments))
+	for _, c := range r.Comments {
+		key := c.Path + "\x00" + strings.TrimSpace(c.Body)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		comments = append(comments, c)
+	}
+	r.Comments = comments
+	return r
+}
+
+// Anchor moves the comments on the lines outside of the hunks of the pull request diff
+// to the summary, like the findings of a local diff with changes missing from the pull
+// request, GitHub rejects the whole review otherwise.
+func Anchor(r Review, diff string) Review {
+	comments := make([]ReviewComment, 0, len(r.Comments))
+	for _, c := range r.Comments {
+		start := c.StartLine
+		if start == 0 {
+			start = c.Line
+		}
+		if review.InDiff(diff, c.Path, start, c.Line) {
+			comments = append(comments, c)
+			continue
+		}
+		r.Body += "\n\n- `" + c.Path + ":" + strconv.Itoa(start) + "` " + c.Body
+	}
+	r.Comments = comments
+	return r
+}
@@ -0,0 +1,64 @@
+package platform
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// PullRequestEvent is the payload of the pull_request webhook event, also the event file of
+// the GitHub Actions workflows triggered by the pull requests.
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#pull_request
+type PullRequestEvent struct {
+	Action      string      `json:"action"`
+	Number      int         `json:"number"`
+	PullRequest PullRequest `json:"pull_request"`
+	Repository  struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	// Installation is set for the events sent to a GitHub App
+	Installation struct {
+		ID int64 `json:"id"`
+	} `json:"installation"`
+}
+
+// ParsePullRequestEvent parses the payload of a pull_request event.
+func ParsePullRequestEvent(payload []byte) (*PullRequestEvent, error) {
+	var e PullRequestEvent
+	if err := json.Unmarshal(payload, &e); err != nil {
+		return nil, err
+	}
+	if e.Number == 0 {
+		e.Number = e.PullRequest.Number
+	}
+	return &e, nil
+}
+
+// Reviewable reports whether the pull request must be reviewed after the event: it's
+// opened, reopened, ready for review or has new commits, and isn't a draft.
+func (e *PullRequestEvent) Reviewable() bool {
+	switch e.Action {
+	case "opened", "reopened", "synchronize", "ready_for_review":
+		return !e.PullRequest.Draft && e.PullRequest.State != "closed"
+	}
+	return false
+}
+
+// VerifySignature reports whether the signature, the X-Hub-Signature-256 header of the
+// webhook request, is the HMAC of the payload with the webhook secret.
+// https://docs.github.com/en/webhooks/using-webhooks/validating-webhook-deliveries
+func VerifySignature(secret string, payload []byte, signature string) bool {
+	sum, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(sum)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(got, mac.Sum(nil))
+}
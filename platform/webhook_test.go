@@ -0,0 +1,72 @@
+package platform
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestVerifySignature(t *testing.T) {
+	payload := []byte(`{"action": "opened"}`)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(payload)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name      string
+		secret    string
+		signature string
+		want      bool
+	}{
+		{"valid", "secret", signature, true},
+		{"wrong secret", "other", signature, false},
+		{"missing prefix", "secret", signature[len("sha256="):], false},
+		{"invalid hex", "secret", "sha256=zz", false},
+		{"empty", "secret", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifySignature(tt.secret, payload, tt.signature); got != tt.want {
+				t.Errorf("VerifySignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePullRequestEvent(t *testing.T) {
+	e, err := ParsePullRequestEvent([]byte(`{
+		"action": "synchronize",
+		"pull_request": {"number": 7, "state": "open", "head": {"ref": "main", "sha": "abc123"}},
+		"repository": {"full_name": "appleboy/CodeGPT"},
+		"installation": {"id": 42}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Number != 7 || e.Repository.FullName != "appleboy/CodeGPT" || e.Installation.ID != 42 || e.PullRequest.Head.SHA != "abc123" {
+		t.Errorf("ParsePullRequestEvent() = %+v", e)
+	}
+
+	tests := []struct {
+		action string
+		draft  bool
+		state  string
+		want   bool
+	}{
+		{"opened", false, "open", true},
+		{"synchronize", false, "open", true},
+		{"ready_for_review", false, "open", true},
+		{"opened", true, "open", false},
+		{"closed", false, "closed", false},
+		{"labeled", false, "open", false},
+	}
+	for _, tt := range tests {
+		e := &PullRequestEvent{Action: tt.action}
+		e.PullRequest.Draft = tt.draft
+		e.PullRequest.State = tt.state
+		if got := e.Reviewable(); got != tt.want {
+			t.Errorf("Reviewable() of %s (draft %v) = %v, want %v", tt.action, tt.draft, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Package policy reads the managed policy file the admins of an organization use to pin
+// the allowed providers and models, the max tokens, the mandatory redaction rules and the
+// local-only mode. The user and repository configs can't override it.
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrNotAllowed is returned for the providers and the models outside of the policy.
+var ErrNotAllowed = errors.New("not allowed by the policy")
+
+// Policy is the managed policy of the organization, the empty fields allow anything.
+type Policy struct {
+	// Providers are the allowed providers, like openai or azure.
+	Providers []string `yaml:"providers"`
+	// Models are the allowed models, with the * wildcard like gpt-4o*.
+	Models []string `yaml:"models"`
+	// MaxTokens is the upper bound of the max tokens of the completions.
+	MaxTokens int `yaml:"max_tokens"`
+	Redact    struct {
+		// Enable forces the redaction of the secrets of the diff.
+		Enable bool `yaml:"enable"`
+		// Patterns are the regular expressions of the secrets always redacted.
+		Patterns []string `yaml:"patterns"`
+	} `yaml:"redact"`
+	Privacy struct {
+		// LocalOnly forces the local-only mode with the allowed hosts.
+		LocalOnly    bool     `yaml:"local_only"`
+		AllowedHosts []string `yaml:"allowed_hosts"`
+	} `yaml:"privacy"`
+
+	// File is the file of the policy.
+	File string `yaml:"-"`
+}
+
+// Path returns the file of the managed policy: codegpt/policy.yaml of %ProgramData% on
+// Windows, /etc/codegpt/policy.yaml otherwise.
+func Path() string {
+	if runtime.GOOS == "windows" {
+		dir := os.Getenv("ProgramData")
+		if dir == "" {
+			dir = `C:\ProgramData`
+		}
+		return filepath.Join(dir, "codegpt", "policy.yaml")
+	}
+	return "/etc/codegpt/policy.yaml"
+}
+
+// Load reads the policy of the file, nil when the file does not exist. The unknown
+// fields are errors, so a typo never disables a rule.
+func Load(file string) (*Policy, error) {
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't read the policy %s: %w", file, err)
+	}
+
+	p := &Policy{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(p); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid policy %s: %w", file, err)
+	}
+	for _, m := range p.Models {
+		if _, err := path.Match(strings.ToLower(m), ""); err != nil {
+			return nil, fmt.Errorf("invalid model %q of the policy %s: %w", m, file, err)
+		}
+	}
+	if p.MaxTokens < 0 {
+		return nil, fmt.Errorf("invalid max_tokens %d of the policy %s", p.MaxTokens, file)
+	}
+	p.File = file
+	return p, nil
+}
+
+// Check returns an error when the provider or the model isn't allowed.
+func (p *Policy) Check(provider, model string) error {
+	if p == nil {
+		return nil
+	}
+	if len(p.Providers) > 0 && !slices.ContainsFunc(p.Providers, func(s string) bool {
+		return strings.EqualFold(s, provider)
+	}) {
+		return fmt.Errorf("the %s provider is %w %s, use one of %s",
+			provider, ErrNotAllowed, p.File, strings.Join(p.Providers, ", "))
+	}
+	if len(p.Models) > 0 && !p.allowModel(model) {
+		return fmt.Errorf("the %s model is %w %s, use one of %s",
+			model, ErrNotAllowed, p.File, strings.Join(p.Models, ", "))
+	}
+	return nil
+}
+
+func (p *Policy) allowModel(model string) bool {
+	model = strings.ToLower(model)
+	for _, m := range p.Models {
+		if ok, _ := path.Match(strings.ToLower(m), model); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Tokens returns the max tokens bounded by the policy.
+func (p *Policy) Tokens(n int) int {
+	if p == nil || p.MaxTokens == 0 || (n > 0 && n <= p.MaxTokens) {
+		return n
+	}
+	return p.MaxTokens
+}
+
+// RedactPatterns returns the redaction patterns with the mandatory ones of the policy.
+func (p *Policy) RedactPatterns(patterns []string) []string {
+	if p == nil {
+		return patterns
+	}
+	out := slices.Clone(patterns)
+	for _, r := range p.Redact.Patterns {
+		if !slices.Contains(out, r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// ForceRedact reports whether the redaction is mandatory.
+func (p *Policy) ForceRedact() bool {
+	return p != nil && (p.Redact.Enable || len(p.Redact.Patterns) > 0)
+}
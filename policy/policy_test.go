@@ -0,0 +1,101 @@
+package policy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writePolicy(t *testing.T, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "policy.yaml")
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoad(t *testing.T) {
+	p, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil || p != nil {
+		t.Fatalf("Load() of a missing file = %v, %v", p, err)
+	}
+
+	p, err = Load(writePolicy(t, `
+providers: [openai, azure]
+models: [gpt-4o, "gpt-4.1*"]
+max_tokens: 1000
+redact:
+  patterns: ["ACME-[0-9]{8}"]
+privacy:
+  local_only: true
+  allowed_hosts: [llm.corp.example]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.MaxTokens != 1000 || !p.Privacy.LocalOnly || !p.ForceRedact() {
+		t.Errorf("Load() = %+v", p)
+	}
+
+	if _, err := Load(writePolicy(t, "max_token: 1000\n")); err == nil {
+		t.Error("Load() accepted an unknown field")
+	}
+	if _, err := Load(writePolicy(t, "models: [\"gpt-[4\"]\n")); err == nil {
+		t.Error("Load() accepted an invalid model pattern")
+	}
+	if p, err := Load(writePolicy(t, "")); err != nil || p == nil {
+		t.Errorf("Load() of an empty file = %v, %v", p, err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	p := &Policy{Providers: []string{"openai", "azure"}, Models: []string{"gpt-4o", "gpt-4.1*"}}
+	tests := []struct {
+		provider, model string
+		want            bool
+	}{
+		{"openai", "gpt-4o", true},
+		{"azure", "GPT-4.1-mini", true},
+		{"openai", "gpt-4o-mini", false},
+		{"mistral", "gpt-4o", false},
+	}
+	for _, tt := range tests {
+		err := p.Check(tt.provider, tt.model)
+		if (err == nil) != tt.want || (err != nil && !errors.Is(err, ErrNotAllowed)) {
+			t.Errorf("Check(%q, %q) = %v, want allowed %v", tt.provider, tt.model, err, tt.want)
+		}
+	}
+
+	var none *Policy
+	if err := none.Check("mistral", "any"); err != nil {
+		t.Errorf("Check() without policy = %v", err)
+	}
+}
+
+func TestTokens(t *testing.T) {
+	p := &Policy{MaxTokens: 1000}
+	for n, want := range map[int]int{300: 300, 4000: 1000, 0: 1000} {
+		if got := p.Tokens(n); got != want {
+			t.Errorf("Tokens(%d) = %d, want %d", n, got, want)
+		}
+	}
+	if got := (&Policy{}).Tokens(4000); got != 4000 {
+		t.Errorf("Tokens() without bound = %d", got)
+	}
+}
+
+func TestRedactPatterns(t *testing.T) {
+	p := &Policy{}
+	p.Redact.Patterns = []string{"ACME-[0-9]{8}"}
+	got := p.RedactPatterns([]string{"token-[a-z]+", "ACME-[0-9]{8}"})
+	want := []string{"token-[a-z]+", "ACME-[0-9]{8}"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RedactPatterns() = %v, want %v", got, want)
+	}
+	if got := p.RedactPatterns(nil); !reflect.DeepEqual(got, []string{"ACME-[0-9]{8}"}) {
+		t.Errorf("RedactPatterns(nil) = %v", got)
+	}
+}
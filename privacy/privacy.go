@@ -0,0 +1,194 @@
+// Package privacy keeps the requests on the approved hosts. A policy allows the hosts
+// by name, and the addresses by IP or CIDR: a host not allowed by name must resolve to
+// allowed addresses only, and the connections of its dialer must go to them, whatever
+// the name resolves to at the dial. Every doubt, like a DNS failure, refuses the request.
+package privacy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// DefaultHosts are the hosts allowed by default, the local machine only.
+var DefaultHosts = []string{"localhost", "127.0.0.0/8", "::1/128"}
+
+// ErrNotAllowed is returned for the requests to the hosts outside of the policy.
+var ErrNotAllowed = errors.New("not allowed by privacy.local_only")
+
+// Policy is the list of the approved hosts.
+type Policy struct {
+	names    []string
+	prefixes []netip.Prefix
+	lookup   func(ctx context.Context, host string) ([]netip.Addr, error)
+
+	// the hosts allowed before, the refused ones are checked again by the next request
+	mu      sync.Mutex
+	checked map[string]bool
+}
+
+// New returns the policy of the hosts: names like llm.example.com, the subdomains
+// with *.example.com, IPs and CIDRs. Without hosts, DefaultHosts are allowed.
+func New(hosts []string) (*Policy, error) {
+	if len(hosts) == 0 {
+		hosts = DefaultHosts
+	}
+
+	p := &Policy{
+		lookup:  resolve,
+		checked: map[string]bool{},
+	}
+	for _, h := range hosts {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h == "" {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(h); err == nil {
+			p.prefixes = append(p.prefixes, prefix.Masked())
+			continue
+		}
+		if addr, err := netip.ParseAddr(h); err == nil {
+			p.prefixes = append(p.prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		if strings.ContainsAny(h, "/:") {
+			return nil, fmt.Errorf("invalid allowed host %q", h)
+		}
+		p.names = append(p.names, h)
+	}
+	return p, nil
+}
+
+// Check returns an error when the host of the URL isn't approved.
+func (p *Policy) Check(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	host := strings.ToLower(u.Hostname())
+	if host == "" {
+		return fmt.Errorf("%s: %w", rawURL, ErrNotAllowed)
+	}
+
+	p.mu.Lock()
+	ok := p.checked[host]
+	p.mu.Unlock()
+	if ok {
+		return nil
+	}
+
+	if err := p.check(ctx, host); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	p.checked[host] = true
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *Policy) check(ctx context.Context, host string) error {
+	if p.allowedName(host) {
+		return nil
+	}
+
+	addrs := []netip.Addr{}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addrs = append(addrs, addr)
+	} else {
+		addrs, err = p.lookup(ctx, host)
+		if err != nil {
+			return fmt.Errorf("can't resolve %s (%s): %w", host, err.Error(), ErrNotAllowed)
+		}
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("%s has no address: %w", host, ErrNotAllowed)
+	}
+	// every address must be approved, the connection may use any of them
+	for _, addr := range addrs {
+		if !p.allowed(addr.Unmap()) {
+			return fmt.Errorf("%s resolves to %s: %w", host, addr, ErrNotAllowed)
+		}
+	}
+	return nil
+}
+
+// resolve returns the IP addresses of the host.
+func resolve(ctx context.Context, host string) ([]netip.Addr, error) {
+	return net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+}
+
+// allowedName reports whether the host is allowed by name.
+func (p *Policy) allowedName(host string) bool {
+	for _, name := range p.names {
+		if host == name || (strings.HasPrefix(name, "*.") && strings.HasSuffix(host, name[1:])) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Policy) allowed(addr netip.Addr) bool {
+	for _, prefix := range p.prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Control is the net.Dialer.Control function refusing the connections to the addresses
+// outside of the policy. It checks the dialed address, not the one the name resolved to
+// at the Check, so a name can't resolve to another address in between.
+func (p *Policy) Control(network, address string, _ syscall.RawConn) error {
+	addr, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid address %s: %w", address, ErrNotAllowed)
+	}
+	if !p.allowed(addr.Addr().Unmap()) {
+		return fmt.Errorf("%s: %w", addr.Addr(), ErrNotAllowed)
+	}
+	return nil
+}
+
+// Dialer returns the dial function of the dialer with the Control of the policy. The
+// hosts allowed by name are dialed without it, they may resolve to any address.
+func (p *Policy) Dialer(d *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	checked := *d
+	checked.Control = func(network, address string, c syscall.RawConn) error {
+		if err := p.Control(network, address, c); err != nil {
+			return err
+		}
+		if d.Control != nil {
+			return d.Control(network, address, c)
+		}
+		return nil
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if host, _, err := net.SplitHostPort(addr); err == nil && p.allowedName(strings.ToLower(host)) {
+			return d.DialContext(ctx, network, addr)
+		}
+		return checked.DialContext(ctx, network, addr)
+	}
+}
+
+// Transport is an http.RoundTripper that refuses the requests to the hosts outside
+// of the policy, the redirects included.
+type Transport struct {
+	Origin http.RoundTripper
+	Policy *Policy
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.Policy.Check(req.Context(), req.URL.String()); err != nil {
+		return nil, err
+	}
+	return t.Origin.RoundTrip(req)
+}
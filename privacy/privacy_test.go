@@ -0,0 +1,160 @@
+package privacy
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	p, err := New([]string{"localhost", "*.corp.example", "10.0.0.0/8", "192.168.1.5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.lookup = func(ctx context.Context, host string) ([]netip.Addr, error) {
+		switch host {
+		case "llm.internal":
+			return []netip.Addr{netip.MustParseAddr("10.1.2.3")}, nil
+		case "mixed.internal":
+			return []netip.Addr{netip.MustParseAddr("10.1.2.3"), netip.MustParseAddr("8.8.8.8")}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"http://localhost:11434/v1", true},
+		{"https://gpt.corp.example/v1", true},
+		{"http://10.20.30.40:8080", true},
+		{"http://192.168.1.5", true},
+		{"http://192.168.1.6", false},
+		{"http://llm.internal/v1", true},
+		{"http://mixed.internal/v1", false},
+		{"https://api.openai.com/v1", false},
+		{"https://corp.example.evil.com", false},
+		{"/v1/chat", false},
+	}
+	for _, tt := range tests {
+		if err := p.Check(context.Background(), tt.url); (err == nil) != tt.want {
+			t.Errorf("Check(%q) = %v, want allowed %v", tt.url, err, tt.want)
+		}
+	}
+}
+
+func TestDefaultHosts(t *testing.T) {
+	p, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, u := range []string{"http://127.0.0.1:8080", "http://[::1]:11434", "http://localhost"} {
+		if err := p.Check(context.Background(), u); err != nil {
+			t.Errorf("Check(%q) = %v", u, err)
+		}
+	}
+	if err := p.Check(context.Background(), "http://203.0.113.7"); !errors.Is(err, ErrNotAllowed) {
+		t.Errorf("Check() of a public IP = %v, want ErrNotAllowed", err)
+	}
+}
+
+func TestInvalidHost(t *testing.T) {
+	if _, err := New([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("New() accepted an invalid CIDR")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	p, _ := New([]string{"192.0.2.1"})
+	client := &http.Client{Transport: &Transport{Origin: http.DefaultTransport, Policy: p}}
+	if _, err := client.Get(srv.URL); !errors.Is(err, ErrNotAllowed) {
+		t.Fatalf("Get() of a refused host = %v, want ErrNotAllowed", err)
+	}
+
+	p, _ = New(nil)
+	client.Transport = &Transport{Origin: http.DefaultTransport, Policy: p}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+}
+
+func TestCheckRefusedAgain(t *testing.T) {
+	p, _ := New([]string{"10.0.0.0/8"})
+	fail := true
+	p.lookup = func(ctx context.Context, host string) ([]netip.Addr, error) {
+		if fail {
+			return nil, errors.New("temporary failure in name resolution")
+		}
+		return []netip.Addr{netip.MustParseAddr("10.1.2.3")}, nil
+	}
+
+	if err := p.Check(context.Background(), "http://llm.internal"); !errors.Is(err, ErrNotAllowed) {
+		t.Fatalf("Check() with a DNS failure = %v, want ErrNotAllowed", err)
+	}
+	fail = false
+	if err := p.Check(context.Background(), "http://llm.internal"); err != nil {
+		t.Errorf("Check() after the DNS failure = %v, the refusal is cached", err)
+	}
+}
+
+func TestControl(t *testing.T) {
+	p, _ := New([]string{"llm.internal", "10.0.0.0/8", "::1/128"})
+
+	tests := []struct {
+		address string
+		want    bool
+	}{
+		{"10.1.2.3:443", true},
+		{"[::1]:11434", true},
+		{"[::ffff:10.1.2.3]:443", true},
+		{"8.8.8.8:443", false},
+		{"127.0.0.1:80", false},
+		{"llm.internal:443", false},
+	}
+	for _, tt := range tests {
+		if err := p.Control("tcp", tt.address, nil); (err == nil) != tt.want {
+			t.Errorf("Control(%q) = %v, want allowed %v", tt.address, err, tt.want)
+		}
+	}
+}
+
+func TestDialer(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+
+	tests := []struct {
+		hosts []string
+		addr  string
+		want  bool
+	}{
+		{nil, "127.0.0.1:" + port, true},
+		{[]string{"192.0.2.1"}, "127.0.0.1:" + port, false},
+		// the name resolves to an address outside of the policy at the dial
+		{[]string{"192.0.2.1"}, "localhost:" + port, false},
+		// the names allowed by name are dialed to any address
+		{[]string{"localhost"}, "localhost:" + port, true},
+	}
+	for _, tt := range tests {
+		p, _ := New(tt.hosts)
+		conn, err := p.Dialer(&net.Dialer{})(context.Background(), "tcp", tt.addr)
+		if err == nil {
+			conn.Close()
+		}
+		if (err == nil) != tt.want {
+			t.Errorf("Dialer(%v) of %s = %v, want allowed %v", tt.hosts, tt.addr, err, tt.want)
+		}
+		if err != nil && !errors.Is(err, ErrNotAllowed) {
+			t.Errorf("Dialer(%v) of %s = %v, want ErrNotAllowed", tt.hosts, tt.addr, err)
+		}
+	}
+}
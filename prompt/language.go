@@ -1,5 +1,7 @@
 package prompt
 
+import "sort"
+
 const DefaultLanguage = "English"
 
 var languageMaps = map[string]string{
@@ -7,6 +9,14 @@ var languageMaps = map[string]string{
 	"zh-tw": "Traditional Chinese",
 	"zh-cn": "Simplified Chinese",
 	"ja":    "Japanese",
+	"ko":    "Korean",
+	"fr":    "French",
+	"de":    "German",
+	"es":    "Spanish",
+	"it":    "Italian",
+	"pt-br": "Brazilian Portuguese",
+	"ru":    "Russian",
+	"vi":    "Vietnamese",
 }
 
 // GetLanguage returns the language name for the given language code,
@@ -17,3 +27,13 @@ func GetLanguage(langCode string) string {
 	}
 	return DefaultLanguage
 }
+
+// LanguageCodes returns the supported language codes in alphabetical order.
+func LanguageCodes() []string {
+	codes := make([]string, 0, len(languageMaps))
+	for code := range languageMaps {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
@@ -11,7 +11,10 @@ func TestGetLanguage(t *testing.T) {
 		{"zh-tw", "Traditional Chinese"},
 		{"zh-cn", "Simplified Chinese"},
 		{"ja", "Japanese"},
-		{"fr", DefaultLanguage},
+		{"ko", "Korean"},
+		{"pt-br", "Brazilian Portuguese"},
+		{"fr", "French"},
+		{"xx", DefaultLanguage},
 	}
 
 	for _, tc := range testCases {
@@ -21,3 +24,15 @@ func TestGetLanguage(t *testing.T) {
 		}
 	}
 }
+
+func TestLanguageCodes(t *testing.T) {
+	codes := LanguageCodes()
+	if len(codes) != len(languageMaps) {
+		t.Fatalf("LanguageCodes() returned %d codes, want %d", len(codes), len(languageMaps))
+	}
+	for i := 1; i < len(codes); i++ {
+		if codes[i-1] > codes[i] {
+			t.Errorf("LanguageCodes() is not sorted: %v", codes)
+		}
+	}
+}
@@ -2,7 +2,11 @@ package prompt
 
 import (
 	"embed"
+	"errors"
+	"io/fs"
 	"log"
+	"os"
+	"path/filepath"
 
 	"github.com/appleboy/CodeGPT/util"
 )
@@ -13,10 +17,25 @@ var templatesFS embed.FS
 // Template file names
 const (
 	CodeReviewTemplate         = "code_review_file_diff.tmpl"
+	CodeReviewSecurityTemplate = "code_review_security.tmpl"
 	SummarizeFileDiffTemplate  = "summarize_file_diff.tmpl"
 	SummarizeTitleTemplate     = "summarize_title.tmpl"
 	ConventionalCommitTemplate = "conventional_commit.tmpl"
 	TranslationTemplate        = "translation.tmpl"
+	SplitCommitsTemplate       = "split_commits.tmpl"
+	MergeTitlesTemplate        = "merge_titles.tmpl"
+	StructuredCommitTemplate   = "structured_commit.tmpl"
+	ConventionalFixTemplate    = "conventional_fix.tmpl"
+	BreakingChangeTemplate     = "breaking_change.tmpl"
+	ReleaseSummaryTemplate     = "release_summary.tmpl"
+	SquashCommitTemplate       = "squash_commit.tmpl"
+	ExplainChangesTemplate     = "explain_changes.tmpl"
+	DocCommentsTemplate        = "doc_comments.tmpl"
+	UnitTestsTemplate          = "unit_tests.tmpl"
+	StandupDigestTemplate      = "standup_digest.tmpl"
+	RefineCommitTemplate       = "refine_commit.tmpl"
+	RefineFeedbackTemplate     = "refine_feedback.tmpl"
+	AskQuestionTemplate        = "ask_question.tmpl"
 	SummarizePrefixKey         = "summarize_prefix"
 	SummarizeTitleKey          = "summarize_title"
 	SummarizeMessageKey        = "summarize_message"
@@ -28,3 +47,39 @@ func init() {
 		log.Fatal(err)
 	}
 }
+
+// Export writes the default prompt templates to the directory as a starting point
+// for custom templates. Existing files are kept unless overwrite is true.
+// It returns the paths of the written files.
+func Export(dir string, overwrite bool) ([]string, error) {
+	entries, err := fs.ReadDir(templatesFS, "templates")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	var written []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		target := filepath.Join(dir, e.Name())
+		if _, err := os.Stat(target); err == nil && !overwrite {
+			continue
+		} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return written, err
+		}
+
+		data, err := templatesFS.ReadFile("templates/" + e.Name())
+		if err != nil {
+			return written, err
+		}
+		if err := os.WriteFile(target, data, 0o644); err != nil {
+			return written, err
+		}
+		written = append(written, target)
+	}
+	return written, nil
+}
@@ -0,0 +1,35 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	dir := t.TempDir()
+	custom := filepath.Join(dir, SummarizeTitleTemplate)
+	if err := os.WriteFile(custom, []byte("custom"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := Export(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range written {
+		if name == custom {
+			t.Error("Export() overwrote an existing template")
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, SummarizeFileDiffTemplate)); err != nil {
+		t.Errorf("Export() didn't write %s: %v", SummarizeFileDiffTemplate, err)
+	}
+
+	if _, err := Export(dir, true); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(custom); string(data) == "custom" {
+		t.Error("Export() with overwrite kept the existing template")
+	}
+}
@@ -0,0 +1,40 @@
+package prompt
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// CommitGroup is a group of files the model proposes to commit together.
+type CommitGroup struct {
+	Files   []string `json:"files"`
+	Message string   `json:"message"`
+}
+
+// ParseCommitGroups parses the JSON array of commit groups from the model response.
+// Markdown code fences and text around the array are ignored.
+func ParseCommitGroups(content string) ([]CommitGroup, error) {
+	start := strings.Index(content, "[")
+	end := strings.LastIndex(content, "]")
+	if start < 0 || end < start {
+		return nil, errors.New("no commit groups found in the response")
+	}
+
+	var groups []CommitGroup
+	if err := json.Unmarshal([]byte(content[start:end+1]), &groups); err != nil {
+		return nil, err
+	}
+
+	// drop the groups without files or message
+	valid := groups[:0]
+	for _, g := range groups {
+		if len(g.Files) == 0 || strings.TrimSpace(g.Message) == "" {
+			continue
+		}
+		g.Message = strings.TrimSpace(g.Message)
+		valid = append(valid, g)
+	}
+
+	return valid, nil
+}
@@ -0,0 +1,31 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCommitGroups(t *testing.T) {
+	content := "```json\n" + `[
+  {"files": ["git/git.go", "git/options.go"], "message": "feat: add split mode"},
+  {"files": [], "message": "chore: empty"},
+  {"files": ["README.md"], "message": " docs: document split mode "}
+]` + "\n```"
+
+	want := []CommitGroup{
+		{Files: []string{"git/git.go", "git/options.go"}, Message: "feat: add split mode"},
+		{Files: []string{"README.md"}, Message: "docs: document split mode"},
+	}
+
+	got, err := ParseCommitGroups(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseCommitGroups() = %v, want %v", got, want)
+	}
+
+	if _, err := ParseCommitGroups("no json here"); err == nil {
+		t.Error("ParseCommitGroups() expected error")
+	}
+}
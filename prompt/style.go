@@ -0,0 +1,79 @@
+package prompt
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// The tones of the commit messages, set with prompt.tone or --tone.
+const (
+	// ToneConcise asks for a few short summary points.
+	ToneConcise = "concise"
+	// ToneDetailed asks for every relevant change with its reason.
+	ToneDetailed = "detailed"
+	// ToneFormal asks for a neutral and impersonal wording.
+	ToneFormal = "formal"
+)
+
+// Tones lists the tones of the commit messages.
+var Tones = []string{ToneConcise, ToneDetailed, ToneFormal}
+
+// The body modes of the commit messages, set with prompt.body, --no_body or --detailed.
+const (
+	// BodyNone generates the subject only.
+	BodyNone = "none"
+	// BodyDetailed asks for a bullet point with the rationale of every change.
+	BodyDetailed = "detailed"
+)
+
+// Bodies lists the body modes of the commit messages.
+var Bodies = []string{BodyNone, BodyDetailed}
+
+// IsBody returns true if the body mode is supported.
+func IsBody(val string) bool {
+	for _, b := range Bodies {
+		if b == val {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTone returns true if the tone is supported.
+func IsTone(val string) bool {
+	for _, t := range Tones {
+		if t == val {
+			return true
+		}
+	}
+	return false
+}
+
+// LimitSubject shortens the first line of the message to max characters, cut at the last
+// space of the title, after the conventional commit prefix. The message is unchanged if
+// max isn't positive or the line already fits.
+func LimitSubject(message string, max int) string {
+	subject, body, hasBody := strings.Cut(message, "\n")
+	if max <= 0 || utf8.RuneCountInString(subject) <= max {
+		return message
+	}
+
+	runes := []rune(subject)
+	cut := string(runes[:max])
+	// the prefix like feat(cli): is kept whole
+	start := 0
+	if i := strings.Index(subject, ": "); i >= 0 && i+2 < len(cut) {
+		start = i + 2
+	}
+	if runes[max] != ' ' {
+		if i := strings.LastIndex(cut[start:], " "); i > 0 {
+			cut = cut[:start+i]
+		}
+	}
+	cut = strings.TrimRight(cut, " ,;:-")
+
+	if !hasBody {
+		return cut
+	}
+	return cut + "\n" + body
+}
@@ -0,0 +1,84 @@
+package prompt
+
+import "testing"
+
+func TestIsTone(t *testing.T) {
+	for _, tone := range Tones {
+		if !IsTone(tone) {
+			t.Errorf("IsTone(%q) = false", tone)
+		}
+	}
+	if IsTone("funny") || IsTone("") {
+		t.Error("IsTone() accepted an unknown tone")
+	}
+}
+
+func TestIsBody(t *testing.T) {
+	for _, body := range Bodies {
+		if !IsBody(body) {
+			t.Errorf("IsBody(%q) = false", body)
+		}
+	}
+	if IsBody("short") || IsBody("") {
+		t.Error("IsBody() accepted an unknown body mode")
+	}
+}
+
+func TestLimitSubject(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		max     int
+		want    string
+	}{
+		{
+			name:    "fits",
+			message: "feat: add the ui command\n\n- body",
+			max:     50,
+			want:    "feat: add the ui command\n\n- body",
+		},
+		{
+			name:    "disabled",
+			message: "feat: add the terminal user interface command",
+			max:     0,
+			want:    "feat: add the terminal user interface command",
+		},
+		{
+			name:    "cut at the last space",
+			message: "feat(cli): add the terminal user interface command\n\n- body",
+			max:     30,
+			want:    "feat(cli): add the terminal\n\n- body",
+		},
+		{
+			name:    "cut at a space",
+			message: "fix: handle the empty diff of the hook",
+			max:     15,
+			want:    "fix: handle the",
+		},
+		{
+			name:    "trailing punctuation",
+			message: "fix: keep the order, the hooks and the files",
+			max:     22,
+			want:    "fix: keep the order",
+		},
+		{
+			name:    "long word",
+			message: "fix: internationalization",
+			max:     12,
+			want:    "fix: interna",
+		},
+		{
+			name:    "wide characters",
+			message: "feat: 新增終端機使用者介面命令",
+			max:     10,
+			want:    "feat: 新增終端",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LimitSubject(tt.message, tt.max); got != tt.want {
+				t.Errorf("LimitSubject() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// Code generated by protoc-gen-connect-go. DO NOT EDIT.
+//
+// Source: codegpt/v1/codegpt.proto
+
+package codegptv1connect
+
+import (
+	connect "connectrpc.com/connect"
+	context "context"
+	errors "errors"
+	v1 "github.com/appleboy/CodeGPT/proto/codegpt/v1"
+	http "net/http"
+	strings "strings"
+)
+
+// This is a compile-time assertion to ensure that this generated file and the connect package are
+// compatible. If you get a compiler error that this constant is not defined, this code was
+// generated with a version of connect newer than the one compiled into your binary. You can fix the
+// problem by either regenerating this code with an older version of connect or updating the connect
+// version compiled into your binary.
+const _ = connect.IsAtLeastVersion1_13_0
+
+const (
+	// CodeGPTServiceName is the fully-qualified name of the CodeGPTService service.
+	CodeGPTServiceName = "codegpt.v1.CodeGPTService"
+)
+
+// These constants are the fully-qualified names of the RPCs defined in this package. They're
+// exposed at runtime as Spec.Procedure and as the final two segments of the HTTP route.
+//
+// Note that these are different from the fully-qualified method names used by
+// google.golang.org/protobuf/reflect/protoreflect. To convert from these constants to
+// reflection-formatted method names, remove the leading slash and convert the remaining slash to a
+// period.
+const (
+	// CodeGPTServiceGenerateCommitMessageProcedure is the fully-qualified name of the CodeGPTService's
+	// GenerateCommitMessage RPC.
+	CodeGPTServiceGenerateCommitMessageProcedure = "/codegpt.v1.CodeGPTService/GenerateCommitMessage"
+	// CodeGPTServiceReviewProcedure is the fully-qualified name of the CodeGPTService's Review RPC.
+	CodeGPTServiceReviewProcedure = "/codegpt.v1.CodeGPTService/Review"
+)
+
+// CodeGPTServiceClient is a client for the codegpt.v1.CodeGPTService service.
+type CodeGPTServiceClient interface {
+	// GenerateCommitMessage streams the steps of the pipeline, then the commit message.
+	GenerateCommitMessage(context.Context, *connect.Request[v1.GenerateCommitMessageRequest]) (*connect.ServerStreamForClient[v1.GenerateCommitMessageResponse], error)
+	// Review streams the findings of the review, the last response has the usage.
+	Review(context.Context, *connect.Request[v1.ReviewRequest]) (*connect.ServerStreamForClient[v1.ReviewResponse], error)
+}
+
+// NewCodeGPTServiceClient constructs a client for the codegpt.v1.CodeGPTService service. By
+// default, it uses the Connect protocol with the binary Protobuf Codec, asks for gzipped responses,
+// and sends uncompressed requests. To use the gRPC or gRPC-Web protocols, supply the
+// connect.WithGRPC() or connect.WithGRPCWeb() options.
+//
+// The URL supplied here should be the base URL for the Connect or gRPC server (for example,
+// http://api.acme.com or https://acme.com/grpc).
+func NewCodeGPTServiceClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) CodeGPTServiceClient {
+	baseURL = strings.TrimRight(baseURL, "/")
+	codeGPTServiceMethods := v1.File_codegpt_v1_codegpt_proto.Services().ByName("CodeGPTService").Methods()
+	return &codeGPTServiceClient{
+		generateCommitMessage: connect.NewClient[v1.GenerateCommitMessageRequest, v1.GenerateCommitMessageResponse](
+			httpClient,
+			baseURL+CodeGPTServiceGenerateCommitMessageProcedure,
+			connect.WithSchema(codeGPTServiceMethods.ByName("GenerateCommitMessage")),
+			connect.WithClientOptions(opts...),
+		),
+		review: connect.NewClient[v1.ReviewRequest, v1.ReviewResponse](
+			httpClient,
+			baseURL+CodeGPTServiceReviewProcedure,
+			connect.WithSchema(codeGPTServiceMethods.ByName("Review")),
+			connect.WithClientOptions(opts...),
+		),
+	}
+}
+
+// codeGPTServiceClient implements CodeGPTServiceClient.
+type codeGPTServiceClient struct {
+	generateCommitMessage *connect.Client[v1.GenerateCommitMessageRequest, v1.GenerateCommitMessageResponse]
+	review                *connect.Client[v1.ReviewRequest, v1.ReviewResponse]
+}
+
+// GenerateCommitMessage calls codegpt.v1.CodeGPTService.GenerateCommitMessage.
+func (c *codeGPTServiceClient) GenerateCommitMessage(ctx context.Context, req *connect.Request[v1.GenerateCommitMessageRequest]) (*connect.ServerStreamForClient[v1.GenerateCommitMessageResponse], error) {
+	return c.generateCommitMessage.CallServerStream(ctx, req)
+}
+
+// Review calls codegpt.v1.CodeGPTService.Review.
+func (c *codeGPTServiceClient) Review(ctx context.Context, req *connect.Request[v1.ReviewRequest]) (*connect.ServerStreamForClient[v1.ReviewResponse], error) {
+	return c.review.CallServerStream(ctx, req)
+}
+
+// CodeGPTServiceHandler is an implementation of the codegpt.v1.CodeGPTService service.
+type CodeGPTServiceHandler interface {
+	// GenerateCommitMessage streams the steps of the pipeline, then the commit message.
+	GenerateCommitMessage(context.Context, *connect.Request[v1.GenerateCommitMessageRequest], *connect.ServerStream[v1.GenerateCommitMessageResponse]) error
+	// Review streams the findings of the review, the last response has the usage.
+	Review(context.Context, *connect.Request[v1.ReviewRequest], *connect.ServerStream[v1.ReviewResponse]) error
+}
+
+// NewCodeGPTServiceHandler builds an HTTP handler from the service implementation. It returns the
+// path on which to mount the handler and the handler itself.
+//
+// By default, handlers support the Connect, gRPC, and gRPC-Web protocols with the binary Protobuf
+// and JSON codecs. They also support gzip compression.
+func NewCodeGPTServiceHandler(svc CodeGPTServiceHandler, opts ...connect.HandlerOption) (string, http.Handler) {
+	codeGPTServiceMethods := v1.File_codegpt_v1_codegpt_proto.Services().ByName("CodeGPTService").Methods()
+	codeGPTServiceGenerateCommitMessageHandler := connect.NewServerStreamHandler(
+		CodeGPTServiceGenerateCommitMessageProcedure,
+		svc.GenerateCommitMessage,
+		connect.WithSchema(codeGPTServiceMethods.ByName("GenerateCommitMessage")),
+		connect.WithHandlerOptions(opts...),
+	)
+	codeGPTServiceReviewHandler := connect.NewServerStreamHandler(
+		CodeGPTServiceReviewProcedure,
+		svc.Review,
+		connect.WithSchema(codeGPTServiceMethods.ByName("Review")),
+		connect.WithHandlerOptions(opts...),
+	)
+	return "/codegpt.v1.CodeGPTService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case CodeGPTServiceGenerateCommitMessageProcedure:
+			codeGPTServiceGenerateCommitMessageHandler.ServeHTTP(w, r)
+		case CodeGPTServiceReviewProcedure:
+			codeGPTServiceReviewHandler.ServeHTTP(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+// UnimplementedCodeGPTServiceHandler returns CodeUnimplemented from all methods.
+type UnimplementedCodeGPTServiceHandler struct{}
+
+func (UnimplementedCodeGPTServiceHandler) GenerateCommitMessage(context.Context, *connect.Request[v1.GenerateCommitMessageRequest], *connect.ServerStream[v1.GenerateCommitMessageResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("codegpt.v1.CodeGPTService.GenerateCommitMessage is not implemented"))
+}
+
+func (UnimplementedCodeGPTServiceHandler) Review(context.Context, *connect.Request[v1.ReviewRequest], *connect.ServerStream[v1.ReviewResponse]) error {
+	return connect.NewError(connect.CodeUnimplemented, errors.New("codegpt.v1.CodeGPTService.Review is not implemented"))
+}
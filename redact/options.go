@@ -0,0 +1,54 @@
+package redact
+
+// Option is an interface that specifies redaction configuration options.
+type Option interface {
+	apply(*config)
+}
+
+// optionFunc is a type of function that can be used to implement the Option interface.
+// It takes a pointer to a config struct and modifies it.
+type optionFunc func(*config)
+
+// Ensure that optionFunc satisfies the Option interface.
+var _ Option = (*optionFunc)(nil)
+
+// The apply method of optionFunc type is implemented here to modify the config struct based on the function passed.
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithPatterns returns an Option that appends custom regular expressions to the default rules.
+func WithPatterns(val []string) Option {
+	return optionFunc(func(c *config) {
+		c.patterns = append(c.patterns, val...)
+	})
+}
+
+// WithEntropyThreshold returns an Option that sets the minimum Shannon entropy
+// for a token to be treated as a secret. Zero or negative values keep the default.
+func WithEntropyThreshold(val float64) Option {
+	return optionFunc(func(c *config) {
+		if val <= 0 {
+			return
+		}
+		c.entropy = val
+	})
+}
+
+// WithMinLength returns an Option that sets the minimum length of a token
+// to be checked by the entropy rule. Zero or negative values keep the default.
+func WithMinLength(val int) Option {
+	return optionFunc(func(c *config) {
+		if val <= 0 {
+			return
+		}
+		c.minLength = val
+	})
+}
+
+// config is a struct that stores configuration options for the redactor.
+type config struct {
+	patterns  []string
+	entropy   float64
+	minLength int
+}
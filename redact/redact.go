@@ -0,0 +1,236 @@
+package redact
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultEntropy is the minimum Shannon entropy of a token treated as a secret by default.
+const DefaultEntropy = 4.5
+
+const defaultMinLength = 20
+
+// Rule is a named regular expression that matches a secret.
+type Rule struct {
+	Name    string
+	Pattern *regexp.Regexp
+}
+
+// defaultRules is the list of well-known secret formats.
+var defaultRules = []Rule{
+	{"private_key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{"aws_access_key", regexp.MustCompile(`\b(?:A3T[A-Z0-9]|AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16}\b`)},
+	{"aws_secret_key", regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?[A-Za-z0-9/+=]{40}`)},
+	{"openai_api_key", regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{20,}`)},
+	{"github_token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+	{"slack_token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"google_api_key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+}
+
+// tokenPattern matches candidate tokens for the entropy check.
+var tokenPattern = regexp.MustCompile(`[A-Za-z0-9+/=_-]+`)
+
+// hunkHeader matches the new file start line of a hunk header like @@ -1,3 +4,5 @@.
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)`)
+
+// Finding describes a secret that was replaced in the content. File and Line are the
+// location of the secret in the new version of the file, the line of the content when
+// it isn't a diff.
+type Finding struct {
+	Rule        string
+	Placeholder string
+	File        string
+	Line        int
+
+	// index is the line of the secret in the content
+	index int
+}
+
+// position is the location of a line of the diff in the new version of its file.
+type position struct {
+	file string
+	line int
+}
+
+// positions returns the location of every line of the diff, from the + side of the hunk
+// headers: the removed lines don't count, they are at the line they were removed before.
+// The lines outside of the hunks are the lines of the content.
+func positions(lines []string) []position {
+	out := make([]position, len(lines))
+	file, line, inHunk := "", 0, false
+	for i, l := range lines {
+		out[i] = position{line: i + 1}
+		switch {
+		case strings.HasPrefix(l, "diff --git "):
+			file, inHunk = "", false
+		case !inHunk && strings.HasPrefix(l, "--- a/"):
+			file = strings.TrimPrefix(l, "--- a/")
+		case !inHunk && strings.HasPrefix(l, "+++ b/"):
+			file = strings.TrimPrefix(l, "+++ b/")
+		case hunkHeader.MatchString(l):
+			line, _ = strconv.Atoi(hunkHeader.FindStringSubmatch(l)[1])
+			inHunk = true
+		case inHunk:
+			out[i] = position{file: file, line: line}
+			if !strings.HasPrefix(l, "-") && !strings.HasPrefix(l, "\\") {
+				line++
+			}
+		}
+	}
+	return out
+}
+
+// Redactor replaces secrets in a git diff or in a text with placeholders.
+type Redactor struct {
+	rules     []Rule
+	entropy   float64
+	minLength int
+}
+
+// New creates a new Redactor with the default rules and the given options.
+func New(opts ...Option) (*Redactor, error) {
+	cfg := &config{
+		entropy:   DefaultEntropy,
+		minLength: defaultMinLength,
+	}
+
+	for _, o := range opts {
+		o.apply(cfg)
+	}
+
+	rules := make([]Rule, 0, len(defaultRules)+len(cfg.patterns))
+	rules = append(rules, defaultRules...)
+	for i, p := range cfg.patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", p, err)
+		}
+		rules = append(rules, Rule{Name: "custom_" + strconv.Itoa(i+1), Pattern: re})
+	}
+
+	return &Redactor{
+		rules:     rules,
+		entropy:   cfg.entropy,
+		minLength: cfg.minLength,
+	}, nil
+}
+
+// Redact replaces every secret found in the git diff with a placeholder
+// and returns the redacted content and the list of findings. The entropy
+// check only inspects the changed lines of the diff.
+func (r *Redactor) Redact(diff string) (string, []Finding) {
+	return r.redact(diff, true)
+}
+
+// RedactText replaces every secret found in a text that isn't a diff, like the
+// content of a file, and returns the redacted text and the list of findings.
+// The entropy check inspects every line of the text.
+func (r *Redactor) RedactText(text string) (string, []Finding) {
+	return r.redact(text, false)
+}
+
+func (r *Redactor) redact(content string, diff bool) (string, []Finding) {
+	var findings []Finding
+	counter := map[string]int{}
+
+	placeholder := func(name string) string {
+		counter[name]++
+		return "REDACTED_" + strings.ToUpper(name) + "_" + strconv.Itoa(counter[name])
+	}
+
+	// the multi-line secrets are replaced by a single line, lineOf maps the lines of the
+	// redacted content to the ones of the content
+	pos := make([]position, strings.Count(content, "\n")+1)
+	if diff {
+		pos = positions(strings.Split(content, "\n"))
+	} else {
+		for i := range pos {
+			pos[i].line = i + 1
+		}
+	}
+	lineOf := make([]int, len(pos))
+	for i := range lineOf {
+		lineOf[i] = i
+	}
+	finding := func(rule, p string, i int) Finding {
+		index := lineOf[i]
+		return Finding{Rule: rule, Placeholder: p, File: pos[index].file, Line: pos[index].line, index: index}
+	}
+
+	for _, rule := range r.rules {
+		matches := rule.Pattern.FindAllStringIndex(content, -1)
+		if len(matches) == 0 {
+			continue
+		}
+
+		var sb strings.Builder
+		last := 0
+		for _, m := range matches {
+			p := placeholder(rule.Name)
+			findings = append(findings, finding(rule.Name, p, strings.Count(content[:m[0]], "\n")))
+			sb.WriteString(content[last:m[0]])
+			sb.WriteString(p)
+			last = m[1]
+		}
+		sb.WriteString(content[last:])
+
+		for j := len(matches) - 1; j >= 0; j-- {
+			m := matches[j]
+			if n := strings.Count(content[m[0]:m[1]], "\n"); n > 0 {
+				i := strings.Count(content[:m[0]], "\n")
+				lineOf = append(lineOf[:i+1], lineOf[i+1+n:]...)
+			}
+		}
+		content = sb.String()
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		// only inspect the changed lines of the diff
+		if diff && (!strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "-") ||
+			strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---")) {
+			continue
+		}
+		lines[i] = tokenPattern.ReplaceAllStringFunc(line, func(token string) string {
+			if len(token) < r.minLength || strings.HasPrefix(token, "REDACTED_") {
+				return token
+			}
+			if Entropy(token) < r.entropy {
+				return token
+			}
+			p := placeholder("high_entropy")
+			findings = append(findings, finding("high_entropy", p, i))
+			return p
+		})
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].index < findings[j].index
+	})
+
+	return strings.Join(lines, "\n"), findings
+}
+
+// Entropy returns the Shannon entropy of the given string in bits per character.
+func Entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	freq := map[rune]float64{}
+	for _, c := range s {
+		freq[c]++
+	}
+
+	var entropy float64
+	length := float64(len([]rune(s)))
+	for _, count := range freq {
+		p := count / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
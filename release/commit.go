@@ -0,0 +1,91 @@
+package release
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Level is the part of the semantic version a release bumps.
+type Level int
+
+// The levels of the releases, from the smallest.
+const (
+	// None doesn't release, like for the docs, the tests or the chores.
+	None Level = iota
+	Patch
+	Minor
+	Major
+)
+
+func (l Level) String() string {
+	switch l {
+	case Patch:
+		return "patch"
+	case Minor:
+		return "minor"
+	case Major:
+		return "major"
+	}
+	return "none"
+}
+
+// PatchTypes are the conventional commit types released in a patch version.
+var PatchTypes = []string{"fix", "perf", "revert"}
+
+// header matches the header of a conventional commit: type(scope)!: subject
+var header = regexp.MustCompile(`^(\w+)(?:\(([^()]*)\))?(!)?: *(.*)$`)
+
+// breakingFooter matches the footer of a breaking change.
+var breakingFooter = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE: `)
+
+// Commit is a commit parsed with the Conventional Commits specification.
+type Commit struct {
+	Hash    string `json:"hash"`
+	Type    string `json:"type,omitempty"`
+	Scope   string `json:"scope,omitempty"`
+	Subject string `json:"subject"`
+	Body    string `json:"body,omitempty"`
+	// Breaking is true with a ! after the type or a BREAKING CHANGE footer
+	Breaking bool   `json:"breaking,omitempty"`
+	Author   string `json:"author,omitempty"`
+	Email    string `json:"email,omitempty"`
+}
+
+// ParseCommit parses the message of the commit. The type is empty when the header
+// doesn't follow the specification, the subject is then the whole header.
+func ParseCommit(hash, message string) Commit {
+	first, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	c := Commit{Hash: hash, Subject: strings.TrimSpace(first), Body: strings.TrimSpace(body)}
+	if m := header.FindStringSubmatch(c.Subject); m != nil {
+		c.Type = strings.ToLower(m[1])
+		c.Scope = m[2]
+		c.Breaking = m[3] == "!"
+		c.Subject = strings.TrimSpace(m[4])
+	}
+	c.Breaking = c.Breaking || breakingFooter.MatchString(c.Body)
+	return c
+}
+
+// Level returns the level of the release of the commit: major for a breaking
+// change, minor for a feature and patch for a fix, a performance improvement or a revert.
+func (c Commit) Level() Level {
+	switch {
+	case c.Breaking:
+		return Major
+	case c.Type == "feat":
+		return Minor
+	case slices.Contains(PatchTypes, c.Type):
+		return Patch
+	}
+	return None
+}
+
+// LevelOf returns the highest level of the commits.
+func LevelOf(commits []Commit) Level {
+	level := None
+	for _, c := range commits {
+		level = max(level, c.Level())
+	}
+	return level
+}
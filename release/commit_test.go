@@ -0,0 +1,57 @@
+package release
+
+import "testing"
+
+func TestParseCommit(t *testing.T) {
+	tests := []struct {
+		message string
+		want    Commit
+	}{
+		{
+			message: "feat(cli): add the semver command\n\n- suggest the next version",
+			want:    Commit{Hash: "h", Type: "feat", Scope: "cli", Subject: "add the semver command", Body: "- suggest the next version"},
+		},
+		{
+			message: "refactor!: drop the v1 config",
+			want:    Commit{Hash: "h", Type: "refactor", Subject: "drop the v1 config", Breaking: true},
+		},
+		{
+			message: "fix: rename the flag\n\nBREAKING CHANGE: use --no_body instead of --short",
+			want: Commit{
+				Hash: "h", Type: "fix", Subject: "rename the flag",
+				Body: "BREAKING CHANGE: use --no_body instead of --short", Breaking: true,
+			},
+		},
+		{
+			message: "Update the readme",
+			want:    Commit{Hash: "h", Subject: "Update the readme"},
+		},
+	}
+	for _, tt := range tests {
+		if got := ParseCommit("h", tt.message); got != tt.want {
+			t.Errorf("ParseCommit(%q) = %+v, want %+v", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestLevelOf(t *testing.T) {
+	tests := []struct {
+		messages []string
+		want     Level
+	}{
+		{messages: nil, want: None},
+		{messages: []string{"docs: update the readme", "chore: bump the deps", "Update the readme"}, want: None},
+		{messages: []string{"docs: update the readme", "perf: cache the models"}, want: Patch},
+		{messages: []string{"fix: handle the empty diff", "feat: add the semver command"}, want: Minor},
+		{messages: []string{"feat: add the semver command", "chore!: require go 1.23"}, want: Major},
+	}
+	for _, tt := range tests {
+		var commits []Commit
+		for _, m := range tt.messages {
+			commits = append(commits, ParseCommit("", m))
+		}
+		if got := LevelOf(commits); got != tt.want {
+			t.Errorf("LevelOf(%q) = %s, want %s", tt.messages, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package release
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Group is a section of the release notes with the commit types it lists.
+type Group struct {
+	Title string
+	Types []string
+}
+
+// Groups are the sections of the release notes, in order. The breaking changes are
+// listed first, whatever their type, and the commits of the other types last.
+var Groups = []Group{
+	{Title: "Features", Types: []string{"feat"}},
+	{Title: "Bug Fixes", Types: []string{"fix"}},
+	{Title: "Performance Improvements", Types: []string{"perf"}},
+	{Title: "Reverts", Types: []string{"revert"}},
+	{Title: "Code Refactoring", Types: []string{"refactor"}},
+	{Title: "Documentation", Types: []string{"docs"}},
+	{Title: "Tests", Types: []string{"test"}},
+	{Title: "Build System", Types: []string{"build", "ci"}},
+}
+
+// The titles of the sections which don't match a commit type.
+const (
+	BreakingTitle = "⚠ Breaking Changes"
+	OtherTitle    = "Other Changes"
+)
+
+// Notes are the markdown release notes of a version.
+type Notes struct {
+	Version  string
+	Previous string
+	// Date is the day of the release, like 2024-05-01
+	Date string
+	// Highlights is the paragraph written by the model, optional
+	Highlights string
+	Commits    []Commit
+	// RepoURL is the web URL of the repository the commits and the comparison link to, optional
+	RepoURL string
+}
+
+// Render returns the markdown of the release notes: the title, the highlights, the commits
+// grouped by type with their links, the contributors and the link of the full changelog.
+func (n Notes) Render() string {
+	var b strings.Builder
+	b.WriteString("## " + n.Version)
+	if n.Date != "" {
+		b.WriteString(" (" + n.Date + ")")
+	}
+	b.WriteString("\n")
+	if n.Highlights != "" {
+		b.WriteString("\n" + strings.TrimSpace(n.Highlights) + "\n")
+	}
+
+	var breaking []Commit
+	for _, c := range n.Commits {
+		if c.Breaking {
+			breaking = append(breaking, c)
+		}
+	}
+	n.section(&b, BreakingTitle, breaking)
+
+	grouped := map[string]bool{}
+	for _, g := range Groups {
+		var commits []Commit
+		for _, c := range n.Commits {
+			if !c.Breaking && slices.Contains(g.Types, c.Type) {
+				commits = append(commits, c)
+			}
+		}
+		n.section(&b, g.Title, commits)
+		for _, t := range g.Types {
+			grouped[t] = true
+		}
+	}
+	var others []Commit
+	for _, c := range n.Commits {
+		if !c.Breaking && !grouped[c.Type] {
+			others = append(others, c)
+		}
+	}
+	n.section(&b, OtherTitle, others)
+
+	if contributors := Contributors(n.Commits); len(contributors) > 0 {
+		b.WriteString("\n### Contributors\n\nThanks to " + joinNames(contributors) + " for this release.\n")
+	}
+	if n.RepoURL != "" && n.Previous != "" {
+		b.WriteString("\n**Full Changelog**: " + n.RepoURL + "/compare/" + n.Previous + "..." + n.Version + "\n")
+	}
+	return b.String()
+}
+
+// section writes the list of the commits under the title, nothing without commits.
+func (n Notes) section(b *strings.Builder, title string, commits []Commit) {
+	if len(commits) == 0 {
+		return
+	}
+	b.WriteString("\n### " + title + "\n\n")
+	for _, c := range commits {
+		b.WriteString("- ")
+		if c.Scope != "" {
+			b.WriteString("**" + c.Scope + ":** ")
+		}
+		b.WriteString(c.Subject)
+		if c.Hash != "" {
+			short := c.Hash
+			if len(short) > 7 {
+				short = short[:7]
+			}
+			if n.RepoURL != "" {
+				b.WriteString(" ([" + short + "](" + n.RepoURL + "/commit/" + c.Hash + "))")
+			} else {
+				b.WriteString(" (" + short + ")")
+			}
+		}
+		b.WriteString("\n")
+	}
+}
+
+// noreply matches the GitHub noreply emails, like 123+octocat@users.noreply.github.com.
+var noreply = regexp.MustCompile(`^(?:\d+\+)?([A-Za-z0-9-]+)@users\.noreply\.github\.com$`)
+
+// Contributors returns the distinct authors of the commits in order, as a GitHub
+// mention when their email is a GitHub noreply email.
+func Contributors(commits []Commit) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, c := range commits {
+		name := c.Author
+		if m := noreply.FindStringSubmatch(c.Email); m != nil {
+			name = "@" + m[1]
+		}
+		if name == "" || seen[name] || strings.HasSuffix(name, "[bot]") {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+// joinNames joins the names like "a, b and c".
+func joinNames(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// remote matches the URLs of the git remotes: https://host/owner/repo.git,
+// git@host:owner/repo.git or ssh://git@host:22/owner/repo.git.
+var remote = regexp.MustCompile(`^(?:(?:https?|ssh|git)://)?(?:[^@/]+@)?([^:/]+)(?::\d+)?[:/](.+?)(?:\.git)?/?$`)
+
+// RepoURL returns the web URL of the repository of the git remote URL, like
+// https://github.com/appleboy/CodeGPT, empty if it can't be converted.
+func RepoURL(remoteURL string) string {
+	m := remote.FindStringSubmatch(strings.TrimSpace(remoteURL))
+	if m == nil || !strings.Contains(m[2], "/") {
+		return ""
+	}
+	return "https://" + m[1] + "/" + m[2]
+}
@@ -0,0 +1,73 @@
+package release
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	notes := Notes{
+		Version:    "v1.3.0",
+		Previous:   "v1.2.0",
+		Date:       "2024-05-01",
+		Highlights: "The release adds the semver command.",
+		RepoURL:    "https://github.com/appleboy/CodeGPT",
+		Commits: []Commit{
+			{Hash: "1111111aaa", Type: "feat", Scope: "cli", Subject: "add the semver command", Author: "Jane", Email: "1+jane@users.noreply.github.com"},
+			{Hash: "2222222bbb", Type: "fix", Subject: "handle the empty log", Author: "John Doe", Email: "john@example.com"},
+			{Hash: "3333333ccc", Type: "refactor", Subject: "drop the v1 config", Breaking: true, Author: "Jane", Email: "jane@example.com"},
+			{Hash: "4444444ddd", Type: "chore", Subject: "bump the deps", Author: "dependabot[bot]"},
+			{Hash: "5555555eee", Subject: "Update the readme", Author: "John Doe"},
+		},
+	}
+	want := `## v1.3.0 (2024-05-01)
+
+The release adds the semver command.
+
+### ⚠ Breaking Changes
+
+- drop the v1 config ([3333333](https://github.com/appleboy/CodeGPT/commit/3333333ccc))
+
+### Features
+
+- **cli:** add the semver command ([1111111](https://github.com/appleboy/CodeGPT/commit/1111111aaa))
+
+### Bug Fixes
+
+- handle the empty log ([2222222](https://github.com/appleboy/CodeGPT/commit/2222222bbb))
+
+### Other Changes
+
+- bump the deps ([4444444](https://github.com/appleboy/CodeGPT/commit/4444444ddd))
+- Update the readme ([5555555](https://github.com/appleboy/CodeGPT/commit/5555555eee))
+
+### Contributors
+
+Thanks to @jane, John Doe and Jane for this release.
+
+**Full Changelog**: https://github.com/appleboy/CodeGPT/compare/v1.2.0...v1.3.0
+`
+	if got := notes.Render(); got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+
+	notes = Notes{Version: "v0.1.0", Commits: []Commit{{Hash: "abc", Type: "feat", Subject: "first", Author: "Jane"}}}
+	want = "## v0.1.0\n\n### Features\n\n- first (abc)\n\n### Contributors\n\nThanks to Jane for this release.\n"
+	if got := notes.Render(); got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRepoURL(t *testing.T) {
+	tests := map[string]string{
+		"https://github.com/appleboy/CodeGPT.git":        "https://github.com/appleboy/CodeGPT",
+		"https://github.com/appleboy/CodeGPT":            "https://github.com/appleboy/CodeGPT",
+		"git@github.com:appleboy/CodeGPT.git":            "https://github.com/appleboy/CodeGPT",
+		"ssh://git@gitea.example.com:2222/team/app.git":  "https://gitea.example.com/team/app",
+		"https://token@gitlab.com/group/sub/project.git": "https://gitlab.com/group/sub/project",
+		"/srv/git/project.git":                           "",
+		"":                                               "",
+	}
+	for in, want := range tests {
+		if got := RepoURL(in); got != want {
+			t.Errorf("RepoURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
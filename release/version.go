@@ -0,0 +1,119 @@
+package release
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version, like v1.2.3 or 1.2.3-rc.1.
+type Version struct {
+	// Prefix is the text before the numbers, like v
+	Prefix string
+	Major  int
+	Minor  int
+	Patch  int
+	// Pre is the pre-release version after the dash, like rc.1
+	Pre string
+}
+
+// version matches a semantic version with an optional prefix and build metadata.
+var version = regexp.MustCompile(`^([A-Za-z/_-]*?)(\d+)\.(\d+)\.(\d+)(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// ParseVersion parses a semantic version, like the name of a tag.
+func ParseVersion(s string) (Version, error) {
+	m := version.FindStringSubmatch(s)
+	if m == nil {
+		return Version{}, errors.New("not a semantic version: " + s)
+	}
+	v := Version{Prefix: m[1], Pre: m[5]}
+	v.Major, _ = strconv.Atoi(m[2])
+	v.Minor, _ = strconv.Atoi(m[3])
+	v.Patch, _ = strconv.Atoi(m[4])
+	return v, nil
+}
+
+func (v Version) String() string {
+	s := v.Prefix + strconv.Itoa(v.Major) + "." + strconv.Itoa(v.Minor) + "." + strconv.Itoa(v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	return s
+}
+
+// Bump returns the next version of the level. The pre-release of the version is
+// released as is, without bumping it again. Before 1.0.0, a breaking change only
+// bumps the minor version, the public API isn't considered stable yet.
+func (v Version) Bump(level Level) Version {
+	if level == None {
+		return v
+	}
+	next := Version{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	if v.Pre != "" {
+		return next
+	}
+	if level == Major && v.Major == 0 {
+		level = Minor
+	}
+	switch level {
+	case Major:
+		next.Major++
+		next.Minor, next.Patch = 0, 0
+	case Minor:
+		next.Minor++
+		next.Patch = 0
+	default:
+		next.Patch++
+	}
+	return next
+}
+
+// Compare returns -1, 0 or 1 when the version is older, the same or newer than other,
+// with the precedence of the semantic versions: a pre-release is older than its release,
+// and the prefixes are ignored.
+func (v Version) Compare(other Version) int {
+	for _, d := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if d != 0 {
+			return sign(d)
+		}
+	}
+	switch {
+	case v.Pre == other.Pre:
+		return 0
+	case v.Pre == "":
+		return 1
+	case other.Pre == "":
+		return -1
+	}
+
+	a, b := strings.Split(v.Pre, "."), strings.Split(other.Pre, ".")
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] == b[i] {
+			continue
+		}
+		x, errX := strconv.Atoi(a[i])
+		y, errY := strconv.Atoi(b[i])
+		switch {
+		case errX == nil && errY == nil:
+			return sign(x - y)
+		case errX == nil:
+			// the numeric identifiers are older than the alphanumeric ones
+			return -1
+		case errY == nil:
+			return 1
+		}
+		return strings.Compare(a[i], b[i])
+	}
+	return sign(len(a) - len(b))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
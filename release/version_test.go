@@ -0,0 +1,73 @@
+package release
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Version
+	}{
+		{in: "v1.2.3", want: Version{Prefix: "v", Major: 1, Minor: 2, Patch: 3}},
+		{in: "0.10.0", want: Version{Minor: 10}},
+		{in: "v2.0.0-rc.1+build.5", want: Version{Prefix: "v", Major: 2, Pre: "rc.1"}},
+		{in: "release-1.0.1", want: Version{Prefix: "release-", Major: 1, Patch: 1}},
+	}
+	for _, tt := range tests {
+		got, err := ParseVersion(tt.in)
+		if err != nil {
+			t.Fatalf("ParseVersion(%q) error = %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseVersion(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+	for _, in := range []string{"", "latest", "v1.2", "v1.2.3.4"} {
+		if _, err := ParseVersion(in); err == nil {
+			t.Errorf("ParseVersion(%q) accepted an invalid version", in)
+		}
+	}
+}
+
+func TestBump(t *testing.T) {
+	tests := []struct {
+		version string
+		level   Level
+		want    string
+	}{
+		{version: "v1.2.3", level: None, want: "v1.2.3"},
+		{version: "v1.2.3", level: Patch, want: "v1.2.4"},
+		{version: "v1.2.3", level: Minor, want: "v1.3.0"},
+		{version: "v1.2.3", level: Major, want: "v2.0.0"},
+		{version: "v0.4.1", level: Major, want: "v0.5.0"},
+		{version: "v2.0.0-rc.2", level: Patch, want: "v2.0.0"},
+	}
+	for _, tt := range tests {
+		v, _ := ParseVersion(tt.version)
+		if got := v.Bump(tt.level).String(); got != tt.want {
+			t.Errorf("%s.Bump(%s) = %s, want %s", tt.version, tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "v1.2.3", b: "1.2.3", want: 0},
+		{a: "v1.2.3", b: "v1.2.4", want: -1},
+		{a: "v1.10.0", b: "v1.9.9", want: 1},
+		{a: "v2.0.0-rc.1", b: "v2.0.0", want: -1},
+		{a: "v2.0.0-rc.10", b: "v2.0.0-rc.2", want: 1},
+		{a: "v2.0.0-beta", b: "v2.0.0-alpha.1", want: 1},
+		{a: "v2.0.0-rc.1", b: "v2.0.0-rc.1.1", want: -1},
+		{a: "v2.0.0-1", b: "v2.0.0-rc", want: -1},
+	}
+	for _, tt := range tests {
+		a, _ := ParseVersion(tt.a)
+		b, _ := ParseVersion(tt.b)
+		if got := a.Compare(b); got != tt.want {
+			t.Errorf("%s.Compare(%s) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
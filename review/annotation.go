@@ -0,0 +1,100 @@
+package review
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// FormatGitHubActions is the output format of the GitHub Actions workflow commands,
+// shown as annotations of the pull request files and of the checks.
+const FormatGitHubActions = "github-actions"
+
+// The levels of the annotations.
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+	LevelNotice  = "notice"
+)
+
+// Annotation is a GitHub Actions workflow command, like ::error file=app.go,line=10::message.
+// The file and the lines are optional.
+type Annotation struct {
+	Level   string
+	File    string
+	Line    int
+	EndLine int
+	Title   string
+	Message string
+}
+
+// String returns the workflow command of the annotation.
+func (a Annotation) String() string {
+	var props []string
+	if a.File != "" {
+		props = append(props, "file="+escapeProperty(a.File))
+		if a.Line > 0 {
+			props = append(props, "line="+strconv.Itoa(a.Line))
+			if a.EndLine > a.Line {
+				props = append(props, "endLine="+strconv.Itoa(a.EndLine))
+			}
+		}
+	}
+	if a.Title != "" {
+		props = append(props, "title="+escapeProperty(a.Title))
+	}
+	cmd := "::" + a.Level
+	if len(props) > 0 {
+		cmd += " " + strings.Join(props, ",")
+	}
+	return cmd + "::" + escapeData(a.Message)
+}
+
+// escapeData escapes the message of a workflow command.
+func escapeData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// escapeProperty escapes the value of a property of a workflow command.
+func escapeProperty(s string) string {
+	s = escapeData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
+
+// annotationLevel maps the severity of a finding to the level of its annotation.
+func annotationLevel(severity string) string {
+	switch severity {
+	case CRITICAL, HIGH:
+		return LevelError
+	case MEDIUM:
+		return LevelWarning
+	default:
+		return LevelNotice
+	}
+}
+
+// RenderGitHubActions writes the findings as GitHub Actions annotations.
+func RenderGitHubActions(w io.Writer, findings []Finding) error {
+	for _, f := range findings {
+		message := f.Message
+		if f.Suggestion != "" {
+			message += "\n\nSuggestion: " + f.Suggestion
+		}
+		a := Annotation{
+			Level:   annotationLevel(f.Severity),
+			File:    f.File,
+			Line:    f.StartLine,
+			EndLine: f.EndLine,
+			Title:   f.Severity + " " + f.category(),
+			Message: message,
+		}
+		if _, err := fmt.Fprintln(w, a.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package review
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRenderGitHubActions(t *testing.T) {
+	findings, _ := ParseFindings(testResponse)
+
+	var buf bytes.Buffer
+	if err := Render(&buf, FormatGitHubActions, findings); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "::error file=db.go,line=5,title=HIGH security::SQL injection%0A%0ASuggestion: use query parameters\n" +
+		"::notice file=main.go,line=10,endLine=12,title=LOW style::rename variable\n"
+	if got := buf.String(); got != want {
+		t.Errorf("RenderGitHubActions() = %q, want %q", got, want)
+	}
+}
+
+func TestAnnotationString(t *testing.T) {
+	tests := []struct {
+		name string
+		a    Annotation
+		want string
+	}{
+		{
+			name: "message only",
+			a:    Annotation{Level: LevelError, Message: "type must be one of feat, fix"},
+			want: "::error::type must be one of feat, fix",
+		},
+		{
+			name: "line without file",
+			a:    Annotation{Level: LevelWarning, Line: 3, Title: "lint", Message: "100% wrong"},
+			want: "::warning title=lint::100%25 wrong",
+		},
+		{
+			name: "escaped properties",
+			a:    Annotation{Level: LevelNotice, File: "a,b:c.go", Line: 1, Title: "x, y", Message: "a\r\nb"},
+			want: "::notice file=a%2Cb%3Ac.go,line=1,title=x%2C y::a%0D%0Ab",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package review
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/appleboy/CodeGPT/git"
+)
+
+// hunkHeader matches the new file range of a hunk header like @@ -1,3 +4,5 @@.
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// lineRange is a range of lines in the new version of a file.
+type lineRange struct {
+	start, end int
+}
+
+// changedRanges returns the line ranges of the hunks for every file in the diff.
+func changedRanges(diff string) map[string][]lineRange {
+	ranges := map[string][]lineRange{}
+	for _, f := range git.ParsePatch(diff) {
+		for _, h := range f.Hunks {
+			m := hunkHeader.FindStringSubmatch(h.Header)
+			if m == nil {
+				continue
+			}
+			start, _ := strconv.Atoi(m[1])
+			count := 1
+			if m[2] != "" {
+				count, _ = strconv.Atoi(m[2])
+			}
+			if count == 0 {
+				continue
+			}
+			ranges[f.Name()] = append(ranges[f.Name()], lineRange{start, start + count - 1})
+		}
+	}
+	return ranges
+}
+
+// MapToDiff moves the line range of every finding inside the closest hunk of
+// the diff, so the locations point to lines that were actually changed.
+// Findings on files missing from the diff are left untouched.
+func MapToDiff(findings []Finding, diff string) []Finding {
+	ranges := changedRanges(diff)
+	for i := range findings {
+		f := &findings[i]
+		hunks, ok := ranges[f.File]
+		if !ok || len(hunks) == 0 {
+			continue
+		}
+
+		best := hunks[0]
+		bestDistance := -1
+		for _, h := range hunks {
+			if f.StartLine >= h.start && f.StartLine <= h.end {
+				best, bestDistance = h, 0
+				break
+			}
+			distance := h.start - f.StartLine
+			if f.StartLine > h.end {
+				distance = f.StartLine - h.end
+			}
+			if bestDistance < 0 || distance < bestDistance {
+				best, bestDistance = h, distance
+			}
+		}
+
+		if bestDistance == 0 {
+			if f.EndLine > best.end {
+				f.EndLine = best.end
+			}
+			continue
+		}
+
+		// the finding is outside of the changed lines, point to the closest hunk
+		if f.StartLine < best.start {
+			f.StartLine = best.start
+		} else {
+			f.StartLine = best.end
+		}
+		f.EndLine = f.StartLine
+	}
+	return findings
+}
+
+// InDiff reports whether the lines from start to end of the file are all in a hunk of the
+// diff, the code hosting services only accept the inline comments on these lines.
+func InDiff(diff, file string, start, end int) bool {
+	for _, h := range changedRanges(diff)[file] {
+		if start >= h.start && end <= h.end {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package review
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Severity levels of a finding, from the lowest to the highest.
+const (
+	LOW      = "LOW"
+	MEDIUM   = "MEDIUM"
+	HIGH     = "HIGH"
+	CRITICAL = "CRITICAL"
+)
+
+// severityLevels maps the severity names to their rank.
+var severityLevels = map[string]int{
+	LOW:      1,
+	MEDIUM:   2,
+	HIGH:     3,
+	CRITICAL: 4,
+}
+
+// SeverityLevel returns the rank of the given severity, 0 if it is unknown.
+func SeverityLevel(severity string) int {
+	return severityLevels[strings.ToUpper(strings.TrimSpace(severity))]
+}
+
+// ValidSeverity reports whether the given severity is known.
+func ValidSeverity(severity string) bool {
+	return SeverityLevel(severity) > 0
+}
+
+// Review profiles select the prompt of the code review.
+const (
+	ProfileGeneral  = "general"
+	ProfileSecurity = "security"
+)
+
+// Finding is a single issue reported by the code review.
+type Finding struct {
+	File       string `json:"file"`
+	StartLine  int    `json:"start_line"`
+	EndLine    int    `json:"end_line"`
+	Severity   string `json:"severity"`
+	Category   string `json:"category"`
+	OWASP      string `json:"owasp,omitempty"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// severityAliases maps the other severity names the models answer with to the levels.
+var severityAliases = map[string]string{
+	"INFO":          LOW,
+	"INFORMATIONAL": LOW,
+	"MINOR":         LOW,
+	"TRIVIAL":       LOW,
+	"MODERATE":      MEDIUM,
+	"WARNING":       MEDIUM,
+	"MAJOR":         HIGH,
+	"ERROR":         HIGH,
+	"SEVERE":        HIGH,
+	"BLOCKER":       CRITICAL,
+}
+
+// fencedBlock matches a markdown code block, with or without the json language.
+var fencedBlock = regexp.MustCompile("(?s)```(?:json)?(.*?)```")
+
+// ParseFindings parses the JSON array of findings from the model response, from its
+// markdown code block or else the first valid JSON array of findings in the text.
+// A finding with an unknown severity is an error.
+func ParseFindings(content string) ([]Finding, error) {
+	findings, ok := decodeFindings(content)
+	if !ok {
+		return nil, errors.New("no review findings found in the response")
+	}
+
+	for i := range findings {
+		f := &findings[i]
+		severity := strings.ToUpper(strings.TrimSpace(f.Severity))
+		if alias, ok := severityAliases[severity]; ok {
+			severity = alias
+		}
+		if !ValidSeverity(severity) {
+			return nil, fmt.Errorf("unknown severity %q of the finding in %s, must be one of LOW, MEDIUM, HIGH or CRITICAL", f.Severity, f.File)
+		}
+		f.Severity = severity
+		if f.EndLine < f.StartLine {
+			f.EndLine = f.StartLine
+		}
+	}
+
+	// show the most severe findings first
+	sort.SliceStable(findings, func(i, j int) bool {
+		return SeverityLevel(findings[i].Severity) > SeverityLevel(findings[j].Severity)
+	})
+
+	return findings, nil
+}
+
+// CountAtLeast returns the number of findings with the given severity or above.
+func CountAtLeast(findings []Finding, severity string) int {
+	level := SeverityLevel(severity)
+	count := 0
+	for _, f := range findings {
+		if SeverityLevel(f.Severity) >= level {
+			count++
+		}
+	}
+	return count
+}
+
+// decodeFindings decodes the findings of the code blocks of the content first, then the
+// JSON arrays starting at every bracket of the text, the prose around it may have some.
+func decodeFindings(content string) ([]Finding, bool) {
+	for _, m := range fencedBlock.FindAllStringSubmatch(content, -1) {
+		var findings []Finding
+		if err := json.Unmarshal([]byte(strings.TrimSpace(m[1])), &findings); err == nil {
+			return findings, true
+		}
+	}
+
+	for i := strings.Index(content, "["); i >= 0; {
+		var findings []Finding
+		if err := json.NewDecoder(strings.NewReader(content[i:])).Decode(&findings); err == nil {
+			return findings, true
+		}
+		next := strings.Index(content[i+1:], "[")
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return nil, false
+}
@@ -0,0 +1,99 @@
+package review
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const testResponse = "```json\n" + `[
+  {"file": "main.go", "start_line": 10, "end_line": 12, "severity": "low", "category": "style", "message": "rename variable"},
+  {"file": "db.go", "start_line": 5, "severity": "HIGH", "category": "security", "message": "SQL injection", "suggestion": "use query parameters"}
+]` + "\n```"
+
+func TestParseFindings(t *testing.T) {
+	findings, err := ParseFindings(testResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(findings) != 2 {
+		t.Fatalf("ParseFindings() returned %d findings, want 2", len(findings))
+	}
+
+	// the most severe finding comes first
+	if findings[0].Severity != HIGH || findings[0].EndLine != 5 {
+		t.Errorf("ParseFindings()[0] = %+v", findings[0])
+	}
+	if findings[1].Severity != LOW {
+		t.Errorf("ParseFindings()[1].Severity = %q, want LOW", findings[1].Severity)
+	}
+
+	if _, err := ParseFindings("looks good to me"); err == nil {
+		t.Error("ParseFindings() expected error")
+	}
+}
+
+func TestParseFindingsProse(t *testing.T) {
+	content := "The [main] function reads os.Args[1] without a check:\n" +
+		`[{"file": "main.go", "start_line": 6, "severity": "major", "category": "bug", "message": "index out of range"}]` +
+		"\nSee [1] for details."
+	findings, err := ParseFindings(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 1 || findings[0].Severity != HIGH {
+		t.Errorf("ParseFindings() = %+v, want one HIGH finding", findings)
+	}
+
+	if _, err := ParseFindings(`[{"file": "main.go", "severity": "urgent", "message": "typo"}]`); err == nil {
+		t.Error("ParseFindings() expected error for the unknown severity")
+	}
+}
+
+func TestCountAtLeast(t *testing.T) {
+	findings, _ := ParseFindings(testResponse)
+
+	tests := []struct {
+		severity string
+		want     int
+	}{
+		{LOW, 2},
+		{MEDIUM, 1},
+		{"high", 1},
+		{CRITICAL, 0},
+	}
+
+	for _, tt := range tests {
+		if got := CountAtLeast(findings, tt.severity); got != tt.want {
+			t.Errorf("CountAtLeast(%q) = %d, want %d", tt.severity, got, tt.want)
+		}
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	findings, _ := ParseFindings(testResponse)
+
+	var buf bytes.Buffer
+	if err := Render(&buf, FormatMarkdown, findings); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "| HIGH | `db.go` | 5 | security | SQL injection | use query parameters |"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("Render() = %q, want it to contain %q", buf.String(), want)
+	}
+
+	findings[0].OWASP = "A03:2021-Injection"
+	buf.Reset()
+	if err := Render(&buf, FormatMarkdown, findings); err != nil {
+		t.Fatal(err)
+	}
+	if want := "| security (A03:2021-Injection) |"; !strings.Contains(buf.String(), want) {
+		t.Errorf("Render() = %q, want it to contain %q", buf.String(), want)
+	}
+
+	if err := Render(&buf, "xml", findings); err == nil {
+		t.Error("Render() expected error for unsupported format")
+	}
+}
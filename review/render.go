@@ -0,0 +1,138 @@
+package review
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats of the findings.
+const (
+	FormatTable    = "table"
+	FormatMarkdown = "markdown"
+)
+
+// lines returns the line range of the finding like 10 or 10-12.
+func (f Finding) lines() string {
+	if f.StartLine == 0 {
+		return "-"
+	}
+	if f.EndLine > f.StartLine {
+		return strconv.Itoa(f.StartLine) + "-" + strconv.Itoa(f.EndLine)
+	}
+	return strconv.Itoa(f.StartLine)
+}
+
+// category returns the category of the finding with its OWASP category, if any.
+func (f Finding) category() string {
+	if f.OWASP == "" {
+		return f.Category
+	}
+	return f.Category + " (" + f.OWASP + ")"
+}
+
+// RenderTable writes the findings as a plain text table.
+func RenderTable(w io.Writer, findings []Finding) error {
+	if len(findings) == 0 {
+		_, err := fmt.Fprintln(w, "No issues found.")
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SEVERITY\tFILE\tLINES\tCATEGORY\tMESSAGE")
+	for _, f := range findings {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", f.Severity, f.File, f.lines(), f.category(), f.Message)
+		if f.Suggestion != "" {
+			fmt.Fprintf(tw, "\t\t\t\t↳ %s\n", f.Suggestion)
+		}
+	}
+	return tw.Flush()
+}
+
+// escapeCell escapes the characters that break a markdown table cell.
+func escapeCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
+
+// RenderMarkdown writes the findings as a markdown table.
+func RenderMarkdown(w io.Writer, findings []Finding) error {
+	if len(findings) == 0 {
+		_, err := fmt.Fprintln(w, "No issues found.")
+		return err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("| Severity | File | Lines | Category | Message | Suggestion |\n")
+	sb.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, f := range findings {
+		sb.WriteString("| " + f.Severity +
+			" | `" + f.File + "`" +
+			" | " + f.lines() +
+			" | " + escapeCell(f.category()) +
+			" | " + escapeCell(f.Message) +
+			" | " + escapeCell(f.Suggestion) + " |\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// maxSummaryFindings is the maximum number of findings listed in the summary.
+const maxSummaryFindings = 20
+
+// Summary returns a short markdown summary of the findings, with their number by
+// severity and the list of the most severe ones, for the chat notifications.
+func Summary(findings []Finding) string {
+	if len(findings) == 0 {
+		return "No issues found."
+	}
+
+	var counts []string
+	for _, severity := range []string{CRITICAL, HIGH, MEDIUM, LOW} {
+		n := 0
+		for _, f := range findings {
+			if SeverityLevel(f.Severity) == SeverityLevel(severity) {
+				n++
+			}
+		}
+		if n > 0 {
+			counts = append(counts, strconv.Itoa(n)+" "+severity)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Found " + strconv.Itoa(len(findings)) + " issue(s): " + strings.Join(counts, ", ") + ".\n\n")
+	for i, f := range findings {
+		if i == maxSummaryFindings {
+			sb.WriteString("- and " + strconv.Itoa(len(findings)-i) + " more\n")
+			break
+		}
+		location := f.File
+		if f.StartLine > 0 {
+			location += ":" + f.lines()
+		}
+		if location != "" {
+			location = " `" + location + "`"
+		}
+		sb.WriteString("- **" + f.Severity + "**" + location + " " + f.category() + ": " + strings.ReplaceAll(f.Message, "\n", " ") + "\n")
+	}
+	return sb.String()
+}
+
+// Render writes the findings in the given format.
+func Render(w io.Writer, format string, findings []Finding) error {
+	switch format {
+	case FormatTable:
+		return RenderTable(w, findings)
+	case FormatMarkdown:
+		return RenderMarkdown(w, findings)
+	case FormatSARIF:
+		return RenderSARIF(w, findings)
+	case FormatGitHubActions:
+		return RenderGitHubActions(w, findings)
+	default:
+		return fmt.Errorf("unsupported output format: %s", format)
+	}
+}
@@ -0,0 +1,29 @@
+package review
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	findings, _ := ParseFindings(testResponse)
+
+	want := "Found 2 issue(s): 1 HIGH, 1 LOW.\n\n" +
+		"- **HIGH** `db.go:5` security: SQL injection\n" +
+		"- **LOW** `main.go:10-12` style: rename variable\n"
+	if got := Summary(findings); got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+
+	if got := Summary(nil); got != "No issues found." {
+		t.Errorf("Summary(nil) = %q", got)
+	}
+
+	many := make([]Finding, maxSummaryFindings+3)
+	for i := range many {
+		many[i] = Finding{Severity: MEDIUM, Category: "bug", Message: "bug"}
+	}
+	if got := Summary(many); !strings.HasSuffix(got, "- **MEDIUM** bug: bug\n- and 3 more\n") {
+		t.Errorf("Summary() = %q", got)
+	}
+}
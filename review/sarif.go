@@ -0,0 +1,171 @@
+package review
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+)
+
+// FormatSARIF is the SARIF 2.1.0 output format used by GitHub Code Scanning.
+const FormatSARIF = "sarif"
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "CodeGPT"
+	toolURI      = "https://github.com/appleboy/CodeGPT"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+// ruleID returns the SARIF rule ID of the finding category, like codegpt/security.
+func ruleID(category string) string {
+	category = strings.ToLower(strings.TrimSpace(category))
+	if category == "" {
+		category = "general"
+	}
+	return "codegpt/" + strings.ReplaceAll(category, " ", "-")
+}
+
+// sarifLevel maps the severity of a finding to the SARIF result level.
+func sarifLevel(severity string) string {
+	switch severity {
+	case CRITICAL, HIGH:
+		return "error"
+	case MEDIUM:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// RenderSARIF writes the findings as a SARIF 2.1.0 log.
+func RenderSARIF(w io.Writer, findings []Finding) error {
+	rules := map[string]sarifRule{}
+	results := make([]sarifResult, 0, len(findings))
+
+	for _, f := range findings {
+		id := ruleID(f.Category)
+		if _, ok := rules[id]; !ok {
+			name := strings.TrimPrefix(id, "codegpt/")
+			rules[id] = sarifRule{
+				ID:               id,
+				Name:             name,
+				ShortDescription: sarifMessage{Text: "CodeGPT " + name + " finding"},
+			}
+		}
+
+		text := f.Message
+		if f.Suggestion != "" {
+			text += "\n\nSuggestion: " + f.Suggestion
+		}
+
+		location := sarifLocation{
+			PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: f.File},
+			},
+		}
+		if f.StartLine > 0 {
+			location.PhysicalLocation.Region = &sarifRegion{
+				StartLine: f.StartLine,
+				EndLine:   f.EndLine,
+			}
+		}
+
+		properties := map[string]string{
+			"severity": f.Severity,
+		}
+		if f.OWASP != "" {
+			properties["owasp"] = f.OWASP
+		}
+
+		results = append(results, sarifResult{
+			RuleID:     id,
+			Level:      sarifLevel(f.Severity),
+			Message:    sarifMessage{Text: text},
+			Locations:  []sarifLocation{location},
+			Properties: properties,
+		})
+	}
+
+	driver := sarifDriver{
+		Name:           toolName,
+		InformationURI: toolURI,
+		Rules:          make([]sarifRule, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		driver.Rules = append(driver.Rules, rule)
+	}
+	sort.Slice(driver.Rules, func(i, j int) bool {
+		return driver.Rules[i].ID < driver.Rules[j].ID
+	})
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{
+			{
+				Tool:    sarifTool{Driver: driver},
+				Results: results,
+			},
+		},
+	})
+}
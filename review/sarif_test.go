@@ -0,0 +1,68 @@
+package review
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestRenderSARIF(t *testing.T) {
+	findings, _ := ParseFindings(testResponse)
+
+	var buf bytes.Buffer
+	if err := Render(&buf, FormatSARIF, findings); err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+
+	if log.Version != sarifVersion || len(log.Runs) != 1 {
+		t.Fatalf("RenderSARIF() = %+v", log)
+	}
+
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 || len(run.Results) != 2 {
+		t.Fatalf("RenderSARIF() rules = %d, results = %d; want 2, 2", len(run.Tool.Driver.Rules), len(run.Results))
+	}
+
+	result := run.Results[0]
+	if result.RuleID != "codegpt/security" || result.Level != "error" {
+		t.Errorf("RenderSARIF() result = %+v", result)
+	}
+	if region := result.Locations[0].PhysicalLocation.Region; region == nil || region.StartLine != 5 {
+		t.Errorf("RenderSARIF() region = %+v, want start line 5", region)
+	}
+}
+
+func TestMapToDiff(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+index aadf691..bfef603 100644
+--- a/main.go
++++ b/main.go
+@@ -8,3 +8,4 @@ func main() {
+ 	run()
++	exit()
+ }
+@@ -40,2 +41,2 @@ func run() {
+-	a()
++	b()
+`
+
+	findings := []Finding{
+		{File: "main.go", StartLine: 9, EndLine: 20},
+		{File: "main.go", StartLine: 30, EndLine: 31},
+		{File: "main.go", StartLine: 2, EndLine: 2},
+		{File: "other.go", StartLine: 7, EndLine: 7},
+	}
+
+	want := [][2]int{{9, 11}, {41, 41}, {8, 8}, {7, 7}}
+
+	for i, f := range MapToDiff(findings, diff) {
+		if f.StartLine != want[i][0] || f.EndLine != want[i][1] {
+			t.Errorf("MapToDiff()[%d] = %d-%d, want %d-%d", i, f.StartLine, f.EndLine, want[i][0], want[i][1])
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// Package standup collects the commits of a developer across repositories for the
+// notes of the daily or weekly standup meetings.
+package standup
+
+import (
+	"strings"
+	"time"
+
+	"github.com/appleboy/CodeGPT/release"
+)
+
+// The periods of the digest accepted by Since, besides the dates of git.
+const (
+	Today     = "today"
+	Yesterday = "yesterday"
+	Week      = "week"
+)
+
+// dateFormat is the date given to the --since option of git log.
+const dateFormat = "2006-01-02 15:04:05 -0700"
+
+// Since returns the --since date of git log of the period. Yesterday is the start of the
+// previous working day, so the digest of Monday covers the work of Friday, today is the
+// start of the day and week is the start of the day a week ago. The other values, like
+// 2024-05-01 or "3 days ago", are given to git as they are.
+func Since(period string, now time.Time) string {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(strings.TrimSpace(period)) {
+	case Today:
+		return midnight.Format(dateFormat)
+	case Yesterday:
+		day := midnight.AddDate(0, 0, -1)
+		for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			day = day.AddDate(0, 0, -1)
+		}
+		return day.Format(dateFormat)
+	case Week:
+		return midnight.AddDate(0, 0, -7).Format(dateFormat)
+	}
+	return period
+}
+
+// Repo is a repository with the commits of the developer, newest first.
+type Repo struct {
+	Name    string           `json:"name"`
+	Commits []release.Commit `json:"commits"`
+}
+
+// Header returns the header of the commit, like feat(cli): add the standup command.
+func Header(c release.Commit) string {
+	if c.Type == "" {
+		return c.Subject
+	}
+	prefix := c.Type
+	if c.Scope != "" {
+		prefix += "(" + c.Scope + ")"
+	}
+	if c.Breaking {
+		prefix += "!"
+	}
+	return prefix + ": " + c.Subject
+}
+
+// Render returns the markdown list of the commits of every repository with commits,
+// the digest without the summary of the model.
+func Render(repos []Repo) string {
+	var b strings.Builder
+	for _, r := range repos {
+		if len(r.Commits) == 0 {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("### " + r.Name + "\n\n")
+		for _, c := range r.Commits {
+			b.WriteString("- " + Header(c) + " (" + short(c.Hash) + ")\n")
+		}
+	}
+	return b.String()
+}
+
+// Count returns the number of commits of the repositories.
+func Count(repos []Repo) int {
+	n := 0
+	for _, r := range repos {
+		n += len(r.Commits)
+	}
+	return n
+}
+
+// Dedupe removes the commits with the header of a newer commit, like the commits of a
+// branch before it was rebased, keeping the order.
+func Dedupe(commits []release.Commit) []release.Commit {
+	seen := map[string]bool{}
+	out := make([]release.Commit, 0, len(commits))
+	for _, c := range commits {
+		h := Header(c)
+		if seen[h] {
+			continue
+		}
+		seen[h] = true
+		out = append(out, c)
+	}
+	return out
+}
+
+func short(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
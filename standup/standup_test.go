@@ -0,0 +1,77 @@
+package standup
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/appleboy/CodeGPT/release"
+)
+
+func TestSince(t *testing.T) {
+	// a Monday
+	now := time.Date(2024, 5, 6, 9, 30, 0, 0, time.UTC)
+	tests := []struct {
+		period string
+		now    time.Time
+		want   string
+	}{
+		{period: "today", now: now, want: "2024-05-06 00:00:00 +0000"},
+		{period: "yesterday", now: now, want: "2024-05-03 00:00:00 +0000"},
+		{period: "Yesterday", now: now.AddDate(0, 0, 1), want: "2024-05-06 00:00:00 +0000"},
+		{period: "yesterday", now: now.AddDate(0, 0, -1), want: "2024-05-03 00:00:00 +0000"},
+		{period: "week", now: now, want: "2024-04-29 00:00:00 +0000"},
+		{period: "2024-05-01", now: now, want: "2024-05-01"},
+		{period: "3 days ago", now: now, want: "3 days ago"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.period, func(t *testing.T) {
+			if got := Since(tt.period, tt.now); got != tt.want {
+				t.Errorf("Since(%q) = %q, want %q", tt.period, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	repos := []Repo{
+		{Name: "api", Commits: []release.Commit{
+			{Hash: "1111111aaa", Type: "feat", Scope: "auth", Subject: "add the login endpoint"},
+			{Hash: "2222222bbb", Subject: "Update the readme"},
+		}},
+		{Name: "empty"},
+		{Name: "web", Commits: []release.Commit{
+			{Hash: "3333333ccc", Type: "refactor", Subject: "drop the v1 client", Breaking: true},
+		}},
+	}
+	want := `### api
+
+- feat(auth): add the login endpoint (1111111)
+- Update the readme (2222222)
+
+### web
+
+- refactor!: drop the v1 client (3333333)
+`
+	if got := Render(repos); got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+	if got := Count(repos); got != 3 {
+		t.Errorf("Count() = %d, want 3", got)
+	}
+	if got := Render([]Repo{{Name: "empty"}}); got != "" {
+		t.Errorf("Render() = %q, want empty", got)
+	}
+}
+
+func TestDedupe(t *testing.T) {
+	commits := []release.Commit{
+		{Hash: "new", Type: "fix", Subject: "handle the empty log"},
+		{Hash: "other", Type: "feat", Subject: "add the standup command"},
+		{Hash: "old", Type: "fix", Subject: "handle the empty log"},
+	}
+	want := commits[:2]
+	if got := Dedupe(commits); !reflect.DeepEqual(got, want) {
+		t.Errorf("Dedupe() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,294 @@
+// Package style learns the conventions of the commit messages of a repository from
+// their subjects: the tense, the case, the emojis, the scopes and the language.
+package style
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/appleboy/CodeGPT/format"
+)
+
+// The tenses of the subjects, set with prompt.tense.
+const (
+	// TenseImperative is like add the flag.
+	TenseImperative = "imperative"
+	// TensePast is like added the flag.
+	TensePast = "past"
+	// TensePresent is like adds the flag.
+	TensePresent = "present"
+)
+
+// Tenses lists the tenses of the subjects.
+var Tenses = []string{TenseImperative, TensePast, TensePresent}
+
+// maxScopes is the number of the most used scopes of the report.
+const maxScopes = 5
+
+var (
+	// prefix matches the conventional commit prefix of a subject, like feat(cli)!:
+	prefix = regexp.MustCompile(`^([a-z]+)(\(([^)]*)\))?!?:\s*`)
+	// shortcode matches the emoji shortcodes of gitmoji, like :sparkles:
+	shortcode = regexp.MustCompile(`:[a-z0-9_+-]+:`)
+)
+
+// Report is the style of the commit subjects. The shares are from 0 to 1.
+type Report struct {
+	// Commits is the number of the analyzed subjects.
+	Commits int `json:"commits"`
+	// Conventional is the share of the subjects with a conventional commit prefix.
+	Conventional float64 `json:"conventional"`
+	// Scoped is the share of the conventional subjects with a scope.
+	Scoped float64 `json:"scoped"`
+	// Scopes are the most used scopes, the most used first.
+	Scopes []string `json:"scopes,omitempty"`
+	// Emoji is the share of the subjects with an emoji.
+	Emoji float64 `json:"emoji"`
+	// Period is the share of the subjects ending with a period.
+	Period float64 `json:"period"`
+	// Tense is the most used tense of the English subjects.
+	Tense string `json:"tense,omitempty"`
+	// Case is the most used case of the first letter, format.CaseUpper or format.CaseLower.
+	Case string `json:"case,omitempty"`
+	// Language is the code of the most used language, like en or zh-tw.
+	Language string `json:"language,omitempty"`
+}
+
+// Analyze returns the style of the subjects. The empty subjects and the ones of the
+// merges, the reverts and the fixups are skipped.
+func Analyze(subjects []string) Report {
+	var (
+		r                            Report
+		conventional, scoped, emojis int
+		periods, upper, lower        int
+		scopes                       = map[string]int{}
+		tenses                       = map[string]int{}
+		languages                    = map[string]int{}
+	)
+	for _, s := range subjects {
+		s = strings.TrimSpace(s)
+		if s == "" || generated(s) {
+			continue
+		}
+		r.Commits++
+
+		if hasEmoji(s) {
+			emojis++
+		}
+		if strings.HasSuffix(s, ".") {
+			periods++
+		}
+
+		title := stripEmoji(s)
+		if m := prefix.FindStringSubmatch(title); m != nil {
+			conventional++
+			if m[3] != "" {
+				scoped++
+				scopes[m[3]]++
+			}
+			title = title[len(m[0]):]
+		}
+		title = strings.TrimSpace(stripEmoji(title))
+
+		if first, _ := utf8.DecodeRuneInString(title); unicode.IsUpper(first) {
+			upper++
+		} else if unicode.IsLower(first) {
+			lower++
+		}
+
+		lang := Language(title)
+		languages[lang]++
+		if lang == "en" {
+			tenses[tense(title)]++
+		}
+	}
+	if r.Commits == 0 {
+		return r
+	}
+
+	r.Conventional = share(conventional, r.Commits)
+	r.Scoped = share(scoped, conventional)
+	r.Scopes = top(scopes, maxScopes)
+	r.Emoji = share(emojis, r.Commits)
+	r.Period = share(periods, r.Commits)
+	if t := top(tenses, 1); len(t) > 0 {
+		r.Tense = t[0]
+	}
+	if l := top(languages, 1); len(l) > 0 {
+		r.Language = l[0]
+	}
+	switch {
+	case upper > lower:
+		r.Case = format.CaseUpper
+	case lower > upper:
+		r.Case = format.CaseLower
+	}
+	return r
+}
+
+// generated reports whether the subject is the one of a merge, a revert or a fixup.
+func generated(subject string) bool {
+	lower := strings.ToLower(subject)
+	for _, p := range []string{"merge ", "revert \"", "fixup!", "squash!", "amend!"} {
+		if strings.HasPrefix(lower, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// share returns n of total, 0 without total.
+func share(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
+
+// top returns the n keys of the highest counts, the ties in alphabetical order.
+func top(counts map[string]int, n int) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+// isEmoji reports whether the rune is a pictograph or a symbol of the emojis.
+func isEmoji(r rune) bool {
+	return (r >= 0x1F300 && r <= 0x1FAFF) || (r >= 0x2600 && r <= 0x27BF) || (r >= 0x1F000 && r <= 0x1F2FF)
+}
+
+// hasEmoji reports whether the subject has an emoji or a gitmoji shortcode.
+func hasEmoji(s string) bool {
+	return strings.IndexFunc(s, isEmoji) >= 0 || shortcode.MatchString(s)
+}
+
+// stripEmoji removes the emojis, their variation selectors and the shortcodes at the
+// start of the subject.
+func stripEmoji(s string) string {
+	s = strings.TrimLeftFunc(s, func(r rune) bool {
+		return isEmoji(r) || r == 0xFE0F || r == 0x200D || unicode.IsSpace(r)
+	})
+	if loc := shortcode.FindStringIndex(s); loc != nil && loc[0] == 0 {
+		s = strings.TrimSpace(s[loc[1]:])
+	}
+	return s
+}
+
+// notVerbs are the first words ending like a past or present verb which are not.
+var notVerbs = map[string]bool{
+	"this": true, "is": true, "was": true, "has": true, "bugs": true, "docs": true,
+	"tests": true, "deps": true, "status": true, "various": true, "minor": true,
+	"embed": true, "shred": true, "red": true, "bed": true,
+}
+
+// irregularPast are the common irregular verbs of the past tense.
+var irregularPast = map[string]bool{
+	"made": true, "built": true, "wrote": true, "rewrote": true, "took": true, "gave": true,
+	"kept": true, "left": true, "broke": true, "found": true, "got": true, "began": true,
+	"chose": true, "led": true, "lost": true, "sent": true, "brought": true, "caught": true,
+	"held": true, "hid": true, "did": true, "undid": true, "became": true, "ran": true,
+}
+
+// tense returns the tense of the first word of the English title.
+func tense(title string) string {
+	fields := strings.Fields(title)
+	if len(fields) == 0 {
+		return TenseImperative
+	}
+	word := strings.ToLower(strings.TrimFunc(fields[0], func(r rune) bool { return !unicode.IsLetter(r) }))
+	switch {
+	case notVerbs[word]:
+		return TenseImperative
+	case irregularPast[word], len(word) > 4 && strings.HasSuffix(word, "ed") && !strings.HasSuffix(word, "eed"):
+		return TensePast
+	case len(word) > 3 && strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us"):
+		return TensePresent
+	}
+	return TenseImperative
+}
+
+// simplified and traditional are the common Chinese characters which differ in the
+// simplified and the traditional scripts.
+const (
+	simplified  = "这们个为发说对时会后进过还实现开关应该测试复删统无档页据务单级变样构议"
+	traditional = "這們個為發說對時會後進過還實現開關應該測試復刪統無檔頁據務單級變樣構議"
+)
+
+// stopWords are the common short words of the languages written with the Latin script.
+var stopWords = map[string][]string{
+	"en":    {"the", "and", "to", "for", "of", "in", "with", "on", "from", "when", "add", "fix", "update", "remove", "use"},
+	"fr":    {"le", "la", "les", "des", "et", "pour", "du", "une", "dans", "ajout", "ajoute", "correction", "avec"},
+	"de":    {"der", "die", "das", "und", "für", "mit", "nicht", "von", "zu", "hinzugefügt", "behoben"},
+	"es":    {"el", "los", "las", "del", "para", "con", "y", "en", "agregar", "añadir", "corregir"},
+	"it":    {"il", "gli", "della", "per", "con", "e", "di", "aggiunto", "aggiungi", "corretto"},
+	"pt-br": {"o", "os", "da", "do", "para", "com", "não", "adiciona", "adicionar", "corrige"},
+}
+
+// Language returns the code of the language of the text, from the script of its letters
+// and, for the Latin script, from its common words. It's en when nothing else is found.
+func Language(text string) string {
+	var han, kana, hangul, cyrillic, simp, trad int
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Hiragana, r), unicode.Is(unicode.Katakana, r):
+			kana++
+		case unicode.Is(unicode.Han, r):
+			han++
+			if strings.ContainsRune(simplified, r) {
+				simp++
+			} else if strings.ContainsRune(traditional, r) {
+				trad++
+			}
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		}
+	}
+	switch {
+	case kana > 0:
+		return "ja"
+	case hangul > 0:
+		return "ko"
+	case han > 0 && simp > trad:
+		return "zh-cn"
+	case han > 0:
+		return "zh-tw"
+	case cyrillic > 0:
+		return "ru"
+	case strings.ContainsAny(strings.ToLower(text), "ơưđạảấầẩẫậắằẳẵặẹẻẽếềểễệỉịọỏốồổỗộớờởỡợụủứừửữựỳỵỷỹ"):
+		return "vi"
+	}
+
+	words := map[string]bool{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		words[w] = true
+	}
+	best, max := "en", 0
+	for _, lang := range []string{"en", "fr", "de", "es", "it", "pt-br"} {
+		n := 0
+		for _, w := range stopWords[lang] {
+			if words[w] {
+				n++
+			}
+		}
+		if n > max {
+			best, max = lang, n
+		}
+	}
+	return best
+}
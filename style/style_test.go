@@ -0,0 +1,90 @@
+package style
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/appleboy/CodeGPT/format"
+)
+
+func TestAnalyze(t *testing.T) {
+	r := Analyze([]string{
+		"feat(cli): add the style command",
+		"fix(git): handle the empty history",
+		"feat(cli): ✨ list the conventions",
+		"docs: explain the style learning",
+		"Merge branch 'main' into feature",
+		"",
+	})
+	want := Report{
+		Commits:      4,
+		Conventional: 1,
+		Scoped:       0.75,
+		Scopes:       []string{"cli", "git"},
+		Emoji:        0.25,
+		Tense:        TenseImperative,
+		Case:         format.CaseLower,
+		Language:     "en",
+	}
+	if !reflect.DeepEqual(r, want) {
+		t.Errorf("Analyze() = %+v, want %+v", r, want)
+	}
+
+	r = Analyze([]string{
+		"✨ Added the style command.",
+		":bug: Fixed the empty history.",
+		"Updated the docs",
+	})
+	if r.Conventional != 0 || r.Tense != TensePast || r.Case != format.CaseUpper || r.Emoji < 0.6 || r.Period < 0.6 {
+		t.Errorf("Analyze() = %+v", r)
+	}
+
+	if r := Analyze(nil); r.Commits != 0 || r.Language != "" {
+		t.Errorf("Analyze(nil) = %+v", r)
+	}
+}
+
+func TestTense(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"add the flag", TenseImperative},
+		{"Added the flag", TensePast},
+		{"built the docs", TensePast},
+		{"adds the flag", TensePresent},
+		{"embed the templates", TenseImperative},
+		{"proceed with the update", TenseImperative},
+		{"docs update", TenseImperative},
+		{"process the files", TenseImperative},
+	}
+	for _, tt := range tests {
+		if got := tense(tt.title); got != tt.want {
+			t.Errorf("tense(%q) = %s, want %s", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"add the style command", "en"},
+		{"refactor", "en"},
+		{"新增提交訊息的風格設定", "zh-tw"},
+		{"修复这个测试", "zh-cn"},
+		{"コミットのスタイルを追加", "ja"},
+		{"커밋 스타일 추가", "ko"},
+		{"добавить команду", "ru"},
+		{"ajoute la commande pour le style", "fr"},
+		{"Fehler in der Vorschau behoben", "de"},
+		{"corregir el error del historial", "es"},
+		{"sửa lỗi của lịch sử", "vi"},
+	}
+	for _, tt := range tests {
+		if got := Language(tt.text); got != tt.want {
+			t.Errorf("Language(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package symbols
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"regexp"
+)
+
+// generated matches the comment of the generated Go files.
+var generated = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+
+// parseGo returns the functions, methods and types of the Go file. The generated
+// files have no symbols.
+func parseGo(name string, src []byte) ([]symbol, error) {
+	if generated.Match(src) {
+		return nil, nil
+	}
+	fset := token.NewFileSet()
+	// the comments aren't parsed, the doc comments don't modify the symbols
+	file, err := parser.ParseFile(fset, name, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, err
+	}
+	format := func(node any) string {
+		var buf bytes.Buffer
+		_ = printer.Fprint(&buf, fset, node)
+		return buf.String()
+	}
+
+	var out []symbol
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			s := symbol{kind: KindFunc, name: d.Name.Name, code: compact(format(d))}
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				s.kind = KindMethod
+				s.name = receiverName(d.Recv.List[0].Type) + "." + d.Name.Name
+			}
+			s.signature = oneLine(format(&ast.FuncDecl{Recv: d.Recv, Name: d.Name, Type: d.Type}))
+			out = append(out, s)
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				t := spec.(*ast.TypeSpec)
+				out = append(out, symbol{
+					kind:      KindType,
+					name:      t.Name.Name,
+					signature: "type " + typeHeader(t, format),
+					code:      compact(format(t)),
+				})
+			}
+		}
+	}
+	return out, nil
+}
+
+// typeHeader returns the type declaration without the fields of the structs and the
+// methods of the interfaces, like Config struct or ID = string.
+func typeHeader(t *ast.TypeSpec, format func(any) string) string {
+	header := t.Name.Name
+	if t.TypeParams != nil {
+		header = format(&ast.TypeSpec{Name: t.Name, TypeParams: t.TypeParams, Type: &ast.Ident{Name: "_"}})
+		header = header[:len(header)-len(" _")]
+	}
+	if t.Assign.IsValid() {
+		header += " ="
+	}
+	switch t.Type.(type) {
+	case *ast.StructType:
+		return header + " struct"
+	case *ast.InterfaceType:
+		return header + " interface"
+	}
+	return header + " " + oneLine(format(t.Type))
+}
+
+// receiverName returns the type name of the method receiver, without pointer and type parameters.
+func receiverName(t ast.Expr) string {
+	switch e := t.(type) {
+	case *ast.StarExpr:
+		return receiverName(e.X)
+	case *ast.IndexExpr:
+		return receiverName(e.X)
+	case *ast.IndexListExpr:
+		return receiverName(e.X)
+	case *ast.Ident:
+		return e.Name
+	}
+	return ""
+}
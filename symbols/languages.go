@@ -0,0 +1,160 @@
+package symbols
+
+import (
+	"regexp"
+	"strings"
+)
+
+// KindClass is the kind of the classes, the other types are KindType.
+const KindClass = "class"
+
+// jsSyntax is the syntax of JavaScript and TypeScript.
+var jsSyntax = syntax{lineComment: "//", blockComments: true, quotes: "\"'`"}
+
+// jsMatchers find the functions, classes, methods and TypeScript types.
+var jsMatchers = []matcher{
+	{re: regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:abstract\s+)?class\s+([A-Za-z_$][\w$]*)`), kind: KindClass, container: true},
+	{re: regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:declare\s+)?(?:async\s+)?function\s*\*?\s*([A-Za-z_$][\w$]*)`), kind: KindFunc},
+	{re: regexp.MustCompile(`^\s*(?:export\s+)?(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:function\b|(?:\([^)]*\)|[A-Za-z_$][\w$]*)\s*(?::[^=]+)?=>)`), kind: KindFunc, statement: true},
+	{re: regexp.MustCompile(`^\s*(?:export\s+)?(?:declare\s+)?interface\s+([A-Za-z_$][\w$]*)`), kind: KindType},
+	{re: regexp.MustCompile(`^\s*(?:export\s+)?(?:declare\s+)?type\s+([A-Za-z_$][\w$]*)[^=]*=`), kind: KindType, statement: true},
+	{re: regexp.MustCompile(`^\s*(?:export\s+)?(?:declare\s+)?(?:const\s+)?enum\s+([A-Za-z_$][\w$]*)`), kind: KindType},
+	{re: regexp.MustCompile(`^\s*(?:(?:static|async|public|private|protected|readonly|override|abstract|get|set)\s+)*\*?\s*([A-Za-z_$#][\w$]*)\s*(?:<[^>]*>)?\s*\(`), kind: KindMethod, member: true},
+}
+
+// javaSyntax is the syntax of Java.
+var javaSyntax = syntax{lineComment: "//", blockComments: true, quotes: `"`, charLiterals: true}
+
+// javaModifiers are the modifiers of the Java declarations.
+const javaModifiers = `(?:(?:public|protected|private|static|final|abstract|sealed|non-sealed|synchronized|native|default|strictfp)\s+)*`
+
+// javaMatchers find the classes, interfaces, enums, records and their methods.
+var javaMatchers = []matcher{
+	{re: regexp.MustCompile(`^\s*` + javaModifiers + `class\s+(\w+)`), kind: KindClass, container: true, nested: true},
+	{re: regexp.MustCompile(`^\s*` + javaModifiers + `(?:@?interface|enum|record)\s+(\w+)`), kind: KindType, container: true, nested: true},
+	{re: regexp.MustCompile(`^\s*` + javaModifiers + `(?:<[^>]+>\s+)?(?:[\w.]+(?:<[^()]*>)?(?:\[\])*\s+)?(\w+)\s*\(`), kind: KindMethod, member: true},
+}
+
+// rustSyntax is the syntax of Rust.
+var rustSyntax = syntax{lineComment: "//", blockComments: true, quotes: `"`, charLiterals: true}
+
+// rustPrefix matches the visibility and the qualifiers of the Rust items.
+const rustPrefix = `^\s*(?:pub(?:\([^)]*\))?\s+)?(?:default\s+)?(?:const\s+)?(?:async\s+)?(?:unsafe\s+)?(?:extern\s+(?:"[^"]*"\s+)?)?`
+
+// rustMatchers find the functions, the types, the traits and the methods of the impl blocks.
+var rustMatchers = []matcher{
+	{re: regexp.MustCompile(rustPrefix + `fn\s+(\w+)`), kind: KindFunc},
+	{re: regexp.MustCompile(rustPrefix + `(?:struct|enum|union)\s+(\w+)`), kind: KindType},
+	{re: regexp.MustCompile(rustPrefix + `type\s+(\w+)`), kind: KindType, nested: true},
+	{re: regexp.MustCompile(rustPrefix + `trait\s+(\w+)`), kind: KindType, container: true},
+	// the impl blocks aren't symbols, they are the containers of the methods of the type
+	{re: regexp.MustCompile(`^\s*(?:unsafe\s+)?impl\b(?:\s*<[^{]*?>)?\s+(?:[^{]*?\s+for\s+)?(?:[\w:]+::)?(\w+)`), container: true},
+	{re: regexp.MustCompile(rustPrefix + `fn\s+(\w+)`), kind: KindMethod, member: true},
+}
+
+// parseJS returns the declarations of a JavaScript or TypeScript file.
+func parseJS(_ string, src []byte) ([]symbol, error) {
+	return scanBraces(string(src), jsSyntax, jsMatchers), nil
+}
+
+// parseJava returns the declarations of a Java file.
+func parseJava(_ string, src []byte) ([]symbol, error) {
+	return scanBraces(string(src), javaSyntax, javaMatchers), nil
+}
+
+// parseRust returns the declarations of a Rust file.
+func parseRust(_ string, src []byte) ([]symbol, error) {
+	return scanBraces(string(src), rustSyntax, rustMatchers), nil
+}
+
+// keywords are the statements which look like the calls of the methods.
+var keywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true, "return": true,
+	"new": true, "else": true, "do": true, "try": true, "throw": true, "super": true, "this": true,
+	"function": true, "synchronized": true,
+}
+
+// pythonSyntax is the syntax of Python.
+var pythonSyntax = syntax{lineComment: "#", quotes: `"'`, tripleQuotes: true}
+
+// pythonDef matches the definitions of the Python functions and classes.
+var pythonDef = regexp.MustCompile(`^([ \t]*)(?:async\s+)?(def|class)\s+([A-Za-z_]\w*)`)
+
+// parsePython returns the functions, the classes and the methods of the top-level classes
+// of a Python file. The nested functions are part of their function.
+func parsePython(_ string, src []byte) ([]symbol, error) {
+	code, masked := mask(string(src), pythonSyntax)
+	codeLines := strings.Split(code, "\n")
+	lines := strings.Split(masked, "\n")
+
+	var (
+		out   []symbol
+		class string
+		// classIndent is the indentation of the class being scanned
+		classIndent = -1
+	)
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if classIndent >= 0 && indent <= classIndent {
+			class, classIndent = "", -1
+		}
+		m := pythonDef.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		// the signature ends at the colon of the line closing the parentheses
+		end := i
+		for depth := 0; end < len(lines); end++ {
+			depth += strings.Count(lines[end], "(") - strings.Count(lines[end], ")")
+			if depth <= 0 {
+				break
+			}
+		}
+		end = min(end, len(lines)-1)
+		// the body is the following lines with a deeper indentation
+		last := end
+		for j := end + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == "" {
+				continue
+			}
+			if len(lines[j])-len(strings.TrimLeft(lines[j], " \t")) <= indent {
+				break
+			}
+			last = j
+		}
+
+		signature := oneLine(strings.Join(codeLines[i:end+1], " "))
+		if colon := strings.LastIndex(signature, ":"); colon > 0 && m[2] == "def" {
+			signature = strings.TrimSpace(signature[:colon])
+		}
+		signature = strings.TrimSuffix(signature, ":")
+		s := symbol{kind: KindFunc, name: m[3], signature: signature}
+
+		switch {
+		case m[2] == "class" && class != "":
+			// the inner classes are part of the class
+			s.kind = KindClass
+			s.name = class + "." + m[3]
+		case m[2] == "class":
+			s.kind = KindClass
+			s.code = compact(signature)
+			class, classIndent = m[3], indent
+			out = append(out, s)
+			// the methods of the class are scanned
+			i = end
+			continue
+		case class != "":
+			s.kind = KindMethod
+			s.name = class + "." + m[3]
+		}
+		s.code = compact(strings.Join(codeLines[i:last+1], "\n"))
+		out = append(out, s)
+		i = last
+	}
+	return out, nil
+}
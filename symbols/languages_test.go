@@ -0,0 +1,167 @@
+package symbols
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLanguages(t *testing.T) {
+	tests := []struct {
+		file string
+		src  string
+		want []string
+	}{
+		{
+			file: "store.ts",
+			src: "import x from 'y'\n// function fake() {}\n" +
+				"export default class Store<T> extends Base {\n  private items: T[] = []\n" +
+				"  static create(): Store<any> {\n    if (x) { return new Store() }\n  }\n" +
+				"  async load(id: string,\n    opts?: {force: boolean}): Promise<void> {\n    const s = `a ${b} }`\n  }\n" +
+				"}\n\n" +
+				"export function parse(s: string): number {\n  function inner() {}\n  return 1\n}\n" +
+				"export const add = (a: number, b: number) => a + b\n" +
+				"const run = async () => {\n  await x()\n}\n" +
+				"export interface Props {\n  name: string\n}\n" +
+				"export type ID = string | number\n" +
+				"enum Color { Red, Green }\n",
+			want: []string{
+				"class Store: export default class Store<T> extends Base",
+				"method Store.create: static create(): Store<any>",
+				"method Store.load: async load(id: string, opts?: {force: boolean}): Promise<void>",
+				"func parse: export function parse(s: string): number",
+				"func add: export const add = (a: number, b: number) => a + b",
+				"func run: const run = async () =>",
+				"type Props: export interface Props",
+				"type ID: export type ID = string | number",
+				"type Color: enum Color",
+			},
+		},
+		{
+			file: "UserService.java",
+			src: "package a;\n\n@Service\npublic class UserService extends Base {\n" +
+				"  private final Repo repo = new Repo();\n" +
+				"  @Override\n  public List<User> find(String name, int limit) throws IOException {\n" +
+				"    if (x) { return null; }\n    char c = '}';\n    return repo.find(name);\n  }\n" +
+				"  public UserService(Repo r) { this.repo = r; }\n" +
+				"  static class Inner {\n    void run() {}\n  }\n" +
+				"  interface Listener { void on(Event e); }\n}\n",
+			want: []string{
+				"class UserService: public class UserService extends Base",
+				"method UserService.find: public List<User> find(String name, int limit) throws IOException",
+				"method UserService.UserService: public UserService(Repo r)",
+				"class UserService.Inner: static class Inner",
+				"method UserService.Inner.run: void run()",
+				"type UserService.Listener: interface Listener",
+				"method UserService.Listener.on: void on(Event e)",
+			},
+		},
+		{
+			file: "lib.rs",
+			src: "use std::fmt;\n\npub struct Point<'a> {\n    x: &'a str,\n}\n\n" +
+				"impl<'a> fmt::Display for Point<'a> {\n    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {\n" +
+				"        let c = '{';\n        write!(f, \"{}\", self.x)\n    }\n}\n\n" +
+				"pub trait Shape {\n    type Unit;\n    fn area(&self) -> f64;\n}\n\n" +
+				"pub(crate) async fn fetch<T>(url: &str) -> Result<T, Error>\nwhere\n    T: Clone,\n{\n    todo!()\n}\n\n" +
+				"pub enum Kind { A, B }\ntype Alias = Vec<u8>;\n",
+			want: []string{
+				"type Point: pub struct Point<'a>",
+				"method Point.fmt: fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result",
+				"type Shape: pub trait Shape",
+				"type Shape.Unit: type Unit",
+				"method Shape.area: fn area(&self) -> f64",
+				"func fetch: pub(crate) async fn fetch<T>(url: &str) -> Result<T, Error> where T: Clone,",
+				"type Kind: pub enum Kind",
+				"type Alias: type Alias = Vec<u8>",
+			},
+		},
+		{
+			file: "greeter.py",
+			src: "import os\n\n\ndef top(a: int, b: str = \"x:\") -> int:\n    \"\"\"doc: with colon\"\"\"\n" +
+				"    def inner():\n        pass\n    return a\n\n\n" +
+				"class Greeter(Base):\n    name = 'x'\n\n    def __init__(self, name):\n        self.name = name\n\n" +
+				"    async def greet(self,\n                    loud=False):\n        return self.name\n\n" +
+				"    class Meta:\n        pass\n\n\ndef after(): pass\n",
+			want: []string{
+				"func top: def top(a: int, b: str = \"x:\") -> int",
+				"class Greeter: class Greeter(Base)",
+				"method Greeter.__init__: def __init__(self, name)",
+				"method Greeter.greet: async def greet(self, loud=False)",
+				"class Greeter.Meta: class Meta",
+				"func after: def after()",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			symbols, err := parserOf(tt.file)(tt.file, []byte(tt.src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, s := range symbols {
+				got = append(got, s.kind+" "+s.name+": "+s.signature)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("symbols =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLanguages(t *testing.T) {
+	tests := []struct {
+		file          string
+		before, after string
+		want          []string
+	}{
+		{
+			file:   "app.js",
+			before: "class App {\n  start() {\n    listen(80) // the port\n  }\n  stop() {}\n}\n",
+			after:  "class App extends Base {\n  start() {\n    listen(80) // the HTTP port\n  }\n  stop(force) {}\n}\n",
+			want: []string{
+				"modified class App in app.js: class App → class App extends Base",
+				"modified method App.stop in app.js: stop() → stop(force)",
+			},
+		},
+		{
+			file:   "Main.java",
+			before: "class Main {\n  void run() { a(); }\n}\n",
+			after:  "class Main {\n  void run() { b(); }\n  void stop() {}\n}\n",
+			want: []string{
+				"modified void run() in Main.java",
+				"added void stop() in Main.java",
+			},
+		},
+		{
+			file:   "main.py",
+			before: "def a():\n    return 1\n\n\ndef b():\n    pass\n",
+			after:  "def a():\n    # a comment doesn't modify it\n    return 2\n",
+			want: []string{
+				"modified def a() in main.py",
+				"removed def b() in main.py",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			changes, err := Diff(tt.file, []byte(tt.before), []byte(tt.after))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Lines(changes, 0); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMask(t *testing.T) {
+	src := "a = \"x // y\" // c\nb = '}' /* d\n*/ e"
+	code, masked := mask(src, syntax{lineComment: "//", blockComments: true, quotes: `"`, charLiterals: true})
+	if want := "a = \"x // y\"     \nb = '}'     \n   e"; code != want {
+		t.Errorf("mask() code = %q, want %q", code, want)
+	}
+	if want := "a = \"      \"     \nb = ' '     \n   e"; masked != want {
+		t.Errorf("mask() masked = %q, want %q", masked, want)
+	}
+}
@@ -0,0 +1,254 @@
+package symbols
+
+import (
+	"regexp"
+	"strings"
+)
+
+// syntax is the lexical syntax of a language, to find the comments and the strings.
+type syntax struct {
+	// lineComment starts a comment up to the end of the line, like // or #
+	lineComment string
+	// blockComments are enclosed in /* and */
+	blockComments bool
+	// quotes are the delimiters of the strings, like " or `
+	quotes string
+	// charLiterals are the 'x' literals of Java and Rust, the other quotes are lifetimes in Rust
+	charLiterals bool
+	// tripleQuotes are the """ and ''' strings of Python
+	tripleQuotes bool
+}
+
+// charLiteral matches a character literal at the start of the text, like 'a' or '\n'.
+var charLiteral = regexp.MustCompile(`^'(?:\\(?:u\{[0-9a-fA-F]+\}|x[0-9a-fA-F]{2}|.)|[^\\'\n])'`)
+
+// mask returns the source without the comments, and the source without the comments and
+// the content of the strings, to find the declarations and the blocks. The comments and
+// the strings are replaced by spaces so the offsets and the lines don't change.
+func mask(src string, s syntax) (string, string) {
+	code := []byte(src)
+	masked := []byte(src)
+	blank := func(buf []byte, from, to int) {
+		for i := from; i < to && i < len(buf); i++ {
+			if buf[i] != '\n' {
+				buf[i] = ' '
+			}
+		}
+	}
+
+	for i := 0; i < len(src); {
+		rest := src[i:]
+		switch {
+		case s.lineComment != "" && strings.HasPrefix(rest, s.lineComment):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			blank(code, i, i+end)
+			blank(masked, i, i+end)
+			i += end
+		case s.blockComments && strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 4
+			}
+			blank(code, i, i+end)
+			blank(masked, i, i+end)
+			i += end
+		case s.tripleQuotes && (strings.HasPrefix(rest, `"""`) || strings.HasPrefix(rest, `'''`)):
+			end := strings.Index(rest[3:], rest[:3])
+			if end < 0 {
+				end = len(rest) - 3
+			}
+			blank(masked, i+3, i+3+end)
+			i += end + 6
+		case s.charLiterals && rest[0] == '\'':
+			if m := charLiteral.FindString(rest); m != "" {
+				blank(masked, i+1, i+len(m)-1)
+				i += len(m)
+				continue
+			}
+			i++
+		case strings.IndexByte(s.quotes, rest[0]) >= 0:
+			end := stringEnd(rest)
+			blank(masked, i+1, i+end-1)
+			i += end
+		default:
+			i++
+		}
+	}
+	return string(code), string(masked)
+}
+
+// stringEnd returns the length of the string literal at the start of the text, up to the
+// end of the line for the unterminated strings other than the template literals.
+func stringEnd(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		case '\n':
+			if quote != '`' {
+				return i
+			}
+		}
+	}
+	return len(text)
+}
+
+// declaration is a declaration found in the masked source.
+type declaration struct {
+	kind string
+	name string
+	// start is the offset of the declaration
+	start int
+	// statement declarations, like the type aliases, may end at the end of the line
+	statement bool
+	// container declarations, like the classes, have member declarations. Only their
+	// header is compared, the members are compared on their own
+	container bool
+}
+
+// extent returns the end of the signature of the declaration, at its block or at the
+// semicolon ending it, and the end of the declaration.
+func extent(masked string, d declaration) (int, int) {
+	depth := 0
+	for i := d.start; i < len(masked); i++ {
+		switch c := masked[i]; c {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case '{':
+			if depth <= 0 {
+				return i, blockEnd(masked, i)
+			}
+		case ';':
+			if depth <= 0 {
+				return i, i + 1
+			}
+		case '\n':
+			if depth > 0 || !d.statement {
+				continue
+			}
+			line := strings.TrimSpace(masked[lineStart(masked, i):i])
+			next := strings.TrimLeft(masked[i:], " \t\r\n")
+			if strings.HasPrefix(next, "{") || strings.HasSuffix(line, "=>") || strings.ContainsAny(line[max(len(line)-1, 0):], "=,(|&:+") {
+				continue
+			}
+			return i, i
+		}
+	}
+	return len(masked), len(masked)
+}
+
+// blockEnd returns the offset after the brace closing the block opened at the offset.
+func blockEnd(masked string, open int) int {
+	depth := 0
+	for i := open; i < len(masked); i++ {
+		switch masked[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(masked)
+}
+
+// lineStart returns the offset of the start of the line of the offset.
+func lineStart(text string, offset int) int {
+	return strings.LastIndexByte(text[:offset], '\n') + 1
+}
+
+// matcher finds a declaration in a line of the masked source.
+type matcher struct {
+	re   *regexp.Regexp
+	kind string
+	// member declarations are only found in the containers, the other ones outside of them
+	member bool
+	// nested declarations are found both in and outside of the containers, like the inner classes
+	nested    bool
+	statement bool
+	container bool
+}
+
+// container is a container declaration being scanned, like a class.
+type container struct {
+	name string
+	end  int
+}
+
+// scanBraces returns the declarations of the source of a language with blocks in braces.
+// The first group of the regular expressions is the name of the declaration. The
+// declarations inside the functions, like the nested functions, are skipped.
+func scanBraces(src string, s syntax, matchers []matcher) []symbol {
+	code, masked := mask(src, s)
+
+	var (
+		out   []symbol
+		stack []container
+	)
+	for offset := 0; offset < len(masked); {
+		for len(stack) > 0 && offset >= stack[len(stack)-1].end {
+			stack = stack[:len(stack)-1]
+		}
+		eol := strings.IndexByte(masked[offset:], '\n')
+		if eol < 0 {
+			eol = len(masked) - offset
+		}
+		line := masked[offset : offset+eol]
+		next := offset + eol + 1
+
+		for _, m := range matchers {
+			if !m.nested && m.member != (len(stack) > 0) {
+				continue
+			}
+			match := m.re.FindStringSubmatchIndex(line)
+			if match == nil || keywords[line[match[2]:match[3]]] {
+				continue
+			}
+			d := declaration{
+				kind:      m.kind,
+				name:      line[match[2]:match[3]],
+				start:     offset + strings.Index(line, strings.TrimLeft(line, " \t")),
+				statement: m.statement,
+				container: m.container,
+			}
+			sig, end := extent(masked, d)
+			sym := symbol{
+				kind:      d.kind,
+				name:      d.name,
+				signature: oneLine(code[d.start:sig]),
+			}
+			if len(stack) > 0 {
+				sym.name = stack[len(stack)-1].name + "." + sym.name
+			}
+			if d.container {
+				sym.code = compact(sym.signature)
+				stack = append(stack, container{name: sym.name, end: end})
+				// the members start in the block
+				if sig < len(masked) && masked[sig] == '{' {
+					next = sig + 1
+				}
+			} else {
+				sym.code = compact(code[d.start:end])
+				next = max(next, end)
+			}
+			if sym.kind != "" {
+				out = append(out, sym)
+			}
+			break
+		}
+		offset = next
+	}
+	return out
+}
@@ -0,0 +1,159 @@
+// Package symbols finds the functions, methods and types changed by a diff from the
+// content of the source files before and after the changes, so the prompts name the
+// real symbols instead of guessing them from the hunks. The Go files are parsed with
+// go/parser, the JavaScript, TypeScript, Python, Java and Rust files with a scanner of
+// their declarations which skips the comments and the strings.
+package symbols
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// The actions of the changes.
+const (
+	Added    = "added"
+	Modified = "modified"
+	Removed  = "removed"
+)
+
+// The kinds of the symbols.
+const (
+	KindFunc   = "func"
+	KindMethod = "method"
+	KindType   = "type"
+)
+
+// Change is a symbol added, modified or removed by the changes.
+type Change struct {
+	File   string `json:"file"`
+	Action string `json:"action"`
+	Kind   string `json:"kind"`
+	// Name is the name of the symbol, Type.Method for the methods
+	Name string `json:"name"`
+	// Signature is the declaration without the body, the old one for a removed symbol
+	Signature string `json:"signature"`
+	// OldSignature is the signature before the changes when they change it
+	OldSignature string `json:"old_signature,omitempty"`
+}
+
+// String describes the change, like: modified func Parse(s string) (int, error) in util/parse.go
+func (c Change) String() string {
+	if c.OldSignature != "" {
+		return c.Action + " " + c.Kind + " " + c.Name + " in " + c.File + ": " + c.OldSignature + " → " + c.Signature
+	}
+	return c.Action + " " + c.Signature + " in " + c.File
+}
+
+// symbol is a declaration of a source file.
+type symbol struct {
+	kind      string
+	name      string
+	signature string
+	// code is the declaration with its body without spaces, to find the modifications
+	code string
+}
+
+// parseFunc returns the declarations of the source file.
+type parseFunc func(name string, src []byte) ([]symbol, error)
+
+// parserOf returns the parser of the language of the file, nil if it isn't supported.
+func parserOf(name string) parseFunc {
+	switch filepath.Ext(name) {
+	case ".go":
+		return parseGo
+	case ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".mts", ".cts":
+		return parseJS
+	case ".py":
+		return parsePython
+	case ".java":
+		return parseJava
+	case ".rs":
+		return parseRust
+	}
+	return nil
+}
+
+// Supported reports whether the symbols of the file can be found.
+func Supported(name string) bool {
+	return parserOf(name) != nil
+}
+
+// Diff returns the symbols added, modified and removed by the changes of the file, from
+// its content before and after them, nil for an added or a deleted file. The changes are
+// in the order of the new file, then the removed symbols in the order of the old file.
+func Diff(name string, before, after []byte) ([]Change, error) {
+	parse := parserOf(name)
+	if parse == nil {
+		return nil, nil
+	}
+	var oldSymbols, newSymbols []symbol
+	var err error
+	if before != nil {
+		if oldSymbols, err = parse(name, before); err != nil {
+			return nil, err
+		}
+	}
+	if after != nil {
+		if newSymbols, err = parse(name, after); err != nil {
+			return nil, err
+		}
+	}
+	return compare(name, oldSymbols, newSymbols), nil
+}
+
+func compare(name string, oldSymbols, newSymbols []symbol) []Change {
+	key := func(s symbol) string {
+		return s.kind + " " + s.name
+	}
+	old := make(map[string]symbol, len(oldSymbols))
+	for _, s := range oldSymbols {
+		old[key(s)] = s
+	}
+	current := make(map[string]bool, len(newSymbols))
+
+	var changes []Change
+	for _, s := range newSymbols {
+		current[key(s)] = true
+		prev, ok := old[key(s)]
+		switch {
+		case !ok:
+			changes = append(changes, Change{File: name, Action: Added, Kind: s.kind, Name: s.name, Signature: s.signature})
+		case prev.signature != s.signature:
+			changes = append(changes, Change{File: name, Action: Modified, Kind: s.kind, Name: s.name, Signature: s.signature, OldSignature: prev.signature})
+		case prev.code != s.code:
+			changes = append(changes, Change{File: name, Action: Modified, Kind: s.kind, Name: s.name, Signature: s.signature})
+		}
+	}
+	for _, s := range oldSymbols {
+		if !current[key(s)] {
+			changes = append(changes, Change{File: name, Action: Removed, Kind: s.kind, Name: s.name, Signature: s.signature})
+		}
+	}
+	return changes
+}
+
+// Lines returns the descriptions of the changes, at most limit of them with a last line
+// counting the others when limit is positive.
+func Lines(changes []Change, limit int) []string {
+	out := make([]string, 0, len(changes))
+	for i, c := range changes {
+		if limit > 0 && i == limit {
+			out = append(out, "and "+strconv.Itoa(len(changes)-i)+" more")
+			break
+		}
+		out = append(out, c.String())
+	}
+	return out
+}
+
+// oneLine joins the lines of the declaration and removes the extra spaces.
+func oneLine(code string) string {
+	return strings.Join(strings.Fields(code), " ")
+}
+
+// compact removes the spaces of the declaration, the formatting doesn't modify it.
+func compact(code string) string {
+	return strings.Join(strings.Fields(code), "")
+}
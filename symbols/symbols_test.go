@@ -0,0 +1,142 @@
+package symbols
+
+import (
+	"reflect"
+	"testing"
+)
+
+const oldGo = `package util
+
+// Parse parses the value.
+func Parse(s string) int {
+	return len(s)
+}
+
+func Keep() {}
+
+func Touch() {
+	println("a")
+}
+
+type Config struct {
+	Name string
+}
+
+type ID string
+
+type Client struct{}
+
+func (c *Client) Close() error { return nil }
+
+func Removed() {}
+`
+
+const newGo = `package util
+
+// Parse parses the value, with a new doc comment.
+func Parse(s string) (int, error) {
+	return len(s), nil
+}
+
+func Keep() {
+}
+
+func Touch() {
+	// a comment only change doesn't count
+	println("b")
+}
+
+type Config struct {
+	Name string
+	Port int
+}
+
+type ID string
+
+type Set[T comparable] map[T]struct{}
+
+type Client struct{}
+
+func (c *Client) Close() error { return nil }
+
+func (c Client) Dial(addr string) error { return nil }
+`
+
+func TestDiff(t *testing.T) {
+	got, err := Diff("util/parse.go", []byte(oldGo), []byte(newGo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Change{
+		{File: "util/parse.go", Action: Modified, Kind: KindFunc, Name: "Parse", Signature: "func Parse(s string) (int, error)", OldSignature: "func Parse(s string) int"},
+		{File: "util/parse.go", Action: Modified, Kind: KindFunc, Name: "Touch", Signature: "func Touch()"},
+		{File: "util/parse.go", Action: Modified, Kind: KindType, Name: "Config", Signature: "type Config struct"},
+		{File: "util/parse.go", Action: Added, Kind: KindType, Name: "Set", Signature: "type Set[T comparable] map[T]struct{}"},
+		{File: "util/parse.go", Action: Added, Kind: KindMethod, Name: "Client.Dial", Signature: "func (c Client) Dial(addr string) error"},
+		{File: "util/parse.go", Action: Removed, Kind: KindFunc, Name: "Removed", Signature: "func Removed()"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestDiffAddedAndDeletedFiles(t *testing.T) {
+	src := []byte("package a\n\ntype Alias = string\n\nfunc New() *T { return nil }\n")
+	added, err := Diff("a.go", nil, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 2 || added[0].Action != Added || added[0].Signature != "type Alias = string" {
+		t.Errorf("Diff() of an added file = %+v", added)
+	}
+	removed, err := Diff("a.go", src, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 2 || removed[1].Action != Removed || removed[1].Signature != "func New() *T" {
+		t.Errorf("Diff() of a deleted file = %+v", removed)
+	}
+}
+
+func TestDiffSkipped(t *testing.T) {
+	generated := []byte("// Code generated by protoc-gen-go. DO NOT EDIT.\n\npackage pb\n\nfunc New() {}\n")
+	tests := []struct {
+		name   string
+		file   string
+		before []byte
+		after  []byte
+	}{
+		{name: "unsupported language", file: "README.md", after: []byte("# title")},
+		{name: "generated file", file: "api.pb.go", after: generated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Diff(tt.file, tt.before, tt.after)
+			if err != nil || got != nil {
+				t.Errorf("Diff() = %+v, %v, want nil", got, err)
+			}
+		})
+	}
+	if _, err := Diff("bad.go", nil, []byte("package a\nfunc {")); err == nil {
+		t.Error("Diff() error = nil, want the syntax error")
+	}
+}
+
+func TestLines(t *testing.T) {
+	changes := []Change{
+		{File: "a.go", Action: Added, Kind: KindFunc, Name: "A", Signature: "func A()"},
+		{File: "a.go", Action: Modified, Kind: KindFunc, Name: "B", Signature: "func B(n int)", OldSignature: "func B()"},
+		{File: "b.go", Action: Removed, Kind: KindType, Name: "C", Signature: "type C int"},
+	}
+	want := []string{
+		"added func A() in a.go",
+		"modified func B in a.go: func B() → func B(n int)",
+		"and 1 more",
+	}
+	if got := Lines(changes, 2); !reflect.DeepEqual(got, want) {
+		t.Errorf("Lines() = %q, want %q", got, want)
+	}
+	if got := Lines(changes, 0); len(got) != 3 {
+		t.Errorf("Lines() = %q, want 3 lines", got)
+	}
+}
@@ -0,0 +1,236 @@
+package testgen
+
+import (
+	"bytes"
+	"errors"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strings"
+)
+
+// Func is a function or a method of a Go file to test.
+type Func struct {
+	// Name is the name of the function, Type.Method for the methods
+	Name string `json:"name"`
+	Line int    `json:"line"`
+	Code string `json:"code"`
+}
+
+// TestFile returns the name of the test file of the Go file, like git_test.go for git.go.
+func TestFile(name string) string {
+	return strings.TrimSuffix(name, ".go") + "_test.go"
+}
+
+// Package returns the package name of the Go file.
+func Package(src []byte) (string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, parser.PackageClauseOnly)
+	if err != nil {
+		return "", err
+	}
+	return file.Name.Name, nil
+}
+
+// ChangedFuncs returns the functions and methods of the Go file with one of the lines,
+// all of them when lines is nil. The main and init functions aren't returned.
+func ChangedFuncs(name string, src []byte, lines []int) ([]Func, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	var funcs []Func
+	for _, decl := range file.Decls {
+		d, ok := decl.(*ast.FuncDecl)
+		if !ok || d.Body == nil || (d.Recv == nil && (d.Name.Name == "main" || d.Name.Name == "init")) {
+			continue
+		}
+		start, end := fset.Position(d.Pos()), fset.Position(d.End())
+		if lines != nil && !slices.ContainsFunc(lines, func(l int) bool { return l >= start.Line && l <= end.Line }) {
+			continue
+		}
+		name := d.Name.Name
+		if d.Recv != nil && len(d.Recv.List) > 0 {
+			name = receiverName(d.Recv.List[0].Type) + "." + name
+		}
+		funcs = append(funcs, Func{
+			Name: name,
+			Line: start.Line,
+			Code: string(src[start.Offset:end.Offset]),
+		})
+	}
+	return funcs, nil
+}
+
+// receiverName returns the type name of the receiver of a method, like Command for *Command.
+func receiverName(t ast.Expr) string {
+	switch x := t.(type) {
+	case *ast.StarExpr:
+		return receiverName(x.X)
+	case *ast.IndexExpr:
+		return receiverName(x.X)
+	case *ast.IndexListExpr:
+		return receiverName(x.X)
+	case *ast.Ident:
+		return x.Name
+	}
+	return ""
+}
+
+// Clean returns the Go code of the model response, without the markdown code fences.
+func Clean(content string) string {
+	content = strings.TrimSpace(content)
+	if i := strings.Index(content, "```"); i >= 0 {
+		content = content[i+3:]
+		content = strings.TrimPrefix(content, "go")
+		if j := strings.LastIndex(content, "```"); j >= 0 {
+			content = content[:j]
+		}
+	}
+	return strings.TrimSpace(content) + "\n"
+}
+
+// Merge adds the declarations and the missing imports of the generated test file to the
+// existing one, and returns the formatted result with the names of the functions skipped
+// because the existing file already declares them. The generated file is returned
+// formatted when there's no existing file.
+func Merge(existing, generated []byte) ([]byte, []string, error) {
+	fset := token.NewFileSet()
+	gen, err := parser.ParseFile(fset, "generated_test.go", generated, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(bytes.TrimSpace(existing)) == 0 {
+		out, err := format.Source(generated)
+		return out, nil, err
+	}
+	old, err := parser.ParseFile(token.NewFileSet(), "existing_test.go", existing, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+	if old.Name.Name != gen.Name.Name {
+		return nil, nil, errors.New("the generated tests are in package " + gen.Name.Name + ", not " + old.Name.Name)
+	}
+
+	declared := map[string]bool{}
+	for _, decl := range old.Decls {
+		if d, ok := decl.(*ast.FuncDecl); ok && d.Recv == nil {
+			declared[d.Name.Name] = true
+		}
+	}
+
+	var (
+		decls   []string
+		skipped []string
+	)
+	for _, decl := range gen.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT {
+				continue
+			}
+		case *ast.FuncDecl:
+			if d.Recv == nil && declared[d.Name.Name] {
+				skipped = append(skipped, d.Name.Name)
+				continue
+			}
+		}
+		start := decl.Pos()
+		if doc := docOf(decl); doc != nil {
+			start = doc.Pos()
+		}
+		decls = append(decls, string(generated[fset.Position(start).Offset:fset.Position(decl.End()).Offset]))
+	}
+
+	have := map[string]bool{}
+	for _, imp := range old.Imports {
+		have[importKey(imp)] = true
+	}
+	var imports []string
+	for _, imp := range gen.Imports {
+		if !have[importKey(imp)] {
+			imports = append(imports, importKey(imp))
+		}
+	}
+
+	src := string(existing)
+	if len(imports) > 0 {
+		src = addImports(src, imports)
+	}
+	if len(decls) > 0 {
+		src = strings.TrimRight(src, "\n") + "\n\n" + strings.Join(decls, "\n\n") + "\n"
+	}
+	out, err := format.Source([]byte(src))
+	return out, skipped, err
+}
+
+// docOf returns the doc comment of the declaration.
+func docOf(decl ast.Decl) *ast.CommentGroup {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		return d.Doc
+	case *ast.GenDecl:
+		return d.Doc
+	}
+	return nil
+}
+
+// importKey returns the import spec as written in the source, like "testing" or
+// assert "github.com/stretchr/testify/assert".
+func importKey(imp *ast.ImportSpec) string {
+	if imp.Name != nil {
+		return imp.Name.Name + " " + imp.Path.Value
+	}
+	return imp.Path.Value
+}
+
+// addImports adds the import specs to the source of the file. The import declarations
+// are replaced with one group of the standard library imports and one of the others.
+func addImports(src string, imports []string) string {
+	fset := token.NewFileSet()
+	parsed, err := parser.ParseFile(fset, "", src, parser.ImportsOnly)
+	if err != nil {
+		return src
+	}
+	var std, others []string
+	for _, spec := range append(specs(parsed), imports...) {
+		path := spec[strings.Index(spec, `"`)+1:]
+		if first, _, _ := strings.Cut(path, "/"); strings.Contains(first, ".") {
+			others = append(others, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	block := "import (\n"
+	for _, spec := range std {
+		block += "\t" + spec + "\n"
+	}
+	if len(std) > 0 && len(others) > 0 {
+		block += "\n"
+	}
+	for _, spec := range others {
+		block += "\t" + spec + "\n"
+	}
+	block += ")"
+
+	start, end := fset.Position(parsed.Name.End()).Offset, fset.Position(parsed.Name.End()).Offset
+	if len(parsed.Decls) > 0 {
+		start = fset.Position(parsed.Decls[0].Pos()).Offset
+		end = fset.Position(parsed.Decls[len(parsed.Decls)-1].End()).Offset
+	} else {
+		block = "\n\n" + block
+	}
+	return src[:start] + block + src[end:]
+}
+
+// specs returns the import specs of the file as written in the source.
+func specs(file *ast.File) []string {
+	var out []string
+	for _, imp := range file.Imports {
+		out = append(out, importKey(imp))
+	}
+	return out
+}
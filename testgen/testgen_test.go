@@ -0,0 +1,149 @@
+package testgen
+
+import (
+	"reflect"
+	"testing"
+)
+
+const source = `package calc
+
+func main() {}
+
+// Add adds the numbers.
+func Add(a, b int) int {
+	return a + b
+}
+
+func (c *Calc[T]) Sub(a, b int) int {
+	return a - b
+}
+`
+
+func TestChangedFuncs(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []int
+		want  []string
+	}{
+		{name: "all", want: []string{"Add", "Calc.Sub"}},
+		{name: "changed", lines: []int{3, 11}, want: []string{"Calc.Sub"}},
+		{name: "none", lines: []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			funcs, err := ChangedFuncs("calc.go", []byte(source), tt.lines)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, f := range funcs {
+				got = append(got, f.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChangedFuncs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if funcs, _ := ChangedFuncs("calc.go", []byte(source), nil); funcs[0].Line != 6 || funcs[0].Code != "func Add(a, b int) int {\n\treturn a + b\n}" {
+		t.Errorf("ChangedFuncs() = %+v", funcs[0])
+	}
+}
+
+func TestClean(t *testing.T) {
+	got := Clean("Here are the tests:\n```go\npackage calc\n```\n")
+	if got != "package calc\n" {
+		t.Errorf("Clean() = %q", got)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	generated := []byte(`package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAdd checks the sums.
+func TestAdd(t *testing.T) {
+	assert.Equal(t, 3, Add(1, 2))
+}
+
+func TestSub(t *testing.T) {}
+`)
+
+	tests := []struct {
+		name     string
+		existing string
+		want     string
+		skipped  []string
+	}{
+		{
+			name: "new file",
+			want: string(generated),
+		},
+		{
+			name:     "single import",
+			existing: "package calc\n\nimport \"testing\"\n\nfunc TestSub(t *testing.T) {\n\tt.Skip()\n}\n",
+			want: `package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSub(t *testing.T) {
+	t.Skip()
+}
+
+// TestAdd checks the sums.
+func TestAdd(t *testing.T) {
+	assert.Equal(t, 3, Add(1, 2))
+}
+`,
+			skipped: []string{"TestSub"},
+		},
+		{
+			name:     "no import",
+			existing: "package calc\n\nvar x = 1\n",
+			want: `package calc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var x = 1
+
+// TestAdd checks the sums.
+func TestAdd(t *testing.T) {
+	assert.Equal(t, 3, Add(1, 2))
+}
+
+func TestSub(t *testing.T) {}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, skipped, err := Merge([]byte(tt.existing), generated)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Merge() =\n%s\nwant\n%s", got, tt.want)
+			}
+			if !reflect.DeepEqual(skipped, tt.skipped) {
+				t.Errorf("Merge() skipped = %q, want %q", skipped, tt.skipped)
+			}
+		})
+	}
+
+	if _, _, err := Merge([]byte("package other\n"), generated); err == nil {
+		t.Error("Merge() expected an error for another package")
+	}
+}
@@ -0,0 +1,61 @@
+package tracing
+
+import "net/http"
+
+// defaultService is the service name of the spans by default.
+const defaultService = "codegpt"
+
+// Option is an interface that specifies the configuration options of the exporter and
+// of the spans.
+type Option interface {
+	apply(*config)
+}
+
+// optionFunc is a type of function that can be used to implement the Option interface.
+// It takes a pointer to a config struct and modifies it.
+type optionFunc func(*config)
+
+// Ensure that optionFunc satisfies the Option interface.
+var _ Option = (*optionFunc)(nil)
+
+// The apply method of optionFunc type is implemented here to modify the config struct based on the function passed.
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithHeaders returns an Option that sets the headers of the export requests, like
+// the API key of a hosted collector.
+func WithHeaders(val map[string]string) Option {
+	return optionFunc(func(c *config) {
+		c.headers = val
+	})
+}
+
+// WithService returns an Option of Init that sets the service name and version of the
+// spans. An empty name keeps the default.
+func WithService(name, version string) Option {
+	return optionFunc(func(c *config) {
+		if name != "" {
+			c.service = name
+		}
+		c.version = version
+	})
+}
+
+// WithHTTPClient returns an Option that sets the HTTP client of the export requests,
+// http.DefaultClient by default so the transport of the privacy policy is used.
+func WithHTTPClient(val *http.Client) Option {
+	return optionFunc(func(c *config) {
+		if val != nil {
+			c.client = val
+		}
+	})
+}
+
+// config is a struct that stores configuration options for the exporter and the spans.
+type config struct {
+	headers map[string]string
+	service string
+	version string
+	client  *http.Client
+}
@@ -0,0 +1,45 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+)
+
+// OTLPExporter sends the spans to the /v1/traces endpoint of an OpenTelemetry collector,
+// with the protobuf encoding of the OTLP/HTTP protocol.
+type OTLPExporter struct {
+	*otlptrace.Exporter
+	endpoint string
+}
+
+// NewOTLPExporter returns an exporter to the collector of the endpoint, like
+// http://localhost:4318. The /v1/traces path is added when it's missing.
+func NewOTLPExporter(ctx context.Context, endpoint string, opts ...Option) (*OTLPExporter, error) {
+	cfg := &config{client: http.DefaultClient}
+	for _, o := range opts {
+		o.apply(cfg)
+	}
+
+	endpoint = strings.TrimRight(endpoint, "/")
+	if !strings.HasSuffix(endpoint, "/v1/traces") {
+		endpoint += "/v1/traces"
+	}
+	exporter, err := otlptracehttp.New(ctx,
+		otlptracehttp.WithEndpointURL(endpoint),
+		otlptracehttp.WithHeaders(cfg.headers),
+		otlptracehttp.WithHTTPClient(cfg.client),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &OTLPExporter{Exporter: exporter, endpoint: endpoint}, nil
+}
+
+// Endpoint returns the URL the spans are sent to.
+func (e *OTLPExporter) Endpoint() string {
+	return e.endpoint
+}
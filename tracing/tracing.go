@@ -0,0 +1,177 @@
+// Package tracing records the spans of the pipeline, like the diff collection, the
+// prompt build and the provider calls, and exports them to an OpenTelemetry collector
+// with the OTLP/HTTP protocol. Without exporter the spans aren't recorded at all.
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// scopeName is the instrumentation scope of the spans.
+const scopeName = "github.com/appleboy/CodeGPT"
+
+// Kind is the kind of a span.
+type Kind = trace.SpanKind
+
+// The kinds of the spans.
+const (
+	KindInternal = trace.SpanKindInternal
+	KindServer   = trace.SpanKindServer
+	KindClient   = trace.SpanKindClient
+)
+
+// Attr is an attribute of a span.
+type Attr = attribute.KeyValue
+
+// String returns a string attribute.
+func String(key, val string) Attr { return attribute.String(key, val) }
+
+// Int returns an integer attribute.
+func Int(key string, val int) Attr { return attribute.Int(key, val) }
+
+// Float returns a number attribute.
+func Float(key string, val float64) Attr { return attribute.Float64(key, val) }
+
+// Bool returns a boolean attribute.
+func Bool(key string, val bool) Attr { return attribute.Bool(key, val) }
+
+// Span is an operation of a trace. The methods of a nil span do nothing, it's the span
+// returned by Start when tracing is disabled.
+type Span struct {
+	span trace.Span
+}
+
+// SetAttributes adds the attributes to the span.
+func (s *Span) SetAttributes(attrs ...Attr) {
+	if s == nil {
+		return
+	}
+	s.span.SetAttributes(attrs...)
+}
+
+// SetError marks the span as failed with the error, a nil error does nothing.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+// End ends the span, only the first call counts.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.span.End()
+}
+
+// SpanContext returns the IDs of the span and of its trace.
+func (s *Span) SpanContext() trace.SpanContext {
+	if s == nil {
+		return trace.SpanContext{}
+	}
+	return s.span.SpanContext()
+}
+
+// Traceparent returns the W3C traceparent header of the span, to propagate the trace.
+func (s *Span) Traceparent() string {
+	if s == nil {
+		return ""
+	}
+	carrier := propagation.MapCarrier{}
+	propagation.TraceContext{}.Inject(trace.ContextWithSpan(context.Background(), s.span), carrier)
+	return carrier.Get("traceparent")
+}
+
+var (
+	mu       sync.Mutex
+	provider *sdktrace.TracerProvider
+)
+
+// Init enables tracing with the exporter, the spans are exported in batches.
+func Init(exporter sdktrace.SpanExporter, opts ...Option) {
+	cfg := &config{service: defaultService}
+	for _, o := range opts {
+		o.apply(cfg)
+	}
+	attrs := []Attr{String("service.name", cfg.service)}
+	if cfg.version != "" {
+		attrs = append(attrs, String("service.version", cfg.version))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attrs...)),
+	)
+}
+
+// Enabled reports whether the spans are recorded.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return provider != nil
+}
+
+// Shutdown exports the spans left and disables tracing. It returns the export error.
+func Shutdown(ctx context.Context) error {
+	mu.Lock()
+	p := provider
+	provider = nil
+	mu.Unlock()
+	if p == nil {
+		return nil
+	}
+	return p.Shutdown(ctx)
+}
+
+// Start starts an internal span, the child of the span of the context or of the remote
+// parent extracted from the request. It returns the context of the span, and a nil span
+// when tracing is disabled.
+func Start(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	return StartKind(ctx, name, KindInternal, attrs...)
+}
+
+// StartKind starts a span of the kind, like the server span of a request, see Start.
+func StartKind(ctx context.Context, name string, kind Kind, attrs ...Attr) (context.Context, *Span) {
+	mu.Lock()
+	p := provider
+	mu.Unlock()
+	if p == nil {
+		return ctx, nil
+	}
+	ctx, span := p.Tracer(scopeName).Start(ctx, name,
+		trace.WithSpanKind(kind),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx, &Span{span: span}
+}
+
+// FromContext returns the span of the context, nil if there's none.
+func FromContext(ctx context.Context) *Span {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return nil
+	}
+	return &Span{span: span}
+}
+
+// Extract returns the context with the parent of the W3C traceparent header, the
+// context itself when the header is empty or invalid.
+func Extract(ctx context.Context, traceparent string) context.Context {
+	remote := propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{"traceparent": traceparent})
+	if !trace.SpanContextFromContext(remote).IsRemote() {
+		return ctx
+	}
+	return remote
+}
@@ -0,0 +1,175 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	collector "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// recorder keeps the exported spans.
+type recorder struct {
+	mu    sync.Mutex
+	spans []sdktrace.ReadOnlySpan
+}
+
+func (r *recorder) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, spans...)
+	return nil
+}
+
+func (r *recorder) Shutdown(ctx context.Context) error { return nil }
+
+func (r *recorder) byName() map[string]sdktrace.ReadOnlySpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := map[string]sdktrace.ReadOnlySpan{}
+	for _, s := range r.spans {
+		out[s.Name()] = s
+	}
+	return out
+}
+
+func TestDisabled(t *testing.T) {
+	ctx, span := Start(context.Background(), "commit")
+	if span != nil {
+		t.Fatal("Start() returned a span without exporter")
+	}
+	// the methods of the nil span do nothing
+	span.SetAttributes(String("model", "gpt-4o"))
+	span.SetError(errors.New("failed"))
+	span.End()
+	if FromContext(ctx) != nil {
+		t.Error("FromContext() returned a span without exporter")
+	}
+}
+
+func TestTrace(t *testing.T) {
+	r := &recorder{}
+	Init(r, WithService("", "v1.0.0"))
+	defer Shutdown(context.Background())
+
+	ctx, root := StartKind(context.Background(), "codegpt commit", KindServer)
+	_, diff := Start(ctx, "diff", Int("files", 2))
+	diff.End()
+	_, provider := StartKind(ctx, "provider", KindClient)
+	provider.SetError(errors.New("timeout"))
+	provider.End()
+	root.End()
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := r.byName()
+	if len(spans) != 3 {
+		t.Fatalf("exported %d spans, want 3", len(spans))
+	}
+	for name, s := range spans {
+		if s.SpanContext().TraceID() != root.SpanContext().TraceID() {
+			t.Errorf("span %s isn't in the trace of the root span", name)
+		}
+	}
+	if spans["diff"].Parent().SpanID() != root.SpanContext().SpanID() {
+		t.Error("the diff span isn't a child of the root span")
+	}
+	if got := spans["provider"]; got.Status().Code != codes.Error || got.Status().Description != "timeout" || got.SpanKind() != trace.SpanKindClient {
+		t.Errorf("provider span = %v %v, want the client span with the error", got.SpanKind(), got.Status())
+	}
+	if v, _ := spans["diff"].Resource().Set().Value("service.version"); v.AsString() != "v1.0.0" {
+		t.Errorf("service.version = %v, want v1.0.0", v.AsString())
+	}
+}
+
+func TestExtract(t *testing.T) {
+	r := &recorder{}
+	Init(r)
+	defer Shutdown(context.Background())
+
+	ctx := Extract(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, span := Start(ctx, "POST /v1/commit-message")
+	if got := span.Traceparent()[:35]; got != "00-4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Traceparent() = %s, want the trace of the header", got)
+	}
+	span.End()
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.byName()["POST /v1/commit-message"].Parent().SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("parent = %s, want the span of the header", got)
+	}
+
+	for _, h := range []string{"", "00-zz-00f067aa0ba902b7-01", "00-00000000000000000000000000000000-00f067aa0ba902b7-01"} {
+		if ctx := Extract(context.Background(), h); ctx != context.Background() {
+			t.Errorf("Extract(%q) returned a parent", h)
+		}
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	var (
+		body   collector.ExportTraceServiceRequest
+		header string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			t.Errorf("path = %s, want /v1/traces", r.URL.Path)
+		}
+		header = r.Header.Get("X-Api-Key")
+		data, _ := io.ReadAll(r.Body)
+		if err := proto.Unmarshal(data, &body); err != nil {
+			t.Errorf("invalid OTLP request: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	e, err := NewOTLPExporter(context.Background(), srv.URL, WithHeaders(map[string]string{"X-Api-Key": "secret"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Endpoint() != srv.URL+"/v1/traces" {
+		t.Errorf("Endpoint() = %s, want the /v1/traces path", e.Endpoint())
+	}
+	Init(e)
+	_, span := StartKind(context.Background(), "provider", KindClient,
+		String("gen_ai.request.model", "gpt-4o"),
+		Int("gen_ai.usage.input_tokens", 12),
+	)
+	span.SetError(errors.New("timeout"))
+	span.End()
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if header != "secret" {
+		t.Errorf("header = %q, want secret", header)
+	}
+
+	rs := body.GetResourceSpans()
+	if len(rs) != 1 || len(rs[0].GetScopeSpans()) != 1 || len(rs[0].GetScopeSpans()[0].GetSpans()) != 1 {
+		t.Fatalf("request = %v, want one span", rs)
+	}
+	if v := rs[0].GetResource().GetAttributes()[0]; v.GetKey() != "service.name" || v.GetValue().GetStringValue() != "codegpt" {
+		t.Errorf("resource attribute = %v, want service.name codegpt", v)
+	}
+	got := rs[0].GetScopeSpans()[0].GetSpans()[0]
+	if got.GetName() != "provider" || got.GetKind() != tracepb.Span_SPAN_KIND_CLIENT {
+		t.Errorf("span = %s %v", got.GetName(), got.GetKind())
+	}
+	if got.GetStatus().GetCode() != tracepb.Status_STATUS_CODE_ERROR {
+		t.Errorf("status = %v, want the error", got.GetStatus())
+	}
+	if tokens := got.GetAttributes()[1]; tokens.GetValue().GetIntValue() != 12 {
+		t.Errorf("attribute = %v, want the int 12", tokens)
+	}
+}
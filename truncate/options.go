@@ -0,0 +1,43 @@
+package truncate
+
+// Option is an interface that specifies truncation configuration options.
+type Option interface {
+	apply(*config)
+}
+
+// optionFunc is a type of function that can be used to implement the Option interface.
+// It takes a pointer to a config struct and modifies it.
+type optionFunc func(*config)
+
+// Ensure that optionFunc satisfies the Option interface.
+var _ Option = (*optionFunc)(nil)
+
+// The apply method of optionFunc type is implemented here to modify the config struct based on the function passed.
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithRules returns an Option that appends path rules, like docs/=0.2, to weight
+// the hunks of the matching files instead of their kind.
+func WithRules(val []Rule) Option {
+	return optionFunc(func(c *config) {
+		c.rules = append(c.rules, val...)
+	})
+}
+
+// WithCounter returns an Option that sets the function counting the tokens of the text,
+// a character count divided by four by default.
+func WithCounter(val func(string) int) Option {
+	return optionFunc(func(c *config) {
+		if val == nil {
+			return
+		}
+		c.counter = val
+	})
+}
+
+// config is a struct that stores configuration options for the truncation.
+type config struct {
+	rules   []Rule
+	counter func(string) int
+}
@@ -0,0 +1,305 @@
+// Package truncate fits a git diff into a budget of tokens. Instead of cutting the end of
+// the diff, the hunks are scored by importance, from the kind of their file, the size of
+// their changes and the path rules, and the lowest-priority ones are dropped first. The
+// omitted hunks are listed at the end of the diff, so the model knows what it didn't see.
+package truncate
+
+import (
+	"errors"
+	"math"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/appleboy/CodeGPT/git"
+)
+
+// The weights of the kinds of files, the source files have the highest priority.
+const (
+	WeightSource = 1.0
+	WeightConfig = 0.6
+	WeightDocs   = 0.5
+	WeightTest   = 0.4
+)
+
+// Rule weights the hunks of the files matching the pattern instead of their kind.
+type Rule struct {
+	Pattern string
+	Weight  float64
+}
+
+// ParseRules parses the path rules in the pattern=weight format, like docs/=0.2.
+// The patterns are the ones of git.scope_map.
+func ParseRules(list []string) ([]Rule, error) {
+	rules := make([]Rule, 0, len(list))
+	for _, s := range list {
+		pattern, value, ok := strings.Cut(s, "=")
+		pattern = strings.TrimSpace(pattern)
+		if !ok || pattern == "" {
+			return nil, errors.New("invalid priority rule " + strconv.Quote(s) + ", the format is pattern=weight")
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || weight < 0 {
+			return nil, errors.New("invalid weight of the priority rule " + strconv.Quote(s))
+		}
+		rules = append(rules, Rule{Pattern: pattern, Weight: weight})
+	}
+	return rules, nil
+}
+
+// Omitted is a file of the diff with hunks dropped to fit the budget.
+type Omitted struct {
+	File  string `json:"file"`
+	Hunks int    `json:"hunks"`
+	Total int    `json:"total"`
+	// Lines is the number of lines cut from the end of a hunk too large for the budget
+	Lines int `json:"lines,omitempty"`
+}
+
+// String describes the omitted hunks, like: cmd/commit.go, 2 of 5 hunks
+func (o Omitted) String() string {
+	var parts []string
+	switch {
+	case o.Hunks == 0:
+	case o.Hunks == o.Total:
+		parts = append(parts, "all "+strconv.Itoa(o.Total)+" hunks")
+	default:
+		parts = append(parts, strconv.Itoa(o.Hunks)+" of "+strconv.Itoa(o.Total)+" hunks")
+	}
+	if o.Lines > 0 {
+		parts = append(parts, "the last "+strconv.Itoa(o.Lines)+" lines of a hunk")
+	}
+	return o.File + ", " + strings.Join(parts, " and ")
+}
+
+// Truncator drops the lowest-priority hunks of the diffs over the budget.
+type Truncator struct {
+	cfg *config
+}
+
+// New returns a truncator with the given options.
+func New(opts ...Option) *Truncator {
+	cfg := &config{
+		counter: func(s string) int { return (len(s) + 3) / 4 },
+	}
+	for _, o := range opts {
+		o.apply(cfg)
+	}
+	return &Truncator{cfg: cfg}
+}
+
+// The kinds of files found from their names.
+var (
+	testNames   = []string{"*_test.go", "*_test.py", "test_*.py", "*.test.*", "*.spec.*", "*Test.java", "*Tests.java", "*Test.kt", "*_spec.rb", "*_test.rs"}
+	testDirs    = []string{"test", "tests", "__tests__", "spec", "testdata"}
+	docsExts    = []string{".md", ".markdown", ".rst", ".txt", ".adoc"}
+	docsDirs    = []string{"docs", "doc"}
+	configExts  = []string{".json", ".yaml", ".yml", ".toml", ".ini", ".cfg", ".conf", ".xml", ".properties", ".env", ".lock", ".mod", ".sum"}
+	configNames = []string{"Dockerfile", "Makefile", ".gitignore", ".gitattributes", ".editorconfig", ".dockerignore"}
+	configDirs  = []string{".github", ".circleci"}
+)
+
+// inDir reports whether the file is in one of the directories, at any level.
+func inDir(file string, dirs []string) bool {
+	for _, d := range strings.Split(path.Dir(file), "/") {
+		for _, dir := range dirs {
+			if d == dir {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// contains reports whether the list contains the value.
+func contains(list []string, val string) bool {
+	for _, v := range list {
+		if v == val {
+			return true
+		}
+	}
+	return false
+}
+
+// Weight returns the weight of the hunks of the file: the one of the longest matching
+// path rule, or the one of the kind of the file.
+func (t *Truncator) Weight(file string) float64 {
+	length := -1
+	weight := 0.0
+	for _, r := range t.cfg.rules {
+		if len(r.Pattern) > length && git.MatchPattern(r.Pattern, file) {
+			length, weight = len(r.Pattern), r.Weight
+		}
+	}
+	if length >= 0 {
+		return weight
+	}
+
+	base := path.Base(file)
+	for _, pattern := range testNames {
+		if ok, _ := path.Match(pattern, base); ok {
+			return WeightTest
+		}
+	}
+	ext := strings.ToLower(path.Ext(base))
+	switch {
+	case inDir(file, testDirs):
+		return WeightTest
+	case contains(docsExts, ext) || inDir(file, docsDirs):
+		return WeightDocs
+	case contains(configExts, ext) || contains(configNames, base) || inDir(file, configDirs):
+		return WeightConfig
+	}
+	return WeightSource
+}
+
+// Score returns the priority of the hunk of the file: the weight of the file, raised
+// with the number of changed lines. The larger changes matter more, but far less than
+// the kind of the file, a large change of the docs doesn't come before the code.
+func (t *Truncator) Score(file string, h *git.Hunk) float64 {
+	changed := 0
+	for _, line := range h.Lines {
+		if strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-") {
+			changed++
+		}
+	}
+	return t.Weight(file) * (1 + churnFactor*math.Log2(1+float64(changed)))
+}
+
+// churnFactor is the weight of the number of changed lines in the score, a hunk of
+// a thousand changed lines scores twice a hunk of one line.
+const churnFactor = 0.1
+
+// hunk is a hunk of the diff with its priority and its size in tokens.
+type hunk struct {
+	file  int
+	hunk  *git.Hunk
+	score float64
+	cost  int
+}
+
+// Fit returns the diff with the lowest-priority hunks dropped until it fits the budget
+// of tokens, and the files with omitted hunks, listed at the end of the diff. The diff
+// is returned as is when it fits, or when the budget isn't positive.
+func (t *Truncator) Fit(diff string, budget int) (string, []Omitted) {
+	count := t.cfg.counter
+	total := count(diff)
+	if budget <= 0 || total <= budget {
+		return diff, nil
+	}
+
+	// the lists of the renamed, binary and generated files are kept as is
+	patch, notes := git.SplitNotes(diff)
+	files := git.ParsePatch(patch)
+
+	var hunks []hunk
+	headers := make([]int, len(files))
+	remaining := make([]int, len(files))
+	for i, f := range files {
+		headers[i] = count(strings.Join(f.Header, "\n"))
+		remaining[i] = len(f.Hunks)
+		for _, h := range f.Hunks {
+			hunks = append(hunks, hunk{
+				file:  i,
+				hunk:  h,
+				score: t.Score(f.Name(), h),
+				cost:  count(h.Header + "\n" + strings.Join(h.Lines, "\n")),
+			})
+		}
+	}
+	// the lowest priority first, and the last hunks of the diff first on a tie
+	order := make([]int, len(hunks))
+	for i := range order {
+		order[len(order)-1-i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return hunks[order[i]].score < hunks[order[j]].score })
+
+	// the list of the omitted hunks costs a line per file
+	report := count(reportTitle)
+	var dropped []int
+	for _, i := range order {
+		if total+report <= budget {
+			break
+		}
+		h := hunks[i]
+		h.hunk.Selected = false
+		dropped = append(dropped, i)
+		total -= h.cost
+		if remaining[h.file] == len(files[h.file].Hunks) {
+			report += count("- " + files[h.file].Name() + ", 10 of 10 hunks\n")
+		}
+		remaining[h.file]--
+		if remaining[h.file] == 0 {
+			total -= headers[h.file]
+		}
+	}
+
+	// the dropped hunks which fit again, like the small ones dropped before a large one,
+	// the highest priority first. The beginning of a hunk too large is kept when there
+	// is room for it, the hunks with a lower priority only get the rest.
+	lines := make([]int, len(files))
+	cut := false
+	for k := len(dropped) - 1; k >= 0; k-- {
+		h := hunks[dropped[k]]
+		header := 0
+		if remaining[h.file] == 0 {
+			header = headers[h.file]
+		}
+		if total+header+h.cost+report <= budget {
+			h.hunk.Selected = true
+			total += header + h.cost
+			remaining[h.file]++
+			continue
+		}
+		if cut {
+			continue
+		}
+
+		marker := "\\ " + strconv.Itoa(len(h.hunk.Lines)) + " more lines omitted"
+		free := budget - total - report - header - count(h.hunk.Header+"\n"+marker) -
+			count(" and the last 10000 lines of a hunk")
+		if free < minCut {
+			continue
+		}
+		kept, size := 0, 0
+		for _, line := range h.hunk.Lines {
+			n := count(line + "\n")
+			if size+n > free {
+				break
+			}
+			kept++
+			size += n
+		}
+		lines[h.file] = len(h.hunk.Lines) - kept
+		h.hunk.Lines = append(h.hunk.Lines[:kept:kept], "\\ "+strconv.Itoa(lines[h.file])+" more lines omitted")
+		h.hunk.Selected = true
+		total += header + count(h.hunk.Header+"\n"+strings.Join(h.hunk.Lines, "\n"))
+		remaining[h.file]++
+		cut = true
+	}
+
+	var omitted []Omitted
+	for i, f := range files {
+		if remaining[i] < len(f.Hunks) || lines[i] > 0 {
+			omitted = append(omitted, Omitted{File: f.Name(), Hunks: len(f.Hunks) - remaining[i], Total: len(f.Hunks), Lines: lines[i]})
+		}
+	}
+
+	out := git.FormatPatch(files, true) + notes
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	out += "\n" + reportTitle + "\n"
+	for _, o := range omitted {
+		out += "- " + o.String() + "\n"
+	}
+	return out, omitted
+}
+
+// minCut is the minimum number of tokens of the beginning of a hunk too large for the
+// budget, a smaller part of it is left out.
+const minCut = 64
+
+// reportTitle is the title of the list of the omitted hunks at the end of the diff.
+const reportTitle = "Hunks omitted to fit the context window:"
@@ -0,0 +1,158 @@
+package truncate
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// patch returns the patch of the file with a hunk per count of changed lines.
+func patch(file string, hunks ...int) string {
+	var b strings.Builder
+	b.WriteString("diff --git a/" + file + " b/" + file + "\n--- a/" + file + "\n+++ b/" + file + "\n")
+	for i, n := range hunks {
+		b.WriteString("@@ -1,1 +1,1 @@\n")
+		for j := 0; j < n; j++ {
+			b.WriteString("+" + strings.Repeat(string(rune('a'+i)), 40) + "\n")
+		}
+	}
+	return b.String()
+}
+
+func TestWeight(t *testing.T) {
+	tr := New(WithRules([]Rule{{Pattern: "internal/", Weight: 2}, {Pattern: "internal/legacy/", Weight: 0.1}}))
+	tests := map[string]float64{
+		"cmd/commit.go":               WeightSource,
+		"cmd/commit_test.go":          WeightTest,
+		"web/src/App.test.tsx":        WeightTest,
+		"tests/e2e/run.py":            WeightTest,
+		"README.md":                   WeightDocs,
+		"docs/guide/setup.html":       WeightDocs,
+		"config/app.yaml":             WeightConfig,
+		".github/workflows/ci.yml":    WeightConfig,
+		"Dockerfile":                  WeightConfig,
+		"internal/store/store.go":     2,
+		"internal/legacy/old_test.go": 0.1,
+	}
+	for file, want := range tests {
+		if got := tr.Weight(file); got != want {
+			t.Errorf("Weight(%q) = %v, want %v", file, got, want)
+		}
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	got, err := ParseRules([]string{"docs/=0.2", " *.sql = 1.5 "})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Rule{{Pattern: "docs/", Weight: 0.2}, {Pattern: "*.sql", Weight: 1.5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseRules() = %v, want %v", got, want)
+	}
+	for _, bad := range []string{"docs/", "=1", "docs/=x", "docs/=-1"} {
+		if _, err := ParseRules([]string{bad}); err == nil {
+			t.Errorf("ParseRules(%q) error = nil", bad)
+		}
+	}
+}
+
+func TestFitUnderBudget(t *testing.T) {
+	diff := patch("main.go", 2)
+	got, omitted := New().Fit(diff, 1000)
+	if got != diff || omitted != nil {
+		t.Errorf("Fit() = %q, %v, want the diff as is", got, omitted)
+	}
+	if got, _ := New().Fit(diff, 0); got != diff {
+		t.Errorf("Fit() without budget = %q, want the diff as is", got)
+	}
+}
+
+func TestFit(t *testing.T) {
+	diff := patch("main.go", 10, 2) + patch("main_test.go", 10) + patch("README.md", 3) +
+		"\nBinary or large files (content omitted):\n- added image logo.png, +12KB\n"
+	tr := New()
+	got, omitted := tr.Fit(diff, 200)
+
+	want := []Omitted{
+		{File: "main_test.go", Hunks: 1, Total: 1},
+		{File: "README.md", Hunks: 1, Total: 1},
+	}
+	if !reflect.DeepEqual(omitted, want) {
+		t.Errorf("Fit() omitted = %v, want %v", omitted, want)
+	}
+	for _, s := range []string{"diff --git a/main.go", strings.Repeat("b", 40), "added image logo.png", "Hunks omitted to fit the context window:\n- main_test.go, all 1 hunks\n- README.md, all 1 hunks\n"} {
+		if !strings.Contains(got, s) {
+			t.Errorf("Fit() = %s\nwant it to contain %q", got, s)
+		}
+	}
+	if strings.Contains(got, "main_test.go b/main_test.go") {
+		t.Errorf("Fit() = %s\nwant the test file dropped", got)
+	}
+	if n := New().cfg.counter(got); n > 200 {
+		t.Errorf("Fit() = %d tokens, want at most 200", n)
+	}
+}
+
+func TestFitPartialFile(t *testing.T) {
+	diff := patch("main.go", 30, 3, 30)
+	got, omitted := New().Fit(diff, New().cfg.counter(diff)-5)
+	want := []Omitted{{File: "main.go", Hunks: 1, Total: 3}}
+	if !reflect.DeepEqual(omitted, want) {
+		t.Errorf("Fit() omitted = %v, want %v", omitted, want)
+	}
+	if strings.Contains(got, strings.Repeat("b", 40)) || !strings.Contains(got, "- main.go, 1 of 3 hunks") {
+		t.Errorf("Fit() = %s\nwant the smallest hunk dropped", got)
+	}
+}
+
+func TestFitLargeHunk(t *testing.T) {
+	diff := patch("main.go", 200) + patch("main_test.go", 2)
+	got, omitted := New().Fit(diff, 600)
+
+	// the beginning of the source file comes before the test file
+	if len(omitted) != 2 || omitted[0].File != "main.go" || omitted[0].Hunks != 0 || omitted[0].Lines == 0 ||
+		omitted[1] != (Omitted{File: "main_test.go", Hunks: 1, Total: 1}) {
+		t.Fatalf("Fit() omitted = %+v, want the end of the hunk of main.go and main_test.go", omitted)
+	}
+	want := "- main.go, the last " + strconv.Itoa(omitted[0].Lines) + " lines of a hunk\n"
+	for _, s := range []string{"diff --git a/main.go", "\\ " + strconv.Itoa(omitted[0].Lines) + " more lines omitted", want} {
+		if !strings.Contains(got, s) {
+			t.Errorf("Fit() = %s\nwant it to contain %q", got, s)
+		}
+	}
+	if n := New().cfg.counter(got); n > 600 || n < 500 {
+		t.Errorf("Fit() = %d tokens, want the most of the 600 tokens", n)
+	}
+}
+
+func TestFitSmallHunksAfterLargeOne(t *testing.T) {
+	// the small docs hunk dropped first fits again once the large test one is dropped
+	diff := patch("main.go", 4) + patch("README.md", 2) + patch("big_test.go", 300)
+	got, omitted := New().Fit(diff, 130)
+	want := []Omitted{{File: "big_test.go", Hunks: 1, Total: 1}}
+	if !reflect.DeepEqual(omitted, want) {
+		t.Errorf("Fit() omitted = %+v, want %+v", omitted, want)
+	}
+	if !strings.Contains(got, "diff --git a/README.md") {
+		t.Errorf("Fit() = %s\nwant the docs", got)
+	}
+}
+
+func TestOmittedString(t *testing.T) {
+	tests := []struct {
+		o    Omitted
+		want string
+	}{
+		{Omitted{File: "a.go", Hunks: 2, Total: 5}, "a.go, 2 of 5 hunks"},
+		{Omitted{File: "a.go", Hunks: 1, Total: 1}, "a.go, all 1 hunks"},
+		{Omitted{File: "a.go", Total: 1, Lines: 80}, "a.go, the last 80 lines of a hunk"},
+		{Omitted{File: "a.go", Hunks: 1, Total: 3, Lines: 80}, "a.go, 1 of 3 hunks and the last 80 lines of a hunk"},
+	}
+	for _, tt := range tests {
+		if got := tt.o.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !unix && !windows
+
+package tui
+
+import (
+	"os"
+	"time"
+)
+
+// waitInput reports the input as ready, the read blocks until a key is pressed.
+func waitInput(f *os.File, timeout time.Duration) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,136 @@
+package tui
+
+import "unicode/utf8"
+
+// KeyType is the type of a key press, KeyRune for the printable characters.
+type KeyType int
+
+// The keys read from the terminal.
+const (
+	KeyRune KeyType = iota
+	KeyEnter
+	KeyEsc
+	KeyTab
+	KeyBackspace
+	KeyCtrlC
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	KeyPgUp
+	KeyPgDown
+)
+
+var keyNames = map[KeyType]string{
+	KeyEnter:     "enter",
+	KeyEsc:       "esc",
+	KeyTab:       "tab",
+	KeyBackspace: "backspace",
+	KeyCtrlC:     "ctrl+c",
+	KeyUp:        "up",
+	KeyDown:      "down",
+	KeyLeft:      "left",
+	KeyRight:     "right",
+	KeyHome:      "home",
+	KeyEnd:       "end",
+	KeyPgUp:      "pgup",
+	KeyPgDown:    "pgdown",
+}
+
+// KeyMsg is the message of a key press.
+type KeyMsg struct {
+	Type KeyType
+	Rune rune
+}
+
+// String returns the name of the key, like "enter" or "ctrl+c", or the character.
+func (k KeyMsg) String() string {
+	if k.Type == KeyRune {
+		return string(k.Rune)
+	}
+	return keyNames[k.Type]
+}
+
+// sequences are the escape sequences of the special keys, in the xterm and vt100 variants.
+var sequences = map[string]KeyType{
+	"\x1b[A":  KeyUp,
+	"\x1b[B":  KeyDown,
+	"\x1b[C":  KeyRight,
+	"\x1b[D":  KeyLeft,
+	"\x1bOA":  KeyUp,
+	"\x1bOB":  KeyDown,
+	"\x1bOC":  KeyRight,
+	"\x1bOD":  KeyLeft,
+	"\x1b[H":  KeyHome,
+	"\x1b[F":  KeyEnd,
+	"\x1bOH":  KeyHome,
+	"\x1bOF":  KeyEnd,
+	"\x1b[1~": KeyHome,
+	"\x1b[4~": KeyEnd,
+	"\x1b[5~": KeyPgUp,
+	"\x1b[6~": KeyPgDown,
+}
+
+// ParseKeys returns the key presses of the input read from the terminal in raw mode.
+// The unknown escape sequences and control characters are dropped.
+func ParseKeys(b []byte) []KeyMsg {
+	var keys []KeyMsg
+	for len(b) > 0 {
+		if b[0] == 0x1b {
+			if len(b) == 1 {
+				keys = append(keys, KeyMsg{Type: KeyEsc})
+				break
+			}
+			n := sequenceLen(b)
+			if t, ok := sequences[string(b[:n])]; ok {
+				keys = append(keys, KeyMsg{Type: t})
+			} else if n == 1 {
+				keys = append(keys, KeyMsg{Type: KeyEsc})
+			}
+			b = b[n:]
+			continue
+		}
+
+		switch b[0] {
+		case '\r', '\n':
+			keys = append(keys, KeyMsg{Type: KeyEnter})
+		case '\t':
+			keys = append(keys, KeyMsg{Type: KeyTab})
+		case 0x7f, 0x08:
+			keys = append(keys, KeyMsg{Type: KeyBackspace})
+		case 0x03:
+			keys = append(keys, KeyMsg{Type: KeyCtrlC})
+		default:
+			r, size := utf8.DecodeRune(b)
+			if r >= ' ' && r != utf8.RuneError {
+				keys = append(keys, KeyMsg{Type: KeyRune, Rune: r})
+			}
+			b = b[size:]
+			continue
+		}
+		b = b[1:]
+	}
+	return keys
+}
+
+// sequenceLen returns the length of the escape sequence at the start of b: CSI sequences
+// end with a letter or ~, SS3 ones are three bytes, a lone ESC is one.
+func sequenceLen(b []byte) int {
+	switch b[1] {
+	case 'O':
+		if len(b) >= 3 {
+			return 3
+		}
+		return len(b)
+	case '[':
+		for i := 2; i < len(b); i++ {
+			if c := b[i]; c == '~' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') {
+				return i + 1
+			}
+		}
+		return len(b)
+	}
+	return 1
+}
@@ -0,0 +1,35 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"runes", "rq", []string{"r", "q"}},
+		{"unicode", "é", []string{"é"}},
+		{"control", "\r\t\x7f\x03", []string{"enter", "tab", "backspace", "ctrl+c"}},
+		{"arrows", "\x1b[A\x1b[B\x1bOC\x1bOD", []string{"up", "down", "right", "left"}},
+		{"pages", "\x1b[5~\x1b[6~", []string{"pgup", "pgdown"}},
+		{"lone esc", "\x1b", []string{"esc"}},
+		{"esc then rune", "\x1bq", []string{"esc", "q"}},
+		{"unknown sequence", "\x1b[15~x", []string{"x"}},
+		{"other control", "\x01a", []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, k := range ParseKeys([]byte(tt.input)) {
+				got = append(got, k.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseKeys(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
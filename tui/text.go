@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// Wrap breaks the text into lines of at most width cells, at the spaces when possible.
+// The tabs are expanded to 4 spaces.
+func Wrap(text string, width int) []string {
+	if width <= 0 {
+		return nil
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\t", "    "), "\n") {
+		line = strings.TrimRight(line, "\r")
+		for runewidth.StringWidth(line) > width {
+			cut := runewidth.Truncate(line, width, "")
+			if i := strings.LastIndex(cut, " "); i > 0 {
+				cut = cut[:i]
+			}
+			lines = append(lines, cut)
+			line = strings.TrimPrefix(line[len(cut):], " ")
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Fit truncates or pads the line to exactly width cells.
+func Fit(line string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	line = strings.ReplaceAll(line, "\t", "    ")
+	if runewidth.StringWidth(line) > width {
+		line = runewidth.Truncate(line, width, "…")
+	}
+	return runewidth.FillRight(line, width)
+}
+
+// The SGR attributes and colors of the styles.
+const (
+	Bold    = 1
+	Faint   = 2
+	Reverse = 7
+	Red     = 31
+	Green   = 32
+	Yellow  = 33
+	Cyan    = 36
+)
+
+// Style returns the text with the SGR attributes, it must be applied after Fit since
+// the escape codes have no width.
+func Style(text string, attrs ...int) string {
+	if len(attrs) == 0 || text == "" {
+		return text
+	}
+	codes := make([]string, len(attrs))
+	for i, a := range attrs {
+		codes[i] = strconv.Itoa(a)
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m" + text + "\x1b[0m"
+}
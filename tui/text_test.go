@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  []string
+	}{
+		{"short", "feat: add the ui", 20, []string{"feat: add the ui"}},
+		{"at the spaces", "feat: add the ui command", 12, []string{"feat: add", "the ui", "command"}},
+		{"long word", "abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"lines", "a\n\nb", 10, []string{"a", "", "b"}},
+		{"wide characters", "新增使用者介面", 6, []string{"新增使", "用者介", "面"}},
+		{"no width", "a", 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Wrap(tt.text, tt.width); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Wrap(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		line  string
+		width int
+		want  string
+	}{
+		{"abc", 5, "abc  "},
+		{"abcdef", 4, "abc…"},
+		{"新增", 5, "新增 "},
+		{"\tx", 6, "    x "},
+	}
+	for _, tt := range tests {
+		if got := Fit(tt.line, tt.width); got != tt.want {
+			t.Errorf("Fit(%q, %d) = %q, want %q", tt.line, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestStyle(t *testing.T) {
+	if got := Style("x", Bold, Red); got != "\x1b[1;31mx\x1b[0m" {
+		t.Errorf("Style() = %q", got)
+	}
+	if got := Style("x"); got != "x" {
+		t.Errorf("Style() without attributes = %q", got)
+	}
+}
@@ -0,0 +1,207 @@
+// Package tui runs the terminal user interfaces in the Elm architecture, like bubbletea:
+// the model handles the messages of the keys and the commands in Update, and View renders
+// the screen from the model.
+package tui
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/appleboy/CodeGPT/util"
+
+	"golang.org/x/term"
+)
+
+// Msg is a message handled by the model: a key press, a resize or the result of a command.
+type Msg any
+
+// Cmd is run in its own goroutine, its message is sent back to the model.
+type Cmd func() Msg
+
+// Model is the state of the user interface.
+type Model interface {
+	// Init returns the first command, like loading the data.
+	Init() Cmd
+	// Update handles the message and returns the new state and the next command.
+	Update(msg Msg) (Model, Cmd)
+	// View renders the state on width x height cells, one line per row.
+	View(width, height int) string
+}
+
+// ResizeMsg is sent when the size of the terminal changes, and before the first render.
+type ResizeMsg struct {
+	Width  int
+	Height int
+}
+
+type quitMsg struct{}
+
+// Quit is the command stopping the program after the update.
+func Quit() Msg {
+	return quitMsg{}
+}
+
+const (
+	// resizeInterval is how often the size of the terminal is checked, SIGWINCH isn't portable.
+	resizeInterval = 200 * time.Millisecond
+	// keyInterval is how often the reader of the keys checks if the program is done.
+	keyInterval = 50 * time.Millisecond
+)
+
+// Program runs a model on the terminal.
+type Program struct {
+	model Model
+	msgs  chan Msg
+	// done is closed when Run returns
+	done chan struct{}
+	in   *os.File
+	out  io.Writer
+}
+
+// NewProgram returns the program of the model, on stdin and stdout.
+func NewProgram(model Model) *Program {
+	return &Program{
+		model: model,
+		msgs:  make(chan Msg, 64),
+		done:  make(chan struct{}),
+		in:    os.Stdin,
+		out:   os.Stdout,
+	}
+}
+
+// Send sends the message to the model, from any goroutine, like a streaming command.
+// The message is dropped once the program is done.
+func (p *Program) Send(msg Msg) {
+	select {
+	case p.msgs <- msg:
+	case <-p.done:
+	}
+}
+
+// Run takes over the terminal until the model quits or the context is done, and returns
+// the final state of the model.
+func (p *Program) Run(ctx context.Context) (Model, error) {
+	fd := int(p.in.Fd())
+	if !term.IsTerminal(fd) {
+		return p.model, errors.New("the terminal user interface needs an interactive terminal")
+	}
+	if err := util.EnableVirtualTerminal(); err != nil {
+		return p.model, errors.New("the terminal user interface needs a console with ANSI support, like Windows Terminal")
+	}
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return p.model, err
+	}
+	defer func() { _ = term.Restore(fd, state) }()
+
+	// the alternate screen keeps the shell history, the cursor is hidden
+	_, _ = io.WriteString(p.out, "\x1b[?1049h\x1b[?25l")
+	defer func() { _, _ = io.WriteString(p.out, "\x1b[?25h\x1b[?1049l") }()
+
+	// the reader of the keys stops before the terminal is restored, the next prompts
+	// of the command get the keys
+	var keys sync.WaitGroup
+	keys.Add(1)
+	go func() {
+		defer keys.Done()
+		p.readKeys()
+	}()
+	defer func() {
+		close(p.done)
+		keys.Wait()
+	}()
+
+	width, height, _ := term.GetSize(int(os.Stdout.Fd()))
+	ticker := time.NewTicker(resizeInterval)
+	defer ticker.Stop()
+
+	p.exec(p.model.Init())
+	p.model, _ = p.model.Update(ResizeMsg{Width: width, Height: height})
+	p.render(width, height)
+	for {
+		var msg Msg
+		select {
+		case <-ctx.Done():
+			return p.model, ctx.Err()
+		case <-ticker.C:
+			w, h, err := term.GetSize(int(os.Stdout.Fd()))
+			if err != nil || (w == width && h == height) {
+				continue
+			}
+			width, height = w, h
+			msg = ResizeMsg{Width: w, Height: h}
+		case msg = <-p.msgs:
+		}
+
+		if _, ok := msg.(quitMsg); ok {
+			return p.model, nil
+		}
+		var cmd Cmd
+		p.model, cmd = p.model.Update(msg)
+		p.exec(cmd)
+		p.render(width, height)
+	}
+}
+
+// exec runs the command in a goroutine, its message is handled like the others.
+func (p *Program) exec(cmd Cmd) {
+	if cmd == nil {
+		return
+	}
+	go func() {
+		if msg := cmd(); msg != nil {
+			p.Send(msg)
+		}
+	}()
+}
+
+// readKeys sends the key presses of the terminal to the model until the program is done.
+// It only reads the keys already typed, so it never blocks after the program is done.
+func (p *Program) readKeys() {
+	buf := make([]byte, 256)
+	for {
+		select {
+		case <-p.done:
+			return
+		default:
+		}
+		ready, err := waitInput(p.in, keyInterval)
+		if err != nil {
+			return
+		}
+		if !ready {
+			continue
+		}
+		n, err := p.in.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, key := range ParseKeys(buf[:n]) {
+			p.Send(key)
+		}
+	}
+}
+
+// render draws the view from the top left corner, the rest of every line is cleared.
+func (p *Program) render(width, height int) {
+	lines := strings.Split(p.model.View(width, height), "\n")
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for i, line := range lines {
+		b.WriteString(line)
+		b.WriteString("\x1b[K")
+		if i < len(lines)-1 {
+			b.WriteString("\r\n")
+		}
+	}
+	b.WriteString("\x1b[J")
+	_, _ = io.WriteString(p.out, b.String())
+}
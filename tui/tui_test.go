@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestReadKeysStops(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	p := NewProgram(nil)
+	p.in = r
+	stopped := make(chan struct{})
+	go func() {
+		p.readKeys()
+		close(stopped)
+	}()
+
+	if _, err := w.WriteString("q"); err != nil {
+		t.Fatal(err)
+	}
+	if msg := <-p.msgs; msg.(KeyMsg).String() != "q" {
+		t.Errorf("readKeys() sent %v, want q", msg)
+	}
+
+	close(p.done)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("readKeys() still reads the input after the program is done")
+	}
+	// the keys typed after aren't taken from the next reader
+	if _, err := w.WriteString("x"); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1)
+	if n, err := r.Read(buf); err != nil || string(buf[:n]) != "x" {
+		t.Errorf("Read() = %q, %v, want x", buf[:n], err)
+	}
+}
+
+func TestSendAfterDone(t *testing.T) {
+	p := NewProgram(nil)
+	close(p.done)
+	sent := make(chan struct{})
+	go func() {
+		for i := 0; i < cap(p.msgs)+1; i++ {
+			p.Send(i)
+		}
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-time.After(time.Second):
+		t.Fatal("Send() blocks after the program is done")
+	}
+}
@@ -0,0 +1,68 @@
+package update
+
+import (
+	"net/http"
+)
+
+// Option is an interface that specifies update client configuration options.
+type Option interface {
+	apply(*config)
+}
+
+// optionFunc is a type of function that can be used to implement the Option interface.
+// It takes a pointer to a config struct and modifies it.
+type optionFunc func(*config)
+
+// Ensure that optionFunc satisfies the Option interface.
+var _ Option = (*optionFunc)(nil)
+
+// The apply method of optionFunc type is implemented here to modify the config struct based on the function passed.
+func (o optionFunc) apply(c *config) {
+	o(c)
+}
+
+// WithBaseURL returns an Option that sets the URL of the GitHub API, https://api.github.com by default.
+func WithBaseURL(val string) Option {
+	return optionFunc(func(c *config) {
+		if val == "" {
+			return
+		}
+		c.baseURL = val
+	})
+}
+
+// WithRepo returns an Option that sets the repository of the releases, like appleboy/CodeGPT.
+func WithRepo(val string) Option {
+	return optionFunc(func(c *config) {
+		if val == "" {
+			return
+		}
+		c.repo = val
+	})
+}
+
+// WithPublicKey returns an Option that sets the base64 Ed25519 public key verifying the
+// signature of the checksums, raw or in the DER form of a PEM file. It's required.
+func WithPublicKey(val string) Option {
+	return optionFunc(func(c *config) {
+		c.publicKey = val
+	})
+}
+
+// WithHTTPClient returns an Option that sets the HTTP client.
+func WithHTTPClient(val *http.Client) Option {
+	return optionFunc(func(c *config) {
+		if val == nil {
+			return
+		}
+		c.httpClient = val
+	})
+}
+
+// config is a struct that stores configuration options for the update client.
+type config struct {
+	baseURL    string
+	repo       string
+	publicKey  string
+	httpClient *http.Client
+}
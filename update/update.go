@@ -0,0 +1,366 @@
+// Package update replaces the running binary with the one of the latest GitHub release.
+// The binary is verified with the checksums of the release, and the checksums with their
+// Ed25519 signature.
+package update
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/appleboy/CodeGPT/release"
+)
+
+// The channels of the releases.
+const (
+	// Stable is the latest release, without the pre-releases.
+	Stable = "stable"
+	// Prerelease is the newest release, the pre-releases included.
+	Prerelease = "prerelease"
+)
+
+const (
+	// ChecksumsFile is the asset with the SHA-256 of the other assets of a release.
+	ChecksumsFile = "checksums.txt"
+	// SignatureFile is the asset with the Ed25519 signature of the checksums.
+	SignatureFile = ChecksumsFile + ".sig"
+
+	// maxAssetSize is the upper bound of a downloaded asset.
+	maxAssetSize = 256 << 20
+)
+
+var (
+	// ErrChecksum is returned when the binary doesn't match the checksums of the release.
+	ErrChecksum = errors.New("checksum mismatch")
+	// ErrSignature is returned when the checksums don't match their signature.
+	ErrSignature = errors.New("invalid signature of the checksums")
+	// ErrNoPublicKey is returned by New without the public key verifying the signatures.
+	ErrNoPublicKey = errors.New("no public key to verify the releases")
+)
+
+// Asset is a file of a release.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Release is a GitHub release.
+type Release struct {
+	Tag        string  `json:"tag_name"`
+	URL        string  `json:"html_url"`
+	Draft      bool    `json:"draft"`
+	Prerelease bool    `json:"prerelease"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Asset returns the asset of the name, nil if the release doesn't have it.
+func (r *Release) Asset(name string) *Asset {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+// Client reads the releases of a GitHub repository and downloads their assets.
+type Client struct {
+	cfg *config
+	key ed25519.PublicKey
+}
+
+// New creates a new update client with the given options.
+func New(opts ...Option) (*Client, error) {
+	cfg := &config{
+		baseURL:    "https://api.github.com",
+		repo:       "appleboy/CodeGPT",
+		httpClient: &http.Client{Timeout: 5 * time.Minute},
+	}
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+
+	if strings.TrimSpace(cfg.publicKey) == "" {
+		return nil, ErrNoPublicKey
+	}
+	key, err := parsePublicKey(cfg.publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{cfg: cfg, key: key}, nil
+}
+
+// parsePublicKey parses the base64 Ed25519 public key, raw or in the DER form of
+// a PEM file.
+func parsePublicKey(s string) (ed25519.PublicKey, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "-----BEGIN PUBLIC KEY-----")
+	s = strings.TrimSuffix(s, "-----END PUBLIC KEY-----")
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	if len(data) == ed25519.PublicKeySize {
+		return ed25519.PublicKey(data), nil
+	}
+	pub, err := x509.ParsePKIXPublicKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	key, ok := pub.(ed25519.PublicKey)
+	if !ok {
+		return nil, errors.New("invalid public key: not an Ed25519 key")
+	}
+	return key, nil
+}
+
+// Latest returns the latest release of the channel.
+// https://docs.github.com/en/rest/releases/releases#list-releases
+func (c *Client) Latest(ctx context.Context, channel string) (*Release, error) {
+	switch channel {
+	case Stable:
+		r := &Release{}
+		if err := c.get(ctx, "/repos/"+c.cfg.repo+"/releases/latest", r); err != nil {
+			return nil, err
+		}
+		return r, nil
+	case Prerelease:
+		var releases []*Release
+		if err := c.get(ctx, "/repos/"+c.cfg.repo+"/releases?per_page=30", &releases); err != nil {
+			return nil, err
+		}
+		return newest(releases)
+	}
+	return nil, fmt.Errorf("invalid channel %q, use %s or %s", channel, Stable, Prerelease)
+}
+
+// newest returns the release of the highest version, the drafts and the tags which
+// aren't versions are skipped.
+func newest(releases []*Release) (*Release, error) {
+	var (
+		out *Release
+		max release.Version
+	)
+	for _, r := range releases {
+		v, err := release.ParseVersion(r.Tag)
+		if r.Draft || err != nil {
+			continue
+		}
+		if out == nil || v.Compare(max) > 0 {
+			out, max = r, v
+		}
+	}
+	if out == nil {
+		return nil, errors.New("no release found")
+	}
+	return out, nil
+}
+
+// get sends a GET request to the GitHub API and decodes the JSON response into out.
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(c.cfg.baseURL, "/")+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := c.cfg.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("github api error: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// AssetName returns the name of the binary of the release for the running platform,
+// like CodeGPT-1.2.3-linux-amd64 for the v1.2.3 tag.
+func AssetName(tag string) string {
+	goarm := ""
+	if runtime.GOARCH == "arm" {
+		goarm = "7"
+		if info, ok := debug.ReadBuildInfo(); ok {
+			for _, s := range info.Settings {
+				if s.Key == "GOARM" && s.Value != "" {
+					goarm = s.Value
+				}
+			}
+		}
+	}
+	return assetName(tag, runtime.GOOS, runtime.GOARCH, goarm)
+}
+
+// assetName returns the name of the binary built by goreleaser for the platform.
+func assetName(tag, goos, goarch, goarm string) string {
+	name := "CodeGPT-" + strings.TrimPrefix(tag, "v") + "-" + goos + "-" + goarch
+	if goarm != "" {
+		name += "-" + goarm
+	}
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Download returns the asset of the name, verified with the checksums of the release.
+// The signature of the checksums is verified first, a release without it is refused.
+func (c *Client) Download(ctx context.Context, r *Release, name string) ([]byte, error) {
+	asset := r.Asset(name)
+	if asset == nil {
+		return nil, fmt.Errorf("the release %s has no %s binary", r.Tag, name)
+	}
+	sumsAsset := r.Asset(ChecksumsFile)
+	if sumsAsset == nil {
+		return nil, fmt.Errorf("the release %s has no %s: %w", r.Tag, ChecksumsFile, ErrChecksum)
+	}
+	sums, err := c.fetch(ctx, sumsAsset.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	sigAsset := r.Asset(SignatureFile)
+	if sigAsset == nil {
+		return nil, fmt.Errorf("the release %s has no %s: %w", r.Tag, SignatureFile, ErrSignature)
+	}
+	sig, err := c.fetch(ctx, sigAsset.URL)
+	if err != nil {
+		return nil, err
+	}
+	if err := Verify(c.key, sums, sig); err != nil {
+		return nil, err
+	}
+
+	want, ok := ParseChecksums(sums)[name]
+	if !ok {
+		return nil, fmt.Errorf("%s isn't in the %s of the release %s: %w", name, ChecksumsFile, r.Tag, ErrChecksum)
+	}
+	data, err := c.fetch(ctx, asset.URL)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return nil, fmt.Errorf("%s is %s, the release expects %s: %w", name, got, want, ErrChecksum)
+	}
+	return data, nil
+}
+
+// fetch downloads the file of the URL.
+func (c *Client) fetch(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.cfg.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("can't download %s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAssetSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxAssetSize {
+		return nil, fmt.Errorf("%s is larger than %d MB", url, maxAssetSize>>20)
+	}
+	return data, nil
+}
+
+// ParseChecksums returns the SHA-256 by file name of the lines "<sha256>  <name>" of the
+// checksums file.
+func ParseChecksums(data []byte) map[string]string {
+	sums := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		// the binary mode of sha256sum prefixes the name with a star
+		sums[strings.TrimPrefix(fields[1], "*")] = strings.ToLower(fields[0])
+	}
+	return sums
+}
+
+// Verify returns ErrSignature when the Ed25519 signature, raw or base64, doesn't match
+// the data.
+func Verify(key ed25519.PublicKey, data, sig []byte) error {
+	if len(sig) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+		if err != nil {
+			return ErrSignature
+		}
+		sig = decoded
+	}
+	if len(sig) != ed25519.SignatureSize || !ed25519.Verify(key, data, sig) {
+		return ErrSignature
+	}
+	return nil
+}
+
+// Replace replaces the binary of the path with the data, keeping its permissions. The
+// new binary is written next to it, then renamed over it.
+func Replace(exe string, data []byte) error {
+	info, err := os.Stat(exe)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(exe), "."+filepath.Base(exe)+".new-*")
+	if err != nil {
+		return fmt.Errorf("can't write next to %s, run the update with the permissions of its folder: %w", exe, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	if runtime.GOOS != "windows" {
+		return os.Rename(tmp.Name(), exe)
+	}
+
+	// the running binary of Windows can't be overwritten, only renamed
+	old := exe + ".old"
+	_ = os.Remove(old)
+	if err := os.Rename(exe, old); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), exe); err != nil {
+		_ = os.Rename(old, exe)
+		return err
+	}
+	// removed by the next update when the old binary is still running
+	_ = os.Remove(old)
+	return nil
+}
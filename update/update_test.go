@@ -0,0 +1,155 @@
+package update
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAssetName(t *testing.T) {
+	tests := []struct {
+		tag, goos, goarch, goarm string
+		want                     string
+	}{
+		{"v1.2.3", "linux", "amd64", "", "CodeGPT-1.2.3-linux-amd64"},
+		{"1.2.3", "darwin", "arm64", "", "CodeGPT-1.2.3-darwin-arm64"},
+		{"v1.2.3", "linux", "arm", "6", "CodeGPT-1.2.3-linux-arm-6"},
+		{"v2.0.0-rc.1", "windows", "amd64", "", "CodeGPT-2.0.0-rc.1-windows-amd64.exe"},
+	}
+	for _, tt := range tests {
+		if got := assetName(tt.tag, tt.goos, tt.goarch, tt.goarm); got != tt.want {
+			t.Errorf("assetName(%s, %s, %s) = %s, want %s", tt.tag, tt.goos, tt.goarch, got, tt.want)
+		}
+	}
+}
+
+// testServer serves the releases of the repository with the binary, its checksums and
+// their signature made with the key.
+func testServer(t *testing.T, binary []byte, key ed25519.PrivateKey) *httptest.Server {
+	t.Helper()
+	name := "CodeGPT-1.1.0-rc.1-linux-amd64"
+	sum := sha256.Sum256(binary)
+	sums := []byte(hex.EncodeToString(sum[:]) + "  " + name + "\n0000  other\n")
+
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+	releases := func() []Release {
+		return []Release{
+			{Tag: "v1.2.0", Draft: true},
+			{Tag: "v1.1.0-rc.1", Prerelease: true, Assets: []Asset{
+				{Name: name, URL: srv.URL + "/download/bin"},
+				{Name: ChecksumsFile, URL: srv.URL + "/download/sums"},
+				{Name: SignatureFile, URL: srv.URL + "/download/sig"},
+			}},
+			{Tag: "v1.0.0"},
+		}
+	}
+	mux.HandleFunc("/repos/appleboy/CodeGPT/releases", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(releases())
+	})
+	mux.HandleFunc("/repos/appleboy/CodeGPT/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(releases()[2])
+	})
+	mux.HandleFunc("/download/bin", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write(binary) })
+	mux.HandleFunc("/download/sums", func(w http.ResponseWriter, r *http.Request) { _, _ = w.Write(sums) })
+	mux.HandleFunc("/download/sig", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString(ed25519.Sign(key, sums))))
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLatestAndDownload(t *testing.T) {
+	pub, key, _ := ed25519.GenerateKey(nil)
+	srv := testServer(t, []byte("new binary"), key)
+
+	c, err := New(WithBaseURL(srv.URL), WithPublicKey(base64.StdEncoding.EncodeToString(pub)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := c.Latest(context.Background(), Stable)
+	if err != nil || r.Tag != "v1.0.0" {
+		t.Fatalf("Latest(stable) = %+v, %v", r, err)
+	}
+	r, err = c.Latest(context.Background(), Prerelease)
+	if err != nil || r.Tag != "v1.1.0-rc.1" {
+		t.Fatalf("Latest(prerelease) = %+v, %v", r, err)
+	}
+	if _, err := c.Latest(context.Background(), "nightly"); err == nil {
+		t.Error("Latest() accepted an invalid channel")
+	}
+
+	data, err := c.Download(context.Background(), r, "CodeGPT-1.1.0-rc.1-linux-amd64")
+	if err != nil || string(data) != "new binary" {
+		t.Fatalf("Download() = %q, %v", data, err)
+	}
+	if _, err := c.Download(context.Background(), r, "CodeGPT-1.1.0-rc.1-plan9-amd64"); err == nil {
+		t.Error("Download() of a missing asset succeeded")
+	}
+
+	// the checksums signed by another key
+	other, _, _ := ed25519.GenerateKey(nil)
+	c, _ = New(WithBaseURL(srv.URL), WithPublicKey(base64.StdEncoding.EncodeToString(other)))
+	if _, err := c.Download(context.Background(), r, "CodeGPT-1.1.0-rc.1-linux-amd64"); !errors.Is(err, ErrSignature) {
+		t.Errorf("Download() with another key = %v, want ErrSignature", err)
+	}
+
+	// a release without the signature
+	c, _ = New(WithBaseURL(srv.URL), WithPublicKey(base64.StdEncoding.EncodeToString(pub)))
+	unsigned := *r
+	unsigned.Assets = r.Assets[:2]
+	if _, err := c.Download(context.Background(), &unsigned, "CodeGPT-1.1.0-rc.1-linux-amd64"); !errors.Is(err, ErrSignature) {
+		t.Errorf("Download() of an unsigned release = %v, want ErrSignature", err)
+	}
+
+	// a binary which doesn't match its checksum
+	r.Assets[0].URL = srv.URL + "/download/sums"
+	if _, err := c.Download(context.Background(), r, "CodeGPT-1.1.0-rc.1-linux-amd64"); !errors.Is(err, ErrChecksum) {
+		t.Errorf("Download() of a tampered binary = %v, want ErrChecksum", err)
+	}
+}
+
+func TestInvalidPublicKey(t *testing.T) {
+	if _, err := New(); !errors.Is(err, ErrNoPublicKey) {
+		t.Errorf("New() without public key = %v, want ErrNoPublicKey", err)
+	}
+	if _, err := New(WithPublicKey("not a key")); err == nil {
+		t.Error("New() accepted an invalid public key")
+	}
+}
+
+func TestParseChecksums(t *testing.T) {
+	sums := ParseChecksums([]byte("ABC  CodeGPT-1.0.0-linux-amd64\ndef *CodeGPT-1.0.0-windows-amd64.exe\n\ninvalid\n"))
+	if len(sums) != 2 || sums["CodeGPT-1.0.0-linux-amd64"] != "abc" || sums["CodeGPT-1.0.0-windows-amd64.exe"] != "def" {
+		t.Errorf("ParseChecksums() = %v", sums)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	exe := filepath.Join(t.TempDir(), "codegpt")
+	if err := os.WriteFile(exe, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := Replace(exe, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(exe)
+	info, _ := os.Stat(exe)
+	if string(data) != "new" || info.Mode().Perm() != 0o755 {
+		t.Errorf("Replace() wrote %q with mode %v", data, info.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(filepath.Dir(exe))
+	if len(entries) != 1 {
+		t.Errorf("Replace() left %d files", len(entries))
+	}
+}
@@ -0,0 +1,51 @@
+package usage
+
+import (
+	"fmt"
+	"time"
+)
+
+// Budget is the monthly limit of the tokens and the estimated cost, zero means no limit.
+type Budget struct {
+	Tokens int
+	Cost   float64
+}
+
+// MonthStart returns the start of the calendar month of the time, in its location.
+func MonthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+// Month returns the usage of the records made since the start of the month of now.
+func Month(records []Record, now time.Time) Summary {
+	spent := Summary{Key: now.Format("2006-01")}
+	for _, r := range Since(records, MonthStart(now)) {
+		spent.Requests++
+		spent.PromptTokens += r.PromptTokens
+		spent.CompletionTokens += r.CompletionTokens
+		spent.TotalTokens += r.TotalTokens
+		spent.Cost += r.Cost
+	}
+	return spent
+}
+
+// ExceededError is returned when the spent usage reaches the budget.
+type ExceededError struct {
+	msg string
+}
+
+func (e *ExceededError) Error() string { return e.msg }
+
+// Exceeded returns an *ExceededError describing the limit reached by the spent usage,
+// nil if the usage is under the budget.
+func (b Budget) Exceeded(spent Summary) error {
+	if b.Tokens > 0 && spent.TotalTokens >= b.Tokens {
+		return &ExceededError{msg: fmt.Sprintf("the monthly token budget is exceeded: %d of %d tokens used in %s",
+			spent.TotalTokens, b.Tokens, spent.Key)}
+	}
+	if b.Cost > 0 && spent.Cost >= b.Cost {
+		return &ExceededError{msg: fmt.Sprintf("the monthly cost budget is exceeded: $%.2f of $%.2f spent in %s",
+			spent.Cost, b.Cost, spent.Key)}
+	}
+	return nil
+}
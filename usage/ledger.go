@@ -0,0 +1,88 @@
+package usage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Record is the token usage of a single invocation.
+type Record struct {
+	Time             time.Time `json:"time"`
+	Command          string    `json:"command"`
+	Provider         string    `json:"provider,omitempty"`
+	Model            string    `json:"model"`
+	Repo             string    `json:"repo,omitempty"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	Cost             float64   `json:"cost"`
+}
+
+// Ledger is a local file that stores the usage records, one JSON object per line. The
+// records are appended, so the commands running at the same time don't lose theirs.
+type Ledger struct {
+	path string
+}
+
+// NewLedger returns a ledger stored in the given file.
+func NewLedger(path string) *Ledger {
+	return &Ledger{path: path}
+}
+
+// Records returns all the records of the ledger, none if the file does not exist.
+func (l *Ledger) Records() ([]Record, error) {
+	data, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var r Record
+		err := dec.Decode(&r)
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+}
+
+// Add appends the record to the ledger, estimating its cost if it is not set. The line
+// is written with a single append, the other writers of the file never interleave it.
+func (l *Ledger) Add(r Record) error {
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+	if r.Cost == 0 {
+		r.Cost = Cost(r.Model, r.PromptTokens, r.CompletionTokens)
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
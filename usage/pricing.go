@@ -0,0 +1,71 @@
+package usage
+
+import "strings"
+
+// Price is the price of a model in USD per 1K tokens.
+type Price struct {
+	Prompt     float64
+	Completion float64
+}
+
+// prices maps model name prefixes to their price, the longest matching prefix wins.
+var prices = map[string]Price{
+	"gpt-4o":                 {Prompt: 0.0025, Completion: 0.01},
+	"gpt-4o-mini":            {Prompt: 0.00015, Completion: 0.0006},
+	"gpt-4-turbo":            {Prompt: 0.01, Completion: 0.03},
+	"gpt-4-0125-preview":     {Prompt: 0.01, Completion: 0.03},
+	"gpt-4-1106-preview":     {Prompt: 0.01, Completion: 0.03},
+	"o1":                     {Prompt: 0.015, Completion: 0.06},
+	"o1-mini":                {Prompt: 0.003, Completion: 0.012},
+	"o1-preview":             {Prompt: 0.015, Completion: 0.06},
+	"gpt-4-32k":              {Prompt: 0.06, Completion: 0.12},
+	"gpt-4":                  {Prompt: 0.03, Completion: 0.06},
+	"gpt-3.5-turbo-16k":      {Prompt: 0.003, Completion: 0.004},
+	"gpt-3.5-turbo-instruct": {Prompt: 0.0015, Completion: 0.002},
+	"gpt-3.5-turbo":          {Prompt: 0.0015, Completion: 0.002},
+	"gpt-3.5-turbo-0125":     {Prompt: 0.0005, Completion: 0.0015},
+	"gpt-3.5-turbo-1106":     {Prompt: 0.001, Completion: 0.002},
+	// the models of AWS Bedrock
+	"anthropic.claude-3-haiku":    {Prompt: 0.00025, Completion: 0.00125},
+	"anthropic.claude-3-sonnet":   {Prompt: 0.003, Completion: 0.015},
+	"anthropic.claude-3-5-sonnet": {Prompt: 0.003, Completion: 0.015},
+	"anthropic.claude-3-opus":     {Prompt: 0.015, Completion: 0.075},
+	"amazon.titan-text-express":   {Prompt: 0.0002, Completion: 0.0006},
+	"amazon.titan-text-lite":      {Prompt: 0.00015, Completion: 0.0002},
+	// the models of Mistral AI
+	"mistral-large":      {Prompt: 0.002, Completion: 0.006},
+	"mistral-medium":     {Prompt: 0.0027, Completion: 0.0081},
+	"mistral-small":      {Prompt: 0.0002, Completion: 0.0006},
+	"codestral":          {Prompt: 0.0002, Completion: 0.0006},
+	"open-mistral-nemo":  {Prompt: 0.00015, Completion: 0.00015},
+	"open-mistral-7b":    {Prompt: 0.00025, Completion: 0.00025},
+	"open-mixtral-8x7b":  {Prompt: 0.0007, Completion: 0.0007},
+	"open-mixtral-8x22b": {Prompt: 0.002, Completion: 0.006},
+	"davinci":            {Prompt: 0.002, Completion: 0.002},
+	"curie":              {Prompt: 0.002, Completion: 0.002},
+	"babbage":            {Prompt: 0.0004, Completion: 0.0004},
+	"ada":                {Prompt: 0.0004, Completion: 0.0004},
+}
+
+// PriceOf returns the price of the model and whether it is known.
+func PriceOf(model string) (Price, bool) {
+	var (
+		found  Price
+		length int
+	)
+	for prefix, p := range prices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > length {
+			found, length = p, len(prefix)
+		}
+	}
+	return found, length > 0
+}
+
+// Cost returns the estimated cost in USD of a request, zero for unknown models.
+func Cost(model string, promptTokens, completionTokens int) float64 {
+	p, ok := PriceOf(model)
+	if !ok {
+		return 0
+	}
+	return (float64(promptTokens)*p.Prompt + float64(completionTokens)*p.Completion) / 1000
+}
@@ -0,0 +1,116 @@
+package usage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// Group keys of the usage summary.
+const (
+	ByModel = "model"
+	ByDay   = "day"
+	ByRepo  = "repo"
+)
+
+// Summary is the total usage of a group of records.
+type Summary struct {
+	Key              string  `json:"key"`
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// key returns the group key of the record.
+func (r Record) key(by string) string {
+	switch by {
+	case ByDay:
+		return r.Time.Local().Format("2006-01-02")
+	case ByRepo:
+		if r.Repo == "" {
+			return "-"
+		}
+		return r.Repo
+	default:
+		return r.Model
+	}
+}
+
+// Since returns the records made at or after the given time.
+func Since(records []Record, t time.Time) []Record {
+	var out []Record
+	for _, r := range records {
+		if !r.Time.Before(t) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// Summarize groups the records by model, day or repo.
+// Days are sorted in chronological order, models and repos by cost.
+func Summarize(records []Record, by string) []Summary {
+	index := map[string]int{}
+	var out []Summary
+	for _, r := range records {
+		k := r.key(by)
+		i, ok := index[k]
+		if !ok {
+			i = len(out)
+			index[k] = i
+			out = append(out, Summary{Key: k})
+		}
+		out[i].Requests++
+		out[i].PromptTokens += r.PromptTokens
+		out[i].CompletionTokens += r.CompletionTokens
+		out[i].TotalTokens += r.TotalTokens
+		out[i].Cost += r.Cost
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if by == ByDay {
+			return out[i].Key < out[j].Key
+		}
+		if out[i].Cost != out[j].Cost {
+			return out[i].Cost > out[j].Cost
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+// Render writes the summary as a plain text table with a total row.
+func Render(w io.Writer, by string, summary []Summary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tREQUESTS\tPROMPT\tCOMPLETION\tTOTAL\tCOST (USD)\n", header(by))
+
+	var total Summary
+	for _, s := range summary {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%.4f\n",
+			s.Key, s.Requests, s.PromptTokens, s.CompletionTokens, s.TotalTokens, s.Cost)
+		total.Requests += s.Requests
+		total.PromptTokens += s.PromptTokens
+		total.CompletionTokens += s.CompletionTokens
+		total.TotalTokens += s.TotalTokens
+		total.Cost += s.Cost
+	}
+	fmt.Fprintf(tw, "TOTAL\t%d\t%d\t%d\t%d\t%.4f\n",
+		total.Requests, total.PromptTokens, total.CompletionTokens, total.TotalTokens, total.Cost)
+	return tw.Flush()
+}
+
+// header returns the column header of the group key.
+func header(by string) string {
+	switch by {
+	case ByDay:
+		return "DAY"
+	case ByRepo:
+		return "REPO"
+	default:
+		return "MODEL"
+	}
+}
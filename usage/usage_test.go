@@ -0,0 +1,141 @@
+package usage
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCost(t *testing.T) {
+	tests := []struct {
+		model string
+		want  float64
+	}{
+		{"gpt-3.5-turbo", 0.0035},
+		{"gpt-3.5-turbo-16k-0613", 0.007},
+		{"gpt-4-0613", 0.09},
+		{"gpt-4-32k", 0.18},
+		{"unknown-model", 0},
+	}
+	for _, tt := range tests {
+		if got := Cost(tt.model, 1000, 1000); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("Cost(%q) = %v, want %v", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestLedger(t *testing.T) {
+	l := NewLedger(filepath.Join(t.TempDir(), "codegpt", "usage.json"))
+
+	records, err := l.Records()
+	if err != nil || len(records) != 0 {
+		t.Fatalf("Records() of a missing ledger = %v, %v", records, err)
+	}
+
+	for _, model := range []string{"gpt-4", "gpt-3.5-turbo"} {
+		if err := l.Add(Record{Command: "commit", Model: model, PromptTokens: 1000, CompletionTokens: 1000, TotalTokens: 2000}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err = l.Records()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Records() returned %d records, want 2", len(records))
+	}
+	if records[0].Time.IsZero() || math.Abs(records[0].Cost-0.09) > 1e-9 {
+		t.Errorf("Records()[0] = %+v", records[0])
+	}
+}
+
+func TestLedgerConcurrentAdd(t *testing.T) {
+	l := NewLedger(filepath.Join(t.TempDir(), "usage.json"))
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.Add(Record{Command: "commit", Model: "gpt-4", TotalTokens: 10}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	records, err := l.Records()
+	if err != nil || len(records) != 20 {
+		t.Errorf("Records() = %d records, %v, want 20", len(records), err)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	day1 := time.Date(2023, 10, 1, 12, 0, 0, 0, time.Local)
+	day2 := day1.Add(24 * time.Hour)
+	records := []Record{
+		{Time: day2, Model: "gpt-3.5-turbo", Repo: "/src/a", TotalTokens: 10, Cost: 1},
+		{Time: day1, Model: "gpt-4", Repo: "/src/b", TotalTokens: 20, Cost: 5},
+		{Time: day2, Model: "gpt-4", TotalTokens: 30, Cost: 3},
+	}
+
+	byModel := Summarize(records, ByModel)
+	if len(byModel) != 2 || byModel[0].Key != "gpt-4" || byModel[0].Requests != 2 || byModel[0].TotalTokens != 50 {
+		t.Errorf("Summarize(ByModel) = %+v", byModel)
+	}
+
+	byDay := Summarize(records, ByDay)
+	if len(byDay) != 2 || byDay[0].Key != "2023-10-01" || byDay[1].Cost != 4 {
+		t.Errorf("Summarize(ByDay) = %+v", byDay)
+	}
+
+	byRepo := Summarize(records, ByRepo)
+	if len(byRepo) != 3 || byRepo[0].Key != "/src/b" || byRepo[2].Key != "/src/a" {
+		t.Errorf("Summarize(ByRepo) = %+v", byRepo)
+	}
+
+	if got := Since(records, day2); len(got) != 2 {
+		t.Errorf("Since() returned %d records, want 2", len(got))
+	}
+}
+
+func TestBudget(t *testing.T) {
+	now := time.Date(2024, 3, 15, 12, 0, 0, 0, time.UTC)
+	records := []Record{
+		{Time: time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC), TotalTokens: 5000, Cost: 5},
+		{Time: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), TotalTokens: 600, Cost: 0.6},
+		{Time: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), TotalTokens: 400, Cost: 0.4},
+	}
+
+	spent := Month(records, now)
+	if spent.Requests != 2 || spent.TotalTokens != 1000 || spent.Key != "2024-03" {
+		t.Fatalf("Month() = %+v", spent)
+	}
+
+	tests := []struct {
+		name    string
+		budget  Budget
+		wantErr bool
+	}{
+		{name: "no limit", budget: Budget{}},
+		{name: "under the token limit", budget: Budget{Tokens: 2000}},
+		{name: "token limit reached", budget: Budget{Tokens: 1000}, wantErr: true},
+		{name: "under the cost limit", budget: Budget{Cost: 1.5}},
+		{name: "cost limit reached", budget: Budget{Tokens: 2000, Cost: 0.5}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.budget.Exceeded(spent)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Exceeded() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var exceeded *ExceededError
+			if err != nil && !errors.As(err, &exceeded) {
+				t.Errorf("Exceeded() error = %T, want *ExceededError", err)
+			}
+		})
+	}
+}
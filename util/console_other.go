@@ -0,0 +1,8 @@
+//go:build !windows
+
+package util
+
+// EnableVirtualTerminal does nothing, the terminals process the ANSI escape sequences.
+func EnableVirtualTerminal() error {
+	return nil
+}
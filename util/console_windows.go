@@ -0,0 +1,29 @@
+//go:build windows
+
+package util
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// EnableVirtualTerminal turns on the processing of the ANSI escape sequences by the
+// consoles of stdout and stderr, off by default before Windows Terminal. It returns an
+// error on the legacy consoles without it, the outputs which aren't consoles are skipped.
+func EnableVirtualTerminal() error {
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		handle := windows.Handle(f.Fd())
+		var mode uint32
+		if err := windows.GetConsoleMode(handle, &mode); err != nil {
+			continue
+		}
+		if mode&windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING != 0 {
+			continue
+		}
+		if err := windows.SetConsoleMode(handle, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"html/template"
 	"io/fs"
+	"os"
+	"path/filepath"
 )
 
 // Data defines a custom type for the template data.
@@ -16,8 +18,10 @@ var (
 	templatesDir = "templates"
 )
 
+// NewTemplateByString renders the template of the string, its CRLF line endings are
+// converted to LF.
 func NewTemplateByString(format string, data map[string]interface{}) (string, error) {
-	t, err := template.New("message").Parse(format)
+	t, err := template.New("message").Parse(NormalizeNewlines(format))
 	if err != nil {
 		return "", err
 	}
@@ -74,7 +78,12 @@ func LoadTemplates(files embed.FS) error {
 			continue
 		}
 
-		pt, err := template.ParseFS(files, templatesDir+"/"+tmpl.Name())
+		// the templates checked out on Windows may have CRLF line endings
+		content, err := fs.ReadFile(files, templatesDir+"/"+tmpl.Name())
+		if err != nil {
+			return err
+		}
+		pt, err := template.New(tmpl.Name()).Parse(NormalizeNewlines(string(content)))
 		if err != nil {
 			return err
 		}
@@ -83,3 +92,34 @@ func LoadTemplates(files embed.FS) error {
 	}
 	return nil
 }
+
+// LoadTemplatesFromDir loads the *.tmpl files of the directory, overriding the
+// templates with the same name. It returns the names of the loaded templates,
+// none if the directory does not exist.
+func LoadTemplatesFromDir(dir string) ([]string, error) {
+	if templates == nil {
+		templates = make(map[string]*template.Template)
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, name := range matches {
+		if info, err := os.Stat(name); err != nil || info.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(name)
+		if err != nil {
+			return names, err
+		}
+		pt, err := template.New(filepath.Base(name)).Parse(NormalizeNewlines(string(content)))
+		if err != nil {
+			return names, err
+		}
+		templates[filepath.Base(name)] = pt
+		names = append(names, filepath.Base(name))
+	}
+	return names, nil
+}
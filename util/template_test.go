@@ -2,6 +2,8 @@ package util
 
 import (
 	"html/template"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -69,3 +71,38 @@ func TestProcessTemplate(t *testing.T) {
 		t.Errorf("Unexpected output. Got: %v, Want: %v", buf.String(), expected)
 	}
 }
+
+func TestLoadTemplatesFromDir(t *testing.T) {
+	templates = map[string]*template.Template{
+		"summary.tmpl": template.Must(template.New("summary.tmpl").Parse("default {{.Name}}")),
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "summary.tmpl"), []byte("custom\r\n{{.Name}}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := LoadTemplatesFromDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != "summary.tmpl" {
+		t.Errorf("LoadTemplatesFromDir() = %v, want [summary.tmpl]", names)
+	}
+
+	out, err := GetTemplateByString("summary.tmpl", Data{"Name": "CodeGPT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "custom\nCodeGPT" {
+		t.Errorf("GetTemplateByString() = %q, want the overridden template with LF", out)
+	}
+
+	// a missing directory is not an error
+	if names, err := LoadTemplatesFromDir(filepath.Join(dir, "missing")); err != nil || len(names) != 0 {
+		t.Errorf("LoadTemplatesFromDir() of a missing directory = %v, %v", names, err)
+	}
+}
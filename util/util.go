@@ -22,3 +22,9 @@ func ConvertToMap(args []string) Data {
 	}
 	return m
 }
+
+// NormalizeNewlines converts the CRLF line endings of the files written on Windows,
+// like COMMIT_EDITMSG or the templates, to LF.
+func NormalizeNewlines(s string) string {
+	return strings.ReplaceAll(s, "\r\n", "\n")
+}
@@ -82,3 +82,10 @@ func TestConvertToMap(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeNewlines(t *testing.T) {
+	got := NormalizeNewlines("feat: add hook\r\n\r\nbody line\r\n")
+	if want := "feat: add hook\n\nbody line\n"; got != want {
+		t.Errorf("NormalizeNewlines() = %q, want %q", got, want)
+	}
+}
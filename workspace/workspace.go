@@ -0,0 +1,291 @@
+// Package workspace detects the workspaces of a monorepo, the Go modules of go.work,
+// the packages of the npm, yarn and pnpm workspaces and the Bazel packages, to scope
+// the commit messages to the workspace of the changes.
+package workspace
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The kinds of the workspaces.
+const (
+	KindGo    = "go"
+	KindNPM   = "npm"
+	KindBazel = "bazel"
+)
+
+// sharedFiles are the files of the repository root updated with the changes of any
+// workspace, like the lock files, which don't tell the workspace of the changes.
+var sharedFiles = []string{
+	"go.work",
+	"go.work.sum",
+	"package.json",
+	"package-lock.json",
+	"npm-shrinkwrap.json",
+	"yarn.lock",
+	"pnpm-lock.yaml",
+	"pnpm-workspace.yaml",
+	"bun.lockb",
+	"MODULE.bazel",
+	"MODULE.bazel.lock",
+}
+
+// Workspace is a workspace of the monorepo.
+type Workspace struct {
+	// Name is the name of the Go module, of the npm package or of the Bazel package,
+	// without the path of the module and the npm scope
+	Name string `json:"name"`
+	// Dir is the folder of the workspace relative to the repository root, with slashes
+	Dir  string `json:"dir"`
+	Kind string `json:"kind"`
+}
+
+// Detect returns the workspaces of the repository root, sorted by folder. The Bazel
+// packages are only listed in the Bazel workspaces, with a MODULE.bazel or WORKSPACE file.
+func Detect(root string) ([]Workspace, error) {
+	var out []Workspace
+	for _, detect := range []func(string) ([]Workspace, error){goWork, npmWorkspaces, bazelPackages} {
+		ws, err := detect(root)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, ws...)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Dir < out[j].Dir
+	})
+	return out, nil
+}
+
+// Of returns the workspace of the changed files, the one with the deepest folder for
+// every file, or nil when the files don't belong to one workspace. The shared files of
+// the repository root, like the lock files, don't count and are returned as the files
+// out of the workspace.
+func Of(files []string, workspaces []Workspace) (*Workspace, []string) {
+	var (
+		found  *Workspace
+		shared []string
+	)
+	for _, f := range files {
+		w := find(f, workspaces)
+		if w == nil {
+			if !strings.Contains(f, "/") && slices.Contains(sharedFiles, f) {
+				shared = append(shared, f)
+				continue
+			}
+			return nil, nil
+		}
+		if found != nil && found.Dir != w.Dir {
+			return nil, nil
+		}
+		found = w
+	}
+	if found == nil {
+		return nil, nil
+	}
+	return found, shared
+}
+
+// find returns the workspace with the deepest folder containing the file.
+func find(file string, workspaces []Workspace) *Workspace {
+	var found *Workspace
+	for i, w := range workspaces {
+		if !strings.HasPrefix(file, w.Dir+"/") {
+			continue
+		}
+		if found == nil || len(w.Dir) > len(found.Dir) {
+			found = &workspaces[i]
+		}
+	}
+	return found
+}
+
+// goWork returns the modules of the use directives of go.work.
+func goWork(root string) ([]Workspace, error) {
+	f, err := os.Open(filepath.Join(root, "go.work"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		out   []Workspace
+		block bool
+	)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		line = strings.TrimSpace(line)
+		switch {
+		case block && line == ")":
+			block = false
+			continue
+		case line == "use (":
+			block = true
+			continue
+		case strings.HasPrefix(line, "use "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "use "))
+		case !block || line == "":
+			continue
+		}
+		dir := relDir(strings.Trim(line, `"`))
+		if dir == "" {
+			// the module of the repository root isn't a workspace of its own
+			continue
+		}
+		out = append(out, Workspace{Name: goModuleName(root, dir), Dir: dir, Kind: KindGo})
+	}
+	return out, scanner.Err()
+}
+
+// goModuleName returns the last element of the module path of the folder.
+func goModuleName(root, dir string) string {
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(dir), "go.mod"))
+	if err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if module, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+				module = strings.Trim(strings.TrimSpace(module), `"`)
+				// the major version suffix isn't the name, like example.com/api/v2
+				name := path.Base(module)
+				if len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+					name = path.Base(path.Dir(module))
+				}
+				return name
+			}
+		}
+	}
+	return path.Base(dir)
+}
+
+// npmWorkspaces returns the packages of the workspaces of package.json, or of the
+// packages of pnpm-workspace.yaml.
+func npmWorkspaces(root string) ([]Workspace, error) {
+	var patterns []string
+	if data, err := os.ReadFile(filepath.Join(root, "package.json")); err == nil {
+		var pkg struct {
+			Workspaces json.RawMessage `json:"workspaces"`
+		}
+		if err := json.Unmarshal(data, &pkg); err != nil {
+			return nil, errors.New("invalid package.json: " + err.Error())
+		}
+		// a list of patterns, or an object with the packages in yarn
+		var object struct {
+			Packages []string `json:"packages"`
+		}
+		if err := json.Unmarshal(pkg.Workspaces, &patterns); err != nil {
+			if err := json.Unmarshal(pkg.Workspaces, &object); err == nil {
+				patterns = object.Packages
+			}
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "pnpm-workspace.yaml")); err == nil {
+		var pnpm struct {
+			Packages []string `yaml:"packages"`
+		}
+		if err := yaml.Unmarshal(data, &pnpm); err != nil {
+			return nil, errors.New("invalid pnpm-workspace.yaml: " + err.Error())
+		}
+		patterns = append(patterns, pnpm.Packages...)
+	}
+
+	var out []Workspace
+	seen := map[string]bool{}
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			continue
+		}
+		// the packages are the folders matching the pattern, ** is one level deep
+		pattern = strings.ReplaceAll(relDir(pattern), "**", "*")
+		matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern), "package.json"))
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			rel, err := filepath.Rel(root, filepath.Dir(m))
+			if err != nil || rel == "." {
+				continue
+			}
+			dir := filepath.ToSlash(rel)
+			if seen[dir] {
+				continue
+			}
+			seen[dir] = true
+			out = append(out, Workspace{Name: npmName(m, dir), Dir: dir, Kind: KindNPM})
+		}
+	}
+	return out, nil
+}
+
+// npmName returns the name of the package without its scope, like api for @acme/api.
+func npmName(file, dir string) string {
+	var pkg struct {
+		Name string `json:"name"`
+	}
+	data, err := os.ReadFile(file)
+	if err != nil || json.Unmarshal(data, &pkg) != nil || pkg.Name == "" {
+		return path.Base(dir)
+	}
+	return path.Base(pkg.Name)
+}
+
+// bazelPackages returns the folders with a BUILD or BUILD.bazel file of the Bazel workspace.
+func bazelPackages(root string) ([]Workspace, error) {
+	bazel := false
+	for _, name := range []string{"MODULE.bazel", "WORKSPACE", "WORKSPACE.bazel"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			bazel = true
+		}
+	}
+	if !bazel {
+		return nil, nil
+	}
+
+	var out []Workspace
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// the output folders of Bazel, the hidden folders and the npm packages
+			name := d.Name()
+			if p != root && (strings.HasPrefix(name, ".") || strings.HasPrefix(name, "bazel-") || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != "BUILD" && d.Name() != "BUILD.bazel" {
+			return nil
+		}
+		rel, err := filepath.Rel(root, filepath.Dir(p))
+		if err != nil || rel == "." {
+			return err
+		}
+		dir := filepath.ToSlash(rel)
+		out = append(out, Workspace{Name: path.Base(dir), Dir: dir, Kind: KindBazel})
+		return nil
+	})
+	return out, err
+}
+
+// relDir cleans the folder relative to the repository root, empty for the root.
+func relDir(dir string) string {
+	dir = path.Clean(strings.TrimSpace(filepath.ToSlash(dir)))
+	dir = strings.TrimPrefix(dir, "./")
+	if dir == "." || dir == "/" {
+		return ""
+	}
+	return strings.TrimSuffix(dir, "/")
+}
@@ -0,0 +1,156 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFiles writes the files of the map, relative to the folder.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		target := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(target, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []Workspace
+	}{
+		{
+			name: "go.work",
+			files: map[string]string{
+				"go.work":                  "go 1.23\n\nuse (\n\t. // the tools\n\t./services/payments\n\t\"./libs/log\"\n)\n\nuse ./cli\n",
+				"services/payments/go.mod": "module example.com/services/payments/v2\n",
+				"libs/log/go.mod":          "module example.com/logging\n",
+			},
+			want: []Workspace{
+				{Name: "cli", Dir: "cli", Kind: KindGo},
+				{Name: "logging", Dir: "libs/log", Kind: KindGo},
+				{Name: "payments", Dir: "services/payments", Kind: KindGo},
+			},
+		},
+		{
+			name: "npm workspaces",
+			files: map[string]string{
+				"package.json":              `{"name": "root", "workspaces": ["packages/*", "apps/web", "!packages/old"]}`,
+				"packages/ui/package.json":  `{"name": "@acme/ui"}`,
+				"packages/api/package.json": `{}`,
+				"packages/docs/README.md":   "no package",
+				"apps/web/package.json":     `{"name": "web-app"}`,
+			},
+			want: []Workspace{
+				{Name: "web-app", Dir: "apps/web", Kind: KindNPM},
+				{Name: "api", Dir: "packages/api", Kind: KindNPM},
+				{Name: "ui", Dir: "packages/ui", Kind: KindNPM},
+			},
+		},
+		{
+			name: "yarn and pnpm workspaces",
+			files: map[string]string{
+				"package.json":          `{"workspaces": {"packages": ["apps/*"]}}`,
+				"pnpm-workspace.yaml":   "packages:\n  - 'libs/**'\n  - 'apps/*'\n",
+				"apps/web/package.json": `{"name": "web"}`,
+				"libs/db/package.json":  `{"name": "db"}`,
+			},
+			want: []Workspace{
+				{Name: "web", Dir: "apps/web", Kind: KindNPM},
+				{Name: "db", Dir: "libs/db", Kind: KindNPM},
+			},
+		},
+		{
+			name: "bazel",
+			files: map[string]string{
+				"MODULE.bazel":                      "module(name = \"acme\")\n",
+				"BUILD.bazel":                       "",
+				"services/api/BUILD":                "",
+				"services/api/handlers/BUILD.bazel": "",
+				"bazel-out/x/BUILD":                 "",
+				".cache/BUILD":                      "",
+			},
+			want: []Workspace{
+				{Name: "api", Dir: "services/api", Kind: KindBazel},
+				{Name: "handlers", Dir: "services/api/handlers", Kind: KindBazel},
+			},
+		},
+		{
+			name:  "BUILD files without a Bazel workspace",
+			files: map[string]string{"services/api/BUILD": ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+			got, err := Detect(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Detect() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectInvalid(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"package.json": "{"})
+	if _, err := Detect(root); err == nil {
+		t.Error("Detect() error = nil, want the invalid package.json")
+	}
+}
+
+func TestOf(t *testing.T) {
+	workspaces := []Workspace{
+		{Name: "api", Dir: "services/api", Kind: KindBazel},
+		{Name: "handlers", Dir: "services/api/handlers", Kind: KindBazel},
+		{Name: "web", Dir: "apps/web", Kind: KindNPM},
+	}
+	tests := []struct {
+		name   string
+		files  []string
+		want   string
+		shared []string
+	}{
+		{name: "one workspace", files: []string{"apps/web/index.ts", "apps/web/package.json"}, want: "web"},
+		{name: "deepest workspace", files: []string{"services/api/handlers/user.go"}, want: "handlers"},
+		{name: "parent and nested workspaces", files: []string{"services/api/main.go", "services/api/handlers/user.go"}},
+		{name: "two workspaces", files: []string{"apps/web/index.ts", "services/api/main.go"}},
+		{name: "file out of the workspaces", files: []string{"apps/web/index.ts", "README.md"}},
+		{name: "prefix of a folder", files: []string{"apps/webhooks/main.go"}},
+		{
+			name:   "shared files of the root",
+			files:  []string{"apps/web/package.json", "package-lock.json", "pnpm-lock.yaml"},
+			want:   "web",
+			shared: []string{"package-lock.json", "pnpm-lock.yaml"},
+		},
+		{name: "shared files only", files: []string{"go.work.sum"}},
+		{name: "lock file of a folder", files: []string{"apps/web/index.ts", "tools/yarn.lock"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, shared := Of(tt.files, workspaces)
+			name := ""
+			if got != nil {
+				name = got.Name
+			}
+			if name != tt.want {
+				t.Errorf("Of() = %q, want %q", name, tt.want)
+			}
+			if !reflect.DeepEqual(shared, tt.shared) {
+				t.Errorf("Of() shared = %v, want %v", shared, tt.shared)
+			}
+		})
+	}
+}